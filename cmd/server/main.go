@@ -57,6 +57,15 @@ import (
 // @tag.name Treinamento
 // @tag.description Programas e sessões de treinamento
 
+// @tag.name Acessos
+// @tag.description Acesso delegado de profissionais a equinos (convite, aceite, revogação)
+
+// @tag.name Auditoria
+// @tag.description Trilha de auditoria: consulta, exportação e estatísticas (admin)
+
+// @tag.name Privacidade
+// @tag.description Direitos do titular (LGPD): acesso, portabilidade, exclusão e fila administrativa
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("Arquivo .env não encontrado, usando variáveis de ambiente do sistema")
@@ -1,25 +1,61 @@
 package app
 
 import (
+	"context"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
 	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/acessos"
+	"github.com/equinoid/backend/internal/modules/auditoria"
 	"github.com/equinoid/backend/internal/modules/auth"
+	"github.com/equinoid/backend/internal/modules/busca"
+	"github.com/equinoid/backend/internal/modules/documentos"
 	"github.com/equinoid/backend/internal/modules/equinos"
 	"github.com/equinoid/backend/internal/modules/eventos"
 	"github.com/equinoid/backend/internal/modules/exames"
 	"github.com/equinoid/backend/internal/modules/financeiro"
 	"github.com/equinoid/backend/internal/modules/gestacao"
 	"github.com/equinoid/backend/internal/modules/leiloes"
+	"github.com/equinoid/backend/internal/modules/linhagem"
+	"github.com/equinoid/backend/internal/modules/marketplace"
+	"github.com/equinoid/backend/internal/modules/moderacao"
+	"github.com/equinoid/backend/internal/modules/notificacoes"
 	"github.com/equinoid/backend/internal/modules/nutricao"
+	"github.com/equinoid/backend/internal/modules/ofertas"
+	"github.com/equinoid/backend/internal/modules/pagamentos"
 	"github.com/equinoid/backend/internal/modules/participacoes"
+	"github.com/equinoid/backend/internal/modules/passaportes"
+	"github.com/equinoid/backend/internal/modules/privacidade"
 	"github.com/equinoid/backend/internal/modules/rankings"
 	"github.com/equinoid/backend/internal/modules/relatorios"
+	"github.com/equinoid/backend/internal/modules/reproducao"
 	"github.com/equinoid/backend/internal/modules/simulador"
+	"github.com/equinoid/backend/internal/modules/social"
 	"github.com/equinoid/backend/internal/modules/tokenizacao"
 	"github.com/equinoid/backend/internal/modules/treinamento"
 	"github.com/equinoid/backend/internal/modules/users"
+	"github.com/equinoid/backend/internal/modules/valorizacao"
+	"github.com/equinoid/backend/internal/modules/verificacao"
+	"github.com/equinoid/backend/internal/security/audit"
+	"github.com/equinoid/backend/internal/security/biometric"
+	"github.com/equinoid/backend/internal/security/blockchain"
+	"github.com/equinoid/backend/internal/security/compliance"
+	equinoidcrypto "github.com/equinoid/backend/internal/security/crypto"
+	"github.com/equinoid/backend/internal/security/pki"
 	"github.com/equinoid/backend/internal/services"
 	"github.com/equinoid/backend/pkg/cache"
 	"github.com/equinoid/backend/pkg/logging"
+	"github.com/equinoid/backend/pkg/payments"
+	"github.com/equinoid/backend/pkg/storage"
 	"gorm.io/gorm"
 )
 
@@ -40,15 +76,37 @@ type ModuleContainer struct {
 	FinanceiroHandler    *financeiro.Handler
 	NutricaoHandler      *nutricao.Handler
 	TreinamentoHandler   *treinamento.Handler
-	
+	AcessosHandler       *acessos.Handler
+	AuditoriaHandler     *auditoria.Handler
+	PrivacidadeHandler   *privacidade.Handler
+	DocumentosHandler    *documentos.Handler
+	PassaportesHandler   *passaportes.Handler
+	VerificacaoHandler   *verificacao.Handler
+	BuscaHandler         *busca.Handler
+	LinhagemHandler      *linhagem.Handler
+	ReproducaoHandler    *reproducao.Handler
+	ValorizacaoHandler   *valorizacao.Handler
+	SocialHandler        *social.Handler
+	ModeracaoHandler     *moderacao.Handler
+	NotificacoesHandler  *notificacoes.Handler
+	OfertasHandler       *ofertas.Handler
+	MarketplaceHandler   *marketplace.Handler
+	PagamentosHandler    *pagamentos.Handler
+
+	AcessosService     acessos.Service
+	SocialService      social.Service
+	OfertasService     ofertas.Service
+	MarketplaceService marketplace.Service
+	PagamentosService  pagamentos.Service
+	LeiloesService     leiloes.Service
+	AuditLogger        *audit.AuditLogger
+	LGPDService        *compliance.LGPDService
+	PKIManager         *pki.PKIManager
+
 	LegacyHandlers *LegacyHandlers
 }
 
 type LegacyHandlers struct {
-	ValorizacaoService        *services.ValorizacaoService
-	LinhagemService           *services.LinhagemService
-	ReproducaoService         *services.ReproducaoService
-	SocialService             *services.SocialService
 	EventoService             *services.EventoService
 	CertificateService        *services.CertificateService
 	IntegrationService        *services.IntegrationService
@@ -60,9 +118,13 @@ type LegacyHandlers struct {
 	D4SignService             *services.D4SignService
 	LeilaoService             *services.LeilaoService
 	ExameLaboratorialService  *services.ExameLaboratorialService
+	PKISignatureService       *services.PKISignatureService
 }
 
 func InitializeModules(db *gorm.DB, cache cache.CacheInterface, logger *logging.Logger, cfg *config.Config) *ModuleContainer {
+	auditLogger := audit.NewAuditLogger(db, time.Duration(cfg.AuditRetentionDays)*24*time.Hour)
+	auditLogger.SetAnchor(newAuditAnchor(db, cfg, logger))
+
 	usersRepo := users.NewRepository(db)
 	usersService := users.NewService(usersRepo, cache, logger)
 	usersHandler := users.NewHandler(usersService, logger)
@@ -70,28 +132,47 @@ func InitializeModules(db *gorm.DB, cache cache.CacheInterface, logger *logging.
 	authService := auth.NewService(usersRepo, cache, logger, cfg)
 	authHandler := auth.NewHandler(authService, logger)
 
+	lgpdService := compliance.NewLGPDService(db, cfg.PrivacyConsentRetention, true)
+	if key, ok := purposeSigningKey(cfg, logger, cfg.PrivacyExportSigningKey, "PRIVACY_EXPORT_SIGNING_KEY", "privacy-export"); ok {
+		lgpdService.SetExportStorage(cfg.PrivacyExportDir, key, cfg.PrivacyExportTTL)
+	}
+	if err := lgpdService.EnsureDefaultPolicies(context.Background()); err != nil {
+		logger.LogError(err, "InitializeModules.EnsureDefaultPolicies", nil)
+	}
+
 	d4signService := services.NewD4SignService(db, logger, cfg)
+	webhookService := services.NewWebhookService(db, cache, lgpdService, logger)
 	
 	equinosRepo := equinos.NewRepository(db)
-	equinosService := equinos.NewService(equinosRepo, cache, logger, d4signService)
+	equinosService := equinos.NewService(equinosRepo, cache, logger, d4signService, auditLogger, webhookService)
 	equinosHandler := equinos.NewHandler(equinosService, logger)
 
+	acessosRepo := acessos.NewRepository(db)
+	acessosService := acessos.NewService(acessosRepo, equinosRepo, auditLogger, lgpdService, logger)
+	acessosHandler := acessos.NewHandler(acessosService, logger)
+
+	auditoriaService := auditoria.NewService(auditLogger, logger)
+	auditoriaHandler := auditoria.NewHandler(auditoriaService, logger)
+
+	privacidadeService := privacidade.NewService(lgpdService, auditLogger, logger)
+	privacidadeHandler := privacidade.NewHandler(privacidadeService, logger)
+
+	pkiManager := newCertificateAuthority(db, cfg, logger)
+	d4signService.SetTimestamper(pkiManager)
+
 	legacyHandlers := &LegacyHandlers{
-		ValorizacaoService:       services.NewValorizacaoService(db, cache, logger),
-		LinhagemService:          services.NewLinhagemService(db, cache, logger),
-		ReproducaoService:        services.NewReproducaoService(db, cache, logger),
-		SocialService:            services.NewSocialService(db, cache, logger),
 		EventoService:            services.NewEventoService(db, cache, logger),
-		CertificateService:       services.NewCertificateService(db, cache, logger, cfg),
+		CertificateService:       services.NewCertificateService(db, cache, logger, cfg, pkiManager),
 		IntegrationService:       services.NewIntegrationService(db, cache, logger, cfg),
 		ReportService:            services.NewReportService(db, cache, logger),
 		SearchService:            services.NewSearchService(db, cache, logger),
-		WebhookService:           services.NewWebhookService(db, cache, logger),
+		WebhookService:           webhookService,
 		ChatbotService:           services.NewChatbotService(db, cache, logger, cfg),
 		PropriedadeService:       services.NewPropriedadeService(db, cache, logger),
 		D4SignService:            d4signService,
 		LeilaoService:            services.NewLeilaoService(db, cache, logger),
 		ExameLaboratorialService: services.NewExameLaboratorialService(db, cache, logger),
+		PKISignatureService:      services.NewPKISignatureService(db, pkiManager, newBiometricVerifier(cfg, logger), logger),
 	}
 
 	healthHandler := NewHealthHandler(db, cache, logger)
@@ -108,20 +189,37 @@ func InitializeModules(db *gorm.DB, cache cache.CacheInterface, logger *logging.
 	gestacaoHandler := gestacao.NewHandler(gestacaoService, logger)
 
 	eventosRepo := eventos.NewRepository(db)
-	eventosService := eventos.NewService(eventosRepo, logger)
+	eventosService := eventos.NewService(eventosRepo, pkiManager, logger)
 	eventosHandler := eventos.NewHandler(eventosService, logger)
 
 	tokenizacaoRepo := tokenizacao.NewRepository(db)
-	tokenizacaoService := tokenizacao.NewService(tokenizacaoRepo, equinosRepo, logger)
+	tokenizacaoService := tokenizacao.NewService(tokenizacaoRepo, equinosRepo, auditLogger, lgpdService, logger)
 	tokenizacaoHandler := tokenizacao.NewHandler(tokenizacaoService, logger)
 
-	leiloesRepo := leiloes.NewRepository(db)
-	leiloesService := leiloes.NewService(leiloesRepo, equinosRepo, logger)
-	leiloesHandler := leiloes.NewHandler(leiloesService, logger)
+	socialRepo := social.NewRepository(db)
+	moderacaoRepo := moderacao.NewRepository(db)
+	moderacaoService := moderacao.NewService(moderacaoRepo, socialRepo, auditLogger, logger)
+	moderacaoHandler := moderacao.NewHandler(moderacaoService, logger)
+	socialService := social.NewService(socialRepo, equinosRepo, lgpdService, moderacaoService, logger)
+	socialHandler := social.NewHandler(socialService, logger)
+
+	notificacoesRepo := notificacoes.NewRepository(db)
+	notificacoesService := notificacoes.NewService(notificacoesRepo, logger)
+	notificacoesHandler := notificacoes.NewHandler(notificacoesService, logger)
+
+	ofertasRepo := ofertas.NewRepository(db)
+	ofertasService := ofertas.NewService(ofertasRepo, equinosRepo, socialRepo, equinosService, d4signService, notificacoesService, auditLogger, logger)
+	ofertasHandler := ofertas.NewHandler(ofertasService, logger)
+
+	// Revogar ou deixar expirar um consentimento desativa na hora as funcionalidades que dependem dele
+	lgpdService.OnConsentWithdrawn(models.FinalidadePerfilSocialPublico, socialService.RestringirPerfisPublicos)
+	lgpdService.OnConsentWithdrawn(models.FinalidadeCompartilhamentoTerceiros, webhookService.DeactivateUserWebhooks)
+	lgpdService.OnConsentWithdrawn(models.FinalidadeCompartilhamentoTerceiros, acessosService.RevogarLaboratorios)
+	lgpdService.OnConsentWithdrawn(models.FinalidadeKYCTokenizacao, tokenizacaoService.CancelarOfertasUsuario)
 
 	examesRepo := exames.NewRepository(db)
-	examesService := exames.NewService(examesRepo, logger)
-	examesHandler := exames.NewHandler(examesService, logger)
+	examesService := exames.NewService(examesRepo, auditLogger, pkiManager, logger)
+	examesHandler := exames.NewHandler(examesService, acessosService, logger)
 
 	rankingsRepo := rankings.NewRepository(db)
 	rankingsService := rankings.NewService(rankingsRepo, logger)
@@ -142,6 +240,47 @@ func InitializeModules(db *gorm.DB, cache cache.CacheInterface, logger *logging.
 	treinamentoService := treinamento.NewService(treinamentoRepo, equinosRepo, logger)
 	treinamentoHandler := treinamento.NewHandler(treinamentoService, logger)
 
+	linhagemService := linhagem.NewService(equinosRepo, cache, logger)
+	linhagemHandler := linhagem.NewHandler(linhagemService, logger)
+
+	reproducaoRepo := reproducao.NewRepository(db)
+	reproducaoService := reproducao.NewService(reproducaoRepo, equinosRepo, cache, logger)
+	reproducaoHandler := reproducao.NewHandler(reproducaoService, logger)
+
+	valorizacaoRepo := valorizacao.NewRepository(db)
+	valorizacaoService := valorizacao.NewService(valorizacaoRepo, equinosRepo, cache, logger)
+	valorizacaoHandler := valorizacao.NewHandler(valorizacaoService, logger)
+
+	documentStorage := newDocumentStorage(cfg, logger)
+
+	documentosRepo := documentos.NewRepository(db)
+	documentosService := documentos.NewService(documentosRepo, equinosRepo, acessosService, documentStorage, auditLogger, cfg, logger)
+	documentosHandler := documentos.NewHandler(documentosService, cfg.UploadMaxSize, logger)
+
+	passaportesRepo := passaportes.NewRepository(db)
+	passaportesService := passaportes.NewService(passaportesRepo, equinosRepo, linhagemService, acessosService, pkiManager, documentStorage, auditLogger, cfg, logger)
+	passaportesHandler := passaportes.NewHandler(passaportesService, logger)
+
+	leiloesRepo := leiloes.NewRepository(db)
+	leiloesService := leiloes.NewService(leiloesRepo, equinosRepo, linhagemService, documentStorage, auditLogger, cfg, logger)
+	leiloesHandler := leiloes.NewHandler(leiloesService, cfg.UploadMaxSize, logger)
+
+	marketplaceRepo := marketplace.NewRepository(db)
+	marketplaceService := marketplace.NewService(marketplaceRepo, equinosRepo, socialRepo, reproducaoRepo, documentStorage, notificacoesService, auditLogger, cfg, logger)
+	marketplaceHandler := marketplace.NewHandler(marketplaceService, cfg.UploadMaxSize, logger)
+
+	pagamentosRepo := pagamentos.NewRepository(db)
+	pagamentosService := pagamentos.NewService(pagamentosRepo, leiloesRepo, marketplaceRepo, equinosRepo, newPaymentProvider(cfg, logger), notificacoesService, auditLogger, cfg, logger)
+	pagamentosHandler := pagamentos.NewHandler(pagamentosService, logger)
+
+	verificacaoRepo := verificacao.NewRepository(db)
+	verificacaoService := verificacao.NewService(verificacaoRepo, equinosRepo, documentStorage, cfg, logger)
+	verificacaoHandler := verificacao.NewHandler(verificacaoService, logger)
+
+	buscaRepo := busca.NewRepository(db)
+	buscaService := busca.NewService(buscaRepo, logger)
+	buscaHandler := busca.NewHandler(buscaService, logger)
+
 	return &ModuleContainer{
 		EquinosHandler:       equinosHandler,
 		UsersHandler:         usersHandler,
@@ -159,6 +298,202 @@ func InitializeModules(db *gorm.DB, cache cache.CacheInterface, logger *logging.
 		FinanceiroHandler:    financeiroHandler,
 		NutricaoHandler:      nutricaoHandler,
 		TreinamentoHandler:   treinamentoHandler,
+		AcessosHandler:       acessosHandler,
+		AcessosService:       acessosService,
+		AuditoriaHandler:     auditoriaHandler,
+		AuditLogger:          auditLogger,
+		PrivacidadeHandler:   privacidadeHandler,
+		DocumentosHandler:    documentosHandler,
+		PassaportesHandler:   passaportesHandler,
+		VerificacaoHandler:   verificacaoHandler,
+		BuscaHandler:         buscaHandler,
+		LinhagemHandler:      linhagemHandler,
+		ReproducaoHandler:    reproducaoHandler,
+		ValorizacaoHandler:   valorizacaoHandler,
+		SocialHandler:        socialHandler,
+		SocialService:        socialService,
+		ModeracaoHandler:     moderacaoHandler,
+		NotificacoesHandler:  notificacoesHandler,
+		OfertasHandler:       ofertasHandler,
+		OfertasService:       ofertasService,
+		MarketplaceHandler:   marketplaceHandler,
+		MarketplaceService:   marketplaceService,
+		PagamentosHandler:    pagamentosHandler,
+		PagamentosService:    pagamentosService,
+		LeiloesService:       leiloesService,
+		LGPDService:          lgpdService,
+		PKIManager:           pkiManager,
 		LegacyHandlers:       legacyHandlers,
 	}
 }
+
+// newAuditAnchor ancora os checkpoints da auditoria em blockchain quando habilitada, senão em arquivo local assinado
+func newAuditAnchor(db *gorm.DB, cfg *config.Config, logger *logging.Logger) audit.Anchor {
+	if cfg.BlockchainEnabled && cfg.EthereumRPCURL != "" {
+		ethereumService, err := blockchain.NewEthereumService(cfg.EthereumRPCURL, cfg.EthereumContractAddress, cfg.EthereumPrivateKey)
+		if err == nil {
+			return audit.NewBlockchainAnchor(blockchain.NewBlockchainManager(db, ethereumService, nil, "ethereum"))
+		}
+		logger.Warnf("Blockchain indisponível para ancorar auditoria: %v. Usando arquivo local assinado", err)
+	}
+
+	signingKey, ok := purposeSigningKey(cfg, logger, cfg.AuditCheckpointSigningKey, "AUDIT_CHECKPOINT_SIGNING_KEY", "audit-checkpoint")
+	if !ok {
+		return nil
+	}
+	return audit.NewFileAnchor(cfg.AuditCheckpointFile, signingKey)
+}
+
+// newCertificateAuthority CA interna que emite os certificados dos equinos. Falhas na inicialização não impedem o
+// servidor de subir: as rotas de certificados respondem como indisponíveis até a CA ser corrigida.
+func newCertificateAuthority(db *gorm.DB, cfg *config.Config, logger *logging.Logger) *pki.PKIManager {
+	encryptionService, err := equinoidcrypto.NewEncryptionService(cfg)
+	if err != nil {
+		logger.LogError(err, "InitializeModules.NewEncryptionService", nil)
+		return pki.NewPKIManager(db, pki.NewCAService(cfg.CACertPath, cfg.CAKeyPath, cfg.CACertValidityDays, nil))
+	}
+
+	ca := pki.NewCAService(cfg.CACertPath, cfg.CAKeyPath, cfg.CACertValidityDays, encryptionService)
+	if err := ca.Initialize(); err != nil {
+		logger.LogError(err, "InitializeModules.CAInitialize", logging.Fields{"ca_cert_path": cfg.CACertPath})
+	}
+	if cfg.PKIPublicURL != "" {
+		baseURL := strings.TrimRight(cfg.PKIPublicURL, "/")
+		ca.SetDistributionPoints(baseURL+"/crl", baseURL+"/ocsp")
+	}
+
+	manager := pki.NewPKIManager(db, ca)
+	manager.SetCRLValidity(cfg.CRLValidity)
+	configureTimestamping(manager, ca, cfg, logger)
+
+	seal := pki.NewSealAuthority(cfg.SealCertPath, cfg.SealKeyPath, ca)
+	if err := seal.Initialize(); err != nil {
+		logger.LogError(err, "InitializeModules.SealInitialize", logging.Fields{"seal_cert_path": cfg.SealCertPath})
+	}
+	manager.SetSealAuthority(seal)
+	return manager
+}
+
+// configureTimestamping TSA interna com chave própria emitida pela CA; com TSA_URL os carimbos passam a vir da TSA
+// externa, verificada contra as raízes de TSA_TRUSTED_CERTS
+func configureTimestamping(manager *pki.PKIManager, ca *pki.CAService, cfg *config.Config, logger *logging.Logger) {
+	tsa, err := pki.NewTimestampAuthority(cfg.TSACertPath, cfg.TSAKeyPath, cfg.TSAPolicyOID, ca)
+	if err != nil {
+		logger.LogError(err, "InitializeModules.NewTimestampAuthority", logging.Fields{"policy": cfg.TSAPolicyOID})
+	} else {
+		if err := tsa.Initialize(); err != nil {
+			logger.LogError(err, "InitializeModules.TSAInitialize", logging.Fields{"tsa_cert_path": cfg.TSACertPath})
+		}
+		manager.SetTimestampAuthority(tsa)
+	}
+
+	if cfg.TSAURL == "" {
+		return
+	}
+	client, err := pki.NewHTTPTimestampClient(cfg.TSAURL, cfg.TSAPolicyOID, cfg.TSATimeout)
+	if err != nil {
+		logger.LogError(err, "InitializeModules.NewHTTPTimestampClient", logging.Fields{"tsa_url": cfg.TSAURL})
+		return
+	}
+
+	var roots []*x509.Certificate
+	if cfg.TSATrustedCertsPath != "" {
+		bundle, err := os.ReadFile(cfg.TSATrustedCertsPath)
+		if err != nil {
+			logger.LogError(err, "InitializeModules.TSATrustedCerts", logging.Fields{"path": cfg.TSATrustedCertsPath})
+		}
+		for block, rest := pem.Decode(bundle); block != nil; block, rest = pem.Decode(rest) {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				logger.LogError(err, "InitializeModules.TSATrustedCerts", logging.Fields{"path": cfg.TSATrustedCertsPath})
+				continue
+			}
+			roots = append(roots, cert)
+		}
+	}
+	manager.SetTimestamper(client, roots)
+}
+
+// biometricMatchTolerance similaridade facial mínima para confirmar o signatário
+const biometricMatchTolerance = 0.8
+
+// newBiometricVerifier step-up biométrico das assinaturas pela PKI interna; sem chave de criptografia dos templates,
+// documentos que exigem a confirmação não podem ser assinados
+func newBiometricVerifier(cfg *config.Config, logger *logging.Logger) services.BiometricVerifier {
+	encryptionService, err := equinoidcrypto.NewEncryptionService(cfg)
+	if err != nil {
+		logger.LogError(err, "InitializeModules.NewBiometricVerifier", nil)
+		return nil
+	}
+	return biometric.NewSignatureService(biometric.NewFaceIDService(biometricMatchTolerance, encryptionService))
+}
+
+// newDocumentStorage armazenamento do cofre de documentos. Falhas de configuração não impedem o servidor de subir:
+// as rotas de documentos respondem como indisponíveis até o armazenamento ser corrigido.
+func newDocumentStorage(cfg *config.Config, logger *logging.Logger) storage.Storage {
+	if cfg.StorageBackend == "s3" {
+		store, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:        cfg.AWSS3Endpoint,
+			Region:          cfg.AWSRegion,
+			Bucket:          cfg.AWSS3Bucket,
+			AccessKeyID:     cfg.AWSAccessKeyID,
+			SecretAccessKey: cfg.AWSSecretAccessKey,
+			ForcePathStyle:  cfg.AWSS3ForcePathStyle,
+		})
+		if err != nil {
+			logger.LogError(err, "InitializeModules.NewS3Storage", logging.Fields{"bucket": cfg.AWSS3Bucket})
+			return nil
+		}
+		return store
+	}
+
+	signingKey, ok := purposeSigningKey(cfg, logger, cfg.DocumentURLSigningKey, "DOCUMENT_URL_SIGNING_KEY", "document-url")
+	if !ok {
+		return nil
+	}
+	basePath := filepath.Join(cfg.UploadPath, "documentos")
+	store, err := storage.NewLocalStorage(basePath, cfg.DocumentPublicURL, signingKey)
+	if err != nil {
+		logger.LogError(err, "InitializeModules.NewLocalStorage", logging.Fields{"path": basePath})
+		return nil
+	}
+	return store
+}
+
+// newPaymentProvider provedor de pagamentos. Provedor desconhecido não impede o servidor de subir: as cobranças
+// respondem como indisponíveis até a configuração ser corrigida.
+func newPaymentProvider(cfg *config.Config, logger *logging.Logger) payments.Provider {
+	switch cfg.PaymentProvider {
+	case "fake":
+		if cfg.Environment == "production" {
+			logger.Warn("PAYMENT_PROVIDER=fake em produção; nenhuma cobrança será realmente processada")
+		}
+		if cfg.PaymentWebhookSecret == "" {
+			logger.Warn("PAYMENT_WEBHOOK_SECRET não definida; webhooks de pagamento serão rejeitados")
+		}
+		return payments.NewFakeProvider(cfg.PaymentWebhookSecret)
+	default:
+		logger.WithFields(logging.Fields{"provider": cfg.PaymentProvider}).Error("PAYMENT_PROVIDER desconhecido; pagamentos indisponíveis")
+		return nil
+	}
+}
+
+// purposeSigningKey chave HMAC dedicada a um propósito. Em produção a chave é obrigatória e, sem ela, o recurso fica
+// desabilitado; fora de produção é derivada do JWT_SECRET por HKDF, uma subchave por propósito, para que nenhuma
+// assinatura compartilhe a chave dos tokens
+func purposeSigningKey(cfg *config.Config, logger *logging.Logger, key, envVar, purpose string) (string, bool) {
+	if key != "" {
+		return key, true
+	}
+	if cfg.Environment == "production" {
+		logger.WithFields(logging.Fields{"purpose": purpose}).Error(envVar + " não definida; recurso desabilitado")
+		return "", false
+	}
+	derived, err := hkdf.Key(sha256.New, []byte(cfg.JWTSecret), nil, "equinoid/"+purpose, 32)
+	if err != nil {
+		logger.LogError(err, "InitializeModules.purposeSigningKey", logging.Fields{"purpose": purpose})
+		return "", false
+	}
+	logger.Warn(envVar + " não definida; usando subchave derivada do JWT_SECRET (apenas fora de produção)")
+	return hex.EncodeToString(derived), true
+}
@@ -0,0 +1,375 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+const (
+	auditRetentionInterval       = 24 * time.Hour
+	privacyExportCleanupInterval = 24 * time.Hour
+	consentExpiryInterval        = time.Hour
+	socialCountersInterval       = 24 * time.Hour
+	ofertasInterval              = 15 * time.Minute
+	anunciosInterval             = time.Hour
+	pagamentosInterval           = 15 * time.Minute
+	leiloesInterval              = time.Minute
+)
+
+// AuditRetention remove logs de auditoria fora do período de retenção
+type AuditRetention interface {
+	CleanupOldLogs(ctx context.Context) (int64, error)
+}
+
+// PrivacyExportCleaner remove pacotes de dados de titulares com prazo de download expirado
+type PrivacyExportCleaner interface {
+	CleanupExpiredExports(ctx context.Context) (int64, error)
+}
+
+// ConsentExpirer encerra consentimentos vencidos e desativa as funcionalidades que dependem deles
+type ConsentExpirer interface {
+	CleanupExpiredConsents(ctx context.Context) (int64, error)
+}
+
+// CRLPublisher assina e publica a lista de certificados revogados da CA interna
+type CRLPublisher interface {
+	PublishCRL(ctx context.Context) (*models.CertificateRevocationList, error)
+}
+
+// D4SignSynchronizer reconcilia documentos D4Sign com webhooks perdidos e lembra signatários pendentes
+type D4SignSynchronizer interface {
+	Reconcile(ctx context.Context) (int, error)
+	SendReminders(ctx context.Context) (int, error)
+}
+
+// SocialCounterReconciler recalcula os contadores da rede social a partir das tabelas de origem
+type SocialCounterReconciler interface {
+	RecalcularContadores(ctx context.Context) (int64, error)
+}
+
+// OfertaProcessor expira propostas vencidas e acompanha os contratos dos negócios aceitos
+type OfertaProcessor interface {
+	ExpirarOfertas(ctx context.Context) (int, error)
+	ProcessarContratos(ctx context.Context) (int, error)
+}
+
+// AnuncioExpirer encerra os anúncios do marketplace com a validade vencida
+type AnuncioExpirer interface {
+	ExpirarAnuncios(ctx context.Context) (int, error)
+}
+
+// PagamentoProcessor acompanha cobranças pendentes, libera ou estorna custódias vencidas e repete repasses falhos
+type PagamentoProcessor interface {
+	ProcessarPagamentos(ctx context.Context) (int, error)
+}
+
+// LeilaoScheduler inicia e encerra os leilões nos horários agendados
+type LeilaoScheduler interface {
+	ProcessarLeiloes(ctx context.Context) (int, error)
+}
+
+// AuditCheckpointer consolida a cadeia de auditoria em checkpoints ancorados
+type AuditCheckpointer interface {
+	CreateCheckpoint(ctx context.Context) (*models.AuditCheckpoint, error)
+}
+
+// StartAuditCheckpointJob gera checkpoints de Merkle da trilha de auditoria a cada intervalo até o contexto ser cancelado
+func StartAuditCheckpointJob(ctx context.Context, checkpointer AuditCheckpointer, interval time.Duration, logger *logging.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runAuditCheckpoint(ctx, checkpointer, logger)
+			}
+		}
+	}()
+}
+
+// StartAuditRetentionJob executa a limpeza de logs de auditoria na inicialização e depois diariamente até o contexto ser cancelado
+func StartAuditRetentionJob(ctx context.Context, retention AuditRetention, logger *logging.Logger) {
+	go func() {
+		ticker := time.NewTicker(auditRetentionInterval)
+		defer ticker.Stop()
+
+		for {
+			runAuditCleanup(ctx, retention, logger)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func runAuditCleanup(ctx context.Context, retention AuditRetention, logger *logging.Logger) {
+	removed, err := retention.CleanupOldLogs(ctx)
+	if err != nil {
+		logger.LogError(err, "AuditRetentionJob", nil)
+		return
+	}
+	if removed > 0 {
+		logger.WithFields(logging.Fields{"removed": removed}).Info("Logs de auditoria expirados removidos")
+	}
+}
+
+func runAuditCheckpoint(ctx context.Context, checkpointer AuditCheckpointer, logger *logging.Logger) {
+	checkpoint, err := checkpointer.CreateCheckpoint(ctx)
+	if err != nil {
+		logger.LogError(err, "AuditCheckpointJob", nil)
+		return
+	}
+	if checkpoint != nil {
+		logger.WithFields(logging.Fields{
+			"checkpoint_id": checkpoint.ID,
+			"to_log_id":     checkpoint.ToLogID,
+			"entries":       checkpoint.EntryCount,
+			"anchor":        checkpoint.AnchorNetwork,
+		}).Info("Checkpoint de auditoria criado")
+	}
+}
+
+// StartPrivacyExportCleanupJob remove pacotes de exportação LGPD expirados na inicialização e depois diariamente até o contexto ser cancelado
+func StartPrivacyExportCleanupJob(ctx context.Context, cleaner PrivacyExportCleaner, logger *logging.Logger) {
+	go func() {
+		ticker := time.NewTicker(privacyExportCleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			removed, err := cleaner.CleanupExpiredExports(ctx)
+			if err != nil {
+				logger.LogError(err, "PrivacyExportCleanupJob", nil)
+			} else if removed > 0 {
+				logger.WithFields(logging.Fields{"removed": removed}).Info("Pacotes de dados de titulares expirados removidos")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StartConsentExpiryJob expira consentimentos vencidos na inicialização e depois a cada hora até o contexto ser cancelado
+func StartConsentExpiryJob(ctx context.Context, expirer ConsentExpirer, logger *logging.Logger) {
+	go func() {
+		ticker := time.NewTicker(consentExpiryInterval)
+		defer ticker.Stop()
+
+		for {
+			expired, err := expirer.CleanupExpiredConsents(ctx)
+			if err != nil {
+				logger.LogError(err, "ConsentExpiryJob", logging.Fields{"expired": expired})
+			}
+			if expired > 0 {
+				logger.WithFields(logging.Fields{"expired": expired}).Info("Consentimentos vencidos expirados")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StartCRLPublishJob publica a CRL na inicialização e depois a cada intervalo, antes do nextUpdate da anterior, até o contexto ser cancelado
+func StartCRLPublishJob(ctx context.Context, publisher CRLPublisher, interval time.Duration, logger *logging.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			crl, err := publisher.PublishCRL(ctx)
+			if err != nil {
+				logger.LogError(err, "CRLPublishJob", nil)
+			} else {
+				logger.WithFields(logging.Fields{
+					"number":      crl.Number,
+					"revoked":     crl.RevokedCount,
+					"next_update": crl.NextUpdate,
+				}).Info("CRL publicada")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StartD4SignSyncJob reconcilia documentos pendentes com a D4Sign e envia lembretes a cada intervalo até o contexto ser cancelado
+func StartD4SignSyncJob(ctx context.Context, synchronizer D4SignSynchronizer, interval time.Duration, logger *logging.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			synced, err := synchronizer.Reconcile(ctx)
+			if err != nil {
+				logger.LogError(err, "D4SignSyncJob.Reconcile", nil)
+			}
+			reminded, err := synchronizer.SendReminders(ctx)
+			if err != nil {
+				logger.LogError(err, "D4SignSyncJob.SendReminders", nil)
+			}
+			if synced > 0 || reminded > 0 {
+				logger.WithFields(logging.Fields{"synced": synced, "reminded": reminded}).Info("Documentos D4Sign sincronizados")
+			}
+		}
+	}()
+}
+
+// StartSocialCountersJob corrige contadores de seguidores, posts e engajamento divergentes na inicialização e depois
+// diariamente até o contexto ser cancelado
+func StartSocialCountersJob(ctx context.Context, reconciler SocialCounterReconciler, logger *logging.Logger) {
+	go func() {
+		ticker := time.NewTicker(socialCountersInterval)
+		defer ticker.Stop()
+
+		for {
+			corrigidos, err := reconciler.RecalcularContadores(ctx)
+			if err != nil {
+				logger.LogError(err, "SocialCountersJob", nil)
+			} else if corrigidos > 0 {
+				logger.WithFields(logging.Fields{"corrigidos": corrigidos}).Warn("Contadores sociais divergentes corrigidos")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StartOfertasJob expira ofertas vencidas, envia contratos pendentes e conclui negócios com contrato assinado a cada
+// intervalo até o contexto ser cancelado
+func StartOfertasJob(ctx context.Context, processor OfertaProcessor, logger *logging.Logger) {
+	go func() {
+		ticker := time.NewTicker(ofertasInterval)
+		defer ticker.Stop()
+
+		for {
+			expiradas, err := processor.ExpirarOfertas(ctx)
+			if err != nil {
+				logger.LogError(err, "OfertasJob.ExpirarOfertas", nil)
+			}
+			contratos, err := processor.ProcessarContratos(ctx)
+			if err != nil {
+				logger.LogError(err, "OfertasJob.ProcessarContratos", nil)
+			}
+			if expiradas > 0 || contratos > 0 {
+				logger.WithFields(logging.Fields{"expiradas": expiradas, "contratos": contratos}).Info("Ofertas processadas")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StartMarketplaceJob expira os anúncios vencidos do marketplace a cada intervalo até o contexto ser cancelado
+func StartMarketplaceJob(ctx context.Context, expirer AnuncioExpirer, logger *logging.Logger) {
+	go func() {
+		ticker := time.NewTicker(anunciosInterval)
+		defer ticker.Stop()
+
+		for {
+			expirados, err := expirer.ExpirarAnuncios(ctx)
+			if err != nil {
+				logger.LogError(err, "MarketplaceJob.ExpirarAnuncios", nil)
+			} else if expirados > 0 {
+				logger.WithFields(logging.Fields{"expirados": expirados}).Info("Anúncios do marketplace expirados")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StartPagamentosJob processa os pagamentos em andamento a cada intervalo até o contexto ser cancelado
+func StartPagamentosJob(ctx context.Context, processor PagamentoProcessor, logger *logging.Logger) {
+	go func() {
+		ticker := time.NewTicker(pagamentosInterval)
+		defer ticker.Stop()
+
+		for {
+			processados, err := processor.ProcessarPagamentos(ctx)
+			if err != nil {
+				logger.LogError(err, "PagamentosJob.ProcessarPagamentos", nil)
+			} else if processados > 0 {
+				logger.WithFields(logging.Fields{"processados": processados}).Info("Pagamentos processados")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StartLeiloesJob inicia e encerra leilões no horário agendado, verificando a cada minuto até o contexto ser cancelado
+func StartLeiloesJob(ctx context.Context, scheduler LeilaoScheduler, logger *logging.Logger) {
+	go func() {
+		ticker := time.NewTicker(leiloesInterval)
+		defer ticker.Stop()
+
+		for {
+			processados, err := scheduler.ProcessarLeiloes(ctx)
+			if err != nil {
+				logger.LogError(err, "LeiloesJob.ProcessarLeiloes", nil)
+			} else if processados > 0 {
+				logger.WithFields(logging.Fields{"processados": processados}).Info("Leilões iniciados ou encerrados")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -4,14 +4,31 @@ import (
 	"github.com/equinoid/backend/internal/config"
 	"github.com/equinoid/backend/internal/handlers"
 	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/acessos"
+	"github.com/equinoid/backend/internal/modules/auditoria"
 	"github.com/equinoid/backend/internal/modules/auth"
+	"github.com/equinoid/backend/internal/modules/documentos"
 	"github.com/equinoid/backend/internal/modules/equinos"
 	"github.com/equinoid/backend/internal/modules/eventos"
 	"github.com/equinoid/backend/internal/modules/gestacao"
+	"github.com/equinoid/backend/internal/modules/linhagem"
+	"github.com/equinoid/backend/internal/modules/marketplace"
+	"github.com/equinoid/backend/internal/modules/moderacao"
+	"github.com/equinoid/backend/internal/modules/notificacoes"
+	"github.com/equinoid/backend/internal/modules/ofertas"
+	"github.com/equinoid/backend/internal/modules/pagamentos"
 	"github.com/equinoid/backend/internal/modules/participacoes"
+	"github.com/equinoid/backend/internal/modules/passaportes"
+	"github.com/equinoid/backend/internal/modules/privacidade"
+	"github.com/equinoid/backend/internal/modules/reproducao"
 	"github.com/equinoid/backend/internal/modules/simulador"
+	"github.com/equinoid/backend/internal/modules/social"
 	"github.com/equinoid/backend/internal/modules/tokenizacao"
 	"github.com/equinoid/backend/internal/modules/users"
+	"github.com/equinoid/backend/internal/modules/valorizacao"
+	"github.com/equinoid/backend/internal/modules/verificacao"
+	"github.com/equinoid/backend/internal/modules/busca"
 	"github.com/equinoid/backend/internal/modules/leiloes"
 	"github.com/equinoid/backend/internal/modules/exames"
 	"github.com/equinoid/backend/internal/modules/rankings"
@@ -34,6 +51,7 @@ func BuildRouter(modules *ModuleContainer, cfg *config.Config, logger *logging.L
 	router.Use(middleware.Recovery(logger))
 	router.Use(middleware.CORS())
 	router.Use(middleware.RequestID())
+	router.Use(middleware.AuditTrail(modules.AuditLogger, logger))
 	router.Use(middleware.RateLimit(cfg.RateLimitPerMinute))
 
 	if cfg.MetricsEnabled {
@@ -62,7 +80,7 @@ func BuildRouter(modules *ModuleContainer, cfg *config.Config, logger *logging.L
 	simulador.RegisterRoutes(v1, modules.SimuladorHandler, authMiddleware)
 	participacoes.RegisterRoutes(v1, modules.ParticipacoesHandler, authMiddleware)
 	gestacao.RegisterRoutes(v1, modules.GestacaoHandler, authMiddleware)
-	eventos.RegisterRoutes(v1, modules.EventosHandler, authMiddleware)
+	eventos.RegisterRoutes(v1, modules.EventosHandler, authMiddleware, middleware.RequireEquinoAccess(modules.AcessosService, models.EscopoLerSaude))
 	tokenizacao.RegisterRoutes(v1, modules.TokenizacaoHandler, authMiddleware)
 	leiloes.RegisterRoutes(v1, modules.LeiloesHandler, authMiddleware)
 	exames.RegisterRoutes(v1, modules.ExamesHandler, authMiddleware)
@@ -71,6 +89,25 @@ func BuildRouter(modules *ModuleContainer, cfg *config.Config, logger *logging.L
 	financeiro.RegisterRoutes(v1, modules.FinanceiroHandler, authMiddleware)
 	nutricao.RegisterRoutes(v1, modules.NutricaoHandler, authMiddleware)
 	treinamento.RegisterRoutes(v1, modules.TreinamentoHandler, authMiddleware)
+	acessos.RegisterRoutes(v1, modules.AcessosHandler, authMiddleware)
+	auditoria.RegisterRoutes(v1, modules.AuditoriaHandler, authMiddleware)
+	privacidade.RegisterRoutes(v1, modules.PrivacidadeHandler, authMiddleware)
+	documentos.RegisterRoutes(v1, modules.DocumentosHandler, authMiddleware)
+	passaportes.RegisterRoutes(v1, modules.PassaportesHandler, authMiddleware)
+	verificacao.RegisterRoutes(v1, modules.VerificacaoHandler, middleware.RateLimit(cfg.PublicVerifyRateLimitPerMinute))
+	busca.RegisterRoutes(v1, modules.BuscaHandler, authMiddleware)
+	linhagem.RegisterRoutes(v1, modules.LinhagemHandler, authMiddleware)
+	reproducao.RegisterRoutes(v1, modules.ReproducaoHandler, authMiddleware)
+	valorizacao.RegisterRoutes(v1, modules.ValorizacaoHandler, authMiddleware)
+	social.RegisterRoutes(v1, modules.SocialHandler, authMiddleware)
+	moderacao.RegisterRoutes(v1, modules.ModeracaoHandler, authMiddleware)
+	ofertas.RegisterRoutes(v1, modules.OfertasHandler, authMiddleware)
+	notificacoes.RegisterRoutes(v1, modules.NotificacoesHandler, authMiddleware)
+	marketplace.RegisterRoutes(v1, modules.MarketplaceHandler, authMiddleware)
+	pagamentos.RegisterRoutes(v1, modules.PagamentosHandler, authMiddleware)
+
+	registerPublicPKIRoutes(v1, legacyHandlers)
+	registerPublicWebhookRoutes(v1, legacyHandlers)
 
 	protected := v1.Group("")
 	protected.Use(authMiddleware)
@@ -85,10 +122,6 @@ func createLegacyHandlersAdapter(legacy *LegacyHandlers, db *gorm.DB, logger *lo
 	return &handlers.Handlers{
 		DB:                       db,
 		Logger:                   logger,
-		ValorizacaoService:       legacy.ValorizacaoService,
-		LinhagemService:          legacy.LinhagemService,
-		ReproducaoService:        legacy.ReproducaoService,
-		SocialService:            legacy.SocialService,
 		EventoService:            legacy.EventoService,
 		CertificateService:       legacy.CertificateService,
 		IntegrationService:       legacy.IntegrationService,
@@ -100,6 +133,29 @@ func createLegacyHandlersAdapter(legacy *LegacyHandlers, db *gorm.DB, logger *lo
 		D4SignService:            legacy.D4SignService,
 		LeilaoService:            legacy.LeilaoService,
 		ExameLaboratorialService: legacy.ExameLaboratorialService,
+		PKISignatureService:      legacy.PKISignatureService,
+	}
+}
+
+// registerPublicPKIRoutes CRL, OCSP, certificado da CA e verificação de assinaturas e carimbos de tempo sem autenticação, para terceiros
+func registerPublicPKIRoutes(rg *gin.RouterGroup, h *handlers.Handlers) {
+	pkiGroup := rg.Group("/pki")
+	{
+		pkiGroup.GET("/ca.pem", h.GetCACertificate)
+		pkiGroup.GET("/crl", h.GetCRL)
+		pkiGroup.GET("/crl.pem", h.GetCRL)
+		pkiGroup.POST("/ocsp", h.OCSP)
+		pkiGroup.GET("/ocsp/*request", h.OCSP)
+		pkiGroup.POST("/signatures/verify", h.VerifySignature)
+		pkiGroup.POST("/timestamps/verify", h.VerifyTimestamp)
+	}
+}
+
+// registerPublicWebhookRoutes webhooks de provedores externos, autenticados pelo próprio handler
+func registerPublicWebhookRoutes(rg *gin.RouterGroup, h *handlers.Handlers) {
+	webhooks := rg.Group("/webhooks")
+	{
+		webhooks.POST("/d4sign", h.HandleD4SignWebhook)
 	}
 }
 
@@ -119,6 +175,21 @@ func registerLegacyRoutes(rg *gin.RouterGroup, h *handlers.Handlers) {
 		certificates.POST("/generate", h.GenerateCertificate)
 		certificates.GET("/validate/:serial", h.ValidateCertificate)
 		certificates.POST("/revoke", h.RevokeCertificate)
+		certificates.POST("/renew/:serial", h.RenewCertificate)
+		certificates.GET("/ca", h.GetCACertificate)
+	}
+
+	signatures := rg.Group("/signatures")
+	{
+		signatures.POST("", h.CreateSignature)
+		signatures.GET("/d4sign/:doc_uuid", h.GetD4SignDocument)
+		signatures.POST("/d4sign/:doc_uuid/sync", h.SyncD4SignDocument)
+		signatures.GET("/d4sign/:doc_uuid/file", h.DownloadD4SignSignedFile)
+	}
+
+	tsa := rg.Group("/pki")
+	{
+		tsa.POST("/tsa", h.TimestampRequest)
 	}
 
 
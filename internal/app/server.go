@@ -64,6 +64,20 @@ func Start(deps Dependencies) error {
 	modules := InitializeModules(db, redisClient, logger, cfg)
 	router := BuildRouter(modules, cfg, logger, keycloakAuth, useKeycloak, db)
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	StartAuditCheckpointJob(jobsCtx, modules.AuditLogger, cfg.AuditCheckpointInterval, logger)
+	StartAuditRetentionJob(jobsCtx, modules.AuditLogger, logger)
+	StartPrivacyExportCleanupJob(jobsCtx, modules.LGPDService, logger)
+	StartConsentExpiryJob(jobsCtx, modules.LGPDService, logger)
+	StartCRLPublishJob(jobsCtx, modules.PKIManager, cfg.CRLValidity/2, logger)
+	StartD4SignSyncJob(jobsCtx, modules.LegacyHandlers.D4SignService, cfg.D4SignSyncInterval, logger)
+	StartSocialCountersJob(jobsCtx, modules.SocialService, logger)
+	StartOfertasJob(jobsCtx, modules.OfertasService, logger)
+	StartMarketplaceJob(jobsCtx, modules.MarketplaceService, logger)
+	StartPagamentosJob(jobsCtx, modules.PagamentosService, logger)
+	StartLeiloesJob(jobsCtx, modules.LeiloesService, logger)
+
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.Port),
 		Handler: router,
@@ -77,6 +91,8 @@ func Start(deps Dependencies) error {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	stopJobs()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
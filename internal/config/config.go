@@ -25,26 +25,63 @@ type Config struct {
 	JWTExpireHours time.Duration
 
 	// Segurança
-	BcryptCost         int
-	RateLimitPerMinute int
+	BcryptCost                     int
+	RateLimitPerMinute             int
+	PublicVerifyRateLimitPerMinute int // rotas públicas de verificação, por IP
 
 	// Upload de arquivos
 	UploadMaxSize int64
 	UploadPath    string
 
+	// Cofre de documentos
+	StorageBackend        string // "local" ou "s3"
+	DocumentPublicURL     string
+	DocumentURLTTL        time.Duration
+	DocumentURLSigningKey string
+
 	// AWS S3
-	AWSRegion          string
-	AWSAccessKeyID     string
-	AWSSecretAccessKey string
-	AWSS3Bucket        string
+	AWSRegion           string
+	AWSAccessKeyID      string
+	AWSSecretAccessKey  string
+	AWSS3Bucket         string
+	AWSS3Endpoint       string // endpoint de serviço compatível com S3 (MinIO, R2...); vazio usa a AWS
+	AWSS3ForcePathStyle bool
 
 	// Logs
 	LogLevel string
 	LogFile  string
 
 	// Certificados digitais
-	CACertPath string
-	CAKeyPath  string
+	CACertPath         string
+	CAKeyPath          string
+	CACertValidityDays int
+	CRLValidity        time.Duration
+	PKIPublicURL       string
+
+	// Carimbo de tempo (RFC 3161)
+	TSACertPath         string
+	TSAKeyPath          string
+	TSAPolicyOID        string
+	TSAURL              string
+	TSATrustedCertsPath string
+	TSATimeout          time.Duration
+
+	// Selo institucional e documentos emitidos (passaportes)
+	SealCertPath          string
+	SealKeyPath           string
+	PassportVerifyBaseURL string // endereço público de verificação impresso no QR code
+
+	// Auditoria
+	AuditRetentionDays        int
+	AuditCheckpointInterval   time.Duration
+	AuditCheckpointFile       string
+	AuditCheckpointSigningKey string
+
+	// Privacidade (LGPD)
+	PrivacyConsentRetention time.Duration
+	PrivacyExportDir        string
+	PrivacyExportSigningKey string
+	PrivacyExportTTL        time.Duration
 
 	// Monitoramento
 	MetricsEnabled bool
@@ -62,15 +99,31 @@ type Config struct {
 	GedaveAPIKey string
 
 	// Blockchain
-	BlockchainEnabled  bool
-	EthereumRPCURL     string
-	EthereumPrivateKey string
+	BlockchainEnabled       bool
+	EthereumRPCURL          string
+	EthereumPrivateKey      string
+	EthereumContractAddress string
 
 	// D4Sign
 	D4SignAPIURL   string
 	D4SignTokenAPI string
 	D4SignCryptKey string
 	D4SignSafeUUID string
+
+	D4SignReminderInterval time.Duration
+	D4SignSyncInterval     time.Duration
+	D4SignWebhookURL       string
+	D4SignWebhookSecret    string
+	D4SignWebhookSecrets   string // segredos por cofre: "cofre:segredo,cofre2:segredo2"
+
+	// Pagamentos
+	PaymentProvider           string
+	PaymentWebhookSecret      string
+	PaymentPlatformFeePercent float64
+	PaymentEscrowPeriod       time.Duration // prazo da custódia até o estorno (animais) ou a liberação automática
+
+	// Leilões
+	AuctionPaymentDeadline time.Duration // prazo após o encerramento para pagar os lotes arrematados
 }
 
 // Load carrega as configurações a partir das variáveis de ambiente
@@ -87,22 +140,54 @@ func Load() *Config {
 		JWTSecret:      getEnv("JWT_SECRET", "super-secret-jwt-key-change-in-production"),
 		JWTExpireHours: time.Duration(getEnvAsInt("JWT_EXPIRE_HOURS", 24)) * time.Hour,
 
-		BcryptCost:         getEnvAsInt("BCRYPT_COST", 12),
-		RateLimitPerMinute: getEnvAsInt("RATE_LIMIT_PER_MINUTE", 100),
+		BcryptCost:                     getEnvAsInt("BCRYPT_COST", 12),
+		RateLimitPerMinute:             getEnvAsInt("RATE_LIMIT_PER_MINUTE", 100),
+		PublicVerifyRateLimitPerMinute: getEnvAsInt("PUBLIC_VERIFY_RATE_LIMIT_PER_MINUTE", 20),
 
 		UploadMaxSize: int64(getEnvAsInt("UPLOAD_MAX_SIZE", 10485760)), // 10MB
 		UploadPath:    getEnv("UPLOAD_PATH", "./uploads"),
 
-		AWSRegion:          getEnv("AWS_REGION", "us-east-1"),
-		AWSAccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
-		AWSSecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
-		AWSS3Bucket:        getEnv("AWS_S3_BUCKET", "equinoid-documents"),
+		StorageBackend:        getEnv("STORAGE_BACKEND", "local"),
+		DocumentPublicURL:     getEnv("DOCUMENT_PUBLIC_URL", "http://localhost:8080/api/v1/public/documentos/arquivo"),
+		DocumentURLTTL:        time.Duration(getEnvAsInt("DOCUMENT_URL_TTL_MINUTES", 15)) * time.Minute,
+		DocumentURLSigningKey: getEnv("DOCUMENT_URL_SIGNING_KEY", ""),
+
+		AWSRegion:           getEnv("AWS_REGION", "us-east-1"),
+		AWSAccessKeyID:      getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey:  getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		AWSS3Bucket:         getEnv("AWS_S3_BUCKET", "equinoid-documents"),
+		AWSS3Endpoint:       getEnv("AWS_S3_ENDPOINT", ""),
+		AWSS3ForcePathStyle: getEnvAsBool("AWS_S3_FORCE_PATH_STYLE", false),
 
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 		LogFile:  getEnv("LOG_FILE", "./logs/equinoid.log"),
 
-		CACertPath: getEnv("CA_CERT_PATH", "./certs/ca-cert.pem"),
-		CAKeyPath:  getEnv("CA_KEY_PATH", "./certs/ca-key.pem"),
+		CACertPath:         getEnv("CA_CERT_PATH", "./certs/ca-cert.pem"),
+		CAKeyPath:          getEnv("CA_KEY_PATH", "./certs/ca-key.pem"),
+		CACertValidityDays: getEnvAsInt("CA_CERT_VALIDITY_DAYS", 365),
+		CRLValidity:        time.Duration(getEnvAsInt("CRL_VALIDITY_HOURS", 24)) * time.Hour,
+		PKIPublicURL:       getEnv("PKI_PUBLIC_URL", "http://localhost:8080/api/v1/pki"),
+
+		TSACertPath:         getEnv("TSA_CERT_PATH", "./certs/tsa-cert.pem"),
+		TSAKeyPath:          getEnv("TSA_KEY_PATH", "./certs/tsa-key.pem"),
+		TSAPolicyOID:        getEnv("TSA_POLICY_OID", "1.3.6.1.4.1.32473.3161.1"),
+		TSAURL:              getEnv("TSA_URL", ""),
+		TSATrustedCertsPath: getEnv("TSA_TRUSTED_CERTS", ""),
+		TSATimeout:          time.Duration(getEnvAsInt("TSA_TIMEOUT_SECONDS", 10)) * time.Second,
+
+		SealCertPath:          getEnv("SEAL_CERT_PATH", "./certs/seal-cert.pem"),
+		SealKeyPath:           getEnv("SEAL_KEY_PATH", "./certs/seal-key.pem"),
+		PassportVerifyBaseURL: getEnv("PASSPORT_VERIFY_BASE_URL", "http://localhost:8080/api/v1/public/passaportes"),
+
+		AuditRetentionDays:        getEnvAsInt("AUDIT_RETENTION_DAYS", 1825),
+		AuditCheckpointInterval:   time.Duration(getEnvAsInt("AUDIT_CHECKPOINT_INTERVAL_MINUTES", 60)) * time.Minute,
+		AuditCheckpointFile:       getEnv("AUDIT_CHECKPOINT_FILE", "./data/audit-checkpoints.jsonl"),
+		AuditCheckpointSigningKey: getEnv("AUDIT_CHECKPOINT_SIGNING_KEY", ""),
+
+		PrivacyConsentRetention: time.Duration(getEnvAsInt("PRIVACY_CONSENT_RETENTION_DAYS", 730)) * 24 * time.Hour,
+		PrivacyExportDir:        getEnv("PRIVACY_EXPORT_DIR", "./data/privacy-exports"),
+		PrivacyExportSigningKey: getEnv("PRIVACY_EXPORT_SIGNING_KEY", ""),
+		PrivacyExportTTL:        time.Duration(getEnvAsInt("PRIVACY_EXPORT_TTL_DAYS", 7)) * 24 * time.Hour,
 
 		MetricsEnabled: getEnvAsBool("METRICS_ENABLED", true),
 		MetricsPath:    getEnv("METRICS_PATH", "/metrics"),
@@ -116,14 +201,28 @@ func Load() *Config {
 		GedaveAPIURL: getEnv("GEDAVE_API_URL", "https://gedave.gov.br/api"),
 		GedaveAPIKey: getEnv("GEDAVE_API_KEY", ""),
 
-		BlockchainEnabled:  getEnvAsBool("BLOCKCHAIN_ENABLED", false),
-		EthereumRPCURL:     getEnv("ETHEREUM_RPC_URL", ""),
-		EthereumPrivateKey: getEnv("ETHEREUM_PRIVATE_KEY", ""),
+		BlockchainEnabled:       getEnvAsBool("BLOCKCHAIN_ENABLED", false),
+		EthereumRPCURL:          getEnv("ETHEREUM_RPC_URL", ""),
+		EthereumPrivateKey:      getEnv("ETHEREUM_PRIVATE_KEY", ""),
+		EthereumContractAddress: getEnv("ETHEREUM_CONTRACT_ADDRESS", ""),
 
 		D4SignAPIURL:   getEnv("D4SIGN_API_URL", "https://sandbox.d4sign.com.br/api/v1"),
 		D4SignTokenAPI: getEnv("D4SIGN_TOKEN_API", ""),
 		D4SignCryptKey: getEnv("D4SIGN_CRYPT_KEY", ""),
 		D4SignSafeUUID: getEnv("D4SIGN_SAFE_UUID", ""),
+
+		D4SignReminderInterval: time.Duration(getEnvAsInt("D4SIGN_REMINDER_INTERVAL_HOURS", 48)) * time.Hour,
+		D4SignSyncInterval:     time.Duration(getEnvAsInt("D4SIGN_SYNC_INTERVAL_MINUTES", 30)) * time.Minute,
+		D4SignWebhookURL:       getEnv("D4SIGN_WEBHOOK_URL", ""),
+		D4SignWebhookSecret:    getEnv("D4SIGN_WEBHOOK_SECRET", ""),
+		D4SignWebhookSecrets:   getEnv("D4SIGN_WEBHOOK_SECRETS", ""),
+
+		PaymentProvider:           getEnv("PAYMENT_PROVIDER", "fake"),
+		PaymentWebhookSecret:      getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		PaymentPlatformFeePercent: getEnvAsFloat("PAYMENT_PLATFORM_FEE_PERCENT", 2.5),
+		PaymentEscrowPeriod:       time.Duration(getEnvAsInt("PAYMENT_ESCROW_DAYS", 30)) * 24 * time.Hour,
+
+		AuctionPaymentDeadline: time.Duration(getEnvAsInt("AUCTION_PAYMENT_DAYS", 5)) * 24 * time.Hour,
 	}
 }
 
@@ -144,6 +243,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsFloat obtém variável de ambiente como float ou retorna valor padrão
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 // getEnvAsBool obtém variável de ambiente como boolean ou retorna valor padrão
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := getEnv(key, "")
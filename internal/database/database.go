@@ -45,7 +45,18 @@ func RunMigrations(db *gorm.DB) error {
 		// Modelos base
 		&models.User{},
 		&models.Certificate{},
+		&models.CertificateRevocationList{},
+		&models.DigitalSignature{},
+		&models.BiometricData{},
+		&models.D4SignDocument{},
+		&models.D4SignDocumentSigner{},
+		&models.D4SignWebhookLog{},
+		&models.D4SignWebhookEvent{},
+		&models.Documento{},
+		&models.DocumentoVersao{},
+		&models.PassaporteEquino{},
 		&models.Equino{},
+		&models.EquinoIdentificador{},
 		&models.Propriedade{},
 		&models.EquinoVeterinario{},
 		&models.Evento{},
@@ -79,6 +90,29 @@ func RunMigrations(db *gorm.DB) error {
 		&models.SeguirEquino{},
 		&models.Oferta{},
 
+		// Modelos de moderação da rede social
+		&models.RegraModeracao{},
+		&models.CasoModeracao{},
+		&models.DenunciaConteudo{},
+		&models.RecursoModeracao{},
+		&models.InfracaoModeracao{},
+
+		// Notificações internas dos usuários
+		&models.Notificacao{},
+
+		// Leilões, lotes, catálogos publicados e habilitação de arrematantes
+		&models.Leilao{},
+		&models.ParticipacaoLeilao{},
+		&models.SessaoLeilao{},
+		&models.CatalogoLeilao{},
+		&models.HabilitacaoLeilao{},
+		&models.DocumentoHabilitacao{},
+
+		// Pagamentos em custódia e lançamentos financeiros
+		&models.TransacaoFinanceira{},
+		&models.Pagamento{},
+		&models.RepassePagamento{},
+
 		// Modelos adicionais
 		&models.RegistroMidia{},
 		&models.RegistroSaude{},
@@ -89,6 +123,12 @@ func RunMigrations(db *gorm.DB) error {
 		&models.PerformanceReprodutiva{},
 		&models.Webhook{},
 		&models.ChatbotQuery{},
+
+		// Modelos de segurança
+		&models.AuditLog{},
+		&models.AuditCheckpoint{},
+		&models.ComplianceRecord{},
+		&models.PoliticaConsentimento{},
 	}
 
 	// Executar auto-migração para todos os modelos
@@ -1,22 +1,25 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/equinoid/backend/internal/middleware"
 	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/ocsp"
 )
 
-// GenerateCertificate gera um novo certificado para um equino
+// GenerateCertificate emite um certificado X.509 para um equino pela CA interna
 func (h *Handlers) GenerateCertificate(c *gin.Context) {
-	var req struct {
-		Equinoid        string `json:"equinoid" binding:"required"`
-		TipoCertificado string `json:"tipo_certificado" binding:"required"`
-		ValidDays       int    `json:"valid_days"`
-	}
-
+	var req models.GenerateCertificateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success:   false,
@@ -27,28 +30,52 @@ func (h *Handlers) GenerateCertificate(c *gin.Context) {
 	}
 
 	userID, _ := middleware.GetUserIDFromContext(c)
-	if req.ValidDays <= 0 {
-		req.ValidDays = 365
-	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
 
-	cert, err := h.CertificateService.GenerateCertificate(c.Request.Context(), userID, req.Equinoid, req.TipoCertificado, req.ValidDays)
+	cert, err := h.CertificateService.GenerateCertificate(c.Request.Context(), userID, userType, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao gerar certificado",
-			Timestamp: time.Now(),
-		})
+		certificateError(c, err, "Erro ao gerar certificado")
 		return
 	}
 
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Success:   true,
-		Data:      cert.ToResponse(),
+		Data:      cert,
 		Message:   "Certificado gerado com sucesso",
 		Timestamp: time.Now(),
 	})
 }
 
+// RenewCertificate renova um certificado próximo do vencimento
+func (h *Handlers) RenewCertificate(c *gin.Context) {
+	var req models.RenewCertificateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success:   false,
+				Error:     "Dados inválidos",
+				Timestamp: time.Now(),
+			})
+			return
+		}
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+
+	cert, err := h.CertificateService.RenewCertificate(c.Request.Context(), userID, c.Param("serial"), &req)
+	if err != nil {
+		certificateError(c, err, "Erro ao renovar certificado")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Data:      cert,
+		Message:   "Certificado renovado com sucesso",
+		Timestamp: time.Now(),
+	})
+}
+
 // ListCertificates lista os certificados do usuário autenticado
 func (h *Handlers) ListCertificates(c *gin.Context) {
 	userID, _ := middleware.GetUserIDFromContext(c)
@@ -75,27 +102,91 @@ func (h *Handlers) ListCertificates(c *gin.Context) {
 	})
 }
 
-// ValidateCertificate verifica a validade de um certificado
+// ValidateCertificate verifica validade, revogação e cadeia de um certificado
 func (h *Handlers) ValidateCertificate(c *gin.Context) {
 	serial := c.Param("serial")
 
-	cert, err := h.CertificateService.ValidateCertificate(c.Request.Context(), serial)
+	result, err := h.CertificateService.ValidateCertificate(c.Request.Context(), serial)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Success:   false,
-			Error:     "Certificado não encontrado",
-			Timestamp: time.Now(),
-		})
+		certificateError(c, err, "Erro ao validar certificado")
 		return
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success:   true,
-		Data:      cert.ToResponse(),
+		Data:      result,
 		Timestamp: time.Now(),
 	})
 }
 
+// GetCACertificate retorna o certificado da CA interna em PEM
+func (h *Handlers) GetCACertificate(c *gin.Context) {
+	caPEM, err := h.CertificateService.CACertificate()
+	if err != nil {
+		certificateError(c, err, "Erro ao obter certificado da CA")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/x-pem-file", caPEM)
+}
+
+// maxOCSPRequestSize limite do corpo de uma requisição OCSP
+const maxOCSPRequestSize = 10 * 1024
+
+// GetCRL publica a lista de certificados revogados vigente em DER (ou PEM em /crl.pem)
+func (h *Handlers) GetCRL(c *gin.Context) {
+	crl, err := h.CertificateService.CurrentCRL(c.Request.Context())
+	if err != nil {
+		certificateError(c, err, "Erro ao obter CRL")
+		return
+	}
+
+	maxAge := int(time.Until(crl.NextUpdate).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+	c.Header("Last-Modified", crl.ThisUpdate.UTC().Format(http.TimeFormat))
+	c.Header("Expires", crl.NextUpdate.UTC().Format(http.TimeFormat))
+
+	if strings.HasSuffix(c.Request.URL.Path, ".pem") {
+		c.Data(http.StatusOK, "application/x-pem-file", pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl.CRLDER}))
+		return
+	}
+	c.Data(http.StatusOK, "application/pkix-crl", crl.CRLDER)
+}
+
+// OCSP responde consultas de status de certificados (RFC 6960) via POST ou GET com a requisição em base64 na URL
+func (h *Handlers) OCSP(c *gin.Context) {
+	var requestDER []byte
+	if c.Request.Method == http.MethodGet {
+		encoded, err := url.PathUnescape(strings.TrimPrefix(c.Param("request"), "/"))
+		if err == nil {
+			requestDER, err = base64.StdEncoding.DecodeString(encoded)
+		}
+		if err != nil {
+			c.Data(http.StatusOK, "application/ocsp-response", ocsp.MalformedRequestErrorResponse)
+			return
+		}
+	} else {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxOCSPRequestSize))
+		if err != nil {
+			c.Data(http.StatusOK, "application/ocsp-response", ocsp.MalformedRequestErrorResponse)
+			return
+		}
+		requestDER = body
+	}
+
+	response, err := h.CertificateService.OCSPResponse(c.Request.Context(), requestDER)
+	if err != nil {
+		certificateError(c, err, "Erro ao responder OCSP")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "application/ocsp-response", response)
+}
+
 // RevokeCertificate revoga um certificado existente
 func (h *Handlers) RevokeCertificate(c *gin.Context) {
 	var req struct {
@@ -112,13 +203,12 @@ func (h *Handlers) RevokeCertificate(c *gin.Context) {
 		return
 	}
 
-	err := h.CertificateService.RevokeCertificate(c.Request.Context(), req.SerialNumber, req.Motivo)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	userType, _ := middleware.GetUserTypeFromContext(c)
+
+	err := h.CertificateService.RevokeCertificate(c.Request.Context(), userID, userType, req.SerialNumber, req.Motivo)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao revogar certificado",
-			Timestamp: time.Now(),
-		})
+		certificateError(c, err, "Erro ao revogar certificado")
 		return
 	}
 
@@ -128,3 +218,27 @@ func (h *Handlers) RevokeCertificate(c *gin.Context) {
 		Timestamp: time.Now(),
 	})
 }
+
+func certificateError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status, message = http.StatusBadRequest, err.Error()
+	case apperrors.IsAuthorization(err):
+		status, message = http.StatusForbidden, err.Error()
+	case apperrors.IsNotFound(err):
+		status, message = http.StatusNotFound, err.Error()
+	case apperrors.IsConflict(err):
+		status, message = http.StatusConflict, err.Error()
+	case apperrors.IsBusiness(err):
+		status, message = http.StatusServiceUnavailable, err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/equinoid/backend/internal/middleware"
 	"github.com/equinoid/backend/internal/models"
 	"github.com/gin-gonic/gin"
 )
@@ -102,3 +103,82 @@ func (h *Handlers) GetD4SignEmbedURL(c *gin.Context) {
 		Timestamp: time.Now(),
 	})
 }
+
+// GetD4SignDocument documento D4Sign com a ordem e o status de cada signatário
+func (h *Handlers) GetD4SignDocument(c *gin.Context) {
+	userID, isAdmin, ok := d4signRequester(c)
+	if !ok {
+		return
+	}
+
+	doc, err := h.D4SignService.GetDocumentForUser(c.Request.Context(), c.Param("doc_uuid"), userID, isAdmin)
+	if err != nil {
+		certificateError(c, err, "Erro ao buscar documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Data:      doc,
+		Timestamp: time.Now(),
+	})
+}
+
+// SyncD4SignDocument consulta a D4Sign e atualiza o documento e os signatários, sem esperar pelos webhooks
+func (h *Handlers) SyncD4SignDocument(c *gin.Context) {
+	userID, isAdmin, ok := d4signRequester(c)
+	if !ok {
+		return
+	}
+
+	docUUID := c.Param("doc_uuid")
+	if _, err := h.D4SignService.GetDocumentForUser(c.Request.Context(), docUUID, userID, isAdmin); err != nil {
+		certificateError(c, err, "Erro ao buscar documento")
+		return
+	}
+
+	doc, err := h.D4SignService.SyncDocument(c.Request.Context(), docUUID)
+	if err != nil {
+		certificateError(c, err, "Erro ao sincronizar documento com a D4Sign")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Data:      doc,
+		Message:   "Documento sincronizado",
+		Timestamp: time.Now(),
+	})
+}
+
+// DownloadD4SignSignedFile PDF final assinado por todos, baixado da D4Sign
+func (h *Handlers) DownloadD4SignSignedFile(c *gin.Context) {
+	userID, isAdmin, ok := d4signRequester(c)
+	if !ok {
+		return
+	}
+
+	doc, content, err := h.D4SignService.SignedFile(c.Request.Context(), c.Param("doc_uuid"), userID, isAdmin)
+	if err != nil {
+		certificateError(c, err, "Erro ao obter documento assinado")
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\""+doc.DocumentUUID+".pdf\"")
+	c.Header("X-Content-SHA256", doc.SignedFileHash)
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
+func d4signRequester(c *gin.Context) (uint, bool, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Usuário não autenticado",
+			Timestamp: time.Now(),
+		})
+		return 0, false, false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	return userID, userType == string(models.UserTypeAdmin), true
+}
@@ -12,10 +12,6 @@ type Handlers struct {
 	
 	EventoService            *services.EventoService
 	CertificateService       *services.CertificateService
-	ValorizacaoService       *services.ValorizacaoService
-	LinhagemService          *services.LinhagemService
-	ReproducaoService        *services.ReproducaoService
-	SocialService            *services.SocialService
 	IntegrationService       *services.IntegrationService
 	ReportService            *services.ReportService
 	SearchService            *services.SearchService
@@ -25,4 +21,5 @@ type Handlers struct {
 	D4SignService            *services.D4SignService
 	LeilaoService            *services.LeilaoService
 	ExameLaboratorialService *services.ExameLaboratorialService
+	PKISignatureService      *services.PKISignatureService
 }
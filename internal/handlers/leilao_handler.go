@@ -62,18 +62,6 @@ func (h *Handlers) CreateLeilao(c *gin.Context) {
 	c.JSON(http.StatusCreated, leilao)
 }
 
-func (h *Handlers) FinalizarLeilao(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
-
-	leilao, err := h.LeilaoService.Finalizar(uint(id))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, leilao)
-}
-
 func (h *Handlers) ListParticipacoes(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
 
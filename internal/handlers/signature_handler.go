@@ -1,17 +1,21 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/equinoid/backend/internal/models"
 	"github.com/equinoid/backend/internal/services"
+	apperrors "github.com/equinoid/backend/pkg/errors"
 	"github.com/gin-gonic/gin"
 )
 
 type CreateSignatureRequest struct {
 	DocumentType      string                 `json:"document_type" binding:"required"`
-	DocumentHash      string                 `json:"document_hash" binding:"required"`
+	DocumentHash      string                 `json:"document_hash"`
 	DocumentData      map[string]interface{} `json:"document_data"`
 	RelatedEntityID   *uint                  `json:"related_entity_id"`
 	RelatedEntityType string                 `json:"related_entity_type"`
@@ -19,6 +23,8 @@ type CreateSignatureRequest struct {
 	Base64File        string                 `json:"base64_file,omitempty"`
 	BiometricData     []byte                 `json:"biometric_data,omitempty"`
 	CertificateID     *uint                  `json:"certificate_id,omitempty"`
+	Location          string                 `json:"location,omitempty"`
+	Sequential        *bool                  `json:"sequential,omitempty"` // D4Sign: assinatura na ordem dos signatários
 }
 
 type SignatureResponse struct {
@@ -29,6 +35,15 @@ type SignatureResponse struct {
 	Status        string `json:"status"`
 	Message       string `json:"message"`
 	EmbedURL      string `json:"embed_url,omitempty"`
+
+	SignatureID       uint       `json:"signature_id,omitempty"`
+	CertificateSerial string     `json:"certificate_serial,omitempty"`
+	CAdES             string     `json:"cades,omitempty"`      // CMS destacada em base64
+	SignedPDF         string     `json:"signed_pdf,omitempty"` // PDF com PAdES embutida em base64
+	SignedAt          *time.Time `json:"signed_at,omitempty"`
+
+	TimestampTime      *time.Time `json:"timestamp_time,omitempty"`
+	TimestampAuthority string     `json:"timestamp_authority,omitempty"`
 }
 
 func (h *Handlers) CreateSignature(c *gin.Context) {
@@ -79,136 +94,282 @@ func (h *Handlers) createD4SignSignature(c *gin.Context, req CreateSignatureRequ
 		RelatedEntityID:   req.RelatedEntityID,
 		RelatedEntityType: req.RelatedEntityType,
 		Signers:           req.Signers,
+		Sequential:        req.Sequential,
 	}
 
-	doc, err := h.D4SignService.CreateDocument(c.Request.Context(), "", d4SignReq, userID)
+	doc, err := h.D4SignService.CreateContract(c.Request.Context(), d4SignReq, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao criar documento na D4Sign: " + err.Error(),
-			Timestamp: time.Now(),
-		})
+		certificateError(c, err, "Erro ao enviar documento para assinatura na D4Sign")
 		return
 	}
 
-	if err := h.D4SignService.AddSigners(c.Request.Context(), doc.DocumentUUID, req.Signers); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao adicionar signatários: " + err.Error(),
-			Timestamp: time.Now(),
-		})
-		return
+	firstSigner := req.Signers[0].Email
+	if len(doc.Signers) > 0 {
+		firstSigner = doc.Signers[0].Email
 	}
-
-	if err := h.D4SignService.SendToSign(c.Request.Context(), doc.DocumentUUID); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao enviar documento para assinatura: " + err.Error(),
-			Timestamp: time.Now(),
-		})
-		return
-	}
-
-	embedURL, _ := h.D4SignService.GetEmbedURL(c.Request.Context(), doc.DocumentUUID, req.Signers[0].Email)
+	embedURL, _ := h.D4SignService.GetEmbedURL(c.Request.Context(), doc.DocumentUUID, firstSigner)
 
 	c.JSON(http.StatusCreated, SignatureResponse{
 		Success:      true,
 		Method:       "d4sign",
 		DocumentUUID: doc.DocumentUUID,
-		Status:       "pending",
+		Status:       doc.Status,
 		Message:      "Documento criado e enviado para assinatura via D4Sign",
 		EmbedURL:     embedURL,
 	})
 }
 
 func (h *Handlers) createPKISignature(c *gin.Context, req CreateSignatureRequest, userID uint) {
-	if req.BiometricData == nil || req.CertificateID == nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Success:   false,
-			Error:     "Para assinatura PKI, biometric_data e certificate_id são obrigatórios",
-			Timestamp: time.Now(),
-		})
+	var document []byte
+	if req.Base64File != "" {
+		decoded, err := base64.StdEncoding.DecodeString(req.Base64File)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success:   false,
+				Error:     "base64_file inválido",
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		document = decoded
+	}
+
+	result, err := h.PKISignatureService.Sign(c.Request.Context(), &services.SignatureRequest{
+		DocumentType:      req.DocumentType,
+		DocumentHash:      req.DocumentHash,
+		DocumentData:      req.DocumentData,
+		Document:          document,
+		Location:          req.Location,
+		SignerID:          userID,
+		RelatedEntityID:   req.RelatedEntityID,
+		RelatedEntityType: req.RelatedEntityType,
+		BiometricData:     req.BiometricData,
+		CertificateID:     req.CertificateID,
+	})
+	if err != nil {
+		certificateError(c, err, "Erro ao assinar documento")
 		return
 	}
 
-	var cert models.Certificate
-	if err := h.DB.First(&cert, *req.CertificateID).Error; err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
+	response := SignatureResponse{
+		Success:           true,
+		Method:            result.Method,
+		DocumentUUID:      result.DocumentUUID,
+		SignatureHash:     result.SignatureHash,
+		Status:            result.Status,
+		Message:           result.Message,
+		SignatureID:       result.SignatureID,
+		CertificateSerial: result.CertificateSerial,
+		CAdES:             base64.StdEncoding.EncodeToString(result.CAdES),
+		SignedAt:          &result.SignedAt,
+
+		TimestampTime:      result.TimestampTime,
+		TimestampAuthority: result.TimestampAuthority,
+	}
+	if len(result.SignedPDF) > 0 {
+		response.SignedPDF = base64.StdEncoding.EncodeToString(result.SignedPDF)
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// maxSignedFileSize limite dos arquivos enviados para verificação de assinatura
+const maxSignedFileSize = 20 << 20
+
+// VerifySignature verifica um arquivo assinado: PDF com assinaturas PAdES ou qualquer arquivo com a CAdES destacada
+// enviada no campo "signature"
+func (h *Handlers) VerifySignature(c *gin.Context) {
+	file, err := readFormFile(c, "file")
+	if err != nil || len(file) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success:   false,
-			Error:     "Certificado não encontrado",
+			Error:     "Envie o arquivo assinado no campo file (até 20 MB)",
 			Timestamp: time.Now(),
 		})
 		return
 	}
 
-	if cert.UserID != userID {
-		c.JSON(http.StatusForbidden, models.ErrorResponse{
-			Success:   false,
-			Error:     "Certificado não pertence ao usuário",
-			Timestamp: time.Now(),
-		})
+	var signature []byte
+	if _, err := c.FormFile("signature"); err == nil {
+		signature, err = readFormFile(c, "signature")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success:   false,
+				Error:     "Assinatura inválida",
+				Timestamp: time.Now(),
+			})
+			return
+		}
+	} else if value := c.PostForm("signature"); value != "" {
+		signature = []byte(value)
+	}
+
+	result, err := h.PKISignatureService.Verify(c.Request.Context(), file, signature)
+	if err != nil {
+		certificateError(c, err, "Erro ao verificar assinatura")
 		return
 	}
 
-	c.JSON(http.StatusNotImplemented, models.ErrorResponse{
-		Success:   false,
-		Error:     "Assinatura PKI interna será implementada em breve. Use o endpoint específico de assinatura biométrica.",
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Data:      result,
 		Timestamp: time.Now(),
 	})
 }
 
-func (h *Handlers) HandleD4SignWebhook(c *gin.Context) {
-	var payload models.D4SignWebhookPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+// maxTimestampQuerySize limite do corpo de uma requisição RFC 3161
+const maxTimestampQuerySize = 10 * 1024
+
+// TimestampRequest atende requisições RFC 3161 (application/timestamp-query) pela TSA interna
+func (h *Handlers) TimestampRequest(c *gin.Context) {
+	if contentType := c.ContentType(); contentType != "application/timestamp-query" {
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{
 			Success:   false,
-			Error:     "Payload inválido: " + err.Error(),
+			Error:     "Envie a requisição com Content-Type application/timestamp-query",
 			Timestamp: time.Now(),
 		})
 		return
 	}
 
-	_, err := h.D4SignService.GetDocumentByUUID(c.Request.Context(), payload.Document.UUID)
-	if err != nil {
-		h.Logger.WithContext(c.Request.Context()).Errorf("Documento não encontrado no webhook: %s", payload.Document.UUID)
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxTimestampQuerySize+1))
+	if err != nil || len(body) == 0 || len(body) > maxTimestampQuerySize {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success:   false,
-			Error:     "Documento não encontrado",
+			Error:     "Requisição de carimbo de tempo inválida",
 			Timestamp: time.Now(),
 		})
 		return
 	}
 
-	var signedAt *time.Time
-	if payload.Event == "document_signed" {
-		now := time.Now()
-		signedAt = &now
+	response, err := h.PKISignatureService.RespondTimestamp(c.Request.Context(), body)
+	if err != nil {
+		certificateError(c, err, "Erro ao emitir carimbo de tempo")
+		return
 	}
 
-	status := "pending"
-	switch payload.Event {
-	case "document_signed":
-		status = "signed"
-	case "document_cancelled":
-		status = "cancelled"
-	case "document_expired":
-		status = "expired"
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/timestamp-reply", response)
+}
+
+// VerifyTimestamp verifica um carimbo de tempo (campo "token") contra o arquivo ("file") ou o SHA-256 ("hash")
+func (h *Handlers) VerifyTimestamp(c *gin.Context) {
+	var token []byte
+	if _, err := c.FormFile("token"); err == nil {
+		token, err = readFormFile(c, "token")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success:   false,
+				Error:     "Carimbo de tempo inválido",
+				Timestamp: time.Now(),
+			})
+			return
+		}
+	} else if value := c.PostForm("token"); value != "" {
+		token = []byte(value)
 	}
 
-	if err := h.D4SignService.UpdateDocumentStatus(c.Request.Context(), payload.Document.UUID, status, signedAt); err != nil {
-		h.Logger.WithContext(c.Request.Context()).Errorf("Erro ao atualizar status do documento: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+	var file []byte
+	if _, err := c.FormFile("file"); err == nil {
+		file, err = readFormFile(c, "file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success:   false,
+				Error:     "Envie o arquivo carimbado no campo file (até 20 MB)",
+				Timestamp: time.Now(),
+			})
+			return
+		}
+	}
+
+	result, err := h.PKISignatureService.VerifyTimestamp(c.Request.Context(), token, file, c.PostForm("hash"))
+	if err != nil {
+		certificateError(c, err, "Erro ao verificar carimbo de tempo")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+}
+
+func readFormFile(c *gin.Context, field string) ([]byte, error) {
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+	if header.Size > maxSignedFileSize {
+		return nil, fmt.Errorf("file too large")
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(io.LimitReader(f, maxSignedFileSize))
+}
+
+// maxD4SignWebhookSize limite do corpo dos webhooks da D4Sign
+const maxD4SignWebhookSize = 1 << 20
+
+// HandleD4SignWebhook recebe eventos da D4Sign autenticados por HMAC (Content-Hmac) ou pelo segredo do cofre
+// (X-D4Sign-Secret ou ?token=); reenvios e eventos fora de ordem respondem 200 sem alterar o documento
+func (h *Handlers) HandleD4SignWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxD4SignWebhookSize+1))
+	if err != nil || len(body) > maxD4SignWebhookSize {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success:   false,
-			Error:     "Erro ao processar webhook",
+			Error:     "Payload inválido",
 			Timestamp: time.Now(),
 		})
 		return
 	}
 
-	h.Logger.WithContext(c.Request.Context()).Infof("Webhook processado: documento %s, evento %s, status %s", payload.Document.UUID, payload.Event, status)
+	secret := c.GetHeader(services.D4SignSecretHeader)
+	if secret == "" {
+		secret = c.Query("token")
+	}
+
+	result, err := h.D4SignService.ReceiveWebhook(c.Request.Context(), &services.D4SignWebhookRequest{
+		Body:      body,
+		Signature: c.GetHeader(services.D4SignHMACHeader),
+		Secret:    secret,
+		EventID:   c.GetHeader(services.D4SignEventIDHeader),
+		RemoteIP:  c.ClientIP(),
+	})
+	if err != nil {
+		switch {
+		case apperrors.IsAuthorization(err):
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Success:   false,
+				Error:     "Webhook não autenticado",
+				Timestamp: time.Now(),
+			})
+		case apperrors.IsValidation(err):
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success:   false,
+				Error:     err.Error(),
+				Timestamp: time.Now(),
+			})
+		default:
+			h.Logger.WithContext(c.Request.Context()).Errorf("Erro ao processar webhook D4Sign: %v", err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success:   false,
+				Error:     "Erro ao processar webhook",
+				Timestamp: time.Now(),
+			})
+		}
+		return
+	}
+
+	h.Logger.WithContext(c.Request.Context()).Infof("Webhook D4Sign %s: evento %s, resultado %s", result.Document.DocumentUUID, result.EventID, result.Status)
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success:   true,
+		Data:      result,
 		Message:   "Webhook processado com sucesso",
 		Timestamp: time.Now(),
 	})
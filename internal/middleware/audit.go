@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditRecorder registra chamadas de API que alteram estado na trilha de auditoria
+type AuditRecorder interface {
+	LogAPIRequest(ctx context.Context, actorID *uint, method, route string, status int, details map[string]interface{}) error
+}
+
+// AuditTrail middleware que registra toda chamada mutável (POST, PUT, PATCH, DELETE) no log de auditoria
+func AuditTrail(recorder AuditRecorder, logger *logging.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		// Disponibiliza a requisição para que o AuditLogger extraia IP e User-Agent
+		ctx := context.WithValue(c.Request.Context(), "http_request", c.Request)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		var actorID *uint
+		if userID, exists := GetUserIDFromContext(c); exists {
+			actorID = &userID
+		}
+
+		details := map[string]interface{}{
+			"path": c.Request.URL.Path,
+		}
+		if requestID, exists := c.Get("request_id"); exists {
+			details["request_id"] = requestID
+		}
+		if len(c.Errors) > 0 {
+			details["errors"] = c.Errors.String()
+		}
+
+		if err := recorder.LogAPIRequest(c.Request.Context(), actorID, c.Request.Method, route, c.Writer.Status(), details); err != nil {
+			logger.LogError(err, "AuditTrail", logging.Fields{"route": route, "method": c.Request.Method})
+		}
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// EquinoAccessChecker decide se um usuário pode exercer um escopo sobre um equino
+type EquinoAccessChecker interface {
+	CheckEquinoAccess(ctx context.Context, userID uint, userType string, equinoid string, escopo string) error
+}
+
+// RequireEquinoAccess middleware que exige acesso ao equino do parâmetro :equinoid no escopo informado
+func RequireEquinoAccess(checker EquinoAccessChecker, escopo string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserIDFromContext(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Authentication required",
+			})
+			c.Abort()
+			return
+		}
+
+		userType, _ := GetUserTypeFromContext(c)
+
+		if err := checker.CheckEquinoAccess(c.Request.Context(), userID, userType, c.Param("equinoid"), escopo); err != nil {
+			status := http.StatusForbidden
+			message := "Insufficient permissions"
+			if apperrors.IsNotFound(err) {
+				status = http.StatusNotFound
+				message = "Equino não encontrado"
+			} else if !apperrors.IsAuthorization(err) {
+				status = http.StatusInternalServerError
+				message = "Erro ao verificar permissões"
+			}
+
+			c.JSON(status, gin.H{
+				"success": false,
+				"error":   message,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
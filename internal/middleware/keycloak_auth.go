@@ -96,6 +96,10 @@ func (k *KeycloakAuth) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		reqCtx := context.WithValue(c.Request.Context(), "user_id", user.ID)
+		reqCtx = context.WithValue(reqCtx, "user_email", claims.Email)
+		c.Request = c.Request.WithContext(reqCtx)
+
 		c.Set("user_id", user.ID)
 		c.Set("keycloak_sub", claims.Sub)
 		c.Set("user_email", claims.Email)
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// PapelDelegado define o papel do profissional com acesso delegado ao equino
+type PapelDelegado string
+
+const (
+	PapelVeterinario   PapelDelegado = "veterinario"
+	PapelLaboratorio   PapelDelegado = "laboratorio"
+	PapelTreinador     PapelDelegado = "treinador"
+	PapelNutricionista PapelDelegado = "nutricionista"
+)
+
+// StatusDelegado define o ciclo de vida de um acesso delegado
+type StatusDelegado string
+
+const (
+	StatusDelegadoPendente StatusDelegado = "pendente"
+	StatusDelegadoAtivo    StatusDelegado = "ativo"
+	StatusDelegadoRecusado StatusDelegado = "recusado"
+	StatusDelegadoRevogado StatusDelegado = "revogado"
+	StatusDelegadoExpirado StatusDelegado = "expirado"
+)
+
+// Escopos de acesso delegado
+const (
+	EscopoLerSaude         = "ler_saude"
+	EscopoEscreverExames   = "escrever_exames"
+	EscopoAssinarRegistros = "assinar_registros"
+)
+
+// EscoposValidos lista os escopos aceitos em um convite
+var EscoposValidos = []string{EscopoLerSaude, EscopoEscreverExames, EscopoAssinarRegistros}
+
+// IsValidPapelDelegado verifica se o papel informado é suportado
+func IsValidPapelDelegado(papel PapelDelegado) bool {
+	switch papel {
+	case PapelVeterinario, PapelLaboratorio, PapelTreinador, PapelNutricionista:
+		return true
+	}
+	return false
+}
+
+// IsValidEscopo verifica se o escopo informado é suportado
+func IsValidEscopo(escopo string) bool {
+	for _, e := range EscoposValidos {
+		if e == escopo {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvidarAcessoRequest representa o convite de um profissional para um equino
+type ConvidarAcessoRequest struct {
+	ProfissionalID uint          `json:"profissional_id" binding:"required"`
+	Papel          PapelDelegado `json:"papel" binding:"required"`
+	Escopos        []string      `json:"escopos" binding:"required,min=1"`
+	ExpiraEm       *time.Time    `json:"expira_em" binding:"required"`
+	IsPrincipal    bool          `json:"is_principal"`
+}
+
+// EscoposList retorna os escopos do acesso como slice
+func (v *EquinoVeterinario) EscoposList() []string {
+	if v.Escopos == "" {
+		return []string{}
+	}
+	return strings.Split(v.Escopos, ",")
+}
+
+// SetEscopos armazena os escopos do acesso
+func (v *EquinoVeterinario) SetEscopos(escopos []string) {
+	v.Escopos = strings.Join(escopos, ",")
+}
+
+// TemEscopo verifica se o acesso inclui o escopo informado
+func (v *EquinoVeterinario) TemEscopo(escopo string) bool {
+	for _, e := range v.EscoposList() {
+		if e == escopo {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusEfetivo retorna o status considerando a data de expiração
+func (v *EquinoVeterinario) StatusEfetivo(now time.Time) StatusDelegado {
+	if (v.Status == StatusDelegadoAtivo || v.Status == StatusDelegadoPendente) &&
+		v.ExpiraEm != nil && !now.Before(*v.ExpiraEm) {
+		return StatusDelegadoExpirado
+	}
+	return v.Status
+}
+
+// IsAtivo verifica se o acesso está aceito e dentro da validade
+func (v *EquinoVeterinario) IsAtivo(now time.Time) bool {
+	return v.StatusEfetivo(now) == StatusDelegadoAtivo
+}
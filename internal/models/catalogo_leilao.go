@@ -0,0 +1,164 @@
+package models
+
+import (
+	"time"
+)
+
+// SessaoLeilao dia ou sessão de pregão em que um grupo de lotes é apresentado
+type SessaoLeilao struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	LeilaoID  uint       `json:"leilao_id" gorm:"not null;uniqueIndex:idx_leiloes_sessoes_numero"`
+	Numero    int        `json:"numero" gorm:"not null;uniqueIndex:idx_leiloes_sessoes_numero"`
+	Titulo    string     `json:"titulo" gorm:"size:100"`
+	InicioEm  *time.Time `json:"inicio_em,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName especifica o nome da tabela
+func (SessaoLeilao) TableName() string {
+	return "leiloes_sessoes"
+}
+
+// CatalogoLeilao versão publicada do catálogo. O conteúdo e o PDF ficam congelados: alterações nos lotes só chegam aos
+// compradores em uma nova versão
+type CatalogoLeilao struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	LeilaoID       uint      `json:"leilao_id" gorm:"not null;uniqueIndex:idx_leiloes_catalogos_versao"`
+	Versao         int       `json:"versao" gorm:"not null;uniqueIndex:idx_leiloes_catalogos_versao"`
+	Motivo         string    `json:"motivo,omitempty" gorm:"size:500"` // o que mudou em relação à versão anterior
+	Lotes          int       `json:"lotes" gorm:"not null"`
+	Conteudo       string    `json:"-" gorm:"type:jsonb;not null"`
+	Hash           string    `json:"hash" gorm:"size:64;not null"` // SHA-256 do PDF
+	Tamanho        int64     `json:"tamanho" gorm:"not null"`
+	StorageBackend string    `json:"-" gorm:"size:20;not null"`
+	StorageKey     string    `json:"-" gorm:"size:500;not null"`
+	PublicadoPor   uint      `json:"publicado_por" gorm:"not null"`
+	PublicadoEm    time.Time `json:"publicado_em" gorm:"not null"`
+}
+
+// TableName especifica o nome da tabela
+func (CatalogoLeilao) TableName() string {
+	return "leiloes_catalogos"
+}
+
+// ConteudoCatalogo documento do catálogo: dados do leilão e lotes agrupados por sessão, na ordem de apresentação
+type ConteudoCatalogo struct {
+	LeilaoID               uint             `json:"leilao_id"`
+	Nome                   string           `json:"nome"`
+	Descricao              string           `json:"descricao,omitempty"`
+	Local                  string           `json:"local,omitempty"`
+	TipoLeilao             TipoLeilao       `json:"tipo_leilao"`
+	DataInicio             time.Time        `json:"data_inicio"`
+	DataFim                time.Time        `json:"data_fim"`
+	Leiloeiro              string           `json:"leiloeiro"`
+	TaxaComissaoPercentual float64          `json:"taxa_comissao_percentual"`
+	TaxaFixa               *float64         `json:"taxa_fixa,omitempty"`
+	Versao                 int              `json:"versao"` // 0 no rascunho
+	Rascunho               bool             `json:"rascunho"`
+	GeradoEm               time.Time        `json:"gerado_em"`
+	TotalLotes             int              `json:"total_lotes"`
+	Sessoes                []SessaoCatalogo `json:"sessoes"`
+}
+
+// SessaoCatalogo sessão com os lotes apresentados nela
+type SessaoCatalogo struct {
+	Numero   int            `json:"numero"`
+	Titulo   string         `json:"titulo,omitempty"`
+	InicioEm *time.Time     `json:"inicio_em,omitempty"`
+	Lotes    []LoteCatalogo `json:"lotes"`
+}
+
+// LoteCatalogo ficha do equino no catálogo. O valor de reserva nunca é publicado
+type LoteCatalogo struct {
+	Numero            int                `json:"numero"`
+	ParticipacaoID    uint               `json:"participacao_id"`
+	ValorInicial      float64            `json:"valor_inicial"`
+	Particularidades  string             `json:"particularidades,omitempty"`
+	Equinoid          string             `json:"equinoid"`
+	Nome              string             `json:"nome"`
+	Sexo              SexoEquino         `json:"sexo"`
+	Raca              string             `json:"raca"`
+	Pelagem           string             `json:"pelagem"`
+	DataNascimento    *time.Time         `json:"data_nascimento,omitempty"`
+	Vendedor          string             `json:"vendedor"`
+	Pedigree          *ArvoreGenealogica `json:"pedigree,omitempty"`
+	Fotos             []FotoCatalogo     `json:"fotos"`
+	PontosValorizacao int                `json:"pontos_valorizacao"`
+	Destaques         []DestaqueCatalogo `json:"destaques"`
+	Sanidade          SanidadeCatalogo   `json:"sanidade"`
+}
+
+// FotoCatalogo foto pública do cofre do equino. A chave de armazenamento fica só no conteúdo gravado; a resposta leva
+// uma URL temporária
+type FotoCatalogo struct {
+	StorageKey string `json:"storage_key,omitempty"`
+	MimeType   string `json:"mime_type"`
+	Capa       bool   `json:"capa"`
+	URL        string `json:"url,omitempty"`
+}
+
+// DestaqueCatalogo conquista validada do equino
+type DestaqueCatalogo struct {
+	Titulo      string               `json:"titulo"`
+	Categoria   CategoriaValorizacao `json:"categoria"`
+	Organizacao string               `json:"organizacao,omitempty"`
+	Data        time.Time            `json:"data"`
+	Nivel       NivelImportancia     `json:"nivel"`
+	Pontos      int                  `json:"pontos"`
+}
+
+// SanidadeCatalogo situação sanitária: última dose de cada vacina e o resultado mais recente de cada tipo de exame
+type SanidadeCatalogo struct {
+	Vacinas []VacinaCatalogo `json:"vacinas"`
+	Exames  []ExameCatalogo  `json:"exames"`
+}
+
+// VacinaCatalogo vacina aplicada e sua dose mais recente
+type VacinaCatalogo struct {
+	Nome       string    `json:"nome"`
+	UltimaDose time.Time `json:"ultima_dose"`
+	Doses      int       `json:"doses"`
+}
+
+// ExameCatalogo exame concluído, sem o laudo
+type ExameCatalogo struct {
+	Tipo      string         `json:"tipo"`
+	Nome      string         `json:"nome"`
+	Resultado ResultadoExame `json:"resultado"`
+	Data      time.Time      `json:"data"`
+}
+
+// LotesLeilao ordem dos lotes por sessão e participações aprovadas ainda sem número de lote
+type LotesLeilao struct {
+	LeilaoID       uint                          `json:"leilao_id"`
+	VersaoCatalogo int                           `json:"versao_catalogo"`
+	Sessoes        []SessaoLeilao                `json:"sessoes"`
+	Lotes          []*ParticipacaoLeilaoResponse `json:"lotes"`
+	SemLote        []*ParticipacaoLeilaoResponse `json:"sem_lote"`
+}
+
+// OrganizarLotesRequest define sessões e a ordem completa dos lotes; os números são atribuídos em sequência na ordem
+// enviada
+type OrganizarLotesRequest struct {
+	Sessoes []SessaoLeilaoRequest `json:"sessoes" binding:"required,min=1,dive"`
+	Lotes   []OrdemLoteRequest    `json:"lotes" binding:"required,min=1,dive"`
+}
+
+// SessaoLeilaoRequest sessão do pregão
+type SessaoLeilaoRequest struct {
+	Numero   int        `json:"numero" binding:"required,min=1"`
+	Titulo   string     `json:"titulo" binding:"max=100"`
+	InicioEm *time.Time `json:"inicio_em"`
+}
+
+// OrdemLoteRequest participação e a sessão em que será apresentada
+type OrdemLoteRequest struct {
+	ParticipacaoID uint `json:"participacao_id" binding:"required"`
+	Sessao         int  `json:"sessao" binding:"required,min=1"`
+}
+
+// PublicarCatalogoRequest representa requisição de publicação do catálogo; o motivo é obrigatório a partir da segunda
+// versão
+type PublicarCatalogoRequest struct {
+	Motivo string `json:"motivo" binding:"max=500"`
+}
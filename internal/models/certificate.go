@@ -10,11 +10,16 @@ import (
 type Certificate struct {
 	ID               uint           `json:"id" gorm:"primaryKey"`
 	UserID           uint           `json:"user_id" gorm:"not null"`
+	Equinoid         string         `json:"equinoid,omitempty" gorm:"size:25;index"`
+	CertificateType  string         `json:"certificate_type,omitempty" gorm:"size:50"`
 	CertificatePEM   string         `json:"certificate_pem" gorm:"type:text;not null"`
-	PrivateKeyPEM    string         `json:"private_key_pem" gorm:"type:text;not null"`
+	PrivateKeyPEM    string         `json:"-" gorm:"type:text;not null"` // criptografada; vazia quando emitido por CSR
 	PublicKeyPEM     string         `json:"public_key_pem" gorm:"type:text;not null"`
+	KeyOrigin        string         `json:"key_origin" gorm:"size:10"`
+	Fingerprint      string         `json:"fingerprint" gorm:"size:64"`
 	CommonName       string         `json:"common_name" gorm:"size:100"`
 	SerialNumber     string         `json:"serial_number" gorm:"uniqueIndex;size:100;not null"`
+	ReplacesID       *uint          `json:"replaces_id,omitempty"`
 	IssuedAt         time.Time      `json:"issued_at" gorm:"not null"`
 	ValidFrom        time.Time      `json:"valid_from" gorm:"not null"`
 	ValidTo          time.Time      `json:"valid_to" gorm:"not null"`
@@ -32,27 +37,43 @@ type Certificate struct {
 
 // CertificateResponse representa a resposta de certificado
 type CertificateResponse struct {
-	ID           uint       `json:"id"`
-	SerialNumber string     `json:"serial_number"`
-	ValidFrom    time.Time  `json:"valid_from"`
-	ValidTo      time.Time  `json:"valid_to"`
-	IsValid      bool       `json:"is_valid"`
-	IsRevoked    bool       `json:"is_revoked"`
-	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
+	ID               uint       `json:"id"`
+	SerialNumber     string     `json:"serial_number"`
+	CommonName       string     `json:"common_name"`
+	Equinoid         string     `json:"equinoid,omitempty"`
+	CertificateType  string     `json:"certificate_type,omitempty"`
+	Fingerprint      string     `json:"fingerprint"`
+	KeyOrigin        string     `json:"key_origin"`
+	ValidFrom        time.Time  `json:"valid_from"`
+	ValidTo          time.Time  `json:"valid_to"`
+	IsValid          bool       `json:"is_valid"`
+	IsRevoked        bool       `json:"is_revoked"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevocationReason string     `json:"revocation_reason,omitempty"`
+	ReplacesID       *uint      `json:"replaces_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
 }
 
-// GenerateCertificateRequest representa a requisição de geração de certificado
+// GenerateCertificateRequest representa a requisição de geração de certificado. Com CSR (PKCS#10 em PEM) a chave
+// privada permanece com o titular; sem CSR o servidor gera o par de chaves e devolve a chave privada uma única vez.
 type GenerateCertificateRequest struct {
-	UserType     UserType `json:"user_type" validate:"required"`
-	ValidityDays int      `json:"validity_days" validate:"required,min=1,max=3650"`
+	Equinoid        string `json:"equinoid" binding:"required"`
+	TipoCertificado string `json:"tipo_certificado" binding:"required,max=50"`
+	ValidDays       int    `json:"valid_days" binding:"omitempty,min=1,max=3650"`
+	CSR             string `json:"csr"`
+}
+
+// RenewCertificateRequest representa a renovação de um certificado, opcionalmente com nova CSR
+type RenewCertificateRequest struct {
+	CSR string `json:"csr"`
 }
 
 // GenerateCertificateResponse representa a resposta de geração de certificado
 type GenerateCertificateResponse struct {
 	Certificate    *CertificateResponse `json:"certificate"`
 	CertificatePEM string               `json:"certificate_pem"`
-	PrivateKey     string               `json:"private_key"`
+	CAChainPEM     string               `json:"ca_chain_pem"`
+	PrivateKey     string               `json:"private_key,omitempty"`
 }
 
 // RevokeCertificateRequest representa a requisição de revogação de certificado
@@ -64,20 +85,41 @@ type RevokeCertificateRequest struct {
 // ValidateCertificateResponse representa a resposta de validação de certificado
 type ValidateCertificateResponse struct {
 	IsValid     bool                 `json:"is_valid"`
+	Status      string               `json:"status"`
+	Reason      string               `json:"reason,omitempty"`
+	Issuer      string               `json:"issuer,omitempty"`
+	Subject     string               `json:"subject,omitempty"`
+	CheckedAt   time.Time            `json:"checked_at"`
 	Certificate *CertificateResponse `json:"certificate,omitempty"`
 }
 
+// Situações possíveis na validação de um certificado
+const (
+	CertificateStatusValid        = "valid"
+	CertificateStatusRevoked      = "revoked"
+	CertificateStatusExpired      = "expired"
+	CertificateStatusNotYetValid  = "not_yet_valid"
+	CertificateStatusInvalidChain = "invalid_chain"
+)
+
 // ToResponse converte Certificate para CertificateResponse
 func (c *Certificate) ToResponse() *CertificateResponse {
 	return &CertificateResponse{
-		ID:           c.ID,
-		SerialNumber: c.SerialNumber,
-		ValidFrom:    c.ValidFrom,
-		ValidTo:      c.ValidTo,
-		IsValid:      c.IsValid(),
-		IsRevoked:    c.IsRevoked,
-		RevokedAt:    c.RevokedAt,
-		CreatedAt:    c.CreatedAt,
+		ID:               c.ID,
+		SerialNumber:     c.SerialNumber,
+		CommonName:       c.CommonName,
+		Equinoid:         c.Equinoid,
+		CertificateType:  c.CertificateType,
+		Fingerprint:      c.Fingerprint,
+		KeyOrigin:        c.KeyOrigin,
+		ValidFrom:        c.ValidFrom,
+		ValidTo:          c.ValidTo,
+		IsValid:          c.IsValid(),
+		IsRevoked:        c.IsRevoked,
+		RevokedAt:        c.RevokedAt,
+		RevocationReason: c.RevocationReason,
+		ReplacesID:       c.ReplacesID,
+		CreatedAt:        c.CreatedAt,
 	}
 }
 
@@ -105,3 +147,19 @@ func (c *Certificate) BeforeCreate(tx *gorm.DB) error {
 	c.IsRevoked = false
 	return nil
 }
+
+// CertificateRevocationList CRL assinada pela CA interna; a de maior número é a publicada
+type CertificateRevocationList struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Number       int64     `json:"number" gorm:"uniqueIndex;not null"`
+	ThisUpdate   time.Time `json:"this_update" gorm:"not null"`
+	NextUpdate   time.Time `json:"next_update" gorm:"not null"`
+	RevokedCount int       `json:"revoked_count"`
+	CRLDER       []byte    `json:"-" gorm:"column:crl_der;not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName especifica o nome da tabela
+func (CertificateRevocationList) TableName() string {
+	return "certificate_revocation_lists"
+}
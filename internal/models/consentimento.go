@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Finalidades de tratamento que dependem de consentimento do titular (LGPD Art. 7, I)
+const (
+	FinalidadePerfilSocialPublico       = "perfil_social_publico"
+	FinalidadeCompartilhamentoTerceiros = "compartilhamento_terceiros"
+	FinalidadeKYCTokenizacao            = "kyc_tokenizacao"
+)
+
+// FinalidadesConsentimento lista as finalidades aceitas
+var FinalidadesConsentimento = []string{
+	FinalidadePerfilSocialPublico,
+	FinalidadeCompartilhamentoTerceiros,
+	FinalidadeKYCTokenizacao,
+}
+
+// IsValidFinalidade verifica se a finalidade informada é suportada
+func IsValidFinalidade(finalidade string) bool {
+	for _, f := range FinalidadesConsentimento {
+		if f == finalidade {
+			return true
+		}
+	}
+	return false
+}
+
+// PoliticaConsentimento versão do texto apresentado ao titular para uma finalidade
+type PoliticaConsentimento struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	Finalidade      string    `json:"finalidade" gorm:"size:50;not null;uniqueIndex:idx_politica_finalidade_versao"`
+	Versao          int       `json:"versao" gorm:"not null;uniqueIndex:idx_politica_finalidade_versao"`
+	Titulo          string    `json:"titulo" gorm:"size:200;not null"`
+	Texto           string    `json:"texto" gorm:"type:text;not null"`
+	TextoHash       string    `json:"texto_hash" gorm:"size:64;not null"`
+	ExigeNovoAceite bool      `json:"exige_novo_aceite" gorm:"default:false"`
+	PublicadaPorID  *uint     `json:"publicada_por_id"`
+	VigenteDesde    time.Time `json:"vigente_desde"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName especifica o nome da tabela
+func (PoliticaConsentimento) TableName() string {
+	return "politicas_consentimento"
+}
+
+// ConcederConsentimentoRequest aceite da versão vigente da política de uma finalidade
+type ConcederConsentimentoRequest struct {
+	Finalidade string `json:"finalidade" binding:"required"`
+	Versao     int    `json:"versao" binding:"required,min=1"`
+}
+
+// RevogarConsentimentoRequest revogação do consentimento pelo titular
+type RevogarConsentimentoRequest struct {
+	Motivo string `json:"motivo" binding:"max=1000"`
+}
+
+// PublicarPoliticaRequest publicação de nova versão da política de uma finalidade
+type PublicarPoliticaRequest struct {
+	Finalidade      string `json:"finalidade" binding:"required"`
+	Titulo          string `json:"titulo" binding:"required,max=200"`
+	Texto           string `json:"texto" binding:"required"`
+	ExigeNovoAceite bool   `json:"exige_novo_aceite"`
+}
+
+// ConsentimentoStatus situação do consentimento do titular para uma finalidade
+type ConsentimentoStatus struct {
+	Finalidade       string     `json:"finalidade"`
+	Concedido        bool       `json:"concedido"`
+	VersaoAceita     int        `json:"versao_aceita,omitempty"`
+	VersaoVigente    int        `json:"versao_vigente"`
+	NovoAceiteNeeded bool       `json:"novo_aceite_necessario"`
+	ConcedidoEm      *time.Time `json:"concedido_em,omitempty"`
+	ValidoAte        *time.Time `json:"valido_ate,omitempty"`
+}
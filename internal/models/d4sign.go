@@ -6,31 +6,84 @@ import (
 	"gorm.io/gorm"
 )
 
+// Status de documentos e signatários D4Sign
+const (
+	D4SignStatusPending   = "pending"
+	D4SignStatusSigned    = "signed"
+	D4SignStatusCancelled = "cancelled"
+	D4SignStatusExpired   = "expired"
+
+	D4SignSignerPending  = "pending"
+	D4SignSignerSigned   = "signed"
+	D4SignSignerRejected = "rejected"
+)
+
+// Papéis dos signatários de contratos; sem ordem explícita, assinam nesta sequência
+const (
+	D4SignRoleVendedor   = "vendedor"
+	D4SignRoleComprador  = "comprador"
+	D4SignRoleSignatario = "signatario"
+	D4SignRoleTestemunha = "testemunha"
+	D4SignRoleLeiloeiro  = "leiloeiro"
+)
+
 // D4SignDocument representa um documento na D4Sign armazenado localmente
 type D4SignDocument struct {
-	ID                uint           `json:"id" gorm:"primaryKey"`
-	DocumentUUID      string         `json:"document_uuid" gorm:"uniqueIndex;not null"`
-	SafeUUID          string         `json:"safe_uuid" gorm:"not null"`
-	Name              string         `json:"name" gorm:"not null"`
-	Status            string         `json:"status" gorm:"default:'pending'"` // pending, signed, cancelled, expired
-	DocumentType      string         `json:"document_type" gorm:"not null"`   // transferencia, contrato, leilao, exportacao
-	RelatedEntityID   *uint          `json:"related_entity_id"`               // ID do equino, contrato, etc
-	RelatedEntityType string         `json:"related_entity_type"`             // equino, contrato, leilao
-	CreatedBy         uint           `json:"created_by" gorm:"not null"`
-	CreatedAt         time.Time      `json:"created_at"`
-	UpdatedAt         time.Time      `json:"updated_at"`
-	SignedAt          *time.Time     `json:"signed_at,omitempty"`
-	DeletedAt         gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	DocumentUUID       string         `json:"document_uuid" gorm:"uniqueIndex;not null"`
+	SafeUUID           string         `json:"safe_uuid" gorm:"not null"`
+	Name               string         `json:"name" gorm:"not null"`
+	Status             string         `json:"status" gorm:"default:'pending'"` // pending, signed, cancelled, expired
+	DocumentType       string         `json:"document_type" gorm:"not null"`   // transferencia, contrato, leilao, exportacao
+	RelatedEntityID    *uint          `json:"related_entity_id"`               // ID do equino, contrato, etc
+	RelatedEntityType  string         `json:"related_entity_type"`             // equino, contrato, leilao
+	CreatedBy          uint           `json:"created_by" gorm:"not null"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	SignedAt           *time.Time     `json:"signed_at,omitempty"`
+	SequentialSigning  bool           `json:"sequential_signing" gorm:"default:false"` // signatários assinam na ordem cadastrada
+	SignedFilePath     string         `json:"-" gorm:"size:500"`                       // PDF final baixado da D4Sign
+	SignedFileHash     string         `json:"signed_file_hash,omitempty" gorm:"size:64"`
+	TimestampToken     string         `json:"timestamp_token,omitempty" gorm:"type:text"` // TimeStampToken RFC 3161 em base64 sobre SignedFileHash
+	TimestampTime      *time.Time     `json:"timestamp_time,omitempty"`
+	TimestampAuthority string         `json:"timestamp_authority,omitempty" gorm:"size:255"`
+	LastSyncedAt       *time.Time     `json:"last_synced_at,omitempty"`
+	DeletedAt          gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
 
 	// Relacionamentos
-	Creator *User `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
+	Creator *User                  `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
+	Signers []D4SignDocumentSigner `json:"signers,omitempty" gorm:"foreignKey:DocumentID"`
+}
+
+// D4SignDocumentSigner signatário de um documento D4Sign com a posição na ordem de assinatura e o status atual
+type D4SignDocumentSigner struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	DocumentID     uint       `json:"document_id" gorm:"not null;index"`
+	Email          string     `json:"email" gorm:"size:255;not null"`
+	Name           string     `json:"name" gorm:"size:255"`
+	Role           string     `json:"role" gorm:"size:30;not null"`
+	SigningOrder   int        `json:"signing_order" gorm:"not null"`
+	KeySigner      string     `json:"-" gorm:"size:100;index"` // chave do signatário na D4Sign
+	Status         string     `json:"status" gorm:"size:20;default:'pending'"`
+	SignedAt       *time.Time `json:"signed_at,omitempty"`
+	RejectedAt     *time.Time `json:"rejected_at,omitempty"`
+	LastReminderAt *time.Time `json:"last_reminder_at,omitempty"`
+	ReminderCount  int        `json:"reminder_count" gorm:"default:0"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName especifica o nome da tabela
+func (D4SignDocumentSigner) TableName() string {
+	return "d4sign_document_signers"
 }
 
 // D4SignSigner representa um signatário de um documento
 type D4SignSigner struct {
 	Email string `json:"email"`
 	Name  string `json:"name"`
-	Role  string `json:"role"` // 1-Signer, 2-Approver, etc.
+	Role  string `json:"role"`            // vendedor, comprador, signatario, testemunha, leiloeiro
+	Order int    `json:"order,omitempty"` // posição na assinatura sequencial; sem ela vale a ordem dos papéis
 }
 
 // CreateD4SignDocumentRequest representa a requisição para criar um documento
@@ -42,17 +95,61 @@ type CreateD4SignDocumentRequest struct {
 	RelatedEntityType string         `json:"related_entity_type"`
 	Signers           []D4SignSigner `json:"signers" validate:"required,min=1"`
 	SafeUUID          string         `json:"safe_uuid"`
+	Sequential        *bool          `json:"sequential,omitempty"` // assinatura na ordem dos signatários (padrão: sim)
 }
 
 // D4SignWebhookPayload representa o payload do webhook da D4Sign
 type D4SignWebhookPayload struct {
-	Event     string                 `json:"event"` // document_signed, document_cancelled, etc
+	ID        string                 `json:"id,omitempty"` // identificador do evento, usado para descartar reenvios
+	Event     string                 `json:"event"`        // document_signed, document_cancelled, etc
 	Document  D4SignWebhookDocument  `json:"document"`
 	Signer    D4SignWebhookSigner    `json:"signer,omitempty"`
 	Timestamp time.Time              `json:"timestamp"`
 	Data      map[string]interface{} `json:"data,omitempty"`
 }
 
+// Resultado do processamento de um webhook recebido da D4Sign
+const (
+	D4SignWebhookProcessed = "processed"
+	D4SignWebhookDuplicate = "duplicate"
+	D4SignWebhookStale     = "stale"    // evento fora de ordem, posterior a um estado final
+	D4SignWebhookIgnored   = "ignored"  // evento desconhecido
+	D4SignWebhookRejected  = "rejected" // autenticação inválida ou payload malformado
+	D4SignWebhookFailed    = "failed"
+)
+
+// D4SignWebhookLog registro de cada webhook recebido da D4Sign, inclusive os rejeitados
+type D4SignWebhookLog struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	EventID      string    `json:"event_id" gorm:"size:100;index"`
+	DocumentUUID string    `json:"document_uuid" gorm:"size:255;index"`
+	Event        string    `json:"event" gorm:"size:50"`
+	Status       string    `json:"status" gorm:"size:20;not null;index"`
+	Error        string    `json:"error,omitempty" gorm:"type:text"`
+	RemoteIP     string    `json:"remote_ip" gorm:"size:64"`
+	Payload      string    `json:"payload" gorm:"type:text"`
+	ReceivedAt   time.Time `json:"received_at" gorm:"not null;index"`
+}
+
+// TableName especifica o nome da tabela
+func (D4SignWebhookLog) TableName() string {
+	return "d4sign_webhook_logs"
+}
+
+// D4SignWebhookEvent evento D4Sign já processado; a chave única garante que cada evento é aplicado uma única vez
+type D4SignWebhookEvent struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	EventID      string    `json:"event_id" gorm:"size:100;uniqueIndex;not null"`
+	DocumentUUID string    `json:"document_uuid" gorm:"size:255;index"`
+	Event        string    `json:"event" gorm:"size:50"`
+	ProcessedAt  time.Time `json:"processed_at" gorm:"not null"`
+}
+
+// TableName especifica o nome da tabela
+func (D4SignWebhookEvent) TableName() string {
+	return "d4sign_webhook_events"
+}
+
 // D4SignWebhookDocument representa o documento no webhook
 type D4SignWebhookDocument struct {
 	UUID   string `json:"uuid"`
@@ -62,8 +159,9 @@ type D4SignWebhookDocument struct {
 
 // D4SignWebhookSigner representa o signatário no webhook
 type D4SignWebhookSigner struct {
-	Email string `json:"email"`
-	Name  string `json:"name"`
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	KeySigner string `json:"key_signer,omitempty"`
 }
 
 // D4SignDocumentStatusResponse representa a resposta de status do documento
@@ -78,10 +176,11 @@ type D4SignDocumentStatusResponse struct {
 
 // D4SignSignerStatus representa o status de um signatário
 type D4SignSignerStatus struct {
-	Email    string     `json:"email"`
-	Name     string     `json:"name"`
-	Status   string     `json:"status"` // pending, signed, rejected
-	SignedAt *time.Time `json:"signed_at,omitempty"`
+	Email     string     `json:"email"`
+	Name      string     `json:"name"`
+	KeySigner string     `json:"key_signer,omitempty"`
+	Status    string     `json:"status"` // pending, signed, rejected
+	SignedAt  *time.Time `json:"signed_at,omitempty"`
 }
 
 // D4SignResponse representa uma resposta genérica da D4Sign
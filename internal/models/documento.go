@@ -0,0 +1,140 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CategoriaDocumento define o tipo de documento guardado no cofre do equino
+type CategoriaDocumento string
+
+const (
+	CategoriaFotoPerfil  CategoriaDocumento = "foto_perfil"
+	CategoriaFotoGaleria CategoriaDocumento = "foto_galeria"
+	CategoriaEvento      CategoriaDocumento = "evento"
+	CategoriaCobertura   CategoriaDocumento = "cobertura"
+	CategoriaLaudo       CategoriaDocumento = "laudo"
+	CategoriaRegistro    CategoriaDocumento = "registro"
+	CategoriaContrato    CategoriaDocumento = "contrato"
+	CategoriaOutro       CategoriaDocumento = "outro"
+)
+
+// VisibilidadeDocumento define quem pode ler o documento além do proprietário
+type VisibilidadeDocumento string
+
+const (
+	// VisibilidadePrivado proprietário, administradores e profissionais com acesso delegado de leitura de saúde
+	VisibilidadePrivado VisibilidadeDocumento = "privado"
+	// VisibilidadePublico qualquer usuário autenticado
+	VisibilidadePublico VisibilidadeDocumento = "publico"
+)
+
+// Entidades às quais um documento pode estar vinculado
+const (
+	EntidadeDocumentoEvento    = "evento"
+	EntidadeDocumentoCobertura = "cobertura"
+	EntidadeDocumentoExame     = "exame"
+)
+
+// IsValidCategoriaDocumento verifica se a categoria informada é suportada
+func IsValidCategoriaDocumento(categoria CategoriaDocumento) bool {
+	switch categoria {
+	case CategoriaFotoPerfil, CategoriaFotoGaleria, CategoriaEvento, CategoriaCobertura,
+		CategoriaLaudo, CategoriaRegistro, CategoriaContrato, CategoriaOutro:
+		return true
+	}
+	return false
+}
+
+// IsFoto verifica se a categoria aceita apenas imagens
+func (c CategoriaDocumento) IsFoto() bool {
+	return c == CategoriaFotoPerfil || c == CategoriaFotoGaleria
+}
+
+// IsValidEntidadeDocumento verifica se o tipo de entidade vinculada é suportado
+func IsValidEntidadeDocumento(entidade string) bool {
+	switch entidade {
+	case EntidadeDocumentoEvento, EntidadeDocumentoCobertura, EntidadeDocumentoExame:
+		return true
+	}
+	return false
+}
+
+// Documento arquivo versionado do cofre de um equino (fotos, laudos, documentos de eventos e coberturas)
+type Documento struct {
+	ID           uint                  `json:"id" gorm:"primaryKey"`
+	UUID         string                `json:"uuid" gorm:"size:36;uniqueIndex;not null"`
+	EquinoID     uint                  `json:"equino_id" gorm:"not null;index"`
+	Equinoid     string                `json:"equinoid" gorm:"size:24;not null;index"`
+	Categoria    CategoriaDocumento    `json:"categoria" gorm:"size:30;not null;index"`
+	Titulo       string                `json:"titulo" gorm:"size:255;not null"`
+	Descricao    *string               `json:"descricao,omitempty" gorm:"type:text"`
+	Visibilidade VisibilidadeDocumento `json:"visibilidade" gorm:"size:20;not null;default:'privado'"`
+	EntidadeTipo *string               `json:"entidade_tipo,omitempty" gorm:"size:30;index:idx_documentos_entidade"`
+	EntidadeID   *uint                 `json:"entidade_id,omitempty" gorm:"index:idx_documentos_entidade"`
+	VersaoAtual  int                   `json:"versao_atual" gorm:"not null;default:1"`
+	CriadoPor    uint                  `json:"criado_por" gorm:"not null"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt        `json:"-" gorm:"index"`
+
+	Versoes []DocumentoVersao `json:"versoes,omitempty" gorm:"foreignKey:DocumentoID"`
+}
+
+// TableName especifica o nome da tabela
+func (Documento) TableName() string {
+	return "documentos"
+}
+
+// DocumentoVersao conteúdo enviado para um documento; versões anteriores permanecem disponíveis
+type DocumentoVersao struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	DocumentoID    uint      `json:"documento_id" gorm:"not null;uniqueIndex:idx_documento_versao"`
+	Numero         int       `json:"numero" gorm:"not null;uniqueIndex:idx_documento_versao"`
+	NomeArquivo    string    `json:"nome_arquivo" gorm:"size:255;not null"`
+	MimeType       string    `json:"mime_type" gorm:"size:100;not null"`
+	Tamanho        int64     `json:"tamanho" gorm:"not null"`
+	Hash           string    `json:"hash" gorm:"size:64;not null;index"`
+	StorageBackend string    `json:"-" gorm:"size:20;not null"`
+	StorageKey     string    `json:"-" gorm:"size:500;not null"`
+	ThumbnailKey   *string   `json:"-" gorm:"size:500"`
+	TemThumbnail   bool      `json:"tem_thumbnail" gorm:"-"`
+	EnviadoPor     uint      `json:"enviado_por" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName especifica o nome da tabela
+func (DocumentoVersao) TableName() string {
+	return "documento_versoes"
+}
+
+// AfterFind indica se a versão possui miniatura sem expor a chave de armazenamento
+func (v *DocumentoVersao) AfterFind(tx *gorm.DB) error {
+	v.TemThumbnail = v.ThumbnailKey != nil
+	return nil
+}
+
+// UploadDocumentoRequest metadados enviados junto do arquivo no upload multipart
+type UploadDocumentoRequest struct {
+	Categoria    CategoriaDocumento    `form:"categoria" binding:"required"`
+	Titulo       string                `form:"titulo"`
+	Descricao    *string               `form:"descricao"`
+	Visibilidade VisibilidadeDocumento `form:"visibilidade"`
+	EntidadeTipo *string               `form:"entidade_tipo"`
+	EntidadeID   *uint                 `form:"entidade_id"`
+}
+
+// ArquivoEnviado conteúdo de um arquivo recebido no upload
+type ArquivoEnviado struct {
+	Nome     string
+	Conteudo []byte
+}
+
+// DocumentoURLResponse URL temporária de download de uma versão do documento
+type DocumentoURLResponse struct {
+	URL       string    `json:"url"`
+	Versao    int       `json:"versao"`
+	Thumbnail bool      `json:"thumbnail"`
+	ExpiraEm  time.Time `json:"expira_em"`
+}
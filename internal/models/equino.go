@@ -23,6 +23,7 @@ type Equino struct {
 	Pelagem        string         `json:"pelagem" gorm:"size:50;not null"`
 	Raca           string         `json:"raca" gorm:"size:100;not null"`
 	PaisOrigem     string         `json:"pais_origem" gorm:"size:3;not null"`
+	Resenha        string         `json:"resenha" gorm:"type:text"` // Sinais e marcas de identificação
 	Genitora       string         `json:"genitora_equinoid" gorm:"size:25"`
 	Genitor        string         `json:"genitor_equinoid" gorm:"size:25"`
 	ProprietarioID uint           `json:"proprietario_id" gorm:"not null"`
@@ -81,6 +82,7 @@ type CreateEquinoRequest struct {
 	Pelagem          string       `json:"pelagem" validate:"required"`
 	Raca             string       `json:"raca" validate:"required"`
 	PaisOrigem       string       `json:"pais_origem" validate:"required,len=3"`
+	Resenha          string       `json:"resenha"`
 	GenitoraEquinoid string       `json:"genitora_equinoid"`
 	GenitorEquinoid  string       `json:"genitor_equinoid"`
 	ProprietarioID   uint         `json:"proprietario_id" validate:"required"`
@@ -95,6 +97,7 @@ type UpdateEquinoRequest struct {
 	Pelagem       *string       `json:"pelagem"`
 	Raca          *string       `json:"raca"`
 	PaisOrigem    *string       `json:"pais_origem" validate:"omitempty,len=3"`
+	Resenha       *string       `json:"resenha"`
 	PropriedadeID *uint         `json:"propriedade_id"`
 	ExternalIDs   []ExternalID  `json:"external_ids"`
 	FotoPerfil    *string       `json:"foto_perfil"`
@@ -113,6 +116,7 @@ type EquinoResponse struct {
 	Pelagem        string                `json:"pelagem"`
 	Raca           string                `json:"raca"`
 	PaisOrigem     string                `json:"pais_origem"`
+	Resenha        string                `json:"resenha,omitempty"`
 	ExternalIDs    []ExternalID          `json:"external_ids,omitempty"`
 	FotoPerfil     string                `json:"foto_perfil,omitempty"`
 	FotosGaleria   []string              `json:"fotos_galeria,omitempty"`
@@ -128,15 +132,25 @@ type EquinoResponse struct {
 	UpdatedAt      time.Time             `json:"updated_at"`
 }
 
-// EquinoVeterinario representa a relação entre equino e veterinário
+// EquinoVeterinario representa a relação entre equino e veterinário.
+// Também modela o acesso delegado de outros profissionais (laboratório,
+// treinador, nutricionista), identificados pelo campo Papel.
 type EquinoVeterinario struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	EquinoID      uint      `json:"equino_id" gorm:"not null"`
-	VeterinarioID uint      `json:"veterinario_id" gorm:"not null"`
-	NomeadoPorID  uint      `json:"nomeado_por_id" gorm:"not null"`
-	DataNomeacao  time.Time `json:"data_nomeacao" gorm:"not null"`
-	IsPrincipal   bool      `json:"is_principal" gorm:"default:false"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	EquinoID      uint           `json:"equino_id" gorm:"not null;index"`
+	VeterinarioID uint           `json:"veterinario_id" gorm:"not null;index"`
+	NomeadoPorID  uint           `json:"nomeado_por_id" gorm:"not null"`
+	DataNomeacao  time.Time      `json:"data_nomeacao" gorm:"not null"`
+	IsPrincipal   bool           `json:"is_principal" gorm:"default:false"`
+	Papel         PapelDelegado  `json:"papel" gorm:"size:20;default:'veterinario'"`
+	Escopos       string         `json:"escopos" gorm:"size:255"`
+	Status        StatusDelegado `json:"status" gorm:"size:20;default:'pendente';index"`
+	ExpiraEm      *time.Time     `json:"expira_em"`
+	AceitoEm      *time.Time     `json:"aceito_em"`
+	RevogadoEm    *time.Time     `json:"revogado_em"`
+	RevogadoPorID *uint          `json:"revogado_por_id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
 
 	// Relacionamentos
 	Equino      *Equino `json:"equino,omitempty" gorm:"foreignKey:EquinoID"`
@@ -146,15 +160,47 @@ type EquinoVeterinario struct {
 
 // EquinoVetResponse representa a resposta da relação equino-veterinário
 type EquinoVetResponse struct {
-	ID            uint          `json:"id"`
-	EquinoID      uint          `json:"equino_id"`
-	VeterinarioID uint          `json:"veterinario_id"`
-	Veterinario   *UserResponse `json:"veterinario,omitempty"`
-	NomeadoPorID  uint          `json:"nomeado_por_id"`
-	NomeadoPor    *UserResponse `json:"nomeado_por,omitempty"`
-	DataNomeacao  time.Time     `json:"data_nomeacao"`
-	IsPrincipal   bool          `json:"is_principal"`
-	CreatedAt     time.Time     `json:"created_at"`
+	ID            uint           `json:"id"`
+	EquinoID      uint           `json:"equino_id"`
+	VeterinarioID uint           `json:"veterinario_id"`
+	Veterinario   *UserResponse  `json:"veterinario,omitempty"`
+	NomeadoPorID  uint           `json:"nomeado_por_id"`
+	NomeadoPor    *UserResponse  `json:"nomeado_por,omitempty"`
+	DataNomeacao  time.Time      `json:"data_nomeacao"`
+	IsPrincipal   bool           `json:"is_principal"`
+	Papel         PapelDelegado  `json:"papel"`
+	Escopos       []string       `json:"escopos"`
+	Status        StatusDelegado `json:"status"`
+	ExpiraEm      *time.Time     `json:"expira_em,omitempty"`
+	AceitoEm      *time.Time     `json:"aceito_em,omitempty"`
+	RevogadoEm    *time.Time     `json:"revogado_em,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// ToResponse converte EquinoVeterinario para EquinoVetResponse
+func (v *EquinoVeterinario) ToResponse() *EquinoVetResponse {
+	response := &EquinoVetResponse{
+		ID:            v.ID,
+		EquinoID:      v.EquinoID,
+		VeterinarioID: v.VeterinarioID,
+		NomeadoPorID:  v.NomeadoPorID,
+		DataNomeacao:  v.DataNomeacao,
+		IsPrincipal:   v.IsPrincipal,
+		Papel:         v.Papel,
+		Escopos:       v.EscoposList(),
+		Status:        v.StatusEfetivo(time.Now()),
+		ExpiraEm:      v.ExpiraEm,
+		AceitoEm:      v.AceitoEm,
+		RevogadoEm:    v.RevogadoEm,
+		CreatedAt:     v.CreatedAt,
+	}
+	if v.Veterinario != nil {
+		response.Veterinario = v.Veterinario.ToResponse()
+	}
+	if v.NomeadoPor != nil {
+		response.NomeadoPor = v.NomeadoPor.ToResponse()
+	}
+	return response
 }
 
 // EquinoSimpleResponse representa uma resposta simplificada de equino
@@ -174,6 +220,7 @@ func (e *Equino) ToResponse() *EquinoResponse {
 		Sexo:           e.Sexo,
 		Pelagem:        e.Pelagem,
 		PaisOrigem:     e.PaisOrigem,
+		Resenha:        e.Resenha,
 		Raca:           e.Raca,
 		Status:         e.Status,
 		FotoPerfil:     e.FotoPerfil,
@@ -198,22 +245,8 @@ func (e *Equino) ToResponse() *EquinoResponse {
 
 	if len(e.VeterinariosNomados) > 0 {
 		response.Veterinarios = make([]EquinoVetResponse, len(e.VeterinariosNomados))
-		for i, v := range e.VeterinariosNomados {
-			response.Veterinarios[i] = EquinoVetResponse{
-				ID:            v.ID,
-				EquinoID:      v.EquinoID,
-				VeterinarioID: v.VeterinarioID,
-				NomeadoPorID:  v.NomeadoPorID,
-				DataNomeacao:  v.DataNomeacao,
-				IsPrincipal:   v.IsPrincipal,
-				CreatedAt:     v.CreatedAt,
-			}
-			if v.Veterinario != nil {
-				response.Veterinarios[i].Veterinario = v.Veterinario.ToResponse()
-			}
-			if v.NomeadoPor != nil {
-				response.Veterinarios[i].NomeadoPor = v.NomeadoPor.ToResponse()
-			}
+		for i := range e.VeterinariosNomados {
+			response.Veterinarios[i] = *e.VeterinariosNomados[i].ToResponse()
 		}
 	}
 
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// TipoIdentificador tipo de identificador alternativo de um equino
+type TipoIdentificador string
+
+const (
+	// IdentificadorEquinoidV2 alias v2 de equinos registrados com EquinoId v1 ou legado; gerado pela plataforma
+	IdentificadorEquinoidV2 TipoIdentificador = "equinoid_v2"
+	// IdentificadorUELN Universal Equine Life Number (15 caracteres)
+	IdentificadorUELN TipoIdentificador = "ueln"
+	// IdentificadorRegistroRaca número no stud book da associação de raça (emissor obrigatório)
+	IdentificadorRegistroRaca TipoIdentificador = "registro_raca"
+)
+
+// IsValidTipoIdentificador verifica se o tipo pode ser cadastrado pelos usuários; aliases v2 são gerados pela plataforma
+func IsValidTipoIdentificador(tipo TipoIdentificador) bool {
+	return tipo == IdentificadorUELN || tipo == IdentificadorRegistroRaca
+}
+
+// EquinoIdentificador identificador alternativo que também localiza o equino
+type EquinoIdentificador struct {
+	ID        uint              `json:"id" gorm:"primaryKey"`
+	EquinoID  uint              `json:"equino_id" gorm:"not null;index"`
+	Tipo      TipoIdentificador `json:"tipo" gorm:"size:30;not null;uniqueIndex:idx_equino_identificador_valor"`
+	Emissor   string            `json:"emissor,omitempty" gorm:"size:100;not null;default:'';uniqueIndex:idx_equino_identificador_valor"`
+	Valor     string            `json:"valor" gorm:"size:64;not null;uniqueIndex:idx_equino_identificador_valor"`
+	CriadoPor *uint             `json:"criado_por,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// TableName especifica o nome da tabela
+func (EquinoIdentificador) TableName() string {
+	return "equino_identificadores"
+}
+
+// CreateIdentificadorRequest identificador externo informado pelo proprietário
+type CreateIdentificadorRequest struct {
+	Tipo    TipoIdentificador `json:"tipo" binding:"required"`
+	Valor   string            `json:"valor" binding:"required"`
+	Emissor string            `json:"emissor"`
+}
@@ -31,6 +31,9 @@ type Evento struct {
 	AceitaPatrocinio      bool           `json:"aceita_patrocinio" gorm:"default:false"`
 	InformacoesPatrocinio string         `json:"informacoes_patrocinio" gorm:"type:text"`
 	AssinaturaDigital     string         `json:"assinatura_digital,omitempty" gorm:"type:text"`
+	CarimboTempo          string         `json:"carimbo_tempo,omitempty" gorm:"type:text"` // token RFC 3161 em base64 sobre o SHA-256 da assinatura
+	CarimboTempoEm        *time.Time     `json:"carimbo_tempo_em,omitempty"`
+	CarimboTempoTSA       string         `json:"carimbo_tempo_tsa,omitempty" gorm:"size:255"`
 	CreatedAt             time.Time      `json:"created_at"`
 	UpdatedAt             time.Time      `json:"updated_at"`
 	DeletedAt             gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
@@ -104,6 +107,7 @@ type CreateEventoRequest struct {
 	ValorInscricao        *float64          `json:"valor_inscricao"`
 	AceitaPatrocinio      bool              `json:"aceita_patrocinio"`
 	InformacoesPatrocinio string            `json:"informacoes_patrocinio"`
+	AssinaturaDigital     string            `json:"assinatura_digital"`
 }
 
 // DocumentoEvento representa um documento anexo ao evento
@@ -136,6 +140,9 @@ type EventoResponse struct {
 	AceitaPatrocinio      bool                       `json:"aceita_patrocinio"`
 	InformacoesPatrocinio string                     `json:"informacoes_patrocinio,omitempty"`
 	AssinaturaDigital     string                     `json:"assinatura_digital,omitempty"`
+	CarimboTempo          string                     `json:"carimbo_tempo,omitempty"`
+	CarimboTempoEm        *time.Time                 `json:"carimbo_tempo_em,omitempty"`
+	CarimboTempoTSA       string                     `json:"carimbo_tempo_tsa,omitempty"`
 	CreatedAt             time.Time                  `json:"created_at"`
 }
 
@@ -165,6 +172,9 @@ func (e *Evento) ToResponse() *EventoResponse {
 		AceitaPatrocinio:      e.AceitaPatrocinio,
 		InformacoesPatrocinio: e.InformacoesPatrocinio,
 		AssinaturaDigital:     e.AssinaturaDigital,
+		CarimboTempo:          e.CarimboTempo,
+		CarimboTempoEm:        e.CarimboTempoEm,
+		CarimboTempoTSA:       e.CarimboTempoTSA,
 		CreatedAt:             e.CreatedAt,
 	}
 
@@ -59,6 +59,10 @@ type ExameLaboratorial struct {
 	Resultado                *ResultadoExame `json:"resultado"`
 	Valores                  JSONB           `json:"valores" gorm:"type:jsonb"`
 	Laudo                    *string         `json:"laudo" gorm:"type:text"`
+	LaudoHash                string          `json:"laudo_hash,omitempty" gorm:"size:64"`
+	LaudoCarimboTempo        string          `json:"laudo_carimbo_tempo,omitempty" gorm:"type:text"` // token RFC 3161 em base64 sobre LaudoHash
+	LaudoCarimboTempoEm      *time.Time      `json:"laudo_carimbo_tempo_em,omitempty"`
+	LaudoCarimboTempoTSA     string          `json:"laudo_carimbo_tempo_tsa,omitempty" gorm:"size:255"`
 	Observacoes              *string         `json:"observacoes" gorm:"type:text"`
 	CertificadoID            *uint           `json:"certificado_id"`
 	CreatedAt                time.Time       `json:"created_at"`
@@ -16,6 +16,9 @@ type TransacaoFinanceira struct {
 	Data        time.Time      `json:"data" gorm:"not null;index"`
 	EquinoID    *uint          `json:"equino_id" gorm:"index"`
 	Status      StatusPagamento `json:"status" gorm:"size:20;default:'pendente'"`
+	UsuarioID   *uint          `json:"usuario_id,omitempty" gorm:"index"`
+	PagamentoID *uint          `json:"pagamento_id,omitempty" gorm:"index"`
+	Referencia  *string        `json:"referencia,omitempty" gorm:"size:100;uniqueIndex"` // chave dos lançamentos gerados por pagamentos
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+)
+
+// HabilitacaoLeilao cadastro do arrematante em um leilão. O leiloeiro responde pelos compradores que aprova: só
+// arremata quem tem a habilitação aprovada, até o limite concedido
+type HabilitacaoLeilao struct {
+	ID                 uint                   `json:"id" gorm:"primaryKey"`
+	LeilaoID           uint                   `json:"leilao_id" gorm:"not null;uniqueIndex:idx_leiloes_habilitacoes_usuario;uniqueIndex:idx_leiloes_habilitacoes_paleta"`
+	UsuarioID          uint                   `json:"usuario_id" gorm:"not null;uniqueIndex:idx_leiloes_habilitacoes_usuario;index"`
+	Status             StatusHabilitacao      `json:"status" gorm:"size:20;not null;default:'pendente';index"`
+	LimiteSolicitado   float64                `json:"limite_solicitado" gorm:"type:decimal(15,2);not null"`
+	Observacoes        string                 `json:"observacoes,omitempty" gorm:"type:text"`
+	Garantia           TipoGarantiaLeilao     `json:"garantia,omitempty" gorm:"size:20"`
+	LimiteAprovado     *float64               `json:"limite_aprovado,omitempty" gorm:"type:decimal(15,2)"`
+	ValorCaucao        *float64               `json:"valor_caucao,omitempty" gorm:"type:decimal(15,2)"`
+	CaucaoReferencia   string                 `json:"caucao_referencia,omitempty" gorm:"size:100"`
+	CaucaoConfirmadaEm *time.Time             `json:"caucao_confirmada_em,omitempty"`
+	Paleta             *int                   `json:"paleta,omitempty" gorm:"uniqueIndex:idx_leiloes_habilitacoes_paleta"` // número de identificação no pregão
+	MotivoRejeicao     string                 `json:"motivo_rejeicao,omitempty" gorm:"size:500"`
+	AnalisadoPor       *uint                  `json:"analisado_por,omitempty"`
+	AnalisadoEm        *time.Time             `json:"analisado_em,omitempty"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+	LimiteUtilizado    float64                `json:"limite_utilizado" gorm:"-"`  // soma dos lotes já arrematados no leilão
+	ComprasPendentes   int64                  `json:"compras_pendentes" gorm:"-"` // arrematações de outros leilões com pagamento vencido
+	Documentos         []DocumentoHabilitacao `json:"documentos,omitempty" gorm:"foreignKey:HabilitacaoID"`
+
+	Usuario *User `json:"usuario,omitempty" gorm:"foreignKey:UsuarioID"`
+}
+
+// TableName especifica o nome da tabela
+func (HabilitacaoLeilao) TableName() string {
+	return "leiloes_habilitacoes"
+}
+
+// StatusHabilitacao etapa da habilitação do arrematante
+type StatusHabilitacao string
+
+const (
+	HabilitacaoPendente         StatusHabilitacao = "pendente"
+	HabilitacaoAguardandoCaucao StatusHabilitacao = "aguardando_caucao"
+	HabilitacaoAprovada         StatusHabilitacao = "aprovada"
+	HabilitacaoRejeitada        StatusHabilitacao = "rejeitada"
+	HabilitacaoCancelada        StatusHabilitacao = "cancelada"
+)
+
+// TipoGarantiaLeilao garantia exigida na aprovação: limite de crédito analisado pelos documentos ou caução depositada
+type TipoGarantiaLeilao string
+
+const (
+	GarantiaLimiteCredito TipoGarantiaLeilao = "limite_credito"
+	GarantiaCaucao        TipoGarantiaLeilao = "caucao"
+)
+
+// DocumentoHabilitacao documento enviado pelo arrematante para análise cadastral
+type DocumentoHabilitacao struct {
+	ID             uint                     `json:"id" gorm:"primaryKey"`
+	HabilitacaoID  uint                     `json:"habilitacao_id" gorm:"not null;index"`
+	Tipo           TipoDocumentoHabilitacao `json:"tipo" gorm:"size:30;not null"`
+	NomeArquivo    string                   `json:"nome_arquivo" gorm:"size:255;not null"`
+	MimeType       string                   `json:"mime_type" gorm:"size:100;not null"`
+	Tamanho        int64                    `json:"tamanho" gorm:"not null"`
+	Hash           string                   `json:"hash" gorm:"size:64;not null"`
+	StorageBackend string                   `json:"-" gorm:"size:20;not null"`
+	StorageKey     string                   `json:"-" gorm:"size:500;not null"`
+	EnviadoPor     uint                     `json:"enviado_por" gorm:"not null"`
+	CreatedAt      time.Time                `json:"created_at"`
+	URL            string                   `json:"url,omitempty" gorm:"-"`
+}
+
+// TableName especifica o nome da tabela
+func (DocumentoHabilitacao) TableName() string {
+	return "leiloes_habilitacoes_documentos"
+}
+
+// TipoDocumentoHabilitacao documentos aceitos na habilitação
+type TipoDocumentoHabilitacao string
+
+const (
+	DocHabilitacaoIdentidade         TipoDocumentoHabilitacao = "documento_identidade"
+	DocHabilitacaoEndereco           TipoDocumentoHabilitacao = "comprovante_endereco"
+	DocHabilitacaoRenda              TipoDocumentoHabilitacao = "comprovante_renda"
+	DocHabilitacaoReferenciaBancaria TipoDocumentoHabilitacao = "referencia_bancaria"
+	DocHabilitacaoContratoSocial     TipoDocumentoHabilitacao = "contrato_social"
+	DocHabilitacaoOutro              TipoDocumentoHabilitacao = "outro"
+)
+
+// IsValid verifica se o tipo de documento é aceito
+func (t TipoDocumentoHabilitacao) IsValid() bool {
+	switch t {
+	case DocHabilitacaoIdentidade, DocHabilitacaoEndereco, DocHabilitacaoRenda, DocHabilitacaoReferenciaBancaria,
+		DocHabilitacaoContratoSocial, DocHabilitacaoOutro:
+		return true
+	}
+	return false
+}
+
+// SolicitarHabilitacaoRequest representa requisição de habilitação para arrematar em um leilão
+type SolicitarHabilitacaoRequest struct {
+	LimiteSolicitado float64 `json:"limite_solicitado" binding:"required,gt=0"`
+	Observacoes      string  `json:"observacoes" binding:"max=1000"`
+}
+
+// AprovarHabilitacaoRequest representa requisição de aprovação; com caução, a habilitação só vale após a confirmação
+// do depósito
+type AprovarHabilitacaoRequest struct {
+	Garantia       TipoGarantiaLeilao `json:"garantia" binding:"required,oneof=limite_credito caucao"`
+	LimiteAprovado float64            `json:"limite_aprovado" binding:"required,gt=0"`
+	ValorCaucao    *float64           `json:"valor_caucao" binding:"omitempty,gt=0"`
+}
+
+// RejeitarHabilitacaoRequest representa requisição de rejeição da habilitação
+type RejeitarHabilitacaoRequest struct {
+	Motivo string `json:"motivo" binding:"required,max=500"`
+}
+
+// ConfirmarCaucaoRequest representa a confirmação do depósito da caução
+type ConfirmarCaucaoRequest struct {
+	Referencia string `json:"referencia" binding:"required,max=100"`
+}
@@ -21,6 +21,11 @@ type Leilao struct {
 	Status                 StatusLeilao   `json:"status" gorm:"size:20;default:'agendado'"`
 	TotalArrecadado        *float64       `json:"total_arrecadado" gorm:"type:decimal(15,2)"`
 	TotalComissoes         *float64       `json:"total_comissoes" gorm:"type:decimal(15,2)"`
+	PublicadoEm            *time.Time     `json:"publicado_em,omitempty"`                    // primeira publicação do catálogo
+	VersaoCatalogo         int            `json:"versao_catalogo" gorm:"not null;default:0"` // última versão publicada
+	IniciadoEm             *time.Time     `json:"iniciado_em,omitempty"`
+	EncerradoEm            *time.Time     `json:"encerrado_em,omitempty"` // encerramento ou cancelamento
+	MotivoCancelamento     string         `json:"motivo_cancelamento,omitempty" gorm:"size:500"`
 	CreatedAt              time.Time      `json:"created_at"`
 	UpdatedAt              time.Time      `json:"updated_at"`
 	DeletedAt              gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
@@ -29,6 +34,15 @@ type Leilao struct {
 	Participacoes []ParticipacaoLeilao   `json:"participacoes,omitempty" gorm:"foreignKey:LeilaoID"`
 }
 
+// CalcularComissao comissão do leiloeiro sobre um lote vendido: percentual sobre o valor mais a taxa fixa, se houver
+func (l *Leilao) CalcularComissao(valorVendido float64) float64 {
+	comissao := valorVendido * (l.TaxaComissaoPercentual / 100)
+	if l.TaxaFixa != nil {
+		comissao += *l.TaxaFixa
+	}
+	return comissao
+}
+
 // TipoLeilao define os tipos de leilão
 type TipoLeilao string
 
@@ -48,6 +62,22 @@ const (
 	StatusLeilaoCancelado   StatusLeilao = "cancelado"
 )
 
+// transicoesLeilao ciclo de vida do leilão: agendado → em_andamento → encerrado, com cancelamento antes do encerramento
+var transicoesLeilao = map[StatusLeilao][]StatusLeilao{
+	StatusLeilaoAgendado:    {StatusLeilaoEmAndamento, StatusLeilaoCancelado},
+	StatusLeilaoEmAndamento: {StatusLeilaoEncerrado, StatusLeilaoCancelado},
+}
+
+// PermiteTransicao indica se o leilão pode passar do status atual para o destino
+func (s StatusLeilao) PermiteTransicao(destino StatusLeilao) bool {
+	for _, permitido := range transicoesLeilao[s] {
+		if permitido == destino {
+			return true
+		}
+	}
+	return false
+}
+
 // ParticipacaoLeilao representa a participação de um equino em um leilão
 type ParticipacaoLeilao struct {
 	ID                  uint           `json:"id" gorm:"primaryKey"`
@@ -64,6 +94,8 @@ type ParticipacaoLeilao struct {
 	ComissaoLeiloeiro   *float64       `json:"comissao_leiloeiro" gorm:"type:decimal(15,2)"`
 	Compareceu          *bool          `json:"compareceu"`
 	PenalizacaoAusencia *int           `json:"penalizacao_ausencia"`
+	NumeroLote          *int           `json:"numero_lote" gorm:"index"`
+	Sessao              *int           `json:"sessao"`
 	CreatedAt           time.Time      `json:"created_at"`
 	UpdatedAt           time.Time      `json:"updated_at"`
 	DeletedAt           gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
@@ -96,7 +128,8 @@ type CreateParticipacaoLeilaoRequest struct {
 // RegistrarVendaRequest representa a requisição para registrar venda
 type RegistrarVendaRequest struct {
 	ValorVendido float64 `json:"valor_vendido" validate:"required,gt=0"`
-	CompradorID  uint    `json:"comprador_id" validate:"required"`
+	CompradorID  uint    `json:"comprador_id" validate:"required_without=Paleta"`
+	Paleta       *int    `json:"paleta"` // identifica o arrematante habilitado no pregão, no lugar do comprador_id
 }
 
 // ParticipacaoLeilaoResponse representa a resposta
@@ -120,6 +153,8 @@ type ParticipacaoLeilaoResponse struct {
 	ComissaoLeiloeiro   *float64                 `json:"comissao_leiloeiro"`
 	Compareceu          *bool                    `json:"compareceu"`
 	PenalizacaoAusencia *int                     `json:"penalizacao_ausencia"`
+	NumeroLote          *int                     `json:"numero_lote"`
+	Sessao              *int                     `json:"sessao"`
 	CreatedAt           time.Time                `json:"created_at"`
 }
 
@@ -140,6 +175,8 @@ func (p *ParticipacaoLeilao) ToResponse() *ParticipacaoLeilaoResponse {
 		ComissaoLeiloeiro:   p.ComissaoLeiloeiro,
 		Compareceu:          p.Compareceu,
 		PenalizacaoAusencia: p.PenalizacaoAusencia,
+		NumeroLote:          p.NumeroLote,
+		Sessao:              p.Sessao,
 		CreatedAt:           p.CreatedAt,
 	}
 
@@ -164,3 +201,32 @@ func (p *ParticipacaoLeilao) ToResponse() *ParticipacaoLeilaoResponse {
 func (ParticipacaoLeilao) TableName() string {
 	return "participacoes_leiloes"
 }
+
+// CancelarLeilaoRequest representa requisição de cancelamento do leilão
+type CancelarLeilaoRequest struct {
+	Motivo string `json:"motivo" binding:"required,max=500"`
+}
+
+// RelatorioLiquidacaoLeilao fechamento do leilão encerrado: totais persistidos no leilão, lotes vendidos e não vendidos
+// e ausências penalizadas
+type RelatorioLiquidacaoLeilao struct {
+	LeilaoID               uint                          `json:"leilao_id"`
+	Nome                   string                        `json:"nome"`
+	Status                 StatusLeilao                  `json:"status"`
+	DataInicio             time.Time                     `json:"data_inicio"`
+	DataFim                time.Time                     `json:"data_fim"`
+	EncerradoEm            *time.Time                    `json:"encerrado_em,omitempty"`
+	TaxaComissaoPercentual float64                       `json:"taxa_comissao_percentual"`
+	TaxaFixa               *float64                      `json:"taxa_fixa,omitempty"`
+	TotalLotes             int                           `json:"total_lotes"`
+	LotesVendidos          int                           `json:"lotes_vendidos"`
+	LotesNaoVendidos       int                           `json:"lotes_nao_vendidos"`
+	TotalArrecadado        float64                       `json:"total_arrecadado"`
+	TotalComissoes         float64                       `json:"total_comissoes"`
+	LiquidoVendedores      float64                       `json:"liquido_vendedores"` // arrecadado menos comissões
+	Ausencias              int                           `json:"ausencias"`
+	PenalizacaoTotal       int                           `json:"penalizacao_total"`
+	Vendidos               []*ParticipacaoLeilaoResponse `json:"vendidos"`
+	NaoVendidos            []*ParticipacaoLeilaoResponse `json:"nao_vendidos"`
+	Ausentes               []*ParticipacaoLeilaoResponse `json:"ausentes"`
+}
@@ -7,23 +7,167 @@ import (
 )
 
 type AnuncioMarketplace struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	UsuarioID uint           `json:"usuario_id" gorm:"not null"`
-	Tipo      string         `json:"tipo" gorm:"size:50;not null"` // 'animal', 'semen', 'embrio', 'equipamento'
-	Titulo    string         `json:"titulo" gorm:"size:200;not null"`
-	Descricao string         `json:"descricao" gorm:"type:text"`
-	Preco     float64        `json:"preco" gorm:"type:decimal(15,2);not null"`
-	Equinoid  *string        `json:"equinoid" gorm:"size:25"`
-	Fotos     JSONB          `json:"fotos" gorm:"type:jsonb"`
-	Status    string         `json:"status" gorm:"size:20;default:'ativo'"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
-
-	Usuario *User   `json:"vendedor,omitempty" gorm:"foreignKey:UsuarioID"`
-	Equino  *Equino `json:"equino,omitempty" gorm:"foreignKey:Equinoid;references:Equinoid"`
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	UsuarioID        uint           `json:"usuario_id" gorm:"not null;index"`
+	Tipo             TipoAnuncio    `json:"tipo" gorm:"size:50;not null"`
+	Titulo           string         `json:"titulo" gorm:"size:200;not null"`
+	Descricao        string         `json:"descricao" gorm:"type:text"`
+	Preco            float64        `json:"preco" gorm:"type:decimal(15,2);not null"`
+	Quantidade       int            `json:"quantidade" gorm:"not null;default:1"` // doses, embriões ou unidades
+	Equinoid         *string        `json:"equinoid" gorm:"size:25;index"`
+	AvaliacaoSemenID *uint          `json:"avaliacao_semen_id,omitempty"`
+	Estado           string         `json:"estado,omitempty" gorm:"size:2"`
+	Cidade           string         `json:"cidade,omitempty" gorm:"size:100"`
+	Status           StatusAnuncio  `json:"status" gorm:"size:20;not null;default:'rascunho';index"`
+	PublicadoEm      *time.Time     `json:"publicado_em,omitempty"`
+	ExpiraEm         *time.Time     `json:"expira_em,omitempty" gorm:"index"`
+	VendidoEm        *time.Time     `json:"vendido_em,omitempty"`
+	CompradorID      *uint          `json:"comprador_id,omitempty" gorm:"index"`
+	ConfirmadoEm     *time.Time     `json:"confirmado_em,omitempty"` // recebimento confirmado pelo comprador
+	TotalFavoritos   int            `json:"total_favoritos" gorm:"not null;default:0"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+
+	Usuario        *User           `json:"vendedor,omitempty" gorm:"foreignKey:UsuarioID"`
+	Equino         *Equino         `json:"equino,omitempty" gorm:"foreignKey:Equinoid;references:Equinoid"`
+	AvaliacaoSemen *AvaliacaoSemen `json:"avaliacao_semen,omitempty" gorm:"foreignKey:AvaliacaoSemenID"`
+	Fotos          []FotoAnuncio   `json:"fotos,omitempty" gorm:"foreignKey:AnuncioID"`
+	Favorito       bool            `json:"favorito" gorm:"-"`
 }
 
 func (AnuncioMarketplace) TableName() string {
 	return "marketplace.anuncios"
 }
+
+// IsEditavel anúncio vendido não muda mais; o registro sustenta a avaliação do vendedor
+func (a *AnuncioMarketplace) IsEditavel() bool {
+	return a.Status != StatusAnuncioVendido
+}
+
+// IsPublico anúncios visíveis a qualquer usuário; os demais só ao vendedor
+func (a *AnuncioMarketplace) IsPublico() bool {
+	return a.Status == StatusAnuncioAtivo || a.Status == StatusAnuncioVendido
+}
+
+// TipoAnuncio define o que está sendo vendido
+type TipoAnuncio string
+
+const (
+	TipoAnuncioAnimal      TipoAnuncio = "animal"
+	TipoAnuncioSemen       TipoAnuncio = "semen"
+	TipoAnuncioEmbriao     TipoAnuncio = "embrio"
+	TipoAnuncioEquipamento TipoAnuncio = "equipamento"
+)
+
+// IsValidTipoAnuncio verifica se o tipo de anúncio é suportado
+func IsValidTipoAnuncio(tipo TipoAnuncio) bool {
+	switch tipo {
+	case TipoAnuncioAnimal, TipoAnuncioSemen, TipoAnuncioEmbriao, TipoAnuncioEquipamento:
+		return true
+	}
+	return false
+}
+
+// StatusAnuncio define a etapa do ciclo de vida do anúncio
+type StatusAnuncio string
+
+const (
+	StatusAnuncioRascunho StatusAnuncio = "rascunho"
+	StatusAnuncioAtivo    StatusAnuncio = "ativo"
+	StatusAnuncioPausado  StatusAnuncio = "pausado"
+	StatusAnuncioVendido  StatusAnuncio = "vendido"
+	StatusAnuncioExpirado StatusAnuncio = "expirado"
+)
+
+// FotoAnuncio imagem do anúncio guardada no armazenamento de arquivos
+type FotoAnuncio struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	AnuncioID  uint      `json:"anuncio_id" gorm:"not null;index"`
+	StorageKey string    `json:"-" gorm:"size:500;not null"`
+	MimeType   string    `json:"mime_type" gorm:"size:100;not null"`
+	Tamanho    int64     `json:"tamanho"`
+	Ordem      int       `json:"ordem" gorm:"not null;default:0"`
+	EnviadoPor uint      `json:"enviado_por" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	URL string `json:"url,omitempty" gorm:"-"`
+}
+
+func (FotoAnuncio) TableName() string {
+	return "marketplace.fotos"
+}
+
+// FavoritoAnuncio anúncio salvo por um usuário
+type FavoritoAnuncio struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UsuarioID uint      `json:"usuario_id" gorm:"not null;uniqueIndex:idx_marketplace_favorito"`
+	AnuncioID uint      `json:"anuncio_id" gorm:"not null;uniqueIndex:idx_marketplace_favorito;index"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Anuncio *AnuncioMarketplace `json:"anuncio,omitempty" gorm:"foreignKey:AnuncioID"`
+}
+
+func (FavoritoAnuncio) TableName() string {
+	return "marketplace.favoritos"
+}
+
+// AvaliacaoVendedor nota dada pelo comprador ao vendedor depois da venda concluída; uma por anúncio
+type AvaliacaoVendedor struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	AnuncioID   uint      `json:"anuncio_id" gorm:"not null;uniqueIndex"`
+	VendedorID  uint      `json:"vendedor_id" gorm:"not null;index"`
+	CompradorID uint      `json:"comprador_id" gorm:"not null"`
+	Nota        int       `json:"nota" gorm:"not null"`
+	Comentario  string    `json:"comentario,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Comprador *User `json:"comprador,omitempty" gorm:"foreignKey:CompradorID"`
+}
+
+func (AvaliacaoVendedor) TableName() string {
+	return "marketplace.avaliacoes_vendedor"
+}
+
+// CreateAnuncioRequest dados de um novo anúncio, criado como rascunho
+type CreateAnuncioRequest struct {
+	Tipo       TipoAnuncio `json:"tipo" binding:"required"`
+	Titulo     string      `json:"titulo" binding:"required,max=200"`
+	Descricao  string      `json:"descricao"`
+	Preco      float64     `json:"preco" binding:"required,gt=0"`
+	Quantidade int         `json:"quantidade" binding:"omitempty,min=1"`
+	Equinoid   string      `json:"equinoid"`
+	Estado     string      `json:"estado" binding:"omitempty,len=2"`
+	Cidade     string      `json:"cidade" binding:"omitempty,max=100"`
+}
+
+// UpdateAnuncioRequest campos alteráveis do anúncio; o tipo não muda depois de criado
+type UpdateAnuncioRequest struct {
+	Titulo     *string  `json:"titulo" binding:"omitempty,min=1,max=200"`
+	Descricao  *string  `json:"descricao"`
+	Preco      *float64 `json:"preco" binding:"omitempty,gt=0"`
+	Quantidade *int     `json:"quantidade" binding:"omitempty,min=1"`
+	Equinoid   *string  `json:"equinoid"`
+	Estado     *string  `json:"estado" binding:"omitempty,len=2"`
+	Cidade     *string  `json:"cidade" binding:"omitempty,max=100"`
+}
+
+// VenderAnuncioRequest registro da venda; com o comprador informado ele pode avaliar o vendedor
+type VenderAnuncioRequest struct {
+	CompradorID *uint `json:"comprador_id"`
+}
+
+// AvaliarVendedorRequest nota de 1 a 5 do comprador
+type AvaliarVendedorRequest struct {
+	Nota       int    `json:"nota" binding:"required,min=1,max=5"`
+	Comentario string `json:"comentario" binding:"omitempty,max=2000"`
+}
+
+// ReputacaoVendedor média e avaliações recebidas pelo vendedor
+type ReputacaoVendedor struct {
+	VendedorID  uint                 `json:"vendedor_id"`
+	Media       float64              `json:"media"`
+	Total       int64                `json:"total"`
+	TotalVendas int64                `json:"total_vendas"`
+	Avaliacoes  []*AvaliacaoVendedor `json:"avaliacoes"`
+}
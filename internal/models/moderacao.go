@@ -0,0 +1,220 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TipoConteudoModerado conteúdo da rede social sujeito a moderação
+type TipoConteudoModerado string
+
+const (
+	ConteudoModeradoPost       TipoConteudoModerado = "post"
+	ConteudoModeradoComentario TipoConteudoModerado = "comentario"
+)
+
+// TipoRegraModeracao forma de comparação da regra com o texto publicado
+type TipoRegraModeracao string
+
+const (
+	// RegraPalavraChave palavra ou expressão comparada sem distinção de maiúsculas e acentos, respeitando limites de palavra
+	RegraPalavraChave TipoRegraModeracao = "palavra_chave"
+	// RegraRegex expressão regular (RE2) aplicada sem distinção de maiúsculas
+	RegraRegex TipoRegraModeracao = "regex"
+	// RegraLink domínio bloqueado nos links do texto, incluindo subdomínios; "*" bloqueia qualquer link
+	RegraLink TipoRegraModeracao = "link"
+)
+
+// AcaoModeracao efeito de uma regra no publish ou de uma decisão do moderador
+type AcaoModeracao string
+
+const (
+	// AcaoBloquear recusa a publicação e conta como infração do autor
+	AcaoBloquear AcaoModeracao = "bloquear"
+	// AcaoOcultar publica oculto (shadow-hide): só o autor vê, e o caso entra na fila
+	AcaoOcultar AcaoModeracao = "ocultar"
+	// AcaoRevisar publica normalmente e envia o caso para a fila de moderação
+	AcaoRevisar AcaoModeracao = "revisar"
+	// AcaoRetirar retira o conteúdo do ar por decisão do moderador (takedown), sujeito a recurso
+	AcaoRetirar AcaoModeracao = "retirar"
+	// AcaoManter mantém ou restaura o conteúdo no ar
+	AcaoManter AcaoModeracao = "manter"
+)
+
+// OrigemCasoModeracao o que abriu o caso na fila
+type OrigemCasoModeracao string
+
+const (
+	OrigemDenuncia OrigemCasoModeracao = "denuncia"
+	OrigemFiltro   OrigemCasoModeracao = "filtro"
+)
+
+// StatusCasoModeracao situação do caso na fila
+type StatusCasoModeracao string
+
+const (
+	StatusCasoPendente  StatusCasoModeracao = "pendente"
+	StatusCasoResolvido StatusCasoModeracao = "resolvido"
+	// StatusCasoRevertido decisão de retirada desfeita por recurso deferido
+	StatusCasoRevertido StatusCasoModeracao = "revertido"
+)
+
+// MotivoDenuncia motivo informado por quem denuncia
+type MotivoDenuncia string
+
+const (
+	MotivoDenunciaSpam              MotivoDenuncia = "spam"
+	MotivoDenunciaAbuso             MotivoDenuncia = "abuso"
+	MotivoDenunciaFraude            MotivoDenuncia = "fraude"
+	MotivoDenunciaConteudoImproprio MotivoDenuncia = "conteudo_improprio"
+	MotivoDenunciaOutro             MotivoDenuncia = "outro"
+)
+
+// StatusRecursoModeracao situação do recurso do autor contra a retirada
+type StatusRecursoModeracao string
+
+const (
+	StatusRecursoPendente   StatusRecursoModeracao = "pendente"
+	StatusRecursoDeferido   StatusRecursoModeracao = "deferido"
+	StatusRecursoIndeferido StatusRecursoModeracao = "indeferido"
+)
+
+// IsValidMotivoDenuncia verifica se o motivo da denúncia é suportado
+func IsValidMotivoDenuncia(motivo MotivoDenuncia) bool {
+	switch motivo {
+	case MotivoDenunciaSpam, MotivoDenunciaAbuso, MotivoDenunciaFraude, MotivoDenunciaConteudoImproprio, MotivoDenunciaOutro:
+		return true
+	}
+	return false
+}
+
+// RegraModeracao filtro configurável aplicado a posts e comentários antes da publicação
+type RegraModeracao struct {
+	ID        uint               `json:"id" gorm:"primaryKey"`
+	Tipo      TipoRegraModeracao `json:"tipo" gorm:"size:20;not null"`
+	Padrao    string             `json:"padrao" gorm:"size:500;not null"`
+	Acao      AcaoModeracao      `json:"acao" gorm:"size:20;not null"`
+	Descricao string             `json:"descricao" gorm:"size:255"`
+	Ativa     bool               `json:"ativa" gorm:"not null;default:true;index"`
+	CriadoPor uint               `json:"criado_por" gorm:"not null"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	DeletedAt gorm.DeletedAt     `json:"-" gorm:"index"`
+}
+
+func (RegraModeracao) TableName() string { return "moderacao_regras" }
+
+// CasoModeracao item da fila de moderação; um único caso pendente por conteúdo agrega todas as denúncias
+type CasoModeracao struct {
+	ID              uint                 `json:"id" gorm:"primaryKey"`
+	ConteudoTipo    TipoConteudoModerado `json:"conteudo_tipo" gorm:"size:20;not null;index:idx_moderacao_casos_aberto,unique,where:status = 'pendente'"`
+	ConteudoID      uint                 `json:"conteudo_id" gorm:"not null;index:idx_moderacao_casos_aberto,unique,where:status = 'pendente'"`
+	AutorID         uint                 `json:"autor_id" gorm:"not null;index"`
+	Origem          OrigemCasoModeracao  `json:"origem" gorm:"size:20;not null"`
+	RegrasAcionadas JSONB                `json:"regras_acionadas,omitempty" gorm:"type:jsonb"`
+	TotalDenuncias  int                  `json:"total_denuncias" gorm:"not null;default:0"`
+	Status          StatusCasoModeracao  `json:"status" gorm:"size:20;not null;default:'pendente';index"`
+	Decisao         *AcaoModeracao       `json:"decisao,omitempty" gorm:"size:20"`
+	MotivoDecisao   string               `json:"motivo_decisao,omitempty" gorm:"type:text"`
+	ModeradorID     *uint                `json:"moderador_id,omitempty"`
+	DecididoEm      *time.Time           `json:"decidido_em,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+
+	// Relacionamentos
+	Autor     *User              `json:"autor,omitempty" gorm:"foreignKey:AutorID"`
+	Denuncias []DenunciaConteudo `json:"denuncias,omitempty" gorm:"foreignKey:CasoID"`
+	Recurso   *RecursoModeracao  `json:"recurso,omitempty" gorm:"foreignKey:CasoID"`
+}
+
+func (CasoModeracao) TableName() string { return "moderacao_casos" }
+
+// DenunciaConteudo denúncia de um usuário; cada usuário denuncia o mesmo caso uma única vez
+type DenunciaConteudo struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CasoID        uint           `json:"caso_id" gorm:"not null;uniqueIndex:idx_moderacao_denuncias_caso_denunciante"`
+	DenuncianteID uint           `json:"denunciante_id" gorm:"not null;uniqueIndex:idx_moderacao_denuncias_caso_denunciante"`
+	Motivo        MotivoDenuncia `json:"motivo" gorm:"size:30;not null"`
+	Descricao     string         `json:"descricao,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+func (DenunciaConteudo) TableName() string { return "moderacao_denuncias" }
+
+// RecursoModeracao pedido do autor para rever uma retirada; um por caso
+type RecursoModeracao struct {
+	ID            uint                   `json:"id" gorm:"primaryKey"`
+	CasoID        uint                   `json:"caso_id" gorm:"not null;uniqueIndex"`
+	AutorID       uint                   `json:"autor_id" gorm:"not null;index"`
+	Justificativa string                 `json:"justificativa" gorm:"type:text;not null"`
+	Status        StatusRecursoModeracao `json:"status" gorm:"size:20;not null;default:'pendente';index"`
+	RevisorID     *uint                  `json:"revisor_id,omitempty"`
+	Resposta      string                 `json:"resposta,omitempty" gorm:"type:text"`
+	DecididoEm    *time.Time             `json:"decidido_em,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+
+	// Relacionamentos
+	Caso *CasoModeracao `json:"caso,omitempty" gorm:"foreignKey:CasoID"`
+}
+
+func (RecursoModeracao) TableName() string { return "moderacao_recursos" }
+
+// InfracaoModeracao conteúdo bloqueado, oculto ou retirado que conta para o limite de reincidência do autor
+type InfracaoModeracao struct {
+	ID           uint                 `json:"id" gorm:"primaryKey"`
+	UserID       uint                 `json:"user_id" gorm:"not null;index:idx_moderacao_infracoes_user_data"`
+	CasoID       *uint                `json:"caso_id,omitempty" gorm:"index"`
+	RegraID      *uint                `json:"regra_id,omitempty"`
+	ConteudoTipo TipoConteudoModerado `json:"conteudo_tipo" gorm:"size:20;not null"`
+	ConteudoID   *uint                `json:"conteudo_id,omitempty"`
+	Motivo       string               `json:"motivo" gorm:"size:255"`
+	Anulada      bool                 `json:"anulada" gorm:"not null;default:false"`
+	CreatedAt    time.Time            `json:"created_at" gorm:"index:idx_moderacao_infracoes_user_data"`
+}
+
+func (InfracaoModeracao) TableName() string { return "moderacao_infracoes" }
+
+// AvaliacaoModeracao resultado dos filtros para um texto prestes a ser publicado
+type AvaliacaoModeracao struct {
+	// Status com que o conteúdo deve ser gravado: ativo ou oculto
+	Status StatusPost `json:"status"`
+	// Revisar indica que o conteúdo deve entrar na fila após ser gravado
+	Revisar bool   `json:"revisar"`
+	Regras  []uint `json:"regras,omitempty"`
+	Motivo  string `json:"motivo,omitempty"`
+}
+
+type CreateRegraModeracaoRequest struct {
+	Tipo      TipoRegraModeracao `json:"tipo" binding:"required"`
+	Padrao    string             `json:"padrao" binding:"required"`
+	Acao      AcaoModeracao      `json:"acao" binding:"required"`
+	Descricao string             `json:"descricao"`
+}
+
+type UpdateRegraModeracaoRequest struct {
+	Padrao    *string        `json:"padrao"`
+	Acao      *AcaoModeracao `json:"acao"`
+	Descricao *string        `json:"descricao"`
+	Ativa     *bool          `json:"ativa"`
+}
+
+type DenunciarConteudoRequest struct {
+	Motivo    MotivoDenuncia `json:"motivo" binding:"required"`
+	Descricao string         `json:"descricao"`
+}
+
+type DecidirCasoRequest struct {
+	Acao   AcaoModeracao `json:"acao" binding:"required"`
+	Motivo string        `json:"motivo" binding:"required"`
+}
+
+type CreateRecursoRequest struct {
+	Justificativa string `json:"justificativa" binding:"required"`
+}
+
+type DecidirRecursoRequest struct {
+	Deferido *bool  `json:"deferido" binding:"required"`
+	Resposta string `json:"resposta" binding:"required"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Notificacao aviso interno entregue ao usuário, com a entidade de origem para o aplicativo abrir o detalhe
+type Notificacao struct {
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	UserID         uint            `json:"user_id" gorm:"not null;index:idx_notificacoes_user_lida"`
+	Tipo           TipoNotificacao `json:"tipo" gorm:"size:40;not null"`
+	Titulo         string          `json:"titulo" gorm:"size:150;not null"`
+	Mensagem       string          `json:"mensagem" gorm:"type:text"`
+	ReferenciaTipo string          `json:"referencia_tipo,omitempty" gorm:"size:40"`
+	ReferenciaID   *uint           `json:"referencia_id,omitempty"`
+	LidaEm         *time.Time      `json:"lida_em,omitempty" gorm:"index:idx_notificacoes_user_lida"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// TableName especifica o nome da tabela
+func (Notificacao) TableName() string {
+	return "notificacoes"
+}
+
+// TipoNotificacao evento que originou a notificação
+type TipoNotificacao string
+
+const (
+	NotificacaoOfertaRecebida       TipoNotificacao = "oferta_recebida"
+	NotificacaoOfertaContraproposta TipoNotificacao = "oferta_contraproposta"
+	NotificacaoOfertaAceita         TipoNotificacao = "oferta_aceita"
+	NotificacaoOfertaRecusada       TipoNotificacao = "oferta_recusada"
+	NotificacaoOfertaCancelada      TipoNotificacao = "oferta_cancelada"
+	NotificacaoOfertaExpirada       TipoNotificacao = "oferta_expirada"
+	NotificacaoOfertaConcluida      TipoNotificacao = "oferta_concluida"
+
+	NotificacaoAnuncioVendido   TipoNotificacao = "anuncio_vendido"
+	NotificacaoAnuncioExpirado  TipoNotificacao = "anuncio_expirado"
+	NotificacaoVendedorAvaliado TipoNotificacao = "vendedor_avaliado"
+
+	NotificacaoPagamentoConfirmado TipoNotificacao = "pagamento_confirmado"
+	NotificacaoPagamentoLiberado   TipoNotificacao = "pagamento_liberado"
+	NotificacaoPagamentoEstornado  TipoNotificacao = "pagamento_estornado"
+)
+
+// NovaNotificacao dados de uma notificação a entregar
+type NovaNotificacao struct {
+	Tipo           TipoNotificacao
+	Titulo         string
+	Mensagem       string
+	ReferenciaTipo string
+	ReferenciaID   *uint
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"time"
+)
+
+// Pagamento cobrança de uma venda (leilão ou marketplace) com o valor retido em custódia até a entrega: para animais,
+// a transferência de propriedade ao comprador; para os demais itens, a confirmação de recebimento
+type Pagamento struct {
+	ID                 uint              `json:"id" gorm:"primaryKey"`
+	Origem             OrigemPagamento   `json:"origem" gorm:"size:20;not null;index:idx_pagamentos_origem"`
+	OrigemID           uint              `json:"origem_id" gorm:"not null;index:idx_pagamentos_origem"`
+	CompradorID        uint              `json:"comprador_id" gorm:"not null;index"`
+	VendedorID         uint              `json:"vendedor_id" gorm:"not null;index"`
+	LeiloeiroID        *uint             `json:"leiloeiro_id,omitempty" gorm:"index"`
+	EquinoID           *uint             `json:"equino_id,omitempty"`
+	Equinoid           *string           `json:"equinoid,omitempty" gorm:"size:25;index"`
+	ExigeTransferencia bool              `json:"exige_transferencia" gorm:"not null;default:false"`
+	Descricao          string            `json:"descricao" gorm:"size:500;not null"`
+	Valor              float64           `json:"valor" gorm:"type:decimal(15,2);not null"`
+	ComissaoLeiloeiro  float64           `json:"comissao_leiloeiro" gorm:"type:decimal(15,2);not null;default:0"`
+	TaxaPlataforma     float64           `json:"taxa_plataforma" gorm:"type:decimal(15,2);not null;default:0"`
+	ValorVendedor      float64           `json:"valor_vendedor" gorm:"type:decimal(15,2);not null"`
+	Metodo             MetodoPagamento   `json:"metodo" gorm:"size:20;not null"`
+	Parcelas           int               `json:"parcelas" gorm:"not null;default:1"`
+	Provedor           string            `json:"provedor" gorm:"size:30;not null"`
+	CobrancaID         *string           `json:"-" gorm:"size:100;uniqueIndex"`
+	PixCopiaECola      string            `json:"pix_copia_e_cola,omitempty" gorm:"type:text"`
+	BoletoLinha        string            `json:"boleto_linha_digitavel,omitempty" gorm:"size:100"`
+	BoletoURL          string            `json:"boleto_url,omitempty" gorm:"size:500"`
+	Status             SituacaoPagamento `json:"status" gorm:"size:30;not null;default:'aguardando_pagamento';index"`
+	MotivoFalha        string            `json:"motivo_falha,omitempty" gorm:"size:500"`
+	VenceEm            *time.Time        `json:"vence_em,omitempty"`
+	PagoEm             *time.Time        `json:"pago_em,omitempty"`
+	LiberarAte         *time.Time        `json:"liberar_ate,omitempty" gorm:"index"` // fim do prazo de custódia
+	LiberadoEm         *time.Time        `json:"liberado_em,omitempty"`
+	EstornadoEm        *time.Time        `json:"estornado_em,omitempty"`
+	EstornoID          *string           `json:"-" gorm:"size:100"`
+	MotivoEstorno      string            `json:"motivo_estorno,omitempty" gorm:"type:text"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+
+	Comprador *User              `json:"comprador,omitempty" gorm:"foreignKey:CompradorID"`
+	Vendedor  *User              `json:"vendedor,omitempty" gorm:"foreignKey:VendedorID"`
+	Repasses  []RepassePagamento `json:"repasses,omitempty" gorm:"foreignKey:PagamentoID"`
+}
+
+// TableName especifica o nome da tabela
+func (Pagamento) TableName() string {
+	return "pagamentos"
+}
+
+// IsParte comprador ou vendedor do pagamento
+func (p *Pagamento) IsParte(userID uint) bool {
+	return p.CompradorID == userID || p.VendedorID == userID
+}
+
+// RepassePagamento parcela do valor em custódia destinada a um beneficiário: o vendedor, o leiloeiro (comissão) ou a
+// plataforma (taxa, que permanece na conta da plataforma)
+type RepassePagamento struct {
+	ID           uint             `json:"id" gorm:"primaryKey"`
+	PagamentoID  uint             `json:"pagamento_id" gorm:"not null;index"`
+	Beneficiario TipoBeneficiario `json:"beneficiario" gorm:"size:20;not null"`
+	UsuarioID    *uint            `json:"usuario_id,omitempty" gorm:"index"`
+	Valor        float64          `json:"valor" gorm:"type:decimal(15,2);not null"`
+	Status       SituacaoRepasse  `json:"status" gorm:"size:20;not null;default:'pendente'"`
+	RepasseID    *string          `json:"-" gorm:"size:100"`
+	Tentativas   int              `json:"tentativas" gorm:"not null;default:0"`
+	UltimoErro   string           `json:"ultimo_erro,omitempty" gorm:"size:500"`
+	EnviadoEm    *time.Time       `json:"enviado_em,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// TableName especifica o nome da tabela
+func (RepassePagamento) TableName() string {
+	return "pagamentos_repasses"
+}
+
+// OrigemPagamento venda que originou o pagamento
+type OrigemPagamento string
+
+const (
+	OrigemPagamentoLeilao      OrigemPagamento = "leilao"
+	OrigemPagamentoMarketplace OrigemPagamento = "marketplace"
+)
+
+// MetodoPagamento meio escolhido pelo comprador
+type MetodoPagamento string
+
+const (
+	MetodoPagamentoPix    MetodoPagamento = "pix"
+	MetodoPagamentoBoleto MetodoPagamento = "boleto"
+	MetodoPagamentoCartao MetodoPagamento = "cartao"
+)
+
+// IsValidMetodoPagamento verifica se o meio de pagamento é suportado
+func IsValidMetodoPagamento(metodo MetodoPagamento) bool {
+	switch metodo {
+	case MetodoPagamentoPix, MetodoPagamentoBoleto, MetodoPagamentoCartao:
+		return true
+	}
+	return false
+}
+
+// SituacaoPagamento etapa do pagamento, da cobrança ao repasse ou estorno
+type SituacaoPagamento string
+
+const (
+	PagamentoAguardando  SituacaoPagamento = "aguardando_pagamento"
+	PagamentoEmCustodia  SituacaoPagamento = "em_custodia"
+	PagamentoEmLiberacao SituacaoPagamento = "em_liberacao" // repasses em envio; falhas são repetidas pela rotina
+	PagamentoLiberado    SituacaoPagamento = "liberado"
+	PagamentoEstornado   SituacaoPagamento = "estornado"
+	PagamentoExpirado    SituacaoPagamento = "expirado"
+	PagamentoRecusado    SituacaoPagamento = "recusado"
+)
+
+// TipoBeneficiario destino de um repasse
+type TipoBeneficiario string
+
+const (
+	BeneficiarioVendedor   TipoBeneficiario = "vendedor"
+	BeneficiarioLeiloeiro  TipoBeneficiario = "leiloeiro"
+	BeneficiarioPlataforma TipoBeneficiario = "plataforma"
+)
+
+// SituacaoRepasse etapa do envio de um repasse
+type SituacaoRepasse string
+
+const (
+	RepassePendente SituacaoRepasse = "pendente"
+	RepassePago     SituacaoRepasse = "pago"
+	RepasseFalhou   SituacaoRepasse = "falhou"
+)
+
+// CheckoutPagamentoRequest representa requisição de pagamento de uma compra
+type CheckoutPagamentoRequest struct {
+	Metodo      MetodoPagamento `json:"metodo" binding:"required"`
+	TokenCartao string          `json:"token_cartao"`
+	Parcelas    int             `json:"parcelas" binding:"omitempty,min=1,max=12"`
+}
+
+// EstornarPagamentoRequest representa requisição de estorno ao comprador
+type EstornarPagamentoRequest struct {
+	Motivo string `json:"motivo" binding:"required,max=1000"`
+}
+
+// ResumoConciliacao totais dos pagamentos do período e lançamentos financeiros divergentes do estado dos pagamentos
+type ResumoConciliacao struct {
+	De                time.Time                `json:"de"`
+	Ate               time.Time                `json:"ate"`
+	Pagamentos        int                      `json:"pagamentos"`
+	Recebido          float64                  `json:"recebido"`
+	EmCustodia        float64                  `json:"em_custodia"`
+	RepassadoVendedor float64                  `json:"repassado_vendedor"`
+	Comissoes         float64                  `json:"comissoes"`
+	TaxasPlataforma   float64                  `json:"taxas_plataforma"`
+	Estornado         float64                  `json:"estornado"`
+	Divergencias      []DivergenciaConciliacao `json:"divergencias"`
+	Corrigidas        int                      `json:"corrigidas"`
+}
+
+// DivergenciaConciliacao lançamento ausente ou com status diferente do esperado para o pagamento
+type DivergenciaConciliacao struct {
+	PagamentoID  uint            `json:"pagamento_id"`
+	Referencia   string          `json:"referencia"`
+	Esperado     StatusPagamento `json:"esperado"`
+	Lancado      StatusPagamento `json:"lancado,omitempty"` // vazio quando o lançamento não existe
+	Valor        float64         `json:"valor"`
+	ValorLancado float64         `json:"valor_lancado"`
+}
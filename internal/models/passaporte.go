@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// StatusPassaporte situação de um passaporte emitido
+type StatusPassaporte string
+
+const (
+	// PassaporteValido última emissão vigente do equino
+	PassaporteValido StatusPassaporte = "valido"
+	// PassaporteSubstituido emissão anterior, substituída por um passaporte mais recente
+	PassaporteSubstituido StatusPassaporte = "substituido"
+	// PassaporteRevogado emissão cancelada pelo proprietário ou por um administrador
+	PassaporteRevogado StatusPassaporte = "revogado"
+)
+
+// PassaporteEquino passaporte/certificado de genealogia em PDF emitido pela plataforma e assinado com o selo
+// institucional. O QR code impresso aponta para a verificação pública pelo código
+type PassaporteEquino struct {
+	ID                uint             `json:"id" gorm:"primaryKey"`
+	Codigo            string           `json:"codigo" gorm:"size:36;uniqueIndex;not null"`
+	EquinoID          uint             `json:"equino_id" gorm:"not null;index"`
+	Equinoid          string           `json:"equinoid" gorm:"size:24;not null;index"`
+	Status            StatusPassaporte `json:"status" gorm:"size:20;not null;default:'valido';index"`
+	Hash              string           `json:"hash" gorm:"size:64;not null;uniqueIndex"`     // SHA-256 do PDF assinado
+	SignatureHash     string           `json:"signature_hash" gorm:"size:64;not null;index"` // SHA-256 da assinatura CMS do selo
+	CertificadoSerial string           `json:"certificado_serial" gorm:"size:64"`            // série do certificado do selo
+	Tamanho           int64            `json:"tamanho" gorm:"not null"`
+	StorageBackend    string           `json:"-" gorm:"size:20;not null"`
+	StorageKey        string           `json:"-" gorm:"size:500;not null"`
+	URLVerificacao    string           `json:"url_verificacao" gorm:"size:500;not null"`
+	EmitidoPor        uint             `json:"emitido_por" gorm:"not null"`
+	EmitidoEm         time.Time        `json:"emitido_em" gorm:"not null"`
+	RevogadoEm        *time.Time       `json:"revogado_em,omitempty"`
+	RevogadoPor       *uint            `json:"revogado_por,omitempty"`
+	MotivoRevogacao   string           `json:"motivo_revogacao,omitempty" gorm:"type:text"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+func (PassaporteEquino) TableName() string {
+	return "passaportes_equinos"
+}
+
+// RevogarPassaporteRequest cancelamento de um passaporte emitido
+type RevogarPassaporteRequest struct {
+	Motivo string `json:"motivo" binding:"required"`
+}
+
+// VerificacaoPassaporte resultado público da verificação de um passaporte, pelo código do QR code ou pelo arquivo
+type VerificacaoPassaporte struct {
+	Autentico    bool                          `json:"autentico"`
+	Registrado   bool                          `json:"registrado"`
+	Integro      bool                          `json:"integro"`
+	Status       StatusPassaporte              `json:"status,omitempty"`
+	Codigo       string                        `json:"codigo,omitempty"`
+	Equinoid     string                        `json:"equinoid,omitempty"`
+	NomeEquino   string                        `json:"nome_equino,omitempty"`
+	EmitidoEm    *time.Time                    `json:"emitido_em,omitempty"`
+	RevogadoEm   *time.Time                    `json:"revogado_em,omitempty"`
+	Hash         string                        `json:"hash"`
+	Assinaturas  []SignatureVerificationResult `json:"assinaturas"`
+	Mensagem     string                        `json:"mensagem"`
+	VerificadoEm time.Time                     `json:"verificado_em"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Tipos de solicitação aceitos no portal de privacidade
+const (
+	SolicitacaoAcesso        = "access"
+	SolicitacaoPortabilidade = "portability"
+	SolicitacaoExclusao      = "deletion"
+)
+
+// CriarSolicitacaoPrivacidadeRequest representa uma solicitação do titular (LGPD Art. 18)
+type CriarSolicitacaoPrivacidadeRequest struct {
+	Tipo      string   `json:"tipo" binding:"required,oneof=access portability deletion"`
+	Formato   string   `json:"formato" binding:"omitempty,oneof=zip json"`
+	Motivo    string   `json:"motivo" binding:"max=1000"`
+	TiposDado []string `json:"tipos_dado"`
+}
+
+// RecusarSolicitacaoPrivacidadeRequest representa a recusa fundamentada de uma solicitação
+type RecusarSolicitacaoPrivacidadeRequest struct {
+	Motivo string `json:"motivo" binding:"required,max=1000"`
+}
+
+// SolicitacaoPrivacidadeResponse solicitação com o acompanhamento do prazo legal
+type SolicitacaoPrivacidadeResponse struct {
+	ComplianceRecord
+	Vencida        bool `json:"vencida"`
+	DiasRestantes  int  `json:"dias_restantes"`
+	DownloadPronto bool `json:"download_pronto"`
+}
+
+// NewSolicitacaoPrivacidadeResponse calcula prazo e disponibilidade do pacote de uma solicitação
+func NewSolicitacaoPrivacidadeResponse(record ComplianceRecord, now time.Time) SolicitacaoPrivacidadeResponse {
+	resp := SolicitacaoPrivacidadeResponse{
+		ComplianceRecord: record,
+		Vencida:          record.IsOverdue(now),
+	}
+	if record.DueAt != nil && record.ProcessedAt == nil {
+		resp.DiasRestantes = int(math.Ceil(record.DueAt.Sub(now).Hours() / 24))
+	}
+	resp.DownloadPronto = record.ExportPath != "" && (record.ExpiresAt == nil || now.Before(*record.ExpiresAt))
+	return resp
+}
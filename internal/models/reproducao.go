@@ -224,6 +224,17 @@ const (
 	CuidadoMaternoRuim      CuidadoMaterno = "ruim"
 )
 
+// DesempenhoReprodutivo coberturas, gestações e partos de um reprodutor ou matriz no ranking reprodutivo
+type DesempenhoReprodutivo struct {
+	Equinoid         string     `json:"equinoid"`
+	Nome             string     `json:"nome"`
+	Sexo             SexoEquino `json:"sexo"`
+	TotalCoberturas  int        `json:"total_coberturas"`
+	TotalGestacoes   int        `json:"total_gestacoes"`
+	PartosConcluidos int        `json:"partos_concluidos"`
+	TaxaConcepcao    float64    `json:"taxa_concepcao"`
+}
+
 // RankingReprodutivo representa um ranking reprodutivo
 type RankingReprodutivo struct {
 	ID                   uint                   `json:"id" gorm:"primaryKey"`
@@ -11,7 +11,15 @@ type CreateCoberturaRequest struct {
 	Observacoes            string        `json:"observacoes"`
 }
 
+// RegistrarCoberturaRequest cobertura entre um reprodutor e uma matriz registrados
+type RegistrarCoberturaRequest struct {
+	ReprodutorEquinoid string `json:"reprodutor_equinoid" binding:"required"`
+	MatrizEquinoid     string `json:"matriz_equinoid" binding:"required"`
+	CreateCoberturaRequest
+}
+
 type CreateAvaliacaoSemenRequest struct {
+	LaboratorioID               uint       `json:"laboratorio_id"`
 	CoberturaID                 *uint      `json:"cobertura_id"`
 	DataColeta                  time.Time  `json:"data_coleta" validate:"required"`
 	DataAnalise                 time.Time  `json:"data_analise" validate:"required"`
@@ -27,6 +35,56 @@ type CreateAvaliacaoSemenRequest struct {
 	Observacoes                 string     `json:"observacoes"`
 }
 
+// Classificar qualidade geral do sêmen pela média das faixas de motilidade, morfologia e viabilidade informadas e a
+// aptidão reprodutiva correspondente
+func (r *CreateAvaliacaoSemenRequest) Classificar() (QualidadeSemen, AptidaoReprodutiva) {
+	score := 0
+	checks := 0
+	faixa := func(valor *float64, boa, regular float64) {
+		if valor == nil {
+			return
+		}
+		checks++
+		if *valor >= boa {
+			score += 2
+		} else if *valor >= regular {
+			score++
+		}
+	}
+	faixa(r.MotilidadeProgressiva, 60, 40)
+	faixa(r.MotilidadeTotal, 70, 50)
+	faixa(r.MorfologiaNormal, 70, 50)
+	faixa(r.Viabilidade, 80, 60)
+
+	qualidade := QualidadeRegular
+	if checks > 0 {
+		avg := float64(score) / float64(checks*2)
+		switch {
+		case avg >= 0.9:
+			qualidade = QualidadeExcelente
+		case avg >= 0.7:
+			qualidade = QualidadeBoa
+		case avg >= 0.5:
+			qualidade = QualidadeRegular
+		case avg >= 0.3:
+			qualidade = QualidadeRuim
+		default:
+			qualidade = QualidadeInadequada
+		}
+	}
+
+	switch qualidade {
+	case QualidadeExcelente:
+		return qualidade, AptidaoAlta
+	case QualidadeBoa:
+		return qualidade, AptidaoMedia
+	case QualidadeRegular:
+		return qualidade, AptidaoBaixa
+	default:
+		return qualidade, AptidaoInadequada
+	}
+}
+
 type RegistrarPartoRequest struct {
 	DataParto        time.Time  `json:"data_parto" validate:"required"`
 	TipoParto        *TipoParto `json:"tipo_parto"`
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type SearchCriteria struct {
 	Nome     string `json:"nome"`
 	Raca     string `json:"raca"`
@@ -9,3 +11,75 @@ type SearchCriteria struct {
 	Page     int    `json:"page"`
 	Limit    int    `json:"limit"`
 }
+
+// Ordenações aceitas pela busca de equinos
+const (
+	OrdenacaoBuscaRelevancia = "relevancia"
+	OrdenacaoBuscaNome       = "nome"
+	OrdenacaoBuscaNomeDesc   = "nome_desc"
+	OrdenacaoBuscaMaisNovos  = "mais_novos"
+	OrdenacaoBuscaMaisVelhos = "mais_velhos"
+	OrdenacaoBuscaRecentes   = "recentes"
+)
+
+// Faixas etárias usadas no filtro e na faceta de idade
+const (
+	FaixaEtariaPotro  = "0-2"
+	FaixaEtariaJovem  = "3-5"
+	FaixaEtariaAdulto = "6-10"
+	FaixaEtariaMaduro = "11-15"
+	FaixaEtariaSenior = "16+"
+)
+
+// BuscaEquinosRequest parâmetros da busca de equinos; filtros de lista aceitam vários valores separados por vírgula
+type BuscaEquinosRequest struct {
+	Q                string `form:"q"`
+	Raca             string `form:"raca"`
+	Pelagem          string `form:"pelagem"`
+	Sexo             string `form:"sexo"`
+	PaisOrigem       string `form:"pais"`
+	FaixaEtaria      string `form:"faixa_etaria"`
+	IdadeMin         *int   `form:"idade_min"`
+	IdadeMax         *int   `form:"idade_max"`
+	NivelValorizacao string `form:"nivel_valorizacao"`
+	Tokenizado       *bool  `form:"tokenizado"`
+	AVenda           *bool  `form:"a_venda"`
+	Ordenacao        string `form:"ordenacao"`
+	Cursor           string `form:"cursor"`
+	Limit            int    `form:"limit"`
+	Facetas          *bool  `form:"facetas"` // padrão: true
+}
+
+// EquinoBusca equino encontrado, com os atributos derivados usados nos filtros
+type EquinoBusca struct {
+	ID               uint             `json:"-"`
+	Equinoid         string           `json:"equinoid"`
+	Nome             string           `json:"nome"`
+	Raca             string           `json:"raca"`
+	Pelagem          string           `json:"pelagem"`
+	Sexo             SexoEquino       `json:"sexo"`
+	DataNascimento   *time.Time       `json:"data_nascimento"`
+	FaixaEtaria      *string          `json:"faixa_etaria"`
+	PaisOrigem       string           `json:"pais_origem"`
+	ProprietarioNome string           `json:"proprietario_nome,omitempty"`
+	NivelValorizacao NivelValorizacao `json:"nivel_valorizacao,omitempty"`
+	Tokenizado       bool             `json:"tokenizado"`
+	AVenda           bool             `json:"a_venda"`
+	Relevancia       float64          `json:"relevancia,omitempty"`
+	CreatedAt        time.Time        `json:"-"`
+}
+
+// FacetaValor quantidade de equinos do resultado com um valor do atributo
+type FacetaValor struct {
+	Valor      string `json:"valor"`
+	Quantidade int64  `json:"quantidade"`
+}
+
+// ResultadoBuscaEquinos página de resultados com total, facetas e cursor da próxima página
+type ResultadoBuscaEquinos struct {
+	Itens         []*EquinoBusca           `json:"itens"`
+	Total         int64                    `json:"total"`
+	Facetas       map[string][]FacetaValor `json:"facetas,omitempty"`
+	Ordenacao     string                   `json:"ordenacao"`
+	ProximoCursor string                   `json:"proximo_cursor,omitempty"`
+}
@@ -10,6 +10,7 @@ import (
 type AuditLog struct {
 	ID         uint           `json:"id" gorm:"primaryKey"`
 	UserID     *uuid.UUID     `json:"user_id"`
+	ActorID    *uint          `json:"actor_id" gorm:"index"`
 	Action     string         `json:"action"`
 	Resource   string         `json:"resource"`
 	ResourceID *uuid.UUID     `json:"resource_id"`
@@ -21,13 +22,35 @@ type AuditLog struct {
 	ErrorMsg   string         `json:"error_msg"`
 	RiskLevel  string         `json:"risk_level"`
 	Timestamp  time.Time      `json:"timestamp"`
+	PrevHash   string         `json:"prev_hash" gorm:"size:64"`
+	Hash       string         `json:"hash" gorm:"size:64;index"`
 	CreatedAt  time.Time      `json:"created_at"`
 	DeletedAt  gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+
+	Actor *User `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+}
+
+// AuditCheckpoint raiz de Merkle de um intervalo contínuo da cadeia de auditoria, ancorada externamente
+type AuditCheckpoint struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	FromLogID          uint       `json:"from_log_id" gorm:"not null;index"`
+	ToLogID            uint       `json:"to_log_id" gorm:"not null;uniqueIndex"`
+	EntryCount         int        `json:"entry_count" gorm:"not null"`
+	MerkleRoot         string     `json:"merkle_root" gorm:"size:64;not null"`
+	LastHash           string     `json:"last_hash" gorm:"size:64;not null"`
+	LastTimestamp      time.Time  `json:"last_timestamp"`
+	PrevCheckpointRoot string     `json:"prev_checkpoint_root" gorm:"size:64"`
+	AnchorNetwork      string     `json:"anchor_network" gorm:"size:50"` // ethereum, hyperledger, local
+	AnchorRef          string     `json:"anchor_ref" gorm:"size:255"`
+	AnchorStatus       string     `json:"anchor_status" gorm:"size:50;default:'pending'"` // pending, anchored, failed
+	AnchorError        string     `json:"anchor_error,omitempty"`
+	AnchoredAt         *time.Time `json:"anchored_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
 }
 
 type BiometricData struct {
 	ID                uint           `json:"id" gorm:"primaryKey"`
-	UserID            uuid.UUID      `json:"user_id"`
+	UserID            uint           `json:"user_id" gorm:"index"`
 	BiometricType     string         `json:"biometric_type"`
 	BiometricTemplate []byte         `json:"biometric_template"`
 	Quality           float64        `json:"quality"`
@@ -38,33 +61,115 @@ type BiometricData struct {
 	DeletedAt         gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
 }
 
+// DigitalSignature assinatura de documento pela PKI interna (CAdES destacada e, para PDFs, PAdES embutida)
 type DigitalSignature struct {
-	ID            uint           `json:"id" gorm:"primaryKey"`
-	UserID        uuid.UUID      `json:"user_id"`
-	DocumentID    uuid.UUID      `json:"document_id"`
-	CertificateID uint           `json:"certificate_id"`
-	Signature     string         `json:"signature"`
-	SignatureHash string         `json:"signature_hash"`
-	DocumentHash  string         `json:"document_hash"`
-	Algorithm     string         `json:"algorithm"`
-	Timestamp     time.Time      `json:"timestamp"`
-	Location      string         `json:"location"`
-	CreatedAt     time.Time      `json:"created_at"`
-	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	UserID             uint           `json:"user_id" gorm:"index;not null"`
+	DocumentID         uuid.UUID      `json:"document_id" gorm:"type:uuid;uniqueIndex"`
+	DocumentType       string         `json:"document_type" gorm:"size:50"`
+	Tier               string         `json:"tier" gorm:"size:30"`
+	Format             string         `json:"format" gorm:"size:10"` // cades, pades
+	CertificateID      uint           `json:"certificate_id" gorm:"index"`
+	CertificateSerial  string         `json:"certificate_serial" gorm:"size:100"`
+	Signature          string         `json:"signature" gorm:"type:text"` // CMS SignedData em base64
+	SignatureHash      string         `json:"signature_hash" gorm:"size:64;index"`
+	PDFSignatureHash   string         `json:"pdf_signature_hash,omitempty" gorm:"size:64;index"` // CMS embutida no PDF (PAdES)
+	DocumentHash       string         `json:"document_hash" gorm:"size:64;index"`
+	Algorithm          string         `json:"algorithm" gorm:"size:50"`
+	Timestamp          time.Time      `json:"timestamp"`
+	Location           string         `json:"location"`
+	RelatedEntityID    *uint          `json:"related_entity_id,omitempty"`
+	RelatedEntityType  string         `json:"related_entity_type,omitempty" gorm:"size:50"`
+	BiometricVerified  bool           `json:"biometric_verified"`
+	BiometricScore     float64        `json:"biometric_score,omitempty"`
+	TimestampToken     string         `json:"timestamp_token,omitempty" gorm:"type:text"` // TimeStampToken RFC 3161 em base64
+	TimestampTime      *time.Time     `json:"timestamp_time,omitempty"`
+	TimestampAuthority string         `json:"timestamp_authority,omitempty" gorm:"size:255"`
+	CreatedAt          time.Time      `json:"created_at"`
+	DeletedAt          gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+}
+
+// Formatos de assinatura produzidos pela PKI interna
+const (
+	SignatureFormatCAdES = "cades"
+	SignatureFormatPAdES = "pades"
+)
+
+// SignatureVerificationResponse resultado da verificação de um arquivo assinado
+type SignatureVerificationResponse struct {
+	Valid        bool                          `json:"valid"`
+	Format       string                        `json:"format"`
+	DocumentHash string                        `json:"document_hash"`
+	Signatures   []SignatureVerificationResult `json:"signatures"`
+	CheckedAt    time.Time                     `json:"checked_at"`
+}
+
+// SignatureVerificationResult situação de cada assinatura encontrada no arquivo
+type SignatureVerificationResult struct {
+	Valid               bool                   `json:"valid"`
+	SignatureValid      bool                   `json:"signature_valid"`
+	ChainValid          bool                   `json:"chain_valid"`
+	CertificateStatus   string                 `json:"certificate_status"`
+	Signer              string                 `json:"signer"`
+	SignerSerial        string                 `json:"signer_serial"`
+	Issuer              string                 `json:"issuer"`
+	SigningTime         *time.Time             `json:"signing_time,omitempty"`
+	CoversWholeDocument bool                   `json:"covers_whole_document"`
+	SignatureID         *uint                  `json:"signature_id,omitempty"`
+	Timestamp           *TimestampVerification `json:"timestamp,omitempty"`
+	Reason              string                 `json:"reason,omitempty"`
+}
+
+// TimestampToken carimbo de tempo RFC 3161 (TimeStampToken em DER) com os dados do TSTInfo
+type TimestampToken struct {
+	Token        []byte
+	Time         time.Time
+	Authority    string
+	SerialNumber string
 }
 
+// TimestampVerification resultado da verificação de um carimbo de tempo, obtido só do token e das raízes confiáveis
+type TimestampVerification struct {
+	Valid        bool       `json:"valid"`
+	Trusted      bool       `json:"trusted"`
+	Authority    string     `json:"authority,omitempty"`
+	Time         *time.Time `json:"time,omitempty"`
+	Accuracy     string     `json:"accuracy,omitempty"`
+	SerialNumber string     `json:"serial_number,omitempty"`
+	Policy       string     `json:"policy,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// ComplianceRecord registra consentimentos e solicitações de titulares (LGPD/GDPR)
 type ComplianceRecord struct {
-	ID             uint           `json:"id" gorm:"primaryKey"`
-	UserID         uuid.UUID      `json:"user_id"`
-	ComplianceType string         `json:"compliance_type"`
-	RequestType    string         `json:"request_type"`
-	Status         string         `json:"status"`
-	RequestData    JSONB          `json:"request_data" gorm:"type:jsonb"`
-	LegalBasis     string         `json:"legal_basis"`
-	ExpiresAt      *time.Time     `json:"expires_at,omitempty"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UserID          uint           `json:"user_id" gorm:"index"`
+	ComplianceType  string         `json:"compliance_type" gorm:"size:50;index"`
+	RequestType     string         `json:"request_type" gorm:"size:50"`
+	Status          string         `json:"status" gorm:"size:20;index"`
+	RequestData     JSONB          `json:"request_data" gorm:"type:jsonb"`
+	LegalBasis      string         `json:"legal_basis"`
+	PolicyVersion   int            `json:"policy_version,omitempty"`
+	DueAt           *time.Time     `json:"due_at,omitempty" gorm:"index"`
+	ProcessedAt     *time.Time     `json:"processed_at,omitempty"`
+	ProcessedByID   *uint          `json:"processed_by_id,omitempty"`
+	Notes           string         `json:"notes,omitempty" gorm:"type:text"`
+	ExportPath      string         `json:"-" gorm:"size:500"`
+	ExportFormat    string         `json:"export_format,omitempty" gorm:"size:10"`
+	ExportChecksum  string         `json:"export_checksum,omitempty" gorm:"size:64"`
+	ExportSignature string         `json:"export_signature,omitempty" gorm:"size:64"`
+	ExpiresAt       *time.Time     `json:"expires_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+}
+
+// IsOverdue indica se a solicitação ainda aberta ultrapassou o prazo legal de resposta
+func (c *ComplianceRecord) IsOverdue(now time.Time) bool {
+	if c.DueAt == nil || c.ProcessedAt != nil {
+		return false
+	}
+	return now.After(*c.DueAt)
 }
 
 type MFADevice struct {
@@ -106,14 +106,18 @@ const (
 	StatusPostAtivo     StatusPost = "ativo"
 	StatusPostArquivado StatusPost = "arquivado"
 	StatusPostRemovido  StatusPost = "removido"
+	// StatusPostOculto ocultado pela moderação (shadow-hide): visível apenas para o autor enquanto aguarda revisão
+	StatusPostOculto StatusPost = "oculto"
+	// StatusPostRetirado retirado do ar por decisão da moderação (takedown), sujeito a recurso
+	StatusPostRetirado StatusPost = "retirado"
 )
 
 // InteracaoSocial representa uma interação social
 type InteracaoSocial struct {
 	ID            uint           `json:"id" gorm:"primaryKey"`
-	PostID        uint           `json:"post_id" gorm:"not null"`
-	UserID        uint           `json:"user_id" gorm:"not null"`
-	TipoInteracao TipoInteracao  `json:"tipo_interacao" gorm:"not null"`
+	PostID        uint           `json:"post_id" gorm:"not null;uniqueIndex:idx_interacao_socials_post_user_tipo"`
+	UserID        uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_interacao_socials_post_user_tipo"`
+	TipoInteracao TipoInteracao  `json:"tipo_interacao" gorm:"not null;uniqueIndex:idx_interacao_socials_post_user_tipo"`
 	CreatedAt     time.Time      `json:"created_at"`
 	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
 
@@ -135,6 +139,16 @@ const (
 	TipoInteracaoSurpresa         TipoInteracao = "surpresa"
 )
 
+// IsValidTipoInteracao verifica se o tipo de interação é suportado
+func IsValidTipoInteracao(tipo TipoInteracao) bool {
+	switch tipo {
+	case TipoInteracaoCurtida, TipoInteracaoCompartilhamento, TipoInteracaoSalvar, TipoInteracaoInteresse,
+		TipoInteracaoAmor, TipoInteracaoRisada, TipoInteracaoSurpresa:
+		return true
+	}
+	return false
+}
+
 // ComentarioSocial representa um comentário social
 type ComentarioSocial struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
@@ -142,6 +156,7 @@ type ComentarioSocial struct {
 	UserID    uint           `json:"user_id" gorm:"not null"`
 	ParentID  *uint          `json:"parent_id"`
 	Conteudo  string         `json:"conteudo" gorm:"type:text;not null"`
+	Status    StatusPost     `json:"status" gorm:"size:20;not null;default:'ativo'"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
@@ -210,38 +225,66 @@ const (
 
 // SeguirEquino representa o relacionamento de seguir entre usuários e equinos
 type SeguirEquino struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	UserID    uint           `json:"user_id" gorm:"not null"`
-	Equinoid  string         `json:"equinoid" gorm:"size:25;not null"`
-	CreatedAt time.Time      `json:"created_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	UserID     uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_seguir_equinos_user_equinoid"`
+	Equinoid   string         `json:"equinoid" gorm:"size:25;not null;uniqueIndex:idx_seguir_equinos_user_equinoid"`
+	Status     StatusSeguir   `json:"status" gorm:"size:20;not null;default:'ativo'"`
+	AprovadoEm *time.Time     `json:"aprovado_em"`
+	CreatedAt  time.Time      `json:"created_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
 
 	// Relacionamentos
 	User   *User   `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Equino *Equino `json:"equino,omitempty" gorm:"foreignKey:Equinoid;references:Equinoid"`
 }
 
-// Oferta representa uma oferta feita por um equino
+// StatusSeguir define o status do seguidor; perfis privados exigem aprovação do proprietário
+type StatusSeguir string
+
+const (
+	StatusSeguirAtivo    StatusSeguir = "ativo"
+	StatusSeguirPendente StatusSeguir = "pendente"
+)
+
+// Oferta proposta de negócio sobre um equino. Cada contraproposta é uma nova oferta da mesma negociação, encadeada à
+// anterior, feita alternadamente pelo comprador e pelo proprietário; só a última proposta da negociação fica pendente
 type Oferta struct {
 	ID                   uint           `json:"id" gorm:"primaryKey"`
-	Equinoid             string         `json:"equinoid" gorm:"size:25;not null"`
-	OfertantePorID       uint           `json:"ofertante_por_id" gorm:"not null"`
+	Equinoid             string         `json:"equinoid" gorm:"size:25;not null;index:idx_oferta_equinoid_status;index:idx_oferta_compra_aceita,unique,where:status_oferta = 'aceita' AND tipo_oferta = 'compra' AND deleted_at IS NULL"`
+	NegociacaoID         uint           `json:"negociacao_id" gorm:"index"`   // ID da oferta que abriu a negociação
+	OfertaAnteriorID     *uint          `json:"oferta_anterior_id,omitempty"` // proposta respondida por esta contraproposta
+	Rodada               int            `json:"rodada" gorm:"not null;default:1"`
+	CompradorID          uint           `json:"comprador_id" gorm:"not null;index"`
+	ProprietarioID       uint           `json:"proprietario_id" gorm:"not null;index"` // proprietário quando a negociação foi aberta
+	OfertantePorID       uint           `json:"ofertante_por_id" gorm:"not null"`      // autor desta proposta
 	TipoOferta           TipoOferta     `json:"tipo_oferta" gorm:"not null"`
 	ValorOferta          float64        `json:"valor_oferta" gorm:"type:decimal(15,2);not null"`
 	Moeda                string         `json:"moeda" gorm:"size:3;default:'BRL'"`
 	CondicoesOferta      string         `json:"condicoes_oferta" gorm:"type:text"`
 	PrazoOferta          *time.Time     `json:"prazo_oferta"`
-	StatusOferta         StatusOferta   `json:"status_oferta" gorm:"default:'pendente'"`
+	StatusOferta         StatusOferta   `json:"status_oferta" gorm:"default:'pendente';index:idx_oferta_equinoid_status"`
 	RespostaProprietario string         `json:"resposta_proprietario" gorm:"type:text"`
 	DataResposta         *time.Time     `json:"data_resposta"`
+	ContratoUUID         string         `json:"contrato_uuid,omitempty" gorm:"size:255;index"` // documento D4Sign do negócio aceito
+	ConcluidaEm          *time.Time     `json:"concluida_em,omitempty"`
 	ObservacoesInternas  string         `json:"observacoes_internas" gorm:"type:text"`
 	CreatedAt            time.Time      `json:"created_at"`
 	UpdatedAt            time.Time      `json:"updated_at"`
 	DeletedAt            gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
 
 	// Relacionamentos
-	Equino    *Equino `json:"equino,omitempty" gorm:"foreignKey:Equinoid;references:Equinoid"`
-	Ofertante *User   `json:"ofertante,omitempty" gorm:"foreignKey:OfertantePorID"`
+	Equino       *Equino `json:"equino,omitempty" gorm:"foreignKey:Equinoid;references:Equinoid"`
+	Ofertante    *User   `json:"ofertante,omitempty" gorm:"foreignKey:OfertantePorID"`
+	Comprador    *User   `json:"comprador,omitempty" gorm:"foreignKey:CompradorID"`
+	Proprietario *User   `json:"proprietario,omitempty" gorm:"foreignKey:ProprietarioID"`
+}
+
+// Contraparte usuário que responde a esta proposta
+func (o *Oferta) Contraparte() uint {
+	if o.OfertantePorID == o.CompradorID {
+		return o.ProprietarioID
+	}
+	return o.CompradorID
 }
 
 // TipoOferta define o tipo de oferta
@@ -264,8 +307,21 @@ const (
 	StatusOfertaRecusada  StatusOferta = "recusada"
 	StatusOfertaExpirada  StatusOferta = "expirada"
 	StatusOfertaCancelada StatusOferta = "cancelada"
+	// StatusOfertaContraproposta proposta respondida com outra proposta da mesma negociação
+	StatusOfertaContraproposta StatusOferta = "contraproposta"
+	// StatusOfertaConcluida contrato assinado pelas partes e, na compra, propriedade transferida
+	StatusOfertaConcluida StatusOferta = "concluida"
 )
 
+// IsValidTipoOferta verifica se o tipo de oferta é suportado
+func IsValidTipoOferta(tipo TipoOferta) bool {
+	switch tipo {
+	case TipoOfertaCompra, TipoOfertaCobertura, TipoOfertaParticipacao, TipoOfertaSociedade, TipoOfertaAluguel:
+		return true
+	}
+	return false
+}
+
 // Request DTOs para sistema social
 type CreatePerfilSocialRequest struct {
 	NomePerfil            string                `json:"nome_perfil"`
@@ -273,20 +329,45 @@ type CreatePerfilSocialRequest struct {
 	Localizacao           string                `json:"localizacao"`
 	StatusDisponibilidade StatusDisponibilidade `json:"status_disponibilidade"`
 	TipoPerfil            TipoPerfil            `json:"tipo_perfil"`
-	MostrarLocalizacao    bool                  `json:"mostrar_localizacao"`
-	PermitirOfertas       bool                  `json:"permitir_ofertas"`
-	PermitirContato       bool                  `json:"permitir_contato"`
-	PermitirSeguir        bool                  `json:"permitir_seguir"`
+	MostrarLocalizacao    *bool                 `json:"mostrar_localizacao"`
+	PermitirOfertas       *bool                 `json:"permitir_ofertas"`
+	PermitirContato       *bool                 `json:"permitir_contato"`
+	PermitirSeguir        *bool                 `json:"permitir_seguir"`
+}
+
+type UpdatePerfilSocialRequest struct {
+	NomePerfil            *string                `json:"nome_perfil"`
+	Bio                   *string                `json:"bio"`
+	Localizacao           *string                `json:"localizacao"`
+	StatusDisponibilidade *StatusDisponibilidade `json:"status_disponibilidade"`
+	TipoPerfil            *TipoPerfil            `json:"tipo_perfil"`
+	MostrarLocalizacao    *bool                  `json:"mostrar_localizacao"`
+	PermitirOfertas       *bool                  `json:"permitir_ofertas"`
+	PermitirContato       *bool                  `json:"permitir_contato"`
+	PermitirSeguir        *bool                  `json:"permitir_seguir"`
 }
 
 type CreatePostRequest struct {
-	TipoConteudo             TipoConteudo      `json:"tipo_conteudo" validate:"required"`
+	TipoConteudo             TipoConteudo      `json:"tipo_conteudo" binding:"required"`
 	Legenda                  string            `json:"legenda"`
 	LocalizacaoPost          string            `json:"localizacao_post"`
 	ArquivosMidia            []DocumentoEvento `json:"arquivos_midia"`
+	ThumbnailURL             string            `json:"thumbnail_url"`
+	DuracaoVideo             *int              `json:"duracao_video"`
 	DataExpiracao            *time.Time        `json:"data_expiracao"`
-	PermitirComentarios      bool              `json:"permitir_comentarios"`
-	PermitirCompartilhamento bool              `json:"permitir_compartilhamento"`
+	PermitirComentarios      *bool             `json:"permitir_comentarios"`
+	PermitirCompartilhamento *bool             `json:"permitir_compartilhamento"`
+}
+
+type CreateComentarioRequest struct {
+	Conteudo string `json:"conteudo" binding:"required"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// FeedSocial página de posts ordenada do mais recente para o mais antigo
+type FeedSocial struct {
+	Itens         []*PostSocial `json:"itens"`
+	ProximoCursor string        `json:"proximo_cursor,omitempty"`
 }
 
 type CreateInteracaoRequest struct {
@@ -294,9 +375,22 @@ type CreateInteracaoRequest struct {
 }
 
 type CreateOfertaRequest struct {
-	TipoOferta      TipoOferta `json:"tipo_oferta" validate:"required"`
-	ValorOferta     float64    `json:"valor_oferta" validate:"required,gt=0"`
+	TipoOferta      TipoOferta `json:"tipo_oferta" binding:"required"`
+	ValorOferta     float64    `json:"valor_oferta" binding:"required,gt=0"`
 	Moeda           string     `json:"moeda"`
 	CondicoesOferta string     `json:"condicoes_oferta"`
 	PrazoOferta     *time.Time `json:"prazo_oferta"`
 }
+
+// ContrapropostaRequest nova proposta em resposta à pendente; condições vazias mantêm as da proposta anterior
+type ContrapropostaRequest struct {
+	ValorOferta     float64    `json:"valor_oferta" binding:"required,gt=0"`
+	CondicoesOferta string     `json:"condicoes_oferta"`
+	PrazoOferta     *time.Time `json:"prazo_oferta"`
+	Mensagem        string     `json:"mensagem"`
+}
+
+// ResponderOfertaRequest mensagem opcional ao aceitar, recusar ou cancelar
+type ResponderOfertaRequest struct {
+	Mensagem string `json:"mensagem"`
+}
@@ -305,6 +305,12 @@ type CreateValorizacaoRequest struct {
 	DadosLeilao *DadosLeilaoRequest `json:"dados_leilao,omitempty"`
 }
 
+// ValidarValorizacaoRequest decisão do validador sobre um registro de valorização pendente
+type ValidarValorizacaoRequest struct {
+	Aprovado    *bool  `json:"aprovado" binding:"required"`
+	Observacoes string `json:"observacoes"`
+}
+
 type DadosLeilaoRequest struct {
 	NomeLeilao            string          `json:"nome_leilao" validate:"required"`
 	TipoLeilao            TipoLeilao      `json:"tipo_leilao" validate:"required"`
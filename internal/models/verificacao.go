@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Tipos de registro localizados pela verificação pública de documentos
+const (
+	DocumentoVerificadoCertificado = "certificado"
+	DocumentoVerificadoPassaporte  = "passaporte"
+	DocumentoVerificadoAssinatura  = "assinatura"
+)
+
+// CartaoPublicoEquino identificação mínima do equino exposta sem autenticação
+type CartaoPublicoEquino struct {
+	Equinoid      string     `json:"equinoid"`
+	Nome          string     `json:"nome"`
+	Raca          string     `json:"raca"`
+	Sexo          SexoEquino `json:"sexo"`
+	AnoNascimento *int       `json:"ano_nascimento,omitempty"`
+	FotoURL       *string    `json:"foto_url,omitempty"` // somente quando a foto de perfil é pública
+}
+
+// VerificacaoEquinoid resultado da conferência pública de um EquinoId
+type VerificacaoEquinoid struct {
+	Equinoid       string               `json:"equinoid"`
+	Versao         string               `json:"versao,omitempty"`
+	FormatoValido  bool                 `json:"formato_valido"`
+	ChecksumValido bool                 `json:"checksum_valido"`
+	Registrado     bool                 `json:"registrado"`
+	Cartao         *CartaoPublicoEquino `json:"cartao,omitempty"`
+	Mensagem       string               `json:"mensagem"`
+	VerificadoEm   time.Time            `json:"verificado_em"`
+}
+
+// DocumentoVerificado registro emitido pela plataforma que corresponde à consulta
+type DocumentoVerificado struct {
+	Tipo          string     `json:"tipo"`
+	Identificador string     `json:"identificador"`
+	Subtipo       string     `json:"subtipo,omitempty"`
+	Valido        bool       `json:"valido"`
+	Status        string     `json:"status"`
+	Equinoid      string     `json:"equinoid,omitempty"`
+	EmitidoEm     time.Time  `json:"emitido_em"`
+	ValidoAte     *time.Time `json:"valido_ate,omitempty"`
+	RevogadoEm    *time.Time `json:"revogado_em,omitempty"`
+}
+
+// VerificacaoDocumento resultado da conferência pública de um serial ou hash de documento
+type VerificacaoDocumento struct {
+	Consulta     string                `json:"consulta"`
+	Encontrado   bool                  `json:"encontrado"`
+	Resultados   []DocumentoVerificado `json:"resultados"`
+	Mensagem     string                `json:"mensagem"`
+	VerificadoEm time.Time             `json:"verificado_em"`
+}
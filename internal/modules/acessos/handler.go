@@ -0,0 +1,290 @@
+package acessos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Convidar godoc
+// @Summary Convidar profissional para um equino
+// @Description Proprietário convida veterinário, laboratório, treinador ou nutricionista com escopos e prazo de validade
+// @Tags Acessos
+// @Accept json
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param convite body models.ConvidarAcessoRequest true "Dados do convite"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/acessos [post]
+// @Security BearerAuth
+func (h *Handler) Convidar(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+
+	var req models.ConvidarAcessoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	acesso, err := h.service.Convidar(c.Request.Context(), c.Param("equinoid"), userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao convidar profissional")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Convite de acesso enviado",
+		Timestamp: time.Now(),
+		Data:      acesso,
+	})
+}
+
+// ListByEquino godoc
+// @Summary Listar acessos delegados de um equino
+// @Description Lista convites e acessos delegados do equino (apenas proprietário ou admin)
+// @Tags Acessos
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/acessos [get]
+// @Security BearerAuth
+func (h *Handler) ListByEquino(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+
+	acessos, err := h.service.ListByEquino(c.Request.Context(), c.Param("equinoid"), userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar acessos")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Acessos do equino (total: %d)", len(acessos)),
+		Timestamp: time.Now(),
+		Data:      acessos,
+	})
+}
+
+// Revogar godoc
+// @Summary Revogar acesso delegado
+// @Description Revoga imediatamente o acesso de um profissional ao equino
+// @Tags Acessos
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param id path int true "ID do acesso"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/acessos/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) Revogar(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID de acesso inválido",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if err := h.service.Revogar(c.Request.Context(), c.Param("equinoid"), uint(id), userID, userType); err != nil {
+		h.respondError(c, err, "Erro ao revogar acesso")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Acesso revogado com sucesso",
+		Timestamp: time.Now(),
+		Data:      nil,
+	})
+}
+
+// ListMeus godoc
+// @Summary Listar meus acessos delegados
+// @Description Lista convites recebidos e acessos delegados do usuário autenticado
+// @Tags Acessos
+// @Produce json
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /acessos [get]
+// @Security BearerAuth
+func (h *Handler) ListMeus(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	acessos, err := h.service.ListMeus(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar acessos")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Meus acessos (total: %d)", len(acessos)),
+		Timestamp: time.Now(),
+		Data:      acessos,
+	})
+}
+
+// Aceitar godoc
+// @Summary Aceitar convite de acesso
+// @Description O profissional convidado aceita o acesso delegado ao equino
+// @Tags Acessos
+// @Produce json
+// @Param id path int true "ID do acesso"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /acessos/{id}/aceitar [post]
+// @Security BearerAuth
+func (h *Handler) Aceitar(c *gin.Context) {
+	h.responderConvite(c, h.service.Aceitar, "Convite aceito com sucesso", "Erro ao aceitar convite")
+}
+
+// Recusar godoc
+// @Summary Recusar convite de acesso
+// @Description O profissional convidado recusa o acesso delegado ao equino
+// @Tags Acessos
+// @Produce json
+// @Param id path int true "ID do acesso"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /acessos/{id}/recusar [post]
+// @Security BearerAuth
+func (h *Handler) Recusar(c *gin.Context) {
+	h.responderConvite(c, h.service.Recusar, "Convite recusado", "Erro ao recusar convite")
+}
+
+func (h *Handler) responderConvite(c *gin.Context, action func(ctx context.Context, id uint, userID uint) (*models.EquinoVetResponse, error), successMsg, errorMsg string) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID de acesso inválido",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	acesso, err := action(c.Request.Context(), uint(id), userID)
+	if err != nil {
+		h.respondError(c, err, errorMsg)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   successMsg,
+		Timestamp: time.Now(),
+		Data:      acesso,
+	})
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
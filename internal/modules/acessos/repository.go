@@ -0,0 +1,109 @@
+package acessos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	FindByID(ctx context.Context, id uint) (*models.EquinoVeterinario, error)
+	FindByEquinoID(ctx context.Context, equinoID uint) ([]*models.EquinoVeterinario, error)
+	FindByProfissionalID(ctx context.Context, profissionalID uint) ([]*models.EquinoVeterinario, error)
+	FindAtivo(ctx context.Context, equinoID, profissionalID uint, now time.Time) (*models.EquinoVeterinario, error)
+	FindVigentesByProprietarioID(ctx context.Context, proprietarioID uint, papel models.PapelDelegado) ([]*models.EquinoVeterinario, error)
+	Create(ctx context.Context, acesso *models.EquinoVeterinario) error
+	Update(ctx context.Context, acesso *models.EquinoVeterinario) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(ctx context.Context, id uint) (*models.EquinoVeterinario, error) {
+	var acesso models.EquinoVeterinario
+	if err := r.db.WithContext(ctx).Preload("Equino").Preload("Veterinario").First(&acesso, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "acesso", Message: "acesso delegado não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_acesso", "erro ao buscar acesso delegado", err)
+	}
+	return &acesso, nil
+}
+
+func (r *repository) FindByEquinoID(ctx context.Context, equinoID uint) ([]*models.EquinoVeterinario, error) {
+	var acessos []*models.EquinoVeterinario
+	if err := r.db.WithContext(ctx).
+		Preload("Veterinario").
+		Where("equino_id = ?", equinoID).
+		Order("created_at DESC").
+		Find(&acessos).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("find_acessos_equino", "erro ao listar acessos do equino", err)
+	}
+	return acessos, nil
+}
+
+func (r *repository) FindByProfissionalID(ctx context.Context, profissionalID uint) ([]*models.EquinoVeterinario, error) {
+	var acessos []*models.EquinoVeterinario
+	if err := r.db.WithContext(ctx).
+		Preload("Equino").
+		Preload("NomeadoPor").
+		Where("veterinario_id = ?", profissionalID).
+		Order("created_at DESC").
+		Find(&acessos).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("find_acessos_profissional", "erro ao listar acessos do profissional", err)
+	}
+	return acessos, nil
+}
+
+func (r *repository) FindAtivo(ctx context.Context, equinoID, profissionalID uint, now time.Time) (*models.EquinoVeterinario, error) {
+	var acesso models.EquinoVeterinario
+	err := r.db.WithContext(ctx).
+		Where("equino_id = ? AND veterinario_id = ? AND status = ?", equinoID, profissionalID, models.StatusDelegadoAtivo).
+		Where("expira_em IS NULL OR expira_em > ?", now).
+		Order("created_at DESC").
+		First(&acesso).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperrors.NewDatabaseError("find_acesso_ativo", "erro ao buscar acesso ativo", err)
+	}
+	return &acesso, nil
+}
+
+func (r *repository) FindVigentesByProprietarioID(ctx context.Context, proprietarioID uint, papel models.PapelDelegado) ([]*models.EquinoVeterinario, error) {
+	equinoIDs := r.db.Model(&models.Equino{}).Select("id").Where("proprietario_id = ?", proprietarioID)
+
+	var acessos []*models.EquinoVeterinario
+	if err := r.db.WithContext(ctx).
+		Preload("Equino").
+		Where("equino_id IN (?) AND papel = ?", equinoIDs, papel).
+		Where("status IN ?", []models.StatusDelegado{models.StatusDelegadoPendente, models.StatusDelegadoAtivo}).
+		Find(&acessos).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("find_acessos_proprietario", "erro ao listar acessos do proprietário", err)
+	}
+	return acessos, nil
+}
+
+func (r *repository) Create(ctx context.Context, acesso *models.EquinoVeterinario) error {
+	if err := r.db.WithContext(ctx).Create(acesso).Error; err != nil {
+		return apperrors.NewDatabaseError("create_acesso", "erro ao criar acesso delegado", err)
+	}
+	return nil
+}
+
+func (r *repository) Update(ctx context.Context, acesso *models.EquinoVeterinario) error {
+	if err := r.db.WithContext(ctx).Omit("Equino", "Veterinario", "NomeadoPor").Save(acesso).Error; err != nil {
+		return apperrors.NewDatabaseError("update_acesso", "erro ao atualizar acesso delegado", err)
+	}
+	return nil
+}
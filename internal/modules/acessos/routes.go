@@ -0,0 +1,23 @@
+package acessos
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	equinos := rg.Group("/equinos")
+	equinos.Use(authMiddleware)
+	{
+		equinos.GET("/:equinoid/acessos", handler.ListByEquino)
+		equinos.POST("/:equinoid/acessos", handler.Convidar)
+		equinos.DELETE("/:equinoid/acessos/:id", handler.Revogar)
+	}
+
+	acessos := rg.Group("/acessos")
+	acessos.Use(authMiddleware)
+	{
+		acessos.GET("", handler.ListMeus)
+		acessos.POST("/:id/aceitar", handler.Aceitar)
+		acessos.POST("/:id/recusar", handler.Recusar)
+	}
+}
@@ -0,0 +1,327 @@
+package acessos
+
+import (
+	"context"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+// AuditLogger registra no log de auditoria as operações sobre acessos delegados
+type AuditLogger interface {
+	LogDelegatedAccess(ctx context.Context, actorID uint, action string, equinoid string, details map[string]interface{}) error
+}
+
+// ConsentChecker verifica o consentimento do titular antes de tratamentos que dependem dele
+type ConsentChecker interface {
+	RequireConsent(ctx context.Context, userID uint, purpose string) error
+}
+
+type Service interface {
+	Convidar(ctx context.Context, equinoid string, userID uint, userType string, req *models.ConvidarAcessoRequest) (*models.EquinoVetResponse, error)
+	ListByEquino(ctx context.Context, equinoid string, userID uint, userType string) ([]*models.EquinoVetResponse, error)
+	ListMeus(ctx context.Context, userID uint) ([]*models.EquinoVetResponse, error)
+	Aceitar(ctx context.Context, id uint, userID uint) (*models.EquinoVetResponse, error)
+	Recusar(ctx context.Context, id uint, userID uint) (*models.EquinoVetResponse, error)
+	Revogar(ctx context.Context, equinoid string, id uint, userID uint, userType string) error
+	RevogarLaboratorios(ctx context.Context, proprietarioID uint) error
+
+	CheckEquinoAccess(ctx context.Context, userID uint, userType string, equinoid string, escopo string) error
+}
+
+type service struct {
+	repo       Repository
+	equinoRepo equinos.Repository
+	audit      AuditLogger
+	consent    ConsentChecker
+	logger     *logging.Logger
+}
+
+func NewService(repo Repository, equinoRepo equinos.Repository, audit AuditLogger, consent ConsentChecker, logger *logging.Logger) Service {
+	return &service{
+		repo:       repo,
+		equinoRepo: equinoRepo,
+		audit:      audit,
+		consent:    consent,
+		logger:     logger,
+	}
+}
+
+func (s *service) Convidar(ctx context.Context, equinoid string, userID uint, userType string, req *models.ConvidarAcessoRequest) (*models.EquinoVetResponse, error) {
+	equino, err := s.findEquinoAsOwner(ctx, equinoid, userID, userType)
+	if err != nil {
+		return nil, err
+	}
+
+	if !models.IsValidPapelDelegado(req.Papel) {
+		return nil, &apperrors.ValidationError{Field: "papel", Message: "papel inválido", Value: req.Papel}
+	}
+	for _, escopo := range req.Escopos {
+		if !models.IsValidEscopo(escopo) {
+			return nil, &apperrors.ValidationError{Field: "escopos", Message: "escopo inválido", Value: escopo}
+		}
+	}
+	if req.ExpiraEm == nil || !req.ExpiraEm.After(time.Now()) {
+		return nil, &apperrors.ValidationError{Field: "expira_em", Message: "data de expiração deve estar no futuro"}
+	}
+	if req.ProfissionalID == equino.ProprietarioID {
+		return nil, &apperrors.ValidationError{Field: "profissional_id", Message: "o proprietário já possui acesso total ao equino"}
+	}
+	// Laboratórios são terceiros: o compartilhamento depende do consentimento do proprietário
+	if req.Papel == models.PapelLaboratorio {
+		if err := s.consent.RequireConsent(ctx, equino.ProprietarioID, models.FinalidadeCompartilhamentoTerceiros); err != nil {
+			return nil, err
+		}
+	}
+
+	acesso := &models.EquinoVeterinario{
+		EquinoID:      equino.ID,
+		VeterinarioID: req.ProfissionalID,
+		NomeadoPorID:  userID,
+		DataNomeacao:  time.Now(),
+		IsPrincipal:   req.IsPrincipal,
+		Papel:         req.Papel,
+		Status:        models.StatusDelegadoPendente,
+		ExpiraEm:      req.ExpiraEm,
+	}
+	acesso.SetEscopos(req.Escopos)
+
+	if err := s.repo.Create(ctx, acesso); err != nil {
+		s.logger.LogError(err, "AcessoService.Convidar", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+
+	s.logAudit(ctx, userID, "delegated_access_invited", equinoid, acesso)
+
+	s.logger.WithFields(logging.Fields{
+		"acesso_id":       acesso.ID,
+		"equinoid":        equinoid,
+		"profissional_id": req.ProfissionalID,
+		"papel":           req.Papel,
+	}).Info("Acesso delegado convidado")
+
+	return acesso.ToResponse(), nil
+}
+
+func (s *service) ListByEquino(ctx context.Context, equinoid string, userID uint, userType string) ([]*models.EquinoVetResponse, error) {
+	equino, err := s.findEquinoAsOwner(ctx, equinoid, userID, userType)
+	if err != nil {
+		return nil, err
+	}
+
+	acessos, err := s.repo.FindByEquinoID(ctx, equino.ID)
+	if err != nil {
+		s.logger.LogError(err, "AcessoService.ListByEquino", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+
+	return toResponses(acessos), nil
+}
+
+func (s *service) ListMeus(ctx context.Context, userID uint) ([]*models.EquinoVetResponse, error) {
+	acessos, err := s.repo.FindByProfissionalID(ctx, userID)
+	if err != nil {
+		s.logger.LogError(err, "AcessoService.ListMeus", logging.Fields{"user_id": userID})
+		return nil, err
+	}
+
+	return toResponses(acessos), nil
+}
+
+func (s *service) Aceitar(ctx context.Context, id uint, userID uint) (*models.EquinoVetResponse, error) {
+	acesso, err := s.findPendenteAsConvidado(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	acesso.Status = models.StatusDelegadoAtivo
+	acesso.AceitoEm = &now
+
+	if err := s.repo.Update(ctx, acesso); err != nil {
+		s.logger.LogError(err, "AcessoService.Aceitar", logging.Fields{"acesso_id": id})
+		return nil, err
+	}
+
+	s.logAudit(ctx, userID, "delegated_access_accepted", equinoidOf(acesso), acesso)
+
+	return acesso.ToResponse(), nil
+}
+
+func (s *service) Recusar(ctx context.Context, id uint, userID uint) (*models.EquinoVetResponse, error) {
+	acesso, err := s.findPendenteAsConvidado(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	acesso.Status = models.StatusDelegadoRecusado
+
+	if err := s.repo.Update(ctx, acesso); err != nil {
+		s.logger.LogError(err, "AcessoService.Recusar", logging.Fields{"acesso_id": id})
+		return nil, err
+	}
+
+	s.logAudit(ctx, userID, "delegated_access_declined", equinoidOf(acesso), acesso)
+
+	return acesso.ToResponse(), nil
+}
+
+func (s *service) Revogar(ctx context.Context, equinoid string, id uint, userID uint, userType string) error {
+	equino, err := s.findEquinoAsOwner(ctx, equinoid, userID, userType)
+	if err != nil {
+		return err
+	}
+
+	acesso, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if acesso.EquinoID != equino.ID {
+		return &apperrors.NotFoundError{Resource: "acesso", Message: "acesso delegado não encontrado", ID: id}
+	}
+	if acesso.Status == models.StatusDelegadoRevogado {
+		return nil
+	}
+
+	now := time.Now()
+	acesso.Status = models.StatusDelegadoRevogado
+	acesso.RevogadoEm = &now
+	acesso.RevogadoPorID = &userID
+
+	if err := s.repo.Update(ctx, acesso); err != nil {
+		s.logger.LogError(err, "AcessoService.Revogar", logging.Fields{"acesso_id": id})
+		return err
+	}
+
+	s.logAudit(ctx, userID, "delegated_access_revoked", equinoid, acesso)
+
+	s.logger.WithFields(logging.Fields{
+		"acesso_id":       id,
+		"equinoid":        equinoid,
+		"profissional_id": acesso.VeterinarioID,
+	}).Info("Acesso delegado revogado")
+
+	return nil
+}
+
+// RevogarLaboratorios revoga os acessos de laboratórios aos equinos do proprietário; executado quando o
+// consentimento de compartilhamento com terceiros é revogado ou expira
+func (s *service) RevogarLaboratorios(ctx context.Context, proprietarioID uint) error {
+	acessos, err := s.repo.FindVigentesByProprietarioID(ctx, proprietarioID, models.PapelLaboratorio)
+	if err != nil {
+		s.logger.LogError(err, "AcessoService.RevogarLaboratorios", logging.Fields{"proprietario_id": proprietarioID})
+		return err
+	}
+
+	now := time.Now()
+	for _, acesso := range acessos {
+		acesso.Status = models.StatusDelegadoRevogado
+		acesso.RevogadoEm = &now
+		acesso.RevogadoPorID = &proprietarioID
+
+		if err := s.repo.Update(ctx, acesso); err != nil {
+			s.logger.LogError(err, "AcessoService.RevogarLaboratorios", logging.Fields{"acesso_id": acesso.ID})
+			return err
+		}
+		s.logAudit(ctx, proprietarioID, "delegated_access_revoked", equinoidOf(acesso), acesso)
+	}
+
+	if len(acessos) > 0 {
+		s.logger.WithFields(logging.Fields{
+			"proprietario_id": proprietarioID,
+			"acessos":         len(acessos),
+		}).Info("Acessos de laboratórios revogados após revogação de consentimento")
+	}
+	return nil
+}
+
+func (s *service) CheckEquinoAccess(ctx context.Context, userID uint, userType string, equinoid string, escopo string) error {
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		return err
+	}
+
+	if userType == string(models.UserTypeAdmin) || equino.ProprietarioID == userID {
+		return nil
+	}
+
+	acesso, err := s.repo.FindAtivo(ctx, equino.ID, userID, time.Now())
+	if err != nil {
+		s.logger.LogError(err, "AcessoService.CheckEquinoAccess", logging.Fields{"equinoid": equinoid, "user_id": userID})
+		return err
+	}
+	if acesso == nil || !acesso.TemEscopo(escopo) {
+		return (&apperrors.AuthorizationError{Message: "acesso negado"}).WithAction(escopo, "equino")
+	}
+
+	if s.audit != nil {
+		if err := s.audit.LogDelegatedAccess(ctx, userID, "delegated_access_used", equinoid, map[string]interface{}{
+			"acesso_id": acesso.ID,
+			"escopo":    escopo,
+			"papel":     acesso.Papel,
+		}); err != nil {
+			s.logger.LogError(err, "AcessoService.CheckEquinoAccess", logging.Fields{"action": "audit"})
+		}
+	}
+
+	return nil
+}
+
+func (s *service) findEquinoAsOwner(ctx context.Context, equinoid string, userID uint, userType string) (*models.Equino, error) {
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if userType != string(models.UserTypeAdmin) && equino.ProprietarioID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o proprietário pode gerenciar acessos"}).WithAction("gerenciar_acessos", "equino")
+	}
+	return equino, nil
+}
+
+func (s *service) findPendenteAsConvidado(ctx context.Context, id uint, userID uint) (*models.EquinoVeterinario, error) {
+	acesso, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if acesso.VeterinarioID != userID {
+		return nil, &apperrors.NotFoundError{Resource: "acesso", Message: "acesso delegado não encontrado", ID: id}
+	}
+	if status := acesso.StatusEfetivo(time.Now()); status != models.StatusDelegadoPendente {
+		return nil, &apperrors.ValidationError{Field: "status", Message: "convite não está pendente", Value: status}
+	}
+	return acesso, nil
+}
+
+func (s *service) logAudit(ctx context.Context, actorID uint, action, equinoid string, acesso *models.EquinoVeterinario) {
+	if s.audit == nil {
+		return
+	}
+	details := map[string]interface{}{
+		"acesso_id":       acesso.ID,
+		"profissional_id": acesso.VeterinarioID,
+		"papel":           acesso.Papel,
+		"escopos":         acesso.EscoposList(),
+		"expira_em":       acesso.ExpiraEm,
+	}
+	if err := s.audit.LogDelegatedAccess(ctx, actorID, action, equinoid, details); err != nil {
+		s.logger.LogError(err, "AcessoService.logAudit", logging.Fields{"action": action})
+	}
+}
+
+func equinoidOf(acesso *models.EquinoVeterinario) string {
+	if acesso.Equino != nil {
+		return acesso.Equino.Equinoid
+	}
+	return ""
+}
+
+func toResponses(acessos []*models.EquinoVeterinario) []*models.EquinoVetResponse {
+	responses := make([]*models.EquinoVetResponse, len(acessos))
+	for i, a := range acessos {
+		responses[i] = a.ToResponse()
+	}
+	return responses
+}
@@ -0,0 +1,140 @@
+package acessos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const proprietarioID uint = 1
+
+// auditLoggerFake registra as ações de auditoria de acessos delegados
+type auditLoggerFake struct {
+	acoes []string
+}
+
+func (f *auditLoggerFake) LogDelegatedAccess(ctx context.Context, actorID uint, action string, equinoid string, details map[string]interface{}) error {
+	f.acoes = append(f.acoes, action)
+	return nil
+}
+
+func setupAcessoService(t *testing.T) (Service, *gorm.DB, *auditLoggerFake) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Skip("sqlite driver unavailable for tests")
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.Equino{}, &models.EquinoIdentificador{}, &models.EquinoVeterinario{}))
+
+	dataNascimento := time.Now().AddDate(-5, 0, 0)
+	require.NoError(t, db.Omit(clause.Associations).Create(&models.Equino{
+		Equinoid:       "BRA-2020-00000001",
+		MicrochipID:    "CHIP_ACESSOS",
+		Nome:           "Sentinela",
+		Sexo:           models.SexoMacho,
+		Raca:           "Mangalarga",
+		Pelagem:        "Alazão",
+		PaisOrigem:     "BRA",
+		DataNascimento: &dataNascimento,
+		Status:         models.StatusAtivo,
+		ProprietarioID: proprietarioID,
+	}).Error)
+
+	audit := &auditLoggerFake{}
+	service := NewService(NewRepository(db), equinos.NewRepository(db), audit, nil, logging.NewLogger("error"))
+	return service, db, audit
+}
+
+// criarAcesso concede ao profissional um acesso ao equino do proprietário com o status e a validade informados
+func criarAcesso(t *testing.T, db *gorm.DB, profissionalID uint, status models.StatusDelegado, expiraEm time.Time, escopos ...string) {
+	var equino models.Equino
+	require.NoError(t, db.Where("equinoid = ?", "BRA-2020-00000001").First(&equino).Error)
+
+	acesso := &models.EquinoVeterinario{
+		EquinoID:      equino.ID,
+		VeterinarioID: profissionalID,
+		NomeadoPorID:  proprietarioID,
+		DataNomeacao:  time.Now(),
+		Papel:         models.PapelVeterinario,
+		Status:        status,
+		ExpiraEm:      &expiraEm,
+	}
+	acesso.SetEscopos(escopos)
+	if status == models.StatusDelegadoAtivo {
+		aceitoEm := time.Now()
+		acesso.AceitoEm = &aceitoEm
+	}
+	if status == models.StatusDelegadoRevogado {
+		revogadoEm := time.Now()
+		acesso.RevogadoEm = &revogadoEm
+		acesso.RevogadoPorID = &acesso.NomeadoPorID
+	}
+	require.NoError(t, db.Omit(clause.Associations).Create(acesso).Error)
+}
+
+func TestAcessoService_CheckEquinoAccess(t *testing.T) {
+	service, db, audit := setupAcessoService(t)
+	amanha := time.Now().Add(24 * time.Hour)
+	ontem := time.Now().Add(-24 * time.Hour)
+
+	const (
+		vetAtivo uint = iota + 10
+		vetExpirado
+		vetRevogado
+		vetPendente
+		semAcesso
+	)
+	criarAcesso(t, db, vetAtivo, models.StatusDelegadoAtivo, amanha, models.EscopoLerSaude, models.EscopoEscreverExames)
+	criarAcesso(t, db, vetExpirado, models.StatusDelegadoAtivo, ontem, models.EscopoLerSaude)
+	criarAcesso(t, db, vetRevogado, models.StatusDelegadoRevogado, amanha, models.EscopoLerSaude)
+	criarAcesso(t, db, vetPendente, models.StatusDelegadoPendente, amanha, models.EscopoLerSaude)
+
+	casos := []struct {
+		nome      string
+		userID    uint
+		userType  string
+		escopo    string
+		permitido bool
+	}{
+		{"Proprietário", proprietarioID, string(models.UserTypeCriador), models.EscopoAssinarRegistros, true},
+		{"Administrador", semAcesso, string(models.UserTypeAdmin), models.EscopoAssinarRegistros, true},
+		{"Acesso ativo com o escopo", vetAtivo, string(models.UserTypeVeterinario), models.EscopoEscreverExames, true},
+		{"Acesso ativo sem o escopo", vetAtivo, string(models.UserTypeVeterinario), models.EscopoAssinarRegistros, false},
+		{"Acesso expirado", vetExpirado, string(models.UserTypeVeterinario), models.EscopoLerSaude, false},
+		{"Acesso revogado", vetRevogado, string(models.UserTypeVeterinario), models.EscopoLerSaude, false},
+		{"Convite pendente não aceito", vetPendente, string(models.UserTypeVeterinario), models.EscopoLerSaude, false},
+		{"Sem acesso delegado", semAcesso, string(models.UserTypeVeterinario), models.EscopoLerSaude, false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			err := service.CheckEquinoAccess(context.Background(), caso.userID, caso.userType, "BRA-2020-00000001", caso.escopo)
+
+			if caso.permitido {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, apperrors.IsAuthorization(err), "esperado erro de autorização, obtido %v", err)
+			}
+		})
+	}
+
+	t.Run("Equino não encontrado", func(t *testing.T) {
+		err := service.CheckEquinoAccess(context.Background(), vetAtivo, string(models.UserTypeVeterinario), "BRA-9999-99999999", models.EscopoLerSaude)
+
+		assert.True(t, apperrors.IsNotFound(err))
+	})
+
+	// Apenas o uso por profissional delegado é auditado; proprietário e administrador não geram registro
+	assert.Equal(t, []string{"delegated_access_used"}, audit.acoes)
+}
@@ -0,0 +1,366 @@
+package auditoria
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/security/audit"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+const maxExportLogs = 10000
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListLogs godoc
+// @Summary Consultar trilha de auditoria
+// @Description Lista registros de auditoria com filtros por ator, ação, recurso, risco, sucesso e período (apenas admin)
+// @Tags Auditoria
+// @Produce json
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(50)
+// @Param actor_id query int false "ID do usuário que executou a ação"
+// @Param action query string false "Ação (ex: resource_updated, api_mutation)"
+// @Param resource query string false "Recurso (ex: equino, exame_laboratorial)"
+// @Param risk_level query string false "Nível de risco (low, medium, high)"
+// @Param success query bool false "Filtrar por sucesso"
+// @Param start_date query string false "Data inicial (RFC3339)"
+// @Param end_date query string false "Data final (RFC3339)"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/audit/logs [get]
+// @Security BearerAuth
+func (h *Handler) ListLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	filter, err := parseFilter(c)
+	if err != nil {
+		h.respondError(c, err, "Filtros inválidos")
+		return
+	}
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+
+	logs, total, err := h.service.ListLogs(c.Request.Context(), filter)
+	if err != nil {
+		h.respondError(c, err, "Erro ao consultar logs de auditoria")
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Logs de auditoria (total: %d)", total),
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: logs,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: totalPages,
+			},
+		},
+	})
+}
+
+// ExportLogs godoc
+// @Summary Exportar trilha de auditoria
+// @Description Exporta registros de auditoria em JSON ou CSV para reguladores e seguradoras (apenas admin)
+// @Tags Auditoria
+// @Produce json
+// @Produce text/csv
+// @Param format query string false "Formato (json ou csv)" default(json)
+// @Param actor_id query int false "ID do usuário que executou a ação"
+// @Param action query string false "Ação"
+// @Param resource query string false "Recurso"
+// @Param risk_level query string false "Nível de risco"
+// @Param success query bool false "Filtrar por sucesso"
+// @Param start_date query string false "Data inicial (RFC3339)"
+// @Param end_date query string false "Data final (RFC3339)"
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/audit/logs/export [get]
+// @Security BearerAuth
+func (h *Handler) ExportLogs(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+
+	filter, err := parseFilter(c)
+	if err != nil {
+		h.respondError(c, err, "Filtros inválidos")
+		return
+	}
+	filter.Limit = maxExportLogs
+
+	data, err := h.service.ExportLogs(c.Request.Context(), filter, format)
+	if err != nil {
+		h.respondError(c, err, "Erro ao exportar logs de auditoria")
+		return
+	}
+
+	contentType := "application/json"
+	if format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+	}
+	filename := fmt.Sprintf("audit_logs_%s.%s", time.Now().Format("20060102_150405"), format)
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// GetStats godoc
+// @Summary Estatísticas de auditoria
+// @Description Totais por ação, nível de risco, falhas e principais IPs no período (apenas admin)
+// @Tags Auditoria
+// @Produce json
+// @Param days query int false "Período em dias" default(30)
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/audit/stats [get]
+// @Security BearerAuth
+func (h *Handler) GetStats(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days < 1 {
+		days = 30
+	}
+
+	stats, err := h.service.GetStats(c.Request.Context(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		h.respondError(c, err, "Erro ao calcular estatísticas de auditoria")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Estatísticas de auditoria dos últimos %d dias", days),
+		Timestamp: time.Now(),
+		Data:      stats,
+	})
+}
+
+// VerifyChain godoc
+// @Summary Verificar integridade da trilha de auditoria
+// @Description Percorre a cadeia de hashes, recalcula as raízes de Merkle dos checkpoints e reporta quebras (apenas admin)
+// @Tags Auditoria
+// @Produce json
+// @Param from_id query int false "ID inicial do registro"
+// @Param to_id query int false "ID final do registro"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/audit/verify [get]
+// @Security BearerAuth
+func (h *Handler) VerifyChain(c *gin.Context) {
+	fromID, err := parseUintQuery(c, "from_id")
+	if err != nil {
+		h.respondError(c, err, "Parâmetros inválidos")
+		return
+	}
+	toID, err := parseUintQuery(c, "to_id")
+	if err != nil {
+		h.respondError(c, err, "Parâmetros inválidos")
+		return
+	}
+
+	report, err := h.service.VerifyChain(c.Request.Context(), fromID, toID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao verificar cadeia de auditoria")
+		return
+	}
+
+	message := "Cadeia de auditoria íntegra"
+	if !report.Valid {
+		message = fmt.Sprintf("Cadeia de auditoria com %d quebra(s) de integridade", len(report.Breaks))
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data:      report,
+	})
+}
+
+// ListCheckpoints godoc
+// @Summary Listar checkpoints da trilha de auditoria
+// @Description Lista raízes de Merkle consolidadas e o status de ancoragem (apenas admin)
+// @Tags Auditoria
+// @Produce json
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/audit/checkpoints [get]
+// @Security BearerAuth
+func (h *Handler) ListCheckpoints(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	checkpoints, total, err := h.service.ListCheckpoints(c.Request.Context(), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar checkpoints de auditoria")
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Checkpoints de auditoria (total: %d)", total),
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: checkpoints,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: totalPages,
+			},
+		},
+	})
+}
+
+// CreateCheckpoint godoc
+// @Summary Criar checkpoint da trilha de auditoria
+// @Description Consolida os registros desde o último checkpoint em uma raiz de Merkle e a ancora imediatamente (apenas admin)
+// @Tags Auditoria
+// @Produce json
+// @Success 201 {object} models.APIResponse
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/audit/checkpoints [post]
+// @Security BearerAuth
+func (h *Handler) CreateCheckpoint(c *gin.Context) {
+	checkpoint, err := h.service.CreateCheckpoint(c.Request.Context())
+	if err != nil {
+		h.respondError(c, err, "Erro ao criar checkpoint de auditoria")
+		return
+	}
+
+	if checkpoint == nil {
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success:   true,
+			Message:   "Nenhum registro novo desde o último checkpoint",
+			Timestamp: time.Now(),
+			Data:      nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Checkpoint de auditoria criado",
+		Timestamp: time.Now(),
+		Data:      checkpoint,
+	})
+}
+
+func parseUintQuery(c *gin.Context, name string) (uint, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, &apperrors.ValidationError{Field: name, Message: name + " inválido", Value: raw}
+	}
+	return uint(value), nil
+}
+
+func parseFilter(c *gin.Context) (*audit.AuditFilter, error) {
+	filter := &audit.AuditFilter{
+		Action:    c.Query("action"),
+		Resource:  c.Query("resource"),
+		RiskLevel: c.Query("risk_level"),
+	}
+
+	if actor := c.Query("actor_id"); actor != "" {
+		id, err := strconv.ParseUint(actor, 10, 32)
+		if err != nil {
+			return nil, &apperrors.ValidationError{Field: "actor_id", Message: "actor_id inválido", Value: actor}
+		}
+		actorID := uint(id)
+		filter.ActorID = &actorID
+	}
+
+	if success := c.Query("success"); success != "" {
+		value, err := strconv.ParseBool(success)
+		if err != nil {
+			return nil, &apperrors.ValidationError{Field: "success", Message: "success deve ser true ou false", Value: success}
+		}
+		filter.Success = &value
+	}
+
+	if start := c.Query("start_date"); start != "" {
+		t, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return nil, &apperrors.ValidationError{Field: "start_date", Message: "data deve estar no formato RFC3339", Value: start}
+		}
+		filter.StartDate = t
+	}
+
+	if end := c.Query("end_date"); end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return nil, &apperrors.ValidationError{Field: "end_date", Message: "data deve estar no formato RFC3339", Value: end}
+		}
+		filter.EndDate = t
+	}
+
+	return filter, nil
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	if apperrors.IsValidation(err) {
+		status = http.StatusBadRequest
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
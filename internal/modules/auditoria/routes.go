@@ -0,0 +1,19 @@
+package auditoria
+
+import (
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	auditoria := rg.Group("/admin/audit")
+	auditoria.Use(authMiddleware, middleware.RequireAdminMiddleware())
+	{
+		auditoria.GET("/logs", handler.ListLogs)
+		auditoria.GET("/logs/export", handler.ExportLogs)
+		auditoria.GET("/stats", handler.GetStats)
+		auditoria.GET("/verify", handler.VerifyChain)
+		auditoria.GET("/checkpoints", handler.ListCheckpoints)
+		auditoria.POST("/checkpoints", handler.CreateCheckpoint)
+	}
+}
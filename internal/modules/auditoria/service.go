@@ -0,0 +1,127 @@
+package auditoria
+
+import (
+	"context"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/security/audit"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+// Store fonte dos registros de auditoria (implementada por audit.AuditLogger)
+type Store interface {
+	QueryLogs(ctx context.Context, filter *audit.AuditFilter) ([]models.AuditLog, error)
+	CountLogs(ctx context.Context, filter *audit.AuditFilter) (int64, error)
+	ExportLogs(ctx context.Context, filter *audit.AuditFilter, format string) ([]byte, error)
+	GetAuditStats(ctx context.Context, period time.Duration) (map[string]interface{}, error)
+	VerifyChain(ctx context.Context, fromID, toID uint) (*audit.ChainReport, error)
+	CreateCheckpoint(ctx context.Context) (*models.AuditCheckpoint, error)
+	ListCheckpoints(ctx context.Context, limit, offset int) ([]models.AuditCheckpoint, int64, error)
+}
+
+type Service interface {
+	ListLogs(ctx context.Context, filter *audit.AuditFilter) ([]models.AuditLog, int64, error)
+	ExportLogs(ctx context.Context, filter *audit.AuditFilter, format string) ([]byte, error)
+	GetStats(ctx context.Context, period time.Duration) (map[string]interface{}, error)
+	VerifyChain(ctx context.Context, fromID, toID uint) (*audit.ChainReport, error)
+	CreateCheckpoint(ctx context.Context) (*models.AuditCheckpoint, error)
+	ListCheckpoints(ctx context.Context, page, limit int) ([]models.AuditCheckpoint, int64, error)
+}
+
+type service struct {
+	store  Store
+	logger *logging.Logger
+}
+
+func NewService(store Store, logger *logging.Logger) Service {
+	return &service{
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (s *service) ListLogs(ctx context.Context, filter *audit.AuditFilter) ([]models.AuditLog, int64, error) {
+	total, err := s.store.CountLogs(ctx, filter)
+	if err != nil {
+		s.logger.LogError(err, "AuditoriaService.ListLogs", nil)
+		return nil, 0, apperrors.NewDatabaseError("count_audit_logs", "erro ao contar logs de auditoria", err)
+	}
+
+	logs, err := s.store.QueryLogs(ctx, filter)
+	if err != nil {
+		s.logger.LogError(err, "AuditoriaService.ListLogs", nil)
+		return nil, 0, apperrors.NewDatabaseError("query_audit_logs", "erro ao consultar logs de auditoria", err)
+	}
+
+	return logs, total, nil
+}
+
+func (s *service) ExportLogs(ctx context.Context, filter *audit.AuditFilter, format string) ([]byte, error) {
+	if format != "json" && format != "csv" {
+		return nil, &apperrors.ValidationError{Field: "format", Message: "formato deve ser json ou csv", Value: format}
+	}
+
+	data, err := s.store.ExportLogs(ctx, filter, format)
+	if err != nil {
+		s.logger.LogError(err, "AuditoriaService.ExportLogs", logging.Fields{"format": format})
+		return nil, apperrors.NewDatabaseError("export_audit_logs", "erro ao exportar logs de auditoria", err)
+	}
+
+	return data, nil
+}
+
+func (s *service) GetStats(ctx context.Context, period time.Duration) (map[string]interface{}, error) {
+	stats, err := s.store.GetAuditStats(ctx, period)
+	if err != nil {
+		s.logger.LogError(err, "AuditoriaService.GetStats", nil)
+		return nil, apperrors.NewDatabaseError("audit_stats", "erro ao calcular estatísticas de auditoria", err)
+	}
+	return stats, nil
+}
+
+func (s *service) VerifyChain(ctx context.Context, fromID, toID uint) (*audit.ChainReport, error) {
+	if toID > 0 && toID < fromID {
+		return nil, &apperrors.ValidationError{Field: "to_id", Message: "to_id deve ser maior ou igual a from_id"}
+	}
+
+	report, err := s.store.VerifyChain(ctx, fromID, toID)
+	if err != nil {
+		s.logger.LogError(err, "AuditoriaService.VerifyChain", logging.Fields{"from_id": fromID, "to_id": toID})
+		return nil, apperrors.NewDatabaseError("verify_audit_chain", "erro ao verificar cadeia de auditoria", err)
+	}
+
+	if !report.Valid {
+		s.logger.WithFields(logging.Fields{
+			"breaks":    len(report.Breaks),
+			"first_id":  report.FirstLogID,
+			"last_id":   report.LastLogID,
+			"truncated": report.Truncated,
+		}).Warn("Cadeia de auditoria com quebras de integridade")
+	}
+
+	return report, nil
+}
+
+func (s *service) CreateCheckpoint(ctx context.Context) (*models.AuditCheckpoint, error) {
+	checkpoint, err := s.store.CreateCheckpoint(ctx)
+	if err != nil {
+		s.logger.LogError(err, "AuditoriaService.CreateCheckpoint", nil)
+		// Checkpoint gravado mas não ancorado: devolvido com anchor_status = failed
+		if checkpoint != nil {
+			return checkpoint, nil
+		}
+		return nil, apperrors.NewDatabaseError("create_audit_checkpoint", "erro ao criar checkpoint de auditoria", err)
+	}
+	return checkpoint, nil
+}
+
+func (s *service) ListCheckpoints(ctx context.Context, page, limit int) ([]models.AuditCheckpoint, int64, error) {
+	checkpoints, total, err := s.store.ListCheckpoints(ctx, limit, (page-1)*limit)
+	if err != nil {
+		s.logger.LogError(err, "AuditoriaService.ListCheckpoints", nil)
+		return nil, 0, apperrors.NewDatabaseError("list_audit_checkpoints", "erro ao listar checkpoints de auditoria", err)
+	}
+	return checkpoints, total, nil
+}
@@ -0,0 +1,141 @@
+package busca
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/equinoid/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// dialeto correspondência e relevância do texto buscado, conforme os recursos do banco
+type dialeto interface {
+	correspondencia(db *gorm.DB, f Filtros) (string, []interface{})
+	relevancia(f Filtros) (string, []interface{})
+	// anuncioAtivo subconsulta de anúncios de venda ativos do equino; nil quando o banco não tem o esquema marketplace
+	anuncioAtivo(db *gorm.DB) *gorm.DB
+}
+
+// postgresDialeto texto completo e trigramas sobre o nome sem acentos (migração 019)
+type postgresDialeto struct{}
+
+const (
+	nomePostgres         = "equinoid_unaccent(lower(equinos.nome))"
+	documentoPostgres    = "to_tsvector('simple', " + nomePostgres + ")"
+	proprietarioPostgres = "equinoid_unaccent(lower(users.name))"
+)
+
+func (postgresDialeto) correspondencia(db *gorm.DB, f Filtros) (string, []interface{}) {
+	prefixo := escapeLike(strings.ToUpper(f.Termo)) + "%"
+	condicao := `(` + nomePostgres + ` % equinoid_unaccent(?)
+		OR equinos.equinoid LIKE ? ESCAPE '\' OR equinos.microchip_id LIKE ? ESCAPE '\' OR EXISTS (?)
+		OR ` + proprietarioPostgres + ` LIKE '%' || equinoid_unaccent(?) || '%' ESCAPE '\'`
+	args := []interface{}{f.Termo, prefixo, prefixo, identificadorPrefixo(db, prefixo), escapeLike(f.Termo)}
+	if consulta := tsquery(f.Termos); consulta != "" {
+		condicao += ` OR ` + documentoPostgres + ` @@ to_tsquery('simple', equinoid_unaccent(?))`
+		args = append(args, consulta)
+	}
+	return condicao + `)`, args
+}
+
+func (postgresDialeto) relevancia(f Filtros) (string, []interface{}) {
+	identificador := strings.ToUpper(f.Termo)
+	expr := `(similarity(` + nomePostgres + `, equinoid_unaccent(?))
+		+ CASE WHEN equinos.equinoid = ? OR equinos.microchip_id = ? THEN 1 ELSE 0 END`
+	args := []interface{}{f.Termo, identificador, identificador}
+	if consulta := tsquery(f.Termos); consulta != "" {
+		expr += ` + ts_rank(` + documentoPostgres + `, to_tsquery('simple', equinoid_unaccent(?)))`
+		args = append(args, consulta)
+	}
+	return `CAST(` + expr + `) AS DOUBLE PRECISION)`, args
+}
+
+func (postgresDialeto) anuncioAtivo(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.AnuncioMarketplace{}).
+		Select("1").
+		Where("equinoid = equinos.equinoid AND tipo = ? AND status = ?", models.TipoAnuncioAnimal, models.StatusAnuncioAtivo)
+}
+
+// tsquery todas as palavras, cada uma também como prefixo ("relamp" encontra "Relâmpago")
+func tsquery(termos []string) string {
+	partes := make([]string, 0, len(termos))
+	for _, termo := range termos {
+		partes = append(partes, termo+":*")
+	}
+	return strings.Join(partes, " & ")
+}
+
+// sqliteDialeto compara por GLOB com classes de caracteres que aceitam cada letra com ou sem acento, em maiúscula ou
+// minúscula; lower() e REPLACE do SQLite não tratam acentos
+type sqliteDialeto struct{}
+
+func (sqliteDialeto) correspondencia(db *gorm.DB, f Filtros) (string, []interface{}) {
+	prefixo := escapeLike(strings.ToUpper(f.Termo)) + "%"
+
+	palavras := make([]string, 0, len(f.Termos))
+	args := []interface{}{prefixo, prefixo, identificadorPrefixo(db, prefixo), "*" + globSemAcento(f.Termo) + "*"}
+	for _, termo := range f.Termos {
+		palavras = append(palavras, `equinos.nome GLOB ?`)
+		args = append(args, "*"+globSemAcento(termo)+"*")
+	}
+	condicao := `(equinos.equinoid LIKE ? ESCAPE '\' OR equinos.microchip_id LIKE ? ESCAPE '\' OR EXISTS (?)
+		OR users.name GLOB ?`
+	if len(palavras) > 0 {
+		condicao += ` OR (` + strings.Join(palavras, " AND ") + `)`
+	}
+	return condicao + `)`, args
+}
+
+func (sqliteDialeto) relevancia(f Filtros) (string, []interface{}) {
+	padrao := globSemAcento(f.Termo)
+	identificador := strings.ToUpper(f.Termo)
+	expr := `CAST((CASE WHEN equinos.nome GLOB ? THEN 3 WHEN equinos.nome GLOB ? THEN 2 WHEN equinos.nome GLOB ? THEN 1 ELSE 0 END)
+		+ (CASE WHEN equinos.equinoid = ? OR equinos.microchip_id = ? THEN 1 ELSE 0 END) AS REAL)`
+	return expr, []interface{}{padrao, padrao + "*", "*" + padrao + "*", identificador, identificador}
+}
+
+func (sqliteDialeto) anuncioAtivo(db *gorm.DB) *gorm.DB {
+	return nil
+}
+
+// acentos letra base e as variantes acentuadas do português (e vizinhos)
+var acentos = map[rune]string{
+	'a': "áàâãäÁÀÂÃÄ",
+	'e': "éèêëÉÈÊË",
+	'i': "íìîïÍÌÎÏ",
+	'o': "óòôõöÓÒÔÕÖ",
+	'u': "úùûüÚÙÛÜ",
+	'c': "çÇ",
+	'n': "ñÑ",
+}
+
+// semAcento variante acentuada -> letra base
+var semAcento = func() map[rune]rune {
+	m := make(map[rune]rune)
+	for base, variantes := range acentos {
+		for _, v := range variantes {
+			m[v] = base
+		}
+	}
+	return m
+}()
+
+// globSemAcento padrão GLOB do texto em que cada letra aceita maiúscula, minúscula e as variantes acentuadas
+// ("relampago" encontra "Relâmpago"); os curingas do GLOB no texto são comparados literalmente
+func globSemAcento(texto string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(texto) {
+		if base, ok := semAcento[r]; ok {
+			r = base
+		}
+		switch {
+		case unicode.IsLetter(r):
+			b.WriteString("[" + string(r) + string(unicode.ToUpper(r)) + acentos[r] + "]")
+		case r == '*' || r == '?' || r == '[':
+			b.WriteString("[" + string(r) + "]")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package busca
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// SearchEquinos godoc
+// @Summary Buscar equinos
+// @Description Busca por texto (nome sem acentos e com tolerância a erros de digitação, EquinoId, UELN/registro de raça, microchip e proprietário) com filtros, facetas, ordenação e paginação por cursor
+// @Tags Busca
+// @Produce json
+// @Param q query string false "Texto livre"
+// @Param raca query string false "Raças, separadas por vírgula"
+// @Param pelagem query string false "Pelagens, separadas por vírgula"
+// @Param sexo query string false "Sexos, separados por vírgula"
+// @Param pais query string false "Países de origem (ISO 3166 alfa-3), separados por vírgula"
+// @Param faixa_etaria query string false "Faixas etárias (0-2, 3-5, 6-10, 11-15, 16+), separadas por vírgula"
+// @Param idade_min query int false "Idade mínima em anos"
+// @Param idade_max query int false "Idade máxima em anos"
+// @Param nivel_valorizacao query string false "Níveis de valorização, separados por vírgula"
+// @Param tokenizado query bool false "Somente equinos com tokenização ativa (true) ou sem (false)"
+// @Param a_venda query bool false "Somente equinos à venda (anúncio ativo ou inscrição em leilão aberto) ou não"
+// @Param ordenacao query string false "relevancia (padrão com texto), nome, nome_desc, mais_novos, mais_velhos, recentes (padrão sem texto)"
+// @Param cursor query string false "Cursor devolvido em proximo_cursor"
+// @Param limit query int false "Itens por página (máx. 100)" default(20)
+// @Param facetas query bool false "Calcular facetas" default(true)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /search/equinos [get]
+// @Security BearerAuth
+func (h *Handler) SearchEquinos(c *gin.Context) {
+	var req models.BuscaEquinosRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Parâmetros inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	resultado, err := h.service.SearchEquinos(c.Request.Context(), &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar equinos")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Equinos encontrados (total: %d)", resultado.Total),
+		Timestamp: time.Now(),
+		Data:      resultado,
+	})
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	if apperrors.IsValidation(err) {
+		status = http.StatusBadRequest
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,261 @@
+package busca
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// maxValoresFaceta valores devolvidos em cada faceta, dos mais frequentes para os menos
+const maxValoresFaceta = 50
+
+// Nomes das facetas devolvidas na busca
+const (
+	FacetaRaca             = "raca"
+	FacetaPelagem          = "pelagem"
+	FacetaSexo             = "sexo"
+	FacetaFaixaEtaria      = "faixa_etaria"
+	FacetaPais             = "pais"
+	FacetaNivelValorizacao = "nivel_valorizacao"
+	FacetaTokenizado       = "tokenizado"
+	FacetaAVenda           = "a_venda"
+)
+
+// facetas coluna da tabela derivada de cada faceta; booleanos viram "sim"/"nao" para ficarem iguais nos dois bancos
+var facetas = map[string]string{
+	FacetaRaca:             "busca.raca",
+	FacetaPelagem:          "busca.pelagem",
+	FacetaSexo:             "busca.sexo",
+	FacetaFaixaEtaria:      "busca.faixa_etaria",
+	FacetaPais:             "busca.pais_origem",
+	FacetaNivelValorizacao: "busca.nivel_valorizacao",
+	FacetaTokenizado:       "CASE WHEN busca.tokenizado THEN 'sim' ELSE 'nao' END",
+	FacetaAVenda:           "CASE WHEN busca.a_venda THEN 'sim' ELSE 'nao' END",
+}
+
+// ordenacoes coluna da tabela derivada e sentido de cada ordenação; o id desempata no mesmo sentido
+var ordenacoes = map[string]struct {
+	coluna string
+	desc   bool
+}{
+	models.OrdenacaoBuscaRelevancia: {"busca.relevancia", true},
+	models.OrdenacaoBuscaNome:       {"busca.nome", false},
+	models.OrdenacaoBuscaNomeDesc:   {"busca.nome", true},
+	models.OrdenacaoBuscaMaisNovos:  {"busca.data_nascimento", true},
+	models.OrdenacaoBuscaMaisVelhos: {"busca.data_nascimento", false},
+	models.OrdenacaoBuscaRecentes:   {"busca.created_at", true},
+}
+
+// Filtros critérios já validados e normalizados pelo serviço
+type Filtros struct {
+	Termo         string   // texto livre em minúsculas
+	Termos        []string // palavras do texto, só letras e dígitos
+	Racas         []string
+	Pelagens      []string
+	Sexos         []string
+	Paises        []string
+	FaixasEtarias []string
+	Niveis        []string
+	NascidoAte    *time.Time // idade mínima
+	NascidoApos   *time.Time // idade máxima
+	Tokenizado    *bool
+	AVenda        *bool
+}
+
+// Cursor posição após o último item da página anterior
+type Cursor struct {
+	Valor interface{}
+	ID    uint
+}
+
+// Consulta busca completa: filtros, ordenação e página
+type Consulta struct {
+	Filtros
+	Ordenacao string
+	Cursor    *Cursor
+	Limit     int
+	Facetas   bool
+	Agora     time.Time
+}
+
+type Repository interface {
+	// Search devolve até Limit+1 itens (o excedente indica próxima página), o total e as facetas do resultado filtrado
+	Search(ctx context.Context, consulta *Consulta) ([]*models.EquinoBusca, int64, map[string][]models.FacetaValor, error)
+}
+
+type repository struct {
+	db      *gorm.DB
+	dialeto dialeto
+}
+
+// NewRepository usa texto completo, unaccent e trigramas no Postgres; nos demais bancos (SQLite em desenvolvimento e
+// testes) a busca remove acentos com REPLACE e compara por LIKE
+func NewRepository(db *gorm.DB) Repository {
+	var d dialeto = sqliteDialeto{}
+	if db.Dialector.Name() == "postgres" {
+		d = postgresDialeto{}
+	}
+	return &repository{db: db, dialeto: d}
+}
+
+func (r *repository) Search(ctx context.Context, consulta *Consulta) ([]*models.EquinoBusca, int64, map[string][]models.FacetaValor, error) {
+	var total int64
+	if err := r.filtrada(ctx, consulta).Count(&total).Error; err != nil {
+		return nil, 0, nil, apperrors.NewDatabaseError("search_count", "erro ao contar resultados da busca", err)
+	}
+
+	ordem := ordenacoes[consulta.Ordenacao]
+	sentido, comparador := "ASC", ">"
+	if ordem.desc {
+		sentido, comparador = "DESC", "<"
+	}
+
+	query := r.filtrada(ctx, consulta).Select("busca.*")
+	if consulta.Cursor != nil {
+		query = query.Where(
+			fmt.Sprintf("%s %s ? OR (%s = ? AND busca.id %s ?)", ordem.coluna, comparador, ordem.coluna, comparador),
+			consulta.Cursor.Valor, consulta.Cursor.Valor, consulta.Cursor.ID,
+		)
+	}
+
+	var itens []*models.EquinoBusca
+	err := query.
+		Order(fmt.Sprintf("%s %s, busca.id %s", ordem.coluna, sentido, sentido)).
+		Limit(consulta.Limit + 1).
+		Scan(&itens).Error
+	if err != nil {
+		return nil, 0, nil, apperrors.NewDatabaseError("search_equinos", "erro ao buscar equinos", err)
+	}
+
+	if !consulta.Facetas {
+		return itens, total, nil, nil
+	}
+	resultado := make(map[string][]models.FacetaValor, len(facetas))
+	for nome, coluna := range facetas {
+		var valores []models.FacetaValor
+		err := r.filtrada(ctx, consulta).
+			Select(coluna + " AS valor, COUNT(*) AS quantidade").
+			Where(coluna + " IS NOT NULL AND " + coluna + " <> ''").
+			Group(coluna).
+			Order("quantidade DESC, valor").
+			Limit(maxValoresFaceta).
+			Scan(&valores).Error
+		if err != nil {
+			return nil, 0, nil, apperrors.NewDatabaseError("search_facetas", "erro ao calcular facetas da busca", err)
+		}
+		resultado[nome] = valores
+	}
+	return itens, total, resultado, nil
+}
+
+// filtrada tabela derivada com os atributos calculados de cada equino, restrita pelos filtros
+func (r *repository) filtrada(ctx context.Context, consulta *Consulta) *gorm.DB {
+	query := r.db.WithContext(ctx).Table("(?) AS busca", r.base(ctx, consulta))
+	f := consulta.Filtros
+
+	if len(f.Racas) > 0 {
+		query = query.Where("busca.raca IN ?", f.Racas)
+	}
+	if len(f.Pelagens) > 0 {
+		query = query.Where("busca.pelagem IN ?", f.Pelagens)
+	}
+	if len(f.Sexos) > 0 {
+		query = query.Where("busca.sexo IN ?", f.Sexos)
+	}
+	if len(f.Paises) > 0 {
+		query = query.Where("busca.pais_origem IN ?", f.Paises)
+	}
+	if len(f.FaixasEtarias) > 0 {
+		query = query.Where("busca.faixa_etaria IN ?", f.FaixasEtarias)
+	}
+	if len(f.Niveis) > 0 {
+		query = query.Where("busca.nivel_valorizacao IN ?", f.Niveis)
+	}
+	if f.NascidoAte != nil {
+		query = query.Where("busca.data_nascimento <= ?", *f.NascidoAte)
+	}
+	if f.NascidoApos != nil {
+		query = query.Where("busca.data_nascimento > ?", *f.NascidoApos)
+	}
+	if f.Tokenizado != nil {
+		query = query.Where("busca.tokenizado = ?", *f.Tokenizado)
+	}
+	if f.AVenda != nil {
+		query = query.Where("busca.a_venda = ?", *f.AVenda)
+	}
+	return query
+}
+
+// base equinos com proprietário, nível de valorização mais recente, tokenização ativa, oferta de venda, faixa etária e
+// relevância para o texto buscado
+func (r *repository) base(ctx context.Context, consulta *Consulta) *gorm.DB {
+	db := r.db.WithContext(ctx)
+
+	nivel := db.Model(&models.RankingValorizacao{}).
+		Select("nivel_valorizacao").
+		Where("equinoid = equinos.equinoid").
+		Order("data_ranking DESC").
+		Limit(1)
+	tokenizado := db.Model(&models.Tokenizacao{}).
+		Select("1").
+		Where("equino_id = equinos.id AND status = ?", models.StatusTokenAtivo)
+	emLeilao := db.Model(&models.ParticipacaoLeilao{}).
+		Select("1").
+		Where("equino_id = equinos.id AND status IN ? AND leilao_id IN (?)",
+			[]models.StatusParticipacaoLeilao{models.StatusParticipacaoInscrito, models.StatusParticipacaoAprovado},
+			db.Model(&models.Leilao{}).Select("id").Where("status IN ?", []models.StatusLeilao{models.StatusLeilaoAgendado, models.StatusLeilaoEmAndamento}),
+		)
+
+	aVenda, aVendaArgs := "EXISTS (?)", []interface{}{emLeilao}
+	if anuncio := r.dialeto.anuncioAtivo(db); anuncio != nil {
+		aVenda, aVendaArgs = "(EXISTS (?) OR EXISTS (?))", []interface{}{emLeilao, anuncio}
+	}
+
+	anos := func(n int) time.Time { return consulta.Agora.AddDate(-n, 0, 0) }
+	faixa := `CASE WHEN equinos.data_nascimento IS NULL THEN NULL
+		WHEN equinos.data_nascimento > ? THEN ? WHEN equinos.data_nascimento > ? THEN ?
+		WHEN equinos.data_nascimento > ? THEN ? WHEN equinos.data_nascimento > ? THEN ? ELSE ? END`
+
+	relevancia, relevanciaArgs := "0", []interface{}(nil)
+	if consulta.Termo != "" {
+		relevancia, relevanciaArgs = r.dialeto.relevancia(consulta.Filtros)
+	}
+
+	selecao := `equinos.id, equinos.equinoid, equinos.nome, equinos.raca, equinos.pelagem, equinos.sexo,
+		equinos.data_nascimento, equinos.pais_origem, equinos.created_at, users.name AS proprietario_nome,
+		(?) AS nivel_valorizacao, EXISTS (?) AS tokenizado, ` + aVenda + ` AS a_venda,
+		` + faixa + ` AS faixa_etaria, ` + relevancia + ` AS relevancia`
+	args := []interface{}{nivel, tokenizado}
+	args = append(args, aVendaArgs...)
+	args = append(args,
+		anos(3), models.FaixaEtariaPotro, anos(6), models.FaixaEtariaJovem,
+		anos(11), models.FaixaEtariaAdulto, anos(16), models.FaixaEtariaMaduro, models.FaixaEtariaSenior,
+	)
+	args = append(args, relevanciaArgs...)
+
+	query := db.Model(&models.Equino{}).
+		Select(selecao, args...).
+		Joins("LEFT JOIN users ON users.id = equinos.proprietario_id")
+	if consulta.Termo != "" {
+		condicao, condicaoArgs := r.dialeto.correspondencia(db, consulta.Filtros)
+		query = query.Where(condicao, condicaoArgs...)
+	}
+	return query
+}
+
+// identificadorPrefixo equinos com alias, UELN ou registro de raça começando pelo texto
+func identificadorPrefixo(db *gorm.DB, prefixo string) *gorm.DB {
+	return db.Model(&models.EquinoIdentificador{}).
+		Select("1").
+		Where(`equino_id = equinos.id AND valor LIKE ? ESCAPE '\'`, prefixo)
+}
+
+// escapeLike escapa os curingas do LIKE para que o texto seja comparado literalmente
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,13 @@
+package busca
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	search := rg.Group("/search")
+	search.Use(authMiddleware)
+	{
+		search.GET("/equinos", handler.SearchEquinos)
+	}
+}
@@ -0,0 +1,237 @@
+package busca
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+	// maxTermo caracteres do texto livre considerados na busca
+	maxTermo = 100
+	// maxTermos palavras do texto livre usadas no texto completo
+	maxTermos = 8
+)
+
+var faixasEtarias = map[string]bool{
+	models.FaixaEtariaPotro:  true,
+	models.FaixaEtariaJovem:  true,
+	models.FaixaEtariaAdulto: true,
+	models.FaixaEtariaMaduro: true,
+	models.FaixaEtariaSenior: true,
+}
+
+type Service interface {
+	SearchEquinos(ctx context.Context, req *models.BuscaEquinosRequest) (*models.ResultadoBuscaEquinos, error)
+}
+
+type service struct {
+	repo   Repository
+	logger *logging.Logger
+}
+
+func NewService(repo Repository, logger *logging.Logger) Service {
+	return &service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// cursorPagina conteúdo opaco do cursor: ordenação em uso, valor da coluna ordenada e id do último item
+type cursorPagina struct {
+	Ordenacao string          `json:"o"`
+	Valor     json.RawMessage `json:"v"`
+	ID        uint            `json:"id"`
+}
+
+func (s *service) SearchEquinos(ctx context.Context, req *models.BuscaEquinosRequest) (*models.ResultadoBuscaEquinos, error) {
+	consulta, err := s.consulta(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	itens, total, facetas, err := s.repo.Search(ctx, consulta)
+	if err != nil {
+		s.logger.LogError(err, "BuscaService.SearchEquinos", logging.Fields{"q": consulta.Termo, "ordenacao": consulta.Ordenacao})
+		return nil, err
+	}
+
+	resultado := &models.ResultadoBuscaEquinos{
+		Itens:     itens,
+		Total:     total,
+		Facetas:   facetas,
+		Ordenacao: consulta.Ordenacao,
+	}
+	if len(itens) > consulta.Limit {
+		resultado.Itens = itens[:consulta.Limit]
+		resultado.ProximoCursor = encodeCursor(consulta.Ordenacao, resultado.Itens[consulta.Limit-1])
+	}
+	if resultado.Itens == nil {
+		resultado.Itens = []*models.EquinoBusca{}
+	}
+	return resultado, nil
+}
+
+// consulta valida e normaliza os parâmetros recebidos
+func (s *service) consulta(req *models.BuscaEquinosRequest, agora time.Time) (*Consulta, error) {
+	termo := strings.Join(strings.Fields(strings.ToLower(req.Q)), " ")
+	if len([]rune(termo)) > maxTermo {
+		termo = string([]rune(termo)[:maxTermo])
+	}
+
+	consulta := &Consulta{
+		Filtros: Filtros{
+			Termo:         termo,
+			Termos:        palavras(termo),
+			Racas:         lista(req.Raca, nil),
+			Pelagens:      lista(req.Pelagem, nil),
+			Sexos:         lista(req.Sexo, strings.ToLower),
+			Paises:        lista(req.PaisOrigem, strings.ToUpper),
+			FaixasEtarias: lista(req.FaixaEtaria, nil),
+			Niveis:        lista(req.NivelValorizacao, strings.ToLower),
+			Tokenizado:    req.Tokenizado,
+			AVenda:        req.AVenda,
+		},
+		Ordenacao: req.Ordenacao,
+		Limit:     req.Limit,
+		Facetas:   req.Facetas == nil || *req.Facetas,
+		Agora:     agora,
+	}
+
+	for _, faixa := range consulta.FaixasEtarias {
+		if !faixasEtarias[faixa] {
+			return nil, &apperrors.ValidationError{Field: "faixa_etaria", Message: "faixa etária inválida (0-2, 3-5, 6-10, 11-15, 16+)", Value: faixa}
+		}
+	}
+	if req.IdadeMin != nil && *req.IdadeMin < 0 || req.IdadeMax != nil && *req.IdadeMax < 0 {
+		return nil, &apperrors.ValidationError{Field: "idade_min", Message: "idade não pode ser negativa"}
+	}
+	if req.IdadeMin != nil && req.IdadeMax != nil && *req.IdadeMin > *req.IdadeMax {
+		return nil, &apperrors.ValidationError{Field: "idade_min", Message: "idade mínima maior que a máxima", Value: *req.IdadeMin}
+	}
+	if req.IdadeMin != nil {
+		nascidoAte := agora.AddDate(-*req.IdadeMin, 0, 0)
+		consulta.NascidoAte = &nascidoAte
+	}
+	if req.IdadeMax != nil {
+		nascidoApos := agora.AddDate(-(*req.IdadeMax + 1), 0, 0)
+		consulta.NascidoApos = &nascidoApos
+	}
+
+	if consulta.Limit <= 0 {
+		consulta.Limit = defaultLimit
+	}
+	if consulta.Limit > maxLimit {
+		consulta.Limit = maxLimit
+	}
+
+	switch {
+	case consulta.Ordenacao == "" && termo != "":
+		consulta.Ordenacao = models.OrdenacaoBuscaRelevancia
+	case consulta.Ordenacao == "", consulta.Ordenacao == models.OrdenacaoBuscaRelevancia && termo == "":
+		consulta.Ordenacao = models.OrdenacaoBuscaRecentes
+	}
+	if _, ok := ordenacoes[consulta.Ordenacao]; !ok {
+		return nil, &apperrors.ValidationError{
+			Field:   "ordenacao",
+			Message: "ordenação inválida (relevancia, nome, nome_desc, mais_novos, mais_velhos, recentes)",
+			Value:   consulta.Ordenacao,
+		}
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor, consulta.Ordenacao)
+		if err != nil {
+			return nil, err
+		}
+		consulta.Cursor = cursor
+	}
+	return consulta, nil
+}
+
+func encodeCursor(ordenacao string, ultimo *models.EquinoBusca) string {
+	var valor interface{}
+	switch ordenacao {
+	case models.OrdenacaoBuscaRelevancia:
+		valor = ultimo.Relevancia
+	case models.OrdenacaoBuscaNome, models.OrdenacaoBuscaNomeDesc:
+		valor = ultimo.Nome
+	case models.OrdenacaoBuscaMaisNovos, models.OrdenacaoBuscaMaisVelhos:
+		valor = ultimo.DataNascimento
+	default:
+		valor = ultimo.CreatedAt
+	}
+	raw, _ := json.Marshal(valor)
+	data, _ := json.Marshal(cursorPagina{Ordenacao: ordenacao, Valor: raw, ID: ultimo.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor recupera o valor com o tipo da coluna ordenada; cursores de outra ordenação são rejeitados
+func decodeCursor(encoded, ordenacao string) (*Cursor, error) {
+	invalido := &apperrors.ValidationError{Field: "cursor", Message: "cursor inválido ou de outra ordenação"}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalido
+	}
+	var pagina cursorPagina
+	if err := json.Unmarshal(data, &pagina); err != nil || pagina.Ordenacao != ordenacao || pagina.ID == 0 {
+		return nil, invalido
+	}
+
+	var valor interface{}
+	switch ordenacao {
+	case models.OrdenacaoBuscaRelevancia:
+		var relevancia float64
+		err = json.Unmarshal(pagina.Valor, &relevancia)
+		valor = relevancia
+	case models.OrdenacaoBuscaNome, models.OrdenacaoBuscaNomeDesc:
+		var nome string
+		err = json.Unmarshal(pagina.Valor, &nome)
+		valor = nome
+	default:
+		var instante time.Time
+		err = json.Unmarshal(pagina.Valor, &instante)
+		valor = instante
+	}
+	if err != nil {
+		return nil, invalido
+	}
+	return &Cursor{Valor: valor, ID: pagina.ID}, nil
+}
+
+// palavras partes do texto com letras e dígitos, usadas no texto completo
+func palavras(termo string) []string {
+	partes := strings.FieldsFunc(termo, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(partes) > maxTermos {
+		partes = partes[:maxTermos]
+	}
+	return partes
+}
+
+// lista valores separados por vírgula, sem vazios
+func lista(valor string, normalizar func(string) string) []string {
+	var valores []string
+	for _, v := range strings.Split(valor, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if normalizar != nil {
+			v = normalizar(v)
+		}
+		valores = append(valores, v)
+	}
+	return valores
+}
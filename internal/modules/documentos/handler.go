@@ -0,0 +1,476 @@
+package documentos
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead folga para os campos do formulário além do arquivo
+const multipartOverhead = 1 << 20
+
+type Handler struct {
+	service       Service
+	maxUploadSize int64
+	logger        *logging.Logger
+}
+
+func NewHandler(service Service, maxUploadSize int64, logger *logging.Logger) *Handler {
+	return &Handler{
+		service:       service,
+		maxUploadSize: maxUploadSize,
+		logger:        logger,
+	}
+}
+
+// Upload godoc
+// @Summary Enviar documento ao cofre do equino
+// @Description Upload multipart de foto, laudo ou documento; o tipo é identificado pelo conteúdo e imagens ganham miniatura
+// @Tags Documentos
+// @Accept multipart/form-data
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param arquivo formData file true "Arquivo (PDF, JPEG, PNG, GIF ou WebP)"
+// @Param categoria formData string true "foto_perfil, foto_galeria, evento, cobertura, laudo, registro, contrato ou outro"
+// @Param titulo formData string false "Título do documento"
+// @Param descricao formData string false "Descrição"
+// @Param visibilidade formData string false "privado ou publico"
+// @Param entidade_tipo formData string false "evento, cobertura ou exame"
+// @Param entidade_id formData int false "ID da entidade vinculada"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/documentos [post]
+// @Security BearerAuth
+func (h *Handler) Upload(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+
+	var req models.UploadDocumentoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	arquivo, ok := h.readFile(c)
+	if !ok {
+		return
+	}
+
+	documento, err := h.service.Upload(c.Request.Context(), c.Param("equinoid"), userID, userType, &req, arquivo)
+	if err != nil {
+		h.respondError(c, err, "Erro ao enviar documento")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Documento enviado",
+		Timestamp: time.Now(),
+		Data:      documento,
+	})
+}
+
+// ListByEquino godoc
+// @Summary Listar documentos do equino
+// @Description Lista os documentos do cofre; sem acesso ao prontuário apenas os públicos são retornados
+// @Tags Documentos
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param categoria query string false "Filtrar por categoria"
+// @Param entidade_tipo query string false "Filtrar por tipo de entidade vinculada"
+// @Param entidade_id query int false "Filtrar por entidade vinculada"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/documentos [get]
+// @Security BearerAuth
+func (h *Handler) ListByEquino(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	filters := make(map[string]interface{})
+	if categoria := c.Query("categoria"); categoria != "" {
+		filters["categoria"] = categoria
+	}
+	if entidadeTipo := c.Query("entidade_tipo"); entidadeTipo != "" {
+		filters["entidade_tipo"] = entidadeTipo
+	}
+	if entidadeID := c.Query("entidade_id"); entidadeID != "" {
+		if id, err := strconv.ParseUint(entidadeID, 10, 32); err == nil {
+			filters["entidade_id"] = uint(id)
+		}
+	}
+
+	documentos, err := h.service.ListByEquino(c.Request.Context(), c.Param("equinoid"), userID, userType, filters)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar documentos")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Documentos do equino (total: %d)", len(documentos)),
+		Timestamp: time.Now(),
+		Data:      documentos,
+	})
+}
+
+// GetByID godoc
+// @Summary Buscar documento
+// @Description Metadados do documento com o histórico de versões
+// @Tags Documentos
+// @Produce json
+// @Param id path int true "ID do documento"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /documentos/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetByID(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	documento, err := h.service.GetByID(c.Request.Context(), id, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Documento encontrado",
+		Timestamp: time.Now(),
+		Data:      documento,
+	})
+}
+
+// AddVersao godoc
+// @Summary Enviar nova versão do documento
+// @Description Substitui o conteúdo atual mantendo as versões anteriores disponíveis
+// @Tags Documentos
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "ID do documento"
+// @Param arquivo formData file true "Novo arquivo"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /documentos/{id}/versoes [post]
+// @Security BearerAuth
+func (h *Handler) AddVersao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	arquivo, ok := h.readFile(c)
+	if !ok {
+		return
+	}
+
+	documento, err := h.service.AddVersao(c.Request.Context(), id, userID, userType, arquivo)
+	if err != nil {
+		h.respondError(c, err, "Erro ao enviar nova versão")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Versão %d enviada", documento.VersaoAtual),
+		Timestamp: time.Now(),
+		Data:      documento,
+	})
+}
+
+// Delete godoc
+// @Summary Excluir documento
+// @Description Remove o documento do cofre (proprietário ou quem enviou)
+// @Tags Documentos
+// @Produce json
+// @Param id path int true "ID do documento"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /documentos/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) Delete(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id, userID, userType); err != nil {
+		h.respondError(c, err, "Erro ao excluir documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Documento excluído",
+		Timestamp: time.Now(),
+	})
+}
+
+// URL godoc
+// @Summary Gerar URL de download
+// @Description URL pré-assinada e temporária para baixar a versão (ou a miniatura) diretamente do armazenamento
+// @Tags Documentos
+// @Produce json
+// @Param id path int true "ID do documento"
+// @Param versao query int false "Número da versão (padrão: atual)"
+// @Param thumbnail query bool false "Miniatura da imagem"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /documentos/{id}/url [get]
+// @Security BearerAuth
+func (h *Handler) URL(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	versao, thumbnail := versionQuery(c)
+
+	url, err := h.service.PresignURL(c.Request.Context(), id, userID, userType, versao, thumbnail)
+	if err != nil {
+		h.respondError(c, err, "Erro ao gerar URL do documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "URL de download gerada",
+		Timestamp: time.Now(),
+		Data:      url,
+	})
+}
+
+// Content godoc
+// @Summary Baixar conteúdo do documento
+// @Description Conteúdo da versão (ou da miniatura) servido pela API; endereço estável usado como foto de perfil
+// @Tags Documentos
+// @Produce octet-stream
+// @Param id path int true "ID do documento"
+// @Param versao query int false "Número da versão (padrão: atual)"
+// @Param thumbnail query bool false "Miniatura da imagem"
+// @Success 200 {file} file
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /documentos/{id}/conteudo [get]
+// @Security BearerAuth
+func (h *Handler) Content(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	versao, thumbnail := versionQuery(c)
+
+	reader, contentType, filename, err := h.service.OpenContent(c.Request.Context(), id, userID, userType, versao, thumbnail)
+	if err != nil {
+		h.respondError(c, err, "Erro ao baixar documento")
+		return
+	}
+	defer reader.Close()
+
+	h.stream(c, reader, contentType, filename)
+}
+
+// DownloadSigned godoc
+// @Summary Baixar arquivo por URL assinada
+// @Description Download sem autenticação das URLs assinadas do armazenamento local
+// @Tags Documentos
+// @Produce octet-stream
+// @Param key query string true "Chave do objeto"
+// @Param expires query int true "Validade (Unix)"
+// @Param filename query string false "Nome do arquivo"
+// @Param signature query string true "Assinatura HMAC"
+// @Success 200 {file} file
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /public/documentos/arquivo [get]
+func (h *Handler) DownloadSigned(c *gin.Context) {
+	key := c.Query("key")
+	filename := c.Query("filename")
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil || key == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "URL de download inválida",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	reader, err := h.service.OpenSigned(c.Request.Context(), key, expires, filename, c.Query("signature"))
+	if err != nil {
+		if apperrors.IsAuthorization(err) {
+			h.logger.LogSecurityEvent("document_signed_url_rejected", "URL assinada de documento inválida ou expirada", 0, c.ClientIP())
+		}
+		h.respondError(c, err, "Erro ao baixar documento")
+		return
+	}
+	defer reader.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if filename == "" {
+		filename = path.Base(key)
+	}
+	h.stream(c, reader, contentType, filename)
+}
+
+func (h *Handler) stream(c *gin.Context, reader io.Reader, contentType, filename string) {
+	c.DataFromReader(http.StatusOK, -1, contentType, reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": filename}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=300",
+	})
+}
+
+// readFile lê o campo multipart "arquivo" respeitando o limite de tamanho de upload
+func (h *Handler) readFile(c *gin.Context) (*models.ArquivoEnviado, bool) {
+	header, err := c.FormFile("arquivo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Arquivo não enviado ou maior que o limite permitido",
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+	if header.Size > h.maxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Arquivo excede o limite de %d bytes", h.maxUploadSize),
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.respondError(c, err, "Erro ao ler arquivo enviado")
+		return nil, false
+	}
+	defer file.Close()
+
+	conteudo, err := io.ReadAll(io.LimitReader(file, h.maxUploadSize+1))
+	if err != nil {
+		h.respondError(c, err, "Erro ao ler arquivo enviado")
+		return nil, false
+	}
+	return &models.ArquivoEnviado{Nome: header.Filename, Conteudo: conteudo}, true
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, string, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, "", false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	return userID, userType, true
+}
+
+func (h *Handler) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func versionQuery(c *gin.Context) (int, bool) {
+	versao, _ := strconv.Atoi(c.Query("versao"))
+	thumbnail, _ := strconv.ParseBool(c.Query("thumbnail"))
+	return versao, thumbnail
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	case apperrors.IsBusiness(err):
+		status = http.StatusServiceUnavailable
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
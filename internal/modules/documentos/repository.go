@@ -0,0 +1,118 @@
+package documentos
+
+import (
+	"context"
+	"errors"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	FindByID(ctx context.Context, id uint) (*models.Documento, error)
+	FindByEquinoID(ctx context.Context, equinoID uint, filters map[string]interface{}) ([]*models.Documento, error)
+	Create(ctx context.Context, documento *models.Documento, versao *models.DocumentoVersao) error
+	AddVersao(ctx context.Context, documento *models.Documento, versao *models.DocumentoVersao) error
+	Delete(ctx context.Context, id uint) error
+	UpdateFotoPerfil(ctx context.Context, equinoID uint, url string) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByID(ctx context.Context, id uint) (*models.Documento, error) {
+	var documento models.Documento
+	err := r.db.WithContext(ctx).
+		Preload("Versoes", func(db *gorm.DB) *gorm.DB { return db.Order("numero DESC") }).
+		First(&documento, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "documento", Message: "documento não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_documento", "erro ao buscar documento", err)
+	}
+	return &documento, nil
+}
+
+func (r *repository) FindByEquinoID(ctx context.Context, equinoID uint, filters map[string]interface{}) ([]*models.Documento, error) {
+	query := r.db.WithContext(ctx).
+		Preload("Versoes", func(db *gorm.DB) *gorm.DB { return db.Order("numero DESC") }).
+		Where("equino_id = ?", equinoID)
+
+	if categoria, ok := filters["categoria"]; ok {
+		query = query.Where("categoria = ?", categoria)
+	}
+	if entidadeTipo, ok := filters["entidade_tipo"]; ok {
+		query = query.Where("entidade_tipo = ?", entidadeTipo)
+	}
+	if entidadeID, ok := filters["entidade_id"]; ok {
+		query = query.Where("entidade_id = ?", entidadeID)
+	}
+	if visibilidade, ok := filters["visibilidade"]; ok {
+		query = query.Where("visibilidade = ?", visibilidade)
+	}
+
+	var documentos []*models.Documento
+	if err := query.Order("created_at DESC").Find(&documentos).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("find_documentos_equino", "erro ao listar documentos do equino", err)
+	}
+	return documentos, nil
+}
+
+func (r *repository) Create(ctx context.Context, documento *models.Documento, versao *models.DocumentoVersao) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Versoes").Create(documento).Error; err != nil {
+			return apperrors.NewDatabaseError("create_documento", "erro ao criar documento", err)
+		}
+		versao.DocumentoID = documento.ID
+		if err := tx.Create(versao).Error; err != nil {
+			return apperrors.NewDatabaseError("create_documento_versao", "erro ao criar versão do documento", err)
+		}
+		return nil
+	})
+}
+
+// AddVersao grava a nova versão somente se nenhuma outra foi registrada desde a leitura do documento
+func (r *repository) AddVersao(ctx context.Context, documento *models.Documento, versao *models.DocumentoVersao) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Documento{}).
+			Where("id = ? AND versao_atual = ?", documento.ID, versao.Numero-1).
+			Update("versao_atual", versao.Numero)
+		if result.Error != nil {
+			return apperrors.NewDatabaseError("update_documento_versao", "erro ao atualizar versão do documento", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return &apperrors.ConflictError{Resource: "documento", Message: "outra versão foi enviada ao mesmo tempo", Value: documento.ID}
+		}
+
+		versao.DocumentoID = documento.ID
+		if err := tx.Create(versao).Error; err != nil {
+			return apperrors.NewDatabaseError("create_documento_versao", "erro ao criar versão do documento", err)
+		}
+		return nil
+	})
+}
+
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Documento{}, id)
+	if result.Error != nil {
+		return apperrors.NewDatabaseError("delete_documento", "erro ao excluir documento", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.NotFoundError{Resource: "documento", Message: "documento não encontrado", ID: id}
+	}
+	return nil
+}
+
+func (r *repository) UpdateFotoPerfil(ctx context.Context, equinoID uint, url string) error {
+	if err := r.db.WithContext(ctx).Model(&models.Equino{}).Where("id = ?", equinoID).Update("foto_perfil", url).Error; err != nil {
+		return apperrors.NewDatabaseError("update_foto_perfil", "erro ao atualizar foto de perfil do equino", err)
+	}
+	return nil
+}
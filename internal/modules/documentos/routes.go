@@ -0,0 +1,27 @@
+package documentos
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	equinos := rg.Group("/equinos")
+	equinos.Use(authMiddleware)
+	{
+		equinos.GET("/:equinoid/documentos", handler.ListByEquino)
+		equinos.POST("/:equinoid/documentos", handler.Upload)
+	}
+
+	documentos := rg.Group("/documentos")
+	documentos.Use(authMiddleware)
+	{
+		documentos.GET("/:id", handler.GetByID)
+		documentos.DELETE("/:id", handler.Delete)
+		documentos.POST("/:id/versoes", handler.AddVersao)
+		documentos.GET("/:id/url", handler.URL)
+		documentos.GET("/:id/conteudo", handler.Content)
+	}
+
+	// URLs assinadas do armazenamento local: a assinatura substitui a autenticação
+	rg.GET("/public/documentos/arquivo", handler.DownloadSigned)
+}
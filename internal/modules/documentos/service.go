@@ -0,0 +1,510 @@
+package documentos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/equinoid/backend/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// allowedMimeTypes tipos aceitos no cofre, identificados pelo conteúdo do arquivo e não pela extensão informada
+var allowedMimeTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+}
+
+// EquinoRepository busca o equino dono do cofre
+type EquinoRepository interface {
+	FindByEquinoid(ctx context.Context, equinoid string) (*models.Equino, error)
+}
+
+// AccessChecker decide se um usuário pode exercer um escopo sobre um equino (proprietário, admin ou acesso delegado)
+type AccessChecker interface {
+	CheckEquinoAccess(ctx context.Context, userID uint, userType string, equinoid string, escopo string) error
+}
+
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+type Service interface {
+	Upload(ctx context.Context, equinoid string, userID uint, userType string, req *models.UploadDocumentoRequest, arquivo *models.ArquivoEnviado) (*models.Documento, error)
+	ListByEquino(ctx context.Context, equinoid string, userID uint, userType string, filters map[string]interface{}) ([]*models.Documento, error)
+	GetByID(ctx context.Context, id uint, userID uint, userType string) (*models.Documento, error)
+	AddVersao(ctx context.Context, id uint, userID uint, userType string, arquivo *models.ArquivoEnviado) (*models.Documento, error)
+	Delete(ctx context.Context, id uint, userID uint, userType string) error
+
+	PresignURL(ctx context.Context, id uint, userID uint, userType string, versao int, thumbnail bool) (*models.DocumentoURLResponse, error)
+	OpenContent(ctx context.Context, id uint, userID uint, userType string, versao int, thumbnail bool) (io.ReadCloser, string, string, error)
+	OpenSigned(ctx context.Context, key string, expires int64, filename, signature string) (io.ReadCloser, error)
+}
+
+type service struct {
+	repo          Repository
+	equinoRepo    EquinoRepository
+	accessChecker AccessChecker
+	store         storage.Storage
+	audit         AuditLogger
+	maxSize       int64
+	urlTTL        time.Duration
+	logger        *logging.Logger
+}
+
+// NewService cria o serviço do cofre; sem armazenamento configurado as operações com arquivos respondem como
+// indisponíveis
+func NewService(repo Repository, equinoRepo EquinoRepository, accessChecker AccessChecker, store storage.Storage, audit AuditLogger, cfg *config.Config, logger *logging.Logger) Service {
+	return &service{
+		repo:          repo,
+		equinoRepo:    equinoRepo,
+		accessChecker: accessChecker,
+		store:         store,
+		audit:         audit,
+		maxSize:       cfg.UploadMaxSize,
+		urlTTL:        cfg.DocumentURLTTL,
+		logger:        logger,
+	}
+}
+
+func (s *service) Upload(ctx context.Context, equinoid string, userID uint, userType string, req *models.UploadDocumentoRequest, arquivo *models.ArquivoEnviado) (*models.Documento, error) {
+	if err := s.storageAvailable(); err != nil {
+		return nil, err
+	}
+	if !models.IsValidCategoriaDocumento(req.Categoria) {
+		return nil, &apperrors.ValidationError{Field: "categoria", Message: "categoria de documento inválida", Value: req.Categoria}
+	}
+	if req.EntidadeTipo != nil && *req.EntidadeTipo != "" {
+		if !models.IsValidEntidadeDocumento(*req.EntidadeTipo) || req.EntidadeID == nil {
+			return nil, &apperrors.ValidationError{Field: "entidade_tipo", Message: "vínculo exige entidade_tipo (evento, cobertura ou exame) e entidade_id", Value: *req.EntidadeTipo}
+		}
+	} else {
+		req.EntidadeTipo, req.EntidadeID = nil, nil
+	}
+
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	owner := isOwner(equino, userID, userType)
+	if err := s.checkWrite(ctx, equino, owner, userID, userType, req.Categoria); err != nil {
+		return nil, err
+	}
+
+	visibilidade := req.Visibilidade
+	switch {
+	case !owner:
+		// Profissionais com acesso delegado não publicam documentos do equino
+		visibilidade = models.VisibilidadePrivado
+	case visibilidade == "" && req.Categoria.IsFoto():
+		visibilidade = models.VisibilidadePublico
+	case visibilidade == "":
+		visibilidade = models.VisibilidadePrivado
+	case visibilidade != models.VisibilidadePrivado && visibilidade != models.VisibilidadePublico:
+		return nil, &apperrors.ValidationError{Field: "visibilidade", Message: "visibilidade deve ser privado ou publico", Value: visibilidade}
+	}
+
+	nome := sanitizeFilename(arquivo.Nome)
+	titulo := strings.TrimSpace(req.Titulo)
+	if titulo == "" {
+		titulo = nome
+	}
+
+	documento := &models.Documento{
+		UUID:         uuid.New().String(),
+		EquinoID:     equino.ID,
+		Equinoid:     equino.Equinoid,
+		Categoria:    req.Categoria,
+		Titulo:       titulo,
+		Descricao:    req.Descricao,
+		Visibilidade: visibilidade,
+		EntidadeTipo: req.EntidadeTipo,
+		EntidadeID:   req.EntidadeID,
+		VersaoAtual:  1,
+		CriadoPor:    userID,
+	}
+
+	versao, err := s.storeVersao(ctx, documento, 1, nome, arquivo.Conteudo, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, documento, versao); err != nil {
+		s.logger.LogError(err, "DocumentoService.Upload", logging.Fields{"equinoid": equinoid})
+		s.discardObjects(ctx, versao)
+		return nil, err
+	}
+
+	if documento.Categoria == models.CategoriaFotoPerfil {
+		if err := s.repo.UpdateFotoPerfil(ctx, equino.ID, contentPath(documento.ID)); err != nil {
+			s.logger.LogError(err, "DocumentoService.Upload", logging.Fields{"equinoid": equinoid, "action": "foto_perfil"})
+		}
+	}
+
+	s.recordChange(ctx, documento.UUID, "create", nil, versao)
+	s.logger.WithFields(logging.Fields{
+		"documento_id": documento.ID,
+		"equinoid":     equinoid,
+		"categoria":    documento.Categoria,
+		"mime_type":    versao.MimeType,
+	}).Info("Documento enviado ao cofre")
+
+	return s.repo.FindByID(ctx, documento.ID)
+}
+
+func (s *service) ListByEquino(ctx context.Context, equinoid string, userID uint, userType string, filters map[string]interface{}) ([]*models.Documento, error) {
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sem acesso ao prontuário, o usuário vê apenas os documentos públicos
+	if err := s.accessChecker.CheckEquinoAccess(ctx, userID, userType, equinoid, models.EscopoLerSaude); err != nil {
+		if !apperrors.IsAuthorization(err) {
+			return nil, err
+		}
+		filters["visibilidade"] = models.VisibilidadePublico
+	}
+
+	documentos, err := s.repo.FindByEquinoID(ctx, equino.ID, filters)
+	if err != nil {
+		s.logger.LogError(err, "DocumentoService.ListByEquino", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+	return documentos, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id uint, userID uint, userType string) (*models.Documento, error) {
+	documento, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRead(ctx, documento, userID, userType); err != nil {
+		return nil, err
+	}
+	return documento, nil
+}
+
+func (s *service) AddVersao(ctx context.Context, id uint, userID uint, userType string, arquivo *models.ArquivoEnviado) (*models.Documento, error) {
+	if err := s.storageAvailable(); err != nil {
+		return nil, err
+	}
+	documento, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, documento.Equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWrite(ctx, equino, isOwner(equino, userID, userType), userID, userType, documento.Categoria); err != nil {
+		return nil, err
+	}
+
+	atual := currentVersao(documento, 0)
+	digest := sha256.Sum256(arquivo.Conteudo)
+	if atual != nil && atual.Hash == hex.EncodeToString(digest[:]) {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: "arquivo idêntico à versão atual", Value: atual.Hash}
+	}
+
+	numero := documento.VersaoAtual + 1
+	versao, err := s.storeVersao(ctx, documento, numero, sanitizeFilename(arquivo.Nome), arquivo.Conteudo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddVersao(ctx, documento, versao); err != nil {
+		if !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "DocumentoService.AddVersao", logging.Fields{"documento_id": id})
+		}
+		s.discardObjects(ctx, versao)
+		return nil, err
+	}
+
+	s.recordChange(ctx, documento.UUID, "new_version", atual, versao)
+	s.logger.WithFields(logging.Fields{"documento_id": id, "versao": numero}).Info("Nova versão de documento enviada")
+
+	return s.repo.FindByID(ctx, id)
+}
+
+// Delete remove o documento da listagem; os arquivos das versões são preservados no armazenamento para auditoria
+func (s *service) Delete(ctx context.Context, id uint, userID uint, userType string) error {
+	documento, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, documento.Equinoid)
+	if err != nil {
+		return err
+	}
+	if !isOwner(equino, userID, userType) && documento.CriadoPor != userID {
+		return (&apperrors.AuthorizationError{Message: "apenas o proprietário ou quem enviou pode excluir o documento"}).WithAction("excluir", "documento")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.LogError(err, "DocumentoService.Delete", logging.Fields{"documento_id": id})
+		return err
+	}
+	if documento.Categoria == models.CategoriaFotoPerfil && equino.FotoPerfil == contentPath(documento.ID) {
+		if err := s.repo.UpdateFotoPerfil(ctx, equino.ID, ""); err != nil {
+			s.logger.LogError(err, "DocumentoService.Delete", logging.Fields{"documento_id": id, "action": "foto_perfil"})
+		}
+	}
+
+	s.recordChange(ctx, documento.UUID, "delete", documento, nil)
+	return nil
+}
+
+func (s *service) PresignURL(ctx context.Context, id uint, userID uint, userType string, numero int, thumbnail bool) (*models.DocumentoURLResponse, error) {
+	if err := s.storageAvailable(); err != nil {
+		return nil, err
+	}
+	documento, versao, key, err := s.resolveObject(ctx, id, userID, userType, numero, thumbnail)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := versao.NomeArquivo
+	if thumbnail {
+		filename = strings.TrimSuffix(filename, filepath.Ext(filename)) + "-miniatura.jpg"
+	}
+	url, err := s.store.PresignGet(ctx, key, s.urlTTL, filename)
+	if err != nil {
+		s.logger.LogError(err, "DocumentoService.PresignURL", logging.Fields{"documento_id": documento.ID, "versao": versao.Numero})
+		return nil, apperrors.NewBusinessError("storage_unavailable", "não foi possível gerar a URL de download", nil)
+	}
+
+	return &models.DocumentoURLResponse{
+		URL:       url,
+		Versao:    versao.Numero,
+		Thumbnail: thumbnail,
+		ExpiraEm:  time.Now().Add(s.urlTTL),
+	}, nil
+}
+
+// OpenContent conteúdo de uma versão pela própria API, com o tipo MIME e o nome do arquivo
+func (s *service) OpenContent(ctx context.Context, id uint, userID uint, userType string, numero int, thumbnail bool) (io.ReadCloser, string, string, error) {
+	if err := s.storageAvailable(); err != nil {
+		return nil, "", "", err
+	}
+	_, versao, key, err := s.resolveObject(ctx, id, userID, userType, numero, thumbnail)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	reader, err := s.open(ctx, key)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if thumbnail {
+		return reader, "image/jpeg", versao.NomeArquivo, nil
+	}
+	return reader, versao.MimeType, versao.NomeArquivo, nil
+}
+
+// OpenSigned conteúdo de uma URL assinada do armazenamento local, servida sem autenticação
+func (s *service) OpenSigned(ctx context.Context, key string, expires int64, filename, signature string) (io.ReadCloser, error) {
+	verifier, ok := s.store.(storage.SignedURLVerifier)
+	if !ok {
+		return nil, &apperrors.NotFoundError{Resource: "arquivo", Message: "download assinado não disponível neste armazenamento"}
+	}
+	if err := verifier.VerifySignedURL(key, expires, filename, signature); err != nil {
+		return nil, &apperrors.AuthorizationError{Message: "URL de download inválida ou expirada"}
+	}
+	return s.open(ctx, key)
+}
+
+// resolveObject confere a leitura e devolve a chave da versão pedida (0 = atual) ou da sua miniatura
+func (s *service) resolveObject(ctx context.Context, id uint, userID uint, userType string, numero int, thumbnail bool) (*models.Documento, *models.DocumentoVersao, string, error) {
+	documento, err := s.GetByID(ctx, id, userID, userType)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	versao := currentVersao(documento, numero)
+	if versao == nil {
+		return nil, nil, "", &apperrors.NotFoundError{Resource: "versao", Message: "versão do documento não encontrada", ID: numero}
+	}
+	if !thumbnail {
+		return documento, versao, versao.StorageKey, nil
+	}
+	if versao.ThumbnailKey == nil {
+		return nil, nil, "", &apperrors.NotFoundError{Resource: "miniatura", Message: "versão do documento não possui miniatura", ID: versao.Numero}
+	}
+	return documento, versao, *versao.ThumbnailKey, nil
+}
+
+func (s *service) open(ctx context.Context, key string) (io.ReadCloser, error) {
+	reader, err := s.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "arquivo", Message: "arquivo não encontrado no armazenamento"}
+		}
+		s.logger.LogError(err, "DocumentoService.open", logging.Fields{"backend": s.store.Name()})
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos indisponível", nil)
+	}
+	return reader, nil
+}
+
+// storeVersao valida o conteúdo, grava o arquivo e a miniatura e devolve a versão ainda não persistida
+func (s *service) storeVersao(ctx context.Context, documento *models.Documento, numero int, nome string, conteudo []byte, userID uint) (*models.DocumentoVersao, error) {
+	if len(conteudo) == 0 {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: "arquivo vazio"}
+	}
+	if s.maxSize > 0 && int64(len(conteudo)) > s.maxSize {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: fmt.Sprintf("arquivo excede o limite de %d bytes", s.maxSize), Value: len(conteudo)}
+	}
+
+	mimeType := http.DetectContentType(conteudo)
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	ext, allowed := allowedMimeTypes[mimeType]
+	if !allowed {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: "tipo de arquivo não permitido (aceitos: PDF, JPEG, PNG, GIF e WebP)", Value: mimeType}
+	}
+	if documento.Categoria.IsFoto() && !strings.HasPrefix(mimeType, "image/") {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: "fotos devem ser imagens", Value: mimeType}
+	}
+
+	digest := sha256.Sum256(conteudo)
+	hash := hex.EncodeToString(digest[:])
+	prefix := fmt.Sprintf("equinos/%s/documentos/%s/v%d", documento.Equinoid, documento.UUID, numero)
+
+	versao := &models.DocumentoVersao{
+		Numero:         numero,
+		NomeArquivo:    strings.TrimSuffix(nome, filepath.Ext(nome)) + ext,
+		MimeType:       mimeType,
+		Tamanho:        int64(len(conteudo)),
+		Hash:           hash,
+		StorageBackend: s.store.Name(),
+		StorageKey:     prefix + "/" + hash + ext,
+		EnviadoPor:     userID,
+	}
+
+	if err := s.store.Put(ctx, versao.StorageKey, conteudo, mimeType); err != nil {
+		s.logger.LogError(err, "DocumentoService.storeVersao", logging.Fields{"backend": s.store.Name(), "documento": documento.UUID})
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos indisponível", nil)
+	}
+
+	if thumbnailMimeTypes[mimeType] {
+		thumb, err := generateThumbnail(conteudo)
+		if err != nil {
+			s.logger.Warnf("Miniatura não gerada para o documento %s: %v", documento.UUID, err)
+			return versao, nil
+		}
+		thumbKey := prefix + "/miniatura.jpg"
+		if err := s.store.Put(ctx, thumbKey, thumb, "image/jpeg"); err != nil {
+			s.logger.LogError(err, "DocumentoService.storeVersao", logging.Fields{"backend": s.store.Name(), "action": "thumbnail"})
+			return versao, nil
+		}
+		versao.ThumbnailKey = &thumbKey
+	}
+
+	return versao, nil
+}
+
+// discardObjects remove arquivos gravados para uma versão que não chegou a ser registrada
+func (s *service) discardObjects(ctx context.Context, versao *models.DocumentoVersao) {
+	keys := []string{versao.StorageKey}
+	if versao.ThumbnailKey != nil {
+		keys = append(keys, *versao.ThumbnailKey)
+	}
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			s.logger.LogError(err, "DocumentoService.discardObjects", logging.Fields{"key": key})
+		}
+	}
+}
+
+// checkRead documentos públicos para qualquer usuário autenticado; privados exigem acesso de leitura ao prontuário
+func (s *service) checkRead(ctx context.Context, documento *models.Documento, userID uint, userType string) error {
+	if documento.Visibilidade == models.VisibilidadePublico {
+		return nil
+	}
+	return s.accessChecker.CheckEquinoAccess(ctx, userID, userType, documento.Equinoid, models.EscopoLerSaude)
+}
+
+// checkWrite proprietário e admin enviam qualquer categoria; profissionais com acesso delegado de escrita enviam
+// apenas documentos clínicos (laudos, eventos, coberturas e outros)
+func (s *service) checkWrite(ctx context.Context, equino *models.Equino, owner bool, userID uint, userType string, categoria models.CategoriaDocumento) error {
+	if owner {
+		return nil
+	}
+	switch categoria {
+	case models.CategoriaLaudo, models.CategoriaEvento, models.CategoriaCobertura, models.CategoriaOutro:
+		return s.accessChecker.CheckEquinoAccess(ctx, userID, userType, equino.Equinoid, models.EscopoEscreverExames)
+	}
+	return (&apperrors.AuthorizationError{Message: "apenas o proprietário pode enviar documentos desta categoria"}).WithAction("enviar_documento", "equino")
+}
+
+func (s *service) storageAvailable() error {
+	if s.store == nil {
+		return apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos não configurado", nil)
+	}
+	return nil
+}
+
+func (s *service) recordChange(ctx context.Context, key, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, "documento", key, operation, before, after); err != nil {
+		s.logger.LogError(err, "DocumentoService.recordChange", logging.Fields{"documento": key, "operation": operation})
+	}
+}
+
+func isOwner(equino *models.Equino, userID uint, userType string) bool {
+	return userType == string(models.UserTypeAdmin) || equino.ProprietarioID == userID
+}
+
+// currentVersao versão pedida ou, com numero 0, a versão atual
+func currentVersao(documento *models.Documento, numero int) *models.DocumentoVersao {
+	if numero == 0 {
+		numero = documento.VersaoAtual
+	}
+	for i := range documento.Versoes {
+		if documento.Versoes[i].Numero == numero {
+			return &documento.Versoes[i]
+		}
+	}
+	return nil
+}
+
+// contentPath endereço estável do conteúdo atual do documento na API, usado como foto de perfil do equino
+func contentPath(id uint) string {
+	return fmt.Sprintf("/api/v1/documentos/%d/conteudo", id)
+}
+
+// sanitizeFilename nome do arquivo sem diretórios nem caracteres de controle, limitado a 200 caracteres
+func sanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' || r == '/' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." {
+		name = "documento"
+	}
+	if runes := []rune(name); len(runes) > 200 {
+		name = string(runes[len(runes)-200:])
+	}
+	return name
+}
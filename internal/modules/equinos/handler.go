@@ -374,3 +374,183 @@ func (h *Handler) TransferOwnership(c *gin.Context) {
 		Data:      nil,
 	})
 }
+
+// FindByIdentificador godoc
+// @Summary Buscar equino por identificador externo
+// @Description Localiza o equino pelo UELN, pelo número de registro na associação de raça (com o emissor) ou pelo alias EquinoId v2
+// @Tags Equinos
+// @Produce json
+// @Param tipo query string true "Tipo do identificador (ueln, registro_raca, equinoid_v2)"
+// @Param valor query string true "Valor do identificador"
+// @Param emissor query string false "Associação emissora (obrigatório para registro_raca)"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /equinos/identificador [get]
+// @Security BearerAuth
+func (h *Handler) FindByIdentificador(c *gin.Context) {
+	tipo := models.TipoIdentificador(c.Query("tipo"))
+	equino, err := h.service.FindByIdentificador(c.Request.Context(), tipo, c.Query("emissor"), c.Query("valor"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar equino por identificador")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Equino encontrado",
+		Timestamp: time.Now(),
+		Data:      equino,
+	})
+}
+
+// ListIdentificadores godoc
+// @Summary Listar identificadores do equino
+// @Description Alias EquinoId v2 (equinos registrados antes da v2), UELN e números de registro em associações de raça
+// @Tags Equinos
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/identificadores [get]
+// @Security BearerAuth
+func (h *Handler) ListIdentificadores(c *gin.Context) {
+	identificadores, err := h.service.ListIdentificadores(c.Request.Context(), c.Param("equinoid"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar identificadores")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Identificadores do equino (total: %d)", len(identificadores)),
+		Timestamp: time.Now(),
+		Data:      identificadores,
+	})
+}
+
+// AddIdentificador godoc
+// @Summary Cadastrar identificador externo
+// @Description Vincula um UELN ou número de registro de associação de raça ao equino; cada identificador pertence a um único equino
+// @Tags Equinos
+// @Accept json
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param request body models.CreateIdentificadorRequest true "Identificador"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/identificadores [post]
+// @Security BearerAuth
+func (h *Handler) AddIdentificador(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateIdentificadorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	identificador, err := h.service.AddIdentificador(c.Request.Context(), c.Param("equinoid"), userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao cadastrar identificador")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Identificador cadastrado",
+		Timestamp: time.Now(),
+		Data:      identificador,
+	})
+}
+
+// RemoveIdentificador godoc
+// @Summary Remover identificador externo
+// @Description Remove um UELN ou número de registro do equino; o alias EquinoId v2 não pode ser removido
+// @Tags Equinos
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param id path int true "ID do identificador"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/identificadores/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) RemoveIdentificador(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if err := h.service.RemoveIdentificador(c.Request.Context(), c.Param("equinoid"), uint(id), userID, userType); err != nil {
+		h.respondError(c, err, "Erro ao remover identificador")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Identificador removido",
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, string, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, "", false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	return userID, userType, true
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
@@ -3,8 +3,10 @@ package equinos
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/utils"
 	apperrors "github.com/equinoid/backend/pkg/errors"
 	"gorm.io/gorm"
 )
@@ -12,6 +14,7 @@ import (
 type Repository interface {
 	FindByID(ctx context.Context, id uint) (*models.Equino, error)
 	FindByEquinoid(ctx context.Context, equinoid string) (*models.Equino, error)
+	FindByIdentificador(ctx context.Context, tipo models.TipoIdentificador, emissor, valor string) (*models.Equino, error)
 	FindByMicrochipID(ctx context.Context, microchipID string) (*models.Equino, error)
 	FindByProprietarioID(ctx context.Context, proprietarioID uint) ([]*models.Equino, error)
 	FindByGenitor(ctx context.Context, genitorEquinoid string) ([]*models.Equino, error)
@@ -24,6 +27,10 @@ type Repository interface {
 	ExistsByEquinoid(ctx context.Context, equinoid string) (bool, error)
 	ExistsByMicrochipID(ctx context.Context, microchipID string) (bool, error)
 	TransferOwnership(ctx context.Context, equinoid string, newOwnerID uint) error
+
+	ListIdentificadores(ctx context.Context, equinoID uint) ([]*models.EquinoIdentificador, error)
+	CreateIdentificador(ctx context.Context, identificador *models.EquinoIdentificador) error
+	DeleteIdentificador(ctx context.Context, equinoID, id uint) error
 }
 
 type repository struct {
@@ -45,9 +52,18 @@ func (r *repository) FindByID(ctx context.Context, id uint) (*models.Equino, err
 	return &equino, nil
 }
 
+// FindByEquinoid busca pelo EquinoId cadastrado (v1, v2 ou legado) e, em seguida, pelo alias v2 dos equinos
+// registrados antes da v2
 func (r *repository) FindByEquinoid(ctx context.Context, equinoid string) (*models.Equino, error) {
 	var equino models.Equino
-	if err := r.db.WithContext(ctx).Where("equinoid = ?", equinoid).First(&equino).Error; err != nil {
+	err := r.db.WithContext(ctx).Where("equinoid = ?", equinoid).First(&equino).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = r.db.WithContext(ctx).
+			Where("id = (?)", r.db.Model(&models.EquinoIdentificador{}).Select("equino_id").
+				Where("tipo = ? AND emissor = '' AND valor = ?", models.IdentificadorEquinoidV2, utils.NormalizeEquinoId(equinoid)).Limit(1)).
+			First(&equino).Error
+	}
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "equino não encontrado", ID: equinoid}
 		}
@@ -56,6 +72,61 @@ func (r *repository) FindByEquinoid(ctx context.Context, equinoid string) (*mode
 	return &equino, nil
 }
 
+// FindByIdentificador busca pelo identificador alternativo (UELN, registro de raça ou alias v2)
+func (r *repository) FindByIdentificador(ctx context.Context, tipo models.TipoIdentificador, emissor, valor string) (*models.Equino, error) {
+	var equino models.Equino
+	err := r.db.WithContext(ctx).
+		Joins("JOIN equino_identificadores ON equino_identificadores.equino_id = equinos.id").
+		Where("equino_identificadores.tipo = ? AND equino_identificadores.emissor = ? AND equino_identificadores.valor = ?", tipo, emissor, valor).
+		First(&equino).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "nenhum equino com este identificador", ID: valor}
+		}
+		return nil, apperrors.NewDatabaseError("find_by_identificador", "erro ao buscar equino por identificador", err)
+	}
+	return &equino, nil
+}
+
+func (r *repository) ListIdentificadores(ctx context.Context, equinoID uint) ([]*models.EquinoIdentificador, error) {
+	var identificadores []*models.EquinoIdentificador
+	if err := r.db.WithContext(ctx).Where("equino_id = ?", equinoID).Order("tipo, created_at").Find(&identificadores).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("list_identificadores", "erro ao listar identificadores do equino", err)
+	}
+	return identificadores, nil
+}
+
+func (r *repository) CreateIdentificador(ctx context.Context, identificador *models.EquinoIdentificador) error {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.EquinoIdentificador{}).
+		Where("tipo = ? AND emissor = ? AND valor = ?", identificador.Tipo, identificador.Emissor, identificador.Valor).
+		Count(&count).Error
+	if err != nil {
+		return apperrors.NewDatabaseError("exists_identificador", "erro ao verificar identificador", err)
+	}
+	if count > 0 {
+		return &apperrors.ConflictError{Resource: "identificador", Message: "identificador já cadastrado para um equino", Value: identificador.Valor}
+	}
+	if err := r.db.WithContext(ctx).Create(identificador).Error; err != nil {
+		return apperrors.NewDatabaseError("create_identificador", "erro ao cadastrar identificador", err)
+	}
+	return nil
+}
+
+// DeleteIdentificador remove um identificador externo do equino; aliases v2 não são removíveis
+func (r *repository) DeleteIdentificador(ctx context.Context, equinoID, id uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND equino_id = ? AND tipo <> ?", id, equinoID, models.IdentificadorEquinoidV2).
+		Delete(&models.EquinoIdentificador{})
+	if result.Error != nil {
+		return apperrors.NewDatabaseError("delete_identificador", "erro ao remover identificador", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.NotFoundError{Resource: "identificador", Message: "identificador não encontrado", ID: id}
+	}
+	return nil
+}
+
 func (r *repository) FindByMicrochipID(ctx context.Context, microchipID string) (*models.Equino, error) {
 	var equino models.Equino
 	if err := r.db.WithContext(ctx).Where("microchip_id = ?", microchipID).First(&equino).Error; err != nil {
@@ -77,7 +148,7 @@ func (r *repository) FindByProprietarioID(ctx context.Context, proprietarioID ui
 
 func (r *repository) FindByGenitor(ctx context.Context, genitorEquinoid string) ([]*models.Equino, error) {
 	var equinos []*models.Equino
-	if err := r.db.WithContext(ctx).Where("genitor_equinoid = ?", genitorEquinoid).Find(&equinos).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("genitor = ?", genitorEquinoid).Find(&equinos).Error; err != nil {
 		return nil, apperrors.NewDatabaseError("find_by_genitor", "erro ao buscar descendentes do genitor", err)
 	}
 	return equinos, nil
@@ -85,7 +156,7 @@ func (r *repository) FindByGenitor(ctx context.Context, genitorEquinoid string)
 
 func (r *repository) FindByGenitora(ctx context.Context, genitoraEquinoid string) ([]*models.Equino, error) {
 	var equinos []*models.Equino
-	if err := r.db.WithContext(ctx).Where("genitora_equinoid = ?", genitoraEquinoid).Find(&equinos).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("genitora = ?", genitoraEquinoid).Find(&equinos).Error; err != nil {
 		return nil, apperrors.NewDatabaseError("find_by_genitora", "erro ao buscar descendentes da genitora", err)
 	}
 	return equinos, nil
@@ -131,6 +202,15 @@ func (r *repository) List(ctx context.Context, page, limit int, filters map[stri
 	if raca, ok := filters["raca"].(string); ok && raca != "" {
 		query = query.Where("raca ILIKE ?", "%"+raca+"%")
 	}
+	if sexo, ok := filters["sexo"].(string); ok && sexo != "" {
+		query = query.Where("sexo = ?", sexo)
+	}
+	if nascidoAte, ok := filters["nascido_ate"].(time.Time); ok {
+		query = query.Where("data_nascimento <= ?", nascidoAte)
+	}
+	if nascidoApos, ok := filters["nascido_apos"].(time.Time); ok {
+		query = query.Where("data_nascimento > ?", nascidoApos)
+	}
 	if proprietarioID, ok := filters["proprietario_id"].(uint); ok {
 		query = query.Where("proprietario_id = ?", proprietarioID)
 	}
@@ -170,6 +250,16 @@ func (r *repository) ExistsByEquinoid(ctx context.Context, equinoid string) (boo
 	if err := r.db.WithContext(ctx).Model(&models.Equino{}).Where("equinoid = ?", equinoid).Count(&count).Error; err != nil {
 		return false, apperrors.NewDatabaseError("exists_by_equinoid", "erro ao verificar existência de equinoid", err)
 	}
+	if count > 0 {
+		return true, nil
+	}
+
+	err := r.db.WithContext(ctx).Model(&models.EquinoIdentificador{}).
+		Where("tipo = ? AND emissor = '' AND valor = ?", models.IdentificadorEquinoidV2, equinoid).
+		Count(&count).Error
+	if err != nil {
+		return false, apperrors.NewDatabaseError("exists_by_equinoid", "erro ao verificar existência de equinoid", err)
+	}
 	return count > 0, nil
 }
 
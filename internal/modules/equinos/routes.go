@@ -10,9 +10,13 @@ func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.Ha
 	{
 		equinos.GET("", handler.ListEquinos)
 		equinos.POST("", handler.CreateEquino)
+		equinos.GET("/identificador", handler.FindByIdentificador)
 		equinos.GET("/:equinoid", handler.GetEquino)
 		equinos.PUT("/:equinoid", handler.UpdateEquino)
 		equinos.DELETE("/:equinoid", handler.DeleteEquino)
 		equinos.POST("/:equinoid/transferir", handler.TransferOwnership)
+		equinos.GET("/:equinoid/identificadores", handler.ListIdentificadores)
+		equinos.POST("/:equinoid/identificadores", handler.AddIdentificador)
+		equinos.DELETE("/:equinoid/identificadores/:id", handler.RemoveIdentificador)
 	}
 }
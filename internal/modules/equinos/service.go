@@ -2,6 +2,8 @@ package equinos
 
 import (
 	"context"
+	"regexp"
+	"strings"
 
 	"github.com/equinoid/backend/internal/models"
 	"github.com/equinoid/backend/internal/utils"
@@ -10,10 +12,31 @@ import (
 	"github.com/equinoid/backend/pkg/logging"
 )
 
+// uelnPattern Universal Equine Life Number: país (3 dígitos) + base de dados (3) + identificação (9)
+var uelnPattern = regexp.MustCompile(`^[0-9]{3}[0-9A-Z]{12}$`)
+
 type D4SignService interface {
-	RegisterDocument(ctx context.Context, equinoid string, docType string, filePath string) (string, error)
+	RegisterDocument(ctx context.Context, createdBy uint, req models.CreateD4SignDocumentRequest) (string, error)
+}
+
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
 }
 
+// WebhookDispatcher entrega eventos aos webhooks cadastrados pelo usuário
+type WebhookDispatcher interface {
+	DispatchEvent(ctx context.Context, userID uint, event string, payload interface{}) (int, error)
+}
+
+// Eventos de equino entregues aos webhooks do proprietário
+const (
+	WebhookEventEquinoCriado      = "equino.criado"
+	WebhookEventEquinoAtualizado  = "equino.atualizado"
+	WebhookEventEquinoRemovido    = "equino.removido"
+	WebhookEventEquinoTransferido = "equino.transferido"
+)
+
 type Service interface {
 	List(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*models.Equino, int64, error)
 	GetByEquinoid(ctx context.Context, equinoidID string) (*models.Equino, error)
@@ -21,6 +44,11 @@ type Service interface {
 	Update(ctx context.Context, equinoidID string, req *models.UpdateEquinoRequest) (*models.Equino, error)
 	Delete(ctx context.Context, equinoidID string) error
 	TransferOwnership(ctx context.Context, equinoidID string, newOwnerID uint) error
+
+	FindByIdentificador(ctx context.Context, tipo models.TipoIdentificador, emissor, valor string) (*models.Equino, error)
+	ListIdentificadores(ctx context.Context, equinoidID string) ([]*models.EquinoIdentificador, error)
+	AddIdentificador(ctx context.Context, equinoidID string, userID uint, userType string, req *models.CreateIdentificadorRequest) (*models.EquinoIdentificador, error)
+	RemoveIdentificador(ctx context.Context, equinoidID string, id uint, userID uint, userType string) error
 }
 
 type service struct {
@@ -28,14 +56,18 @@ type service struct {
 	cache         cache.CacheInterface
 	logger        *logging.Logger
 	d4signService D4SignService
+	audit         AuditLogger
+	webhooks      WebhookDispatcher
 }
 
-func NewService(repo Repository, cache cache.CacheInterface, logger *logging.Logger, d4signService D4SignService) Service {
+func NewService(repo Repository, cache cache.CacheInterface, logger *logging.Logger, d4signService D4SignService, audit AuditLogger, webhooks WebhookDispatcher) Service {
 	return &service{
 		repo:          repo,
 		cache:         cache,
 		logger:        logger,
 		d4signService: d4signService,
+		audit:         audit,
+		webhooks:      webhooks,
 	}
 }
 
@@ -103,6 +135,7 @@ func (s *service) Create(ctx context.Context, req *models.CreateEquinoRequest, u
 		Pelagem:        req.Pelagem,
 		DataNascimento: req.DataNascimento,
 		PaisOrigem:     req.PaisOrigem,
+		Resenha:        req.Resenha,
 		ProprietarioID: req.ProprietarioID,
 		Status:         "ativo",
 	}
@@ -112,6 +145,9 @@ func (s *service) Create(ctx context.Context, req *models.CreateEquinoRequest, u
 		return nil, err
 	}
 
+	s.recordChange(ctx, equino.Equinoid, "create", nil, equino)
+	s.notifyWebhooks(ctx, equino.ProprietarioID, WebhookEventEquinoCriado, equino)
+
 	s.logger.WithFields(logging.Fields{
 		"equinoid":        equino.Equinoid,
 		"proprietario_id": userID,
@@ -128,6 +164,7 @@ func (s *service) Update(ctx context.Context, equinoidID string, req *models.Upd
 		}
 		return nil, err
 	}
+	before := *equino
 
 	if req.Nome != nil && *req.Nome != "" {
 		equino.Nome = *req.Nome
@@ -138,25 +175,42 @@ func (s *service) Update(ctx context.Context, equinoidID string, req *models.Upd
 	if req.Pelagem != nil && *req.Pelagem != "" {
 		equino.Pelagem = *req.Pelagem
 	}
+	if req.Resenha != nil {
+		equino.Resenha = *req.Resenha
+	}
 
 	if err := s.repo.Update(ctx, equino); err != nil {
 		s.logger.LogError(err, "EquinoService.Update", logging.Fields{"equinoid": equinoidID})
 		return nil, err
 	}
 
+	s.recordChange(ctx, equino.Equinoid, "update", &before, equino)
+	s.notifyWebhooks(ctx, equino.ProprietarioID, WebhookEventEquinoAtualizado, equino)
+
 	s.logger.WithFields(logging.Fields{"equinoid": equinoidID}).Info("Equino atualizado com sucesso")
 
 	return equino, nil
 }
 
 func (s *service) Delete(ctx context.Context, equinoidID string) error {
-	if err := s.repo.Delete(ctx, equinoidID); err != nil {
+	equino, err := s.repo.FindByEquinoid(ctx, equinoidID)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "EquinoService.Delete", logging.Fields{"equinoid": equinoidID})
+		}
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, equino.Equinoid); err != nil {
 		if !apperrors.IsNotFound(err) {
 			s.logger.LogError(err, "EquinoService.Delete", logging.Fields{"equinoid": equinoidID})
 		}
 		return err
 	}
 
+	s.recordChange(ctx, equino.Equinoid, "delete", equino, nil)
+	s.notifyWebhooks(ctx, equino.ProprietarioID, WebhookEventEquinoRemovido, equino)
+
 	s.logger.WithFields(logging.Fields{"equinoid": equinoidID}).Info("Equino deletado com sucesso")
 
 	return nil
@@ -171,7 +225,7 @@ func (s *service) TransferOwnership(ctx context.Context, equinoidID string, newO
 		return err
 	}
 
-	if err := s.repo.TransferOwnership(ctx, equinoidID, newOwnerID); err != nil {
+	if err := s.repo.TransferOwnership(ctx, equino.Equinoid, newOwnerID); err != nil {
 		s.logger.LogError(err, "EquinoService.TransferOwnership", logging.Fields{
 			"equinoid":     equinoidID,
 			"new_owner_id": newOwnerID,
@@ -179,6 +233,12 @@ func (s *service) TransferOwnership(ctx context.Context, equinoidID string, newO
 		return err
 	}
 
+	after := *equino
+	after.ProprietarioID = newOwnerID
+	s.recordChange(ctx, equino.Equinoid, "transfer_ownership", equino, &after)
+	s.notifyWebhooks(ctx, equino.ProprietarioID, WebhookEventEquinoTransferido, &after)
+	s.notifyWebhooks(ctx, newOwnerID, WebhookEventEquinoTransferido, &after)
+
 	s.logger.WithFields(logging.Fields{
 		"equinoid":     equinoidID,
 		"old_owner_id": equino.ProprietarioID,
@@ -187,3 +247,132 @@ func (s *service) TransferOwnership(ctx context.Context, equinoidID string, newO
 
 	return nil
 }
+
+// FindByIdentificador localiza o equino por UELN, número de registro na associação de raça ou alias v2
+func (s *service) FindByIdentificador(ctx context.Context, tipo models.TipoIdentificador, emissor, valor string) (*models.Equino, error) {
+	emissor, valor, err := normalizeIdentificador(tipo, emissor, valor)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindByIdentificador(ctx, tipo, emissor, valor)
+}
+
+func (s *service) ListIdentificadores(ctx context.Context, equinoidID string) ([]*models.EquinoIdentificador, error) {
+	equino, err := s.GetByEquinoid(ctx, equinoidID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListIdentificadores(ctx, equino.ID)
+}
+
+func (s *service) AddIdentificador(ctx context.Context, equinoidID string, userID uint, userType string, req *models.CreateIdentificadorRequest) (*models.EquinoIdentificador, error) {
+	if !models.IsValidTipoIdentificador(req.Tipo) {
+		return nil, &apperrors.ValidationError{Field: "tipo", Message: "tipo de identificador inválido (ueln ou registro_raca)", Value: req.Tipo}
+	}
+	emissor, valor, err := normalizeIdentificador(req.Tipo, req.Emissor, req.Valor)
+	if err != nil {
+		return nil, err
+	}
+
+	equino, err := s.GetByEquinoid(ctx, equinoidID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOwner(equino, userID, userType, "cadastrar_identificador"); err != nil {
+		return nil, err
+	}
+
+	identificador := &models.EquinoIdentificador{
+		EquinoID:  equino.ID,
+		Tipo:      req.Tipo,
+		Emissor:   emissor,
+		Valor:     valor,
+		CriadoPor: &userID,
+	}
+	if err := s.repo.CreateIdentificador(ctx, identificador); err != nil {
+		if !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "EquinoService.AddIdentificador", logging.Fields{"equinoid": equino.Equinoid, "tipo": req.Tipo})
+		}
+		return nil, err
+	}
+
+	s.recordChange(ctx, equino.Equinoid, "add_identificador", nil, identificador)
+	return identificador, nil
+}
+
+func (s *service) RemoveIdentificador(ctx context.Context, equinoidID string, id uint, userID uint, userType string) error {
+	equino, err := s.GetByEquinoid(ctx, equinoidID)
+	if err != nil {
+		return err
+	}
+	if err := checkOwner(equino, userID, userType, "remover_identificador"); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteIdentificador(ctx, equino.ID, id); err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "EquinoService.RemoveIdentificador", logging.Fields{"equinoid": equino.Equinoid, "identificador_id": id})
+		}
+		return err
+	}
+
+	s.recordChange(ctx, equino.Equinoid, "remove_identificador", map[string]interface{}{"identificador_id": id}, nil)
+	return nil
+}
+
+// normalizeIdentificador padroniza emissor e valor como são gravados; UELN tem 15 caracteres alfanuméricos, os três
+// primeiros com o código numérico do país, e o número de registro exige a associação emissora
+func normalizeIdentificador(tipo models.TipoIdentificador, emissor, valor string) (string, string, error) {
+	valor = strings.ToUpper(strings.TrimSpace(valor))
+	emissor = strings.ToUpper(strings.TrimSpace(emissor))
+
+	switch tipo {
+	case models.IdentificadorUELN:
+		valor = strings.ReplaceAll(valor, " ", "")
+		if !uelnPattern.MatchString(valor) {
+			return "", "", &apperrors.ValidationError{Field: "valor", Message: "UELN deve ter 15 caracteres alfanuméricos começando pelo código numérico do país", Value: valor}
+		}
+		return "", valor, nil
+	case models.IdentificadorRegistroRaca:
+		if emissor == "" {
+			return "", "", &apperrors.ValidationError{Field: "emissor", Message: "informe a associação de raça que emitiu o registro"}
+		}
+		if valor == "" || len(valor) > 64 || len(emissor) > 100 {
+			return "", "", &apperrors.ValidationError{Field: "valor", Message: "número de registro inválido", Value: valor}
+		}
+		return emissor, valor, nil
+	case models.IdentificadorEquinoidV2:
+		return "", utils.NormalizeEquinoId(valor), nil
+	}
+	return "", "", &apperrors.ValidationError{Field: "tipo", Message: "tipo de identificador inválido", Value: tipo}
+}
+
+func checkOwner(equino *models.Equino, userID uint, userType, action string) error {
+	if userType == string(models.UserTypeAdmin) || equino.ProprietarioID == userID {
+		return nil
+	}
+	return (&apperrors.AuthorizationError{Message: "apenas o proprietário pode alterar os identificadores do equino"}).WithAction(action, "equino")
+}
+
+func (s *service) recordChange(ctx context.Context, equinoid, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, "equino", equinoid, operation, before, after); err != nil {
+		s.logger.LogError(err, "EquinoService.recordChange", logging.Fields{"equinoid": equinoid, "operation": operation})
+	}
+}
+
+// notifyWebhooks entrega o evento em segundo plano, sem atrasar a resposta; titulares sem consentimento de
+// compartilhamento com terceiros são ignorados em silêncio
+func (s *service) notifyWebhooks(ctx context.Context, userID uint, event string, equino *models.Equino) {
+	if s.webhooks == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if _, err := s.webhooks.DispatchEvent(ctx, userID, event, equino); err != nil && !apperrors.IsAuthorization(err) {
+			s.logger.LogError(err, "EquinoService.notifyWebhooks", logging.Fields{"equinoid": equino.Equinoid, "event": event})
+		}
+	}()
+}
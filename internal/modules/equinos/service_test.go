@@ -0,0 +1,78 @@
+package equinos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type eventoWebhook struct {
+	userID uint
+	event  string
+}
+
+// webhookDispatcherFake registra os eventos despachados; titulares fora de comConsentimento recebem o erro do consentimento
+type webhookDispatcherFake struct {
+	eventos          chan eventoWebhook
+	comConsentimento map[uint]bool
+}
+
+func (f *webhookDispatcherFake) DispatchEvent(ctx context.Context, userID uint, event string, payload interface{}) (int, error) {
+	f.eventos <- eventoWebhook{userID: userID, event: event}
+	if !f.comConsentimento[userID] {
+		return 0, &apperrors.AuthorizationError{Message: "consentimento não concedido"}
+	}
+	return 1, nil
+}
+
+func TestEquinoService_TransferOwnershipNotificaWebhooks(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Skip("sqlite driver unavailable for tests")
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.Equino{}, &models.EquinoIdentificador{}))
+
+	dataNascimento := time.Now().AddDate(-5, 0, 0)
+	require.NoError(t, db.Create(&models.Equino{
+		Equinoid:       "BRA-2020-00000001",
+		MicrochipID:    "CHIP_WEBHOOK",
+		Nome:           "Mensageiro",
+		Sexo:           models.SexoMacho,
+		Raca:           "Mangalarga",
+		Pelagem:        "Alazão",
+		PaisOrigem:     "BRA",
+		DataNascimento: &dataNascimento,
+		Status:         models.StatusAtivo,
+		ProprietarioID: 1,
+	}).Error)
+
+	dispatcher := &webhookDispatcherFake{eventos: make(chan eventoWebhook, 2), comConsentimento: map[uint]bool{1: true}}
+	service := NewService(NewRepository(db), nil, logging.NewLogger("error"), nil, nil, dispatcher)
+
+	require.NoError(t, service.TransferOwnership(context.Background(), "BRA-2020-00000001", 2))
+
+	var recebidos []eventoWebhook
+	for len(recebidos) < 2 {
+		select {
+		case evento := <-dispatcher.eventos:
+			recebidos = append(recebidos, evento)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("esperados 2 eventos de webhook, recebidos %d", len(recebidos))
+		}
+	}
+	assert.ElementsMatch(t, []eventoWebhook{
+		{userID: 1, event: WebhookEventEquinoTransferido},
+		{userID: 2, event: WebhookEventEquinoTransferido},
+	}, recebidos)
+}
@@ -4,7 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc, healthAccess gin.HandlerFunc) {
 	eventos := rg.Group("/eventos")
 	eventos.Use(authMiddleware)
 	{
@@ -18,6 +18,6 @@ func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.Ha
 	equinos := rg.Group("/equinos")
 	equinos.Use(authMiddleware)
 	{
-		equinos.GET("/:equinoid/eventos", handler.ListByEquino)
+		equinos.GET("/:equinoid/eventos", healthAccess, handler.ListByEquino)
 	}
 }
@@ -2,6 +2,8 @@ package eventos
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 
 	"github.com/equinoid/backend/internal/models"
 	apperrors "github.com/equinoid/backend/pkg/errors"
@@ -17,15 +19,22 @@ type Service interface {
 	ListByEquino(ctx context.Context, equinoid string) ([]*models.EventoResponse, error)
 }
 
+// Timestamper emite carimbos de tempo RFC 3161 sobre um digest SHA-256
+type Timestamper interface {
+	Timestamp(ctx context.Context, digest []byte) (*models.TimestampToken, error)
+}
+
 type service struct {
-	repo   Repository
-	logger *logging.Logger
+	repo        Repository
+	timestamper Timestamper
+	logger      *logging.Logger
 }
 
-func NewService(repo Repository, logger *logging.Logger) Service {
+func NewService(repo Repository, timestamper Timestamper, logger *logging.Logger) Service {
 	return &service{
-		repo:   repo,
-		logger: logger,
+		repo:        repo,
+		timestamper: timestamper,
+		logger:      logger,
 	}
 }
 
@@ -67,7 +76,9 @@ func (s *service) Create(ctx context.Context, userID uint, req *models.CreateEve
 		ValorInscricao:        req.ValorInscricao,
 		AceitaPatrocinio:      req.AceitaPatrocinio,
 		InformacoesPatrocinio: req.InformacoesPatrocinio,
+		AssinaturaDigital:     req.AssinaturaDigital,
 	}
+	s.stampSignature(ctx, evento)
 
 	if err := s.repo.Create(ctx, evento); err != nil {
 		s.logger.LogError(err, "EventoService.Create", logging.Fields{"user_id": userID})
@@ -80,7 +91,7 @@ func (s *service) Create(ctx context.Context, userID uint, req *models.CreateEve
 }
 
 func (s *service) Update(ctx context.Context, id uint, req *models.CreateEventoRequest) (*models.EventoResponse, error) {
-	_, err := s.repo.FindByID(ctx, id)
+	current, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		if !apperrors.IsNotFound(err) {
 			s.logger.LogError(err, "EventoService.Update", logging.Fields{"evento_id": id})
@@ -100,6 +111,10 @@ func (s *service) Update(ctx context.Context, id uint, req *models.CreateEventoR
 		AceitaPatrocinio:      req.AceitaPatrocinio,
 		InformacoesPatrocinio: req.InformacoesPatrocinio,
 	}
+	if req.AssinaturaDigital != "" && req.AssinaturaDigital != current.AssinaturaDigital {
+		eventoUpdate.AssinaturaDigital = req.AssinaturaDigital
+		s.stampSignature(ctx, eventoUpdate)
+	}
 
 	if err := s.repo.Update(ctx, eventoUpdate); err != nil {
 		s.logger.LogError(err, "EventoService.Update", logging.Fields{"evento_id": id})
@@ -130,3 +145,22 @@ func (s *service) ListByEquino(ctx context.Context, equinoid string) ([]*models.
 	}
 	return eventos, nil
 }
+
+// stampSignature anexa ao evento um carimbo de tempo sobre o SHA-256 da assinatura digital. Falhas da TSA não
+// impedem o registro do evento: ficam no log e o evento segue sem carimbo.
+func (s *service) stampSignature(ctx context.Context, evento *models.Evento) {
+	if evento.AssinaturaDigital == "" || s.timestamper == nil {
+		return
+	}
+
+	digest := sha256.Sum256([]byte(evento.AssinaturaDigital))
+	token, err := s.timestamper.Timestamp(ctx, digest[:])
+	if err != nil {
+		s.logger.LogError(err, "EventoService.stampSignature", logging.Fields{"evento_id": evento.ID})
+		return
+	}
+
+	evento.CarimboTempo = base64.StdEncoding.EncodeToString(token.Token)
+	evento.CarimboTempoEm = &token.Time
+	evento.CarimboTempoTSA = token.Authority
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/equinoid/backend/internal/middleware"
 	"github.com/equinoid/backend/internal/models"
 	apperrors "github.com/equinoid/backend/pkg/errors"
 	"github.com/equinoid/backend/pkg/logging"
@@ -13,20 +14,22 @@ import (
 )
 
 type Handler struct {
-	service Service
-	logger  *logging.Logger
+	service       Service
+	accessChecker middleware.EquinoAccessChecker
+	logger        *logging.Logger
 }
 
-func NewHandler(service Service, logger *logging.Logger) *Handler {
+func NewHandler(service Service, accessChecker middleware.EquinoAccessChecker, logger *logging.Logger) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
+		service:       service,
+		accessChecker: accessChecker,
+		logger:        logger,
 	}
 }
 
 // List godoc
 // @Summary Listar exames laboratoriais
-// @Description Lista todos os exames laboratoriais com filtros opcionais
+// @Description Lista os exames laboratoriais dos equinos a que o usuário tem acesso, com filtros opcionais
 // @Tags Exames
 // @Produce json
 // @Param equinoid query string false "Filtrar por Equinoid"
@@ -41,7 +44,11 @@ func NewHandler(service Service, logger *logging.Logger) *Handler {
 func (h *Handler) List(c *gin.Context) {
 	filters := make(map[string]interface{})
 	
-	if equinoid := c.Query("equinoid"); equinoid != "" {
+	equinoid := c.Query("equinoid")
+	if equinoid != "" {
+		if !h.authorize(c, equinoid, models.EscopoLerSaude) {
+			return
+		}
 		filters["equinoid"] = equinoid
 	}
 	if status := c.Query("status"); status != "" {
@@ -62,6 +69,9 @@ func (h *Handler) List(c *gin.Context) {
 	}
 
 	exames, err := h.service.ListAll(c.Request.Context(), filters)
+	if err == nil && equinoid == "" {
+		exames, err = h.filtrarAcessiveis(c, exames)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success:   false,
@@ -87,6 +97,7 @@ func (h *Handler) List(c *gin.Context) {
 // @Param id path int true "ID do exame"
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /exames-laboratoriais/{id} [get]
@@ -103,21 +114,8 @@ func (h *Handler) GetByID(c *gin.Context) {
 		return
 	}
 
-	exame, err := h.service.GetByID(c.Request.Context(), uint(id))
-	if err != nil {
-		if apperrors.IsNotFound(err) {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
-				Success:   false,
-				Error:     "Exame não encontrado",
-				Timestamp: time.Now(),
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao buscar exame",
-			Timestamp: time.Now(),
-		})
+	exame, ok := h.loadExame(c, uint(id), models.EscopoLerSaude)
+	if !ok {
 		return
 	}
 
@@ -152,6 +150,10 @@ func (h *Handler) Create(c *gin.Context) {
 		return
 	}
 
+	if !h.authorize(c, req.Equinoid, models.EscopoEscreverExames) {
+		return
+	}
+
 	exame, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -180,6 +182,7 @@ func (h *Handler) Create(c *gin.Context) {
 // @Param exame body models.UpdateExameRequest true "Dados para atualização"
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /exames-laboratoriais/{id} [put]
@@ -206,6 +209,10 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
+	if _, ok := h.loadExame(c, uint(id), models.EscopoEscreverExames); !ok {
+		return
+	}
+
 	exame, err := h.service.Update(c.Request.Context(), uint(id), &req)
 	if err != nil {
 		if apperrors.IsNotFound(err) {
@@ -240,6 +247,7 @@ func (h *Handler) Update(c *gin.Context) {
 // @Param id path int true "ID do exame"
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /exames-laboratoriais/{id} [delete]
@@ -256,6 +264,10 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
+	if _, ok := h.loadExame(c, uint(id), models.EscopoEscreverExames); !ok {
+		return
+	}
+
 	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
 		if apperrors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -291,6 +303,7 @@ func (h *Handler) Delete(c *gin.Context) {
 // @Param recebimento body object{data_recebimento=string} false "Data de recebimento"
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /exames-laboratoriais/{id}/receber-amostra [put]
 // @Security BearerAuth
@@ -311,6 +324,10 @@ func (h *Handler) ReceberAmostra(c *gin.Context) {
 	}
 	c.ShouldBindJSON(&req)
 
+	if _, ok := h.loadExame(c, uint(id), models.EscopoEscreverExames); !ok {
+		return
+	}
+
 	exame, err := h.service.ReceberAmostra(c.Request.Context(), uint(id), req.DataRecebimento)
 	if err != nil {
 		if apperrors.IsValidation(err) {
@@ -345,6 +362,7 @@ func (h *Handler) ReceberAmostra(c *gin.Context) {
 // @Param id path int true "ID do exame"
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /exames-laboratoriais/{id}/iniciar-analise [put]
 // @Security BearerAuth
@@ -360,6 +378,10 @@ func (h *Handler) IniciarAnalise(c *gin.Context) {
 		return
 	}
 
+	if _, ok := h.loadExame(c, uint(id), models.EscopoEscreverExames); !ok {
+		return
+	}
+
 	exame, err := h.service.IniciarAnalise(c.Request.Context(), uint(id))
 	if err != nil {
 		if apperrors.IsValidation(err) {
@@ -425,6 +447,10 @@ func (h *Handler) ConcluirExame(c *gin.Context) {
 		return
 	}
 
+	if _, ok := h.loadExame(c, uint(id), models.EscopoAssinarRegistros); !ok {
+		return
+	}
+
 	exame, err := h.service.ConcluirExame(c.Request.Context(), uint(id), req.Resultado, req.Valores, req.Laudo)
 	if err != nil {
 		if apperrors.IsValidation(err) {
@@ -450,3 +476,97 @@ func (h *Handler) ConcluirExame(c *gin.Context) {
 		Data:      exame,
 	})
 }
+
+// loadExame busca o exame e verifica o escopo do usuário sobre o equino dele
+func (h *Handler) loadExame(c *gin.Context, id uint, escopo string) (*models.ExameLaboratorial, bool) {
+	exame, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Success:   false,
+				Error:     "Exame não encontrado",
+				Timestamp: time.Now(),
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success:   false,
+			Error:     "Erro ao buscar exame",
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+
+	if !h.authorize(c, exame.Equinoid, escopo) {
+		return nil, false
+	}
+	return exame, true
+}
+
+// filtrarAcessiveis mantém apenas os exames de equinos que o usuário pode ler; administradores veem todos
+func (h *Handler) filtrarAcessiveis(c *gin.Context, exames []*models.ExameLaboratorial) ([]*models.ExameLaboratorial, error) {
+	userID, _ := middleware.GetUserIDFromContext(c)
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	if userType == string(models.UserTypeAdmin) {
+		return exames, nil
+	}
+
+	permitidos := make(map[string]bool)
+	filtrados := make([]*models.ExameLaboratorial, 0, len(exames))
+	for _, exame := range exames {
+		permitido, visto := permitidos[exame.Equinoid]
+		if !visto {
+			err := h.accessChecker.CheckEquinoAccess(c.Request.Context(), userID, userType, exame.Equinoid, models.EscopoLerSaude)
+			if err != nil && !apperrors.IsAuthorization(err) && !apperrors.IsNotFound(err) {
+				return nil, err
+			}
+			permitido = err == nil
+			permitidos[exame.Equinoid] = permitido
+		}
+		if permitido {
+			filtrados = append(filtrados, exame)
+		}
+	}
+	return filtrados, nil
+}
+
+// authorize verifica se o usuário autenticado possui o escopo informado sobre o equino
+func (h *Handler) authorize(c *gin.Context, equinoid string, escopo string) bool {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+
+	if err := h.accessChecker.CheckEquinoAccess(c.Request.Context(), userID, userType, equinoid, escopo); err != nil {
+		if apperrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Success:   false,
+				Error:     "Equino não encontrado",
+				Timestamp: time.Now(),
+			})
+			return false
+		}
+		if apperrors.IsAuthorization(err) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Success:   false,
+				Error:     err.Error(),
+				Timestamp: time.Now(),
+			})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success:   false,
+			Error:     "Erro ao verificar permissões",
+			Timestamp: time.Now(),
+		})
+		return false
+	}
+
+	return true
+}
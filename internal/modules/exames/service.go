@@ -2,6 +2,10 @@ package exames
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"time"
 
 	"github.com/equinoid/backend/internal/models"
@@ -9,6 +13,16 @@ import (
 	"github.com/equinoid/backend/pkg/logging"
 )
 
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+// Timestamper emite carimbos de tempo RFC 3161 sobre um digest SHA-256
+type Timestamper interface {
+	Timestamp(ctx context.Context, digest []byte) (*models.TimestampToken, error)
+}
+
 type Service interface {
 	ListAll(ctx context.Context, filters map[string]interface{}) ([]*models.ExameLaboratorial, error)
 	GetByID(ctx context.Context, id uint) (*models.ExameLaboratorial, error)
@@ -22,14 +36,18 @@ type Service interface {
 }
 
 type service struct {
-	repo   Repository
-	logger *logging.Logger
+	repo        Repository
+	audit       AuditLogger
+	timestamper Timestamper
+	logger      *logging.Logger
 }
 
-func NewService(repo Repository, logger *logging.Logger) Service {
+func NewService(repo Repository, audit AuditLogger, timestamper Timestamper, logger *logging.Logger) Service {
 	return &service{
-		repo:   repo,
-		logger: logger,
+		repo:        repo,
+		audit:       audit,
+		timestamper: timestamper,
+		logger:      logger,
 	}
 }
 
@@ -71,6 +89,8 @@ func (s *service) Create(ctx context.Context, req *models.CreateExameRequest) (*
 		return nil, err
 	}
 
+	s.recordChange(ctx, exame.ID, "create", nil, exame)
+
 	s.logger.WithFields(logging.Fields{"exame_id": exame.ID, "tipo": exame.TipoExame}).Info("Exame solicitado")
 	return s.GetByID(ctx, exame.ID)
 }
@@ -80,6 +100,7 @@ func (s *service) Update(ctx context.Context, id uint, req *models.UpdateExameRe
 	if err != nil {
 		return nil, err
 	}
+	before := *exame
 
 	if req.Status != nil {
 		exame.Status = *req.Status
@@ -102,8 +123,9 @@ func (s *service) Update(ctx context.Context, id uint, req *models.UpdateExameRe
 	if req.Valores != nil {
 		exame.Valores = req.Valores
 	}
-	if req.Laudo != nil {
+	if req.Laudo != nil && (exame.Laudo == nil || *exame.Laudo != *req.Laudo) {
 		exame.Laudo = req.Laudo
+		s.stampLaudo(ctx, exame)
 	}
 	if req.Observacoes != nil {
 		exame.Observacoes = req.Observacoes
@@ -114,16 +136,24 @@ func (s *service) Update(ctx context.Context, id uint, req *models.UpdateExameRe
 		return nil, err
 	}
 
+	s.recordChange(ctx, id, "update", &before, exame)
+
 	return s.GetByID(ctx, id)
 }
 
 func (s *service) Delete(ctx context.Context, id uint) error {
+	exame, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	if err := s.repo.Delete(ctx, id); err != nil {
 		if !apperrors.IsNotFound(err) {
 			s.logger.LogError(err, "ExameService.Delete", logging.Fields{"id": id})
 		}
 		return err
 	}
+	s.recordChange(ctx, id, "delete", exame, nil)
 	s.logger.WithFields(logging.Fields{"exame_id": id}).Info("Exame deletado")
 	return nil
 }
@@ -211,3 +241,40 @@ func (s *service) ConcluirExame(ctx context.Context, id uint, resultado models.R
 
 	return exameAtualizado, nil
 }
+
+func (s *service) recordChange(ctx context.Context, id uint, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, "exame_laboratorial", fmt.Sprintf("%d", id), operation, before, after); err != nil {
+		s.logger.LogError(err, "ExameService.recordChange", logging.Fields{"id": id, "operation": operation})
+	}
+}
+
+// stampLaudo registra o SHA-256 do laudo e anexa um carimbo de tempo sobre ele. Sem a TSA o laudo é salvo com o hash
+// e sem carimbo, e a falha fica no log.
+func (s *service) stampLaudo(ctx context.Context, exame *models.ExameLaboratorial) {
+	exame.LaudoHash = ""
+	exame.LaudoCarimboTempo = ""
+	exame.LaudoCarimboTempoEm = nil
+	exame.LaudoCarimboTempoTSA = ""
+	if exame.Laudo == nil || *exame.Laudo == "" {
+		return
+	}
+
+	digest := sha256.Sum256([]byte(*exame.Laudo))
+	exame.LaudoHash = hex.EncodeToString(digest[:])
+	if s.timestamper == nil {
+		return
+	}
+
+	token, err := s.timestamper.Timestamp(ctx, digest[:])
+	if err != nil {
+		s.logger.LogError(err, "ExameService.stampLaudo", logging.Fields{"exame_id": exame.ID})
+		return
+	}
+
+	exame.LaudoCarimboTempo = base64.StdEncoding.EncodeToString(token.Token)
+	exame.LaudoCarimboTempoEm = &token.Time
+	exame.LaudoCarimboTempoTSA = token.Authority
+}
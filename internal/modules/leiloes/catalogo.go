@@ -0,0 +1,242 @@
+package leiloes
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+// geracoesCatalogo gerações do pedigree de cada lote
+const geracoesCatalogo = 3
+
+// maxFotosLote fotos públicas listadas por lote; a primeira é a capa impressa no PDF
+const maxFotosLote = 6
+
+// maxDestaquesLote conquistas listadas por lote, das de maior pontuação
+const maxDestaquesLote = 5
+
+// maxFotoCapa fotos maiores são omitidas do PDF quando o cofre não tem miniatura
+const maxFotoCapa = 4 << 20
+
+// montarCatalogo reúne o conteúdo atual do catálogo e a chave da foto de capa de cada lote numerado. Participações
+// aprovadas ainda sem número entram com número zero na sua sessão
+func (s *service) montarCatalogo(ctx context.Context, leilao *models.Leilao) (*models.ConteudoCatalogo, map[int]string, error) {
+	sessoes, err := s.repo.FindSessoes(ctx, leilao.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	participacoes, err := s.repo.FindLotes(ctx, leilao.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conteudo := &models.ConteudoCatalogo{
+		LeilaoID:               leilao.ID,
+		Nome:                   leilao.Nome,
+		Descricao:              leilao.Descricao,
+		Local:                  leilao.Local,
+		TipoLeilao:             leilao.TipoLeilao,
+		DataInicio:             leilao.DataInicio,
+		DataFim:                leilao.DataFim,
+		TaxaComissaoPercentual: leilao.TaxaComissaoPercentual,
+		TaxaFixa:               leilao.TaxaFixa,
+		Versao:                 leilao.VersaoCatalogo,
+		GeradoEm:               time.Now().UTC().Truncate(time.Second),
+		Sessoes:                []models.SessaoCatalogo{},
+	}
+	if leilao.Leiloeiro != nil {
+		conteudo.Leiloeiro = leilao.Leiloeiro.Name
+	}
+
+	indice := make(map[int]int)
+	for _, sessao := range sessoes {
+		indice[sessao.Numero] = len(conteudo.Sessoes)
+		conteudo.Sessoes = append(conteudo.Sessoes, models.SessaoCatalogo{
+			Numero:   sessao.Numero,
+			Titulo:   sessao.Titulo,
+			InicioEm: sessao.InicioEm,
+			Lotes:    []models.LoteCatalogo{},
+		})
+	}
+
+	capas := make(map[int]string)
+	for _, p := range participacoes {
+		lote, capa, err := s.montarLote(ctx, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		if lote.Numero > 0 && capa != "" {
+			capas[lote.Numero] = capa
+		}
+
+		numeroSessao := 1
+		if p.Sessao != nil {
+			numeroSessao = *p.Sessao
+		}
+		i, ok := indice[numeroSessao]
+		if !ok {
+			// Sessão ainda não definida pelo leiloeiro: o lote aparece em uma sessão sem título
+			i = len(conteudo.Sessoes)
+			indice[numeroSessao] = i
+			conteudo.Sessoes = append(conteudo.Sessoes, models.SessaoCatalogo{Numero: numeroSessao, Lotes: []models.LoteCatalogo{}})
+		}
+		conteudo.Sessoes[i].Lotes = append(conteudo.Sessoes[i].Lotes, *lote)
+		conteudo.TotalLotes++
+	}
+
+	sort.SliceStable(conteudo.Sessoes, func(i, j int) bool { return conteudo.Sessoes[i].Numero < conteudo.Sessoes[j].Numero })
+	return conteudo, capas, nil
+}
+
+// montarLote ficha do lote: identificação, pedigree, fotos públicas, valorização validada e situação sanitária
+func (s *service) montarLote(ctx context.Context, p *models.ParticipacaoLeilao) (*models.LoteCatalogo, string, error) {
+	lote := &models.LoteCatalogo{
+		ParticipacaoID:   p.ID,
+		ValorInicial:     p.ValorInicial,
+		Particularidades: p.Particularidades,
+		Fotos:            []models.FotoCatalogo{},
+		Destaques:        []models.DestaqueCatalogo{},
+		Sanidade: models.SanidadeCatalogo{
+			Vacinas: []models.VacinaCatalogo{},
+			Exames:  []models.ExameCatalogo{},
+		},
+	}
+	if p.NumeroLote != nil {
+		lote.Numero = *p.NumeroLote
+	}
+	if p.Criador != nil {
+		lote.Vendedor = p.Criador.Name
+	}
+	e := p.Equino
+	if e == nil {
+		return lote, "", nil
+	}
+	lote.Equinoid = e.Equinoid
+	lote.Nome = e.Nome
+	lote.Sexo = e.Sexo
+	lote.Raca = e.Raca
+	lote.Pelagem = e.Pelagem
+	lote.DataNascimento = e.DataNascimento
+
+	if s.pedigree != nil {
+		arvore, err := s.pedigree.GetArvoreGenealogica(ctx, e.Equinoid, geracoesCatalogo)
+		if err != nil && !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "LeilaoService.montarLote", logging.Fields{"equinoid": e.Equinoid, "action": "pedigree"})
+			return nil, "", err
+		}
+		lote.Pedigree = arvore
+	}
+
+	fotos, err := s.repo.FindFotosPublicas(ctx, e.ID, maxFotosLote)
+	if err != nil {
+		return nil, "", err
+	}
+	var capa string
+	for i, foto := range fotos {
+		lote.Fotos = append(lote.Fotos, models.FotoCatalogo{StorageKey: foto.StorageKey, MimeType: foto.MimeType, Capa: i == 0})
+		if i == 0 {
+			if foto.ThumbnailKey != nil {
+				capa = *foto.ThumbnailKey
+			} else if foto.Tamanho <= maxFotoCapa {
+				capa = foto.StorageKey
+			}
+		}
+	}
+
+	destaques, pontos, err := s.repo.FindDestaques(ctx, e.Equinoid, maxDestaquesLote)
+	if err != nil {
+		return nil, "", err
+	}
+	lote.PontosValorizacao = pontos
+	for _, d := range destaques {
+		lote.Destaques = append(lote.Destaques, models.DestaqueCatalogo{
+			Titulo:      d.Titulo,
+			Categoria:   d.Categoria,
+			Organizacao: d.Organizacao,
+			Data:        d.DataRegistro,
+			Nivel:       d.NivelImportancia,
+			Pontos:      d.PontosValorizacao,
+		})
+	}
+
+	vacinas, err := s.repo.FindVacinas(ctx, e.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	lote.Sanidade.Vacinas = resumirVacinas(vacinas)
+
+	exames, err := s.repo.FindExamesConcluidos(ctx, e.Equinoid)
+	if err != nil {
+		return nil, "", err
+	}
+	lote.Sanidade.Exames = resumirExames(exames)
+
+	return lote, capa, nil
+}
+
+// resumirVacinas uma linha por vacina com a dose mais recente. Os eventos chegam ordenados por data decrescente
+func resumirVacinas(eventos []*models.Evento) []models.VacinaCatalogo {
+	indice := make(map[string]int)
+	resumo := []models.VacinaCatalogo{}
+	for _, evento := range eventos {
+		nome := strings.TrimSpace(evento.NomeEvento)
+		if nome == "" {
+			nome = strings.TrimSpace(evento.Descricao)
+		}
+		if nome == "" {
+			nome = "Vacina"
+		}
+		chave := strings.ToLower(nome)
+
+		if i, ok := indice[chave]; ok {
+			resumo[i].Doses++
+			continue
+		}
+		indice[chave] = len(resumo)
+		resumo = append(resumo, models.VacinaCatalogo{Nome: nome, UltimaDose: evento.DataEvento, Doses: 1})
+	}
+	return resumo
+}
+
+// resumirExames resultado mais recente de cada tipo de exame. Os exames chegam do mais recente para o mais antigo
+func resumirExames(exames []*models.ExameLaboratorial) []models.ExameCatalogo {
+	vistos := make(map[string]bool)
+	resumo := []models.ExameCatalogo{}
+	for _, exame := range exames {
+		chave := strings.ToLower(strings.TrimSpace(exame.TipoExame))
+		if vistos[chave] || exame.Resultado == nil {
+			continue
+		}
+		vistos[chave] = true
+
+		data := exame.DataSolicitacao
+		if exame.DataConclusao != nil {
+			data = *exame.DataConclusao
+		}
+		resumo = append(resumo, models.ExameCatalogo{
+			Tipo:      exame.TipoExame,
+			Nome:      exame.NomeExame,
+			Resultado: *exame.Resultado,
+			Data:      data,
+		})
+	}
+	return resumo
+}
+
+// lotesSemNumero participações aprovadas que ainda não receberam número de lote
+func lotesSemNumero(conteudo *models.ConteudoCatalogo) []uint {
+	var ids []uint
+	for _, sessao := range conteudo.Sessoes {
+		for _, lote := range sessao.Lotes {
+			if lote.Numero == 0 {
+				ids = append(ids, lote.ParticipacaoID)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,446 @@
+package leiloes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+// maxDocumentosHabilitacao documentos aceitos por habilitação
+const maxDocumentosHabilitacao = 10
+
+// documentosHabilitacaoPermitidos tipos de arquivo aceitos e a extensão gravada no armazenamento
+var documentosHabilitacaoPermitidos = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// documentosObrigatorios sem eles a habilitação não pode ser aprovada
+var documentosObrigatorios = []models.TipoDocumentoHabilitacao{
+	models.DocHabilitacaoIdentidade,
+	models.DocHabilitacaoEndereco,
+}
+
+// SolicitarHabilitacao pede a habilitação para arrematar no leilão. Uma solicitação pendente é atualizada; uma
+// rejeitada ou cancelada volta para análise
+func (s *service) SolicitarHabilitacao(ctx context.Context, leilaoID uint, userID uint, req *models.SolicitarHabilitacaoRequest) (*models.HabilitacaoLeilao, error) {
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := exigirHabilitacoesAbertas(leilao); err != nil {
+		return nil, err
+	}
+	if leilao.LeiloeiroID == userID {
+		return nil, &apperrors.ValidationError{Field: "leilao_id", Message: "o leiloeiro não pode se habilitar como arrematante do próprio leilão"}
+	}
+	if err := s.exigirSemComprasPendentes(ctx, userID, leilaoID); err != nil {
+		return nil, err
+	}
+
+	habilitacao, err := s.repo.FindHabilitacao(ctx, leilaoID, userID)
+	if err != nil && !apperrors.IsNotFound(err) {
+		s.logger.LogError(err, "LeilaoService.SolicitarHabilitacao", logging.Fields{"leilao_id": leilaoID, "user_id": userID})
+		return nil, err
+	}
+
+	if habilitacao == nil {
+		habilitacao = &models.HabilitacaoLeilao{
+			LeilaoID:         leilaoID,
+			UsuarioID:        userID,
+			Status:           models.HabilitacaoPendente,
+			LimiteSolicitado: req.LimiteSolicitado,
+			Observacoes:      strings.TrimSpace(req.Observacoes),
+		}
+		if err := s.repo.CreateHabilitacao(ctx, habilitacao); err != nil {
+			s.logger.LogError(err, "LeilaoService.SolicitarHabilitacao", logging.Fields{"leilao_id": leilaoID, "user_id": userID})
+			return nil, err
+		}
+		s.recordChange(ctx, "habilitacao_leilao", habilitacao.ID, "create", nil, habilitacao)
+		s.logger.WithFields(logging.Fields{"leilao_id": leilaoID, "user_id": userID, "habilitacao_id": habilitacao.ID}).Info("Habilitação solicitada")
+		return s.detalharHabilitacao(ctx, habilitacao.ID)
+	}
+
+	before := *habilitacao
+	switch habilitacao.Status {
+	case models.HabilitacaoPendente:
+	case models.HabilitacaoRejeitada, models.HabilitacaoCancelada:
+		habilitacao.Status = models.HabilitacaoPendente
+		habilitacao.Garantia = ""
+		habilitacao.LimiteAprovado = nil
+		habilitacao.ValorCaucao = nil
+		habilitacao.CaucaoReferencia = ""
+		habilitacao.CaucaoConfirmadaEm = nil
+		habilitacao.MotivoRejeicao = ""
+		habilitacao.AnalisadoPor = nil
+		habilitacao.AnalisadoEm = nil
+	default:
+		return nil, &apperrors.ConflictError{Resource: "habilitacao_leilao", Message: "a habilitação já foi analisada", Value: habilitacao.Status}
+	}
+	habilitacao.LimiteSolicitado = req.LimiteSolicitado
+	habilitacao.Observacoes = strings.TrimSpace(req.Observacoes)
+
+	if err := s.repo.UpdateHabilitacao(ctx, habilitacao); err != nil {
+		s.logger.LogError(err, "LeilaoService.SolicitarHabilitacao", logging.Fields{"habilitacao_id": habilitacao.ID})
+		return nil, err
+	}
+	s.recordChange(ctx, "habilitacao_leilao", habilitacao.ID, "update", &before, habilitacao)
+	return s.detalharHabilitacao(ctx, habilitacao.ID)
+}
+
+// EnviarDocumentoHabilitacao anexa um documento à habilitação pendente do usuário
+func (s *service) EnviarDocumentoHabilitacao(ctx context.Context, leilaoID uint, userID uint, tipo models.TipoDocumentoHabilitacao, arquivo *models.ArquivoEnviado) (*models.DocumentoHabilitacao, error) {
+	if s.store == nil {
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos não configurado", nil)
+	}
+	if !tipo.IsValid() {
+		return nil, &apperrors.ValidationError{Field: "tipo", Message: "tipo de documento inválido", Value: tipo}
+	}
+	if s.maxSize > 0 && int64(len(arquivo.Conteudo)) > s.maxSize {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: fmt.Sprintf("arquivo excede o limite de %d bytes", s.maxSize), Value: len(arquivo.Conteudo)}
+	}
+	mimeType := http.DetectContentType(arquivo.Conteudo)
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	ext, permitido := documentosHabilitacaoPermitidos[mimeType]
+	if !permitido {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: "o documento deve ser PDF, JPEG ou PNG", Value: mimeType}
+	}
+
+	habilitacao, err := s.repo.FindHabilitacao(ctx, leilaoID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if habilitacao.Status != models.HabilitacaoPendente {
+		return nil, &apperrors.ValidationError{Field: "status", Message: "documentos só podem ser enviados enquanto a habilitação está pendente", Value: habilitacao.Status}
+	}
+	if len(habilitacao.Documentos) >= maxDocumentosHabilitacao {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: fmt.Sprintf("a habilitação já tem o máximo de %d documentos", maxDocumentosHabilitacao), Value: len(habilitacao.Documentos)}
+	}
+
+	digest := sha256.Sum256(arquivo.Conteudo)
+	documento := &models.DocumentoHabilitacao{
+		HabilitacaoID:  habilitacao.ID,
+		Tipo:           tipo,
+		NomeArquivo:    arquivo.Nome,
+		MimeType:       mimeType,
+		Tamanho:        int64(len(arquivo.Conteudo)),
+		Hash:           hex.EncodeToString(digest[:]),
+		StorageBackend: s.store.Name(),
+		StorageKey:     fmt.Sprintf("leiloes/%d/habilitacoes/%d/%s%s", leilaoID, habilitacao.ID, hex.EncodeToString(digest[:]), ext),
+		EnviadoPor:     userID,
+	}
+	if err := s.store.Put(ctx, documento.StorageKey, arquivo.Conteudo, mimeType); err != nil {
+		s.logger.LogError(err, "LeilaoService.EnviarDocumentoHabilitacao", logging.Fields{"backend": s.store.Name(), "habilitacao_id": habilitacao.ID})
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos indisponível", nil)
+	}
+	if err := s.repo.CreateDocumentoHabilitacao(ctx, documento); err != nil {
+		s.logger.LogError(err, "LeilaoService.EnviarDocumentoHabilitacao", logging.Fields{"habilitacao_id": habilitacao.ID})
+		return nil, err
+	}
+
+	s.recordChange(ctx, "habilitacao_leilao", habilitacao.ID, "upload", nil, documento)
+	s.assinarDocumento(ctx, documento)
+	return documento, nil
+}
+
+func (s *service) GetMinhaHabilitacao(ctx context.Context, leilaoID uint, userID uint) (*models.HabilitacaoLeilao, error) {
+	habilitacao, err := s.repo.FindHabilitacao(ctx, leilaoID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.completarHabilitacao(ctx, habilitacao)
+}
+
+// CancelarHabilitacao desistência do arrematante antes da aprovação definitiva
+func (s *service) CancelarHabilitacao(ctx context.Context, leilaoID uint, userID uint) (*models.HabilitacaoLeilao, error) {
+	habilitacao, err := s.repo.FindHabilitacao(ctx, leilaoID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if habilitacao.Status != models.HabilitacaoPendente && habilitacao.Status != models.HabilitacaoAguardandoCaucao {
+		return nil, &apperrors.ValidationError{Field: "status", Message: "apenas habilitações em análise podem ser canceladas", Value: habilitacao.Status}
+	}
+	return s.transicionarHabilitacao(ctx, habilitacao, models.HabilitacaoCancelada, false, nil)
+}
+
+// ListHabilitacoes fila de análise do leiloeiro, opcionalmente filtrada pelo status
+func (s *service) ListHabilitacoes(ctx context.Context, leilaoID uint, userID uint, userType string, status models.StatusHabilitacao) ([]*models.HabilitacaoLeilao, error) {
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "listar_habilitacoes"); err != nil {
+		return nil, err
+	}
+	habilitacoes, err := s.repo.ListHabilitacoes(ctx, leilaoID, status)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.ListHabilitacoes", logging.Fields{"leilao_id": leilaoID})
+		return nil, err
+	}
+	return habilitacoes, nil
+}
+
+// GetHabilitacao detalhe com documentos, limite utilizado e compras pendentes; visível ao leiloeiro e ao arrematante
+func (s *service) GetHabilitacao(ctx context.Context, id uint, userID uint, userType string) (*models.HabilitacaoLeilao, error) {
+	habilitacao, err := s.repo.FindHabilitacaoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if habilitacao.UsuarioID != userID {
+		leilao, err := s.repo.FindByID(ctx, habilitacao.LeilaoID)
+		if err != nil {
+			return nil, err
+		}
+		if err := podeGerenciar(leilao, userID, userType, "ver_habilitacao"); err != nil {
+			return nil, err
+		}
+	}
+	return s.completarHabilitacao(ctx, habilitacao)
+}
+
+// AprovarHabilitacao concede o limite ao arrematante. Com limite de crédito a habilitação vale imediatamente e recebe a
+// paleta; com caução aguarda a confirmação do depósito
+func (s *service) AprovarHabilitacao(ctx context.Context, id uint, userID uint, userType string, req *models.AprovarHabilitacaoRequest) (*models.HabilitacaoLeilao, error) {
+	habilitacao, leilao, err := s.habilitacaoParaAnalise(ctx, id, userID, userType, "aprovar_habilitacao")
+	if err != nil {
+		return nil, err
+	}
+	if habilitacao.Status != models.HabilitacaoPendente {
+		return nil, &apperrors.ValidationError{Field: "status", Message: "apenas habilitações pendentes podem ser aprovadas", Value: habilitacao.Status}
+	}
+	if err := exigirHabilitacoesAbertas(leilao); err != nil {
+		return nil, err
+	}
+	if faltando := documentosFaltando(habilitacao); len(faltando) > 0 {
+		return nil, &apperrors.ValidationError{Field: "documentos", Message: "a habilitação não tem os documentos obrigatórios", Value: faltando}
+	}
+	if err := s.exigirSemComprasPendentes(ctx, habilitacao.UsuarioID, habilitacao.LeilaoID); err != nil {
+		return nil, err
+	}
+
+	limite := req.LimiteAprovado
+	habilitacao.Garantia = req.Garantia
+	habilitacao.LimiteAprovado = &limite
+	habilitacao.ValorCaucao = nil
+	destino := models.HabilitacaoAprovada
+	if req.Garantia == models.GarantiaCaucao {
+		if req.ValorCaucao == nil {
+			return nil, &apperrors.ValidationError{Field: "valor_caucao", Message: "informe o valor da caução exigida"}
+		}
+		caucao := *req.ValorCaucao
+		habilitacao.ValorCaucao = &caucao
+		destino = models.HabilitacaoAguardandoCaucao
+	}
+	return s.transicionarHabilitacao(ctx, habilitacao, destino, destino == models.HabilitacaoAprovada, &userID)
+}
+
+// ConfirmarCaucao registra o depósito da caução e conclui a habilitação com a paleta
+func (s *service) ConfirmarCaucao(ctx context.Context, id uint, userID uint, userType string, req *models.ConfirmarCaucaoRequest) (*models.HabilitacaoLeilao, error) {
+	habilitacao, leilao, err := s.habilitacaoParaAnalise(ctx, id, userID, userType, "confirmar_caucao")
+	if err != nil {
+		return nil, err
+	}
+	if habilitacao.Status != models.HabilitacaoAguardandoCaucao {
+		return nil, &apperrors.ValidationError{Field: "status", Message: "a habilitação não aguarda caução", Value: habilitacao.Status}
+	}
+	if err := exigirHabilitacoesAbertas(leilao); err != nil {
+		return nil, err
+	}
+
+	agora := time.Now()
+	habilitacao.CaucaoReferencia = strings.TrimSpace(req.Referencia)
+	habilitacao.CaucaoConfirmadaEm = &agora
+	return s.transicionarHabilitacao(ctx, habilitacao, models.HabilitacaoAprovada, true, &userID)
+}
+
+func (s *service) RejeitarHabilitacao(ctx context.Context, id uint, userID uint, userType string, req *models.RejeitarHabilitacaoRequest) (*models.HabilitacaoLeilao, error) {
+	habilitacao, _, err := s.habilitacaoParaAnalise(ctx, id, userID, userType, "rejeitar_habilitacao")
+	if err != nil {
+		return nil, err
+	}
+	if habilitacao.Status != models.HabilitacaoPendente && habilitacao.Status != models.HabilitacaoAguardandoCaucao {
+		return nil, &apperrors.ValidationError{Field: "status", Message: "apenas habilitações em análise podem ser rejeitadas", Value: habilitacao.Status}
+	}
+	habilitacao.MotivoRejeicao = strings.TrimSpace(req.Motivo)
+	return s.transicionarHabilitacao(ctx, habilitacao, models.HabilitacaoRejeitada, false, &userID)
+}
+
+// habilitacaoParaAnalise carrega a habilitação e o leilão, exigindo que o usuário seja o leiloeiro ou administrador
+func (s *service) habilitacaoParaAnalise(ctx context.Context, id uint, userID uint, userType string, acao string) (*models.HabilitacaoLeilao, *models.Leilao, error) {
+	habilitacao, err := s.repo.FindHabilitacaoByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	leilao, err := s.repo.FindByID(ctx, habilitacao.LeilaoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, acao); err != nil {
+		return nil, nil, err
+	}
+	return habilitacao, leilao, nil
+}
+
+func (s *service) transicionarHabilitacao(ctx context.Context, habilitacao *models.HabilitacaoLeilao, destino models.StatusHabilitacao, atribuirPaleta bool, analisadoPor *uint) (*models.HabilitacaoLeilao, error) {
+	before := *habilitacao
+	origem := habilitacao.Status
+	habilitacao.Status = destino
+	if analisadoPor != nil {
+		agora := time.Now()
+		habilitacao.AnalisadoPor = analisadoPor
+		habilitacao.AnalisadoEm = &agora
+	}
+	if err := s.repo.TransicionarHabilitacao(ctx, habilitacao, origem, atribuirPaleta); err != nil {
+		if !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "LeilaoService.transicionarHabilitacao", logging.Fields{"habilitacao_id": habilitacao.ID, "status": destino})
+		}
+		return nil, err
+	}
+
+	s.recordChange(ctx, "habilitacao_leilao", habilitacao.ID, "update", &before, habilitacao)
+	s.logger.WithFields(logging.Fields{
+		"habilitacao_id": habilitacao.ID,
+		"leilao_id":      habilitacao.LeilaoID,
+		"de":             origem,
+		"para":           destino,
+		"paleta":         habilitacao.Paleta,
+	}).Info("Habilitação de arrematante atualizada")
+
+	return s.detalharHabilitacao(ctx, habilitacao.ID)
+}
+
+func (s *service) detalharHabilitacao(ctx context.Context, id uint) (*models.HabilitacaoLeilao, error) {
+	habilitacao, err := s.repo.FindHabilitacaoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.completarHabilitacao(ctx, habilitacao)
+}
+
+// completarHabilitacao preenche o limite já utilizado, as compras pendentes e as URLs dos documentos
+func (s *service) completarHabilitacao(ctx context.Context, habilitacao *models.HabilitacaoLeilao) (*models.HabilitacaoLeilao, error) {
+	utilizado, err := s.repo.TotalArrematado(ctx, habilitacao.LeilaoID, habilitacao.UsuarioID)
+	if err != nil {
+		return nil, err
+	}
+	pendentes, err := s.repo.ComprasPendentes(ctx, habilitacao.UsuarioID, habilitacao.LeilaoID, time.Now().Add(-s.prazoPagamento))
+	if err != nil {
+		return nil, err
+	}
+	habilitacao.LimiteUtilizado = utilizado
+	habilitacao.ComprasPendentes = pendentes
+	for i := range habilitacao.Documentos {
+		s.assinarDocumento(ctx, &habilitacao.Documentos[i])
+	}
+	return habilitacao, nil
+}
+
+func (s *service) assinarDocumento(ctx context.Context, documento *models.DocumentoHabilitacao) {
+	if s.store == nil {
+		return
+	}
+	url, err := s.store.PresignGet(ctx, documento.StorageKey, s.urlTTL, documento.NomeArquivo)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.assinarDocumento", logging.Fields{"documento_id": documento.ID})
+		return
+	}
+	documento.URL = url
+}
+
+// arrematante habilitação do comprador da venda, pela paleta ou pelo ID do usuário. Só arremata quem está aprovado
+func (s *service) arrematante(ctx context.Context, leilaoID uint, req *models.RegistrarVendaRequest) (*models.HabilitacaoLeilao, error) {
+	var habilitacao *models.HabilitacaoLeilao
+	var err error
+	switch {
+	case req.Paleta != nil:
+		habilitacao, err = s.repo.FindHabilitacaoPorPaleta(ctx, leilaoID, *req.Paleta)
+		if err == nil && req.CompradorID != 0 && req.CompradorID != habilitacao.UsuarioID {
+			return nil, &apperrors.ValidationError{Field: "comprador_id", Message: "a paleta informada pertence a outro arrematante", Value: *req.Paleta}
+		}
+	case req.CompradorID != 0:
+		habilitacao, err = s.repo.FindHabilitacao(ctx, leilaoID, req.CompradorID)
+	default:
+		return nil, &apperrors.ValidationError{Field: "comprador_id", Message: "informe a paleta ou o comprador"}
+	}
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return nil, &apperrors.ValidationError{Field: "comprador_id", Message: "o comprador não está habilitado neste leilão"}
+		}
+		return nil, err
+	}
+	if habilitacao.Status != models.HabilitacaoAprovada {
+		return nil, &apperrors.ValidationError{Field: "comprador_id", Message: "a habilitação do comprador não está aprovada", Value: habilitacao.Status}
+	}
+	return habilitacao, nil
+}
+
+// exigirLimiteDisponivel o lance somado ao que o arrematante já comprou no leilão não pode passar do limite aprovado.
+// Conferido com a habilitação travada na gravação da venda
+func exigirLimiteDisponivel(habilitacao *models.HabilitacaoLeilao, utilizado, valor float64) error {
+	if habilitacao.Status != models.HabilitacaoAprovada {
+		return &apperrors.ValidationError{Field: "comprador_id", Message: "a habilitação do comprador não está aprovada", Value: habilitacao.Status}
+	}
+	if habilitacao.LimiteAprovado == nil {
+		return &apperrors.ValidationError{Field: "comprador_id", Message: "a habilitação do comprador não tem limite aprovado"}
+	}
+	if disponivel := *habilitacao.LimiteAprovado - utilizado; valor > disponivel {
+		if disponivel < 0 {
+			disponivel = 0
+		}
+		return &apperrors.ValidationError{
+			Field:   "valor_vendido",
+			Message: fmt.Sprintf("lance acima do limite aprovado do arrematante (disponível: %s)", formatValor(disponivel)),
+			Value:   disponivel,
+		}
+	}
+	return nil
+}
+
+// exigirSemComprasPendentes bloqueia quem não pagou lotes arrematados em leilões encerrados há mais que o prazo
+func (s *service) exigirSemComprasPendentes(ctx context.Context, usuarioID, leilaoID uint) error {
+	pendentes, err := s.repo.ComprasPendentes(ctx, usuarioID, leilaoID, time.Now().Add(-s.prazoPagamento))
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.exigirSemComprasPendentes", logging.Fields{"user_id": usuarioID})
+		return err
+	}
+	if pendentes > 0 {
+		return &apperrors.ValidationError{Field: "comprador_id", Message: "o arrematante tem lotes de leilões anteriores com pagamento vencido", Value: pendentes}
+	}
+	return nil
+}
+
+// exigirHabilitacoesAbertas habilitações são aceitas e analisadas até o fim do pregão
+func exigirHabilitacoesAbertas(leilao *models.Leilao) error {
+	if leilao.Status != models.StatusLeilaoAgendado && leilao.Status != models.StatusLeilaoEmAndamento {
+		return &apperrors.ValidationError{Field: "status", Message: "o leilão não aceita mais habilitações", Value: leilao.Status}
+	}
+	if time.Now().After(leilao.DataFim) {
+		return &apperrors.ValidationError{Field: "data_fim", Message: "o horário do leilão já terminou", Value: leilao.DataFim}
+	}
+	return nil
+}
+
+func documentosFaltando(habilitacao *models.HabilitacaoLeilao) []models.TipoDocumentoHabilitacao {
+	enviados := make(map[models.TipoDocumentoHabilitacao]bool, len(habilitacao.Documentos))
+	for _, documento := range habilitacao.Documentos {
+		enviados[documento.Tipo] = true
+	}
+	var faltando []models.TipoDocumentoHabilitacao
+	for _, tipo := range documentosObrigatorios {
+		if !enviados[tipo] {
+			faltando = append(faltando, tipo)
+		}
+	}
+	return faltando
+}
@@ -2,6 +2,8 @@ package leiloes
 
 import (
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 	"time"
@@ -13,15 +15,20 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// multipartOverhead folga para cabeçalhos e campos do formulário além do arquivo
+const multipartOverhead = 1 << 20
+
 type Handler struct {
-	service Service
-	logger  *logging.Logger
+	service       Service
+	maxUploadSize int64
+	logger        *logging.Logger
 }
 
-func NewHandler(service Service, logger *logging.Logger) *Handler {
+func NewHandler(service Service, maxUploadSize int64, logger *logging.Logger) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
+		service:       service,
+		maxUploadSize: maxUploadSize,
+		logger:        logger,
 	}
 }
 
@@ -149,17 +156,22 @@ func (h *Handler) CriarParticipacao(c *gin.Context) {
 
 // AprovarParticipacao godoc
 // @Summary Aprovar participação em leilão
-// @Description Aprova uma participação pendente em um leilão
+// @Description Aprova uma participação pendente em um leilão. Apenas o leiloeiro ou um administrador
 // @Tags Leilões
 // @Produce json
 // @Param id path int true "ID da participação"
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /leiloes/participacoes/{id}/aprovar [post]
 // @Security BearerAuth
 func (h *Handler) AprovarParticipacao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -171,29 +183,9 @@ func (h *Handler) AprovarParticipacao(c *gin.Context) {
 		return
 	}
 
-	participacao, err := h.service.AprovarParticipacao(c.Request.Context(), uint(id))
+	participacao, err := h.service.AprovarParticipacao(c.Request.Context(), uint(id), userID, userType)
 	if err != nil {
-		if apperrors.IsValidation(err) {
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{
-				Success:   false,
-				Error:     err.Error(),
-				Timestamp: time.Now(),
-			})
-			return
-		}
-		if apperrors.IsNotFound(err) {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
-				Success:   false,
-				Error:     err.Error(),
-				Timestamp: time.Now(),
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao aprovar participação",
-			Timestamp: time.Now(),
-		})
+		h.respondError(c, err, "Erro ao aprovar participação")
 		return
 	}
 
@@ -207,7 +199,7 @@ func (h *Handler) AprovarParticipacao(c *gin.Context) {
 
 // RegistrarVenda godoc
 // @Summary Registrar venda em participação
-// @Description Registra a venda de um equino em uma participação de leilão
+// @Description Registra a venda de um equino em uma participação de leilão. O comprador é identificado pela paleta ou pelo ID e precisa estar habilitado, com limite disponível e sem pagamentos vencidos em outros leilões. Apenas o leiloeiro ou um administrador
 // @Tags Leilões
 // @Accept json
 // @Produce json
@@ -215,11 +207,16 @@ func (h *Handler) AprovarParticipacao(c *gin.Context) {
 // @Param venda body models.RegistrarVendaRequest true "Dados da venda"
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /leiloes/participacoes/{id}/venda [post]
 // @Security BearerAuth
 func (h *Handler) RegistrarVenda(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -241,29 +238,9 @@ func (h *Handler) RegistrarVenda(c *gin.Context) {
 		return
 	}
 
-	participacao, err := h.service.RegistrarVenda(c.Request.Context(), uint(id), &req)
+	participacao, err := h.service.RegistrarVenda(c.Request.Context(), uint(id), userID, userType, &req)
 	if err != nil {
-		if apperrors.IsValidation(err) {
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{
-				Success:   false,
-				Error:     err.Error(),
-				Timestamp: time.Now(),
-			})
-			return
-		}
-		if apperrors.IsNotFound(err) {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
-				Success:   false,
-				Error:     err.Error(),
-				Timestamp: time.Now(),
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao registrar venda",
-			Timestamp: time.Now(),
-		})
+		h.respondError(c, err, "Erro ao registrar venda")
 		return
 	}
 
@@ -277,17 +254,22 @@ func (h *Handler) RegistrarVenda(c *gin.Context) {
 
 // MarcarAusencia godoc
 // @Summary Marcar ausência em leilão
-// @Description Marca um participante como ausente no leilão
+// @Description Marca um participante como ausente no leilão. Apenas o leiloeiro ou um administrador
 // @Tags Leilões
 // @Produce json
 // @Param id path int true "ID da participação"
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /leiloes/participacoes/{id}/ausencia [post]
 // @Security BearerAuth
 func (h *Handler) MarcarAusencia(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -299,29 +281,9 @@ func (h *Handler) MarcarAusencia(c *gin.Context) {
 		return
 	}
 
-	participacao, err := h.service.MarcarAusencia(c.Request.Context(), uint(id))
+	participacao, err := h.service.MarcarAusencia(c.Request.Context(), uint(id), userID, userType)
 	if err != nil {
-		if apperrors.IsValidation(err) {
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{
-				Success:   false,
-				Error:     err.Error(),
-				Timestamp: time.Now(),
-			})
-			return
-		}
-		if apperrors.IsNotFound(err) {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
-				Success:   false,
-				Error:     err.Error(),
-				Timestamp: time.Now(),
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao marcar ausência",
-			Timestamp: time.Now(),
-		})
+		h.respondError(c, err, "Erro ao marcar ausência")
 		return
 	}
 
@@ -335,17 +297,22 @@ func (h *Handler) MarcarAusencia(c *gin.Context) {
 
 // MarcarPresenca godoc
 // @Summary Marcar presença em leilão
-// @Description Marca um participante como presente no leilão
+// @Description Marca um participante como presente no leilão. Apenas o leiloeiro ou um administrador
 // @Tags Leilões
 // @Produce json
 // @Param id path int true "ID da participação"
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /leiloes/participacoes/{id}/presenca [post]
 // @Security BearerAuth
 func (h *Handler) MarcarPresenca(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -357,36 +324,981 @@ func (h *Handler) MarcarPresenca(c *gin.Context) {
 		return
 	}
 
-	participacao, err := h.service.MarcarPresenca(c.Request.Context(), uint(id))
+	participacao, err := h.service.MarcarPresenca(c.Request.Context(), uint(id), userID, userType)
 	if err != nil {
-		if apperrors.IsValidation(err) {
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{
-				Success:   false,
-				Error:     err.Error(),
-				Timestamp: time.Now(),
-			})
+		h.respondError(c, err, "Erro ao marcar presença")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Presença confirmada",
+		Timestamp: time.Now(),
+		Data:      participacao,
+	})
+}
+
+// ListLotes godoc
+// @Summary Listar lotes do leilão
+// @Description Sessões, lotes na ordem de apresentação e participações aprovadas ainda sem número de lote
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/lotes [get]
+// @Security BearerAuth
+func (h *Handler) ListLotes(c *gin.Context) {
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	lotes, err := h.service.ListLotes(c.Request.Context(), leilaoID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar lotes")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Lotes do leilão (total: %d)", len(lotes.Lotes)),
+		Timestamp: time.Now(),
+		Data:      lotes,
+	})
+}
+
+// OrganizarLotes godoc
+// @Summary Organizar lotes do leilão
+// @Description Define as sessões e a ordem completa dos lotes; os números são atribuídos em sequência. Apenas o leiloeiro ou um administrador, antes do início do leilão
+// @Tags Leilões
+// @Accept json
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Param request body models.OrganizarLotesRequest true "Sessões e ordem dos lotes"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/lotes [put]
+// @Security BearerAuth
+func (h *Handler) OrganizarLotes(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.OrganizarLotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	lotes, err := h.service.OrganizarLotes(c.Request.Context(), leilaoID, userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao organizar lotes")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Lotes organizados com sucesso",
+		Timestamp: time.Now(),
+		Data:      lotes,
+	})
+}
+
+// GetCatalogo godoc
+// @Summary Consultar catálogo do leilão
+// @Description Última versão publicada do catálogo. Com rascunho=true, o leiloeiro ou um administrador vê o catálogo montado com os dados atuais, sem publicar
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Param rascunho query bool false "Montar o catálogo com os dados atuais"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/catalogo [get]
+// @Security BearerAuth
+func (h *Handler) GetCatalogo(c *gin.Context) {
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("rascunho") == "true" {
+		userID, userType, ok := h.requireUser(c)
+		if !ok {
 			return
 		}
-		if apperrors.IsNotFound(err) {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
-				Success:   false,
-				Error:     err.Error(),
-				Timestamp: time.Now(),
-			})
+		catalogo, err := h.service.RascunhoCatalogo(c.Request.Context(), leilaoID, userID, userType)
+		if err != nil {
+			h.respondError(c, err, "Erro ao montar rascunho do catálogo")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success:   false,
-			Error:     "Erro ao marcar presença",
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success:   true,
+			Message:   "Rascunho do catálogo",
 			Timestamp: time.Now(),
+			Data:      catalogo,
 		})
 		return
 	}
 
+	h.respondCatalogo(c, leilaoID, 0)
+}
+
+// GetVersaoCatalogo godoc
+// @Summary Consultar versão do catálogo
+// @Description Conteúdo de uma versão publicada do catálogo, exatamente como foi publicada
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Param versao path int true "Versão do catálogo"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/catalogo/versoes/{versao} [get]
+// @Security BearerAuth
+func (h *Handler) GetVersaoCatalogo(c *gin.Context) {
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+	versao, ok := h.parseVersao(c)
+	if !ok {
+		return
+	}
+	h.respondCatalogo(c, leilaoID, versao)
+}
+
+// ListVersoesCatalogo godoc
+// @Summary Listar versões do catálogo
+// @Description Versões publicadas do catálogo, da mais recente para a mais antiga, com o motivo de cada errata
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/catalogo/versoes [get]
+// @Security BearerAuth
+func (h *Handler) ListVersoesCatalogo(c *gin.Context) {
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	versoes, err := h.service.ListVersoesCatalogo(c.Request.Context(), leilaoID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar versões do catálogo")
+		return
+	}
+
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success:   true,
-		Message:   "Presença confirmada",
+		Message:   fmt.Sprintf("Versões do catálogo (total: %d)", len(versoes)),
+		Timestamp: time.Now(),
+		Data:      versoes,
+	})
+}
+
+// DownloadCatalogo godoc
+// @Summary Baixar catálogo em PDF
+// @Description PDF da última versão publicada do catálogo
+// @Tags Leilões
+// @Produce application/pdf
+// @Param leilao_id path int true "ID do leilão"
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/catalogo/pdf [get]
+// @Security BearerAuth
+func (h *Handler) DownloadCatalogo(c *gin.Context) {
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+	h.respondCatalogoPDF(c, leilaoID, 0)
+}
+
+// DownloadVersaoCatalogo godoc
+// @Summary Baixar versão do catálogo em PDF
+// @Description PDF de uma versão publicada do catálogo, exatamente como foi publicado
+// @Tags Leilões
+// @Produce application/pdf
+// @Param leilao_id path int true "ID do leilão"
+// @Param versao path int true "Versão do catálogo"
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/catalogo/versoes/{versao}/pdf [get]
+// @Security BearerAuth
+func (h *Handler) DownloadVersaoCatalogo(c *gin.Context) {
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+	versao, ok := h.parseVersao(c)
+	if !ok {
+		return
+	}
+	h.respondCatalogoPDF(c, leilaoID, versao)
+}
+
+// PublicarCatalogo godoc
+// @Summary Publicar catálogo do leilão
+// @Description Congela o catálogo atual em uma nova versão (JSON e PDF). A partir da segunda versão o motivo da errata é obrigatório. Apenas o leiloeiro ou um administrador, antes do início do leilão
+// @Tags Leilões
+// @Accept json
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Param request body models.PublicarCatalogoRequest false "Motivo da nova versão"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/catalogo/publicar [post]
+// @Security BearerAuth
+func (h *Handler) PublicarCatalogo(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.PublicarCatalogoRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success:   false,
+				Error:     "Dados inválidos: " + err.Error(),
+				Timestamp: time.Now(),
+			})
+			return
+		}
+	}
+
+	catalogo, err := h.service.PublicarCatalogo(c.Request.Context(), leilaoID, userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao publicar catálogo")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Catálogo publicado na versão %d", catalogo.Versao),
+		Timestamp: time.Now(),
+		Data:      catalogo,
+	})
+}
+
+// IniciarLeilao godoc
+// @Summary Iniciar leilão
+// @Description Abre o pregão antes do horário agendado; no horário de início a transição é automática. Inscrições não aprovadas são canceladas. Apenas o leiloeiro ou um administrador
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/iniciar [post]
+// @Security BearerAuth
+func (h *Handler) IniciarLeilao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	leilao, err := h.service.IniciarLeilao(c.Request.Context(), leilaoID, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao iniciar leilão")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Leilão iniciado",
+		Timestamp: time.Now(),
+		Data:      leilao,
+	})
+}
+
+// EncerrarLeilao godoc
+// @Summary Encerrar leilão
+// @Description Fecha o pregão antes do término agendado; no horário de término a transição é automática. Lotes sem venda ficam como não vendidos e os totais são gravados. Retorna o relatório de liquidação
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/encerrar [post]
+// @Security BearerAuth
+func (h *Handler) EncerrarLeilao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	relatorio, err := h.service.EncerrarLeilao(c.Request.Context(), leilaoID, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao encerrar leilão")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Leilão encerrado",
+		Timestamp: time.Now(),
+		Data:      relatorio,
+	})
+}
+
+// CancelarLeilao godoc
+// @Summary Cancelar leilão
+// @Description Cancela um leilão agendado ou em andamento sem lotes vendidos; as participações em aberto são canceladas
+// @Tags Leilões
+// @Accept json
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Param request body models.CancelarLeilaoRequest true "Motivo do cancelamento"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/cancelar [post]
+// @Security BearerAuth
+func (h *Handler) CancelarLeilao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CancelarLeilaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	leilao, err := h.service.CancelarLeilao(c.Request.Context(), leilaoID, userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao cancelar leilão")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Leilão cancelado",
+		Timestamp: time.Now(),
+		Data:      leilao,
+	})
+}
+
+// GetLiquidacao godoc
+// @Summary Relatório de liquidação do leilão
+// @Description Totais arrecadados e de comissões, lotes vendidos e não vendidos e ausências penalizadas de um leilão encerrado
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/liquidacao [get]
+// @Security BearerAuth
+func (h *Handler) GetLiquidacao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	relatorio, err := h.service.GetLiquidacao(c.Request.Context(), leilaoID, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao gerar relatório de liquidação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Relatório de liquidação do leilão",
+		Timestamp: time.Now(),
+		Data:      relatorio,
+	})
+}
+
+// SolicitarHabilitacao godoc
+// @Summary Solicitar habilitação para arrematar
+// @Description Pede a habilitação do usuário como arrematante do leilão, informando o limite de compra desejado. Uma solicitação rejeitada ou cancelada volta para análise
+// @Tags Leilões
+// @Accept json
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Param habilitacao body models.SolicitarHabilitacaoRequest true "Limite solicitado"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/habilitacao [post]
+// @Security BearerAuth
+func (h *Handler) SolicitarHabilitacao(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SolicitarHabilitacaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	habilitacao, err := h.service.SolicitarHabilitacao(c.Request.Context(), leilaoID, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao solicitar habilitação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Habilitação enviada para análise",
+		Timestamp: time.Now(),
+		Data:      habilitacao,
+	})
+}
+
+// GetMinhaHabilitacao godoc
+// @Summary Consultar minha habilitação
+// @Description Situação da habilitação do usuário no leilão, com documentos enviados, paleta e limite utilizado
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/habilitacao [get]
+// @Security BearerAuth
+func (h *Handler) GetMinhaHabilitacao(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	habilitacao, err := h.service.GetMinhaHabilitacao(c.Request.Context(), leilaoID, userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar habilitação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Habilitação encontrada",
+		Timestamp: time.Now(),
+		Data:      habilitacao,
+	})
+}
+
+// CancelarHabilitacao godoc
+// @Summary Cancelar habilitação
+// @Description Desiste de uma habilitação ainda em análise ou aguardando caução
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/habilitacao [delete]
+// @Security BearerAuth
+func (h *Handler) CancelarHabilitacao(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	habilitacao, err := h.service.CancelarHabilitacao(c.Request.Context(), leilaoID, userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao cancelar habilitação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Habilitação cancelada",
+		Timestamp: time.Now(),
+		Data:      habilitacao,
+	})
+}
+
+// EnviarDocumentoHabilitacao godoc
+// @Summary Enviar documento da habilitação
+// @Description Anexa um documento (PDF, JPEG ou PNG) à habilitação pendente. Identidade e comprovante de endereço são obrigatórios para a aprovação
+// @Tags Leilões
+// @Accept multipart/form-data
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Param tipo formData string true "Tipo do documento" Enums(documento_identidade, comprovante_endereco, comprovante_renda, referencia_bancaria, contrato_social, outro)
+// @Param arquivo formData file true "Documento"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/habilitacao/documentos [post]
+// @Security BearerAuth
+func (h *Handler) EnviarDocumentoHabilitacao(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	arquivo, ok := h.readFile(c)
+	if !ok {
+		return
+	}
+	tipo := models.TipoDocumentoHabilitacao(c.PostForm("tipo"))
+
+	documento, err := h.service.EnviarDocumentoHabilitacao(c.Request.Context(), leilaoID, userID, tipo, arquivo)
+	if err != nil {
+		h.respondError(c, err, "Erro ao enviar documento")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Documento anexado à habilitação",
+		Timestamp: time.Now(),
+		Data:      documento,
+	})
+}
+
+// ListHabilitacoes godoc
+// @Summary Listar habilitações do leilão
+// @Description Fila de análise de arrematantes do leilão; restrito ao leiloeiro e administradores
+// @Tags Leilões
+// @Produce json
+// @Param leilao_id path int true "ID do leilão"
+// @Param status query string false "Filtrar por status" Enums(pendente, aguardando_caucao, aprovada, rejeitada, cancelada)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /leiloes/{leilao_id}/habilitacoes [get]
+// @Security BearerAuth
+func (h *Handler) ListHabilitacoes(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	leilaoID, ok := h.parseLeilaoID(c)
+	if !ok {
+		return
+	}
+
+	status := models.StatusHabilitacao(c.Query("status"))
+	habilitacoes, err := h.service.ListHabilitacoes(c.Request.Context(), leilaoID, userID, userType, status)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar habilitações")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Habilitações do leilão",
+		Timestamp: time.Now(),
+		Data:      habilitacoes,
+	})
+}
+
+// GetHabilitacao godoc
+// @Summary Detalhar habilitação
+// @Description Habilitação com documentos, limite utilizado e compras pendentes em outros leilões; visível ao leiloeiro e ao próprio arrematante
+// @Tags Leilões
+// @Produce json
+// @Param id path int true "ID da habilitação"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /leiloes/habilitacoes/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetHabilitacao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseHabilitacaoID(c)
+	if !ok {
+		return
+	}
+
+	habilitacao, err := h.service.GetHabilitacao(c.Request.Context(), id, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar habilitação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Habilitação encontrada",
+		Timestamp: time.Now(),
+		Data:      habilitacao,
+	})
+}
+
+// AprovarHabilitacao godoc
+// @Summary Aprovar habilitação
+// @Description Concede o limite de compra ao arrematante. Com limite de crédito a habilitação vale imediatamente e recebe a paleta; com caução aguarda a confirmação do depósito
+// @Tags Leilões
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da habilitação"
+// @Param aprovacao body models.AprovarHabilitacaoRequest true "Garantia e limite aprovado"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /leiloes/habilitacoes/{id}/aprovar [post]
+// @Security BearerAuth
+func (h *Handler) AprovarHabilitacao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseHabilitacaoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AprovarHabilitacaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	habilitacao, err := h.service.AprovarHabilitacao(c.Request.Context(), id, userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao aprovar habilitação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Habilitação aprovada",
+		Timestamp: time.Now(),
+		Data:      habilitacao,
+	})
+}
+
+// RejeitarHabilitacao godoc
+// @Summary Rejeitar habilitação
+// @Tags Leilões
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da habilitação"
+// @Param rejeicao body models.RejeitarHabilitacaoRequest true "Motivo da rejeição"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /leiloes/habilitacoes/{id}/rejeitar [post]
+// @Security BearerAuth
+func (h *Handler) RejeitarHabilitacao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseHabilitacaoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RejeitarHabilitacaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	habilitacao, err := h.service.RejeitarHabilitacao(c.Request.Context(), id, userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao rejeitar habilitação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Habilitação rejeitada",
+		Timestamp: time.Now(),
+		Data:      habilitacao,
+	})
+}
+
+// ConfirmarCaucao godoc
+// @Summary Confirmar caução da habilitação
+// @Description Registra o depósito da caução e conclui a habilitação, atribuindo a paleta do arrematante
+// @Tags Leilões
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da habilitação"
+// @Param caucao body models.ConfirmarCaucaoRequest true "Referência do depósito"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /leiloes/habilitacoes/{id}/caucao [post]
+// @Security BearerAuth
+func (h *Handler) ConfirmarCaucao(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseHabilitacaoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConfirmarCaucaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	habilitacao, err := h.service.ConfirmarCaucao(c.Request.Context(), id, userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao confirmar caução")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Caução confirmada; arrematante habilitado",
+		Timestamp: time.Now(),
+		Data:      habilitacao,
+	})
+}
+
+func (h *Handler) respondCatalogo(c *gin.Context, leilaoID uint, versao int) {
+	catalogo, err := h.service.GetCatalogo(c.Request.Context(), leilaoID, versao)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar catálogo")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Catálogo do leilão (versão %d)", catalogo.Versao),
+		Timestamp: time.Now(),
+		Data:      catalogo,
+	})
+}
+
+func (h *Handler) respondCatalogoPDF(c *gin.Context, leilaoID uint, versao int) {
+	reader, catalogo, err := h.service.OpenCatalogoPDF(c.Request.Context(), leilaoID, versao)
+	if err != nil {
+		h.respondError(c, err, "Erro ao baixar catálogo")
+		return
+	}
+	defer reader.Close()
+
+	filename := fmt.Sprintf("catalogo-leilao-%d-v%d.pdf", leilaoID, catalogo.Versao)
+	c.DataFromReader(http.StatusOK, catalogo.Tamanho, "application/pdf", reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": filename}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, string, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, "", false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	return userID, userType, true
+}
+
+func (h *Handler) parseLeilaoID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("leilao_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID de leilão inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *Handler) parseHabilitacaoID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID de habilitação inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// readFile lê o campo multipart "arquivo" respeitando o limite de tamanho de upload
+func (h *Handler) readFile(c *gin.Context) (*models.ArquivoEnviado, bool) {
+	header, err := c.FormFile("arquivo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Arquivo não enviado ou maior que o limite permitido",
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+	if header.Size > h.maxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Arquivo excede o limite de %d bytes", h.maxUploadSize),
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.respondError(c, err, "Erro ao ler arquivo enviado")
+		return nil, false
+	}
+	defer file.Close()
+
+	conteudo, err := io.ReadAll(io.LimitReader(file, h.maxUploadSize+1))
+	if err != nil {
+		h.respondError(c, err, "Erro ao ler arquivo enviado")
+		return nil, false
+	}
+	return &models.ArquivoEnviado{Nome: header.Filename, Conteudo: conteudo}, true
+}
+
+func (h *Handler) parseVersao(c *gin.Context) (int, bool) {
+	versao, err := strconv.Atoi(c.Param("versao"))
+	if err != nil || versao < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Versão do catálogo inválida",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return versao, true
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	case apperrors.IsBusiness(err):
+		status = http.StatusServiceUnavailable
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
 		Timestamp: time.Now(),
-		Data:      participacao,
 	})
 }
@@ -0,0 +1,433 @@
+package leiloes
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/pkg/pdf"
+)
+
+const (
+	margem         = 30.0
+	larguraUtil    = pdf.A4Width - 2*margem
+	corpoTexto     = 9.0
+	corpoRotulo    = 7.0
+	alturaSecao    = 16.0
+	alturaLinha    = corpoTexto + 4
+	limiteRodape   = margem + 24
+	formatoData    = "02/01/2006"
+	textoAusente   = "—"
+	tituloDoc      = "CATÁLOGO DE LEILÃO"
+	nomePlataforma = "EQUINOID"
+	textoRodape    = "Valores iniciais sujeitos às condições do leilão. O catálogo publicado não é alterado; correções saem em nova versão."
+)
+
+var (
+	corPrimaria = pdf.Color{R: 0.11, G: 0.27, B: 0.22}
+	corSuave    = pdf.Color{R: 0.93, G: 0.95, B: 0.94}
+	corBorda    = pdf.Color{R: 0.70, G: 0.75, B: 0.73}
+	corRotulo   = pdf.Color{R: 0.40, G: 0.40, B: 0.40}
+)
+
+// renderCatalogo monta o PDF: capa com os dados do leilão e o índice de lotes por sessão, seguida de uma página por
+// lote com foto, identificação, pedigree de três gerações, destaques e situação sanitária
+func renderCatalogo(c *models.ConteudoCatalogo, capas map[int][]byte) []byte {
+	doc := pdf.New(pdf.Info{
+		Title:    fmt.Sprintf("Catálogo - %s (versão %d)", c.Nome, c.Versao),
+		Author:   nomePlataforma,
+		Subject:  tituloDoc,
+		Creator:  nomePlataforma,
+		Created:  c.GeradoEm,
+		Keywords: []string{fmt.Sprintf("leilao-%d", c.LeilaoID), fmt.Sprintf("v%d", c.Versao)},
+	})
+
+	drawIndice(doc, c)
+	for _, sessao := range c.Sessoes {
+		for i := range sessao.Lotes {
+			drawLote(doc, c, &sessao, &sessao.Lotes[i], capas[sessao.Lotes[i].Numero])
+		}
+	}
+	return doc.Bytes()
+}
+
+// drawIndice capa do catálogo; o índice continua nas páginas seguintes quando não cabe
+func drawIndice(doc *pdf.Document, c *models.ConteudoCatalogo) {
+	page := doc.AddPage()
+	top := page.Height() - margem
+	page.FillRect(margem, top-64, larguraUtil, 64, corPrimaria)
+	page.Text(margem+14, top-24, pdf.Helvetica, 9, pdf.White, tituloDoc)
+	page.Text(margem+14, top-44, pdf.HelveticaBold, 16, pdf.White, pdf.Truncate(c.Nome, pdf.HelveticaBold, 16, larguraUtil-120))
+	page.TextRight(margem+larguraUtil-14, top-24, pdf.HelveticaBold, 10, pdf.White, nomePlataforma)
+	page.TextRight(margem+larguraUtil-14, top-38, pdf.Helvetica, 7, pdf.White, fmt.Sprintf("Versão %d", c.Versao))
+	drawRodape(page, c)
+	y := top - 76
+
+	y = drawTituloSecao(page, "Leilão", y)
+	comissao := fmt.Sprintf("%s%%", formatNumero(c.TaxaComissaoPercentual))
+	if c.TaxaFixa != nil {
+		comissao += " + " + formatValor(*c.TaxaFixa)
+	}
+	y = drawCampos(page, margem+4, larguraUtil-8, y, [][2]string{
+		{"Início", c.DataInicio.Format(formatoData + " 15:04")},
+		{"Encerramento", c.DataFim.Format(formatoData + " 15:04")},
+		{"Local", c.Local},
+		{"Modalidade", string(c.TipoLeilao)},
+		{"Leiloeiro", c.Leiloeiro},
+		{"Comissão", comissao},
+	})
+	if strings.TrimSpace(c.Descricao) != "" {
+		y = drawParagrafo(page, c.Descricao, y, 6)
+	}
+
+	y = drawTituloSecao(page, fmt.Sprintf("Lotes (%d)", c.TotalLotes), y)
+	colunas := []struct {
+		titulo  string
+		largura float64
+	}{
+		{"LOTE", larguraUtil * 0.08},
+		{"NOME", larguraUtil * 0.30},
+		{"EQUINOID", larguraUtil * 0.18},
+		{"RAÇA", larguraUtil * 0.16},
+		{"SEXO", larguraUtil * 0.10},
+		{"VALOR INICIAL", larguraUtil * 0.18},
+	}
+	cabecalho := func(y float64) float64 {
+		x := margem + 4
+		y -= corpoRotulo + 4
+		for _, col := range colunas {
+			page.Text(x, y, pdf.HelveticaBold, corpoRotulo, corRotulo, col.titulo)
+			x += col.largura
+		}
+		y -= 4
+		page.Line(margem, y, margem+larguraUtil, y, 0.5, corBorda)
+		return y
+	}
+	y = cabecalho(y)
+
+	for _, sessao := range c.Sessoes {
+		if len(sessao.Lotes) == 0 {
+			continue
+		}
+		for i, lote := range sessao.Lotes {
+			// A sessão não fica sozinha no fim da página
+			necessario := alturaLinha
+			if i == 0 {
+				necessario += alturaLinha + 4
+			}
+			if y-necessario < limiteRodape {
+				page = doc.AddPage()
+				drawRodape(page, c)
+				y = cabecalho(page.Height() - margem)
+			}
+			if i == 0 {
+				y -= alturaLinha + 4
+				page.Text(margem+4, y, pdf.HelveticaBold, corpoTexto, corPrimaria, tituloSessao(&sessao))
+			}
+
+			y -= alturaLinha
+			valores := []string{formatNumeroLote(lote.Numero), lote.Nome, lote.Equinoid, lote.Raca, formatSexo(lote.Sexo), formatValor(lote.ValorInicial)}
+			x := margem + 4
+			for j, col := range colunas {
+				valor := valores[j]
+				if valor == "" {
+					valor = textoAusente
+				}
+				font := pdf.Helvetica
+				if j == 0 {
+					font = pdf.HelveticaBold
+				}
+				page.Text(x, y, font, corpoTexto-1, pdf.Black, pdf.Truncate(valor, font, corpoTexto-1, col.largura-6))
+				x += col.largura
+			}
+		}
+	}
+}
+
+func drawLote(doc *pdf.Document, c *models.ConteudoCatalogo, sessao *models.SessaoCatalogo, lote *models.LoteCatalogo, foto []byte) {
+	page := doc.AddPage()
+	drawRodape(page, c)
+
+	top := page.Height() - margem
+	page.FillRect(margem, top-44, larguraUtil, 44, corPrimaria)
+	page.Text(margem+14, top-28, pdf.HelveticaBold, 18, pdf.White, "LOTE "+formatNumeroLote(lote.Numero))
+	page.TextRight(margem+larguraUtil-14, top-20, pdf.HelveticaBold, 9, pdf.White, pdf.Truncate(c.Nome, pdf.HelveticaBold, 9, larguraUtil/2))
+	page.TextRight(margem+larguraUtil-14, top-34, pdf.Helvetica, 8, pdf.White, tituloSessao(sessao))
+	y := top - 56
+
+	y = drawTituloSecao(page, "Identificação", y)
+	const fotoLargura, fotoAltura = 170.0, 150.0
+	fotoY := y - fotoAltura
+	page.Rect(margem, fotoY, fotoLargura, fotoAltura, 0.75, corBorda)
+	if img := embedFoto(doc, foto); img != nil {
+		w, h := fitInside(float64(img.Width()), float64(img.Height()), fotoLargura-4, fotoAltura-4)
+		page.DrawImage(img, margem+2+(fotoLargura-4-w)/2, fotoY+2+(fotoAltura-4-h)/2, w, h)
+	} else {
+		page.TextCentered(margem+fotoLargura/2, fotoY+fotoAltura/2, pdf.Helvetica, corpoRotulo, corRotulo, "Sem foto")
+	}
+
+	x0 := margem + fotoLargura + 16
+	drawCampos(page, x0, margem+larguraUtil-x0, y, [][2]string{
+		{"Nome", lote.Nome},
+		{"EquinoId", lote.Equinoid},
+		{"Sexo", formatSexo(lote.Sexo)},
+		{"Nascimento", formatDataPtr(lote.DataNascimento)},
+		{"Raça", lote.Raca},
+		{"Pelagem", lote.Pelagem},
+		{"Vendedor", lote.Vendedor},
+		{"Pontos de valorização", fmt.Sprintf("%d", lote.PontosValorizacao)},
+		{"Valor inicial", formatValor(lote.ValorInicial)},
+	})
+	y = fotoY - 12
+
+	if strings.TrimSpace(lote.Particularidades) != "" {
+		y = drawTituloSecao(page, "Particularidades", y)
+		y = drawParagrafo(page, lote.Particularidades, y, 4)
+	}
+
+	y = drawPedigree(page, lote.Pedigree, y)
+	y = drawDestaques(page, lote.Destaques, y)
+	drawSanidade(page, &lote.Sanidade, y)
+}
+
+// drawCampos rótulos e valores em duas colunas
+func drawCampos(page *pdf.Page, x0, largura, y float64, campos [][2]string) float64 {
+	colWidth := largura / 2
+	for i, campo := range campos {
+		x := x0 + float64(i%2)*colWidth
+		linha := y - 4 - float64(i/2)*28
+		valor := campo[1]
+		if valor == "" {
+			valor = textoAusente
+		}
+		page.Text(x, linha-corpoRotulo, pdf.Helvetica, corpoRotulo, corRotulo, strings.ToUpper(campo[0]))
+		page.Text(x, linha-corpoRotulo-12, pdf.HelveticaBold, corpoTexto+1, pdf.Black, pdf.Truncate(valor, pdf.HelveticaBold, corpoTexto+1, colWidth-8))
+	}
+	return y - 4 - float64((len(campos)+1)/2)*28 - 8
+}
+
+func drawParagrafo(page *pdf.Page, texto string, y float64, maxLinhas int) float64 {
+	linhas := pdf.Wrap(strings.TrimSpace(texto), pdf.Helvetica, corpoTexto, larguraUtil-8)
+	if len(linhas) > maxLinhas {
+		linhas = linhas[:maxLinhas]
+		linhas[maxLinhas-1] = pdf.Truncate(linhas[maxLinhas-1]+" ...", pdf.Helvetica, corpoTexto, larguraUtil-8)
+	}
+	for _, linha := range linhas {
+		y -= corpoTexto + 3
+		page.Text(margem+4, y, pdf.Helvetica, corpoTexto, pdf.Black, linha)
+	}
+	return y - 14
+}
+
+// drawPedigree pedigree em colunas, uma por geração, com o pai acima da mãe em cada par
+func drawPedigree(page *pdf.Page, arvore *models.ArvoreGenealogica, y float64) float64 {
+	y = drawTituloSecao(page, fmt.Sprintf("Pedigree (%d gerações)", geracoesCatalogo), y)
+
+	const altura = 176.0
+	colWidth := larguraUtil / geracoesCatalogo
+	var ancestrais *models.Ancestrais
+	if arvore != nil {
+		ancestrais = arvore.Ancestrais
+	}
+	for geracao := 1; geracao <= geracoesCatalogo; geracao++ {
+		slots := 1 << geracao
+		slotHeight := altura / float64(slots)
+		x := margem + float64(geracao-1)*colWidth
+		for i := 0; i < slots; i++ {
+			top := y - float64(i)*slotHeight
+			drawAncestral(page, ancestralAt(ancestrais, geracao, i), i%2 == 0, x+2, top-slotHeight+1, colWidth-4, slotHeight-2)
+		}
+	}
+	return y - altura - 12
+}
+
+func drawAncestral(page *pdf.Page, node *models.AncestralNode, pai bool, x, y, w, h float64) {
+	page.Rect(x, y, w, h, 0.5, corBorda)
+	papel := "Mãe"
+	if pai {
+		papel = "Pai"
+	}
+	nome, equinoid := "Não registrado", ""
+	if node != nil {
+		nome, equinoid = node.Nome, node.Equinoid
+	}
+
+	mid := y + h/2
+	page.Text(x+4, mid+corpoTexto/2+2, pdf.Helvetica, corpoRotulo-1, corRotulo, strings.ToUpper(papel))
+	page.Text(x+4, mid-corpoTexto/2+1, pdf.HelveticaBold, corpoTexto, pdf.Black, pdf.Truncate(nome, pdf.HelveticaBold, corpoTexto, w-8))
+	if equinoid != "" {
+		page.Text(x+4, mid-corpoTexto/2-9, pdf.Helvetica, corpoRotulo, corRotulo, equinoid)
+	}
+}
+
+// ancestralAt ancestral na posição i (0 = mais acima) da geração, seguindo pai (par) e mãe (ímpar) a partir do
+// equino
+func ancestralAt(ancestrais *models.Ancestrais, geracao, i int) *models.AncestralNode {
+	var node *models.AncestralNode
+	for nivel := geracao - 1; nivel >= 0; nivel-- {
+		if ancestrais == nil {
+			return nil
+		}
+		if (i>>nivel)&1 == 0 {
+			node = ancestrais.Pai
+		} else {
+			node = ancestrais.Mae
+		}
+		if node == nil {
+			return nil
+		}
+		ancestrais = node.Ancestrais
+	}
+	return node
+}
+
+func drawDestaques(page *pdf.Page, destaques []models.DestaqueCatalogo, y float64) float64 {
+	y = drawTituloSecao(page, "Destaques", y)
+	if len(destaques) == 0 {
+		page.Text(margem+4, y-corpoTexto-3, pdf.Helvetica, corpoTexto, pdf.Black, "Nenhuma conquista validada.")
+		return y - corpoTexto - 17
+	}
+	for _, d := range destaques {
+		y -= alturaLinha
+		page.Text(margem+4, y, pdf.Helvetica, corpoTexto-1, corRotulo, d.Data.Format(formatoData))
+		titulo := d.Titulo
+		if d.Organizacao != "" {
+			titulo += " · " + d.Organizacao
+		}
+		page.Text(margem+64, y, pdf.HelveticaBold, corpoTexto-1, pdf.Black, pdf.Truncate(titulo, pdf.HelveticaBold, corpoTexto-1, larguraUtil-130))
+		page.TextRight(margem+larguraUtil-4, y, pdf.Helvetica, corpoTexto-1, corRotulo, fmt.Sprintf("%d pts", d.Pontos))
+	}
+	return y - 14
+}
+
+// drawSanidade vacinas à esquerda e exames à direita, até o rodapé
+func drawSanidade(page *pdf.Page, sanidade *models.SanidadeCatalogo, y float64) {
+	y = drawTituloSecao(page, "Situação sanitária", y)
+	metade := larguraUtil / 2
+
+	linhas := func(x float64, titulo string, itens [][2]string, vazio string) {
+		yy := y - corpoRotulo - 4
+		page.Text(x, yy, pdf.HelveticaBold, corpoRotulo, corRotulo, titulo)
+		if len(itens) == 0 {
+			page.Text(x, yy-alturaLinha, pdf.Helvetica, corpoTexto-1, pdf.Black, vazio)
+			return
+		}
+		for _, item := range itens {
+			yy -= alturaLinha
+			if yy < limiteRodape {
+				return
+			}
+			page.Text(x, yy, pdf.Helvetica, corpoTexto-1, pdf.Black, pdf.Truncate(item[0], pdf.Helvetica, corpoTexto-1, metade-90))
+			page.TextRight(x+metade-12, yy, pdf.Helvetica, corpoTexto-1, corRotulo, item[1])
+		}
+	}
+
+	vacinas := make([][2]string, 0, len(sanidade.Vacinas))
+	for _, v := range sanidade.Vacinas {
+		vacinas = append(vacinas, [2]string{fmt.Sprintf("%s (%d doses)", v.Nome, v.Doses), v.UltimaDose.Format(formatoData)})
+	}
+	exames := make([][2]string, 0, len(sanidade.Exames))
+	for _, e := range sanidade.Exames {
+		nome := e.Nome
+		if nome == "" {
+			nome = e.Tipo
+		}
+		exames = append(exames, [2]string{fmt.Sprintf("%s: %s", nome, e.Resultado), e.Data.Format(formatoData)})
+	}
+	linhas(margem+4, "VACINAS (ÚLTIMA DOSE)", vacinas, "Nenhuma vacina registrada.")
+	linhas(margem+metade+4, "EXAMES (MAIS RECENTE POR TIPO)", exames, "Nenhum exame concluído.")
+}
+
+func drawRodape(page *pdf.Page, c *models.ConteudoCatalogo) {
+	page.Line(margem, margem+18, margem+larguraUtil, margem+18, 0.5, corBorda)
+	page.Text(margem, margem+8, pdf.Helvetica, corpoRotulo-1, corRotulo, textoRodape)
+	page.Text(margem, margem, pdf.Helvetica, corpoRotulo-1, corRotulo,
+		fmt.Sprintf("%s · versão %d · gerado em %s UTC", c.Nome, c.Versao, c.GeradoEm.Format(formatoData+" 15:04")))
+}
+
+func drawTituloSecao(page *pdf.Page, titulo string, y float64) float64 {
+	page.FillRect(margem, y-alturaSecao, larguraUtil, alturaSecao, corSuave)
+	page.Text(margem+6, y-alturaSecao+5, pdf.HelveticaBold, corpoTexto, corPrimaria, strings.ToUpper(titulo))
+	return y - alturaSecao - 4
+}
+
+// embedFoto incorpora a foto de capa; formatos sem decodificador (WebP) são omitidos
+func embedFoto(doc *pdf.Document, foto []byte) *pdf.Image {
+	if len(foto) == 0 {
+		return nil
+	}
+	img, err := doc.AddImage(foto)
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+// fitInside dimensões da imagem escalada para caber na caixa mantendo a proporção
+func fitInside(w, h, maxW, maxH float64) (float64, float64) {
+	scale := maxW / w
+	if h*scale > maxH {
+		scale = maxH / h
+	}
+	return w * scale, h * scale
+}
+
+func tituloSessao(sessao *models.SessaoCatalogo) string {
+	titulo := fmt.Sprintf("Sessão %d", sessao.Numero)
+	if sessao.Titulo != "" {
+		titulo += " · " + sessao.Titulo
+	}
+	if sessao.InicioEm != nil {
+		titulo += " · " + sessao.InicioEm.Format(formatoData+" 15:04")
+	}
+	return titulo
+}
+
+func formatNumeroLote(numero int) string {
+	if numero == 0 {
+		return textoAusente
+	}
+	return fmt.Sprintf("%03d", numero)
+}
+
+func formatSexo(sexo models.SexoEquino) string {
+	switch sexo {
+	case models.SexoMacho:
+		return "Macho"
+	case models.SexoFemea:
+		return "Fêmea"
+	}
+	return string(sexo)
+}
+
+func formatDataPtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(formatoData)
+}
+
+// formatValor valor em reais com separador de milhar e vírgula decimal, como em R$ 1.250.000,00
+func formatValor(valor float64) string {
+	centavos := int64(valor*100 + 0.5)
+	return fmt.Sprintf("R$ %s,%02d", agruparMilhar(centavos/100), centavos%100)
+}
+
+// formatNumero percentual com vírgula decimal, sem casas quando inteiro
+func formatNumero(valor float64) string {
+	if valor == float64(int64(valor)) {
+		return fmt.Sprintf("%d", int64(valor))
+	}
+	return strings.Replace(fmt.Sprintf("%.2f", valor), ".", ",", 1)
+}
+
+func agruparMilhar(inteiro int64) string {
+	texto := fmt.Sprint(inteiro)
+	var grupos []string
+	for len(texto) > 3 {
+		grupos = append([]string{texto[len(texto)-3:]}, grupos...)
+		texto = texto[:len(texto)-3]
+	}
+	return strings.Join(append([]string{texto}, grupos...), ".")
+}
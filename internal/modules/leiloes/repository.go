@@ -2,10 +2,12 @@ package leiloes
 
 import (
 	"context"
+	"time"
 
 	"github.com/equinoid/backend/internal/models"
 	apperrors "github.com/equinoid/backend/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
@@ -20,6 +22,38 @@ type Repository interface {
 	CreateParticipacao(ctx context.Context, participacao *models.ParticipacaoLeilao) error
 	UpdateParticipacao(ctx context.Context, participacao *models.ParticipacaoLeilao) error
 	DeleteParticipacao(ctx context.Context, id uint) error
+
+	FindSessoes(ctx context.Context, leilaoID uint) ([]models.SessaoLeilao, error)
+	FindLotes(ctx context.Context, leilaoID uint) ([]*models.ParticipacaoLeilao, error)
+	ProximoLote(ctx context.Context, leilaoID uint) (numero int, sessao int, err error)
+	OrganizarLotes(ctx context.Context, leilaoID uint, sessoes []models.SessaoLeilao, lotes []models.OrdemLoteRequest) error
+
+	FindFotosPublicas(ctx context.Context, equinoID uint, limit int) ([]*models.DocumentoVersao, error)
+	FindVacinas(ctx context.Context, equinoID uint) ([]*models.Evento, error)
+	FindExamesConcluidos(ctx context.Context, equinoid string) ([]*models.ExameLaboratorial, error)
+	FindDestaques(ctx context.Context, equinoid string, limit int) ([]*models.RegistroValorizacao, int, error)
+
+	CreateCatalogo(ctx context.Context, catalogo *models.CatalogoLeilao) error
+	FindCatalogo(ctx context.Context, leilaoID uint, versao int) (*models.CatalogoLeilao, error)
+	ListCatalogos(ctx context.Context, leilaoID uint) ([]*models.CatalogoLeilao, error)
+
+	FindParaIniciar(ctx context.Context, ate time.Time, limit int) ([]*models.Leilao, error)
+	FindParaEncerrar(ctx context.Context, ate time.Time, limit int) ([]*models.Leilao, error)
+	IniciarLeilao(ctx context.Context, leilao *models.Leilao, em time.Time) error
+	EncerrarLeilao(ctx context.Context, leilao *models.Leilao, em time.Time) error
+	CancelarLeilao(ctx context.Context, leilao *models.Leilao, motivo string, em time.Time) error
+
+	FindHabilitacao(ctx context.Context, leilaoID, usuarioID uint) (*models.HabilitacaoLeilao, error)
+	FindHabilitacaoByID(ctx context.Context, id uint) (*models.HabilitacaoLeilao, error)
+	FindHabilitacaoPorPaleta(ctx context.Context, leilaoID uint, paleta int) (*models.HabilitacaoLeilao, error)
+	ListHabilitacoes(ctx context.Context, leilaoID uint, status models.StatusHabilitacao) ([]*models.HabilitacaoLeilao, error)
+	CreateHabilitacao(ctx context.Context, habilitacao *models.HabilitacaoLeilao) error
+	UpdateHabilitacao(ctx context.Context, habilitacao *models.HabilitacaoLeilao) error
+	TransicionarHabilitacao(ctx context.Context, habilitacao *models.HabilitacaoLeilao, de models.StatusHabilitacao, atribuirPaleta bool) error
+	CreateDocumentoHabilitacao(ctx context.Context, documento *models.DocumentoHabilitacao) error
+	TotalArrematado(ctx context.Context, leilaoID, compradorID uint) (float64, error)
+	RegistrarVenda(ctx context.Context, participacao *models.ParticipacaoLeilao, habilitacaoID uint, verificarLimite func(habilitacao *models.HabilitacaoLeilao, utilizado float64) error) error
+	ComprasPendentes(ctx context.Context, compradorID, excetoLeilaoID uint, encerradosAntes time.Time) (int64, error)
 }
 
 type repository struct {
@@ -134,3 +168,536 @@ func (r *repository) DeleteParticipacao(ctx context.Context, id uint) error {
 	}
 	return nil
 }
+
+func (r *repository) FindSessoes(ctx context.Context, leilaoID uint) ([]models.SessaoLeilao, error) {
+	var sessoes []models.SessaoLeilao
+	if err := r.db.WithContext(ctx).Where("leilao_id = ?", leilaoID).Order("numero ASC").Find(&sessoes).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("find_sessoes_leilao", "erro ao buscar sessões do leilão", err)
+	}
+	return sessoes, nil
+}
+
+// FindLotes participações aprovadas ou já apregoadas do leilão, na ordem de apresentação; as ainda sem número de lote
+// vêm por último
+func (r *repository) FindLotes(ctx context.Context, leilaoID uint) ([]*models.ParticipacaoLeilao, error) {
+	var lotes []*models.ParticipacaoLeilao
+	if err := r.db.WithContext(ctx).
+		Preload("Equino").
+		Preload("Criador").
+		Preload("Comprador").
+		Where("leilao_id = ? AND status IN ?", leilaoID, []models.StatusParticipacaoLeilao{
+			models.StatusParticipacaoAprovado,
+			models.StatusParticipacaoVendido,
+			models.StatusParticipacaoNaoVendido,
+		}).
+		Order("numero_lote IS NULL, sessao ASC, numero_lote ASC, id ASC").
+		Find(&lotes).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("find_lotes_leilao", "erro ao buscar lotes do leilão", err)
+	}
+	return lotes, nil
+}
+
+// ProximoLote número seguinte ao último lote e a última sessão, para acrescentar uma participação recém-aprovada ao fim
+// do pregão
+func (r *repository) ProximoLote(ctx context.Context, leilaoID uint) (int, int, error) {
+	var ultimo struct {
+		Numero int
+		Sessao int
+	}
+	err := r.db.WithContext(ctx).Model(&models.ParticipacaoLeilao{}).
+		Select("COALESCE(MAX(numero_lote), 0) AS numero, COALESCE(MAX(sessao), 0) AS sessao").
+		Where("leilao_id = ?", leilaoID).
+		Scan(&ultimo).Error
+	if err != nil {
+		return 0, 0, apperrors.NewDatabaseError("proximo_lote", "erro ao numerar lote", err)
+	}
+	if ultimo.Sessao == 0 {
+		var sessao int
+		err := r.db.WithContext(ctx).Model(&models.SessaoLeilao{}).
+			Select("COALESCE(MAX(numero), 1)").
+			Where("leilao_id = ?", leilaoID).
+			Scan(&sessao).Error
+		if err != nil {
+			return 0, 0, apperrors.NewDatabaseError("proximo_lote", "erro ao numerar lote", err)
+		}
+		ultimo.Sessao = sessao
+	}
+	return ultimo.Numero + 1, ultimo.Sessao, nil
+}
+
+// OrganizarLotes substitui as sessões e renumera os lotes na ordem informada, na mesma transação
+func (r *repository) OrganizarLotes(ctx context.Context, leilaoID uint, sessoes []models.SessaoLeilao, lotes []models.OrdemLoteRequest) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("leilao_id = ?", leilaoID).Delete(&models.SessaoLeilao{}).Error; err != nil {
+			return err
+		}
+		for i := range sessoes {
+			sessoes[i].ID = 0
+			sessoes[i].LeilaoID = leilaoID
+			if err := tx.Create(&sessoes[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		// Libera os números antes de reatribuí-los, para não colidir com o índice único por leilão
+		if err := tx.Model(&models.ParticipacaoLeilao{}).
+			Where("leilao_id = ? AND numero_lote IS NOT NULL", leilaoID).
+			Updates(map[string]interface{}{"numero_lote": nil, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		for i, lote := range lotes {
+			if err := tx.Model(&models.ParticipacaoLeilao{}).
+				Where("id = ? AND leilao_id = ?", lote.ParticipacaoID, leilaoID).
+				Updates(map[string]interface{}{"numero_lote": i + 1, "sessao": lote.Sessao, "updated_at": time.Now()}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return apperrors.NewDatabaseError("organizar_lotes", "erro ao organizar lotes do leilão", err)
+	}
+	return nil
+}
+
+// FindFotosPublicas versão atual das fotos públicas do cofre do equino, a de perfil primeiro
+func (r *repository) FindFotosPublicas(ctx context.Context, equinoID uint, limit int) ([]*models.DocumentoVersao, error) {
+	var fotos []*models.DocumentoVersao
+	err := r.db.WithContext(ctx).
+		Select("documento_versoes.*").
+		Joins("JOIN documentos ON documentos.id = documento_versoes.documento_id AND documentos.versao_atual = documento_versoes.numero").
+		Where("documentos.equino_id = ? AND documentos.categoria IN ? AND documentos.visibilidade = ? AND documentos.deleted_at IS NULL",
+			equinoID, []models.CategoriaDocumento{models.CategoriaFotoPerfil, models.CategoriaFotoGaleria}, models.VisibilidadePublico).
+		Order("documentos.categoria = 'foto_perfil' DESC, documentos.created_at DESC").
+		Limit(limit).
+		Find(&fotos).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("find_fotos_catalogo", "erro ao buscar fotos do equino", err)
+	}
+	return fotos, nil
+}
+
+func (r *repository) FindVacinas(ctx context.Context, equinoID uint) ([]*models.Evento, error) {
+	var vacinas []*models.Evento
+	err := r.db.WithContext(ctx).
+		Where("equino_id = ? AND tipo_evento = ?", equinoID, models.TipoEventoVacina).
+		Order("data_evento DESC").
+		Find(&vacinas).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("find_vacinas_catalogo", "erro ao buscar vacinas do equino", err)
+	}
+	return vacinas, nil
+}
+
+// FindExamesConcluidos exames com resultado, do mais recente para o mais antigo
+func (r *repository) FindExamesConcluidos(ctx context.Context, equinoid string) ([]*models.ExameLaboratorial, error) {
+	var exames []*models.ExameLaboratorial
+	err := r.db.WithContext(ctx).
+		Where("equinoid = ? AND status = ? AND resultado IS NOT NULL", equinoid, "concluido").
+		Order("data_conclusao DESC").
+		Find(&exames).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("find_exames_catalogo", "erro ao buscar exames do equino", err)
+	}
+	return exames, nil
+}
+
+// FindDestaques registros de valorização validados de maior pontuação e o total de pontos validados do equino
+func (r *repository) FindDestaques(ctx context.Context, equinoid string, limit int) ([]*models.RegistroValorizacao, int, error) {
+	validados := []models.StatusValidacao{models.StatusValidado, models.StatusAprovado}
+
+	var destaques []*models.RegistroValorizacao
+	err := r.db.WithContext(ctx).
+		Where("equinoid = ? AND status_validacao IN ?", equinoid, validados).
+		Order("pontos_valorizacao DESC, data_registro DESC").
+		Limit(limit).
+		Find(&destaques).Error
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("find_destaques_catalogo", "erro ao buscar valorização do equino", err)
+	}
+
+	var pontos int
+	err = r.db.WithContext(ctx).Model(&models.RegistroValorizacao{}).
+		Select("COALESCE(SUM(pontos_valorizacao), 0)").
+		Where("equinoid = ? AND status_validacao IN ?", equinoid, validados).
+		Scan(&pontos).Error
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("find_destaques_catalogo", "erro ao somar valorização do equino", err)
+	}
+	return destaques, pontos, nil
+}
+
+// CreateCatalogo grava a versão e a registra no leilão. A versão só avança a partir da última publicada, então duas
+// publicações simultâneas não geram a mesma versão
+func (r *repository) CreateCatalogo(ctx context.Context, catalogo *models.CatalogoLeilao) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Leilao{}).
+			Where("id = ? AND versao_catalogo = ?", catalogo.LeilaoID, catalogo.Versao-1).
+			Updates(map[string]interface{}{
+				"versao_catalogo": catalogo.Versao,
+				"publicado_em":    gorm.Expr("COALESCE(publicado_em, ?)", catalogo.PublicadoEm),
+				"updated_at":      time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &apperrors.ConflictError{Resource: "catalogo_leilao", Message: "outra versão do catálogo foi publicada ao mesmo tempo", Value: catalogo.Versao}
+		}
+		return tx.Create(catalogo).Error
+	})
+	if err != nil {
+		if apperrors.IsConflict(err) {
+			return err
+		}
+		return apperrors.NewDatabaseError("create_catalogo_leilao", "erro ao publicar catálogo", err)
+	}
+	return nil
+}
+
+// FindCatalogo versão publicada do catálogo; versão 0 traz a mais recente
+func (r *repository) FindCatalogo(ctx context.Context, leilaoID uint, versao int) (*models.CatalogoLeilao, error) {
+	var catalogo models.CatalogoLeilao
+	query := r.db.WithContext(ctx).Where("leilao_id = ?", leilaoID)
+	if versao > 0 {
+		query = query.Where("versao = ?", versao)
+	}
+	if err := query.Order("versao DESC").First(&catalogo).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &apperrors.NotFoundError{Resource: "catalogo_leilao", Message: "catálogo do leilão não publicado", ID: leilaoID}
+		}
+		return nil, apperrors.NewDatabaseError("find_catalogo_leilao", "erro ao buscar catálogo", err)
+	}
+	return &catalogo, nil
+}
+
+func (r *repository) ListCatalogos(ctx context.Context, leilaoID uint) ([]*models.CatalogoLeilao, error) {
+	var catalogos []*models.CatalogoLeilao
+	if err := r.db.WithContext(ctx).Where("leilao_id = ?", leilaoID).Order("versao DESC").Find(&catalogos).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("list_catalogos_leilao", "erro ao listar versões do catálogo", err)
+	}
+	return catalogos, nil
+}
+
+// FindParaIniciar leilões agendados cujo início já chegou
+func (r *repository) FindParaIniciar(ctx context.Context, ate time.Time, limit int) ([]*models.Leilao, error) {
+	var leiloes []*models.Leilao
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND data_inicio <= ?", models.StatusLeilaoAgendado, ate).
+		Order("data_inicio ASC").
+		Limit(limit).
+		Find(&leiloes).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("find_leiloes_iniciar", "erro ao buscar leilões a iniciar", err)
+	}
+	return leiloes, nil
+}
+
+// FindParaEncerrar leilões em andamento cujo término já passou
+func (r *repository) FindParaEncerrar(ctx context.Context, ate time.Time, limit int) ([]*models.Leilao, error) {
+	var leiloes []*models.Leilao
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND data_fim <= ?", models.StatusLeilaoEmAndamento, ate).
+		Order("data_fim ASC").
+		Limit(limit).
+		Find(&leiloes).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("find_leiloes_encerrar", "erro ao buscar leilões a encerrar", err)
+	}
+	return leiloes, nil
+}
+
+// IniciarLeilao abre o pregão. Inscrições ainda não aprovadas são canceladas: a lista de lotes fica fechada
+func (r *repository) IniciarLeilao(ctx context.Context, leilao *models.Leilao, em time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := transicionar(tx, leilao.ID, models.StatusLeilaoAgendado, models.StatusLeilaoEmAndamento, map[string]interface{}{
+			"iniciado_em": em,
+		}); err != nil {
+			return err
+		}
+		return tx.Model(&models.ParticipacaoLeilao{}).
+			Where("leilao_id = ? AND status = ?", leilao.ID, models.StatusParticipacaoInscrito).
+			Updates(map[string]interface{}{"status": models.StatusParticipacaoCancelado, "updated_at": em}).Error
+	})
+	if err != nil {
+		if apperrors.IsConflict(err) {
+			return err
+		}
+		return apperrors.NewDatabaseError("iniciar_leilao", "erro ao iniciar leilão", err)
+	}
+	leilao.Status = models.StatusLeilaoEmAndamento
+	leilao.IniciadoEm = &em
+	return nil
+}
+
+// EncerrarLeilao fecha o pregão: lotes aprovados sem venda ficam como não vendidos e os totais de vendas e comissões
+// são gravados no leilão na mesma transação
+func (r *repository) EncerrarLeilao(ctx context.Context, leilao *models.Leilao, em time.Time) error {
+	var totais struct {
+		Arrecadado float64
+		Comissoes  float64
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ParticipacaoLeilao{}).
+			Where("leilao_id = ? AND status = ?", leilao.ID, models.StatusParticipacaoAprovado).
+			Updates(map[string]interface{}{"status": models.StatusParticipacaoNaoVendido, "updated_at": em}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ParticipacaoLeilao{}).
+			Where("leilao_id = ? AND status = ?", leilao.ID, models.StatusParticipacaoInscrito).
+			Updates(map[string]interface{}{"status": models.StatusParticipacaoCancelado, "updated_at": em}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ParticipacaoLeilao{}).
+			Select("COALESCE(SUM(valor_vendido), 0) AS arrecadado, COALESCE(SUM(comissao_leiloeiro), 0) AS comissoes").
+			Where("leilao_id = ? AND status = ?", leilao.ID, models.StatusParticipacaoVendido).
+			Scan(&totais).Error; err != nil {
+			return err
+		}
+		return transicionar(tx, leilao.ID, models.StatusLeilaoEmAndamento, models.StatusLeilaoEncerrado, map[string]interface{}{
+			"encerrado_em":     em,
+			"total_arrecadado": totais.Arrecadado,
+			"total_comissoes":  totais.Comissoes,
+		})
+	})
+	if err != nil {
+		if apperrors.IsConflict(err) {
+			return err
+		}
+		return apperrors.NewDatabaseError("encerrar_leilao", "erro ao encerrar leilão", err)
+	}
+	leilao.Status = models.StatusLeilaoEncerrado
+	leilao.EncerradoEm = &em
+	leilao.TotalArrecadado = &totais.Arrecadado
+	leilao.TotalComissoes = &totais.Comissoes
+	return nil
+}
+
+// CancelarLeilao cancela o leilão e as participações em aberto. Um leilão com lote vendido não pode ser cancelado,
+// só encerrado
+func (r *repository) CancelarLeilao(ctx context.Context, leilao *models.Leilao, motivo string, em time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var vendidos int64
+		if err := tx.Model(&models.ParticipacaoLeilao{}).
+			Where("leilao_id = ? AND status = ?", leilao.ID, models.StatusParticipacaoVendido).
+			Count(&vendidos).Error; err != nil {
+			return err
+		}
+		if vendidos > 0 {
+			return &apperrors.ValidationError{Field: "status", Message: "leilão com lotes vendidos não pode ser cancelado; encerre o pregão", Value: vendidos}
+		}
+		if err := transicionar(tx, leilao.ID, leilao.Status, models.StatusLeilaoCancelado, map[string]interface{}{
+			"encerrado_em":        em,
+			"motivo_cancelamento": motivo,
+		}); err != nil {
+			return err
+		}
+		return tx.Model(&models.ParticipacaoLeilao{}).
+			Where("leilao_id = ? AND status IN ?", leilao.ID, []models.StatusParticipacaoLeilao{
+				models.StatusParticipacaoInscrito,
+				models.StatusParticipacaoAprovado,
+			}).
+			Updates(map[string]interface{}{"status": models.StatusParticipacaoCancelado, "updated_at": em}).Error
+	})
+	if err != nil {
+		if apperrors.IsConflict(err) || apperrors.IsValidation(err) {
+			return err
+		}
+		return apperrors.NewDatabaseError("cancelar_leilao", "erro ao cancelar leilão", err)
+	}
+	leilao.Status = models.StatusLeilaoCancelado
+	leilao.EncerradoEm = &em
+	leilao.MotivoCancelamento = motivo
+	return nil
+}
+
+// transicionar muda o status só se o leilão ainda estiver no status de origem, para que o job e uma ação manual
+// simultâneos não apliquem a mesma transição duas vezes
+func transicionar(tx *gorm.DB, leilaoID uint, de, para models.StatusLeilao, campos map[string]interface{}) error {
+	campos["status"] = para
+	campos["updated_at"] = time.Now()
+	result := tx.Model(&models.Leilao{}).Where("id = ? AND status = ?", leilaoID, de).Updates(campos)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.ConflictError{Resource: "leilao", Message: "o status do leilão foi alterado por outra operação", Value: de}
+	}
+	return nil
+}
+
+func (r *repository) FindHabilitacao(ctx context.Context, leilaoID, usuarioID uint) (*models.HabilitacaoLeilao, error) {
+	return r.findHabilitacao(ctx, "leilao_id = ? AND usuario_id = ?", leilaoID, usuarioID)
+}
+
+func (r *repository) FindHabilitacaoByID(ctx context.Context, id uint) (*models.HabilitacaoLeilao, error) {
+	return r.findHabilitacao(ctx, "id = ?", id)
+}
+
+// FindHabilitacaoPorPaleta arrematante identificado pelo número de paleta no pregão
+func (r *repository) FindHabilitacaoPorPaleta(ctx context.Context, leilaoID uint, paleta int) (*models.HabilitacaoLeilao, error) {
+	return r.findHabilitacao(ctx, "leilao_id = ? AND paleta = ?", leilaoID, paleta)
+}
+
+func (r *repository) findHabilitacao(ctx context.Context, query string, args ...interface{}) (*models.HabilitacaoLeilao, error) {
+	var habilitacao models.HabilitacaoLeilao
+	err := r.db.WithContext(ctx).
+		Preload("Usuario").
+		Preload("Documentos", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where(query, args...).
+		First(&habilitacao).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &apperrors.NotFoundError{Resource: "habilitacao_leilao", Message: "habilitação não encontrada"}
+		}
+		return nil, apperrors.NewDatabaseError("find_habilitacao_leilao", "erro ao buscar habilitação", err)
+	}
+	return &habilitacao, nil
+}
+
+func (r *repository) ListHabilitacoes(ctx context.Context, leilaoID uint, status models.StatusHabilitacao) ([]*models.HabilitacaoLeilao, error) {
+	var habilitacoes []*models.HabilitacaoLeilao
+	query := r.db.WithContext(ctx).Preload("Usuario").Where("leilao_id = ?", leilaoID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("created_at ASC").Find(&habilitacoes).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("list_habilitacoes_leilao", "erro ao listar habilitações", err)
+	}
+	return habilitacoes, nil
+}
+
+func (r *repository) CreateHabilitacao(ctx context.Context, habilitacao *models.HabilitacaoLeilao) error {
+	if err := r.db.WithContext(ctx).Omit("Usuario", "Documentos").Create(habilitacao).Error; err != nil {
+		return apperrors.NewDatabaseError("create_habilitacao_leilao", "erro ao solicitar habilitação", err)
+	}
+	return nil
+}
+
+func (r *repository) UpdateHabilitacao(ctx context.Context, habilitacao *models.HabilitacaoLeilao) error {
+	if err := r.db.WithContext(ctx).Omit("Usuario", "Documentos").Save(habilitacao).Error; err != nil {
+		return apperrors.NewDatabaseError("update_habilitacao_leilao", "erro ao atualizar habilitação", err)
+	}
+	return nil
+}
+
+// TransicionarHabilitacao grava a análise só se a habilitação ainda estiver no status de origem. Na aprovação
+// definitiva o arrematante recebe a próxima paleta livre do leilão
+func (r *repository) TransicionarHabilitacao(ctx context.Context, habilitacao *models.HabilitacaoLeilao, de models.StatusHabilitacao, atribuirPaleta bool) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if atribuirPaleta {
+			var ultima int
+			if err := tx.Model(&models.HabilitacaoLeilao{}).
+				Select("COALESCE(MAX(paleta), 0)").
+				Where("leilao_id = ?", habilitacao.LeilaoID).
+				Scan(&ultima).Error; err != nil {
+				return err
+			}
+			paleta := ultima + 1
+			habilitacao.Paleta = &paleta
+		}
+		habilitacao.UpdatedAt = time.Now()
+		result := tx.Model(&models.HabilitacaoLeilao{}).
+			Where("id = ? AND status = ?", habilitacao.ID, de).
+			Select("status", "garantia", "limite_aprovado", "valor_caucao", "caucao_referencia", "caucao_confirmada_em",
+				"paleta", "motivo_rejeicao", "analisado_por", "analisado_em", "updated_at").
+			Updates(habilitacao)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &apperrors.ConflictError{Resource: "habilitacao_leilao", Message: "a habilitação foi alterada por outra operação", Value: de}
+		}
+		return nil
+	})
+	if err != nil {
+		if atribuirPaleta {
+			habilitacao.Paleta = nil
+		}
+		if apperrors.IsConflict(err) {
+			return err
+		}
+		return apperrors.NewDatabaseError("transicionar_habilitacao_leilao", "erro ao atualizar habilitação", err)
+	}
+	return nil
+}
+
+func (r *repository) CreateDocumentoHabilitacao(ctx context.Context, documento *models.DocumentoHabilitacao) error {
+	if err := r.db.WithContext(ctx).Create(documento).Error; err != nil {
+		return apperrors.NewDatabaseError("create_documento_habilitacao", "erro ao registrar documento", err)
+	}
+	return nil
+}
+
+// TotalArrematado soma dos lotes já arrematados pelo comprador no leilão, abatida do limite aprovado
+func (r *repository) TotalArrematado(ctx context.Context, leilaoID, compradorID uint) (float64, error) {
+	var total float64
+	if err := r.db.WithContext(ctx).Model(&models.ParticipacaoLeilao{}).
+		Select("COALESCE(SUM(valor_vendido), 0)").
+		Where("leilao_id = ? AND comprador_id = ? AND status = ?", leilaoID, compradorID, models.StatusParticipacaoVendido).
+		Scan(&total).Error; err != nil {
+		return 0, apperrors.NewDatabaseError("total_arrematado_leilao", "erro ao somar lotes arrematados", err)
+	}
+	return total, nil
+}
+
+// RegistrarVenda grava a venda com a habilitação do arrematante travada até o commit: a soma dos lotes já
+// arrematados e a conferência do limite valem para a venda gravada, sem que outra venda simultânea ao mesmo
+// arrematante passe pela mesma margem. O lote só é vendido se ainda estiver aprovado
+func (r *repository) RegistrarVenda(ctx context.Context, participacao *models.ParticipacaoLeilao, habilitacaoID uint, verificarLimite func(habilitacao *models.HabilitacaoLeilao, utilizado float64) error) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var habilitacao models.HabilitacaoLeilao
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&habilitacao, habilitacaoID).Error; err != nil {
+			return err
+		}
+		var utilizado float64
+		if err := tx.Model(&models.ParticipacaoLeilao{}).
+			Select("COALESCE(SUM(valor_vendido), 0)").
+			Where("leilao_id = ? AND comprador_id = ? AND status = ?", habilitacao.LeilaoID, habilitacao.UsuarioID, models.StatusParticipacaoVendido).
+			Scan(&utilizado).Error; err != nil {
+			return err
+		}
+		if err := verificarLimite(&habilitacao, utilizado); err != nil {
+			return err
+		}
+
+		participacao.UpdatedAt = time.Now()
+		result := tx.Model(&models.ParticipacaoLeilao{}).
+			Where("id = ? AND status = ?", participacao.ID, models.StatusParticipacaoAprovado).
+			Select("valor_vendido", "valor_final", "comprador_id", "status", "comissao_leiloeiro", "updated_at").
+			Updates(participacao)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &apperrors.ConflictError{Resource: "participacao_leilao", Message: "o lote foi alterado por outra operação", Value: models.StatusParticipacaoAprovado}
+		}
+		return nil
+	})
+	if err != nil {
+		if apperrors.IsValidation(err) || apperrors.IsConflict(err) {
+			return err
+		}
+		return apperrors.NewDatabaseError("registrar_venda_leilao", "erro ao registrar venda", err)
+	}
+	return nil
+}
+
+// ComprasPendentes lotes arrematados em outros leilões, encerrados antes do prazo informado, sem pagamento
+// confirmado. Um pagamento estornado depois de pago conta como quitado: o estorno desfez a venda
+func (r *repository) ComprasPendentes(ctx context.Context, compradorID, excetoLeilaoID uint, encerradosAntes time.Time) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&models.ParticipacaoLeilao{}).
+		Joins("JOIN leilaos ON leilaos.id = participacoes_leiloes.leilao_id").
+		Where("participacoes_leiloes.comprador_id = ? AND participacoes_leiloes.status = ? AND participacoes_leiloes.leilao_id <> ?",
+			compradorID, models.StatusParticipacaoVendido, excetoLeilaoID).
+		Where("COALESCE(leilaos.encerrado_em, leilaos.data_fim) < ?", encerradosAntes).
+		Where(`NOT EXISTS (SELECT 1 FROM pagamentos WHERE pagamentos.origem = ? AND pagamentos.origem_id = participacoes_leiloes.id
+			AND (pagamentos.status IN ? OR (pagamentos.status = ? AND pagamentos.pago_em IS NOT NULL)))`,
+			models.OrigemPagamentoLeilao,
+			[]models.SituacaoPagamento{models.PagamentoEmCustodia, models.PagamentoEmLiberacao, models.PagamentoLiberado},
+			models.PagamentoEstornado).
+		Count(&total).Error
+	if err != nil {
+		return 0, apperrors.NewDatabaseError("compras_pendentes_leilao", "erro ao verificar compras pendentes", err)
+	}
+	return total, nil
+}
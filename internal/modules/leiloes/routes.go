@@ -8,8 +8,29 @@ func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.Ha
 	leiloes := rg.Group("/leiloes")
 	leiloes.Use(authMiddleware)
 	{
+		leiloes.POST("/:leilao_id/iniciar", handler.IniciarLeilao)
+		leiloes.POST("/:leilao_id/encerrar", handler.EncerrarLeilao)
+		leiloes.POST("/:leilao_id/cancelar", handler.CancelarLeilao)
+		leiloes.GET("/:leilao_id/liquidacao", handler.GetLiquidacao)
+
+		leiloes.POST("/:leilao_id/habilitacao", handler.SolicitarHabilitacao)
+		leiloes.GET("/:leilao_id/habilitacao", handler.GetMinhaHabilitacao)
+		leiloes.DELETE("/:leilao_id/habilitacao", handler.CancelarHabilitacao)
+		leiloes.POST("/:leilao_id/habilitacao/documentos", handler.EnviarDocumentoHabilitacao)
+		leiloes.GET("/:leilao_id/habilitacoes", handler.ListHabilitacoes)
+
 		leiloes.GET("/:leilao_id/participacoes", handler.ListParticipacoes)
 		leiloes.POST("/:leilao_id/participacoes", handler.CriarParticipacao)
+
+		leiloes.GET("/:leilao_id/lotes", handler.ListLotes)
+		leiloes.PUT("/:leilao_id/lotes", handler.OrganizarLotes)
+
+		leiloes.GET("/:leilao_id/catalogo", handler.GetCatalogo)
+		leiloes.GET("/:leilao_id/catalogo/pdf", handler.DownloadCatalogo)
+		leiloes.POST("/:leilao_id/catalogo/publicar", handler.PublicarCatalogo)
+		leiloes.GET("/:leilao_id/catalogo/versoes", handler.ListVersoesCatalogo)
+		leiloes.GET("/:leilao_id/catalogo/versoes/:versao", handler.GetVersaoCatalogo)
+		leiloes.GET("/:leilao_id/catalogo/versoes/:versao/pdf", handler.DownloadVersaoCatalogo)
 		
 		participacoes := leiloes.Group("/participacoes")
 		{
@@ -18,5 +39,13 @@ func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.Ha
 			participacoes.POST("/:id/ausencia", handler.MarcarAusencia)
 			participacoes.POST("/:id/presenca", handler.MarcarPresenca)
 		}
+
+		habilitacoes := leiloes.Group("/habilitacoes")
+		{
+			habilitacoes.GET("/:id", handler.GetHabilitacao)
+			habilitacoes.POST("/:id/aprovar", handler.AprovarHabilitacao)
+			habilitacoes.POST("/:id/rejeitar", handler.RejeitarHabilitacao)
+			habilitacoes.POST("/:id/caucao", handler.ConfirmarCaucao)
+		}
 	}
 }
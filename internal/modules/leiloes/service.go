@@ -1,37 +1,100 @@
 package leiloes
 
 import (
+	"bytes"
 	"context"
-
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/config"
 	"github.com/equinoid/backend/internal/models"
 	"github.com/equinoid/backend/internal/modules/equinos"
 	apperrors "github.com/equinoid/backend/pkg/errors"
 	"github.com/equinoid/backend/pkg/logging"
+	"github.com/equinoid/backend/pkg/storage"
 )
 
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+// PedigreeProvider monta a árvore genealógica impressa em cada lote do catálogo
+type PedigreeProvider interface {
+	GetArvoreGenealogica(ctx context.Context, equinoid string, geracoes int) (*models.ArvoreGenealogica, error)
+}
+
 type Service interface {
 	ListAll(ctx context.Context, leiloeiroID *uint) ([]*models.Leilao, error)
 	GetByID(ctx context.Context, id uint) (*models.Leilao, error)
 	
 	ListParticipacoes(ctx context.Context, leilaoID uint) ([]*models.ParticipacaoLeilaoResponse, error)
 	CriarParticipacao(ctx context.Context, leilaoID, criadorID uint, req *models.CreateParticipacaoLeilaoRequest) (*models.ParticipacaoLeilaoResponse, error)
-	AprovarParticipacao(ctx context.Context, participacaoID uint) (*models.ParticipacaoLeilaoResponse, error)
-	RegistrarVenda(ctx context.Context, participacaoID uint, req *models.RegistrarVendaRequest) (*models.ParticipacaoLeilaoResponse, error)
-	MarcarAusencia(ctx context.Context, participacaoID uint) (*models.ParticipacaoLeilaoResponse, error)
-	MarcarPresenca(ctx context.Context, participacaoID uint) (*models.ParticipacaoLeilaoResponse, error)
+	AprovarParticipacao(ctx context.Context, participacaoID uint, userID uint, userType string) (*models.ParticipacaoLeilaoResponse, error)
+	RegistrarVenda(ctx context.Context, participacaoID uint, userID uint, userType string, req *models.RegistrarVendaRequest) (*models.ParticipacaoLeilaoResponse, error)
+	MarcarAusencia(ctx context.Context, participacaoID uint, userID uint, userType string) (*models.ParticipacaoLeilaoResponse, error)
+	MarcarPresenca(ctx context.Context, participacaoID uint, userID uint, userType string) (*models.ParticipacaoLeilaoResponse, error)
+
+	ListLotes(ctx context.Context, leilaoID uint) (*models.LotesLeilao, error)
+	OrganizarLotes(ctx context.Context, leilaoID uint, userID uint, userType string, req *models.OrganizarLotesRequest) (*models.LotesLeilao, error)
+
+	GetCatalogo(ctx context.Context, leilaoID uint, versao int) (*models.ConteudoCatalogo, error)
+	RascunhoCatalogo(ctx context.Context, leilaoID uint, userID uint, userType string) (*models.ConteudoCatalogo, error)
+	ListVersoesCatalogo(ctx context.Context, leilaoID uint) ([]*models.CatalogoLeilao, error)
+	OpenCatalogoPDF(ctx context.Context, leilaoID uint, versao int) (io.ReadCloser, *models.CatalogoLeilao, error)
+	PublicarCatalogo(ctx context.Context, leilaoID uint, userID uint, userType string, req *models.PublicarCatalogoRequest) (*models.CatalogoLeilao, error)
+
+	IniciarLeilao(ctx context.Context, leilaoID uint, userID uint, userType string) (*models.Leilao, error)
+	EncerrarLeilao(ctx context.Context, leilaoID uint, userID uint, userType string) (*models.RelatorioLiquidacaoLeilao, error)
+	CancelarLeilao(ctx context.Context, leilaoID uint, userID uint, userType string, req *models.CancelarLeilaoRequest) (*models.Leilao, error)
+	GetLiquidacao(ctx context.Context, leilaoID uint, userID uint, userType string) (*models.RelatorioLiquidacaoLeilao, error)
+	ProcessarLeiloes(ctx context.Context) (int, error)
+
+	SolicitarHabilitacao(ctx context.Context, leilaoID uint, userID uint, req *models.SolicitarHabilitacaoRequest) (*models.HabilitacaoLeilao, error)
+	EnviarDocumentoHabilitacao(ctx context.Context, leilaoID uint, userID uint, tipo models.TipoDocumentoHabilitacao, arquivo *models.ArquivoEnviado) (*models.DocumentoHabilitacao, error)
+	GetMinhaHabilitacao(ctx context.Context, leilaoID uint, userID uint) (*models.HabilitacaoLeilao, error)
+	CancelarHabilitacao(ctx context.Context, leilaoID uint, userID uint) (*models.HabilitacaoLeilao, error)
+	ListHabilitacoes(ctx context.Context, leilaoID uint, userID uint, userType string, status models.StatusHabilitacao) ([]*models.HabilitacaoLeilao, error)
+	GetHabilitacao(ctx context.Context, id uint, userID uint, userType string) (*models.HabilitacaoLeilao, error)
+	AprovarHabilitacao(ctx context.Context, id uint, userID uint, userType string, req *models.AprovarHabilitacaoRequest) (*models.HabilitacaoLeilao, error)
+	RejeitarHabilitacao(ctx context.Context, id uint, userID uint, userType string, req *models.RejeitarHabilitacaoRequest) (*models.HabilitacaoLeilao, error)
+	ConfirmarCaucao(ctx context.Context, id uint, userID uint, userType string, req *models.ConfirmarCaucaoRequest) (*models.HabilitacaoLeilao, error)
 }
 
+// loteTransicoes leilões iniciados ou encerrados por execução do job
+const loteTransicoes = 100
+
 type service struct {
-	repo       Repository
-	equinoRepo equinos.Repository
-	logger     *logging.Logger
+	repo           Repository
+	equinoRepo     equinos.Repository
+	pedigree       PedigreeProvider
+	store          storage.Storage
+	audit          AuditLogger
+	urlTTL         time.Duration
+	maxSize        int64
+	prazoPagamento time.Duration
+	logger         *logging.Logger
 }
 
-func NewService(repo Repository, equinoRepo equinos.Repository, logger *logging.Logger) Service {
+// NewService cria o serviço de leilões; sem armazenamento configurado a publicação do catálogo e o envio de
+// documentos da habilitação respondem como indisponíveis
+func NewService(repo Repository, equinoRepo equinos.Repository, pedigree PedigreeProvider, store storage.Storage, audit AuditLogger, cfg *config.Config, logger *logging.Logger) Service {
 	return &service{
-		repo:       repo,
-		equinoRepo: equinoRepo,
-		logger:     logger,
+		repo:           repo,
+		equinoRepo:     equinoRepo,
+		pedigree:       pedigree,
+		store:          store,
+		audit:          audit,
+		urlTTL:         cfg.DocumentURLTTL,
+		maxSize:        cfg.UploadMaxSize,
+		prazoPagamento: cfg.AuctionPaymentDeadline,
+		logger:         logger,
 	}
 }
 
@@ -75,8 +138,8 @@ func (s *service) CriarParticipacao(ctx context.Context, leilaoID, criadorID uin
 		return nil, err
 	}
 
-	if leilao.Status == models.StatusLeilaoEncerrado || leilao.Status == models.StatusLeilaoCancelado {
-		return nil, &apperrors.ValidationError{Message: "leilão não aceita mais inscrições"}
+	if err := exigirInscricoesAbertas(leilao); err != nil {
+		return nil, err
 	}
 
 	var equinoID uint
@@ -104,6 +167,8 @@ func (s *service) CriarParticipacao(ctx context.Context, leilaoID, criadorID uin
 		return nil, err
 	}
 
+	s.recordChange(ctx, "participacao_leilao", participacao.ID, "create", nil, participacao)
+
 	s.logger.WithFields(logging.Fields{
 		"participacao_id": participacao.ID,
 		"leilao_id":       leilaoID,
@@ -113,64 +178,105 @@ func (s *service) CriarParticipacao(ctx context.Context, leilaoID, criadorID uin
 	return s.getParticipacaoResponse(ctx, participacao.ID)
 }
 
-func (s *service) AprovarParticipacao(ctx context.Context, participacaoID uint) (*models.ParticipacaoLeilaoResponse, error) {
+func (s *service) AprovarParticipacao(ctx context.Context, participacaoID uint, userID uint, userType string) (*models.ParticipacaoLeilaoResponse, error) {
 	participacao, err := s.repo.FindParticipacaoByID(ctx, participacaoID)
 	if err != nil {
 		return nil, err
 	}
+	before := *participacao
 
+	leilao, err := s.leilaoDaParticipacao(ctx, participacao)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "aprovar_participacao"); err != nil {
+		return nil, err
+	}
 	if participacao.Status != models.StatusParticipacaoInscrito {
 		return nil, &apperrors.ValidationError{Message: "apenas participações inscritas podem ser aprovadas"}
 	}
+	if err := exigirInscricoesAbertas(leilao); err != nil {
+		return nil, err
+	}
 
 	participacao.Status = models.StatusParticipacaoAprovado
+	if participacao.NumeroLote == nil {
+		// Lote aprovado entra no fim do pregão; o leiloeiro reordena depois
+		numero, sessao, err := s.repo.ProximoLote(ctx, participacao.LeilaoID)
+		if err != nil {
+			s.logger.LogError(err, "LeilaoService.AprovarParticipacao", logging.Fields{"participacao_id": participacaoID})
+			return nil, err
+		}
+		participacao.NumeroLote = &numero
+		participacao.Sessao = &sessao
+	}
 
 	if err := s.repo.UpdateParticipacao(ctx, participacao); err != nil {
 		s.logger.LogError(err, "LeilaoService.AprovarParticipacao", logging.Fields{"participacao_id": participacaoID})
 		return nil, err
 	}
 
+	s.recordChange(ctx, "participacao_leilao", participacaoID, "update", &before, participacao)
+
 	s.logger.WithFields(logging.Fields{"participacao_id": participacaoID}).Info("Participação aprovada")
 	return s.getParticipacaoResponse(ctx, participacaoID)
 }
 
-func (s *service) RegistrarVenda(ctx context.Context, participacaoID uint, req *models.RegistrarVendaRequest) (*models.ParticipacaoLeilaoResponse, error) {
+func (s *service) RegistrarVenda(ctx context.Context, participacaoID uint, userID uint, userType string, req *models.RegistrarVendaRequest) (*models.ParticipacaoLeilaoResponse, error) {
 	participacao, err := s.repo.FindParticipacaoByID(ctx, participacaoID)
 	if err != nil {
 		return nil, err
 	}
+	before := *participacao
 
+	leilao, err := s.leilaoDaParticipacao(ctx, participacao)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "registrar_venda"); err != nil {
+		return nil, err
+	}
 	if participacao.Status != models.StatusParticipacaoAprovado {
 		return nil, &apperrors.ValidationError{Message: "apenas participações aprovadas podem ser vendidas"}
 	}
-
-	leilao := participacao.Leilao
-	if leilao == nil {
-		leilao, err = s.repo.FindByID(ctx, participacao.LeilaoID)
-		if err != nil {
-			return nil, err
-		}
+	if err := exigirPregaoAberto(leilao); err != nil {
+		return nil, err
+	}
+	if req.ValorVendido <= 0 {
+		return nil, &apperrors.ValidationError{Field: "valor_vendido", Message: "informe o valor de venda", Value: req.ValorVendido}
+	}
+	habilitacao, err := s.arrematante(ctx, leilao.ID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.exigirSemComprasPendentes(ctx, habilitacao.UsuarioID, leilao.ID); err != nil {
+		return nil, err
 	}
 
 	participacao.ValorVendido = &req.ValorVendido
 	participacao.ValorFinal = &req.ValorVendido
-	participacao.CompradorID = &req.CompradorID
+	participacao.CompradorID = &habilitacao.UsuarioID
 	participacao.Status = models.StatusParticipacaoVendido
 
-	comissaoPercentual := req.ValorVendido * (leilao.TaxaComissaoPercentual / 100)
-	comissaoTotal := comissaoPercentual
-	if leilao.TaxaFixa != nil {
-		comissaoTotal += *leilao.TaxaFixa
-	}
+	comissaoTotal := leilao.CalcularComissao(req.ValorVendido)
 	participacao.ComissaoLeiloeiro = &comissaoTotal
 
-	if err := s.repo.UpdateParticipacao(ctx, participacao); err != nil {
-		s.logger.LogError(err, "LeilaoService.RegistrarVenda", logging.Fields{"participacao_id": participacaoID})
+	err = s.repo.RegistrarVenda(ctx, participacao, habilitacao.ID, func(travada *models.HabilitacaoLeilao, utilizado float64) error {
+		return exigirLimiteDisponivel(travada, utilizado, req.ValorVendido)
+	})
+	if err != nil {
+		if !apperrors.IsValidation(err) && !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "LeilaoService.RegistrarVenda", logging.Fields{"participacao_id": participacaoID})
+		}
 		return nil, err
 	}
 
+	s.recordChange(ctx, "participacao_leilao", participacaoID, "update", &before, participacao)
+
 	s.logger.WithFields(logging.Fields{
 		"participacao_id": participacaoID,
+		"comprador_id":    habilitacao.UsuarioID,
+		"paleta":          habilitacao.Paleta,
 		"valor_vendido":   req.ValorVendido,
 		"comissao":        comissaoTotal,
 	}).Info("Venda registrada com sucesso")
@@ -178,18 +284,22 @@ func (s *service) RegistrarVenda(ctx context.Context, participacaoID uint, req *
 	return s.getParticipacaoResponse(ctx, participacaoID)
 }
 
-func (s *service) MarcarAusencia(ctx context.Context, participacaoID uint) (*models.ParticipacaoLeilaoResponse, error) {
+func (s *service) MarcarAusencia(ctx context.Context, participacaoID uint, userID uint, userType string) (*models.ParticipacaoLeilaoResponse, error) {
 	participacao, err := s.repo.FindParticipacaoByID(ctx, participacaoID)
 	if err != nil {
 		return nil, err
 	}
+	before := *participacao
 
-	leilao := participacao.Leilao
-	if leilao == nil {
-		leilao, err = s.repo.FindByID(ctx, participacao.LeilaoID)
-		if err != nil {
-			return nil, err
-		}
+	leilao, err := s.leilaoDaParticipacao(ctx, participacao)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "marcar_ausencia"); err != nil {
+		return nil, err
+	}
+	if err := exigirPregaoAberto(leilao); err != nil {
+		return nil, err
 	}
 
 	if leilao.TipoLeilao != models.TipoLeilaoPresencial {
@@ -206,6 +316,8 @@ func (s *service) MarcarAusencia(ctx context.Context, participacaoID uint) (*mod
 		return nil, err
 	}
 
+	s.recordChange(ctx, "participacao_leilao", participacaoID, "update", &before, participacao)
+
 	s.logger.WithFields(logging.Fields{
 		"participacao_id": participacaoID,
 		"penalizacao":     penalizacao,
@@ -214,11 +326,23 @@ func (s *service) MarcarAusencia(ctx context.Context, participacaoID uint) (*mod
 	return s.getParticipacaoResponse(ctx, participacaoID)
 }
 
-func (s *service) MarcarPresenca(ctx context.Context, participacaoID uint) (*models.ParticipacaoLeilaoResponse, error) {
+func (s *service) MarcarPresenca(ctx context.Context, participacaoID uint, userID uint, userType string) (*models.ParticipacaoLeilaoResponse, error) {
 	participacao, err := s.repo.FindParticipacaoByID(ctx, participacaoID)
 	if err != nil {
 		return nil, err
 	}
+	before := *participacao
+
+	leilao, err := s.leilaoDaParticipacao(ctx, participacao)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "marcar_presenca"); err != nil {
+		return nil, err
+	}
+	if err := exigirPregaoAberto(leilao); err != nil {
+		return nil, err
+	}
 
 	compareceu := true
 	participacao.Compareceu = &compareceu
@@ -229,10 +353,257 @@ func (s *service) MarcarPresenca(ctx context.Context, participacaoID uint) (*mod
 		return nil, err
 	}
 
+	s.recordChange(ctx, "participacao_leilao", participacaoID, "update", &before, participacao)
+
 	s.logger.WithFields(logging.Fields{"participacao_id": participacaoID}).Info("Presença marcada")
 	return s.getParticipacaoResponse(ctx, participacaoID)
 }
 
+// IniciarLeilao abre o pregão antes do horário agendado; no horário, o job faz a mesma transição
+func (s *service) IniciarLeilao(ctx context.Context, leilaoID uint, userID uint, userType string) (*models.Leilao, error) {
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "iniciar"); err != nil {
+		return nil, err
+	}
+	if err := s.iniciar(ctx, leilao, "manual"); err != nil {
+		return nil, err
+	}
+	return leilao, nil
+}
+
+// EncerrarLeilao fecha o pregão antes do término agendado e devolve o relatório de liquidação
+func (s *service) EncerrarLeilao(ctx context.Context, leilaoID uint, userID uint, userType string) (*models.RelatorioLiquidacaoLeilao, error) {
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "encerrar"); err != nil {
+		return nil, err
+	}
+	if err := s.encerrar(ctx, leilao, "manual"); err != nil {
+		return nil, err
+	}
+	return s.montarLiquidacao(ctx, leilao)
+}
+
+// CancelarLeilao cancela um leilão agendado ou em andamento sem vendas; as participações em aberto são canceladas
+func (s *service) CancelarLeilao(ctx context.Context, leilaoID uint, userID uint, userType string, req *models.CancelarLeilaoRequest) (*models.Leilao, error) {
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "cancelar"); err != nil {
+		return nil, err
+	}
+	if err := exigirTransicao(leilao, models.StatusLeilaoCancelado); err != nil {
+		return nil, err
+	}
+	motivo := strings.TrimSpace(req.Motivo)
+	if motivo == "" {
+		return nil, &apperrors.ValidationError{Field: "motivo", Message: "informe o motivo do cancelamento"}
+	}
+
+	before := *leilao
+	if err := s.repo.CancelarLeilao(ctx, leilao, motivo, time.Now()); err != nil {
+		if !apperrors.IsConflict(err) && !apperrors.IsValidation(err) {
+			s.logger.LogError(err, "LeilaoService.CancelarLeilao", logging.Fields{"leilao_id": leilaoID})
+		}
+		return nil, err
+	}
+
+	s.recordChange(ctx, "leilao", leilaoID, "cancel", &before, leilao)
+	s.logger.WithFields(logging.Fields{"leilao_id": leilaoID, "status_anterior": before.Status}).Info("Leilão cancelado")
+	return leilao, nil
+}
+
+// GetLiquidacao relatório de liquidação de um leilão encerrado
+func (s *service) GetLiquidacao(ctx context.Context, leilaoID uint, userID uint, userType string) (*models.RelatorioLiquidacaoLeilao, error) {
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "consultar_liquidacao"); err != nil {
+		return nil, err
+	}
+	if leilao.Status != models.StatusLeilaoEncerrado {
+		return nil, &apperrors.ValidationError{Field: "status", Message: "a liquidação só está disponível após o encerramento do leilão", Value: leilao.Status}
+	}
+	return s.montarLiquidacao(ctx, leilao)
+}
+
+// ProcessarLeiloes aplica as transições agendadas: inicia os leilões cuja data de início chegou e encerra os que
+// passaram da data de término
+func (s *service) ProcessarLeiloes(ctx context.Context) (int, error) {
+	agora := time.Now()
+	processados := 0
+
+	iniciar, err := s.repo.FindParaIniciar(ctx, agora, loteTransicoes)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.ProcessarLeiloes", logging.Fields{"action": "iniciar"})
+		return 0, err
+	}
+	for _, leilao := range iniciar {
+		if ctx.Err() != nil {
+			return processados, ctx.Err()
+		}
+		if err := s.iniciar(ctx, leilao, "agendamento"); err == nil {
+			processados++
+		}
+	}
+
+	encerrar, err := s.repo.FindParaEncerrar(ctx, agora, loteTransicoes)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.ProcessarLeiloes", logging.Fields{"action": "encerrar"})
+		return processados, err
+	}
+	for _, leilao := range encerrar {
+		if ctx.Err() != nil {
+			return processados, ctx.Err()
+		}
+		if err := s.encerrar(ctx, leilao, "agendamento"); err == nil {
+			processados++
+		}
+	}
+	return processados, nil
+}
+
+func (s *service) iniciar(ctx context.Context, leilao *models.Leilao, origem string) error {
+	if err := exigirTransicao(leilao, models.StatusLeilaoEmAndamento); err != nil {
+		return err
+	}
+	before := *leilao
+	if err := s.repo.IniciarLeilao(ctx, leilao, time.Now()); err != nil {
+		if !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "LeilaoService.iniciar", logging.Fields{"leilao_id": leilao.ID, "origem": origem})
+		}
+		return err
+	}
+
+	s.recordChange(ctx, "leilao", leilao.ID, "start", &before, leilao)
+	s.logger.WithFields(logging.Fields{"leilao_id": leilao.ID, "origem": origem}).Info("Leilão iniciado")
+	return nil
+}
+
+func (s *service) encerrar(ctx context.Context, leilao *models.Leilao, origem string) error {
+	if err := exigirTransicao(leilao, models.StatusLeilaoEncerrado); err != nil {
+		return err
+	}
+	before := *leilao
+	if err := s.repo.EncerrarLeilao(ctx, leilao, time.Now()); err != nil {
+		if !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "LeilaoService.encerrar", logging.Fields{"leilao_id": leilao.ID, "origem": origem})
+		}
+		return err
+	}
+
+	s.recordChange(ctx, "leilao", leilao.ID, "close", &before, leilao)
+	s.logger.WithFields(logging.Fields{
+		"leilao_id":        leilao.ID,
+		"origem":           origem,
+		"total_arrecadado": *leilao.TotalArrecadado,
+		"total_comissoes":  *leilao.TotalComissoes,
+	}).Info("Leilão encerrado")
+	return nil
+}
+
+// montarLiquidacao relatório a partir das participações, que não mudam mais depois do encerramento
+func (s *service) montarLiquidacao(ctx context.Context, leilao *models.Leilao) (*models.RelatorioLiquidacaoLeilao, error) {
+	participacoes, err := s.repo.FindParticipacoesByLeilaoID(ctx, leilao.ID)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.montarLiquidacao", logging.Fields{"leilao_id": leilao.ID})
+		return nil, err
+	}
+
+	relatorio := &models.RelatorioLiquidacaoLeilao{
+		LeilaoID:               leilao.ID,
+		Nome:                   leilao.Nome,
+		Status:                 leilao.Status,
+		DataInicio:             leilao.DataInicio,
+		DataFim:                leilao.DataFim,
+		EncerradoEm:            leilao.EncerradoEm,
+		TaxaComissaoPercentual: leilao.TaxaComissaoPercentual,
+		TaxaFixa:               leilao.TaxaFixa,
+		Vendidos:               []*models.ParticipacaoLeilaoResponse{},
+		NaoVendidos:            []*models.ParticipacaoLeilaoResponse{},
+		Ausentes:               []*models.ParticipacaoLeilaoResponse{},
+	}
+	if leilao.TotalArrecadado != nil {
+		relatorio.TotalArrecadado = *leilao.TotalArrecadado
+	}
+	if leilao.TotalComissoes != nil {
+		relatorio.TotalComissoes = *leilao.TotalComissoes
+	}
+	relatorio.LiquidoVendedores = relatorio.TotalArrecadado - relatorio.TotalComissoes
+
+	for _, p := range participacoes {
+		switch p.Status {
+		case models.StatusParticipacaoVendido:
+			relatorio.Vendidos = append(relatorio.Vendidos, p.ToResponse())
+		case models.StatusParticipacaoNaoVendido:
+			relatorio.NaoVendidos = append(relatorio.NaoVendidos, p.ToResponse())
+		default:
+			continue
+		}
+		relatorio.TotalLotes++
+		if p.Compareceu != nil && !*p.Compareceu {
+			relatorio.Ausentes = append(relatorio.Ausentes, p.ToResponse())
+			if p.PenalizacaoAusencia != nil {
+				relatorio.PenalizacaoTotal += *p.PenalizacaoAusencia
+			}
+		}
+	}
+	relatorio.LotesVendidos = len(relatorio.Vendidos)
+	relatorio.LotesNaoVendidos = len(relatorio.NaoVendidos)
+	relatorio.Ausencias = len(relatorio.Ausentes)
+	return relatorio, nil
+}
+
+// leilaoDaParticipacao leilão carregado com a participação ou buscado pelo ID
+func (s *service) leilaoDaParticipacao(ctx context.Context, participacao *models.ParticipacaoLeilao) (*models.Leilao, error) {
+	if participacao.Leilao != nil {
+		return participacao.Leilao, nil
+	}
+	return s.repo.FindByID(ctx, participacao.LeilaoID)
+}
+
+// exigirInscricoesAbertas inscrições e aprovações só antes do início do leilão
+func exigirInscricoesAbertas(leilao *models.Leilao) error {
+	if leilao.Status != models.StatusLeilaoAgendado || !time.Now().Before(leilao.DataInicio) {
+		return &apperrors.ValidationError{Field: "status", Message: "leilão não aceita mais inscrições", Value: leilao.Status}
+	}
+	return nil
+}
+
+// exigirPregaoAberto vendas e controle de presença só com o leilão em andamento e dentro do horário
+func exigirPregaoAberto(leilao *models.Leilao) error {
+	if leilao.Status != models.StatusLeilaoEmAndamento {
+		return &apperrors.ValidationError{Field: "status", Message: "o leilão não está em andamento", Value: leilao.Status}
+	}
+	if time.Now().After(leilao.DataFim) {
+		return &apperrors.ValidationError{Field: "data_fim", Message: "o horário do leilão já terminou", Value: leilao.DataFim}
+	}
+	return nil
+}
+
+func exigirTransicao(leilao *models.Leilao, destino models.StatusLeilao) error {
+	if !leilao.Status.PermiteTransicao(destino) {
+		return &apperrors.ValidationError{Field: "status", Message: fmt.Sprintf("leilão %s não pode passar para %s", leilao.Status, destino), Value: leilao.Status}
+	}
+	return nil
+}
+
+func (s *service) recordChange(ctx context.Context, resource string, id uint, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, resource, fmt.Sprintf("%d", id), operation, before, after); err != nil {
+		s.logger.LogError(err, "LeilaoService.recordChange", logging.Fields{"resource": resource, "id": id, "operation": operation})
+	}
+}
+
 func (s *service) getParticipacaoResponse(ctx context.Context, id uint) (*models.ParticipacaoLeilaoResponse, error) {
 	participacao, err := s.repo.FindParticipacaoByID(ctx, id)
 	if err != nil {
@@ -240,3 +611,317 @@ func (s *service) getParticipacaoResponse(ctx context.Context, id uint) (*models
 	}
 	return participacao.ToResponse(), nil
 }
+
+func (s *service) ListLotes(ctx context.Context, leilaoID uint) (*models.LotesLeilao, error) {
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	sessoes, err := s.repo.FindSessoes(ctx, leilaoID)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.ListLotes", logging.Fields{"leilao_id": leilaoID})
+		return nil, err
+	}
+	participacoes, err := s.repo.FindLotes(ctx, leilaoID)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.ListLotes", logging.Fields{"leilao_id": leilaoID})
+		return nil, err
+	}
+
+	lotes := &models.LotesLeilao{
+		LeilaoID:       leilaoID,
+		VersaoCatalogo: leilao.VersaoCatalogo,
+		Sessoes:        sessoes,
+		Lotes:          []*models.ParticipacaoLeilaoResponse{},
+		SemLote:        []*models.ParticipacaoLeilaoResponse{},
+	}
+	for _, p := range participacoes {
+		if p.NumeroLote == nil {
+			lotes.SemLote = append(lotes.SemLote, p.ToResponse())
+		} else {
+			lotes.Lotes = append(lotes.Lotes, p.ToResponse())
+		}
+	}
+	return lotes, nil
+}
+
+// OrganizarLotes redefine as sessões e a ordem de todos os lotes do leilão. A lista deve conter cada participação
+// aprovada exatamente uma vez, agrupada por sessão; os números de lote seguem a ordem enviada
+func (s *service) OrganizarLotes(ctx context.Context, leilaoID uint, userID uint, userType string, req *models.OrganizarLotesRequest) (*models.LotesLeilao, error) {
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "organizar_lotes"); err != nil {
+		return nil, err
+	}
+	if leilao.Status != models.StatusLeilaoAgendado {
+		return nil, &apperrors.ValidationError{Field: "status", Message: "os lotes só podem ser reorganizados antes do início do leilão", Value: leilao.Status}
+	}
+
+	sessoes := make([]models.SessaoLeilao, 0, len(req.Sessoes))
+	numerosSessao := make(map[int]bool, len(req.Sessoes))
+	for _, sessao := range req.Sessoes {
+		if numerosSessao[sessao.Numero] {
+			return nil, &apperrors.ValidationError{Field: "sessoes", Message: "número de sessão repetido", Value: sessao.Numero}
+		}
+		numerosSessao[sessao.Numero] = true
+		sessoes = append(sessoes, models.SessaoLeilao{Numero: sessao.Numero, Titulo: strings.TrimSpace(sessao.Titulo), InicioEm: sessao.InicioEm})
+	}
+
+	atuais, err := s.repo.FindLotes(ctx, leilaoID)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.OrganizarLotes", logging.Fields{"leilao_id": leilaoID})
+		return nil, err
+	}
+	pendentes := make(map[uint]bool, len(atuais))
+	for _, p := range atuais {
+		pendentes[p.ID] = true
+	}
+
+	sessaoAnterior := 0
+	for _, lote := range req.Lotes {
+		if !numerosSessao[lote.Sessao] {
+			return nil, &apperrors.ValidationError{Field: "lotes", Message: "lote em sessão não definida", Value: lote.ParticipacaoID}
+		}
+		if lote.Sessao < sessaoAnterior {
+			return nil, &apperrors.ValidationError{Field: "lotes", Message: "os lotes devem estar agrupados em ordem crescente de sessão", Value: lote.ParticipacaoID}
+		}
+		sessaoAnterior = lote.Sessao
+		if !pendentes[lote.ParticipacaoID] {
+			return nil, &apperrors.ValidationError{Field: "lotes", Message: "participação repetida ou que não está aprovada neste leilão", Value: lote.ParticipacaoID}
+		}
+		delete(pendentes, lote.ParticipacaoID)
+	}
+	if len(pendentes) > 0 {
+		faltantes := make([]uint, 0, len(pendentes))
+		for _, p := range atuais {
+			if pendentes[p.ID] {
+				faltantes = append(faltantes, p.ID)
+			}
+		}
+		return nil, &apperrors.ValidationError{Field: "lotes", Message: "todas as participações aprovadas devem receber um lote", Value: faltantes}
+	}
+
+	antes, err := s.ListLotes(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.OrganizarLotes(ctx, leilaoID, sessoes, req.Lotes); err != nil {
+		s.logger.LogError(err, "LeilaoService.OrganizarLotes", logging.Fields{"leilao_id": leilaoID})
+		return nil, err
+	}
+	depois, err := s.ListLotes(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	s.recordChange(ctx, "lotes_leilao", leilaoID, "update", antes, depois)
+
+	s.logger.WithFields(logging.Fields{"leilao_id": leilaoID, "lotes": len(req.Lotes), "sessoes": len(sessoes)}).Info("Lotes do leilão reorganizados")
+	return depois, nil
+}
+
+// GetCatalogo versão publicada do catálogo (0 para a mais recente), com URLs temporárias das fotos
+func (s *service) GetCatalogo(ctx context.Context, leilaoID uint, versao int) (*models.ConteudoCatalogo, error) {
+	catalogo, err := s.repo.FindCatalogo(ctx, leilaoID, versao)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "LeilaoService.GetCatalogo", logging.Fields{"leilao_id": leilaoID, "versao": versao})
+		}
+		return nil, err
+	}
+
+	var conteudo models.ConteudoCatalogo
+	if err := json.Unmarshal([]byte(catalogo.Conteudo), &conteudo); err != nil {
+		s.logger.LogError(err, "LeilaoService.GetCatalogo", logging.Fields{"leilao_id": leilaoID, "versao": catalogo.Versao})
+		return nil, err
+	}
+	s.assinarFotos(ctx, &conteudo)
+	return &conteudo, nil
+}
+
+// RascunhoCatalogo catálogo montado com os dados atuais, para o leiloeiro conferir antes de publicar
+func (s *service) RascunhoCatalogo(ctx context.Context, leilaoID uint, userID uint, userType string) (*models.ConteudoCatalogo, error) {
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "ver_rascunho_catalogo"); err != nil {
+		return nil, err
+	}
+
+	conteudo, _, err := s.montarCatalogo(ctx, leilao)
+	if err != nil {
+		return nil, err
+	}
+	conteudo.Rascunho = true
+	s.assinarFotos(ctx, conteudo)
+	return conteudo, nil
+}
+
+func (s *service) ListVersoesCatalogo(ctx context.Context, leilaoID uint) ([]*models.CatalogoLeilao, error) {
+	if _, err := s.repo.FindByID(ctx, leilaoID); err != nil {
+		return nil, err
+	}
+	catalogos, err := s.repo.ListCatalogos(ctx, leilaoID)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.ListVersoesCatalogo", logging.Fields{"leilao_id": leilaoID})
+		return nil, err
+	}
+	return catalogos, nil
+}
+
+// OpenCatalogoPDF PDF da versão publicada exatamente como gerado (0 para a mais recente)
+func (s *service) OpenCatalogoPDF(ctx context.Context, leilaoID uint, versao int) (io.ReadCloser, *models.CatalogoLeilao, error) {
+	catalogo, err := s.repo.FindCatalogo(ctx, leilaoID, versao)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "LeilaoService.OpenCatalogoPDF", logging.Fields{"leilao_id": leilaoID, "versao": versao})
+		}
+		return nil, nil, err
+	}
+	if s.store == nil {
+		return nil, nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos não configurado", nil)
+	}
+	reader, err := s.store.Get(ctx, catalogo.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, &apperrors.NotFoundError{Resource: "arquivo", Message: "PDF do catálogo não encontrado no armazenamento", ID: catalogo.Versao}
+		}
+		s.logger.LogError(err, "LeilaoService.OpenCatalogoPDF", logging.Fields{"backend": s.store.Name(), "leilao_id": leilaoID})
+		return nil, nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos indisponível", nil)
+	}
+	return reader, catalogo, nil
+}
+
+// PublicarCatalogo congela o catálogo atual em uma nova versão (JSON e PDF). A primeira versão publica o leilão; as
+// seguintes são erratas e exigem o motivo. Só é possível antes do início do pregão
+func (s *service) PublicarCatalogo(ctx context.Context, leilaoID uint, userID uint, userType string, req *models.PublicarCatalogoRequest) (*models.CatalogoLeilao, error) {
+	if s.store == nil {
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos não configurado", nil)
+	}
+	leilao, err := s.repo.FindByID(ctx, leilaoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := podeGerenciar(leilao, userID, userType, "publicar_catalogo"); err != nil {
+		return nil, err
+	}
+	if leilao.Status != models.StatusLeilaoAgendado {
+		return nil, &apperrors.ValidationError{Field: "status", Message: "o catálogo só pode ser publicado antes do início do leilão", Value: leilao.Status}
+	}
+	versao := leilao.VersaoCatalogo + 1
+	motivo := strings.TrimSpace(req.Motivo)
+	if versao > 1 && motivo == "" {
+		return nil, &apperrors.ValidationError{Field: "motivo", Message: "informe o que mudou em relação à versão publicada"}
+	}
+
+	conteudo, capas, err := s.montarCatalogo(ctx, leilao)
+	if err != nil {
+		return nil, err
+	}
+	if conteudo.TotalLotes == 0 {
+		return nil, &apperrors.ValidationError{Field: "lotes", Message: "o leilão não tem lotes aprovados para publicar"}
+	}
+	if semLote := lotesSemNumero(conteudo); len(semLote) > 0 {
+		return nil, &apperrors.ValidationError{Field: "lotes", Message: "há participações aprovadas sem número de lote", Value: semLote}
+	}
+
+	publicadoEm := time.Now().UTC().Truncate(time.Second)
+	conteudo.Versao = versao
+	conteudo.GeradoEm = publicadoEm
+	serializado, err := json.Marshal(conteudo)
+	if err != nil {
+		s.logger.LogError(err, "LeilaoService.PublicarCatalogo", logging.Fields{"leilao_id": leilaoID, "action": "marshal"})
+		return nil, err
+	}
+	arquivo := renderCatalogo(conteudo, s.carregarCapas(ctx, capas))
+
+	digest := sha256.Sum256(arquivo)
+	catalogo := &models.CatalogoLeilao{
+		LeilaoID:       leilaoID,
+		Versao:         versao,
+		Motivo:         motivo,
+		Lotes:          conteudo.TotalLotes,
+		Conteudo:       string(serializado),
+		Hash:           hex.EncodeToString(digest[:]),
+		Tamanho:        int64(len(arquivo)),
+		StorageBackend: s.store.Name(),
+		StorageKey:     fmt.Sprintf("leiloes/%d/catalogos/v%d.pdf", leilaoID, versao),
+		PublicadoPor:   userID,
+		PublicadoEm:    publicadoEm,
+	}
+	if err := s.store.Put(ctx, catalogo.StorageKey, arquivo, "application/pdf"); err != nil {
+		s.logger.LogError(err, "LeilaoService.PublicarCatalogo", logging.Fields{"backend": s.store.Name(), "leilao_id": leilaoID})
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos indisponível", nil)
+	}
+	if err := s.repo.CreateCatalogo(ctx, catalogo); err != nil {
+		if !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "LeilaoService.PublicarCatalogo", logging.Fields{"leilao_id": leilaoID})
+		}
+		if delErr := s.store.Delete(ctx, catalogo.StorageKey); delErr != nil {
+			s.logger.LogError(delErr, "LeilaoService.PublicarCatalogo", logging.Fields{"key": catalogo.StorageKey})
+		}
+		return nil, err
+	}
+
+	s.recordChange(ctx, "catalogo_leilao", leilaoID, "publish", nil, catalogo)
+	s.logger.WithFields(logging.Fields{
+		"leilao_id": leilaoID,
+		"versao":    versao,
+		"lotes":     catalogo.Lotes,
+		"hash":      catalogo.Hash,
+	}).Info("Catálogo do leilão publicado")
+
+	return catalogo, nil
+}
+
+// assinarFotos troca as chaves de armazenamento das fotos por URLs temporárias
+func (s *service) assinarFotos(ctx context.Context, conteudo *models.ConteudoCatalogo) {
+	for i := range conteudo.Sessoes {
+		for j := range conteudo.Sessoes[i].Lotes {
+			fotos := conteudo.Sessoes[i].Lotes[j].Fotos
+			for k := range fotos {
+				if s.store != nil && fotos[k].StorageKey != "" {
+					url, err := s.store.PresignGet(ctx, fotos[k].StorageKey, s.urlTTL, "")
+					if err != nil {
+						s.logger.LogError(err, "LeilaoService.assinarFotos", logging.Fields{"leilao_id": conteudo.LeilaoID})
+					} else {
+						fotos[k].URL = url
+					}
+				}
+				fotos[k].StorageKey = ""
+			}
+		}
+	}
+}
+
+// carregarCapas foto de capa de cada lote para o PDF; fotos indisponíveis são omitidas
+func (s *service) carregarCapas(ctx context.Context, capas map[int]string) map[int][]byte {
+	conteudo := make(map[int][]byte, len(capas))
+	for numero, key := range capas {
+		reader, err := s.store.Get(ctx, key)
+		if err != nil {
+			s.logger.Warnf("Foto do lote %d indisponível para o catálogo: %v", numero, err)
+			continue
+		}
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, io.LimitReader(reader, maxFotoCapa))
+		reader.Close()
+		if err != nil {
+			s.logger.Warnf("Foto do lote %d indisponível para o catálogo: %v", numero, err)
+			continue
+		}
+		conteudo[numero] = buf.Bytes()
+	}
+	return conteudo
+}
+
+// podeGerenciar apenas o leiloeiro do leilão ou um administrador conduzem o pregão, organizam lotes e publicam o
+// catálogo
+func podeGerenciar(leilao *models.Leilao, userID uint, userType string, acao string) error {
+	if userType == string(models.UserTypeAdmin) || leilao.LeiloeiroID == userID {
+		return nil
+	}
+	return (&apperrors.AuthorizationError{Message: "apenas o leiloeiro do leilão pode realizar esta operação"}).WithAction(acao, "leilao")
+}
@@ -0,0 +1,280 @@
+package leiloes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	leiloeiroID   uint = 1
+	arrematanteID uint = 2
+	criadorID     uint = 3
+)
+
+func setupLeiloesService(t *testing.T) (Service, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Skip("sqlite driver unavailable for tests")
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.Equino{}, &models.Leilao{}, &models.SessaoLeilao{}, &models.ParticipacaoLeilao{},
+		&models.HabilitacaoLeilao{}, &models.DocumentoHabilitacao{}, &models.Pagamento{}))
+
+	cfg := &config.Config{AuctionPaymentDeadline: 5 * 24 * time.Hour}
+	return NewService(NewRepository(db), equinos.NewRepository(db), nil, nil, nil, cfg, logging.NewLogger("error")), db
+}
+
+func criarLeilao(t *testing.T, db *gorm.DB, status models.StatusLeilao, inicio, fim time.Time) *models.Leilao {
+	leilao := &models.Leilao{
+		Nome:                   "Leilão de Potros",
+		LeiloeiroID:            leiloeiroID,
+		TaxaComissaoPercentual: 5,
+		DataInicio:             inicio,
+		DataFim:                fim,
+		TipoLeilao:             models.TipoLeilaoPresencial,
+		Status:                 status,
+	}
+	require.NoError(t, db.Omit("Leiloeiro", "Participacoes").Create(leilao).Error)
+	return leilao
+}
+
+func criarLote(t *testing.T, db *gorm.DB, leilaoID uint, status models.StatusParticipacaoLeilao) *models.ParticipacaoLeilao {
+	participacao := &models.ParticipacaoLeilao{
+		LeilaoID:     leilaoID,
+		EquinoID:     10,
+		CriadorID:    criadorID,
+		ValorInicial: 10000,
+		Status:       status,
+	}
+	require.NoError(t, db.Omit("Leilao", "Equino", "Criador", "Comprador").Create(participacao).Error)
+	return participacao
+}
+
+func habilitarArrematante(t *testing.T, db *gorm.DB, leilaoID uint, limite float64) *models.HabilitacaoLeilao {
+	paleta := 1
+	habilitacao := &models.HabilitacaoLeilao{
+		LeilaoID:         leilaoID,
+		UsuarioID:        arrematanteID,
+		Status:           models.HabilitacaoAprovada,
+		LimiteSolicitado: limite,
+		Garantia:         models.GarantiaLimiteCredito,
+		LimiteAprovado:   &limite,
+		Paleta:           &paleta,
+	}
+	require.NoError(t, db.Omit("Usuario", "Documentos").Create(habilitacao).Error)
+	return habilitacao
+}
+
+func venda(valor float64) *models.RegistrarVendaRequest {
+	paleta := 1
+	return &models.RegistrarVendaRequest{ValorVendido: valor, Paleta: &paleta}
+}
+
+func TestLeilaoService_RegistrarVendaRespeitaLimiteDoArrematante(t *testing.T) {
+	service, db := setupLeiloesService(t)
+	ctx := context.Background()
+
+	leilao := criarLeilao(t, db, models.StatusLeilaoEmAndamento, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	habilitarArrematante(t, db, leilao.ID, 100000)
+	primeiro := criarLote(t, db, leilao.ID, models.StatusParticipacaoAprovado)
+	segundo := criarLote(t, db, leilao.ID, models.StatusParticipacaoAprovado)
+
+	_, err := service.RegistrarVenda(ctx, primeiro.ID, criadorID, string(models.UserTypeCriador), venda(80000))
+	assert.True(t, apperrors.IsAuthorization(err))
+
+	vendido, err := service.RegistrarVenda(ctx, primeiro.ID, leiloeiroID, "", venda(80000))
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusParticipacaoVendido, vendido.Status)
+	require.NotNil(t, vendido.CompradorID)
+	assert.Equal(t, arrematanteID, *vendido.CompradorID)
+
+	_, err = service.RegistrarVenda(ctx, segundo.ID, leiloeiroID, "", venda(30000))
+	assert.True(t, apperrors.IsValidation(err))
+
+	_, err = service.RegistrarVenda(ctx, primeiro.ID, leiloeiroID, "", venda(10000))
+	assert.True(t, apperrors.IsValidation(err))
+
+	_, err = service.RegistrarVenda(ctx, segundo.ID, leiloeiroID, "", venda(20000))
+	require.NoError(t, err)
+}
+
+func TestLeilaoService_RegistrarVendaNaoVendeLoteJaVendido(t *testing.T) {
+	_, db := setupLeiloesService(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	leilao := criarLeilao(t, db, models.StatusLeilaoEmAndamento, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	habilitacao := habilitarArrematante(t, db, leilao.ID, 100000)
+	lote := criarLote(t, db, leilao.ID, models.StatusParticipacaoVendido)
+
+	valor := 5000.0
+	lote.ValorVendido = &valor
+	err := repo.RegistrarVenda(ctx, lote, habilitacao.ID, func(*models.HabilitacaoLeilao, float64) error { return nil })
+	assert.True(t, apperrors.IsConflict(err))
+}
+
+func loteVendido(t *testing.T, db *gorm.DB, leilaoID uint, valor, comissao float64) *models.ParticipacaoLeilao {
+	participacao := criarLote(t, db, leilaoID, models.StatusParticipacaoVendido)
+	require.NoError(t, db.Model(participacao).Updates(map[string]interface{}{
+		"valor_vendido":      valor,
+		"comissao_leiloeiro": comissao,
+		"comprador_id":       arrematanteID,
+	}).Error)
+	return participacao
+}
+
+func statusLote(t *testing.T, db *gorm.DB, id uint) models.StatusParticipacaoLeilao {
+	var participacao models.ParticipacaoLeilao
+	require.NoError(t, db.First(&participacao, id).Error)
+	return participacao.Status
+}
+
+func TestStatusLeilao_PermiteTransicao(t *testing.T) {
+	casos := []struct {
+		de, para  models.StatusLeilao
+		permitida bool
+	}{
+		{models.StatusLeilaoAgendado, models.StatusLeilaoEmAndamento, true},
+		{models.StatusLeilaoAgendado, models.StatusLeilaoCancelado, true},
+		{models.StatusLeilaoAgendado, models.StatusLeilaoEncerrado, false},
+		{models.StatusLeilaoEmAndamento, models.StatusLeilaoEncerrado, true},
+		{models.StatusLeilaoEmAndamento, models.StatusLeilaoCancelado, true},
+		{models.StatusLeilaoEmAndamento, models.StatusLeilaoAgendado, false},
+		{models.StatusLeilaoEncerrado, models.StatusLeilaoEmAndamento, false},
+		{models.StatusLeilaoEncerrado, models.StatusLeilaoCancelado, false},
+		{models.StatusLeilaoCancelado, models.StatusLeilaoAgendado, false},
+		{models.StatusLeilaoCancelado, models.StatusLeilaoEmAndamento, false},
+	}
+	for _, caso := range casos {
+		t.Run(string(caso.de)+"->"+string(caso.para), func(t *testing.T) {
+			assert.Equal(t, caso.permitida, caso.de.PermiteTransicao(caso.para))
+		})
+	}
+}
+
+func TestLeilaoService_CicloDeVidaManual(t *testing.T) {
+	service, db := setupLeiloesService(t)
+	ctx := context.Background()
+
+	leilao := criarLeilao(t, db, models.StatusLeilaoAgendado, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	inscrito := criarLote(t, db, leilao.ID, models.StatusParticipacaoInscrito)
+	aprovado := criarLote(t, db, leilao.ID, models.StatusParticipacaoAprovado)
+
+	_, err := service.EncerrarLeilao(ctx, leilao.ID, leiloeiroID, "")
+	assert.True(t, apperrors.IsValidation(err))
+
+	_, err = service.IniciarLeilao(ctx, leilao.ID, criadorID, string(models.UserTypeCriador))
+	assert.True(t, apperrors.IsAuthorization(err))
+
+	iniciado, err := service.IniciarLeilao(ctx, leilao.ID, leiloeiroID, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusLeilaoEmAndamento, iniciado.Status)
+	assert.NotNil(t, iniciado.IniciadoEm)
+	assert.Equal(t, models.StatusParticipacaoCancelado, statusLote(t, db, inscrito.ID))
+	assert.Equal(t, models.StatusParticipacaoAprovado, statusLote(t, db, aprovado.ID))
+
+	_, err = service.IniciarLeilao(ctx, leilao.ID, leiloeiroID, "")
+	assert.True(t, apperrors.IsValidation(err))
+
+	_, err = service.CancelarLeilao(ctx, leilao.ID, leiloeiroID, "", &models.CancelarLeilaoRequest{Motivo: "  "})
+	assert.True(t, apperrors.IsValidation(err))
+
+	liquidacao, err := service.EncerrarLeilao(ctx, leilao.ID, 99, string(models.UserTypeAdmin))
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusLeilaoEncerrado, liquidacao.Status)
+	assert.NotNil(t, liquidacao.EncerradoEm)
+	assert.Equal(t, models.StatusParticipacaoNaoVendido, statusLote(t, db, aprovado.ID))
+
+	_, err = service.CancelarLeilao(ctx, leilao.ID, leiloeiroID, "", &models.CancelarLeilaoRequest{Motivo: "Chuva"})
+	assert.True(t, apperrors.IsValidation(err))
+	_, err = service.IniciarLeilao(ctx, leilao.ID, leiloeiroID, "")
+	assert.True(t, apperrors.IsValidation(err))
+}
+
+func TestLeilaoService_CancelamentoExigeLeilaoSemVendas(t *testing.T) {
+	service, db := setupLeiloesService(t)
+	ctx := context.Background()
+	motivo := &models.CancelarLeilaoRequest{Motivo: "Problema no recinto"}
+
+	agendado := criarLeilao(t, db, models.StatusLeilaoAgendado, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	aprovado := criarLote(t, db, agendado.ID, models.StatusParticipacaoAprovado)
+
+	cancelado, err := service.CancelarLeilao(ctx, agendado.ID, leiloeiroID, "", motivo)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusLeilaoCancelado, cancelado.Status)
+	assert.Equal(t, motivo.Motivo, cancelado.MotivoCancelamento)
+	assert.Equal(t, models.StatusParticipacaoCancelado, statusLote(t, db, aprovado.ID))
+
+	_, err = service.CancelarLeilao(ctx, agendado.ID, leiloeiroID, "", motivo)
+	assert.True(t, apperrors.IsValidation(err))
+
+	emAndamento := criarLeilao(t, db, models.StatusLeilaoEmAndamento, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	loteVendido(t, db, emAndamento.ID, 50000, 2500)
+
+	_, err = service.CancelarLeilao(ctx, emAndamento.ID, leiloeiroID, "", motivo)
+	assert.True(t, apperrors.IsValidation(err))
+
+	var persistido models.Leilao
+	require.NoError(t, db.First(&persistido, emAndamento.ID).Error)
+	assert.Equal(t, models.StatusLeilaoEmAndamento, persistido.Status)
+}
+
+func TestLeilaoService_ProcessarLeiloesAplicaAgenda(t *testing.T) {
+	service, db := setupLeiloesService(t)
+	ctx := context.Background()
+	agora := time.Now()
+
+	abrir := criarLeilao(t, db, models.StatusLeilaoAgendado, agora.Add(-time.Minute), agora.Add(time.Hour))
+	futuro := criarLeilao(t, db, models.StatusLeilaoAgendado, agora.Add(time.Hour), agora.Add(2*time.Hour))
+	fechar := criarLeilao(t, db, models.StatusLeilaoEmAndamento, agora.Add(-2*time.Hour), agora.Add(-time.Minute))
+
+	loteVendido(t, db, fechar.ID, 80000, 4000)
+	loteVendido(t, db, fechar.ID, 20000.5, 1000.03)
+	semLance := criarLote(t, db, fechar.ID, models.StatusParticipacaoAprovado)
+	pendente := criarLote(t, db, fechar.ID, models.StatusParticipacaoInscrito)
+
+	processados, err := service.ProcessarLeiloes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, processados)
+
+	var aberto, naoIniciado, encerrado models.Leilao
+	require.NoError(t, db.First(&aberto, abrir.ID).Error)
+	require.NoError(t, db.First(&naoIniciado, futuro.ID).Error)
+	require.NoError(t, db.First(&encerrado, fechar.ID).Error)
+
+	assert.Equal(t, models.StatusLeilaoEmAndamento, aberto.Status)
+	assert.NotNil(t, aberto.IniciadoEm)
+	assert.Equal(t, models.StatusLeilaoAgendado, naoIniciado.Status)
+
+	assert.Equal(t, models.StatusLeilaoEncerrado, encerrado.Status)
+	assert.NotNil(t, encerrado.EncerradoEm)
+	require.NotNil(t, encerrado.TotalArrecadado)
+	require.NotNil(t, encerrado.TotalComissoes)
+	assert.InDelta(t, 100000.5, *encerrado.TotalArrecadado, 0.001)
+	assert.InDelta(t, 5000.03, *encerrado.TotalComissoes, 0.001)
+	assert.Equal(t, models.StatusParticipacaoNaoVendido, statusLote(t, db, semLance.ID))
+	assert.Equal(t, models.StatusParticipacaoCancelado, statusLote(t, db, pendente.ID))
+
+	liquidacao, err := service.GetLiquidacao(ctx, fechar.ID, leiloeiroID, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, liquidacao.LotesVendidos)
+	assert.Equal(t, 1, liquidacao.LotesNaoVendidos)
+	assert.InDelta(t, 95000.47, liquidacao.LiquidoVendedores, 0.001)
+
+	processados, err = service.ProcessarLeiloes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, processados)
+}
@@ -0,0 +1,151 @@
+package linhagem
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// geracoesPadrao profundidade da árvore genealógica quando o cliente não informa
+const geracoesPadrao = 3
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetArvoreGenealogica godoc
+// @Summary Árvore genealógica
+// @Description Ancestrais cadastrados do equino até o número de gerações informado (máximo 10)
+// @Tags Linhagem
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param geracoes query int false "Número de gerações" default(3)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/arvore-genealogica [get]
+// @Security BearerAuth
+func (h *Handler) GetArvoreGenealogica(c *gin.Context) {
+	geracoes, err := strconv.Atoi(c.DefaultQuery("geracoes", strconv.Itoa(geracoesPadrao)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Número de gerações inválido",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	arvore, err := h.service.GetArvoreGenealogica(c.Request.Context(), c.Param("equinoid"), geracoes)
+	if err != nil {
+		h.respondError(c, err, "Erro ao montar árvore genealógica")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Árvore genealógica",
+		Timestamp: time.Now(),
+		Data:      arvore,
+	})
+}
+
+// GetDescendentes godoc
+// @Summary Descendentes
+// @Description Filhos cadastrados do equino, como genitor ou genitora
+// @Tags Linhagem
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/descendentes [get]
+// @Security BearerAuth
+func (h *Handler) GetDescendentes(c *gin.Context) {
+	descendentes, err := h.service.GetDescendentes(c.Request.Context(), c.Param("equinoid"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar descendentes")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Descendentes do equino (total: %d)", len(descendentes)),
+		Timestamp: time.Now(),
+		Data:      descendentes,
+	})
+}
+
+// ValidarParentesco godoc
+// @Summary Validar parentesco
+// @Description Ancestrais em comum, grau de parentesco e coeficiente de consanguinidade estimado entre dois equinos
+// @Tags Linhagem
+// @Produce json
+// @Param equinoid1 query string true "Equinoid do primeiro equino"
+// @Param equinoid2 query string true "Equinoid do segundo equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /linhagem/parentesco [get]
+// @Security BearerAuth
+func (h *Handler) ValidarParentesco(c *gin.Context) {
+	equinoid1 := c.Query("equinoid1")
+	equinoid2 := c.Query("equinoid2")
+	if equinoid1 == "" || equinoid2 == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Informe equinoid1 e equinoid2",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	resultado, err := h.service.ValidarParentesco(c.Request.Context(), equinoid1, equinoid2)
+	if err != nil {
+		h.respondError(c, err, "Erro ao validar parentesco")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Validação de parentesco",
+		Timestamp: time.Now(),
+		Data:      resultado,
+	})
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
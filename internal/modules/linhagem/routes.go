@@ -0,0 +1,20 @@
+package linhagem
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	equinos := rg.Group("/equinos")
+	equinos.Use(authMiddleware)
+	{
+		equinos.GET("/:equinoid/arvore-genealogica", handler.GetArvoreGenealogica)
+		equinos.GET("/:equinoid/descendentes", handler.GetDescendentes)
+	}
+
+	linhagem := rg.Group("/linhagem")
+	linhagem.Use(authMiddleware)
+	{
+		linhagem.GET("/parentesco", handler.ValidarParentesco)
+	}
+}
@@ -0,0 +1,217 @@
+package linhagem
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/equinoid/backend/internal/constants"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	"github.com/equinoid/backend/pkg/cache"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+type Service interface {
+	GetArvoreGenealogica(ctx context.Context, equinoid string, geracoes int) (*models.ArvoreGenealogica, error)
+	ValidarParentesco(ctx context.Context, equinoid1, equinoid2 string) (*models.ResultadoValidacaoParentesco, error)
+	GetDescendentes(ctx context.Context, equinoid string) ([]*models.Equino, error)
+}
+
+type service struct {
+	equinoRepo equinos.Repository
+	cache      cache.CacheInterface
+	logger     *logging.Logger
+}
+
+func NewService(equinoRepo equinos.Repository, cache cache.CacheInterface, logger *logging.Logger) Service {
+	return &service{
+		equinoRepo: equinoRepo,
+		cache:      cache,
+		logger:     logger,
+	}
+}
+
+func (s *service) GetArvoreGenealogica(ctx context.Context, equinoid string, geracoes int) (*models.ArvoreGenealogica, error) {
+	if geracoes < 0 {
+		return nil, &apperrors.ValidationError{Field: "geracoes", Message: "número de gerações deve ser positivo", Value: geracoes}
+	}
+	if geracoes > constants.MaxGeracoesArvoreGenealogica {
+		geracoes = constants.MaxGeracoesArvoreGenealogica
+	}
+
+	equino, err := s.findEquino(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+
+	arvore := &models.ArvoreGenealogica{
+		Equinoid: equino.Equinoid,
+		Nome:     equino.Nome,
+		Geracoes: geracoes,
+	}
+	if geracoes > 0 {
+		arvore.Ancestrais = s.buildAncestrais(ctx, equino, geracoes-1)
+	}
+
+	return arvore, nil
+}
+
+// buildAncestrais monta pai e mãe do equino; genitores não cadastrados no sistema ficam fora da árvore
+func (s *service) buildAncestrais(ctx context.Context, equino *models.Equino, geracoesRestantes int) *models.Ancestrais {
+	ancestrais := &models.Ancestrais{}
+	ancestrais.Pai = s.buildNode(ctx, equino.Genitor, geracoesRestantes)
+	ancestrais.Mae = s.buildNode(ctx, equino.Genitora, geracoesRestantes)
+	return ancestrais
+}
+
+func (s *service) buildNode(ctx context.Context, equinoid string, geracoesRestantes int) *models.AncestralNode {
+	if equinoid == "" {
+		return nil
+	}
+
+	ancestral, err := s.findEquino(ctx, equinoid)
+	if err != nil {
+		return nil
+	}
+
+	node := &models.AncestralNode{
+		Equinoid: ancestral.Equinoid,
+		Nome:     ancestral.Nome,
+		Sexo:     string(ancestral.Sexo),
+	}
+	if geracoesRestantes > 0 {
+		node.Ancestrais = s.buildAncestrais(ctx, ancestral, geracoesRestantes-1)
+	}
+	return node
+}
+
+func (s *service) ValidarParentesco(ctx context.Context, equinoid1, equinoid2 string) (*models.ResultadoValidacaoParentesco, error) {
+	equino1, err := s.findEquino(ctx, equinoid1)
+	if err != nil {
+		return nil, err
+	}
+
+	equino2, err := s.findEquino(ctx, equinoid2)
+	if err != nil {
+		return nil, err
+	}
+
+	if equino1.Equinoid == equino2.Equinoid {
+		return nil, &apperrors.ValidationError{Field: "equinoid2", Message: "informe dois equinos diferentes", Value: equinoid2}
+	}
+
+	ancestrais1 := s.collectAncestrais(ctx, equino1, constants.MaxGeracoesAncestrais)
+	ancestrais2 := s.collectAncestrais(ctx, equino2, constants.MaxGeracoesAncestrais)
+
+	comuns := []string{}
+	presentes := make(map[string]bool, len(ancestrais1))
+	for _, id := range ancestrais1 {
+		presentes[id] = true
+	}
+	for _, id := range ancestrais2 {
+		if presentes[id] {
+			comuns = append(comuns, id)
+		}
+	}
+
+	// Linha direta: um equino é ancestral do outro mesmo sem ancestrais em comum cadastrados
+	linhaDireta := presentes[equino2.Equinoid] || contains(ancestrais2, equino1.Equinoid)
+
+	resultado := &models.ResultadoValidacaoParentesco{
+		Equino1:                    equino1.Equinoid,
+		Equino2:                    equino2.Equinoid,
+		SaoParentes:                linhaDireta || len(comuns) > 0,
+		AncestaisComuns:            comuns,
+		CoeficienteConsanguinidade: float64(len(comuns)) * constants.CoeficienteConsanguinidadeBase,
+	}
+	if resultado.SaoParentes {
+		resultado.GrauParentesco = grauParentesco(equino1, equino2, linhaDireta, len(comuns))
+	}
+
+	return resultado, nil
+}
+
+// collectAncestrais EquinoIds dos ancestrais cadastrados até maxGeracoes, sem repetição
+func (s *service) collectAncestrais(ctx context.Context, equino *models.Equino, maxGeracoes int) []string {
+	ancestrais := []string{}
+	visitados := make(map[string]bool)
+
+	var collect func(equinoid string, geracao int)
+	collect = func(equinoid string, geracao int) {
+		if equinoid == "" || geracao > maxGeracoes || visitados[equinoid] {
+			return
+		}
+		visitados[equinoid] = true
+		ancestrais = append(ancestrais, equinoid)
+
+		ancestral, err := s.findEquino(ctx, equinoid)
+		if err != nil {
+			return
+		}
+		collect(ancestral.Genitor, geracao+1)
+		collect(ancestral.Genitora, geracao+1)
+	}
+
+	collect(equino.Genitor, 1)
+	collect(equino.Genitora, 1)
+	return ancestrais
+}
+
+func grauParentesco(equino1, equino2 *models.Equino, linhaDireta bool, totalComuns int) string {
+	switch {
+	case equino1.Genitor == equino2.Equinoid || equino1.Genitora == equino2.Equinoid:
+		return "filho/filha"
+	case equino2.Genitor == equino1.Equinoid || equino2.Genitora == equino1.Equinoid:
+		return "pai/mãe"
+	case linhaDireta:
+		return "linha direta"
+	case equino1.Genitor != "" && equino1.Genitor == equino2.Genitor && equino1.Genitora != "" && equino1.Genitora == equino2.Genitora:
+		return "irmão/irmã"
+	case (equino1.Genitor != "" && equino1.Genitor == equino2.Genitor) || (equino1.Genitora != "" && equino1.Genitora == equino2.Genitora):
+		return "meio-irmão/meia-irmã"
+	default:
+		return fmt.Sprintf("parentes (%d ancestrais comuns)", totalComuns)
+	}
+}
+
+func (s *service) GetDescendentes(ctx context.Context, equinoid string) ([]*models.Equino, error) {
+	equino, err := s.findEquino(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+
+	descendentes, err := s.equinoRepo.FindByGenitor(ctx, equino.Equinoid)
+	if err != nil {
+		s.logger.LogError(err, "LinhagemService.GetDescendentes", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+
+	porGenitora, err := s.equinoRepo.FindByGenitora(ctx, equino.Equinoid)
+	if err != nil {
+		s.logger.LogError(err, "LinhagemService.GetDescendentes", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+
+	return append(descendentes, porGenitora...), nil
+}
+
+func (s *service) findEquino(ctx context.Context, equinoid string) (*models.Equino, error) {
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "LinhagemService", logging.Fields{"equinoid": equinoid})
+		}
+		return nil, err
+	}
+	return equino, nil
+}
+
+func contains(lista []string, valor string) bool {
+	for _, item := range lista {
+		if item == valor {
+			return true
+		}
+	}
+	return false
+}
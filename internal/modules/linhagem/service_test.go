@@ -0,0 +1,200 @@
+package linhagem
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupLinhagemService(t *testing.T) (Service, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Skip("sqlite driver unavailable for tests")
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.Equino{}, &models.EquinoIdentificador{}))
+
+	return NewService(equinos.NewRepository(db), nil, logging.NewLogger("error")), db
+}
+
+func criarEquino(t *testing.T, db *gorm.DB, equinoid, nome string, sexo models.SexoEquino, genitor, genitora string) *models.Equino {
+	dataNascimento := time.Now().AddDate(-10, 0, 0)
+	equino := &models.Equino{
+		Equinoid:       equinoid,
+		MicrochipID:    "CHIP_" + equinoid,
+		Nome:           nome,
+		Sexo:           sexo,
+		Raca:           "Mangalarga",
+		Pelagem:        "Alazão",
+		PaisOrigem:     "BRA",
+		DataNascimento: &dataNascimento,
+		Genitor:        genitor,
+		Genitora:       genitora,
+		Status:         models.StatusAtivo,
+		ProprietarioID: 1,
+	}
+	require.NoError(t, db.Create(equino).Error)
+	return equino
+}
+
+func TestLinhagemService_GetArvoreGenealogica(t *testing.T) {
+	service, db := setupLinhagemService(t)
+
+	criarEquino(t, db, "BRA-2010-00000001", "Avô", models.SexoMacho, "", "")
+	criarEquino(t, db, "BRA-2012-00000001", "Mãe", models.SexoFemea, "", "")
+	criarEquino(t, db, "BRA-2015-00000001", "Pai", models.SexoMacho, "BRA-2010-00000001", "")
+	criarEquino(t, db, "BRA-2020-00000001", "Filho", models.SexoMacho, "BRA-2015-00000001", "BRA-2012-00000001")
+
+	t.Run("Obter árvore genealógica", func(t *testing.T) {
+		arvore, err := service.GetArvoreGenealogica(context.Background(), "BRA-2020-00000001", 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, "BRA-2020-00000001", arvore.Equinoid)
+		assert.Equal(t, 2, arvore.Geracoes)
+		require.NotNil(t, arvore.Ancestrais)
+		require.NotNil(t, arvore.Ancestrais.Pai)
+		require.NotNil(t, arvore.Ancestrais.Mae)
+		assert.Equal(t, "BRA-2015-00000001", arvore.Ancestrais.Pai.Equinoid)
+		assert.Equal(t, "BRA-2012-00000001", arvore.Ancestrais.Mae.Equinoid)
+		require.NotNil(t, arvore.Ancestrais.Pai.Ancestrais)
+		require.NotNil(t, arvore.Ancestrais.Pai.Ancestrais.Pai)
+		assert.Equal(t, "BRA-2010-00000001", arvore.Ancestrais.Pai.Ancestrais.Pai.Equinoid)
+		assert.Nil(t, arvore.Ancestrais.Pai.Ancestrais.Mae)
+	})
+
+	t.Run("Uma geração não inclui avós", func(t *testing.T) {
+		arvore, err := service.GetArvoreGenealogica(context.Background(), "BRA-2020-00000001", 1)
+
+		require.NoError(t, err)
+		require.NotNil(t, arvore.Ancestrais.Pai)
+		assert.Nil(t, arvore.Ancestrais.Pai.Ancestrais)
+	})
+
+	t.Run("Equino não encontrado", func(t *testing.T) {
+		_, err := service.GetArvoreGenealogica(context.Background(), "BRA-9999-99999999", 3)
+
+		assert.True(t, apperrors.IsNotFound(err))
+	})
+
+	t.Run("Gerações negativas", func(t *testing.T) {
+		_, err := service.GetArvoreGenealogica(context.Background(), "BRA-2020-00000001", -1)
+
+		assert.True(t, apperrors.IsValidation(err))
+	})
+
+	t.Run("Limitar gerações a 10", func(t *testing.T) {
+		arvore, err := service.GetArvoreGenealogica(context.Background(), "BRA-2020-00000001", 20)
+
+		require.NoError(t, err)
+		assert.Equal(t, 10, arvore.Geracoes)
+	})
+}
+
+func TestLinhagemService_ValidarParentesco(t *testing.T) {
+	service, db := setupLinhagemService(t)
+
+	criarEquino(t, db, "BRA-2015-00000010", "Pai Comum", models.SexoMacho, "", "")
+	criarEquino(t, db, "BRA-2015-00000011", "Mãe Comum", models.SexoFemea, "", "")
+	criarEquino(t, db, "BRA-2015-00000012", "Outra Mãe", models.SexoFemea, "", "")
+	criarEquino(t, db, "BRA-2020-00000010", "Filho 1", models.SexoMacho, "BRA-2015-00000010", "BRA-2015-00000011")
+	criarEquino(t, db, "BRA-2020-00000011", "Filho 2", models.SexoFemea, "BRA-2015-00000010", "BRA-2015-00000011")
+	criarEquino(t, db, "BRA-2020-00000012", "Filho 3", models.SexoFemea, "BRA-2015-00000010", "BRA-2015-00000012")
+	criarEquino(t, db, "BRA-2020-00000020", "Não Parente", models.SexoMacho, "", "")
+
+	t.Run("Detectar irmãos", func(t *testing.T) {
+		resultado, err := service.ValidarParentesco(context.Background(), "BRA-2020-00000010", "BRA-2020-00000011")
+
+		require.NoError(t, err)
+		assert.True(t, resultado.SaoParentes)
+		assert.Equal(t, "irmão/irmã", resultado.GrauParentesco)
+		assert.ElementsMatch(t, []string{"BRA-2015-00000010", "BRA-2015-00000011"}, resultado.AncestaisComuns)
+		assert.Equal(t, 0.125, resultado.CoeficienteConsanguinidade)
+	})
+
+	t.Run("Detectar meio-irmãos", func(t *testing.T) {
+		resultado, err := service.ValidarParentesco(context.Background(), "BRA-2020-00000010", "BRA-2020-00000012")
+
+		require.NoError(t, err)
+		assert.True(t, resultado.SaoParentes)
+		assert.Equal(t, "meio-irmão/meia-irmã", resultado.GrauParentesco)
+		assert.Equal(t, []string{"BRA-2015-00000010"}, resultado.AncestaisComuns)
+	})
+
+	t.Run("Detectar pai e filho", func(t *testing.T) {
+		resultado, err := service.ValidarParentesco(context.Background(), "BRA-2015-00000010", "BRA-2020-00000010")
+
+		require.NoError(t, err)
+		assert.True(t, resultado.SaoParentes)
+		assert.Equal(t, "pai/mãe", resultado.GrauParentesco)
+	})
+
+	t.Run("Não parentes", func(t *testing.T) {
+		resultado, err := service.ValidarParentesco(context.Background(), "BRA-2020-00000010", "BRA-2020-00000020")
+
+		require.NoError(t, err)
+		assert.False(t, resultado.SaoParentes)
+		assert.Empty(t, resultado.GrauParentesco)
+		assert.Empty(t, resultado.AncestaisComuns)
+		assert.Equal(t, 0.0, resultado.CoeficienteConsanguinidade)
+	})
+
+	t.Run("Mesmo equino", func(t *testing.T) {
+		_, err := service.ValidarParentesco(context.Background(), "BRA-2020-00000010", "BRA-2020-00000010")
+
+		assert.True(t, apperrors.IsValidation(err))
+	})
+}
+
+func TestLinhagemService_GetDescendentes(t *testing.T) {
+	service, db := setupLinhagemService(t)
+
+	criarEquino(t, db, "BRA-2015-00000020", "Reprodutor", models.SexoMacho, "", "")
+	criarEquino(t, db, "BRA-2015-00000021", "Matriz", models.SexoFemea, "", "")
+	for i := 1; i <= 5; i++ {
+		genitora := ""
+		if i%2 == 0 {
+			genitora = "BRA-2015-00000021"
+		}
+		criarEquino(t, db, fmt.Sprintf("BRA-2020-0000%04d", i+50), fmt.Sprintf("Descendente %d", i), models.SexoMacho, "BRA-2015-00000020", genitora)
+	}
+	criarEquino(t, db, "BRA-2020-00000100", "Sem Descendentes", models.SexoMacho, "", "")
+
+	t.Run("Listar descendentes do reprodutor", func(t *testing.T) {
+		descendentes, err := service.GetDescendentes(context.Background(), "BRA-2015-00000020")
+
+		require.NoError(t, err)
+		assert.Len(t, descendentes, 5)
+	})
+
+	t.Run("Listar descendentes da matriz", func(t *testing.T) {
+		descendentes, err := service.GetDescendentes(context.Background(), "BRA-2015-00000021")
+
+		require.NoError(t, err)
+		assert.Len(t, descendentes, 2)
+	})
+
+	t.Run("Sem descendentes", func(t *testing.T) {
+		descendentes, err := service.GetDescendentes(context.Background(), "BRA-2020-00000100")
+
+		require.NoError(t, err)
+		assert.Empty(t, descendentes)
+	})
+
+	t.Run("Equino não encontrado", func(t *testing.T) {
+		_, err := service.GetDescendentes(context.Background(), "BRA-9999-99999999")
+
+		assert.True(t, apperrors.IsNotFound(err))
+	})
+}
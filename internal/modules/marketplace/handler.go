@@ -0,0 +1,834 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead folga para os campos do formulário além do arquivo
+const multipartOverhead = 1 << 20
+
+type Handler struct {
+	service       Service
+	maxUploadSize int64
+	logger        *logging.Logger
+}
+
+func NewHandler(service Service, maxUploadSize int64, logger *logging.Logger) *Handler {
+	return &Handler{
+		service:       service,
+		maxUploadSize: maxUploadSize,
+		logger:        logger,
+	}
+}
+
+// BuscarAnuncios godoc
+// @Summary Buscar anúncios do marketplace
+// @Description Anúncios ativos de animais, sêmen, embriões e equipamentos
+// @Tags Marketplace
+// @Produce json
+// @Param tipo query string false "animal, semen, embrio ou equipamento"
+// @Param q query string false "Texto no título ou na descrição"
+// @Param preco_min query number false "Preço mínimo"
+// @Param preco_max query number false "Preço máximo"
+// @Param raca query string false "Raça do equino anunciado"
+// @Param estado query string false "UF"
+// @Param cidade query string false "Cidade"
+// @Param vendedor_id query int false "Anúncios de um vendedor"
+// @Param ordem query string false "recentes, menor_preco ou maior_preco" default(recentes)
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios [get]
+// @Security BearerAuth
+func (h *Handler) BuscarAnuncios(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := parsePagination(c)
+
+	filtro := FiltroAnuncios{
+		Tipo:   models.TipoAnuncio(c.Query("tipo")),
+		Texto:  c.Query("q"),
+		Raca:   c.Query("raca"),
+		Estado: c.Query("estado"),
+		Cidade: c.Query("cidade"),
+		Ordem:  OrdemBusca(c.Query("ordem")),
+		Page:   page,
+		Limit:  limit,
+	}
+	var err error
+	if filtro.PrecoMin, err = parseFloatQuery(c, "preco_min"); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+	if filtro.PrecoMax, err = parseFloatQuery(c, "preco_max"); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+	if v := c.Query("vendedor_id"); v != "" {
+		vendedorID, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			h.badRequest(c, fmt.Errorf("vendedor_id inválido"))
+			return
+		}
+		filtro.VendedorID = uint(vendedorID)
+	}
+
+	anuncios, total, err := h.service.Buscar(c.Request.Context(), userID, filtro)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar anúncios")
+		return
+	}
+	h.respondPage(c, fmt.Sprintf("Anúncios encontrados (total: %d)", total), anuncios, page, limit, total)
+}
+
+// ListMeusAnuncios godoc
+// @Summary Listar meus anúncios
+// @Description Anúncios do próprio vendedor em qualquer etapa, dos alterados mais recentemente aos mais antigos
+// @Tags Marketplace
+// @Produce json
+// @Param status query string false "rascunho, ativo, pausado, vendido ou expirado"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/meus [get]
+// @Security BearerAuth
+func (h *Handler) ListMeusAnuncios(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := parsePagination(c)
+
+	anuncios, total, err := h.service.ListMeus(c.Request.Context(), userID, models.StatusAnuncio(c.Query("status")), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar anúncios")
+		return
+	}
+	h.respondPage(c, fmt.Sprintf("Meus anúncios (total: %d)", total), anuncios, page, limit, total)
+}
+
+// CreateAnuncio godoc
+// @Summary Criar anúncio
+// @Description Cria o anúncio como rascunho. Animal exige equino do vendedor; sêmen exige garanhão do vendedor com avaliação de sêmen apta; embrião pode indicar a doadora
+// @Tags Marketplace
+// @Accept json
+// @Produce json
+// @Param anuncio body models.CreateAnuncioRequest true "Dados do anúncio"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios [post]
+// @Security BearerAuth
+func (h *Handler) CreateAnuncio(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAnuncioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	anuncio, err := h.service.Criar(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao criar anúncio")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Anúncio criado como rascunho",
+		Timestamp: time.Now(),
+		Data:      anuncio,
+	})
+}
+
+// GetAnuncio godoc
+// @Summary Detalhar anúncio
+// @Description Anúncios ativos e vendidos são públicos; os demais só o vendedor vê
+// @Tags Marketplace
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetAnuncio(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	anuncio, err := h.service.Get(c.Request.Context(), id, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar anúncio")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Anúncio encontrado",
+		Timestamp: time.Now(),
+		Data:      anuncio,
+	})
+}
+
+// UpdateAnuncio godoc
+// @Summary Atualizar anúncio
+// @Description Altera os dados de um anúncio ainda não vendido; o tipo não muda depois de criado
+// @Tags Marketplace
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Param anuncio body models.UpdateAnuncioRequest true "Campos a alterar"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id} [put]
+// @Security BearerAuth
+func (h *Handler) UpdateAnuncio(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAnuncioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	anuncio, err := h.service.Atualizar(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao atualizar anúncio")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Anúncio atualizado",
+		Timestamp: time.Now(),
+		Data:      anuncio,
+	})
+}
+
+// DeleteAnuncio godoc
+// @Summary Excluir anúncio
+// @Description Remove um anúncio não vendido (vendedor ou administrador)
+// @Tags Marketplace
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteAnuncio(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Excluir(c.Request.Context(), id, userID, userType); err != nil {
+		h.respondError(c, err, "Erro ao excluir anúncio")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Anúncio excluído",
+		Timestamp: time.Now(),
+	})
+}
+
+// PublicarAnuncio godoc
+// @Summary Publicar anúncio
+// @Description Coloca no ar por 60 dias um rascunho, um anúncio pausado ou um expirado, conferindo de novo as regras do tipo
+// @Tags Marketplace
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/publicar [post]
+// @Security BearerAuth
+func (h *Handler) PublicarAnuncio(c *gin.Context) {
+	h.transicao(c, h.service.Publicar, "Anúncio publicado", "Erro ao publicar anúncio")
+}
+
+// PausarAnuncio godoc
+// @Summary Pausar anúncio
+// @Description Tira o anúncio ativo da busca até ser publicado de novo
+// @Tags Marketplace
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/pausar [post]
+// @Security BearerAuth
+func (h *Handler) PausarAnuncio(c *gin.Context) {
+	h.transicao(c, h.service.Pausar, "Anúncio pausado", "Erro ao pausar anúncio")
+}
+
+// VenderAnuncio godoc
+// @Summary Registrar venda
+// @Description Encerra o anúncio como vendido. Informando o comprador, ele pode avaliar o vendedor depois do pagamento liberado ou da confirmação da compra; na venda de animal o perfil social do equino passa a vendido
+// @Tags Marketplace
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Param venda body models.VenderAnuncioRequest false "Comprador"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/vender [post]
+// @Security BearerAuth
+func (h *Handler) VenderAnuncio(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.VenderAnuncioRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.badRequest(c, err)
+		return
+	}
+
+	anuncio, err := h.service.Vender(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar venda")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Venda registrada",
+		Timestamp: time.Now(),
+		Data:      anuncio,
+	})
+}
+
+// UploadFoto godoc
+// @Summary Enviar foto do anúncio
+// @Description Upload multipart de imagem (JPEG, PNG, GIF ou WebP), até 10 por anúncio
+// @Tags Marketplace
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Param arquivo formData file true "Imagem"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/fotos [post]
+// @Security BearerAuth
+func (h *Handler) UploadFoto(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	arquivo, ok := h.readFile(c)
+	if !ok {
+		return
+	}
+
+	foto, err := h.service.AdicionarFoto(c.Request.Context(), id, userID, arquivo)
+	if err != nil {
+		h.respondError(c, err, "Erro ao enviar foto")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Foto adicionada ao anúncio",
+		Timestamp: time.Now(),
+		Data:      foto,
+	})
+}
+
+// DeleteFoto godoc
+// @Summary Remover foto do anúncio
+// @Tags Marketplace
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Param foto_id path int true "ID da foto"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/fotos/{foto_id} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteFoto(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	fotoID, err := strconv.ParseUint(c.Param("foto_id"), 10, 32)
+	if err != nil {
+		h.badRequest(c, fmt.Errorf("foto_id inválido"))
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoverFoto(c.Request.Context(), id, uint(fotoID), userID); err != nil {
+		h.respondError(c, err, "Erro ao remover foto")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Foto removida",
+		Timestamp: time.Now(),
+	})
+}
+
+// Favoritar godoc
+// @Summary Favoritar anúncio
+// @Description Salva um anúncio ativo na lista do usuário; repetir não altera nada
+// @Tags Marketplace
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/favorito [post]
+// @Security BearerAuth
+func (h *Handler) Favoritar(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Favoritar(c.Request.Context(), id, userID); err != nil {
+		h.respondError(c, err, "Erro ao favoritar anúncio")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Anúncio salvo nos favoritos",
+		Timestamp: time.Now(),
+	})
+}
+
+// Desfavoritar godoc
+// @Summary Remover anúncio dos favoritos
+// @Tags Marketplace
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/favorito [delete]
+// @Security BearerAuth
+func (h *Handler) Desfavoritar(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Desfavoritar(c.Request.Context(), id, userID); err != nil {
+		h.respondError(c, err, "Erro ao remover favorito")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Anúncio removido dos favoritos",
+		Timestamp: time.Now(),
+	})
+}
+
+// ListFavoritos godoc
+// @Summary Listar favoritos
+// @Description Anúncios salvos pelo usuário, dos mais recentes aos mais antigos
+// @Tags Marketplace
+// @Produce json
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/favoritos [get]
+// @Security BearerAuth
+func (h *Handler) ListFavoritos(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := parsePagination(c)
+
+	anuncios, total, err := h.service.ListFavoritos(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar favoritos")
+		return
+	}
+	h.respondPage(c, fmt.Sprintf("Favoritos (total: %d)", total), anuncios, page, limit, total)
+}
+
+// ConfirmarCompra godoc
+// @Summary Confirmar compra
+// @Description O comprador registrado na venda confirma o recebimento. Compras pagas pela plataforma são confirmadas liberando o pagamento
+// @Tags Marketplace
+// @Produce json
+// @Param id path int true "ID do anúncio vendido"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/confirmar-compra [post]
+// @Security BearerAuth
+func (h *Handler) ConfirmarCompra(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	anuncio, err := h.service.ConfirmarCompra(c.Request.Context(), id, userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao confirmar compra")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Compra confirmada",
+		Timestamp: time.Now(),
+		Data:      anuncio,
+	})
+}
+
+// AvaliarVendedor godoc
+// @Summary Avaliar vendedor
+// @Description Nota de 1 a 5 do comprador registrado na venda, disponível depois do pagamento liberado ou da confirmação da compra; cada compra é avaliada uma única vez
+// @Tags Marketplace
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do anúncio vendido"
+// @Param avaliacao body models.AvaliarVendedorRequest true "Avaliação"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/avaliacao [post]
+// @Security BearerAuth
+func (h *Handler) AvaliarVendedor(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.AvaliarVendedorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	avaliacao, err := h.service.AvaliarVendedor(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao avaliar vendedor")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Avaliação registrada",
+		Timestamp: time.Now(),
+		Data:      avaliacao,
+	})
+}
+
+// GetReputacao godoc
+// @Summary Reputação do vendedor
+// @Description Média das notas, vendas concluídas e avaliações mais recentes
+// @Tags Marketplace
+// @Produce json
+// @Param id path int true "ID do vendedor"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /marketplace/vendedores/{id}/avaliacoes [get]
+// @Security BearerAuth
+func (h *Handler) GetReputacao(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	if _, _, ok := h.requireUser(c); !ok {
+		return
+	}
+	page, limit := parsePagination(c)
+
+	reputacao, err := h.service.Reputacao(c.Request.Context(), id, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao carregar reputação do vendedor")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Reputação do vendedor (avaliações: %d)", reputacao.Total),
+		Timestamp: time.Now(),
+		Data:      reputacao,
+	})
+}
+
+type acaoAnuncio func(ctx context.Context, id uint, userID uint) (*models.AnuncioMarketplace, error)
+
+// transicao executa uma mudança de status do anúncio pelo vendedor
+func (h *Handler) transicao(c *gin.Context, acao acaoAnuncio, sucesso, fallback string) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	anuncio, err := acao(c.Request.Context(), id, userID)
+	if err != nil {
+		h.respondError(c, err, fallback)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   sucesso,
+		Timestamp: time.Now(),
+		Data:      anuncio,
+	})
+}
+
+// readFile lê o campo multipart "arquivo" respeitando o limite de tamanho de upload
+func (h *Handler) readFile(c *gin.Context) (*models.ArquivoEnviado, bool) {
+	header, err := c.FormFile("arquivo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Arquivo não enviado ou maior que o limite permitido",
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+	if header.Size > h.maxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Arquivo excede o limite de %d bytes", h.maxUploadSize),
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.respondError(c, err, "Erro ao ler arquivo enviado")
+		return nil, false
+	}
+	defer file.Close()
+
+	conteudo, err := io.ReadAll(io.LimitReader(file, h.maxUploadSize+1))
+	if err != nil {
+		h.respondError(c, err, "Erro ao ler arquivo enviado")
+		return nil, false
+	}
+	return &models.ArquivoEnviado{Nome: header.Filename, Conteudo: conteudo}, true
+}
+
+func (h *Handler) respondPage(c *gin.Context, message string, data interface{}, page, limit int, total int64) {
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: data,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: totalPages,
+			},
+		},
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, string, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, "", false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	return userID, userType, true
+}
+
+func (h *Handler) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *Handler) badRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Success:   false,
+		Error:     "Dados inválidos: " + err.Error(),
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	case apperrors.IsBusiness(err):
+		status = http.StatusServiceUnavailable
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
+
+func parsePagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+// parseFloatQuery valor numérico opcional da query string
+func parseFloatQuery(c *gin.Context, nome string) (*float64, error) {
+	v := c.Query(nome)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s inválido", nome)
+	}
+	return &f, nil
+}
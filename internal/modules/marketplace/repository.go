@@ -0,0 +1,455 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrdemBusca ordenação dos resultados da busca
+type OrdemBusca string
+
+const (
+	OrdemRecentes   OrdemBusca = "recentes"
+	OrdemMenorPreco OrdemBusca = "menor_preco"
+	OrdemMaiorPreco OrdemBusca = "maior_preco"
+	// OrdemAtualizados usada na lista do próprio vendedor, que inclui rascunhos ainda sem data de publicação
+	OrdemAtualizados OrdemBusca = "atualizados"
+)
+
+// FiltroAnuncios critérios da busca de anúncios; sem status informado a busca traz apenas os ativos
+type FiltroAnuncios struct {
+	Tipo       models.TipoAnuncio
+	Status     []models.StatusAnuncio
+	VendedorID uint
+	Texto      string
+	PrecoMin   *float64
+	PrecoMax   *float64
+	Raca       string
+	Estado     string
+	Cidade     string
+	Ordem      OrdemBusca
+	Page       int
+	Limit      int
+}
+
+type Repository interface {
+	Create(ctx context.Context, anuncio *models.AnuncioMarketplace) error
+	Find(ctx context.Context, id uint) (*models.AnuncioMarketplace, error)
+	Update(ctx context.Context, anuncio *models.AnuncioMarketplace) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filtro FiltroAnuncios) ([]*models.AnuncioMarketplace, int64, error)
+	FindAnuncioEquino(ctx context.Context, equinoid string, tipo models.TipoAnuncio, status []models.StatusAnuncio, excetoID uint) (*models.AnuncioMarketplace, error)
+
+	AlterarStatus(ctx context.Context, id uint, de []models.StatusAnuncio, para models.StatusAnuncio, campos map[string]interface{}) (bool, error)
+	ConfirmarCompra(ctx context.Context, id uint, compradorID uint, em time.Time) (bool, error)
+	SituacaoPagamento(ctx context.Context, anuncioID uint) (models.SituacaoPagamento, error)
+	ListVencidos(ctx context.Context, agora time.Time, limit int) ([]*models.AnuncioMarketplace, error)
+
+	CreateFoto(ctx context.Context, foto *models.FotoAnuncio) error
+	FindFoto(ctx context.Context, anuncioID, fotoID uint) (*models.FotoAnuncio, error)
+	DeleteFoto(ctx context.Context, id uint) error
+	CountFotos(ctx context.Context, anuncioID uint) (int64, int, error)
+
+	Favoritar(ctx context.Context, favorito *models.FavoritoAnuncio) (bool, error)
+	Desfavoritar(ctx context.Context, userID, anuncioID uint) (bool, error)
+	ListFavoritos(ctx context.Context, userID uint, page, limit int) ([]*models.AnuncioMarketplace, int64, error)
+	FavoritosDe(ctx context.Context, userID uint, anuncioIDs []uint) (map[uint]bool, error)
+
+	UsuarioExiste(ctx context.Context, userID uint) (bool, error)
+	CreateAvaliacao(ctx context.Context, avaliacao *models.AvaliacaoVendedor) error
+	FindAvaliacao(ctx context.Context, anuncioID uint) (*models.AvaliacaoVendedor, error)
+	ListAvaliacoes(ctx context.Context, vendedorID uint, page, limit int) ([]*models.AvaliacaoVendedor, int64, error)
+	ResumoVendedor(ctx context.Context, vendedorID uint) (media float64, vendas int64, err error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, anuncio *models.AnuncioMarketplace) error {
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(anuncio).Error; err != nil {
+		return apperrors.NewDatabaseError("create_anuncio", "erro ao criar anúncio", err)
+	}
+	return nil
+}
+
+func (r *repository) Find(ctx context.Context, id uint) (*models.AnuncioMarketplace, error) {
+	var anuncio models.AnuncioMarketplace
+	err := r.db.WithContext(ctx).
+		Preload("Usuario").Preload("Equino").Preload("AvaliacaoSemen").
+		Preload("Fotos", func(db *gorm.DB) *gorm.DB { return db.Order("ordem ASC, id ASC") }).
+		Where("id = ?", id).First(&anuncio).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "anuncio", Message: "anúncio não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_anuncio", "erro ao buscar anúncio", err)
+	}
+	return &anuncio, nil
+}
+
+func (r *repository) Update(ctx context.Context, anuncio *models.AnuncioMarketplace) error {
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Save(anuncio).Error; err != nil {
+		return apperrors.NewDatabaseError("update_anuncio", "erro ao atualizar anúncio", err)
+	}
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&models.AnuncioMarketplace{}, id).Error; err != nil {
+		return apperrors.NewDatabaseError("delete_anuncio", "erro ao excluir anúncio", err)
+	}
+	return nil
+}
+
+func (r *repository) List(ctx context.Context, filtro FiltroAnuncios) ([]*models.AnuncioMarketplace, int64, error) {
+	var anuncios []*models.AnuncioMarketplace
+	var total int64
+
+	status := filtro.Status
+	if len(status) == 0 {
+		status = []models.StatusAnuncio{models.StatusAnuncioAtivo}
+	}
+	query := r.db.WithContext(ctx).Model(&models.AnuncioMarketplace{}).Where("status IN ?", status)
+	if filtro.Tipo != "" {
+		query = query.Where("tipo = ?", filtro.Tipo)
+	}
+	if filtro.VendedorID != 0 {
+		query = query.Where("usuario_id = ?", filtro.VendedorID)
+	}
+	if texto := strings.ToLower(strings.TrimSpace(filtro.Texto)); texto != "" {
+		like := "%" + texto + "%"
+		query = query.Where("(LOWER(titulo) LIKE ? OR LOWER(descricao) LIKE ?)", like, like)
+	}
+	if filtro.PrecoMin != nil {
+		query = query.Where("preco >= ?", *filtro.PrecoMin)
+	}
+	if filtro.PrecoMax != nil {
+		query = query.Where("preco <= ?", *filtro.PrecoMax)
+	}
+	if filtro.Raca != "" {
+		racas := r.db.Model(&models.Equino{}).Select("equinoid").Where("LOWER(raca) = ?", strings.ToLower(filtro.Raca))
+		query = query.Where("equinoid IN (?)", racas)
+	}
+	if filtro.Estado != "" {
+		query = query.Where("estado = ?", strings.ToUpper(filtro.Estado))
+	}
+	if filtro.Cidade != "" {
+		query = query.Where("LOWER(cidade) = ?", strings.ToLower(filtro.Cidade))
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_anuncios", "erro ao contar anúncios", err)
+	}
+
+	ordem := "publicado_em DESC, id DESC"
+	switch filtro.Ordem {
+	case OrdemMenorPreco:
+		ordem = "preco ASC, id DESC"
+	case OrdemMaiorPreco:
+		ordem = "preco DESC, id DESC"
+	case OrdemAtualizados:
+		ordem = "updated_at DESC, id DESC"
+	}
+
+	offset := (filtro.Page - 1) * filtro.Limit
+	err := query.Preload("Equino").
+		Preload("Fotos", func(db *gorm.DB) *gorm.DB { return db.Order("ordem ASC, id ASC") }).
+		Offset(offset).Limit(filtro.Limit).
+		Order(ordem).
+		Find(&anuncios).Error
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_anuncios", "erro ao listar anúncios", err)
+	}
+	return anuncios, total, nil
+}
+
+// FindAnuncioEquino outro anúncio do mesmo equino e tipo em um dos status informados
+func (r *repository) FindAnuncioEquino(ctx context.Context, equinoid string, tipo models.TipoAnuncio, status []models.StatusAnuncio, excetoID uint) (*models.AnuncioMarketplace, error) {
+	var anuncio models.AnuncioMarketplace
+	err := r.db.WithContext(ctx).
+		Where("equinoid = ? AND tipo = ? AND status IN ? AND id <> ?", equinoid, tipo, status, excetoID).
+		First(&anuncio).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperrors.NewDatabaseError("find_anuncio_equino", "erro ao buscar anúncios do equino", err)
+	}
+	return &anuncio, nil
+}
+
+// AlterarStatus muda o status só se o anúncio ainda estiver em um dos status de origem, evitando transições
+// concorrentes
+func (r *repository) AlterarStatus(ctx context.Context, id uint, de []models.StatusAnuncio, para models.StatusAnuncio, campos map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": para, "updated_at": time.Now()}
+	for campo, valor := range campos {
+		updates[campo] = valor
+	}
+	result := r.db.WithContext(ctx).Model(&models.AnuncioMarketplace{}).
+		Where("id = ? AND status IN ?", id, de).
+		Updates(updates)
+	if result.Error != nil {
+		return false, apperrors.NewDatabaseError("alterar_status_anuncio", "erro ao atualizar status do anúncio", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ConfirmarCompra registra a confirmação do comprador uma única vez; devolve false se já estava confirmada
+func (r *repository) ConfirmarCompra(ctx context.Context, id uint, compradorID uint, em time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.AnuncioMarketplace{}).
+		Where("id = ? AND status = ? AND comprador_id = ? AND confirmado_em IS NULL", id, models.StatusAnuncioVendido, compradorID).
+		Updates(map[string]interface{}{"confirmado_em": em, "updated_at": time.Now()})
+	if result.Error != nil {
+		return false, apperrors.NewDatabaseError("confirmar_compra_anuncio", "erro ao confirmar a compra", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SituacaoPagamento status do pagamento mais recente da venda pela plataforma; vazio quando a venda não foi paga
+// por aqui
+func (r *repository) SituacaoPagamento(ctx context.Context, anuncioID uint) (models.SituacaoPagamento, error) {
+	var pagamentos []models.Pagamento
+	err := r.db.WithContext(ctx).Select("status").
+		Where("origem = ? AND origem_id = ?", models.OrigemPagamentoMarketplace, anuncioID).
+		Order("created_at DESC, id DESC").Limit(1).
+		Find(&pagamentos).Error
+	if err != nil {
+		return "", apperrors.NewDatabaseError("situacao_pagamento_anuncio", "erro ao buscar pagamento da venda", err)
+	}
+	if len(pagamentos) == 0 {
+		return "", nil
+	}
+	return pagamentos[0].Status, nil
+}
+
+// ListVencidos anúncios publicados ou pausados com a validade vencida
+func (r *repository) ListVencidos(ctx context.Context, agora time.Time, limit int) ([]*models.AnuncioMarketplace, error) {
+	var anuncios []*models.AnuncioMarketplace
+	err := r.db.WithContext(ctx).
+		Where("status IN ? AND expira_em < ?", []models.StatusAnuncio{models.StatusAnuncioAtivo, models.StatusAnuncioPausado}, agora).
+		Order("expira_em ASC").
+		Limit(limit).
+		Find(&anuncios).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("list_anuncios_vencidos", "erro ao listar anúncios vencidos", err)
+	}
+	return anuncios, nil
+}
+
+func (r *repository) CreateFoto(ctx context.Context, foto *models.FotoAnuncio) error {
+	if err := r.db.WithContext(ctx).Create(foto).Error; err != nil {
+		return apperrors.NewDatabaseError("create_foto_anuncio", "erro ao registrar foto do anúncio", err)
+	}
+	return nil
+}
+
+func (r *repository) FindFoto(ctx context.Context, anuncioID, fotoID uint) (*models.FotoAnuncio, error) {
+	var foto models.FotoAnuncio
+	err := r.db.WithContext(ctx).Where("id = ? AND anuncio_id = ?", fotoID, anuncioID).First(&foto).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "foto", Message: "foto não encontrada no anúncio", ID: fotoID}
+		}
+		return nil, apperrors.NewDatabaseError("find_foto_anuncio", "erro ao buscar foto do anúncio", err)
+	}
+	return &foto, nil
+}
+
+func (r *repository) DeleteFoto(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&models.FotoAnuncio{}, id).Error; err != nil {
+		return apperrors.NewDatabaseError("delete_foto_anuncio", "erro ao remover foto do anúncio", err)
+	}
+	return nil
+}
+
+// CountFotos quantidade de fotos do anúncio e a maior ordem usada
+func (r *repository) CountFotos(ctx context.Context, anuncioID uint) (int64, int, error) {
+	var resumo struct {
+		Total int64
+		Ordem int
+	}
+	err := r.db.WithContext(ctx).Model(&models.FotoAnuncio{}).
+		Select("COUNT(*) AS total, COALESCE(MAX(ordem), 0) AS ordem").
+		Where("anuncio_id = ?", anuncioID).
+		Scan(&resumo).Error
+	if err != nil {
+		return 0, 0, apperrors.NewDatabaseError("count_fotos_anuncio", "erro ao contar fotos do anúncio", err)
+	}
+	return resumo.Total, resumo.Ordem, nil
+}
+
+// Favoritar grava o favorito e o contador do anúncio na mesma transação; repetir não altera nada
+func (r *repository) Favoritar(ctx context.Context, favorito *models.FavoritoAnuncio) (bool, error) {
+	criado := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(favorito)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		criado = true
+		return tx.Model(&models.AnuncioMarketplace{}).Where("id = ?", favorito.AnuncioID).
+			UpdateColumn("total_favoritos", gorm.Expr("total_favoritos + 1")).Error
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("favoritar_anuncio", "erro ao favoritar anúncio", err)
+	}
+	return criado, nil
+}
+
+func (r *repository) Desfavoritar(ctx context.Context, userID, anuncioID uint) (bool, error) {
+	removido := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("usuario_id = ? AND anuncio_id = ?", userID, anuncioID).Delete(&models.FavoritoAnuncio{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		removido = true
+		return tx.Model(&models.AnuncioMarketplace{}).Where("id = ? AND total_favoritos > 0", anuncioID).
+			UpdateColumn("total_favoritos", gorm.Expr("total_favoritos - 1")).Error
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("desfavoritar_anuncio", "erro ao remover favorito", err)
+	}
+	return removido, nil
+}
+
+// ListFavoritos anúncios salvos pelo usuário, dos mais recentes aos mais antigos; excluídos pelo vendedor somem da lista
+func (r *repository) ListFavoritos(ctx context.Context, userID uint, page, limit int) ([]*models.AnuncioMarketplace, int64, error) {
+	var favoritos []*models.FavoritoAnuncio
+	var total int64
+
+	anuncios := r.db.Model(&models.AnuncioMarketplace{}).Select("id")
+	query := r.db.WithContext(ctx).Model(&models.FavoritoAnuncio{}).
+		Where("usuario_id = ? AND anuncio_id IN (?)", userID, anuncios)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_favoritos", "erro ao contar favoritos", err)
+	}
+
+	offset := (page - 1) * limit
+	err := query.
+		Preload("Anuncio").
+		Preload("Anuncio.Fotos", func(db *gorm.DB) *gorm.DB { return db.Order("ordem ASC, id ASC") }).
+		Offset(offset).Limit(limit).
+		Order("created_at DESC, id DESC").
+		Find(&favoritos).Error
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_favoritos", "erro ao listar favoritos", err)
+	}
+
+	lista := make([]*models.AnuncioMarketplace, 0, len(favoritos))
+	for _, favorito := range favoritos {
+		if favorito.Anuncio != nil {
+			favorito.Anuncio.Favorito = true
+			lista = append(lista, favorito.Anuncio)
+		}
+	}
+	return lista, total, nil
+}
+
+// FavoritosDe quais dos anúncios informados o usuário salvou
+func (r *repository) FavoritosDe(ctx context.Context, userID uint, anuncioIDs []uint) (map[uint]bool, error) {
+	favoritos := make(map[uint]bool, len(anuncioIDs))
+	if len(anuncioIDs) == 0 {
+		return favoritos, nil
+	}
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&models.FavoritoAnuncio{}).
+		Where("usuario_id = ? AND anuncio_id IN ?", userID, anuncioIDs).
+		Pluck("anuncio_id", &ids).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("favoritos_usuario", "erro ao carregar favoritos", err)
+	}
+	for _, id := range ids {
+		favoritos[id] = true
+	}
+	return favoritos, nil
+}
+
+func (r *repository) UsuarioExiste(ctx context.Context, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return false, apperrors.NewDatabaseError("find_usuario", "erro ao buscar usuário", err)
+	}
+	return count > 0, nil
+}
+
+func (r *repository) CreateAvaliacao(ctx context.Context, avaliacao *models.AvaliacaoVendedor) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(avaliacao)
+	if result.Error != nil {
+		return apperrors.NewDatabaseError("create_avaliacao_vendedor", "erro ao registrar avaliação", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.ConflictError{Resource: "avaliacao", Message: "a compra já foi avaliada", Value: avaliacao.AnuncioID}
+	}
+	return nil
+}
+
+func (r *repository) FindAvaliacao(ctx context.Context, anuncioID uint) (*models.AvaliacaoVendedor, error) {
+	var avaliacao models.AvaliacaoVendedor
+	err := r.db.WithContext(ctx).Where("anuncio_id = ?", anuncioID).First(&avaliacao).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperrors.NewDatabaseError("find_avaliacao_vendedor", "erro ao buscar avaliação", err)
+	}
+	return &avaliacao, nil
+}
+
+func (r *repository) ListAvaliacoes(ctx context.Context, vendedorID uint, page, limit int) ([]*models.AvaliacaoVendedor, int64, error) {
+	var avaliacoes []*models.AvaliacaoVendedor
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.AvaliacaoVendedor{}).Where("vendedor_id = ?", vendedorID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_avaliacoes_vendedor", "erro ao contar avaliações", err)
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("Comprador").
+		Offset(offset).Limit(limit).
+		Order("created_at DESC, id DESC").
+		Find(&avaliacoes).Error
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_avaliacoes_vendedor", "erro ao listar avaliações", err)
+	}
+	return avaliacoes, total, nil
+}
+
+// ResumoVendedor média das notas recebidas e quantidade de anúncios vendidos
+func (r *repository) ResumoVendedor(ctx context.Context, vendedorID uint) (float64, int64, error) {
+	var media struct{ Media float64 }
+	err := r.db.WithContext(ctx).Model(&models.AvaliacaoVendedor{}).
+		Select("COALESCE(AVG(nota), 0) AS media").
+		Where("vendedor_id = ?", vendedorID).
+		Scan(&media).Error
+	if err != nil {
+		return 0, 0, apperrors.NewDatabaseError("resumo_vendedor", "erro ao calcular reputação do vendedor", err)
+	}
+
+	var vendas int64
+	err = r.db.WithContext(ctx).Model(&models.AnuncioMarketplace{}).
+		Where("usuario_id = ? AND status = ?", vendedorID, models.StatusAnuncioVendido).
+		Count(&vendas).Error
+	if err != nil {
+		return 0, 0, apperrors.NewDatabaseError("resumo_vendedor", "erro ao contar vendas do vendedor", err)
+	}
+	return media.Media, vendas, nil
+}
@@ -0,0 +1,33 @@
+package marketplace
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	marketplace := rg.Group("/marketplace")
+	marketplace.Use(authMiddleware)
+	{
+		marketplace.GET("/anuncios", handler.BuscarAnuncios)
+		marketplace.POST("/anuncios", handler.CreateAnuncio)
+		marketplace.GET("/anuncios/meus", handler.ListMeusAnuncios)
+		marketplace.GET("/anuncios/:id", handler.GetAnuncio)
+		marketplace.PUT("/anuncios/:id", handler.UpdateAnuncio)
+		marketplace.DELETE("/anuncios/:id", handler.DeleteAnuncio)
+
+		marketplace.POST("/anuncios/:id/publicar", handler.PublicarAnuncio)
+		marketplace.POST("/anuncios/:id/pausar", handler.PausarAnuncio)
+		marketplace.POST("/anuncios/:id/vender", handler.VenderAnuncio)
+
+		marketplace.POST("/anuncios/:id/fotos", handler.UploadFoto)
+		marketplace.DELETE("/anuncios/:id/fotos/:foto_id", handler.DeleteFoto)
+
+		marketplace.POST("/anuncios/:id/favorito", handler.Favoritar)
+		marketplace.DELETE("/anuncios/:id/favorito", handler.Desfavoritar)
+		marketplace.GET("/favoritos", handler.ListFavoritos)
+
+		marketplace.POST("/anuncios/:id/confirmar-compra", handler.ConfirmarCompra)
+		marketplace.POST("/anuncios/:id/avaliacao", handler.AvaliarVendedor)
+		marketplace.GET("/vendedores/:id/avaliacoes", handler.GetReputacao)
+	}
+}
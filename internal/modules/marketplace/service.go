@@ -0,0 +1,870 @@
+package marketplace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	"github.com/equinoid/backend/internal/modules/social"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/equinoid/backend/pkg/storage"
+)
+
+const (
+	// duracaoAnuncio validade de um anúncio publicado; depois disso ele expira e pode ser renovado
+	duracaoAnuncio = 60 * 24 * time.Hour
+	// maxFotos fotos por anúncio
+	maxFotos = 10
+	// loteExpiracao anúncios tratados por execução da rotina de expiração
+	loteExpiracao = 100
+)
+
+// fotosPermitidas tipos de imagem aceitos, identificados pelo conteúdo do arquivo
+var fotosPermitidas = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// statusEmVenda anúncios que ocupam o equino; não pode haver dois ao mesmo tempo para o mesmo animal
+var statusEmVenda = []models.StatusAnuncio{models.StatusAnuncioAtivo, models.StatusAnuncioPausado}
+
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+// Notificador entrega avisos a vendedores e compradores
+type Notificador interface {
+	Notificar(ctx context.Context, userIDs []uint, notificacao models.NovaNotificacao) error
+}
+
+// AvaliacoesSemen histórico de avaliações de sêmen do garanhão, da coleta mais recente à mais antiga
+type AvaliacoesSemen interface {
+	ListAvaliacoesSemen(ctx context.Context, equinoid string) ([]*models.AvaliacaoSemen, error)
+}
+
+type Service interface {
+	Criar(ctx context.Context, userID uint, req *models.CreateAnuncioRequest) (*models.AnuncioMarketplace, error)
+	Get(ctx context.Context, id uint, userID uint, userType string) (*models.AnuncioMarketplace, error)
+	Buscar(ctx context.Context, userID uint, filtro FiltroAnuncios) ([]*models.AnuncioMarketplace, int64, error)
+	ListMeus(ctx context.Context, userID uint, status models.StatusAnuncio, page, limit int) ([]*models.AnuncioMarketplace, int64, error)
+	Atualizar(ctx context.Context, id uint, userID uint, req *models.UpdateAnuncioRequest) (*models.AnuncioMarketplace, error)
+	Excluir(ctx context.Context, id uint, userID uint, userType string) error
+
+	Publicar(ctx context.Context, id uint, userID uint) (*models.AnuncioMarketplace, error)
+	Pausar(ctx context.Context, id uint, userID uint) (*models.AnuncioMarketplace, error)
+	Vender(ctx context.Context, id uint, userID uint, req *models.VenderAnuncioRequest) (*models.AnuncioMarketplace, error)
+	ConfirmarCompra(ctx context.Context, id uint, userID uint) (*models.AnuncioMarketplace, error)
+
+	AdicionarFoto(ctx context.Context, id uint, userID uint, arquivo *models.ArquivoEnviado) (*models.FotoAnuncio, error)
+	RemoverFoto(ctx context.Context, id uint, fotoID uint, userID uint) error
+
+	Favoritar(ctx context.Context, id uint, userID uint) error
+	Desfavoritar(ctx context.Context, id uint, userID uint) error
+	ListFavoritos(ctx context.Context, userID uint, page, limit int) ([]*models.AnuncioMarketplace, int64, error)
+
+	AvaliarVendedor(ctx context.Context, id uint, userID uint, req *models.AvaliarVendedorRequest) (*models.AvaliacaoVendedor, error)
+	Reputacao(ctx context.Context, vendedorID uint, page, limit int) (*models.ReputacaoVendedor, error)
+
+	ExpirarAnuncios(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo        Repository
+	equinoRepo  equinos.Repository
+	socialRepo  social.Repository
+	avaliacoes  AvaliacoesSemen
+	store       storage.Storage
+	notificador Notificador
+	audit       AuditLogger
+	maxSize     int64
+	urlTTL      time.Duration
+	logger      *logging.Logger
+}
+
+// NewService cria o serviço do marketplace; sem armazenamento configurado o envio de fotos responde como indisponível
+func NewService(repo Repository, equinoRepo equinos.Repository, socialRepo social.Repository, avaliacoes AvaliacoesSemen, store storage.Storage, notificador Notificador, audit AuditLogger, cfg *config.Config, logger *logging.Logger) Service {
+	return &service{
+		repo:        repo,
+		equinoRepo:  equinoRepo,
+		socialRepo:  socialRepo,
+		avaliacoes:  avaliacoes,
+		store:       store,
+		notificador: notificador,
+		audit:       audit,
+		maxSize:     cfg.UploadMaxSize,
+		urlTTL:      cfg.DocumentURLTTL,
+		logger:      logger,
+	}
+}
+
+// Criar registra o anúncio como rascunho; as regras do tipo já são conferidas aqui e de novo na publicação
+func (s *service) Criar(ctx context.Context, userID uint, req *models.CreateAnuncioRequest) (*models.AnuncioMarketplace, error) {
+	if !models.IsValidTipoAnuncio(req.Tipo) {
+		return nil, &apperrors.ValidationError{Field: "tipo", Message: "tipo deve ser animal, semen, embrio ou equipamento", Value: req.Tipo}
+	}
+
+	anuncio := &models.AnuncioMarketplace{
+		UsuarioID:  userID,
+		Tipo:       req.Tipo,
+		Titulo:     strings.TrimSpace(req.Titulo),
+		Descricao:  strings.TrimSpace(req.Descricao),
+		Preco:      req.Preco,
+		Quantidade: req.Quantidade,
+		Estado:     strings.ToUpper(strings.TrimSpace(req.Estado)),
+		Cidade:     strings.TrimSpace(req.Cidade),
+		Status:     models.StatusAnuncioRascunho,
+	}
+	if equinoid := strings.TrimSpace(req.Equinoid); equinoid != "" {
+		anuncio.Equinoid = &equinoid
+	}
+	if anuncio.Quantidade == 0 {
+		anuncio.Quantidade = 1
+	}
+	if anuncio.Titulo == "" {
+		return nil, &apperrors.ValidationError{Field: "titulo", Message: "título é obrigatório"}
+	}
+	if err := s.validarItem(ctx, anuncio); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, anuncio); err != nil {
+		s.logger.LogError(err, "MarketplaceService.Criar", logging.Fields{"user_id": userID, "tipo": req.Tipo})
+		return nil, err
+	}
+
+	s.recordChange(ctx, anuncio, "create", nil, anuncio)
+	s.logger.LogBusinessEvent("anuncio_criado", "Anúncio criado como rascunho", userID, equinoidDe(anuncio), logging.Fields{
+		"anuncio_id": anuncio.ID,
+		"tipo":       anuncio.Tipo,
+		"preco":      anuncio.Preco,
+	})
+	return anuncio, nil
+}
+
+// Get anúncios ativos e vendidos são públicos; rascunhos, pausados e expirados só o vendedor e administradores veem
+func (s *service) Get(ctx context.Context, id uint, userID uint, userType string) (*models.AnuncioMarketplace, error) {
+	anuncio, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !anuncio.IsPublico() && anuncio.UsuarioID != userID && userType != string(models.UserTypeAdmin) {
+		return nil, &apperrors.NotFoundError{Resource: "anuncio", Message: "anúncio não encontrado", ID: id}
+	}
+
+	s.marcarFavoritos(ctx, userID, anuncio)
+	s.assinarFotos(ctx, anuncio)
+	return anuncio, nil
+}
+
+// Buscar anúncios ativos com os filtros informados
+func (s *service) Buscar(ctx context.Context, userID uint, filtro FiltroAnuncios) ([]*models.AnuncioMarketplace, int64, error) {
+	if filtro.Tipo != "" && !models.IsValidTipoAnuncio(filtro.Tipo) {
+		return nil, 0, &apperrors.ValidationError{Field: "tipo", Message: "tipo deve ser animal, semen, embrio ou equipamento", Value: filtro.Tipo}
+	}
+	switch filtro.Ordem {
+	case "":
+		filtro.Ordem = OrdemRecentes
+	case OrdemRecentes, OrdemMenorPreco, OrdemMaiorPreco:
+	default:
+		return nil, 0, &apperrors.ValidationError{Field: "ordem", Message: "ordem deve ser recentes, menor_preco ou maior_preco", Value: filtro.Ordem}
+	}
+	if filtro.PrecoMin != nil && filtro.PrecoMax != nil && *filtro.PrecoMin > *filtro.PrecoMax {
+		return nil, 0, &apperrors.ValidationError{Field: "preco_min", Message: "preço mínimo maior que o máximo", Value: *filtro.PrecoMin}
+	}
+	filtro.Status = []models.StatusAnuncio{models.StatusAnuncioAtivo}
+
+	anuncios, total, err := s.repo.List(ctx, filtro)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.Buscar", logging.Fields{"user_id": userID})
+		return nil, 0, err
+	}
+	s.marcarFavoritos(ctx, userID, anuncios...)
+	s.assinarFotos(ctx, anuncios...)
+	return anuncios, total, nil
+}
+
+// ListMeus anúncios do próprio vendedor em qualquer etapa, ou só na etapa informada
+func (s *service) ListMeus(ctx context.Context, userID uint, status models.StatusAnuncio, page, limit int) ([]*models.AnuncioMarketplace, int64, error) {
+	filtro := FiltroAnuncios{
+		VendedorID: userID,
+		Status:     []models.StatusAnuncio{models.StatusAnuncioRascunho, models.StatusAnuncioAtivo, models.StatusAnuncioPausado, models.StatusAnuncioVendido, models.StatusAnuncioExpirado},
+		Ordem:      OrdemAtualizados,
+		Page:       page,
+		Limit:      limit,
+	}
+	if status != "" {
+		filtro.Status = []models.StatusAnuncio{status}
+	}
+
+	anuncios, total, err := s.repo.List(ctx, filtro)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.ListMeus", logging.Fields{"user_id": userID})
+		return nil, 0, err
+	}
+	s.assinarFotos(ctx, anuncios...)
+	return anuncios, total, nil
+}
+
+// Atualizar altera os dados do anúncio ainda não vendido; anúncios publicados passam de novo pelas regras do tipo
+func (s *service) Atualizar(ctx context.Context, id uint, userID uint, req *models.UpdateAnuncioRequest) (*models.AnuncioMarketplace, error) {
+	anuncio, err := s.findDoVendedor(ctx, id, userID, "atualizar")
+	if err != nil {
+		return nil, err
+	}
+	if !anuncio.IsEditavel() {
+		return nil, &apperrors.ConflictError{Resource: "anuncio", Message: "anúncio vendido não pode ser alterado", Value: id}
+	}
+
+	before := *anuncio
+	if req.Titulo != nil {
+		if anuncio.Titulo = strings.TrimSpace(*req.Titulo); anuncio.Titulo == "" {
+			return nil, &apperrors.ValidationError{Field: "titulo", Message: "título é obrigatório"}
+		}
+	}
+	if req.Descricao != nil {
+		anuncio.Descricao = strings.TrimSpace(*req.Descricao)
+	}
+	if req.Preco != nil {
+		anuncio.Preco = *req.Preco
+	}
+	if req.Quantidade != nil {
+		anuncio.Quantidade = *req.Quantidade
+	}
+	if req.Estado != nil {
+		anuncio.Estado = strings.ToUpper(strings.TrimSpace(*req.Estado))
+	}
+	if req.Cidade != nil {
+		anuncio.Cidade = strings.TrimSpace(*req.Cidade)
+	}
+	if req.Equinoid != nil {
+		anuncio.Equinoid = nil
+		if equinoid := strings.TrimSpace(*req.Equinoid); equinoid != "" {
+			anuncio.Equinoid = &equinoid
+		}
+		anuncio.Equino = nil
+		anuncio.AvaliacaoSemen = nil
+	}
+	if err := s.validarItem(ctx, anuncio); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, anuncio); err != nil {
+		s.logger.LogError(err, "MarketplaceService.Atualizar", logging.Fields{"anuncio_id": id})
+		return nil, err
+	}
+
+	s.recordChange(ctx, anuncio, "update", &before, anuncio)
+	return s.Get(ctx, id, userID, "")
+}
+
+// Excluir remove o anúncio; vendidos ficam como histórico da reputação do vendedor
+func (s *service) Excluir(ctx context.Context, id uint, userID uint, userType string) error {
+	anuncio, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if anuncio.UsuarioID != userID && userType != string(models.UserTypeAdmin) {
+		return (&apperrors.AuthorizationError{Message: "apenas o vendedor pode excluir o anúncio"}).WithAction("excluir", "anuncio")
+	}
+	if !anuncio.IsEditavel() {
+		return &apperrors.ConflictError{Resource: "anuncio", Message: "anúncio vendido não pode ser excluído", Value: id}
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.LogError(err, "MarketplaceService.Excluir", logging.Fields{"anuncio_id": id})
+		return err
+	}
+
+	s.recordChange(ctx, anuncio, "delete", anuncio, nil)
+	s.logger.LogBusinessEvent("anuncio_excluido", "Anúncio excluído", userID, equinoidDe(anuncio), logging.Fields{"anuncio_id": id})
+	return nil
+}
+
+// Publicar coloca o rascunho, o anúncio pausado ou o expirado no ar por mais 60 dias, conferindo de novo as regras
+// do tipo
+func (s *service) Publicar(ctx context.Context, id uint, userID uint) (*models.AnuncioMarketplace, error) {
+	anuncio, err := s.findDoVendedor(ctx, id, userID, "publicar")
+	if err != nil {
+		return nil, err
+	}
+	if anuncio.Status == models.StatusAnuncioAtivo {
+		return nil, &apperrors.ConflictError{Resource: "anuncio", Message: "o anúncio já está publicado", Value: id}
+	}
+	if err := s.validarItem(ctx, anuncio); err != nil {
+		return nil, err
+	}
+
+	agora := time.Now()
+	campos := map[string]interface{}{
+		"expira_em":          agora.Add(duracaoAnuncio),
+		"avaliacao_semen_id": anuncio.AvaliacaoSemenID,
+	}
+	if anuncio.PublicadoEm == nil {
+		campos["publicado_em"] = agora
+	}
+	de := []models.StatusAnuncio{models.StatusAnuncioRascunho, models.StatusAnuncioPausado, models.StatusAnuncioExpirado}
+	return s.transicionar(ctx, anuncio, userID, de, models.StatusAnuncioAtivo, campos, "publicar")
+}
+
+// Pausar tira o anúncio da busca sem perder favoritos nem a validade
+func (s *service) Pausar(ctx context.Context, id uint, userID uint) (*models.AnuncioMarketplace, error) {
+	anuncio, err := s.findDoVendedor(ctx, id, userID, "pausar")
+	if err != nil {
+		return nil, err
+	}
+	return s.transicionar(ctx, anuncio, userID, []models.StatusAnuncio{models.StatusAnuncioAtivo}, models.StatusAnuncioPausado, nil, "pausar")
+}
+
+// Vender encerra o anúncio. Com o comprador informado ele é avisado e, depois de pagar ou confirmar o recebimento,
+// pode avaliar o vendedor; na venda de animal o perfil social do equino passa a vendido
+func (s *service) Vender(ctx context.Context, id uint, userID uint, req *models.VenderAnuncioRequest) (*models.AnuncioMarketplace, error) {
+	anuncio, err := s.findDoVendedor(ctx, id, userID, "vender")
+	if err != nil {
+		return nil, err
+	}
+	if req.CompradorID != nil {
+		if *req.CompradorID == userID {
+			return nil, &apperrors.ValidationError{Field: "comprador_id", Message: "o vendedor não pode ser o comprador", Value: *req.CompradorID}
+		}
+		existe, err := s.repo.UsuarioExiste(ctx, *req.CompradorID)
+		if err != nil {
+			s.logger.LogError(err, "MarketplaceService.Vender", logging.Fields{"anuncio_id": id})
+			return nil, err
+		}
+		if !existe {
+			return nil, &apperrors.ValidationError{Field: "comprador_id", Message: "comprador não encontrado", Value: *req.CompradorID}
+		}
+	}
+
+	campos := map[string]interface{}{
+		"vendido_em":   time.Now(),
+		"comprador_id": req.CompradorID,
+	}
+	vendido, err := s.transicionar(ctx, anuncio, userID, statusEmVenda, models.StatusAnuncioVendido, campos, "vender")
+	if err != nil {
+		return nil, err
+	}
+
+	if vendido.Tipo == models.TipoAnuncioAnimal && vendido.Equinoid != nil {
+		s.marcarEquinoVendido(ctx, *vendido.Equinoid)
+	}
+	if vendido.CompradorID != nil {
+		s.notificar(ctx, []uint{*vendido.CompradorID}, models.NotificacaoAnuncioVendido, "Compra registrada",
+			fmt.Sprintf("O vendedor registrou a sua compra de \"%s\". Depois de receber, confirme a compra para avaliar o vendedor.", vendido.Titulo), vendido)
+	}
+	return vendido, nil
+}
+
+// ConfirmarCompra o comprador registrado confirma que recebeu o item. Compras pagas pela plataforma são confirmadas
+// na liberação do pagamento, que também repassa o valor ao vendedor
+func (s *service) ConfirmarCompra(ctx context.Context, id uint, userID uint) (*models.AnuncioMarketplace, error) {
+	anuncio, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if anuncio.Status != models.StatusAnuncioVendido {
+		return nil, &apperrors.ValidationError{Field: "anuncio_id", Message: "o anúncio ainda não foi vendido", Value: anuncio.Status}
+	}
+	if anuncio.CompradorID == nil || *anuncio.CompradorID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o comprador registrado na venda pode confirmar a compra"}).WithAction("confirmar_compra", "anuncio")
+	}
+	if anuncio.ConfirmadoEm != nil {
+		return anuncio, nil
+	}
+
+	situacao, err := s.repo.SituacaoPagamento(ctx, anuncio.ID)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.ConfirmarCompra", logging.Fields{"anuncio_id": id})
+		return nil, err
+	}
+	switch situacao {
+	case models.PagamentoAguardando, models.PagamentoEmCustodia, models.PagamentoEmLiberacao:
+		return nil, &apperrors.ValidationError{Field: "anuncio_id", Message: "a compra tem pagamento pela plataforma: confirme o recebimento liberando o pagamento", Value: situacao}
+	}
+
+	before := *anuncio
+	agora := time.Now()
+	if _, err := s.repo.ConfirmarCompra(ctx, anuncio.ID, userID, agora); err != nil {
+		s.logger.LogError(err, "MarketplaceService.ConfirmarCompra", logging.Fields{"anuncio_id": id})
+		return nil, err
+	}
+	anuncio.ConfirmadoEm = &agora
+	s.recordChange(ctx, anuncio, "confirmar_compra", &before, anuncio)
+	s.notificar(ctx, []uint{anuncio.UsuarioID}, models.NotificacaoAnuncioVendido, "Compra confirmada",
+		fmt.Sprintf("O comprador confirmou o recebimento de \"%s\".", anuncio.Titulo), anuncio)
+	s.logger.WithFields(logging.Fields{"anuncio_id": anuncio.ID, "comprador_id": userID}).Info("Compra confirmada pelo comprador")
+	return anuncio, nil
+}
+
+// AdicionarFoto grava a imagem no armazenamento e a anexa ao final da galeria do anúncio
+func (s *service) AdicionarFoto(ctx context.Context, id uint, userID uint, arquivo *models.ArquivoEnviado) (*models.FotoAnuncio, error) {
+	if s.store == nil {
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de fotos não configurado", nil)
+	}
+	anuncio, err := s.findDoVendedor(ctx, id, userID, "enviar fotos")
+	if err != nil {
+		return nil, err
+	}
+	if !anuncio.IsEditavel() {
+		return nil, &apperrors.ConflictError{Resource: "anuncio", Message: "anúncio vendido não pode ser alterado", Value: id}
+	}
+
+	if len(arquivo.Conteudo) == 0 {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: "arquivo vazio"}
+	}
+	if s.maxSize > 0 && int64(len(arquivo.Conteudo)) > s.maxSize {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: fmt.Sprintf("arquivo excede o limite de %d bytes", s.maxSize), Value: len(arquivo.Conteudo)}
+	}
+	mimeType := http.DetectContentType(arquivo.Conteudo)
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	ext, permitido := fotosPermitidas[mimeType]
+	if !permitido {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: "a foto deve ser JPEG, PNG, GIF ou WebP", Value: mimeType}
+	}
+
+	total, ordem, err := s.repo.CountFotos(ctx, id)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.AdicionarFoto", logging.Fields{"anuncio_id": id})
+		return nil, err
+	}
+	if total >= maxFotos {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: fmt.Sprintf("o anúncio já tem o máximo de %d fotos", maxFotos), Value: total}
+	}
+
+	digest := sha256.Sum256(arquivo.Conteudo)
+	foto := &models.FotoAnuncio{
+		AnuncioID:  id,
+		StorageKey: fmt.Sprintf("marketplace/anuncios/%d/%s%s", id, hex.EncodeToString(digest[:]), ext),
+		MimeType:   mimeType,
+		Tamanho:    int64(len(arquivo.Conteudo)),
+		Ordem:      ordem + 1,
+		EnviadoPor: userID,
+	}
+	if err := s.store.Put(ctx, foto.StorageKey, arquivo.Conteudo, mimeType); err != nil {
+		s.logger.LogError(err, "MarketplaceService.AdicionarFoto", logging.Fields{"backend": s.store.Name(), "anuncio_id": id})
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de fotos indisponível", nil)
+	}
+	if err := s.repo.CreateFoto(ctx, foto); err != nil {
+		s.logger.LogError(err, "MarketplaceService.AdicionarFoto", logging.Fields{"anuncio_id": id})
+		return nil, err
+	}
+
+	foto.URL = s.urlFoto(ctx, foto)
+	return foto, nil
+}
+
+// RemoverFoto retira a foto da galeria e apaga o arquivo; falhas no armazenamento não impedem a remoção
+func (s *service) RemoverFoto(ctx context.Context, id uint, fotoID uint, userID uint) error {
+	anuncio, err := s.findDoVendedor(ctx, id, userID, "remover fotos")
+	if err != nil {
+		return err
+	}
+	if !anuncio.IsEditavel() {
+		return &apperrors.ConflictError{Resource: "anuncio", Message: "anúncio vendido não pode ser alterado", Value: id}
+	}
+	foto, err := s.repo.FindFoto(ctx, id, fotoID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteFoto(ctx, foto.ID); err != nil {
+		s.logger.LogError(err, "MarketplaceService.RemoverFoto", logging.Fields{"anuncio_id": id, "foto_id": fotoID})
+		return err
+	}
+	if s.store != nil {
+		if err := s.store.Delete(ctx, foto.StorageKey); err != nil {
+			s.logger.LogError(err, "MarketplaceService.RemoverFoto", logging.Fields{"backend": s.store.Name(), "foto_id": fotoID})
+		}
+	}
+	return nil
+}
+
+// Favoritar salva um anúncio ativo na lista do usuário; repetir não altera nada
+func (s *service) Favoritar(ctx context.Context, id uint, userID uint) error {
+	anuncio, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if anuncio.Status != models.StatusAnuncioAtivo {
+		return &apperrors.ValidationError{Field: "anuncio_id", Message: "apenas anúncios ativos podem ser favoritados", Value: anuncio.Status}
+	}
+
+	if _, err := s.repo.Favoritar(ctx, &models.FavoritoAnuncio{UsuarioID: userID, AnuncioID: id}); err != nil {
+		s.logger.LogError(err, "MarketplaceService.Favoritar", logging.Fields{"anuncio_id": id, "user_id": userID})
+		return err
+	}
+	return nil
+}
+
+func (s *service) Desfavoritar(ctx context.Context, id uint, userID uint) error {
+	if _, err := s.repo.Desfavoritar(ctx, userID, id); err != nil {
+		s.logger.LogError(err, "MarketplaceService.Desfavoritar", logging.Fields{"anuncio_id": id, "user_id": userID})
+		return err
+	}
+	return nil
+}
+
+func (s *service) ListFavoritos(ctx context.Context, userID uint, page, limit int) ([]*models.AnuncioMarketplace, int64, error) {
+	anuncios, total, err := s.repo.ListFavoritos(ctx, userID, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.ListFavoritos", logging.Fields{"user_id": userID})
+		return nil, 0, err
+	}
+	s.assinarFotos(ctx, anuncios...)
+	return anuncios, total, nil
+}
+
+// AvaliarVendedor nota do comprador registrado na venda, depois do pagamento liberado ou da confirmação da compra;
+// cada compra é avaliada uma única vez
+func (s *service) AvaliarVendedor(ctx context.Context, id uint, userID uint, req *models.AvaliarVendedorRequest) (*models.AvaliacaoVendedor, error) {
+	anuncio, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if anuncio.Status != models.StatusAnuncioVendido {
+		return nil, &apperrors.ValidationError{Field: "anuncio_id", Message: "só é possível avaliar o vendedor depois da venda concluída", Value: anuncio.Status}
+	}
+	if anuncio.CompradorID == nil || *anuncio.CompradorID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o comprador registrado na venda pode avaliar o vendedor"}).WithAction("avaliar", "anuncio")
+	}
+	if anuncio.ConfirmadoEm == nil {
+		situacao, err := s.repo.SituacaoPagamento(ctx, anuncio.ID)
+		if err != nil {
+			s.logger.LogError(err, "MarketplaceService.AvaliarVendedor", logging.Fields{"anuncio_id": id})
+			return nil, err
+		}
+		if situacao != models.PagamentoLiberado {
+			return nil, &apperrors.ValidationError{Field: "anuncio_id", Message: "a avaliação fica disponível depois do pagamento liberado ou da confirmação da compra", Value: situacao}
+		}
+	}
+
+	avaliacao := &models.AvaliacaoVendedor{
+		AnuncioID:   anuncio.ID,
+		VendedorID:  anuncio.UsuarioID,
+		CompradorID: userID,
+		Nota:        req.Nota,
+		Comentario:  strings.TrimSpace(req.Comentario),
+	}
+	if err := s.repo.CreateAvaliacao(ctx, avaliacao); err != nil {
+		if !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "MarketplaceService.AvaliarVendedor", logging.Fields{"anuncio_id": id, "user_id": userID})
+		}
+		return nil, err
+	}
+
+	s.notificar(ctx, []uint{anuncio.UsuarioID}, models.NotificacaoVendedorAvaliado, "Nova avaliação recebida",
+		fmt.Sprintf("O comprador de \"%s\" deu nota %d à venda.", anuncio.Titulo, avaliacao.Nota), anuncio)
+	s.logger.LogBusinessEvent("vendedor_avaliado", "Vendedor avaliado pelo comprador", userID, equinoidDe(anuncio), logging.Fields{
+		"anuncio_id":  anuncio.ID,
+		"vendedor_id": anuncio.UsuarioID,
+		"nota":        avaliacao.Nota,
+	})
+	return avaliacao, nil
+}
+
+// Reputacao média das notas, vendas concluídas e avaliações mais recentes do vendedor
+func (s *service) Reputacao(ctx context.Context, vendedorID uint, page, limit int) (*models.ReputacaoVendedor, error) {
+	existe, err := s.repo.UsuarioExiste(ctx, vendedorID)
+	if err != nil {
+		return nil, err
+	}
+	if !existe {
+		return nil, &apperrors.NotFoundError{Resource: "vendedor", Message: "vendedor não encontrado", ID: vendedorID}
+	}
+
+	media, vendas, err := s.repo.ResumoVendedor(ctx, vendedorID)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.Reputacao", logging.Fields{"vendedor_id": vendedorID})
+		return nil, err
+	}
+	avaliacoes, total, err := s.repo.ListAvaliacoes(ctx, vendedorID, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.Reputacao", logging.Fields{"vendedor_id": vendedorID})
+		return nil, err
+	}
+
+	return &models.ReputacaoVendedor{
+		VendedorID:  vendedorID,
+		Media:       float64(int(media*100+0.5)) / 100,
+		Total:       total,
+		TotalVendas: vendas,
+		Avaliacoes:  avaliacoes,
+	}, nil
+}
+
+// ExpirarAnuncios encerra os anúncios publicados ou pausados com a validade vencida e avisa os vendedores
+func (s *service) ExpirarAnuncios(ctx context.Context) (int, error) {
+	vencidos, err := s.repo.ListVencidos(ctx, time.Now(), loteExpiracao)
+	if err != nil {
+		return 0, err
+	}
+
+	expirados := 0
+	for _, anuncio := range vencidos {
+		aplicado, err := s.repo.AlterarStatus(ctx, anuncio.ID, statusEmVenda, models.StatusAnuncioExpirado, nil)
+		if err != nil {
+			s.logger.LogError(err, "MarketplaceService.ExpirarAnuncios", logging.Fields{"anuncio_id": anuncio.ID})
+			continue
+		}
+		if !aplicado {
+			continue
+		}
+		expirados++
+
+		before := *anuncio
+		anuncio.Status = models.StatusAnuncioExpirado
+		s.recordChange(ctx, anuncio, "expirar", &before, anuncio)
+		s.notificar(ctx, []uint{anuncio.UsuarioID}, models.NotificacaoAnuncioExpirado, "Anúncio expirado",
+			fmt.Sprintf("O anúncio \"%s\" saiu do ar depois de %d dias. Publique de novo para renová-lo.", anuncio.Titulo, int(duracaoAnuncio.Hours()/24)), anuncio)
+	}
+	return expirados, nil
+}
+
+// validarItem regras de cada tipo: animal exige equino do vendedor sem outro anúncio em andamento; sêmen exige
+// garanhão do vendedor com avaliação de sêmen apta e válida; embrião pode indicar a doadora; equipamento não se liga a
+// equino
+func (s *service) validarItem(ctx context.Context, anuncio *models.AnuncioMarketplace) error {
+	if anuncio.Preco <= 0 {
+		return &apperrors.ValidationError{Field: "preco", Message: "preço deve ser maior que zero", Value: anuncio.Preco}
+	}
+	if anuncio.Quantidade < 1 {
+		return &apperrors.ValidationError{Field: "quantidade", Message: "quantidade deve ser ao menos 1", Value: anuncio.Quantidade}
+	}
+
+	anuncio.AvaliacaoSemenID = nil
+	switch anuncio.Tipo {
+	case models.TipoAnuncioEquipamento:
+		if anuncio.Equinoid != nil {
+			return &apperrors.ValidationError{Field: "equinoid", Message: "anúncio de equipamento não se refere a um equino", Value: *anuncio.Equinoid}
+		}
+		return nil
+
+	case models.TipoAnuncioEmbriao:
+		if anuncio.Equinoid == nil {
+			return nil
+		}
+		equino, err := s.equinoDoVendedor(ctx, anuncio)
+		if err != nil {
+			return err
+		}
+		if !equino.IsFemea() {
+			return &apperrors.ValidationError{Field: "equinoid", Message: "a doadora do embrião deve ser uma fêmea", Value: equino.Equinoid}
+		}
+		return nil
+
+	case models.TipoAnuncioAnimal:
+		if anuncio.Quantidade != 1 {
+			return &apperrors.ValidationError{Field: "quantidade", Message: "anúncio de animal vende um único equino", Value: anuncio.Quantidade}
+		}
+		equino, err := s.equinoDoVendedor(ctx, anuncio)
+		if err != nil {
+			return err
+		}
+		if equino.Status == models.StatusFalecido || equino.Status == models.StatusVendido {
+			return &apperrors.ValidationError{Field: "equinoid", Message: "o equino não pode ser vendido", Value: equino.Status}
+		}
+		outro, err := s.repo.FindAnuncioEquino(ctx, equino.Equinoid, models.TipoAnuncioAnimal, statusEmVenda, anuncio.ID)
+		if err != nil {
+			s.logger.LogError(err, "MarketplaceService.validarItem", logging.Fields{"equinoid": equino.Equinoid})
+			return err
+		}
+		if outro != nil {
+			return &apperrors.ConflictError{Resource: "anuncio", Message: "o equino já tem um anúncio de venda publicado", Value: outro.ID}
+		}
+		return nil
+
+	case models.TipoAnuncioSemen:
+		equino, err := s.equinoDoVendedor(ctx, anuncio)
+		if err != nil {
+			return err
+		}
+		if !equino.IsMacho() {
+			return &apperrors.ValidationError{Field: "equinoid", Message: "sêmen só pode ser anunciado de um garanhão", Value: equino.Equinoid}
+		}
+		avaliacao, err := s.avaliacaoVigente(ctx, equino.Equinoid)
+		if err != nil {
+			return err
+		}
+		anuncio.AvaliacaoSemenID = &avaliacao.ID
+		return nil
+	}
+	return &apperrors.ValidationError{Field: "tipo", Message: "tipo de anúncio inválido", Value: anuncio.Tipo}
+}
+
+// equinoDoVendedor equino referenciado pelo anúncio, que precisa pertencer ao vendedor
+func (s *service) equinoDoVendedor(ctx context.Context, anuncio *models.AnuncioMarketplace) (*models.Equino, error) {
+	if anuncio.Equinoid == nil {
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: fmt.Sprintf("anúncio de %s precisa indicar o equino", anuncio.Tipo)}
+	}
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, *anuncio.Equinoid)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return nil, &apperrors.ValidationError{Field: "equinoid", Message: "equino não encontrado", Value: *anuncio.Equinoid}
+		}
+		return nil, err
+	}
+	if equino.ProprietarioID != anuncio.UsuarioID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o proprietário pode anunciar o equino"}).WithAction("anunciar", "equino")
+	}
+	anuncio.Equinoid = &equino.Equinoid
+	return equino, nil
+}
+
+// avaliacaoVigente avaliação de sêmen mais recente do garanhão, desde que apta e dentro da validade
+func (s *service) avaliacaoVigente(ctx context.Context, equinoid string) (*models.AvaliacaoSemen, error) {
+	avaliacoes, err := s.avaliacoes.ListAvaliacoesSemen(ctx, equinoid)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.avaliacaoVigente", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+	if len(avaliacoes) == 0 {
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "o garanhão não tem avaliação de sêmen registrada", Value: equinoid}
+	}
+
+	ultima := avaliacoes[0]
+	if ultima.AptidaoReprodutiva == models.AptidaoInadequada {
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "a avaliação de sêmen mais recente considerou o garanhão inapto", Value: ultima.ID}
+	}
+	if ultima.DataValidade != nil && ultima.DataValidade.Before(time.Now()) {
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "a avaliação de sêmen mais recente está vencida", Value: ultima.DataValidade}
+	}
+	return ultima, nil
+}
+
+// findDoVendedor anúncio que só o próprio vendedor pode alterar
+func (s *service) findDoVendedor(ctx context.Context, id uint, userID uint, acao string) (*models.AnuncioMarketplace, error) {
+	anuncio, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if anuncio.UsuarioID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o vendedor pode " + acao + " o anúncio"}).WithAction(acao, "anuncio")
+	}
+	return anuncio, nil
+}
+
+// transicionar aplica a mudança de status se o anúncio ainda estiver em uma das etapas de origem
+func (s *service) transicionar(ctx context.Context, anuncio *models.AnuncioMarketplace, userID uint, de []models.StatusAnuncio, para models.StatusAnuncio, campos map[string]interface{}, operacao string) (*models.AnuncioMarketplace, error) {
+	aplicado, err := s.repo.AlterarStatus(ctx, anuncio.ID, de, para, campos)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService."+operacao, logging.Fields{"anuncio_id": anuncio.ID})
+		return nil, err
+	}
+	if !aplicado {
+		return nil, &apperrors.ConflictError{Resource: "anuncio", Message: fmt.Sprintf("não é possível %s um anúncio %s", operacao, anuncio.Status), Value: anuncio.ID}
+	}
+
+	atualizado, err := s.repo.Find(ctx, anuncio.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.recordChange(ctx, atualizado, operacao, anuncio, atualizado)
+	s.logger.LogBusinessEvent("anuncio_"+string(para), "Status do anúncio alterado", userID, equinoidDe(atualizado), logging.Fields{
+		"anuncio_id": atualizado.ID,
+		"de":         anuncio.Status,
+		"para":       para,
+	})
+	s.assinarFotos(ctx, atualizado)
+	return atualizado, nil
+}
+
+// marcarEquinoVendido reflete a venda no perfil social do equino; sem perfil não há o que atualizar
+func (s *service) marcarEquinoVendido(ctx context.Context, equinoid string) {
+	perfil, err := s.socialRepo.FindPerfil(ctx, equinoid)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "MarketplaceService.marcarEquinoVendido", logging.Fields{"equinoid": equinoid})
+		}
+		return
+	}
+	if perfil.StatusDisponibilidade == models.StatusDispVendido {
+		return
+	}
+	perfil.StatusDisponibilidade = models.StatusDispVendido
+	if err := s.socialRepo.UpdatePerfil(ctx, perfil); err != nil {
+		s.logger.LogError(err, "MarketplaceService.marcarEquinoVendido", logging.Fields{"equinoid": equinoid})
+	}
+}
+
+// marcarFavoritos indica quais anúncios o usuário já salvou
+func (s *service) marcarFavoritos(ctx context.Context, userID uint, anuncios ...*models.AnuncioMarketplace) {
+	ids := make([]uint, 0, len(anuncios))
+	for _, anuncio := range anuncios {
+		ids = append(ids, anuncio.ID)
+	}
+	favoritos, err := s.repo.FavoritosDe(ctx, userID, ids)
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.marcarFavoritos", logging.Fields{"user_id": userID})
+		return
+	}
+	for _, anuncio := range anuncios {
+		anuncio.Favorito = favoritos[anuncio.ID]
+	}
+}
+
+// assinarFotos preenche as URLs temporárias das fotos
+func (s *service) assinarFotos(ctx context.Context, anuncios ...*models.AnuncioMarketplace) {
+	for _, anuncio := range anuncios {
+		for i := range anuncio.Fotos {
+			anuncio.Fotos[i].URL = s.urlFoto(ctx, &anuncio.Fotos[i])
+		}
+	}
+}
+
+func (s *service) urlFoto(ctx context.Context, foto *models.FotoAnuncio) string {
+	if s.store == nil {
+		return ""
+	}
+	url, err := s.store.PresignGet(ctx, foto.StorageKey, s.urlTTL, "")
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.urlFoto", logging.Fields{"foto_id": foto.ID})
+		return ""
+	}
+	return url
+}
+
+// notificar falhas na entrega de avisos não desfazem a operação
+func (s *service) notificar(ctx context.Context, destinatarios []uint, tipo models.TipoNotificacao, titulo, mensagem string, anuncio *models.AnuncioMarketplace) {
+	if s.notificador == nil {
+		return
+	}
+	err := s.notificador.Notificar(ctx, destinatarios, models.NovaNotificacao{
+		Tipo:           tipo,
+		Titulo:         titulo,
+		Mensagem:       mensagem,
+		ReferenciaTipo: "anuncio",
+		ReferenciaID:   &anuncio.ID,
+	})
+	if err != nil {
+		s.logger.LogError(err, "MarketplaceService.notificar", logging.Fields{"anuncio_id": anuncio.ID, "tipo": tipo})
+	}
+}
+
+func (s *service) recordChange(ctx context.Context, anuncio *models.AnuncioMarketplace, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, "anuncio_marketplace", fmt.Sprint(anuncio.ID), operation, before, after); err != nil {
+		s.logger.LogError(err, "MarketplaceService.recordChange", logging.Fields{"anuncio_id": anuncio.ID, "operation": operation})
+	}
+}
+
+func equinoidDe(anuncio *models.AnuncioMarketplace) string {
+	if anuncio.Equinoid == nil {
+		return ""
+	}
+	return *anuncio.Equinoid
+}
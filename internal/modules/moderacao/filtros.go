@@ -0,0 +1,125 @@
+package moderacao
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/equinoid/backend/internal/models"
+)
+
+// severidade ordem de precedência quando mais de uma regra é acionada
+var severidade = map[models.AcaoModeracao]int{
+	models.AcaoRevisar:  1,
+	models.AcaoOcultar:  2,
+	models.AcaoBloquear: 3,
+}
+
+// semAcento variantes acentuadas do português -> letra base
+var semAcento = func() map[rune]rune {
+	m := make(map[rune]rune)
+	for base, variantes := range map[rune]string{
+		'a': "áàâãä",
+		'e': "éèêë",
+		'i': "íìîï",
+		'o': "óòôõö",
+		'u': "úùûü",
+		'c': "ç",
+		'n': "ñ",
+	} {
+		for _, v := range variantes {
+			m[v] = base
+		}
+	}
+	return m
+}()
+
+// padraoLink URLs com esquema, endereços iniciados por www. e domínios soltos com TLD comum ("compre em golpe.com.br")
+var padraoLink = regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s<>"']+|\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9][a-z0-9-]*)*\.(?:com|net|org|br|io|me|ly|info|biz|xyz|app|link|site|online|store|shop|top|click)\b(?:/[^\s<>"']*)?`)
+
+// normalizar minúsculas sem acentos, para comparar palavras-chave com o texto
+func normalizar(texto string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(texto) {
+		if base, ok := semAcento[r]; ok {
+			r = base
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// contemPalavra procura a palavra-chave (já normalizada) no texto normalizado sem casar dentro de outra palavra
+func contemPalavra(texto, palavra string) bool {
+	if palavra == "" {
+		return false
+	}
+	for inicio := 0; ; {
+		i := strings.Index(texto[inicio:], palavra)
+		if i < 0 {
+			return false
+		}
+		i += inicio
+		fim := i + len(palavra)
+		if limitePalavra(texto, i, true) && limitePalavra(texto, fim, false) {
+			return true
+		}
+		inicio = i + 1
+	}
+}
+
+func limitePalavra(texto string, pos int, antes bool) bool {
+	var r rune
+	if antes {
+		r, _ = utf8.DecodeLastRuneInString(texto[:pos])
+	} else {
+		r, _ = utf8.DecodeRuneInString(texto[pos:])
+	}
+	return r == utf8.RuneError || (!unicode.IsLetter(r) && !unicode.IsDigit(r))
+}
+
+// dominios hosts dos links encontrados no texto, em minúsculas e sem o prefixo www.
+func dominios(texto string) []string {
+	var hosts []string
+	for _, link := range padraoLink.FindAllString(texto, -1) {
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		u, err := url.Parse(link)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		hosts = append(hosts, strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."))
+	}
+	return hosts
+}
+
+// dominioBloqueado o domínio da regra bloqueia também os subdomínios; "*" bloqueia qualquer link
+func dominioBloqueado(host, padrao string) bool {
+	padrao = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(padrao)), "www.")
+	if padrao == "*" {
+		return true
+	}
+	return host == padrao || strings.HasSuffix(host, "."+padrao)
+}
+
+// aciona verifica se a regra casa com o texto; regras regex inválidas nunca são gravadas, mas são ignoradas aqui
+// por segurança
+func aciona(regra *models.RegraModeracao, texto, normalizado string, hosts []string) bool {
+	switch regra.Tipo {
+	case models.RegraPalavraChave:
+		return contemPalavra(normalizado, normalizar(strings.TrimSpace(regra.Padrao)))
+	case models.RegraRegex:
+		re, err := regexp.Compile("(?i)" + regra.Padrao)
+		return err == nil && re.MatchString(texto)
+	case models.RegraLink:
+		for _, host := range hosts {
+			if dominioBloqueado(host, regra.Padrao) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,568 @@
+package moderacao
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// DenunciarPost godoc
+// @Summary Denunciar post
+// @Description Denuncia um post da rede social; cada usuário denuncia o mesmo conteúdo uma vez. Com 3 denúncias o post fica oculto até a revisão
+// @Tags Moderação
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do post"
+// @Param denuncia body models.DenunciarConteudoRequest true "Motivo da denúncia"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/posts/{id}/denuncias [post]
+// @Security BearerAuth
+func (h *Handler) DenunciarPost(c *gin.Context) {
+	h.denunciar(c, models.ConteudoModeradoPost)
+}
+
+// DenunciarComentario godoc
+// @Summary Denunciar comentário
+// @Description Denuncia um comentário; cada usuário denuncia o mesmo conteúdo uma vez. Com 3 denúncias o comentário fica oculto até a revisão
+// @Tags Moderação
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do comentário"
+// @Param denuncia body models.DenunciarConteudoRequest true "Motivo da denúncia"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/comentarios/{id}/denuncias [post]
+// @Security BearerAuth
+func (h *Handler) DenunciarComentario(c *gin.Context) {
+	h.denunciar(c, models.ConteudoModeradoComentario)
+}
+
+func (h *Handler) denunciar(c *gin.Context, tipo models.TipoConteudoModerado) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.DenunciarConteudoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	denuncia, err := h.service.Denunciar(c.Request.Context(), tipo, id, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar denúncia")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Denúncia registrada",
+		Timestamp: time.Now(),
+		Data:      denuncia,
+	})
+}
+
+// ListMeusCasos godoc
+// @Summary Listar decisões sobre meu conteúdo
+// @Description Posts e comentários do usuário ocultados ou retirados pela moderação, com o recurso quando houver
+// @Tags Moderação
+// @Produce json
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/meus-casos [get]
+// @Security BearerAuth
+func (h *Handler) ListMeusCasos(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := parsePagination(c)
+
+	casos, total, err := h.service.ListMeusCasos(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar casos de moderação")
+		return
+	}
+
+	h.respondPage(c, fmt.Sprintf("Casos de moderação (total: %d)", total), casos, page, limit, total)
+}
+
+// Recorrer godoc
+// @Summary Recorrer de retirada
+// @Description O autor recorre uma única vez da retirada do seu conteúdo, em até 30 dias da decisão
+// @Tags Moderação
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do caso"
+// @Param recurso body models.CreateRecursoRequest true "Justificativa"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/casos/{id}/recurso [post]
+// @Security BearerAuth
+func (h *Handler) Recorrer(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateRecursoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	recurso, err := h.service.Recorrer(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar recurso")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Recurso registrado",
+		Timestamp: time.Now(),
+		Data:      recurso,
+	})
+}
+
+// ListFila godoc
+// @Summary Fila de moderação
+// @Description Casos abertos por denúncias ou pelos filtros, com mais denúncias primeiro (apenas admin)
+// @Tags Moderação
+// @Produce json
+// @Param status query string false "Status (pendente, resolvido, revertido)" default(pendente)
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/fila [get]
+// @Security BearerAuth
+func (h *Handler) ListFila(c *gin.Context) {
+	page, limit := parsePagination(c)
+
+	casos, total, err := h.service.ListFila(c.Request.Context(), models.StatusCasoModeracao(c.Query("status")), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar fila de moderação")
+		return
+	}
+
+	h.respondPage(c, fmt.Sprintf("Fila de moderação (total: %d)", total), casos, page, limit, total)
+}
+
+// GetCaso godoc
+// @Summary Detalhar caso de moderação
+// @Description Caso com as denúncias, o recurso e o post ou comentário moderado (apenas admin)
+// @Tags Moderação
+// @Produce json
+// @Param id path int true "ID do caso"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/casos/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetCaso(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	caso, err := h.service.GetCaso(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar caso de moderação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Caso de moderação",
+		Timestamp: time.Now(),
+		Data:      caso,
+	})
+}
+
+// Decidir godoc
+// @Summary Decidir caso de moderação
+// @Description Mantém o conteúdo no ar, oculta (visível só para o autor) ou retira do ar; ocultar e retirar contam como infração do autor (apenas admin)
+// @Tags Moderação
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do caso"
+// @Param decisao body models.DecidirCasoRequest true "Decisão"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/casos/{id}/decisao [post]
+// @Security BearerAuth
+func (h *Handler) Decidir(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	adminID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.DecidirCasoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	caso, err := h.service.Decidir(c.Request.Context(), id, adminID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao decidir caso de moderação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Caso decidido",
+		Timestamp: time.Now(),
+		Data:      caso,
+	})
+}
+
+// ListRecursos godoc
+// @Summary Listar recursos
+// @Description Recursos de autores contra retiradas de conteúdo (apenas admin)
+// @Tags Moderação
+// @Produce json
+// @Param status query string false "Status (pendente, deferido, indeferido)" default(pendente)
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/recursos [get]
+// @Security BearerAuth
+func (h *Handler) ListRecursos(c *gin.Context) {
+	page, limit := parsePagination(c)
+
+	recursos, total, err := h.service.ListRecursos(c.Request.Context(), models.StatusRecursoModeracao(c.Query("status")), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar recursos")
+		return
+	}
+
+	h.respondPage(c, fmt.Sprintf("Recursos de moderação (total: %d)", total), recursos, page, limit, total)
+}
+
+// DecidirRecurso godoc
+// @Summary Decidir recurso
+// @Description Recurso deferido devolve o conteúdo ao ar e anula as infrações do caso; deve ser decidido por outro moderador (apenas admin)
+// @Tags Moderação
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do recurso"
+// @Param decisao body models.DecidirRecursoRequest true "Decisão"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/recursos/{id}/decisao [post]
+// @Security BearerAuth
+func (h *Handler) DecidirRecurso(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	adminID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.DecidirRecursoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	recurso, err := h.service.DecidirRecurso(c.Request.Context(), id, adminID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao decidir recurso")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Recurso decidido",
+		Timestamp: time.Now(),
+		Data:      recurso,
+	})
+}
+
+// ListRegras godoc
+// @Summary Listar regras de moderação
+// @Description Filtros de palavras-chave, expressões regulares e links aplicados antes da publicação (apenas admin)
+// @Tags Moderação
+// @Produce json
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/regras [get]
+// @Security BearerAuth
+func (h *Handler) ListRegras(c *gin.Context) {
+	page, limit := parsePagination(c)
+
+	regras, total, err := h.service.ListRegras(c.Request.Context(), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar regras de moderação")
+		return
+	}
+
+	h.respondPage(c, fmt.Sprintf("Regras de moderação (total: %d)", total), regras, page, limit, total)
+}
+
+// CreateRegra godoc
+// @Summary Criar regra de moderação
+// @Description Cria um filtro de palavra-chave (sem distinção de maiúsculas e acentos), regex ou domínio de link, com a ação bloquear, ocultar ou revisar (apenas admin)
+// @Tags Moderação
+// @Accept json
+// @Produce json
+// @Param regra body models.CreateRegraModeracaoRequest true "Regra"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/regras [post]
+// @Security BearerAuth
+func (h *Handler) CreateRegra(c *gin.Context) {
+	adminID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateRegraModeracaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	regra, err := h.service.CreateRegra(c.Request.Context(), adminID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao criar regra de moderação")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Regra de moderação criada",
+		Timestamp: time.Now(),
+		Data:      regra,
+	})
+}
+
+// UpdateRegra godoc
+// @Summary Atualizar regra de moderação
+// @Description Altera padrão, ação, descrição ou ativa/desativa a regra (apenas admin)
+// @Tags Moderação
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da regra"
+// @Param regra body models.UpdateRegraModeracaoRequest true "Campos a alterar"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/regras/{id} [put]
+// @Security BearerAuth
+func (h *Handler) UpdateRegra(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRegraModeracaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	regra, err := h.service.UpdateRegra(c.Request.Context(), id, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao atualizar regra de moderação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Regra de moderação atualizada",
+		Timestamp: time.Now(),
+		Data:      regra,
+	})
+}
+
+// DeleteRegra godoc
+// @Summary Excluir regra de moderação
+// @Description Remove a regra dos filtros de publicação (apenas admin)
+// @Tags Moderação
+// @Produce json
+// @Param id path int true "ID da regra"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /moderacao/regras/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteRegra(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRegra(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, "Erro ao excluir regra de moderação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Regra de moderação excluída",
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *Handler) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *Handler) badRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Success:   false,
+		Error:     "Dados inválidos: " + err.Error(),
+		Timestamp: time.Now(),
+	})
+}
+
+func parsePagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func (h *Handler) respondPage(c *gin.Context, message string, data interface{}, page, limit int, total int64) {
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: data,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: totalPages,
+			},
+		},
+	})
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
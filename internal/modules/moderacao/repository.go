@@ -0,0 +1,320 @@
+package moderacao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	ListRegrasAtivas(ctx context.Context) ([]*models.RegraModeracao, error)
+	ListRegras(ctx context.Context, page, limit int) ([]*models.RegraModeracao, int64, error)
+	FindRegra(ctx context.Context, id uint) (*models.RegraModeracao, error)
+	CreateRegra(ctx context.Context, regra *models.RegraModeracao) error
+	UpdateRegra(ctx context.Context, regra *models.RegraModeracao) error
+	DeleteRegra(ctx context.Context, id uint) error
+
+	FindCaso(ctx context.Context, id uint) (*models.CasoModeracao, error)
+	FindCasoAberto(ctx context.Context, tipo models.TipoConteudoModerado, conteudoID uint) (*models.CasoModeracao, error)
+	AbrirCaso(ctx context.Context, caso *models.CasoModeracao) (*models.CasoModeracao, error)
+	UpdateCaso(ctx context.Context, caso *models.CasoModeracao) error
+	ListFila(ctx context.Context, status models.StatusCasoModeracao, page, limit int) ([]*models.CasoModeracao, int64, error)
+	ListCasosAutor(ctx context.Context, autorID uint, page, limit int) ([]*models.CasoModeracao, int64, error)
+	ConteudoMantido(ctx context.Context, tipo models.TipoConteudoModerado, conteudoID uint) (bool, error)
+
+	Denunciar(ctx context.Context, denuncia *models.DenunciaConteudo) (bool, error)
+
+	CreateRecurso(ctx context.Context, recurso *models.RecursoModeracao) error
+	FindRecurso(ctx context.Context, id uint) (*models.RecursoModeracao, error)
+	UpdateRecurso(ctx context.Context, recurso *models.RecursoModeracao) error
+	ListRecursos(ctx context.Context, status models.StatusRecursoModeracao, page, limit int) ([]*models.RecursoModeracao, int64, error)
+
+	CreateInfracao(ctx context.Context, infracao *models.InfracaoModeracao) error
+	AnularInfracoes(ctx context.Context, casoID uint) (int64, error)
+	ContarInfracoes(ctx context.Context, userID uint, desde time.Time) (int64, error)
+	UltimaPublicacao(ctx context.Context, userID uint) (*time.Time, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) ListRegrasAtivas(ctx context.Context) ([]*models.RegraModeracao, error) {
+	var regras []*models.RegraModeracao
+	if err := r.db.WithContext(ctx).Where("ativa = ?", true).Order("id ASC").Find(&regras).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("list_regras_moderacao", "erro ao carregar regras de moderação", err)
+	}
+	return regras, nil
+}
+
+func (r *repository) ListRegras(ctx context.Context, page, limit int) ([]*models.RegraModeracao, int64, error) {
+	var regras []*models.RegraModeracao
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.RegraModeracao{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_regras_moderacao", "erro ao contar regras de moderação", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Offset(offset).Limit(limit).Order("id ASC").Find(&regras).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_regras_moderacao", "erro ao listar regras de moderação", err)
+	}
+	return regras, total, nil
+}
+
+func (r *repository) FindRegra(ctx context.Context, id uint) (*models.RegraModeracao, error) {
+	var regra models.RegraModeracao
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&regra).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "regra_moderacao", Message: "regra de moderação não encontrada", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_regra_moderacao", "erro ao buscar regra de moderação", err)
+	}
+	return &regra, nil
+}
+
+func (r *repository) CreateRegra(ctx context.Context, regra *models.RegraModeracao) error {
+	if err := r.db.WithContext(ctx).Create(regra).Error; err != nil {
+		return apperrors.NewDatabaseError("create_regra_moderacao", "erro ao criar regra de moderação", err)
+	}
+	return nil
+}
+
+func (r *repository) UpdateRegra(ctx context.Context, regra *models.RegraModeracao) error {
+	if err := r.db.WithContext(ctx).Save(regra).Error; err != nil {
+		return apperrors.NewDatabaseError("update_regra_moderacao", "erro ao atualizar regra de moderação", err)
+	}
+	return nil
+}
+
+func (r *repository) DeleteRegra(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.RegraModeracao{}).Error; err != nil {
+		return apperrors.NewDatabaseError("delete_regra_moderacao", "erro ao excluir regra de moderação", err)
+	}
+	return nil
+}
+
+func (r *repository) FindCaso(ctx context.Context, id uint) (*models.CasoModeracao, error) {
+	var caso models.CasoModeracao
+	err := r.db.WithContext(ctx).
+		Preload("Autor").
+		Preload("Denuncias", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC, id ASC") }).
+		Preload("Recurso").
+		Where("id = ?", id).First(&caso).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "caso_moderacao", Message: "caso de moderação não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_caso_moderacao", "erro ao buscar caso de moderação", err)
+	}
+	return &caso, nil
+}
+
+func (r *repository) FindCasoAberto(ctx context.Context, tipo models.TipoConteudoModerado, conteudoID uint) (*models.CasoModeracao, error) {
+	var caso models.CasoModeracao
+	err := r.db.WithContext(ctx).
+		Where("conteudo_tipo = ? AND conteudo_id = ? AND status = ?", tipo, conteudoID, models.StatusCasoPendente).
+		First(&caso).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "caso_moderacao", Message: "nenhum caso aberto para o conteúdo", ID: conteudoID}
+		}
+		return nil, apperrors.NewDatabaseError("find_caso_moderacao", "erro ao buscar caso de moderação", err)
+	}
+	return &caso, nil
+}
+
+// AbrirCaso cria o caso ou devolve o caso já aberto para o mesmo conteúdo; o índice único parcial sobre os casos
+// pendentes garante um único caso aberto por conteúdo mesmo com denúncias simultâneas
+func (r *repository) AbrirCaso(ctx context.Context, caso *models.CasoModeracao) (*models.CasoModeracao, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(caso)
+	if result.Error != nil {
+		return nil, apperrors.NewDatabaseError("abrir_caso_moderacao", "erro ao abrir caso de moderação", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return caso, nil
+	}
+	return r.FindCasoAberto(ctx, caso.ConteudoTipo, caso.ConteudoID)
+}
+
+func (r *repository) UpdateCaso(ctx context.Context, caso *models.CasoModeracao) error {
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Save(caso).Error; err != nil {
+		return apperrors.NewDatabaseError("update_caso_moderacao", "erro ao atualizar caso de moderação", err)
+	}
+	return nil
+}
+
+// ListFila casos com mais denúncias primeiro e, no empate, os mais antigos
+func (r *repository) ListFila(ctx context.Context, status models.StatusCasoModeracao, page, limit int) ([]*models.CasoModeracao, int64, error) {
+	var casos []*models.CasoModeracao
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.CasoModeracao{}).Where("status = ?", status)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_fila_moderacao", "erro ao contar casos de moderação", err)
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("Autor").
+		Offset(offset).Limit(limit).
+		Order("total_denuncias DESC, created_at ASC, id ASC").
+		Find(&casos).Error
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_fila_moderacao", "erro ao listar casos de moderação", err)
+	}
+	return casos, total, nil
+}
+
+func (r *repository) ListCasosAutor(ctx context.Context, autorID uint, page, limit int) ([]*models.CasoModeracao, int64, error) {
+	var casos []*models.CasoModeracao
+	var total int64
+
+	// O autor acompanha apenas o que afetou o conteúdo dele; casos pendentes de conteúdo no ar não são expostos
+	query := r.db.WithContext(ctx).Model(&models.CasoModeracao{}).
+		Where("autor_id = ? AND decisao IS NOT NULL AND decisao <> ?", autorID, models.AcaoManter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_casos_autor", "erro ao contar casos do autor", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Preload("Recurso").Offset(offset).Limit(limit).Order("decidido_em DESC, id DESC").Find(&casos).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_casos_autor", "erro ao listar casos do autor", err)
+	}
+	return casos, total, nil
+}
+
+// ConteudoMantido indica se um moderador já revisou o conteúdo e decidiu mantê-lo no ar
+func (r *repository) ConteudoMantido(ctx context.Context, tipo models.TipoConteudoModerado, conteudoID uint) (bool, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&models.CasoModeracao{}).
+		Where("conteudo_tipo = ? AND conteudo_id = ? AND decisao = ?", tipo, conteudoID, models.AcaoManter).
+		Count(&total).Error
+	if err != nil {
+		return false, apperrors.NewDatabaseError("conteudo_mantido", "erro ao consultar decisões anteriores", err)
+	}
+	return total > 0, nil
+}
+
+// Denunciar registra a denúncia uma única vez por usuário e caso; repetir a denúncia não altera o total
+func (r *repository) Denunciar(ctx context.Context, denuncia *models.DenunciaConteudo) (bool, error) {
+	criada := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(denuncia)
+		if result.Error != nil {
+			return result.Error
+		}
+		criada = result.RowsAffected == 1
+		if !criada {
+			return nil
+		}
+		return tx.Model(&models.CasoModeracao{}).Where("id = ?", denuncia.CasoID).
+			UpdateColumn("total_denuncias", gorm.Expr("total_denuncias + 1")).Error
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("denunciar_conteudo", "erro ao registrar denúncia", err)
+	}
+	return criada, nil
+}
+
+func (r *repository) CreateRecurso(ctx context.Context, recurso *models.RecursoModeracao) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(recurso)
+	if result.Error != nil {
+		return apperrors.NewDatabaseError("create_recurso_moderacao", "erro ao registrar recurso", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.ConflictError{Resource: "recurso_moderacao", Message: "já existe recurso para este caso", Value: recurso.CasoID}
+	}
+	return nil
+}
+
+func (r *repository) FindRecurso(ctx context.Context, id uint) (*models.RecursoModeracao, error) {
+	var recurso models.RecursoModeracao
+	if err := r.db.WithContext(ctx).Preload("Caso").Where("id = ?", id).First(&recurso).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "recurso_moderacao", Message: "recurso não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_recurso_moderacao", "erro ao buscar recurso", err)
+	}
+	return &recurso, nil
+}
+
+func (r *repository) UpdateRecurso(ctx context.Context, recurso *models.RecursoModeracao) error {
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Save(recurso).Error; err != nil {
+		return apperrors.NewDatabaseError("update_recurso_moderacao", "erro ao atualizar recurso", err)
+	}
+	return nil
+}
+
+func (r *repository) ListRecursos(ctx context.Context, status models.StatusRecursoModeracao, page, limit int) ([]*models.RecursoModeracao, int64, error) {
+	var recursos []*models.RecursoModeracao
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.RecursoModeracao{}).Where("status = ?", status)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_recursos_moderacao", "erro ao contar recursos", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Preload("Caso").Offset(offset).Limit(limit).Order("created_at ASC, id ASC").Find(&recursos).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_recursos_moderacao", "erro ao listar recursos", err)
+	}
+	return recursos, total, nil
+}
+
+func (r *repository) CreateInfracao(ctx context.Context, infracao *models.InfracaoModeracao) error {
+	if err := r.db.WithContext(ctx).Create(infracao).Error; err != nil {
+		return apperrors.NewDatabaseError("create_infracao_moderacao", "erro ao registrar infração", err)
+	}
+	return nil
+}
+
+func (r *repository) AnularInfracoes(ctx context.Context, casoID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.InfracaoModeracao{}).
+		Where("caso_id = ? AND anulada = ?", casoID, false).
+		Update("anulada", true)
+	if result.Error != nil {
+		return 0, apperrors.NewDatabaseError("anular_infracoes", "erro ao anular infrações", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *repository) ContarInfracoes(ctx context.Context, userID uint, desde time.Time) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&models.InfracaoModeracao{}).
+		Where("user_id = ? AND anulada = ? AND created_at >= ?", userID, false, desde).
+		Count(&total).Error
+	if err != nil {
+		return 0, apperrors.NewDatabaseError("contar_infracoes", "erro ao contar infrações", err)
+	}
+	return total, nil
+}
+
+// UltimaPublicacao data do post ou comentário mais recente do usuário
+func (r *repository) UltimaPublicacao(ctx context.Context, userID uint) (*time.Time, error) {
+	var posts, comentarios []time.Time
+	db := r.db.WithContext(ctx)
+	if err := db.Model(&models.PostSocial{}).Where("criado_por = ?", userID).Order("created_at DESC").Limit(1).Pluck("created_at", &posts).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("ultima_publicacao", "erro ao consultar publicações do usuário", err)
+	}
+	if err := db.Model(&models.ComentarioSocial{}).Where("user_id = ?", userID).Order("created_at DESC").Limit(1).Pluck("created_at", &comentarios).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("ultima_publicacao", "erro ao consultar publicações do usuário", err)
+	}
+
+	var ultima *time.Time
+	for _, datas := range [][]time.Time{posts, comentarios} {
+		if len(datas) > 0 && (ultima == nil || datas[0].After(*ultima)) {
+			ultima = &datas[0]
+		}
+	}
+	return ultima, nil
+}
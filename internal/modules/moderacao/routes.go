@@ -0,0 +1,36 @@
+package moderacao
+
+import (
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	social := rg.Group("/social")
+	social.Use(authMiddleware)
+	{
+		social.POST("/posts/:id/denuncias", handler.DenunciarPost)
+		social.POST("/comentarios/:id/denuncias", handler.DenunciarComentario)
+	}
+
+	moderacao := rg.Group("/moderacao")
+	moderacao.Use(authMiddleware)
+	{
+		moderacao.GET("/meus-casos", handler.ListMeusCasos)
+		moderacao.POST("/casos/:id/recurso", handler.Recorrer)
+	}
+
+	admin := rg.Group("/moderacao")
+	admin.Use(authMiddleware, middleware.RequireAdminMiddleware())
+	{
+		admin.GET("/fila", handler.ListFila)
+		admin.GET("/casos/:id", handler.GetCaso)
+		admin.POST("/casos/:id/decisao", handler.Decidir)
+		admin.GET("/recursos", handler.ListRecursos)
+		admin.POST("/recursos/:id/decisao", handler.DecidirRecurso)
+		admin.GET("/regras", handler.ListRegras)
+		admin.POST("/regras", handler.CreateRegra)
+		admin.PUT("/regras/:id", handler.UpdateRegra)
+		admin.DELETE("/regras/:id", handler.DeleteRegra)
+	}
+}
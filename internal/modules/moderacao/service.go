@@ -0,0 +1,656 @@
+package moderacao
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/social"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+const (
+	// limiteDenunciasOcultar denúncias de usuários distintos que ocultam o conteúdo até a revisão
+	limiteDenunciasOcultar = 3
+	// janelaReincidencia período em que as infrações contam para o limite de publicação
+	janelaReincidencia = 30 * 24 * time.Hour
+	// limiteInfracoes infrações na janela a partir das quais o autor é tratado como reincidente
+	limiteInfracoes = 3
+	// intervaloReincidente intervalo mínimo entre publicações de autores reincidentes
+	intervaloReincidente = 10 * time.Minute
+	// prazoRecurso prazo para o autor recorrer de uma retirada
+	prazoRecurso = 30 * 24 * time.Hour
+)
+
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+// CasoDetalhe caso da fila com o conteúdo moderado, para a decisão do moderador
+type CasoDetalhe struct {
+	*models.CasoModeracao
+	Post       *models.PostSocial       `json:"post,omitempty"`
+	Comentario *models.ComentarioSocial `json:"comentario,omitempty"`
+}
+
+type Service interface {
+	AvaliarPublicacao(ctx context.Context, autorID uint, texto string) (*models.AvaliacaoModeracao, error)
+	EncaminharRevisao(ctx context.Context, avaliacao *models.AvaliacaoModeracao, tipo models.TipoConteudoModerado, conteudoID, autorID uint) error
+
+	Denunciar(ctx context.Context, tipo models.TipoConteudoModerado, conteudoID uint, userID uint, req *models.DenunciarConteudoRequest) (*models.DenunciaConteudo, error)
+
+	ListFila(ctx context.Context, status models.StatusCasoModeracao, page, limit int) ([]*models.CasoModeracao, int64, error)
+	GetCaso(ctx context.Context, id uint) (*CasoDetalhe, error)
+	Decidir(ctx context.Context, id uint, moderadorID uint, req *models.DecidirCasoRequest) (*models.CasoModeracao, error)
+	ListMeusCasos(ctx context.Context, userID uint, page, limit int) ([]*models.CasoModeracao, int64, error)
+
+	Recorrer(ctx context.Context, casoID uint, userID uint, req *models.CreateRecursoRequest) (*models.RecursoModeracao, error)
+	ListRecursos(ctx context.Context, status models.StatusRecursoModeracao, page, limit int) ([]*models.RecursoModeracao, int64, error)
+	DecidirRecurso(ctx context.Context, id uint, revisorID uint, req *models.DecidirRecursoRequest) (*models.RecursoModeracao, error)
+
+	ListRegras(ctx context.Context, page, limit int) ([]*models.RegraModeracao, int64, error)
+	CreateRegra(ctx context.Context, userID uint, req *models.CreateRegraModeracaoRequest) (*models.RegraModeracao, error)
+	UpdateRegra(ctx context.Context, id uint, req *models.UpdateRegraModeracaoRequest) (*models.RegraModeracao, error)
+	DeleteRegra(ctx context.Context, id uint) error
+}
+
+type service struct {
+	repo       Repository
+	socialRepo social.Repository
+	audit      AuditLogger
+	logger     *logging.Logger
+}
+
+func NewService(repo Repository, socialRepo social.Repository, audit AuditLogger, logger *logging.Logger) Service {
+	return &service{
+		repo:       repo,
+		socialRepo: socialRepo,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+// conteudo post ou comentário alvo de um caso
+type conteudo struct {
+	post       *models.PostSocial
+	comentario *models.ComentarioSocial
+	autorID    uint
+	status     models.StatusPost
+	perfilID   uint
+}
+
+// AvaliarPublicacao aplica o limite de reincidência e as regras ativas ao texto antes da publicação. Regras de
+// bloqueio recusam o conteúdo e contam como infração; ocultar publica o conteúdo visível só para o autor; revisar
+// publica e encaminha para a fila
+func (s *service) AvaliarPublicacao(ctx context.Context, autorID uint, texto string) (*models.AvaliacaoModeracao, error) {
+	avaliacao := &models.AvaliacaoModeracao{Status: models.StatusPostAtivo}
+
+	reincidente, err := s.checkReincidencia(ctx, autorID)
+	if err != nil {
+		return nil, err
+	}
+	if reincidente {
+		avaliacao.Revisar = true
+		avaliacao.Motivo = "autor reincidente"
+	}
+
+	regras, err := s.repo.ListRegrasAtivas(ctx)
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.AvaliarPublicacao", logging.Fields{"user_id": autorID})
+		return nil, err
+	}
+
+	normalizado := normalizar(texto)
+	hosts := dominios(texto)
+	var acao models.AcaoModeracao
+	var decisiva *models.RegraModeracao
+	for _, regra := range regras {
+		if !aciona(regra, texto, normalizado, hosts) {
+			continue
+		}
+		avaliacao.Regras = append(avaliacao.Regras, regra.ID)
+		if severidade[regra.Acao] > severidade[acao] {
+			acao, decisiva = regra.Acao, regra
+		}
+	}
+
+	switch acao {
+	case models.AcaoBloquear:
+		infracao := &models.InfracaoModeracao{
+			UserID:  autorID,
+			RegraID: &decisiva.ID,
+			Motivo:  motivoRegra(decisiva),
+		}
+		if err := s.repo.CreateInfracao(ctx, infracao); err != nil {
+			s.logger.LogError(err, "ModeracaoService.AvaliarPublicacao", logging.Fields{"user_id": autorID})
+			return nil, err
+		}
+		s.recordChange(ctx, "moderacao_infracao", fmt.Sprint(infracao.ID), "bloquear_publicacao", nil, infracao)
+		s.logger.LogBusinessEvent("publicacao_bloqueada", "Publicação recusada pelos filtros de moderação", autorID, "", logging.Fields{"regra_id": decisiva.ID})
+		return nil, &apperrors.ValidationError{Field: "conteudo", Message: "o conteúdo viola as regras da comunidade e não foi publicado"}
+	case models.AcaoOcultar:
+		avaliacao.Status = models.StatusPostOculto
+		avaliacao.Motivo = motivoRegra(decisiva)
+	case models.AcaoRevisar:
+		avaliacao.Revisar = true
+		avaliacao.Motivo = motivoRegra(decisiva)
+	}
+	return avaliacao, nil
+}
+
+// EncaminharRevisao abre o caso na fila para o conteúdo que os filtros ocultaram ou marcaram para revisão; o
+// conteúdo oculto conta como infração até a decisão do moderador
+func (s *service) EncaminharRevisao(ctx context.Context, avaliacao *models.AvaliacaoModeracao, tipo models.TipoConteudoModerado, conteudoID, autorID uint) error {
+	caso, err := s.repo.AbrirCaso(ctx, &models.CasoModeracao{
+		ConteudoTipo:    tipo,
+		ConteudoID:      conteudoID,
+		AutorID:         autorID,
+		Origem:          models.OrigemFiltro,
+		RegrasAcionadas: models.JSONB{"regras": avaliacao.Regras, "motivo": avaliacao.Motivo, "oculto": avaliacao.Status == models.StatusPostOculto},
+		Status:          models.StatusCasoPendente,
+	})
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.EncaminharRevisao", logging.Fields{"tipo": tipo, "conteudo_id": conteudoID})
+		return err
+	}
+	s.recordChange(ctx, "moderacao_caso", fmt.Sprint(caso.ID), "encaminhar_revisao", nil, caso)
+
+	if avaliacao.Status != models.StatusPostOculto {
+		return nil
+	}
+	return s.registrarInfracao(ctx, caso, avaliacao.Motivo)
+}
+
+// Denunciar registra a denúncia no caso aberto do conteúdo, criando-o se preciso; ao atingir o limite de denúncias o
+// conteúdo é ocultado até a revisão, a menos que um moderador já o tenha mantido
+func (s *service) Denunciar(ctx context.Context, tipo models.TipoConteudoModerado, conteudoID uint, userID uint, req *models.DenunciarConteudoRequest) (*models.DenunciaConteudo, error) {
+	if !models.IsValidMotivoDenuncia(req.Motivo) {
+		return nil, &apperrors.ValidationError{Field: "motivo", Message: "motivo deve ser spam, abuso, fraude, conteudo_improprio ou outro", Value: req.Motivo}
+	}
+	alvo, err := s.findConteudo(ctx, tipo, conteudoID)
+	if err != nil {
+		return nil, err
+	}
+	if alvo.status != models.StatusPostAtivo {
+		return nil, &apperrors.NotFoundError{Resource: string(tipo), Message: "conteúdo não encontrado", ID: conteudoID}
+	}
+	if alvo.autorID == userID {
+		return nil, &apperrors.ValidationError{Field: "conteudo_id", Message: "não é possível denunciar o próprio conteúdo", Value: conteudoID}
+	}
+
+	caso, err := s.repo.AbrirCaso(ctx, &models.CasoModeracao{
+		ConteudoTipo: tipo,
+		ConteudoID:   conteudoID,
+		AutorID:      alvo.autorID,
+		Origem:       models.OrigemDenuncia,
+		Status:       models.StatusCasoPendente,
+	})
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.Denunciar", logging.Fields{"tipo": tipo, "conteudo_id": conteudoID})
+		return nil, err
+	}
+
+	denuncia := &models.DenunciaConteudo{
+		CasoID:        caso.ID,
+		DenuncianteID: userID,
+		Motivo:        req.Motivo,
+		Descricao:     strings.TrimSpace(req.Descricao),
+	}
+	criada, err := s.repo.Denunciar(ctx, denuncia)
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.Denunciar", logging.Fields{"caso_id": caso.ID})
+		return nil, err
+	}
+	if !criada {
+		return nil, &apperrors.ConflictError{Resource: "denuncia", Message: "você já denunciou este conteúdo", Value: conteudoID}
+	}
+	s.recordChange(ctx, "moderacao_denuncia", fmt.Sprint(denuncia.ID), "denunciar", nil, denuncia)
+	s.logger.LogBusinessEvent("conteudo_denunciado", "Conteúdo social denunciado", userID, "", logging.Fields{"caso_id": caso.ID, "tipo": tipo, "conteudo_id": conteudoID})
+
+	if err := s.ocultarPorDenuncias(ctx, caso.ID, alvo); err != nil {
+		// a denúncia já está registrada; o caso continua na fila mesmo sem a ocultação automática
+		s.logger.LogError(err, "ModeracaoService.Denunciar", logging.Fields{"caso_id": caso.ID})
+	}
+	return denuncia, nil
+}
+
+func (s *service) ListFila(ctx context.Context, status models.StatusCasoModeracao, page, limit int) ([]*models.CasoModeracao, int64, error) {
+	if status == "" {
+		status = models.StatusCasoPendente
+	}
+	casos, total, err := s.repo.ListFila(ctx, status, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.ListFila", logging.Fields{"status": status})
+		return nil, 0, err
+	}
+	return casos, total, nil
+}
+
+func (s *service) GetCaso(ctx context.Context, id uint) (*CasoDetalhe, error) {
+	caso, err := s.repo.FindCaso(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	alvo, err := s.findConteudo(ctx, caso.ConteudoTipo, caso.ConteudoID)
+	if err != nil && !apperrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	detalhe := &CasoDetalhe{CasoModeracao: caso}
+	if alvo != nil {
+		detalhe.Post, detalhe.Comentario = alvo.post, alvo.comentario
+	}
+	return detalhe, nil
+}
+
+// Decidir resolve o caso: manter devolve o conteúdo ao ar, ocultar o deixa visível só para o autor e retirar o tira
+// do ar com direito a recurso. Ocultar e retirar contam como infração do autor
+func (s *service) Decidir(ctx context.Context, id uint, moderadorID uint, req *models.DecidirCasoRequest) (*models.CasoModeracao, error) {
+	var destino models.StatusPost
+	switch req.Acao {
+	case models.AcaoManter:
+		destino = models.StatusPostAtivo
+	case models.AcaoOcultar:
+		destino = models.StatusPostOculto
+	case models.AcaoRetirar:
+		destino = models.StatusPostRetirado
+	default:
+		return nil, &apperrors.ValidationError{Field: "acao", Message: "ação deve ser manter, ocultar ou retirar", Value: req.Acao}
+	}
+
+	caso, err := s.repo.FindCaso(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if caso.Status != models.StatusCasoPendente {
+		return nil, &apperrors.ConflictError{Resource: "caso_moderacao", Message: "caso já decidido", Value: id}
+	}
+	antes := *caso
+
+	if err := s.alterarStatus(ctx, caso, moderados, destino); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	caso.Status = models.StatusCasoResolvido
+	caso.Decisao = &req.Acao
+	caso.MotivoDecisao = strings.TrimSpace(req.Motivo)
+	caso.ModeradorID = &moderadorID
+	caso.DecididoEm = &now
+	if err := s.repo.UpdateCaso(ctx, caso); err != nil {
+		s.logger.LogError(err, "ModeracaoService.Decidir", logging.Fields{"caso_id": id})
+		return nil, err
+	}
+	s.recordChange(ctx, "moderacao_caso", fmt.Sprint(caso.ID), "decidir", antes, *caso)
+
+	// Conteúdo oculto pelos filtros já contou como infração no encaminhamento; mantê-lo no ar anula essa infração
+	jaContada := ocultoPeloFiltro(caso)
+	switch {
+	case req.Acao != models.AcaoManter && !jaContada:
+		if err := s.registrarInfracao(ctx, caso, caso.MotivoDecisao); err != nil {
+			return nil, err
+		}
+	case req.Acao == models.AcaoManter && jaContada:
+		if _, err := s.repo.AnularInfracoes(ctx, caso.ID); err != nil {
+			s.logger.LogError(err, "ModeracaoService.Decidir", logging.Fields{"caso_id": id})
+			return nil, err
+		}
+	}
+
+	s.logger.LogBusinessEvent("caso_moderacao_decidido", "Caso de moderação decidido", moderadorID, "", logging.Fields{"caso_id": id, "acao": req.Acao})
+	return caso, nil
+}
+
+// ListMeusCasos decisões de moderação que afetaram o conteúdo do usuário, com o recurso se houver
+func (s *service) ListMeusCasos(ctx context.Context, userID uint, page, limit int) ([]*models.CasoModeracao, int64, error) {
+	casos, total, err := s.repo.ListCasosAutor(ctx, userID, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.ListMeusCasos", logging.Fields{"user_id": userID})
+		return nil, 0, err
+	}
+	return casos, total, nil
+}
+
+// Recorrer o autor pode recorrer uma única vez de uma retirada, dentro do prazo de recurso
+func (s *service) Recorrer(ctx context.Context, casoID uint, userID uint, req *models.CreateRecursoRequest) (*models.RecursoModeracao, error) {
+	justificativa := strings.TrimSpace(req.Justificativa)
+	if justificativa == "" {
+		return nil, &apperrors.ValidationError{Field: "justificativa", Message: "justificativa é obrigatória"}
+	}
+
+	caso, err := s.repo.FindCaso(ctx, casoID)
+	if err != nil {
+		return nil, err
+	}
+	if caso.AutorID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o autor do conteúdo pode recorrer"}).WithAction("recorrer", "caso_moderacao")
+	}
+	if caso.Status != models.StatusCasoResolvido || caso.Decisao == nil || *caso.Decisao != models.AcaoRetirar {
+		return nil, &apperrors.ValidationError{Field: "caso_id", Message: "apenas retiradas de conteúdo admitem recurso", Value: casoID}
+	}
+	if caso.DecididoEm != nil && time.Since(*caso.DecididoEm) > prazoRecurso {
+		return nil, &apperrors.ValidationError{Field: "caso_id", Message: "prazo de 30 dias para recurso encerrado", Value: casoID}
+	}
+
+	recurso := &models.RecursoModeracao{
+		CasoID:        caso.ID,
+		AutorID:       userID,
+		Justificativa: justificativa,
+		Status:        models.StatusRecursoPendente,
+	}
+	if err := s.repo.CreateRecurso(ctx, recurso); err != nil {
+		if !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "ModeracaoService.Recorrer", logging.Fields{"caso_id": casoID})
+		}
+		return nil, err
+	}
+
+	s.recordChange(ctx, "moderacao_recurso", fmt.Sprint(recurso.ID), "recorrer", nil, recurso)
+	s.logger.LogBusinessEvent("recurso_moderacao_aberto", "Autor recorreu da retirada de conteúdo", userID, "", logging.Fields{"caso_id": casoID})
+	return recurso, nil
+}
+
+func (s *service) ListRecursos(ctx context.Context, status models.StatusRecursoModeracao, page, limit int) ([]*models.RecursoModeracao, int64, error) {
+	if status == "" {
+		status = models.StatusRecursoPendente
+	}
+	recursos, total, err := s.repo.ListRecursos(ctx, status, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.ListRecursos", logging.Fields{"status": status})
+		return nil, 0, err
+	}
+	return recursos, total, nil
+}
+
+// DecidirRecurso recurso deferido devolve o conteúdo ao ar, anula as infrações do caso e marca o caso como revertido
+func (s *service) DecidirRecurso(ctx context.Context, id uint, revisorID uint, req *models.DecidirRecursoRequest) (*models.RecursoModeracao, error) {
+	recurso, err := s.repo.FindRecurso(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if recurso.Status != models.StatusRecursoPendente {
+		return nil, &apperrors.ConflictError{Resource: "recurso_moderacao", Message: "recurso já decidido", Value: id}
+	}
+	if recurso.Caso == nil {
+		return nil, &apperrors.NotFoundError{Resource: "caso_moderacao", Message: "caso de moderação não encontrado", ID: recurso.CasoID}
+	}
+	if recurso.Caso.ModeradorID != nil && *recurso.Caso.ModeradorID == revisorID {
+		return nil, (&apperrors.AuthorizationError{Message: "o recurso deve ser decidido por outro moderador"}).WithAction("decidir_recurso", "recurso_moderacao")
+	}
+	antes := *recurso
+	antes.Caso = nil
+
+	if *req.Deferido {
+		caso := recurso.Caso
+		casoAntes := *caso
+		if err := s.alterarStatus(ctx, caso, []models.StatusPost{models.StatusPostRetirado}, models.StatusPostAtivo); err != nil {
+			return nil, err
+		}
+		if _, err := s.repo.AnularInfracoes(ctx, caso.ID); err != nil {
+			s.logger.LogError(err, "ModeracaoService.DecidirRecurso", logging.Fields{"caso_id": caso.ID})
+			return nil, err
+		}
+		caso.Status = models.StatusCasoRevertido
+		if err := s.repo.UpdateCaso(ctx, caso); err != nil {
+			s.logger.LogError(err, "ModeracaoService.DecidirRecurso", logging.Fields{"caso_id": caso.ID})
+			return nil, err
+		}
+		s.recordChange(ctx, "moderacao_caso", fmt.Sprint(caso.ID), "reverter", casoAntes, *caso)
+		recurso.Status = models.StatusRecursoDeferido
+	} else {
+		recurso.Status = models.StatusRecursoIndeferido
+	}
+
+	now := time.Now()
+	recurso.RevisorID = &revisorID
+	recurso.Resposta = strings.TrimSpace(req.Resposta)
+	recurso.DecididoEm = &now
+	if err := s.repo.UpdateRecurso(ctx, recurso); err != nil {
+		s.logger.LogError(err, "ModeracaoService.DecidirRecurso", logging.Fields{"recurso_id": id})
+		return nil, err
+	}
+
+	depois := *recurso
+	depois.Caso = nil
+	s.recordChange(ctx, "moderacao_recurso", fmt.Sprint(recurso.ID), "decidir_recurso", antes, depois)
+	s.logger.LogBusinessEvent("recurso_moderacao_decidido", "Recurso de moderação decidido", revisorID, "", logging.Fields{"recurso_id": id, "status": recurso.Status})
+	return recurso, nil
+}
+
+func (s *service) ListRegras(ctx context.Context, page, limit int) ([]*models.RegraModeracao, int64, error) {
+	regras, total, err := s.repo.ListRegras(ctx, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.ListRegras", nil)
+		return nil, 0, err
+	}
+	return regras, total, nil
+}
+
+func (s *service) CreateRegra(ctx context.Context, userID uint, req *models.CreateRegraModeracaoRequest) (*models.RegraModeracao, error) {
+	regra := &models.RegraModeracao{
+		Tipo:      req.Tipo,
+		Padrao:    strings.TrimSpace(req.Padrao),
+		Acao:      req.Acao,
+		Descricao: req.Descricao,
+		Ativa:     true,
+		CriadoPor: userID,
+	}
+	if err := validateRegra(regra); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRegra(ctx, regra); err != nil {
+		s.logger.LogError(err, "ModeracaoService.CreateRegra", nil)
+		return nil, err
+	}
+
+	s.recordChange(ctx, "moderacao_regra", fmt.Sprint(regra.ID), "create", nil, regra)
+	return regra, nil
+}
+
+func (s *service) UpdateRegra(ctx context.Context, id uint, req *models.UpdateRegraModeracaoRequest) (*models.RegraModeracao, error) {
+	regra, err := s.repo.FindRegra(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	antes := *regra
+
+	if req.Padrao != nil {
+		regra.Padrao = strings.TrimSpace(*req.Padrao)
+	}
+	if req.Acao != nil {
+		regra.Acao = *req.Acao
+	}
+	if req.Descricao != nil {
+		regra.Descricao = *req.Descricao
+	}
+	if req.Ativa != nil {
+		regra.Ativa = *req.Ativa
+	}
+	if err := validateRegra(regra); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRegra(ctx, regra); err != nil {
+		s.logger.LogError(err, "ModeracaoService.UpdateRegra", logging.Fields{"regra_id": id})
+		return nil, err
+	}
+	s.recordChange(ctx, "moderacao_regra", fmt.Sprint(regra.ID), "update", antes, *regra)
+	return regra, nil
+}
+
+func (s *service) DeleteRegra(ctx context.Context, id uint) error {
+	regra, err := s.repo.FindRegra(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteRegra(ctx, id); err != nil {
+		s.logger.LogError(err, "ModeracaoService.DeleteRegra", logging.Fields{"regra_id": id})
+		return err
+	}
+	s.recordChange(ctx, "moderacao_regra", fmt.Sprint(id), "delete", regra, nil)
+	return nil
+}
+
+// moderados status de conteúdo sobre os quais o moderador decide; conteúdo removido pelo autor fica como está
+var moderados = []models.StatusPost{models.StatusPostAtivo, models.StatusPostOculto, models.StatusPostRetirado}
+
+// checkReincidencia autores com infrações recentes publicam com intervalo mínimo e sempre passam pela fila
+func (s *service) checkReincidencia(ctx context.Context, autorID uint) (bool, error) {
+	infracoes, err := s.repo.ContarInfracoes(ctx, autorID, time.Now().Add(-janelaReincidencia))
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.checkReincidencia", logging.Fields{"user_id": autorID})
+		return false, err
+	}
+	if infracoes < limiteInfracoes {
+		return false, nil
+	}
+
+	ultima, err := s.repo.UltimaPublicacao(ctx, autorID)
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.checkReincidencia", logging.Fields{"user_id": autorID})
+		return false, err
+	}
+	if ultima != nil && time.Since(*ultima) < intervaloReincidente {
+		liberaEm := ultima.Add(intervaloReincidente)
+		return false, apperrors.NewBusinessError("publicacao_limitada",
+			fmt.Sprintf("publicações limitadas por infrações recentes; tente novamente após %s", liberaEm.Format("15:04")),
+			map[string]interface{}{"libera_em": liberaEm, "infracoes": infracoes})
+	}
+	return true, nil
+}
+
+// ocultarPorDenuncias oculta o conteúdo ainda no ar quando o caso atinge o limite de denúncias
+func (s *service) ocultarPorDenuncias(ctx context.Context, casoID uint, alvo *conteudo) error {
+	caso, err := s.repo.FindCaso(ctx, casoID)
+	if err != nil {
+		return err
+	}
+	if caso.TotalDenuncias < limiteDenunciasOcultar {
+		return nil
+	}
+	mantido, err := s.repo.ConteudoMantido(ctx, caso.ConteudoTipo, caso.ConteudoID)
+	if err != nil || mantido {
+		return err
+	}
+
+	antes := alvo.status
+	if err := s.alterarStatus(ctx, caso, []models.StatusPost{models.StatusPostAtivo}, models.StatusPostOculto); err != nil {
+		return err
+	}
+	s.recordChange(ctx, string(caso.ConteudoTipo), fmt.Sprint(caso.ConteudoID), "ocultar_por_denuncias",
+		map[string]interface{}{"status": antes}, map[string]interface{}{"status": models.StatusPostOculto, "caso_id": caso.ID})
+	return nil
+}
+
+// alterarStatus aplica ao post ou comentário do caso a mudança de status, ajustando os contadores sociais
+func (s *service) alterarStatus(ctx context.Context, caso *models.CasoModeracao, de []models.StatusPost, para models.StatusPost) error {
+	alvo, err := s.findConteudo(ctx, caso.ConteudoTipo, caso.ConteudoID)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			// conteúdo excluído pelo autor: a decisão fica registrada no caso
+			return nil
+		}
+		return err
+	}
+
+	if alvo.post != nil {
+		_, err = s.socialRepo.AlterarStatusPost(ctx, alvo.post.ID, de, para)
+	} else {
+		_, err = s.socialRepo.AlterarStatusComentario(ctx, alvo.comentario, alvo.perfilID, de, para)
+	}
+	if err != nil {
+		s.logger.LogError(err, "ModeracaoService.alterarStatus", logging.Fields{"caso_id": caso.ID, "status": para})
+	}
+	return err
+}
+
+func (s *service) registrarInfracao(ctx context.Context, caso *models.CasoModeracao, motivo string) error {
+	conteudoID := caso.ConteudoID
+	infracao := &models.InfracaoModeracao{
+		UserID:       caso.AutorID,
+		CasoID:       &caso.ID,
+		ConteudoTipo: caso.ConteudoTipo,
+		ConteudoID:   &conteudoID,
+		Motivo:       motivo,
+	}
+	if err := s.repo.CreateInfracao(ctx, infracao); err != nil {
+		s.logger.LogError(err, "ModeracaoService.registrarInfracao", logging.Fields{"caso_id": caso.ID})
+		return err
+	}
+	s.recordChange(ctx, "moderacao_infracao", fmt.Sprint(infracao.ID), "create", nil, infracao)
+	return nil
+}
+
+// ocultoPeloFiltro o caso foi aberto por uma regra de ocultação, que já registrou a infração
+func ocultoPeloFiltro(caso *models.CasoModeracao) bool {
+	oculto, _ := caso.RegrasAcionadas["oculto"].(bool)
+	return oculto
+}
+
+func (s *service) findConteudo(ctx context.Context, tipo models.TipoConteudoModerado, id uint) (*conteudo, error) {
+	switch tipo {
+	case models.ConteudoModeradoPost:
+		post, err := s.socialRepo.FindPost(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &conteudo{post: post, autorID: post.CriadoPor, status: post.StatusPost, perfilID: post.PerfilSocialID}, nil
+	case models.ConteudoModeradoComentario:
+		comentario, err := s.socialRepo.FindComentario(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		post, err := s.socialRepo.FindPost(ctx, comentario.PostID)
+		if err != nil {
+			return nil, err
+		}
+		return &conteudo{comentario: comentario, autorID: comentario.UserID, status: comentario.Status, perfilID: post.PerfilSocialID}, nil
+	}
+	return nil, &apperrors.ValidationError{Field: "conteudo_tipo", Message: "tipo de conteúdo inválido", Value: tipo}
+}
+
+func (s *service) recordChange(ctx context.Context, resource, key, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, resource, key, operation, before, after); err != nil {
+		s.logger.LogError(err, "ModeracaoService.recordChange", logging.Fields{"resource": resource, "key": key, "operation": operation})
+	}
+}
+
+// validateRegra regras de publicação aceitam apenas bloquear, ocultar ou revisar; padrões regex precisam compilar
+func validateRegra(regra *models.RegraModeracao) error {
+	if regra.Padrao == "" {
+		return &apperrors.ValidationError{Field: "padrao", Message: "padrão é obrigatório"}
+	}
+	if _, ok := severidade[regra.Acao]; !ok {
+		return &apperrors.ValidationError{Field: "acao", Message: "ação deve ser bloquear, ocultar ou revisar", Value: regra.Acao}
+	}
+
+	switch regra.Tipo {
+	case models.RegraPalavraChave:
+	case models.RegraRegex:
+		if _, err := regexp.Compile("(?i)" + regra.Padrao); err != nil {
+			return &apperrors.ValidationError{Field: "padrao", Message: "expressão regular inválida: " + err.Error(), Value: regra.Padrao}
+		}
+	case models.RegraLink:
+		if strings.ContainsAny(regra.Padrao, " /") {
+			return &apperrors.ValidationError{Field: "padrao", Message: "informe apenas o domínio (ex.: exemplo.com) ou *", Value: regra.Padrao}
+		}
+	default:
+		return &apperrors.ValidationError{Field: "tipo", Message: "tipo deve ser palavra_chave, regex ou link", Value: regra.Tipo}
+	}
+	return nil
+}
+
+func motivoRegra(regra *models.RegraModeracao) string {
+	if regra.Descricao != "" {
+		return regra.Descricao
+	}
+	return fmt.Sprintf("regra %d (%s)", regra.ID, regra.Tipo)
+}
@@ -0,0 +1,179 @@
+package notificacoes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListNotificacoes godoc
+// @Summary Listar notificações
+// @Description Notificações do usuário, das mais recentes para as mais antigas, com o total ainda não lido
+// @Tags Notificações
+// @Produce json
+// @Param nao_lidas query bool false "Somente não lidas"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /notificacoes [get]
+// @Security BearerAuth
+func (h *Handler) ListNotificacoes(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := parsePagination(c)
+	naoLidas, _ := strconv.ParseBool(c.Query("nao_lidas"))
+
+	caixa, total, err := h.service.List(c.Request.Context(), userID, naoLidas, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar notificações")
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Notificações (não lidas: %d)", caixa.NaoLidas),
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: caixa,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: totalPages,
+			},
+		},
+	})
+}
+
+// MarcarLida godoc
+// @Summary Marcar notificação como lida
+// @Tags Notificações
+// @Produce json
+// @Param id path int true "ID da notificação"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /notificacoes/{id}/lida [post]
+// @Security BearerAuth
+func (h *Handler) MarcarLida(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.MarcarLida(c.Request.Context(), uint(id), userID); err != nil {
+		h.respondError(c, err, "Erro ao marcar notificação como lida")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Notificação marcada como lida",
+		Timestamp: time.Now(),
+	})
+}
+
+// MarcarTodasLidas godoc
+// @Summary Marcar todas as notificações como lidas
+// @Tags Notificações
+// @Produce json
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /notificacoes/lidas [post]
+// @Security BearerAuth
+func (h *Handler) MarcarTodasLidas(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	marcadas, err := h.service.MarcarTodasLidas(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao marcar notificações como lidas")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("%d notificações marcadas como lidas", marcadas),
+		Timestamp: time.Now(),
+		Data:      gin.H{"marcadas": marcadas},
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+func parsePagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	if apperrors.IsNotFound(err) {
+		status = http.StatusNotFound
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,96 @@
+package notificacoes
+
+import (
+	"context"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(ctx context.Context, notificacoes []*models.Notificacao) error
+	List(ctx context.Context, userID uint, naoLidas bool, page, limit int) ([]*models.Notificacao, int64, error)
+	ContarNaoLidas(ctx context.Context, userID uint) (int64, error)
+	MarcarLida(ctx context.Context, id, userID uint) (bool, error)
+	MarcarTodasLidas(ctx context.Context, userID uint) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, notificacoes []*models.Notificacao) error {
+	if len(notificacoes) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Create(&notificacoes).Error; err != nil {
+		return apperrors.NewDatabaseError("create_notificacoes", "erro ao registrar notificações", err)
+	}
+	return nil
+}
+
+func (r *repository) List(ctx context.Context, userID uint, naoLidas bool, page, limit int) ([]*models.Notificacao, int64, error) {
+	var notificacoes []*models.Notificacao
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Notificacao{}).Where("user_id = ?", userID)
+	if naoLidas {
+		query = query.Where("lida_em IS NULL")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_notificacoes", "erro ao contar notificações", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Offset(offset).Limit(limit).Order("created_at DESC, id DESC").Find(&notificacoes).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_notificacoes", "erro ao listar notificações", err)
+	}
+	return notificacoes, total, nil
+}
+
+func (r *repository) ContarNaoLidas(ctx context.Context, userID uint) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&models.Notificacao{}).
+		Where("user_id = ? AND lida_em IS NULL", userID).
+		Count(&total).Error
+	if err != nil {
+		return 0, apperrors.NewDatabaseError("contar_notificacoes", "erro ao contar notificações não lidas", err)
+	}
+	return total, nil
+}
+
+// MarcarLida devolve false quando a notificação não existe ou pertence a outro usuário; marcar de novo não altera a data
+func (r *repository) MarcarLida(ctx context.Context, id, userID uint) (bool, error) {
+	var notificacao models.Notificacao
+	err := r.db.WithContext(ctx).Select("id").Where("id = ? AND user_id = ?", id, userID).Limit(1).Find(&notificacao).Error
+	if err != nil {
+		return false, apperrors.NewDatabaseError("marcar_notificacao_lida", "erro ao buscar notificação", err)
+	}
+	if notificacao.ID == 0 {
+		return false, nil
+	}
+
+	err = r.db.WithContext(ctx).Model(&models.Notificacao{}).
+		Where("id = ? AND lida_em IS NULL", id).
+		Update("lida_em", time.Now()).Error
+	if err != nil {
+		return false, apperrors.NewDatabaseError("marcar_notificacao_lida", "erro ao marcar notificação como lida", err)
+	}
+	return true, nil
+}
+
+func (r *repository) MarcarTodasLidas(ctx context.Context, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Notificacao{}).
+		Where("user_id = ? AND lida_em IS NULL", userID).
+		Update("lida_em", time.Now())
+	if result.Error != nil {
+		return 0, apperrors.NewDatabaseError("marcar_notificacoes_lidas", "erro ao marcar notificações como lidas", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,15 @@
+package notificacoes
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	notificacoes := rg.Group("/notificacoes")
+	notificacoes.Use(authMiddleware)
+	{
+		notificacoes.GET("", handler.ListNotificacoes)
+		notificacoes.POST("/lidas", handler.MarcarTodasLidas)
+		notificacoes.POST("/:id/lida", handler.MarcarLida)
+	}
+}
@@ -0,0 +1,95 @@
+package notificacoes
+
+import (
+	"context"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+// Caixa notificações de uma página com o total ainda não lido
+type Caixa struct {
+	Notificacoes []*models.Notificacao `json:"notificacoes"`
+	NaoLidas     int64                 `json:"nao_lidas"`
+}
+
+type Service interface {
+	Notificar(ctx context.Context, userIDs []uint, notificacao models.NovaNotificacao) error
+	List(ctx context.Context, userID uint, naoLidas bool, page, limit int) (*Caixa, int64, error)
+	MarcarLida(ctx context.Context, id, userID uint) error
+	MarcarTodasLidas(ctx context.Context, userID uint) (int64, error)
+}
+
+type service struct {
+	repo   Repository
+	logger *logging.Logger
+}
+
+func NewService(repo Repository, logger *logging.Logger) Service {
+	return &service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Notificar entrega a mesma notificação a cada destinatário uma única vez, ignorando IDs repetidos ou zerados
+func (s *service) Notificar(ctx context.Context, userIDs []uint, notificacao models.NovaNotificacao) error {
+	vistos := make(map[uint]bool, len(userIDs))
+	var lote []*models.Notificacao
+	for _, userID := range userIDs {
+		if userID == 0 || vistos[userID] {
+			continue
+		}
+		vistos[userID] = true
+		lote = append(lote, &models.Notificacao{
+			UserID:         userID,
+			Tipo:           notificacao.Tipo,
+			Titulo:         notificacao.Titulo,
+			Mensagem:       notificacao.Mensagem,
+			ReferenciaTipo: notificacao.ReferenciaTipo,
+			ReferenciaID:   notificacao.ReferenciaID,
+		})
+	}
+
+	if err := s.repo.Create(ctx, lote); err != nil {
+		s.logger.LogError(err, "NotificacaoService.Notificar", logging.Fields{"tipo": notificacao.Tipo, "destinatarios": len(lote)})
+		return err
+	}
+	return nil
+}
+
+func (s *service) List(ctx context.Context, userID uint, naoLidas bool, page, limit int) (*Caixa, int64, error) {
+	notificacoes, total, err := s.repo.List(ctx, userID, naoLidas, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "NotificacaoService.List", logging.Fields{"user_id": userID})
+		return nil, 0, err
+	}
+	pendentes, err := s.repo.ContarNaoLidas(ctx, userID)
+	if err != nil {
+		s.logger.LogError(err, "NotificacaoService.List", logging.Fields{"user_id": userID})
+		return nil, 0, err
+	}
+	return &Caixa{Notificacoes: notificacoes, NaoLidas: pendentes}, total, nil
+}
+
+func (s *service) MarcarLida(ctx context.Context, id, userID uint) error {
+	encontrada, err := s.repo.MarcarLida(ctx, id, userID)
+	if err != nil {
+		s.logger.LogError(err, "NotificacaoService.MarcarLida", logging.Fields{"notificacao_id": id, "user_id": userID})
+		return err
+	}
+	if !encontrada {
+		return &apperrors.NotFoundError{Resource: "notificacao", Message: "notificação não encontrada", ID: id}
+	}
+	return nil
+}
+
+func (s *service) MarcarTodasLidas(ctx context.Context, userID uint) (int64, error) {
+	marcadas, err := s.repo.MarcarTodasLidas(ctx, userID)
+	if err != nil {
+		s.logger.LogError(err, "NotificacaoService.MarcarTodasLidas", logging.Fields{"user_id": userID})
+		return 0, err
+	}
+	return marcadas, nil
+}
@@ -0,0 +1,167 @@
+package ofertas
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/pkg/pdf"
+)
+
+const (
+	margem         = 50.0
+	larguraUtil    = pdf.A4Width - 2*margem
+	corpoTexto     = 10.0
+	corpoRotulo    = 8.0
+	formatoData    = "02/01/2006"
+	textoAusente   = "—"
+	nomePlataforma = "EQUINOID"
+)
+
+var (
+	corPrimaria = pdf.Color{R: 0.11, G: 0.27, B: 0.22}
+	corBorda    = pdf.Color{R: 0.70, G: 0.75, B: 0.73}
+	corRotulo   = pdf.Color{R: 0.40, G: 0.40, B: 0.40}
+)
+
+// titulosContrato título do instrumento por tipo de negócio
+var titulosContrato = map[models.TipoOferta]string{
+	models.TipoOfertaCompra:       "CONTRATO DE COMPRA E VENDA DE EQUINO",
+	models.TipoOfertaCobertura:    "CONTRATO DE COBERTURA",
+	models.TipoOfertaParticipacao: "CONTRATO DE PARTICIPAÇÃO EM EQUINO",
+	models.TipoOfertaSociedade:    "CONTRATO DE SOCIEDADE SOBRE EQUINO",
+	models.TipoOfertaAluguel:      "CONTRATO DE ARRENDAMENTO DE EQUINO",
+}
+
+// clausulasPadrao cláusulas comuns acrescentadas às condições negociadas
+var clausulasPadrao = []string{
+	"As partes declaram que o valor e as condições acima resultam da negociação registrada na plataforma, cujo histórico integra este contrato.",
+	"O PROPRIETÁRIO declara ser o legítimo titular do equino identificado, livre de ônus que impeçam o negócio.",
+	"O contrato produz efeitos após a assinatura eletrônica de ambas as partes; na compra e venda, a propriedade é transferida no registro da plataforma na conclusão das assinaturas.",
+}
+
+// renderContrato gera o contrato do negócio aceito para assinatura eletrônica das partes
+func renderContrato(oferta *models.Oferta, emitidoEm time.Time) []byte {
+	titulo := titulosContrato[oferta.TipoOferta]
+	doc := pdf.New(pdf.Info{
+		Title:    fmt.Sprintf("%s - oferta %d", titulo, oferta.NegociacaoID),
+		Author:   nomePlataforma,
+		Subject:  titulo,
+		Creator:  nomePlataforma,
+		Created:  emitidoEm,
+		Keywords: []string{oferta.Equinoid, fmt.Sprintf("negociacao-%d", oferta.NegociacaoID)},
+	})
+	page := doc.AddPage()
+
+	top := page.Height() - margem
+	page.FillRect(margem, top-44, larguraUtil, 44, corPrimaria)
+	page.Text(margem+12, top-20, pdf.HelveticaBold, 13, pdf.White, titulo)
+	page.Text(margem+12, top-35, pdf.Helvetica, 8, pdf.White, fmt.Sprintf("Negociação nº %d · rodada %d · emitido em %s", oferta.NegociacaoID, oferta.Rodada, emitidoEm.Format("02/01/2006 15:04")+" UTC"))
+	page.TextRight(margem+larguraUtil-12, top-20, pdf.HelveticaBold, 10, pdf.White, nomePlataforma)
+	y := top - 64
+
+	vendedor, comprador := nomeUsuario(oferta.Proprietario), nomeUsuario(oferta.Comprador)
+	y = drawCampos(page, "Partes", [][2]string{
+		{"Proprietário", vendedor},
+		{"Documento", documentoUsuario(oferta.Proprietario)},
+		{"Interessado", comprador},
+		{"Documento", documentoUsuario(oferta.Comprador)},
+	}, y)
+
+	equino := oferta.Equino
+	if equino == nil {
+		equino = &models.Equino{Equinoid: oferta.Equinoid}
+	}
+	y = drawCampos(page, "Objeto", [][2]string{
+		{"Equino", equino.Nome},
+		{"EquinoId", equino.Equinoid},
+		{"Microchip", equino.MicrochipID},
+		{"Raça", equino.Raca},
+	}, y)
+
+	prazo := textoAusente
+	if oferta.PrazoOferta != nil {
+		prazo = oferta.PrazoOferta.Format(formatoData)
+	}
+	y = drawCampos(page, "Valor", [][2]string{
+		{"Valor acordado", formatValor(oferta.ValorOferta, oferta.Moeda)},
+		{"Proposta válida até", prazo},
+	}, y)
+
+	clausulas := append([]string{}, clausulasPadrao...)
+	if condicoes := strings.TrimSpace(oferta.CondicoesOferta); condicoes != "" {
+		clausulas = append([]string{"Condições negociadas: " + condicoes}, clausulas...)
+	}
+	y = drawTitulo(page, "Cláusulas", y)
+	for i, clausula := range clausulas {
+		for j, linha := range pdf.Wrap(fmt.Sprintf("%d. %s", i+1, clausula), pdf.Helvetica, corpoTexto, larguraUtil) {
+			if j == 0 {
+				y -= 4
+			}
+			y -= corpoTexto + 4
+			page.Text(margem, y, pdf.Helvetica, corpoTexto, pdf.Black, linha)
+		}
+	}
+
+	y -= 60
+	metade := larguraUtil / 2
+	for i, assinante := range [][2]string{{"PROPRIETÁRIO", vendedor}, {"INTERESSADO", comprador}} {
+		x := margem + float64(i)*metade
+		page.Line(x+10, y, x+metade-10, y, 0.75, corBorda)
+		page.TextCentered(x+metade/2, y-12, pdf.HelveticaBold, corpoRotulo, pdf.Black, assinante[0])
+		page.TextCentered(x+metade/2, y-24, pdf.Helvetica, corpoRotulo, corRotulo, pdf.Truncate(assinante[1], pdf.Helvetica, corpoRotulo, metade-20))
+	}
+
+	return doc.Bytes()
+}
+
+func drawTitulo(page *pdf.Page, titulo string, y float64) float64 {
+	page.Text(margem, y-corpoTexto, pdf.HelveticaBold, corpoTexto+1, corPrimaria, strings.ToUpper(titulo))
+	page.Line(margem, y-corpoTexto-4, margem+larguraUtil, y-corpoTexto-4, 0.5, corBorda)
+	return y - corpoTexto - 10
+}
+
+// drawCampos desenha os pares rótulo/valor em duas colunas
+func drawCampos(page *pdf.Page, titulo string, campos [][2]string, y float64) float64 {
+	y = drawTitulo(page, titulo, y)
+	colWidth := larguraUtil / 2
+	for i, campo := range campos {
+		x := margem + float64(i%2)*colWidth
+		linha := y - float64(i/2)*26
+		valor := campo[1]
+		if valor == "" {
+			valor = textoAusente
+		}
+		page.Text(x, linha-corpoRotulo, pdf.Helvetica, corpoRotulo, corRotulo, strings.ToUpper(campo[0]))
+		page.Text(x, linha-corpoRotulo-12, pdf.HelveticaBold, corpoTexto, pdf.Black, pdf.Truncate(valor, pdf.HelveticaBold, corpoTexto, colWidth-8))
+	}
+	return y - float64((len(campos)+1)/2)*26 - 10
+}
+
+func nomeUsuario(user *models.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.Name
+}
+
+func documentoUsuario(user *models.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.CPFCNPJ
+}
+
+// formatValor valor com separador de milhar e vírgula decimal, como em 1.250.000,00
+func formatValor(valor float64, moeda string) string {
+	centavos := int64(valor*100 + 0.5)
+	inteiro := fmt.Sprint(centavos / 100)
+	var grupos []string
+	for len(inteiro) > 3 {
+		grupos = append([]string{inteiro[len(inteiro)-3:]}, grupos...)
+		inteiro = inteiro[:len(inteiro)-3]
+	}
+	grupos = append([]string{inteiro}, grupos...)
+	return fmt.Sprintf("%s %s,%02d", moeda, strings.Join(grupos, "."), centavos%100)
+}
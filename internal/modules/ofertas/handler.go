@@ -0,0 +1,364 @@
+package ofertas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateOferta godoc
+// @Summary Fazer oferta por equino
+// @Description Abre uma negociação com o proprietário. O perfil social do equino precisa aceitar ofertas e estar disponível; sem prazo informado a proposta vale 7 dias (máximo 30)
+// @Tags Ofertas
+// @Accept json
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param oferta body models.CreateOfertaRequest true "Proposta"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/ofertas [post]
+// @Security BearerAuth
+func (h *Handler) CreateOferta(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateOfertaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	oferta, err := h.service.Criar(c.Request.Context(), c.Param("equinoid"), userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar oferta")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Oferta enviada ao proprietário",
+		Timestamp: time.Now(),
+		Data:      oferta,
+	})
+}
+
+// ListOfertas godoc
+// @Summary Listar negociações
+// @Description Proposta vigente de cada negociação do usuário, recebidas como proprietário ou enviadas como interessado
+// @Tags Ofertas
+// @Produce json
+// @Param papel query string false "recebidas ou enviadas" default(recebidas)
+// @Param equinoid query string false "Filtrar por equino"
+// @Param status query string false "pendente, aceita, recusada, expirada, cancelada ou concluida"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /ofertas [get]
+// @Security BearerAuth
+func (h *Handler) ListOfertas(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := parsePagination(c)
+	papel := PapelOfertas(c.DefaultQuery("papel", string(PapelRecebidas)))
+
+	ofertas, total, err := h.service.List(c.Request.Context(), userID, papel, c.Query("equinoid"), models.StatusOferta(c.Query("status")), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar ofertas")
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Negociações (total: %d)", total),
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: ofertas,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: totalPages,
+			},
+		},
+	})
+}
+
+// GetOferta godoc
+// @Summary Detalhar negociação
+// @Description Proposta vigente e histórico de contrapropostas; visível apenas às partes
+// @Tags Ofertas
+// @Produce json
+// @Param id path int true "ID de qualquer proposta da negociação"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /ofertas/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetOferta(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	negociacao, err := h.service.Get(c.Request.Context(), id, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar oferta")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Negociação encontrada",
+		Timestamp: time.Now(),
+		Data:      negociacao,
+	})
+}
+
+// Contrapropor godoc
+// @Summary Fazer contraproposta
+// @Description Responde a proposta pendente com um novo valor; proprietário e interessado se alternam, até 10 propostas por negociação
+// @Tags Ofertas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da proposta pendente"
+// @Param contraproposta body models.ContrapropostaRequest true "Nova proposta"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /ofertas/{id}/contraproposta [post]
+// @Security BearerAuth
+func (h *Handler) Contrapropor(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.ContrapropostaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	oferta, err := h.service.Contrapropor(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar contraproposta")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Contraproposta enviada",
+		Timestamp: time.Now(),
+		Data:      oferta,
+	})
+}
+
+// AceitarOferta godoc
+// @Summary Aceitar proposta
+// @Description Fecha o negócio, recusa as ofertas concorrentes e envia o contrato para assinatura das partes. Na compra, a propriedade é transferida quando o contrato é assinado
+// @Tags Ofertas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da proposta pendente"
+// @Param resposta body models.ResponderOfertaRequest false "Mensagem à outra parte"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /ofertas/{id}/aceitar [post]
+// @Security BearerAuth
+func (h *Handler) AceitarOferta(c *gin.Context) {
+	h.responder(c, h.service.Aceitar, "Proposta aceita", "Erro ao aceitar oferta")
+}
+
+// RecusarOferta godoc
+// @Summary Recusar proposta
+// @Description Encerra a negociação sem acordo
+// @Tags Ofertas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da proposta pendente"
+// @Param resposta body models.ResponderOfertaRequest false "Mensagem à outra parte"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /ofertas/{id}/recusar [post]
+// @Security BearerAuth
+func (h *Handler) RecusarOferta(c *gin.Context) {
+	h.responder(c, h.service.Recusar, "Proposta recusada", "Erro ao recusar oferta")
+}
+
+// CancelarOferta godoc
+// @Summary Cancelar proposta
+// @Description O autor retira a própria proposta enquanto ela estiver pendente
+// @Tags Ofertas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da proposta pendente"
+// @Param resposta body models.ResponderOfertaRequest false "Mensagem à outra parte"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /ofertas/{id}/cancelar [post]
+// @Security BearerAuth
+func (h *Handler) CancelarOferta(c *gin.Context) {
+	h.responder(c, h.service.Cancelar, "Proposta cancelada", "Erro ao cancelar oferta")
+}
+
+type acaoResposta func(ctx context.Context, id uint, userID uint, req *models.ResponderOfertaRequest) (*models.Oferta, error)
+
+// responder aceita, recusa ou cancela; a mensagem é opcional e o corpo pode ser omitido
+func (h *Handler) responder(c *gin.Context, acao acaoResposta, sucesso, fallback string) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.ResponderOfertaRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.badRequest(c, err)
+		return
+	}
+
+	oferta, err := acao(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		h.respondError(c, err, fallback)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   sucesso,
+		Timestamp: time.Now(),
+		Data:      oferta,
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, string, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, "", false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	return userID, userType, true
+}
+
+func (h *Handler) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *Handler) badRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Success:   false,
+		Error:     "Dados inválidos: " + err.Error(),
+		Timestamp: time.Now(),
+	})
+}
+
+func parsePagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
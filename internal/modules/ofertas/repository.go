@@ -0,0 +1,354 @@
+package ofertas
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FiltroOfertas negociações de um usuário, como comprador ou como proprietário
+type FiltroOfertas struct {
+	CompradorID    uint
+	ProprietarioID uint
+	Equinoid       string
+	Status         models.StatusOferta
+	Page           int
+	Limit          int
+}
+
+// ContratoOferta negócio aceito com o status do contrato na D4Sign
+type ContratoOferta struct {
+	Oferta          *models.Oferta
+	StatusDocumento string
+}
+
+type Repository interface {
+	Create(ctx context.Context, oferta *models.Oferta) error
+	Find(ctx context.Context, id uint) (*models.Oferta, error)
+	Update(ctx context.Context, oferta *models.Oferta) error
+	ListNegociacao(ctx context.Context, negociacaoID uint) ([]*models.Oferta, error)
+	List(ctx context.Context, filtro FiltroOfertas) ([]*models.Oferta, int64, error)
+	FindPendenteComprador(ctx context.Context, equinoid string, compradorID uint, tipo models.TipoOferta) (*models.Oferta, error)
+	FindCompraAceita(ctx context.Context, equinoid string) (*models.Oferta, error)
+
+	Contrapropor(ctx context.Context, anterior *models.Oferta, resposta string, nova *models.Oferta) (bool, error)
+	Aceitar(ctx context.Context, oferta *models.Oferta, resposta, motivoConcorrentes string) (bool, []*models.Oferta, error)
+	AlterarStatus(ctx context.Context, id uint, de, para models.StatusOferta, resposta string) (bool, error)
+	Concluir(ctx context.Context, id uint) (bool, error)
+
+	ListVencidas(ctx context.Context, agora time.Time, limit int) ([]*models.Oferta, error)
+	ListAceitasSemContrato(ctx context.Context, limit int) ([]*models.Oferta, error)
+	ListContratosFinalizados(ctx context.Context, limit int) ([]*ContratoOferta, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create grava a oferta; a primeira proposta abre a negociação com o próprio ID
+func (r *repository) Create(ctx context.Context, oferta *models.Oferta) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(oferta).Error; err != nil {
+			return err
+		}
+		if oferta.NegociacaoID != 0 {
+			return nil
+		}
+		oferta.NegociacaoID = oferta.ID
+		return tx.Model(&models.Oferta{}).Where("id = ?", oferta.ID).Update("negociacao_id", oferta.ID).Error
+	})
+	if err != nil {
+		return apperrors.NewDatabaseError("create_oferta", "erro ao registrar oferta", err)
+	}
+	return nil
+}
+
+func (r *repository) Find(ctx context.Context, id uint) (*models.Oferta, error) {
+	var oferta models.Oferta
+	err := r.db.WithContext(ctx).
+		Preload("Equino").Preload("Comprador").Preload("Proprietario").
+		Where("id = ?", id).First(&oferta).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "oferta", Message: "oferta não encontrada", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_oferta", "erro ao buscar oferta", err)
+	}
+	return &oferta, nil
+}
+
+func (r *repository) Update(ctx context.Context, oferta *models.Oferta) error {
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Save(oferta).Error; err != nil {
+		return apperrors.NewDatabaseError("update_oferta", "erro ao atualizar oferta", err)
+	}
+	return nil
+}
+
+func (r *repository) ListNegociacao(ctx context.Context, negociacaoID uint) ([]*models.Oferta, error) {
+	var ofertas []*models.Oferta
+	err := r.db.WithContext(ctx).
+		Where("negociacao_id = ?", negociacaoID).
+		Order("rodada ASC, id ASC").
+		Find(&ofertas).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("list_negociacao", "erro ao carregar negociação", err)
+	}
+	return ofertas, nil
+}
+
+// List devolve a proposta mais recente de cada negociação; as substituídas por contraproposta ficam no histórico
+func (r *repository) List(ctx context.Context, filtro FiltroOfertas) ([]*models.Oferta, int64, error) {
+	var ofertas []*models.Oferta
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Oferta{}).Where("status_oferta <> ?", models.StatusOfertaContraproposta)
+	if filtro.CompradorID != 0 {
+		query = query.Where("comprador_id = ?", filtro.CompradorID)
+	}
+	if filtro.ProprietarioID != 0 {
+		query = query.Where("proprietario_id = ?", filtro.ProprietarioID)
+	}
+	if filtro.Equinoid != "" {
+		query = query.Where("equinoid = ?", filtro.Equinoid)
+	}
+	if filtro.Status != "" {
+		query = query.Where("status_oferta = ?", filtro.Status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_ofertas", "erro ao contar ofertas", err)
+	}
+
+	offset := (filtro.Page - 1) * filtro.Limit
+	err := query.Preload("Equino").
+		Offset(offset).Limit(filtro.Limit).
+		Order("updated_at DESC, id DESC").
+		Find(&ofertas).Error
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_ofertas", "erro ao listar ofertas", err)
+	}
+	return ofertas, total, nil
+}
+
+func (r *repository) FindPendenteComprador(ctx context.Context, equinoid string, compradorID uint, tipo models.TipoOferta) (*models.Oferta, error) {
+	var oferta models.Oferta
+	err := r.db.WithContext(ctx).
+		Where("equinoid = ? AND comprador_id = ? AND tipo_oferta = ? AND status_oferta = ?", equinoid, compradorID, tipo, models.StatusOfertaPendente).
+		Limit(1).Find(&oferta).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("find_oferta_pendente", "erro ao buscar oferta pendente", err)
+	}
+	if oferta.ID == 0 {
+		return nil, nil
+	}
+	return &oferta, nil
+}
+
+// FindCompraAceita venda do equino já acertada e ainda sem contrato concluído
+func (r *repository) FindCompraAceita(ctx context.Context, equinoid string) (*models.Oferta, error) {
+	var oferta models.Oferta
+	err := r.db.WithContext(ctx).
+		Where("equinoid = ? AND tipo_oferta = ? AND status_oferta = ?", equinoid, models.TipoOfertaCompra, models.StatusOfertaAceita).
+		Limit(1).Find(&oferta).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("find_compra_aceita", "erro ao buscar venda em andamento", err)
+	}
+	if oferta.ID == 0 {
+		return nil, nil
+	}
+	return &oferta, nil
+}
+
+// Contrapropor substitui a proposta pendente pela nova na mesma transação; devolve false se a anterior já foi
+// respondida por outra requisição
+func (r *repository) Contrapropor(ctx context.Context, anterior *models.Oferta, resposta string, nova *models.Oferta) (bool, error) {
+	aplicado := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Oferta{}).
+			Where("id = ? AND status_oferta = ?", anterior.ID, models.StatusOfertaPendente).
+			Updates(map[string]interface{}{
+				"status_oferta":         models.StatusOfertaContraproposta,
+				"resposta_proprietario": resposta,
+				"data_resposta":         time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Omit(clause.Associations).Create(nova).Error; err != nil {
+			return err
+		}
+		aplicado = true
+		return nil
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("contrapropor_oferta", "erro ao registrar contraproposta", err)
+	}
+	return aplicado, nil
+}
+
+// Aceitar fecha a negociação e recusa as concorrentes pendentes do mesmo equino: todas, numa venda, ou as do mesmo
+// tipo nos demais negócios. Devolve as ofertas recusadas para avisar os interessados
+func (r *repository) Aceitar(ctx context.Context, oferta *models.Oferta, resposta, motivoConcorrentes string) (bool, []*models.Oferta, error) {
+	var recusadas []*models.Oferta
+	aplicado := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		agora := time.Now()
+		result := tx.Model(&models.Oferta{}).
+			Where("id = ? AND status_oferta = ?", oferta.ID, models.StatusOfertaPendente).
+			Updates(map[string]interface{}{
+				"status_oferta":         models.StatusOfertaAceita,
+				"resposta_proprietario": resposta,
+				"data_resposta":         agora,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		aplicado = true
+
+		concorrentes := tx.Where("equinoid = ? AND status_oferta = ? AND negociacao_id <> ?", oferta.Equinoid, models.StatusOfertaPendente, oferta.NegociacaoID)
+		if oferta.TipoOferta != models.TipoOfertaCompra {
+			concorrentes = concorrentes.Where("tipo_oferta = ?", oferta.TipoOferta)
+		}
+		if err := concorrentes.Find(&recusadas).Error; err != nil {
+			return err
+		}
+		if len(recusadas) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(recusadas))
+		for i, recusada := range recusadas {
+			ids[i] = recusada.ID
+			recusada.StatusOferta = models.StatusOfertaRecusada
+			recusada.RespostaProprietario = motivoConcorrentes
+			recusada.DataResposta = &agora
+		}
+		return tx.Model(&models.Oferta{}).
+			Where("id IN ? AND status_oferta = ?", ids, models.StatusOfertaPendente).
+			Updates(map[string]interface{}{
+				"status_oferta":         models.StatusOfertaRecusada,
+				"resposta_proprietario": motivoConcorrentes,
+				"data_resposta":         agora,
+			}).Error
+	})
+	if err != nil {
+		return false, nil, apperrors.NewDatabaseError("aceitar_oferta", "erro ao aceitar oferta", err)
+	}
+	return aplicado, recusadas, nil
+}
+
+// AlterarStatus transição condicionada ao status atual; devolve false quando outra requisição mudou a oferta antes
+func (r *repository) AlterarStatus(ctx context.Context, id uint, de, para models.StatusOferta, resposta string) (bool, error) {
+	updates := map[string]interface{}{
+		"status_oferta": para,
+		"data_resposta": time.Now(),
+	}
+	if resposta != "" {
+		updates["resposta_proprietario"] = resposta
+	}
+
+	result := r.db.WithContext(ctx).Model(&models.Oferta{}).
+		Where("id = ? AND status_oferta = ?", id, de).
+		Updates(updates)
+	if result.Error != nil {
+		return false, apperrors.NewDatabaseError("alterar_status_oferta", "erro ao atualizar oferta", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) Concluir(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Oferta{}).
+		Where("id = ? AND status_oferta = ?", id, models.StatusOfertaAceita).
+		Updates(map[string]interface{}{
+			"status_oferta": models.StatusOfertaConcluida,
+			"concluida_em":  time.Now(),
+		})
+	if result.Error != nil {
+		return false, apperrors.NewDatabaseError("concluir_oferta", "erro ao concluir negociação", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) ListVencidas(ctx context.Context, agora time.Time, limit int) ([]*models.Oferta, error) {
+	var ofertas []*models.Oferta
+	err := r.db.WithContext(ctx).
+		Preload("Equino").
+		Where("status_oferta = ? AND prazo_oferta < ?", models.StatusOfertaPendente, agora).
+		Order("prazo_oferta ASC").Limit(limit).
+		Find(&ofertas).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("list_ofertas_vencidas", "erro ao listar ofertas vencidas", err)
+	}
+	return ofertas, nil
+}
+
+func (r *repository) ListAceitasSemContrato(ctx context.Context, limit int) ([]*models.Oferta, error) {
+	var ofertas []*models.Oferta
+	err := r.db.WithContext(ctx).
+		Preload("Equino").Preload("Comprador").Preload("Proprietario").
+		Where("status_oferta = ? AND (contrato_uuid IS NULL OR contrato_uuid = '')", models.StatusOfertaAceita).
+		Order("data_resposta ASC").Limit(limit).
+		Find(&ofertas).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("list_ofertas_sem_contrato", "erro ao listar negócios sem contrato", err)
+	}
+	return ofertas, nil
+}
+
+// ListContratosFinalizados negócios aceitos cujo contrato foi assinado, cancelado ou expirou na D4Sign
+func (r *repository) ListContratosFinalizados(ctx context.Context, limit int) ([]*ContratoOferta, error) {
+	var ofertas []*models.Oferta
+	err := r.db.WithContext(ctx).
+		Preload("Equino").
+		Where("status_oferta = ? AND contrato_uuid <> ''", models.StatusOfertaAceita).
+		Order("data_resposta ASC").
+		Find(&ofertas).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("list_contratos_finalizados", "erro ao listar negócios com contrato", err)
+	}
+	if len(ofertas) == 0 {
+		return nil, nil
+	}
+
+	uuids := make([]string, len(ofertas))
+	for i, oferta := range ofertas {
+		uuids[i] = oferta.ContratoUUID
+	}
+	var documentos []models.D4SignDocument
+	err = r.db.WithContext(ctx).Model(&models.D4SignDocument{}).
+		Select("document_uuid", "status").
+		Where("document_uuid IN ? AND status IN ?", uuids, []string{models.D4SignStatusSigned, models.D4SignStatusCancelled, models.D4SignStatusExpired}).
+		Find(&documentos).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("list_contratos_finalizados", "erro ao consultar contratos na D4Sign", err)
+	}
+	status := make(map[string]string, len(documentos))
+	for _, documento := range documentos {
+		status[documento.DocumentUUID] = documento.Status
+	}
+
+	var contratos []*ContratoOferta
+	for _, oferta := range ofertas {
+		if len(contratos) == limit {
+			break
+		}
+		if s, ok := status[oferta.ContratoUUID]; ok {
+			contratos = append(contratos, &ContratoOferta{Oferta: oferta, StatusDocumento: s})
+		}
+	}
+	return contratos, nil
+}
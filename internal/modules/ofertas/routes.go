@@ -0,0 +1,24 @@
+package ofertas
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	equinos := rg.Group("/equinos")
+	equinos.Use(authMiddleware)
+	{
+		equinos.POST("/:equinoid/ofertas", handler.CreateOferta)
+	}
+
+	ofertas := rg.Group("/ofertas")
+	ofertas.Use(authMiddleware)
+	{
+		ofertas.GET("", handler.ListOfertas)
+		ofertas.GET("/:id", handler.GetOferta)
+		ofertas.POST("/:id/contraproposta", handler.Contrapropor)
+		ofertas.POST("/:id/aceitar", handler.AceitarOferta)
+		ofertas.POST("/:id/recusar", handler.RecusarOferta)
+		ofertas.POST("/:id/cancelar", handler.CancelarOferta)
+	}
+}
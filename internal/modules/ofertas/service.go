@@ -0,0 +1,710 @@
+package ofertas
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	"github.com/equinoid/backend/internal/modules/social"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+const (
+	// prazoPadrao validade de uma proposta sem prazo informado
+	prazoPadrao = 7 * 24 * time.Hour
+	// prazoMaximo validade máxima de uma proposta
+	prazoMaximo = 30 * 24 * time.Hour
+	// maxRodadas propostas por negociação, contando a oferta inicial
+	maxRodadas = 10
+	// loteProcessamento ofertas tratadas por execução das rotinas de expiração e contratos
+	loteProcessamento = 100
+
+	motivoConcorrenteVenda = "O proprietário aceitou outra proposta de compra para este equino."
+	motivoConcorrente      = "O proprietário aceitou outra proposta deste tipo para o equino."
+	motivoNovoProprietario = "O equino mudou de proprietário durante a negociação."
+)
+
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+// Notificador entrega avisos às partes da negociação
+type Notificador interface {
+	Notificar(ctx context.Context, userIDs []uint, notificacao models.NovaNotificacao) error
+}
+
+// Transferidor transfere a propriedade do equino vendido
+type Transferidor interface {
+	TransferOwnership(ctx context.Context, equinoidID string, newOwnerID uint) error
+}
+
+// GeradorContrato envia o contrato do negócio aceito para assinatura das partes e devolve o UUID do documento
+type GeradorContrato interface {
+	RegisterDocument(ctx context.Context, createdBy uint, req models.CreateD4SignDocumentRequest) (string, error)
+}
+
+// Negociacao proposta vigente com o histórico de contrapropostas, da oferta inicial à mais recente
+type Negociacao struct {
+	Atual     *models.Oferta   `json:"atual"`
+	Historico []*models.Oferta `json:"historico"`
+}
+
+// PapelOfertas lado do usuário nas negociações listadas
+type PapelOfertas string
+
+const (
+	PapelRecebidas PapelOfertas = "recebidas"
+	PapelEnviadas  PapelOfertas = "enviadas"
+)
+
+type Service interface {
+	Criar(ctx context.Context, equinoid string, userID uint, req *models.CreateOfertaRequest) (*models.Oferta, error)
+	Get(ctx context.Context, id uint, userID uint, userType string) (*Negociacao, error)
+	List(ctx context.Context, userID uint, papel PapelOfertas, equinoid string, status models.StatusOferta, page, limit int) ([]*models.Oferta, int64, error)
+
+	Contrapropor(ctx context.Context, id uint, userID uint, req *models.ContrapropostaRequest) (*models.Oferta, error)
+	Aceitar(ctx context.Context, id uint, userID uint, req *models.ResponderOfertaRequest) (*models.Oferta, error)
+	Recusar(ctx context.Context, id uint, userID uint, req *models.ResponderOfertaRequest) (*models.Oferta, error)
+	Cancelar(ctx context.Context, id uint, userID uint, req *models.ResponderOfertaRequest) (*models.Oferta, error)
+
+	ExpirarOfertas(ctx context.Context) (int, error)
+	ProcessarContratos(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo         Repository
+	equinoRepo   equinos.Repository
+	socialRepo   social.Repository
+	transferidor Transferidor
+	contratos    GeradorContrato
+	notificador  Notificador
+	audit        AuditLogger
+	logger       *logging.Logger
+}
+
+func NewService(repo Repository, equinoRepo equinos.Repository, socialRepo social.Repository, transferidor Transferidor, contratos GeradorContrato, notificador Notificador, audit AuditLogger, logger *logging.Logger) Service {
+	return &service{
+		repo:         repo,
+		equinoRepo:   equinoRepo,
+		socialRepo:   socialRepo,
+		transferidor: transferidor,
+		contratos:    contratos,
+		notificador:  notificador,
+		audit:        audit,
+		logger:       logger,
+	}
+}
+
+// Criar abre uma negociação com o proprietário do equino. O perfil social do equino precisa aceitar ofertas e estar
+// disponível; cada interessado mantém uma única negociação pendente por equino e tipo de negócio
+func (s *service) Criar(ctx context.Context, equinoid string, userID uint, req *models.CreateOfertaRequest) (*models.Oferta, error) {
+	if !models.IsValidTipoOferta(req.TipoOferta) {
+		return nil, &apperrors.ValidationError{Field: "tipo_oferta", Message: "tipo de oferta inválido", Value: req.TipoOferta}
+	}
+	moeda, err := normalizarMoeda(req.Moeda)
+	if err != nil {
+		return nil, err
+	}
+	prazo, err := validarPrazo(req.PrazoOferta)
+	if err != nil {
+		return nil, err
+	}
+
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if equino.ProprietarioID == userID {
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "não é possível fazer oferta pelo próprio equino", Value: equinoid}
+	}
+	if err := s.checkAceitaOfertas(ctx, equino.Equinoid); err != nil {
+		return nil, err
+	}
+
+	pendente, err := s.repo.FindPendenteComprador(ctx, equino.Equinoid, userID, req.TipoOferta)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.Criar", logging.Fields{"equinoid": equinoid, "user_id": userID})
+		return nil, err
+	}
+	if pendente != nil {
+		return nil, &apperrors.ConflictError{Resource: "oferta", Message: "já existe uma negociação pendente sua deste tipo para o equino", Value: pendente.NegociacaoID}
+	}
+	if req.TipoOferta == models.TipoOfertaCompra {
+		if err := s.checkSemVendaEmAndamento(ctx, equino.Equinoid); err != nil {
+			return nil, err
+		}
+	}
+
+	oferta := &models.Oferta{
+		Equinoid:        equino.Equinoid,
+		Rodada:          1,
+		CompradorID:     userID,
+		ProprietarioID:  equino.ProprietarioID,
+		OfertantePorID:  userID,
+		TipoOferta:      req.TipoOferta,
+		ValorOferta:     req.ValorOferta,
+		Moeda:           moeda,
+		CondicoesOferta: strings.TrimSpace(req.CondicoesOferta),
+		PrazoOferta:     prazo,
+		StatusOferta:    models.StatusOfertaPendente,
+	}
+	if err := s.repo.Create(ctx, oferta); err != nil {
+		s.logger.LogError(err, "OfertaService.Criar", logging.Fields{"equinoid": equinoid, "user_id": userID})
+		return nil, err
+	}
+
+	s.recordChange(ctx, oferta, "create", nil, oferta)
+	s.notificar(ctx, []uint{oferta.ProprietarioID}, models.NotificacaoOfertaRecebida, "Nova oferta recebida",
+		fmt.Sprintf("Você recebeu uma oferta de %s (%s) por %s.", formatValor(oferta.ValorOferta, oferta.Moeda), oferta.TipoOferta, nomeEquino(equino)), oferta)
+	s.logger.LogBusinessEvent("oferta_criada", "Oferta registrada", userID, equino.Equinoid, logging.Fields{
+		"oferta_id": oferta.ID,
+		"tipo":      oferta.TipoOferta,
+		"valor":     oferta.ValorOferta,
+	})
+	return oferta, nil
+}
+
+// Get devolve a proposta vigente e o histórico da negociação às partes e aos administradores
+func (s *service) Get(ctx context.Context, id uint, userID uint, userType string) (*Negociacao, error) {
+	oferta, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isParte(oferta, userID) && userType != string(models.UserTypeAdmin) {
+		return nil, &apperrors.NotFoundError{Resource: "oferta", Message: "oferta não encontrada", ID: id}
+	}
+
+	historico, err := s.repo.ListNegociacao(ctx, oferta.NegociacaoID)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.Get", logging.Fields{"oferta_id": id})
+		return nil, err
+	}
+
+	if len(historico) == 0 {
+		historico = []*models.Oferta{oferta}
+	}
+
+	atual := oferta
+	if ultima := historico[len(historico)-1]; ultima.ID != oferta.ID {
+		if atual, err = s.repo.Find(ctx, ultima.ID); err != nil {
+			return nil, err
+		}
+	}
+	return &Negociacao{Atual: atual, Historico: historico}, nil
+}
+
+func (s *service) List(ctx context.Context, userID uint, papel PapelOfertas, equinoid string, status models.StatusOferta, page, limit int) ([]*models.Oferta, int64, error) {
+	filtro := FiltroOfertas{Equinoid: equinoid, Status: status, Page: page, Limit: limit}
+	switch papel {
+	case PapelRecebidas:
+		filtro.ProprietarioID = userID
+	case PapelEnviadas:
+		filtro.CompradorID = userID
+	default:
+		return nil, 0, &apperrors.ValidationError{Field: "papel", Message: "papel deve ser recebidas ou enviadas", Value: papel}
+	}
+
+	ofertas, total, err := s.repo.List(ctx, filtro)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.List", logging.Fields{"user_id": userID, "papel": papel})
+		return nil, 0, err
+	}
+	return ofertas, total, nil
+}
+
+// Contrapropor responde a proposta pendente com um novo valor; as partes se alternam até alguém aceitar ou recusar
+func (s *service) Contrapropor(ctx context.Context, id uint, userID uint, req *models.ContrapropostaRequest) (*models.Oferta, error) {
+	anterior, err := s.findParaResponder(ctx, id, userID, "contrapropor")
+	if err != nil {
+		return nil, err
+	}
+	if anterior.Rodada >= maxRodadas {
+		return nil, &apperrors.ValidationError{Field: "rodada", Message: fmt.Sprintf("a negociação atingiu o limite de %d propostas; aceite ou recuse a atual", maxRodadas)}
+	}
+	prazo, err := validarPrazo(req.PrazoOferta)
+	if err != nil {
+		return nil, err
+	}
+
+	condicoes := strings.TrimSpace(req.CondicoesOferta)
+	if condicoes == "" {
+		condicoes = anterior.CondicoesOferta
+	}
+	nova := &models.Oferta{
+		Equinoid:         anterior.Equinoid,
+		NegociacaoID:     anterior.NegociacaoID,
+		OfertaAnteriorID: &anterior.ID,
+		Rodada:           anterior.Rodada + 1,
+		CompradorID:      anterior.CompradorID,
+		ProprietarioID:   anterior.ProprietarioID,
+		OfertantePorID:   userID,
+		TipoOferta:       anterior.TipoOferta,
+		ValorOferta:      req.ValorOferta,
+		Moeda:            anterior.Moeda,
+		CondicoesOferta:  condicoes,
+		PrazoOferta:      prazo,
+		StatusOferta:     models.StatusOfertaPendente,
+	}
+
+	aplicado, err := s.repo.Contrapropor(ctx, anterior, strings.TrimSpace(req.Mensagem), nova)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.Contrapropor", logging.Fields{"oferta_id": id, "user_id": userID})
+		return nil, err
+	}
+	if !aplicado {
+		return nil, &apperrors.ConflictError{Resource: "oferta", Message: "a oferta já foi respondida", Value: id}
+	}
+
+	s.recordChange(ctx, nova, "contraproposta", anterior, nova)
+	s.notificar(ctx, []uint{nova.Contraparte()}, models.NotificacaoOfertaContraproposta, "Contraproposta recebida",
+		fmt.Sprintf("Nova proposta de %s por %s na rodada %d.", formatValor(nova.ValorOferta, nova.Moeda), nomeEquino(anterior.Equino), nova.Rodada), nova)
+	s.logger.LogBusinessEvent("oferta_contraproposta", "Contraproposta registrada", userID, nova.Equinoid, logging.Fields{
+		"negociacao_id": nova.NegociacaoID,
+		"oferta_id":     nova.ID,
+		"rodada":        nova.Rodada,
+		"valor":         nova.ValorOferta,
+	})
+	return nova, nil
+}
+
+// Aceitar fecha o negócio nos termos da proposta pendente, recusa as ofertas concorrentes e envia o contrato para
+// assinatura das partes. Na compra, a propriedade é transferida quando o contrato é assinado
+func (s *service) Aceitar(ctx context.Context, id uint, userID uint, req *models.ResponderOfertaRequest) (*models.Oferta, error) {
+	oferta, err := s.findParaResponder(ctx, id, userID, "aceitar")
+	if err != nil {
+		return nil, err
+	}
+	if oferta.TipoOferta == models.TipoOfertaCompra {
+		if err := s.checkSemVendaEmAndamento(ctx, oferta.Equinoid); err != nil {
+			return nil, err
+		}
+	}
+
+	motivo := motivoConcorrente
+	if oferta.TipoOferta == models.TipoOfertaCompra {
+		motivo = motivoConcorrenteVenda
+	}
+	aplicado, recusadas, err := s.repo.Aceitar(ctx, oferta, strings.TrimSpace(req.Mensagem), motivo)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.Aceitar", logging.Fields{"oferta_id": id, "user_id": userID})
+		return nil, err
+	}
+	if !aplicado {
+		return nil, &apperrors.ConflictError{Resource: "oferta", Message: "a oferta já foi respondida", Value: id}
+	}
+
+	before := *oferta
+	oferta.StatusOferta = models.StatusOfertaAceita
+	oferta.RespostaProprietario = strings.TrimSpace(req.Mensagem)
+	s.recordChange(ctx, oferta, "aceitar", &before, oferta)
+	s.notificar(ctx, []uint{oferta.CompradorID, oferta.ProprietarioID}, models.NotificacaoOfertaAceita, "Negócio fechado",
+		fmt.Sprintf("A proposta de %s por %s foi aceita. O contrato será enviado para assinatura das partes.", formatValor(oferta.ValorOferta, oferta.Moeda), nomeEquino(oferta.Equino)), oferta)
+	for _, recusada := range recusadas {
+		s.recordChange(ctx, recusada, "recusar_concorrente", nil, recusada)
+		s.notificar(ctx, []uint{recusada.CompradorID}, models.NotificacaoOfertaRecusada, "Oferta recusada", recusada.RespostaProprietario, recusada)
+	}
+	s.logger.LogBusinessEvent("oferta_aceita", "Oferta aceita", userID, oferta.Equinoid, logging.Fields{
+		"negociacao_id":          oferta.NegociacaoID,
+		"oferta_id":              oferta.ID,
+		"valor":                  oferta.ValorOferta,
+		"concorrentes_recusadas": len(recusadas),
+	})
+
+	// Falhas no envio do contrato ficam para a rotina de contratos, que tenta de novo
+	s.iniciarContrato(ctx, oferta)
+	return oferta, nil
+}
+
+// Recusar encerra a negociação sem acordo
+func (s *service) Recusar(ctx context.Context, id uint, userID uint, req *models.ResponderOfertaRequest) (*models.Oferta, error) {
+	oferta, err := s.findParaResponder(ctx, id, userID, "recusar")
+	if err != nil {
+		return nil, err
+	}
+	return s.encerrar(ctx, oferta, userID, models.StatusOfertaRecusada, strings.TrimSpace(req.Mensagem))
+}
+
+// Cancelar retira a proposta pendente; só o autor da proposta pode retirá-la
+func (s *service) Cancelar(ctx context.Context, id uint, userID uint, req *models.ResponderOfertaRequest) (*models.Oferta, error) {
+	oferta, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isParte(oferta, userID) {
+		return nil, &apperrors.NotFoundError{Resource: "oferta", Message: "oferta não encontrada", ID: id}
+	}
+	if oferta.OfertantePorID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o autor da proposta pode cancelá-la"}).WithAction("cancelar", "oferta")
+	}
+	if oferta.StatusOferta != models.StatusOfertaPendente {
+		return nil, &apperrors.ConflictError{Resource: "oferta", Message: "apenas propostas pendentes podem ser canceladas", Value: oferta.StatusOferta}
+	}
+	return s.encerrar(ctx, oferta, userID, models.StatusOfertaCancelada, strings.TrimSpace(req.Mensagem))
+}
+
+// ExpirarOfertas encerra as propostas pendentes com prazo vencido e avisa as duas partes
+func (s *service) ExpirarOfertas(ctx context.Context) (int, error) {
+	vencidas, err := s.repo.ListVencidas(ctx, time.Now(), loteProcessamento)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.ExpirarOfertas", nil)
+		return 0, err
+	}
+
+	expiradas := 0
+	for _, oferta := range vencidas {
+		ok, err := s.expirar(ctx, oferta)
+		if err != nil {
+			return expiradas, err
+		}
+		if ok {
+			expiradas++
+		}
+	}
+	return expiradas, nil
+}
+
+// ProcessarContratos envia os contratos pendentes de envio e aplica o resultado das assinaturas: contrato assinado
+// conclui o negócio, transferindo a propriedade na compra; cancelado ou expirado desfaz o negócio
+func (s *service) ProcessarContratos(ctx context.Context) (int, error) {
+	semContrato, err := s.repo.ListAceitasSemContrato(ctx, loteProcessamento)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.ProcessarContratos", nil)
+		return 0, err
+	}
+	processados := 0
+	for _, oferta := range semContrato {
+		if s.iniciarContrato(ctx, oferta) {
+			processados++
+		}
+	}
+
+	finalizados, err := s.repo.ListContratosFinalizados(ctx, loteProcessamento)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.ProcessarContratos", nil)
+		return processados, err
+	}
+	for _, contrato := range finalizados {
+		var aplicado bool
+		if contrato.StatusDocumento == models.D4SignStatusSigned {
+			aplicado, err = s.concluir(ctx, contrato.Oferta)
+		} else {
+			aplicado, err = s.desfazer(ctx, contrato.Oferta, fmt.Sprintf("O contrato não foi assinado (%s).", contrato.StatusDocumento))
+		}
+		if err != nil {
+			return processados, err
+		}
+		if aplicado {
+			processados++
+		}
+	}
+	return processados, nil
+}
+
+// findParaResponder proposta pendente que o usuário, como contraparte, pode aceitar, recusar ou contrapropor. Uma
+// proposta vencida é expirada na hora; se o equino mudou de dono, a negociação é cancelada
+func (s *service) findParaResponder(ctx context.Context, id uint, userID uint, acao string) (*models.Oferta, error) {
+	oferta, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isParte(oferta, userID) {
+		return nil, &apperrors.NotFoundError{Resource: "oferta", Message: "oferta não encontrada", ID: id}
+	}
+	if oferta.StatusOferta != models.StatusOfertaPendente {
+		return nil, &apperrors.ConflictError{Resource: "oferta", Message: "a oferta não está mais pendente", Value: oferta.StatusOferta}
+	}
+	if oferta.Contraparte() != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "a proposta aguarda a resposta da outra parte"}).WithAction(acao, "oferta")
+	}
+
+	if oferta.PrazoOferta != nil && oferta.PrazoOferta.Before(time.Now()) {
+		if _, err := s.expirar(ctx, oferta); err != nil {
+			return nil, err
+		}
+		return nil, &apperrors.ValidationError{Field: "prazo_oferta", Message: "o prazo da oferta expirou", Value: oferta.PrazoOferta}
+	}
+
+	if oferta.Equino == nil || oferta.Equino.ProprietarioID != oferta.ProprietarioID {
+		if _, err := s.encerrar(ctx, oferta, 0, models.StatusOfertaCancelada, motivoNovoProprietario); err != nil {
+			return nil, err
+		}
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "o equino mudou de proprietário e a negociação foi cancelada", Value: oferta.Equinoid}
+	}
+	return oferta, nil
+}
+
+// encerrar recusa ou cancela a proposta pendente e avisa a outra parte; userID zero indica encerramento automático
+func (s *service) encerrar(ctx context.Context, oferta *models.Oferta, userID uint, status models.StatusOferta, mensagem string) (*models.Oferta, error) {
+	aplicado, err := s.repo.AlterarStatus(ctx, oferta.ID, models.StatusOfertaPendente, status, mensagem)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.encerrar", logging.Fields{"oferta_id": oferta.ID, "status": status})
+		return nil, err
+	}
+	if !aplicado {
+		return nil, &apperrors.ConflictError{Resource: "oferta", Message: "a oferta já foi respondida", Value: oferta.ID}
+	}
+
+	before := *oferta
+	oferta.StatusOferta = status
+	if mensagem != "" {
+		oferta.RespostaProprietario = mensagem
+	}
+	s.recordChange(ctx, oferta, string(status), &before, oferta)
+
+	destinatarios := []uint{oferta.CompradorID, oferta.ProprietarioID}
+	if userID != 0 {
+		destinatarios = []uint{oferta.Contraparte()}
+		if userID == oferta.Contraparte() {
+			destinatarios = []uint{oferta.OfertantePorID}
+		}
+	}
+	tipo, titulo := models.NotificacaoOfertaRecusada, "Oferta recusada"
+	if status == models.StatusOfertaCancelada {
+		tipo, titulo = models.NotificacaoOfertaCancelada, "Negociação cancelada"
+	}
+	texto := fmt.Sprintf("A negociação por %s foi encerrada sem acordo.", nomeEquino(oferta.Equino))
+	if mensagem != "" {
+		texto += " " + mensagem
+	}
+	s.notificar(ctx, destinatarios, tipo, titulo, texto, oferta)
+	s.logger.LogBusinessEvent("oferta_"+string(status), "Negociação encerrada sem acordo", userID, oferta.Equinoid, logging.Fields{
+		"negociacao_id": oferta.NegociacaoID,
+		"oferta_id":     oferta.ID,
+	})
+	return oferta, nil
+}
+
+func (s *service) expirar(ctx context.Context, oferta *models.Oferta) (bool, error) {
+	aplicado, err := s.repo.AlterarStatus(ctx, oferta.ID, models.StatusOfertaPendente, models.StatusOfertaExpirada, "")
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.expirar", logging.Fields{"oferta_id": oferta.ID})
+		return false, err
+	}
+	if !aplicado {
+		return false, nil
+	}
+
+	before := *oferta
+	oferta.StatusOferta = models.StatusOfertaExpirada
+	s.recordChange(ctx, oferta, "expirar", &before, oferta)
+	s.notificar(ctx, []uint{oferta.CompradorID, oferta.ProprietarioID}, models.NotificacaoOfertaExpirada, "Oferta expirada",
+		fmt.Sprintf("A proposta de %s por %s expirou sem resposta.", formatValor(oferta.ValorOferta, oferta.Moeda), nomeEquino(oferta.Equino)), oferta)
+	return true, nil
+}
+
+// iniciarContrato gera o contrato do negócio aceito e o envia à D4Sign com o proprietário e o interessado como
+// signatários; devolve false quando o envio falhou ou não está configurado
+func (s *service) iniciarContrato(ctx context.Context, oferta *models.Oferta) bool {
+	if s.contratos == nil || oferta.ContratoUUID != "" {
+		return false
+	}
+	if oferta.Proprietario == nil || oferta.Comprador == nil {
+		s.logger.LogError(fmt.Errorf("partes da oferta %d não carregadas", oferta.ID), "OfertaService.iniciarContrato", logging.Fields{"oferta_id": oferta.ID})
+		return false
+	}
+
+	tipoDocumento, papelProprietario, papelComprador := "contrato", models.D4SignRoleSignatario, models.D4SignRoleSignatario
+	if oferta.TipoOferta == models.TipoOfertaCompra {
+		tipoDocumento, papelProprietario, papelComprador = "transferencia", models.D4SignRoleVendedor, models.D4SignRoleComprador
+	}
+	conteudo := renderContrato(oferta, time.Now().UTC())
+	uuid, err := s.contratos.RegisterDocument(ctx, oferta.ProprietarioID, models.CreateD4SignDocumentRequest{
+		Base64File:        base64.StdEncoding.EncodeToString(conteudo),
+		Name:              fmt.Sprintf("contrato-%s-negociacao-%d.pdf", oferta.Equinoid, oferta.NegociacaoID),
+		DocumentType:      tipoDocumento,
+		RelatedEntityID:   &oferta.ID,
+		RelatedEntityType: "oferta",
+		Signers: []models.D4SignSigner{
+			{Email: oferta.Proprietario.Email, Name: oferta.Proprietario.Name, Role: papelProprietario, Order: 1},
+			{Email: oferta.Comprador.Email, Name: oferta.Comprador.Name, Role: papelComprador, Order: 2},
+		},
+	})
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.iniciarContrato", logging.Fields{"oferta_id": oferta.ID})
+		return false
+	}
+
+	oferta.ContratoUUID = uuid
+	if err := s.repo.Update(ctx, oferta); err != nil {
+		s.logger.LogError(err, "OfertaService.iniciarContrato", logging.Fields{"oferta_id": oferta.ID, "document_uuid": uuid})
+		return false
+	}
+	s.logger.LogBusinessEvent("oferta_contrato_enviado", "Contrato do negócio enviado para assinatura", oferta.ProprietarioID, oferta.Equinoid, logging.Fields{
+		"oferta_id":     oferta.ID,
+		"document_uuid": uuid,
+	})
+	return true
+}
+
+// concluir aplica o contrato assinado. Na compra a propriedade passa ao comprador antes de a negociação ser marcada
+// como concluída, de forma que uma falha na transferência seja retomada na próxima execução
+func (s *service) concluir(ctx context.Context, oferta *models.Oferta) (bool, error) {
+	if oferta.TipoOferta == models.TipoOfertaCompra {
+		equino, err := s.equinoRepo.FindByEquinoid(ctx, oferta.Equinoid)
+		if err != nil {
+			s.logger.LogError(err, "OfertaService.concluir", logging.Fields{"oferta_id": oferta.ID})
+			return false, err
+		}
+		switch equino.ProprietarioID {
+		case oferta.CompradorID:
+			// Transferência já aplicada numa execução anterior
+		case oferta.ProprietarioID:
+			if s.transferidor == nil {
+				return false, nil
+			}
+			if err := s.transferidor.TransferOwnership(ctx, oferta.Equinoid, oferta.CompradorID); err != nil {
+				s.logger.LogError(err, "OfertaService.concluir", logging.Fields{"oferta_id": oferta.ID})
+				return false, err
+			}
+		default:
+			return s.desfazer(ctx, oferta, motivoNovoProprietario)
+		}
+	}
+
+	aplicado, err := s.repo.Concluir(ctx, oferta.ID)
+	if err != nil || !aplicado {
+		if err != nil {
+			s.logger.LogError(err, "OfertaService.concluir", logging.Fields{"oferta_id": oferta.ID})
+		}
+		return false, err
+	}
+
+	before := *oferta
+	oferta.StatusOferta = models.StatusOfertaConcluida
+	s.recordChange(ctx, oferta, "concluir", &before, oferta)
+	texto := fmt.Sprintf("O contrato de %s foi assinado pelas partes.", nomeEquino(oferta.Equino))
+	if oferta.TipoOferta == models.TipoOfertaCompra {
+		texto += " A propriedade do equino foi transferida ao comprador."
+	}
+	s.notificar(ctx, []uint{oferta.CompradorID, oferta.ProprietarioID}, models.NotificacaoOfertaConcluida, "Negócio concluído", texto, oferta)
+	s.logger.LogBusinessEvent("oferta_concluida", "Negócio concluído com contrato assinado", oferta.CompradorID, oferta.Equinoid, logging.Fields{
+		"negociacao_id": oferta.NegociacaoID,
+		"oferta_id":     oferta.ID,
+		"tipo":          oferta.TipoOferta,
+	})
+	return true, nil
+}
+
+// desfazer cancela o negócio aceito cujo contrato não será assinado
+func (s *service) desfazer(ctx context.Context, oferta *models.Oferta, motivo string) (bool, error) {
+	aplicado, err := s.repo.AlterarStatus(ctx, oferta.ID, models.StatusOfertaAceita, models.StatusOfertaCancelada, motivo)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.desfazer", logging.Fields{"oferta_id": oferta.ID})
+		return false, err
+	}
+	if !aplicado {
+		return false, nil
+	}
+
+	before := *oferta
+	oferta.StatusOferta = models.StatusOfertaCancelada
+	oferta.RespostaProprietario = motivo
+	s.recordChange(ctx, oferta, "desfazer", &before, oferta)
+	s.notificar(ctx, []uint{oferta.CompradorID, oferta.ProprietarioID}, models.NotificacaoOfertaCancelada, "Negócio cancelado",
+		fmt.Sprintf("O negócio por %s foi cancelado. %s", nomeEquino(oferta.Equino), motivo), oferta)
+	return true, nil
+}
+
+// checkAceitaOfertas exige perfil social com ofertas habilitadas e equino disponível
+func (s *service) checkAceitaOfertas(ctx context.Context, equinoid string) error {
+	perfil, err := s.socialRepo.FindPerfil(ctx, equinoid)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return &apperrors.ValidationError{Field: "equinoid", Message: "o equino não está recebendo ofertas", Value: equinoid}
+		}
+		s.logger.LogError(err, "OfertaService.checkAceitaOfertas", logging.Fields{"equinoid": equinoid})
+		return err
+	}
+	if !perfil.PermitirOfertas {
+		return &apperrors.ValidationError{Field: "equinoid", Message: "o proprietário não está aceitando ofertas por este equino", Value: equinoid}
+	}
+	if perfil.StatusDisponibilidade != "" && perfil.StatusDisponibilidade != models.StatusDisponivel {
+		return &apperrors.ValidationError{Field: "equinoid", Message: "o equino não está disponível para negociação", Value: perfil.StatusDisponibilidade}
+	}
+	return nil
+}
+
+func (s *service) checkSemVendaEmAndamento(ctx context.Context, equinoid string) error {
+	aceita, err := s.repo.FindCompraAceita(ctx, equinoid)
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.checkSemVendaEmAndamento", logging.Fields{"equinoid": equinoid})
+		return err
+	}
+	if aceita != nil {
+		return &apperrors.ConflictError{Resource: "oferta", Message: "o equino já tem uma venda acertada aguardando contrato", Value: aceita.NegociacaoID}
+	}
+	return nil
+}
+
+// notificar falhas na entrega de avisos não desfazem a operação
+func (s *service) notificar(ctx context.Context, destinatarios []uint, tipo models.TipoNotificacao, titulo, mensagem string, oferta *models.Oferta) {
+	if s.notificador == nil {
+		return
+	}
+	err := s.notificador.Notificar(ctx, destinatarios, models.NovaNotificacao{
+		Tipo:           tipo,
+		Titulo:         titulo,
+		Mensagem:       mensagem,
+		ReferenciaTipo: "oferta",
+		ReferenciaID:   &oferta.ID,
+	})
+	if err != nil {
+		s.logger.LogError(err, "OfertaService.notificar", logging.Fields{"oferta_id": oferta.ID, "tipo": tipo})
+	}
+}
+
+func (s *service) recordChange(ctx context.Context, oferta *models.Oferta, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, "oferta", fmt.Sprint(oferta.ID), operation, before, after); err != nil {
+		s.logger.LogError(err, "OfertaService.recordChange", logging.Fields{"oferta_id": oferta.ID, "operation": operation})
+	}
+}
+
+func isParte(oferta *models.Oferta, userID uint) bool {
+	return oferta.CompradorID == userID || oferta.ProprietarioID == userID
+}
+
+// validarPrazo prazo informado entre agora e o máximo ou, na falta dele, o prazo padrão
+func validarPrazo(prazo *time.Time) (*time.Time, error) {
+	agora := time.Now()
+	if prazo == nil {
+		padrao := agora.Add(prazoPadrao)
+		return &padrao, nil
+	}
+	if !prazo.After(agora) {
+		return nil, &apperrors.ValidationError{Field: "prazo_oferta", Message: "o prazo deve ser uma data futura", Value: prazo}
+	}
+	if prazo.After(agora.Add(prazoMaximo)) {
+		return nil, &apperrors.ValidationError{Field: "prazo_oferta", Message: "o prazo não pode passar de 30 dias", Value: prazo}
+	}
+	return prazo, nil
+}
+
+func normalizarMoeda(moeda string) (string, error) {
+	moeda = strings.ToUpper(strings.TrimSpace(moeda))
+	if moeda == "" {
+		return "BRL", nil
+	}
+	if len(moeda) != 3 {
+		return "", &apperrors.ValidationError{Field: "moeda", Message: "moeda deve ser um código ISO 4217", Value: moeda}
+	}
+	return moeda, nil
+}
+
+func nomeEquino(equino *models.Equino) string {
+	if equino == nil {
+		return "o equino"
+	}
+	if equino.Nome == "" {
+		return equino.Equinoid
+	}
+	return fmt.Sprintf("%s (%s)", equino.Nome, equino.Equinoid)
+}
@@ -0,0 +1,467 @@
+package pagamentos
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookSize tamanho máximo aceito para o corpo dos webhooks do provedor
+const maxWebhookSize = 1 << 20
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// PagarLeilao godoc
+// @Summary Pagar lote arrematado
+// @Description Gera a cobrança (PIX, boleto ou cartão) do lote vendido ao usuário. O valor fica em custódia até a transferência do equino e então é dividido entre vendedor, comissão do leiloeiro e taxa da plataforma
+// @Tags Pagamentos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da participação no leilão"
+// @Param pagamento body models.CheckoutPagamentoRequest true "Meio de pagamento"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /leiloes/participacoes/{id}/pagamento [post]
+// @Security BearerAuth
+func (h *Handler) PagarLeilao(c *gin.Context) {
+	h.checkout(c, h.service.PagarLeilao)
+}
+
+// PagarAnuncio godoc
+// @Summary Pagar compra do marketplace
+// @Description Gera a cobrança (PIX, boleto ou cartão) do anúncio vendido ao usuário. Animais ficam em custódia até a transferência de propriedade; os demais itens até o comprador confirmar o recebimento
+// @Tags Pagamentos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do anúncio"
+// @Param pagamento body models.CheckoutPagamentoRequest true "Meio de pagamento"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /marketplace/anuncios/{id}/pagamento [post]
+// @Security BearerAuth
+func (h *Handler) PagarAnuncio(c *gin.Context) {
+	h.checkout(c, h.service.PagarAnuncio)
+}
+
+func (h *Handler) checkout(c *gin.Context, pagar func(ctx context.Context, id uint, userID uint, req *models.CheckoutPagamentoRequest) (*models.Pagamento, error)) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.CheckoutPagamentoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	pagamento, err := pagar(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao gerar cobrança")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Cobrança gerada",
+		Timestamp: time.Now(),
+		Data:      pagamento,
+	})
+}
+
+// ListPagamentos godoc
+// @Summary Listar pagamentos
+// @Description Pagamentos em que o usuário é comprador ou vendedor, com os repasses
+// @Tags Pagamentos
+// @Produce json
+// @Param papel query string false "comprador ou vendedor; vazio traz os dois"
+// @Param status query string false "aguardando_pagamento, em_custodia, em_liberacao, liberado, estornado, expirado ou recusado"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /pagamentos [get]
+// @Security BearerAuth
+func (h *Handler) ListPagamentos(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := parsePagination(c)
+
+	pagamentos, total, err := h.service.List(c.Request.Context(), userID, PapelPagamento(c.Query("papel")), models.SituacaoPagamento(c.Query("status")), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar pagamentos")
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Pagamentos (total: %d)", total),
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: pagamentos,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: totalPages,
+			},
+		},
+	})
+}
+
+// GetPagamento godoc
+// @Summary Detalhar pagamento
+// @Description Situação da cobrança, da custódia e dos repasses; visível ao comprador, ao vendedor e a administradores
+// @Tags Pagamentos
+// @Produce json
+// @Param id path int true "ID do pagamento"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /pagamentos/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetPagamento(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	pagamento, err := h.service.Get(c.Request.Context(), id, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar pagamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Pagamento encontrado",
+		Timestamp: time.Now(),
+		Data:      pagamento,
+	})
+}
+
+// LiberarPagamento godoc
+// @Summary Liberar pagamento em custódia
+// @Description O comprador confirma o recebimento e o valor é repassado ao vendedor, ao leiloeiro e à plataforma. Para animais, a transferência de propriedade ao comprador precisa ter sido feita; administradores liberam sem essa exigência
+// @Tags Pagamentos
+// @Produce json
+// @Param id path int true "ID do pagamento"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /pagamentos/{id}/liberar [post]
+// @Security BearerAuth
+func (h *Handler) LiberarPagamento(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	pagamento, err := h.service.Liberar(c.Request.Context(), id, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao liberar pagamento")
+		return
+	}
+
+	message := "Pagamento liberado"
+	if pagamento.Status == models.PagamentoEmLiberacao {
+		message = "Liberação iniciada; repasses pendentes serão reenviados"
+	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data:      pagamento,
+	})
+}
+
+// EstornarPagamento godoc
+// @Summary Estornar pagamento
+// @Description Devolve ao comprador um pagamento ainda em custódia. Disponível ao vendedor e a administradores; depois da liberação o valor já foi repassado
+// @Tags Pagamentos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do pagamento"
+// @Param estorno body models.EstornarPagamentoRequest true "Motivo"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /pagamentos/{id}/estornar [post]
+// @Security BearerAuth
+func (h *Handler) EstornarPagamento(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.EstornarPagamentoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	pagamento, err := h.service.Estornar(c.Request.Context(), id, userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao estornar pagamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Pagamento estornado",
+		Timestamp: time.Now(),
+		Data:      pagamento,
+	})
+}
+
+// SimularPagamento godoc
+// @Summary Simular pagamento (provedor local)
+// @Description Confirma a cobrança PIX ou boleto no provedor local de testes como se o comprador tivesse pago. Restrito a administradores
+// @Tags Pagamentos
+// @Produce json
+// @Param id path int true "ID do pagamento"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /pagamentos/{id}/simular [post]
+// @Security BearerAuth
+func (h *Handler) SimularPagamento(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	pagamento, err := h.service.SimularPagamento(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Erro ao simular pagamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Pagamento simulado",
+		Timestamp: time.Now(),
+		Data:      pagamento,
+	})
+}
+
+// GetConciliacao godoc
+// @Summary Conciliação financeira dos pagamentos
+// @Description Totais recebidos, em custódia, repassados e estornados no período e lançamentos financeiros ausentes ou divergentes. Com corrigir=true os lançamentos são regravados. Restrito a administradores
+// @Tags Pagamentos
+// @Produce json
+// @Param de query string false "Início (AAAA-MM-DD); padrão: primeiro dia do mês"
+// @Param ate query string false "Fim, inclusive (AAAA-MM-DD); padrão: hoje"
+// @Param corrigir query bool false "Regravar lançamentos divergentes"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /pagamentos/conciliacao [get]
+// @Security BearerAuth
+func (h *Handler) GetConciliacao(c *gin.Context) {
+	agora := time.Now()
+	de := time.Date(agora.Year(), agora.Month(), 1, 0, 0, 0, 0, agora.Location())
+	ate := time.Date(agora.Year(), agora.Month(), agora.Day(), 0, 0, 0, 0, agora.Location())
+
+	var err error
+	if v := c.Query("de"); v != "" {
+		if de, err = time.ParseInLocation("2006-01-02", v, agora.Location()); err != nil {
+			h.badRequest(c, fmt.Errorf("de deve estar no formato AAAA-MM-DD"))
+			return
+		}
+	}
+	if v := c.Query("ate"); v != "" {
+		if ate, err = time.ParseInLocation("2006-01-02", v, agora.Location()); err != nil {
+			h.badRequest(c, fmt.Errorf("ate deve estar no formato AAAA-MM-DD"))
+			return
+		}
+	}
+	corrigir, _ := strconv.ParseBool(c.DefaultQuery("corrigir", "false"))
+
+	resumo, err := h.service.Conciliacao(c.Request.Context(), de, ate.AddDate(0, 0, 1), corrigir)
+	if err != nil {
+		h.respondError(c, err, "Erro ao conciliar pagamentos")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Conciliação: %d divergência(s)", len(resumo.Divergencias)),
+		Timestamp: time.Now(),
+		Data:      resumo,
+	})
+}
+
+// Webhook godoc
+// @Summary Webhook do provedor de pagamentos
+// @Description Recebe as mudanças de situação das cobranças. Autenticado pela assinatura HMAC do provedor, sem token de usuário
+// @Tags Pagamentos
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /webhooks/pagamentos [post]
+func (h *Handler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookSize+1))
+	if err != nil || len(body) > maxWebhookSize {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Payload inválido",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if err := h.service.ProcessarWebhook(c.Request.Context(), body, c.Request.Header); err != nil {
+		if apperrors.IsAuthorization(err) {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Success:   false,
+				Error:     "Webhook não autenticado",
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		h.respondError(c, err, "Erro ao processar webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Webhook processado",
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, string, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, "", false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	return userID, userType, true
+}
+
+func (h *Handler) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *Handler) badRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Success:   false,
+		Error:     "Dados inválidos: " + err.Error(),
+		Timestamp: time.Now(),
+	})
+}
+
+func parsePagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	case apperrors.IsBusiness(err):
+		status = http.StatusServiceUnavailable
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,243 @@
+package pagamentos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PapelPagamento lado do usuário nos pagamentos listados
+type PapelPagamento string
+
+const (
+	PapelComprador PapelPagamento = "comprador"
+	PapelVendedor  PapelPagamento = "vendedor"
+)
+
+// statusAbertos pagamentos que ainda bloqueiam uma nova cobrança da mesma venda
+var statusAbertos = []models.SituacaoPagamento{
+	models.PagamentoAguardando,
+	models.PagamentoEmCustodia,
+	models.PagamentoEmLiberacao,
+	models.PagamentoLiberado,
+}
+
+type Repository interface {
+	Create(ctx context.Context, pagamento *models.Pagamento) error
+	Find(ctx context.Context, id uint) (*models.Pagamento, error)
+	FindByCobranca(ctx context.Context, cobrancaID string) (*models.Pagamento, error)
+	FindAberto(ctx context.Context, origem models.OrigemPagamento, origemID uint) (*models.Pagamento, error)
+	List(ctx context.Context, userID uint, papel PapelPagamento, status models.SituacaoPagamento, page, limit int) ([]*models.Pagamento, int64, error)
+	ListPorStatus(ctx context.Context, status models.SituacaoPagamento, aposID uint, limit int) ([]*models.Pagamento, error)
+	ListPeriodo(ctx context.Context, de, ate time.Time) ([]*models.Pagamento, error)
+
+	AlterarStatus(ctx context.Context, id uint, de []models.SituacaoPagamento, para models.SituacaoPagamento, campos map[string]interface{}) (bool, error)
+	UpdateRepasse(ctx context.Context, repasse *models.RepassePagamento) error
+
+	FindUsuario(ctx context.Context, id uint) (*models.User, error)
+
+	SalvarLancamentos(ctx context.Context, lancamentos []*models.TransacaoFinanceira) error
+	ListLancamentos(ctx context.Context, pagamentoIDs []uint) ([]*models.TransacaoFinanceira, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create grava o pagamento com os repasses previstos na mesma transação
+func (r *repository) Create(ctx context.Context, pagamento *models.Pagamento) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(pagamento).Error; err != nil {
+			return err
+		}
+		for i := range pagamento.Repasses {
+			pagamento.Repasses[i].PagamentoID = pagamento.ID
+			if err := tx.Create(&pagamento.Repasses[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return apperrors.NewDatabaseError("create_pagamento", "erro ao registrar pagamento", err)
+	}
+	return nil
+}
+
+func (r *repository) Find(ctx context.Context, id uint) (*models.Pagamento, error) {
+	var pagamento models.Pagamento
+	err := r.db.WithContext(ctx).
+		Preload("Repasses", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("id = ?", id).First(&pagamento).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "pagamento", Message: "pagamento não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_pagamento", "erro ao buscar pagamento", err)
+	}
+	return &pagamento, nil
+}
+
+func (r *repository) FindByCobranca(ctx context.Context, cobrancaID string) (*models.Pagamento, error) {
+	var pagamento models.Pagamento
+	err := r.db.WithContext(ctx).
+		Preload("Repasses", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("cobranca_id = ?", cobrancaID).First(&pagamento).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "pagamento", Message: "pagamento não encontrado para a cobrança", ID: cobrancaID}
+		}
+		return nil, apperrors.NewDatabaseError("find_pagamento_cobranca", "erro ao buscar pagamento da cobrança", err)
+	}
+	return &pagamento, nil
+}
+
+// FindAberto pagamento em andamento ou concluído da venda; nil quando a venda ainda pode ser cobrada
+func (r *repository) FindAberto(ctx context.Context, origem models.OrigemPagamento, origemID uint) (*models.Pagamento, error) {
+	var pagamento models.Pagamento
+	err := r.db.WithContext(ctx).
+		Preload("Repasses", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("origem = ? AND origem_id = ? AND status IN ?", origem, origemID, statusAbertos).
+		First(&pagamento).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperrors.NewDatabaseError("find_pagamento_aberto", "erro ao buscar pagamentos da venda", err)
+	}
+	return &pagamento, nil
+}
+
+func (r *repository) List(ctx context.Context, userID uint, papel PapelPagamento, status models.SituacaoPagamento, page, limit int) ([]*models.Pagamento, int64, error) {
+	var pagamentos []*models.Pagamento
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Pagamento{})
+	switch papel {
+	case PapelComprador:
+		query = query.Where("comprador_id = ?", userID)
+	case PapelVendedor:
+		query = query.Where("vendedor_id = ?", userID)
+	default:
+		query = query.Where("(comprador_id = ? OR vendedor_id = ?)", userID, userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_pagamentos", "erro ao contar pagamentos", err)
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("Repasses", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Order("created_at DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&pagamentos).Error
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_pagamentos", "erro ao listar pagamentos", err)
+	}
+	return pagamentos, total, nil
+}
+
+// ListPorStatus pagamentos em um status depois do cursor aposID, para as rotinas percorrerem todos em lotes
+func (r *repository) ListPorStatus(ctx context.Context, status models.SituacaoPagamento, aposID uint, limit int) ([]*models.Pagamento, error) {
+	var pagamentos []*models.Pagamento
+	err := r.db.WithContext(ctx).
+		Preload("Repasses", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("status = ? AND id > ?", status, aposID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&pagamentos).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("list_pagamentos_status", "erro ao listar pagamentos", err)
+	}
+	return pagamentos, nil
+}
+
+// ListPeriodo pagamentos criados no período, com os repasses
+func (r *repository) ListPeriodo(ctx context.Context, de, ate time.Time) ([]*models.Pagamento, error) {
+	var pagamentos []*models.Pagamento
+	err := r.db.WithContext(ctx).
+		Preload("Repasses", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("created_at >= ? AND created_at < ?", de, ate).
+		Order("id ASC").
+		Find(&pagamentos).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("list_pagamentos_periodo", "erro ao listar pagamentos do período", err)
+	}
+	return pagamentos, nil
+}
+
+// AlterarStatus muda o status só se o pagamento ainda estiver em um dos status de origem, para que webhook, rotina e
+// usuários concorrentes não apliquem a mesma transição duas vezes
+func (r *repository) AlterarStatus(ctx context.Context, id uint, de []models.SituacaoPagamento, para models.SituacaoPagamento, campos map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": para, "updated_at": time.Now()}
+	for campo, valor := range campos {
+		updates[campo] = valor
+	}
+	result := r.db.WithContext(ctx).Model(&models.Pagamento{}).
+		Where("id = ? AND status IN ?", id, de).
+		Updates(updates)
+	if result.Error != nil {
+		return false, apperrors.NewDatabaseError("alterar_status_pagamento", "erro ao atualizar status do pagamento", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *repository) UpdateRepasse(ctx context.Context, repasse *models.RepassePagamento) error {
+	if err := r.db.WithContext(ctx).Save(repasse).Error; err != nil {
+		return apperrors.NewDatabaseError("update_repasse", "erro ao atualizar repasse", err)
+	}
+	return nil
+}
+
+func (r *repository) FindUsuario(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "user", Message: "usuário não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_usuario_pagamento", "erro ao buscar usuário", err)
+	}
+	return &user, nil
+}
+
+// SalvarLancamentos grava os lançamentos financeiros do pagamento; a referência identifica cada lançamento, então
+// repetir atualiza status e valor em vez de duplicar, restaurando lançamentos removidos
+func (r *repository) SalvarLancamentos(ctx context.Context, lancamentos []*models.TransacaoFinanceira) error {
+	if len(lancamentos) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "referencia"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "valor", "descricao", "updated_at", "deleted_at"}),
+	}).Omit(clause.Associations).Create(&lancamentos).Error
+	if err != nil {
+		return apperrors.NewDatabaseError("salvar_lancamentos_pagamento", "erro ao registrar lançamentos do pagamento", err)
+	}
+	return nil
+}
+
+func (r *repository) ListLancamentos(ctx context.Context, pagamentoIDs []uint) ([]*models.TransacaoFinanceira, error) {
+	var lancamentos []*models.TransacaoFinanceira
+	if len(pagamentoIDs) == 0 {
+		return lancamentos, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("pagamento_id IN ?", pagamentoIDs).
+		Order("id ASC").
+		Find(&lancamentos).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("list_lancamentos_pagamento", "erro ao listar lançamentos dos pagamentos", err)
+	}
+	return lancamentos, nil
+}
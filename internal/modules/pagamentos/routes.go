@@ -0,0 +1,42 @@
+package pagamentos
+
+import (
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	leiloes := rg.Group("/leiloes")
+	leiloes.Use(authMiddleware)
+	{
+		leiloes.POST("/participacoes/:id/pagamento", handler.PagarLeilao)
+	}
+
+	marketplace := rg.Group("/marketplace")
+	marketplace.Use(authMiddleware)
+	{
+		marketplace.POST("/anuncios/:id/pagamento", handler.PagarAnuncio)
+	}
+
+	pagamentos := rg.Group("/pagamentos")
+	pagamentos.Use(authMiddleware)
+	{
+		pagamentos.GET("", handler.ListPagamentos)
+		pagamentos.GET("/:id", handler.GetPagamento)
+		pagamentos.POST("/:id/liberar", handler.LiberarPagamento)
+		pagamentos.POST("/:id/estornar", handler.EstornarPagamento)
+	}
+
+	admin := rg.Group("/pagamentos")
+	admin.Use(authMiddleware, middleware.RequireAdminMiddleware())
+	{
+		admin.GET("/conciliacao", handler.GetConciliacao)
+		admin.POST("/:id/simular", handler.SimularPagamento)
+	}
+
+	// Notificações do provedor, autenticadas pela assinatura
+	webhooks := rg.Group("/webhooks")
+	{
+		webhooks.POST("/pagamentos", handler.Webhook)
+	}
+}
@@ -0,0 +1,987 @@
+package pagamentos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	"github.com/equinoid/backend/internal/modules/leiloes"
+	"github.com/equinoid/backend/internal/modules/marketplace"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/equinoid/backend/pkg/payments"
+)
+
+const (
+	// validadePix prazo para pagar o QR Code PIX
+	validadePix = time.Hour
+	// validadeBoleto vencimento do boleto
+	validadeBoleto = 3 * 24 * time.Hour
+	// lotePagamentos pagamentos lidos por consulta das rotinas de conciliação
+	lotePagamentos = 100
+
+	motivoPrazoTransferencia = "A transferência de propriedade do equino não foi concluída dentro do prazo de custódia."
+	motivoEstornoProvedor    = "Estorno registrado pelo provedor de pagamentos."
+)
+
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+// Notificador entrega avisos a compradores e vendedores
+type Notificador interface {
+	Notificar(ctx context.Context, userIDs []uint, notificacao models.NovaNotificacao) error
+}
+
+// Simulador provedores que permitem confirmar uma cobrança sem pagamento real (ambientes de desenvolvimento e testes)
+type Simulador interface {
+	Confirm(chargeID string) error
+}
+
+type Service interface {
+	PagarLeilao(ctx context.Context, participacaoID uint, userID uint, req *models.CheckoutPagamentoRequest) (*models.Pagamento, error)
+	PagarAnuncio(ctx context.Context, anuncioID uint, userID uint, req *models.CheckoutPagamentoRequest) (*models.Pagamento, error)
+	Get(ctx context.Context, id uint, userID uint, userType string) (*models.Pagamento, error)
+	List(ctx context.Context, userID uint, papel PapelPagamento, status models.SituacaoPagamento, page, limit int) ([]*models.Pagamento, int64, error)
+
+	Liberar(ctx context.Context, id uint, userID uint, userType string) (*models.Pagamento, error)
+	Estornar(ctx context.Context, id uint, userID uint, userType string, req *models.EstornarPagamentoRequest) (*models.Pagamento, error)
+	SimularPagamento(ctx context.Context, id uint) (*models.Pagamento, error)
+	ProcessarWebhook(ctx context.Context, payload []byte, header http.Header) error
+
+	Conciliacao(ctx context.Context, de, ate time.Time, corrigir bool) (*models.ResumoConciliacao, error)
+	ProcessarPagamentos(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo            Repository
+	leiloesRepo     leiloes.Repository
+	marketplaceRepo marketplace.Repository
+	equinoRepo      equinos.Repository
+	provider        payments.Provider
+	notificador     Notificador
+	audit           AuditLogger
+	taxaPlataforma  float64
+	prazoCustodia   time.Duration
+	logger          *logging.Logger
+}
+
+// NewService cria o serviço de pagamentos; sem provedor configurado as cobranças respondem como indisponíveis
+func NewService(repo Repository, leiloesRepo leiloes.Repository, marketplaceRepo marketplace.Repository, equinoRepo equinos.Repository, provider payments.Provider, notificador Notificador, audit AuditLogger, cfg *config.Config, logger *logging.Logger) Service {
+	return &service{
+		repo:            repo,
+		leiloesRepo:     leiloesRepo,
+		marketplaceRepo: marketplaceRepo,
+		equinoRepo:      equinoRepo,
+		provider:        provider,
+		notificador:     notificador,
+		audit:           audit,
+		taxaPlataforma:  cfg.PaymentPlatformFeePercent,
+		prazoCustodia:   cfg.PaymentEscrowPeriod,
+		logger:          logger,
+	}
+}
+
+// venda dados comuns às origens de um pagamento
+type venda struct {
+	origem             models.OrigemPagamento
+	origemID           uint
+	compradorID        uint
+	vendedorID         uint
+	leiloeiroID        *uint
+	equinoID           *uint
+	equinoid           *string
+	exigeTransferencia bool
+	descricao          string
+	valor              float64
+	comissao           float64
+}
+
+// PagarLeilao cobra o arrematante de um lote vendido. A comissão do leiloeiro é a registrada na venda (percentual e
+// taxa fixa do leilão) e fica retida com o restante até a transferência do equino
+func (s *service) PagarLeilao(ctx context.Context, participacaoID uint, userID uint, req *models.CheckoutPagamentoRequest) (*models.Pagamento, error) {
+	participacao, err := s.leiloesRepo.FindParticipacaoByID(ctx, participacaoID)
+	if err != nil {
+		return nil, err
+	}
+	if participacao.Status != models.StatusParticipacaoVendido || participacao.ValorVendido == nil {
+		return nil, &apperrors.ValidationError{Field: "participacao_id", Message: "o lote ainda não foi vendido", Value: participacaoID}
+	}
+	if participacao.CompradorID == nil || *participacao.CompradorID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o arrematante pode pagar o lote"}).WithAction("pagar", "participacao_leilao")
+	}
+
+	leilao := participacao.Leilao
+	if leilao == nil {
+		leilao, err = s.leiloesRepo.FindByID(ctx, participacao.LeilaoID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	comissao := leilao.CalcularComissao(*participacao.ValorVendido)
+	if participacao.ComissaoLeiloeiro != nil {
+		comissao = *participacao.ComissaoLeiloeiro
+	}
+
+	equinoID := participacao.EquinoID
+	v := &venda{
+		origem:             models.OrigemPagamentoLeilao,
+		origemID:           participacao.ID,
+		compradorID:        userID,
+		vendedorID:         participacao.CriadorID,
+		leiloeiroID:        &leilao.LeiloeiroID,
+		equinoID:           &equinoID,
+		exigeTransferencia: true,
+		descricao:          fmt.Sprintf("Lote arrematado no leilão %s", leilao.Nome),
+		valor:              *participacao.ValorVendido,
+		comissao:           comissao,
+	}
+	if participacao.Equino != nil {
+		equinoid := participacao.Equino.Equinoid
+		v.equinoid = &equinoid
+		v.descricao = fmt.Sprintf("%s: %s", v.descricao, participacao.Equino.Nome)
+	}
+	return s.cobrar(ctx, v, req)
+}
+
+// PagarAnuncio cobra o comprador registrado na venda de um anúncio do marketplace. Animais ficam em custódia até a
+// transferência de propriedade; sêmen, embriões e equipamentos até o comprador confirmar o recebimento
+func (s *service) PagarAnuncio(ctx context.Context, anuncioID uint, userID uint, req *models.CheckoutPagamentoRequest) (*models.Pagamento, error) {
+	anuncio, err := s.marketplaceRepo.Find(ctx, anuncioID)
+	if err != nil {
+		return nil, err
+	}
+	if anuncio.Status != models.StatusAnuncioVendido {
+		return nil, &apperrors.ValidationError{Field: "anuncio_id", Message: "o anúncio ainda não foi vendido", Value: anuncioID}
+	}
+	if anuncio.CompradorID == nil || *anuncio.CompradorID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o comprador registrado na venda pode pagar o anúncio"}).WithAction("pagar", "anuncio")
+	}
+
+	v := &venda{
+		origem:             models.OrigemPagamentoMarketplace,
+		origemID:           anuncio.ID,
+		compradorID:        userID,
+		vendedorID:         anuncio.UsuarioID,
+		equinoid:           anuncio.Equinoid,
+		exigeTransferencia: anuncio.Tipo == models.TipoAnuncioAnimal && anuncio.Equinoid != nil,
+		descricao:          fmt.Sprintf("Marketplace: %s", anuncio.Titulo),
+		valor:              anuncio.Preco,
+	}
+	if anuncio.Equino != nil {
+		v.equinoID = &anuncio.Equino.ID
+	}
+	return s.cobrar(ctx, v, req)
+}
+
+// cobrar registra o pagamento com a divisão prevista e gera a cobrança no provedor. Repetir o pedido com o mesmo meio
+// enquanto a cobrança está em aberto devolve a mesma cobrança
+func (s *service) cobrar(ctx context.Context, v *venda, req *models.CheckoutPagamentoRequest) (*models.Pagamento, error) {
+	if s.provider == nil {
+		return nil, apperrors.NewBusinessError("payments_unavailable", "provedor de pagamentos não configurado", nil)
+	}
+	if !models.IsValidMetodoPagamento(req.Metodo) {
+		return nil, &apperrors.ValidationError{Field: "metodo", Message: "meio de pagamento deve ser pix, boleto ou cartao", Value: req.Metodo}
+	}
+	parcelas := 1
+	if req.Metodo == models.MetodoPagamentoCartao {
+		if req.TokenCartao == "" {
+			return nil, &apperrors.ValidationError{Field: "token_cartao", Message: "token do cartão é obrigatório para pagamento com cartão"}
+		}
+		if req.Parcelas > 0 {
+			parcelas = req.Parcelas
+		}
+	} else if req.Parcelas > 1 {
+		return nil, &apperrors.ValidationError{Field: "parcelas", Message: "parcelamento disponível apenas no cartão", Value: req.Parcelas}
+	}
+
+	existente, err := s.repo.FindAberto(ctx, v.origem, v.origemID)
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.cobrar", logging.Fields{"origem": v.origem, "origem_id": v.origemID})
+		return nil, err
+	}
+	if existente != nil {
+		if existente.Status == models.PagamentoAguardando && existente.Metodo == req.Metodo {
+			return existente, nil
+		}
+		return nil, &apperrors.ConflictError{Resource: "pagamento", Message: "a venda já possui um pagamento em andamento ou concluído", Value: existente.ID}
+	}
+
+	total := payments.ToCents(v.valor)
+	comissao := payments.ToCents(v.comissao)
+	taxa := payments.ToCents(v.valor * s.taxaPlataforma / 100)
+	liquido := total - comissao - taxa
+	if total <= 0 {
+		return nil, &apperrors.ValidationError{Field: "valor", Message: "a venda não tem valor a cobrar", Value: v.valor}
+	}
+	if liquido < 0 {
+		return nil, &apperrors.ValidationError{Field: "valor", Message: "comissão e taxas excedem o valor da venda", Value: v.valor}
+	}
+
+	comprador, err := s.repo.FindUsuario(ctx, v.compradorID)
+	if err != nil {
+		return nil, err
+	}
+
+	vence := time.Now().Add(validadePix)
+	if req.Metodo == models.MetodoPagamentoBoleto {
+		vence = time.Now().Add(validadeBoleto)
+	}
+	pagamento := &models.Pagamento{
+		Origem:             v.origem,
+		OrigemID:           v.origemID,
+		CompradorID:        v.compradorID,
+		VendedorID:         v.vendedorID,
+		LeiloeiroID:        v.leiloeiroID,
+		EquinoID:           v.equinoID,
+		Equinoid:           v.equinoid,
+		ExigeTransferencia: v.exigeTransferencia,
+		Descricao:          v.descricao,
+		Valor:              payments.FromCents(total),
+		ComissaoLeiloeiro:  payments.FromCents(comissao),
+		TaxaPlataforma:     payments.FromCents(taxa),
+		ValorVendedor:      payments.FromCents(liquido),
+		Metodo:             req.Metodo,
+		Parcelas:           parcelas,
+		Provedor:           s.provider.Name(),
+		Status:             models.PagamentoAguardando,
+		VenceEm:            &vence,
+	}
+	vendedorID := v.vendedorID
+	pagamento.Repasses = append(pagamento.Repasses, models.RepassePagamento{
+		Beneficiario: models.BeneficiarioVendedor, UsuarioID: &vendedorID, Valor: pagamento.ValorVendedor, Status: models.RepassePendente,
+	})
+	if comissao > 0 && v.leiloeiroID != nil {
+		pagamento.Repasses = append(pagamento.Repasses, models.RepassePagamento{
+			Beneficiario: models.BeneficiarioLeiloeiro, UsuarioID: v.leiloeiroID, Valor: pagamento.ComissaoLeiloeiro, Status: models.RepassePendente,
+		})
+	}
+	if taxa > 0 {
+		pagamento.Repasses = append(pagamento.Repasses, models.RepassePagamento{
+			Beneficiario: models.BeneficiarioPlataforma, Valor: pagamento.TaxaPlataforma, Status: models.RepassePendente,
+		})
+	}
+
+	if err := s.repo.Create(ctx, pagamento); err != nil {
+		s.logger.LogError(err, "PagamentoService.cobrar", logging.Fields{"origem": v.origem, "origem_id": v.origemID})
+		return nil, err
+	}
+
+	charge, err := s.provider.CreateCharge(ctx, payments.ChargeRequest{
+		Reference:    fmt.Sprintf("pagamento:%d", pagamento.ID),
+		Method:       payments.Method(req.Metodo),
+		AmountCents:  total,
+		Description:  pagamento.Descricao,
+		Customer:     parte(comprador),
+		DueAt:        vence,
+		CardToken:    req.TokenCartao,
+		Installments: parcelas,
+	})
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.cobrar", logging.Fields{"pagamento_id": pagamento.ID, "provedor": pagamento.Provedor})
+		if _, errStatus := s.repo.AlterarStatus(ctx, pagamento.ID, []models.SituacaoPagamento{models.PagamentoAguardando}, models.PagamentoRecusado,
+			map[string]interface{}{"motivo_falha": "falha ao gerar a cobrança no provedor"}); errStatus != nil {
+			s.logger.LogError(errStatus, "PagamentoService.cobrar", logging.Fields{"pagamento_id": pagamento.ID})
+		}
+		return nil, apperrors.NewBusinessError("payments_unavailable", "não foi possível gerar a cobrança no provedor de pagamentos", nil)
+	}
+
+	campos := map[string]interface{}{
+		"cobranca_id":      charge.ID,
+		"pix_copia_e_cola": charge.PixCode,
+		"boleto_linha":     charge.BoletoLine,
+		"boleto_url":       charge.BoletoURL,
+	}
+	if !charge.DueAt.IsZero() {
+		campos["vence_em"] = charge.DueAt
+	}
+	if _, err := s.repo.AlterarStatus(ctx, pagamento.ID, []models.SituacaoPagamento{models.PagamentoAguardando}, models.PagamentoAguardando, campos); err != nil {
+		s.logger.LogError(err, "PagamentoService.cobrar", logging.Fields{"pagamento_id": pagamento.ID, "cobranca_id": charge.ID})
+		return nil, err
+	}
+	pagamento.CobrancaID = &charge.ID
+	if _, err := s.aplicarCobranca(ctx, pagamento, charge.Status, charge.FailureReason); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logging.Fields{
+		"pagamento_id": pagamento.ID,
+		"origem":       pagamento.Origem,
+		"origem_id":    pagamento.OrigemID,
+		"metodo":       pagamento.Metodo,
+		"valor":        pagamento.Valor,
+	}).Info("Cobrança gerada")
+
+	return s.repo.Find(ctx, pagamento.ID)
+}
+
+func (s *service) Get(ctx context.Context, id uint, userID uint, userType string) (*models.Pagamento, error) {
+	pagamento, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !pagamento.IsParte(userID) && userType != string(models.UserTypeAdmin) {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas comprador e vendedor podem ver o pagamento"}).WithAction("ver", "pagamento")
+	}
+	return pagamento, nil
+}
+
+func (s *service) List(ctx context.Context, userID uint, papel PapelPagamento, status models.SituacaoPagamento, page, limit int) ([]*models.Pagamento, int64, error) {
+	if papel != "" && papel != PapelComprador && papel != PapelVendedor {
+		return nil, 0, &apperrors.ValidationError{Field: "papel", Message: "papel deve ser comprador ou vendedor", Value: papel}
+	}
+	pagamentos, total, err := s.repo.List(ctx, userID, papel, status, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.List", logging.Fields{"user_id": userID})
+		return nil, 0, err
+	}
+	return pagamentos, total, nil
+}
+
+// Liberar encerra a custódia e repassa os valores. O comprador libera ao confirmar o recebimento; para animais a
+// transferência de propriedade já precisa constar no cadastro. Administradores liberam em disputas sem essa exigência
+func (s *service) Liberar(ctx context.Context, id uint, userID uint, userType string) (*models.Pagamento, error) {
+	pagamento, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	admin := userType == string(models.UserTypeAdmin)
+	if pagamento.CompradorID != userID && !admin {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o comprador ou um administrador pode liberar o pagamento"}).WithAction("liberar", "pagamento")
+	}
+	if pagamento.Status != models.PagamentoEmCustodia {
+		return nil, &apperrors.ConflictError{Resource: "pagamento", Message: "apenas pagamentos em custódia podem ser liberados", Value: pagamento.Status}
+	}
+	if pagamento.ExigeTransferencia && !admin {
+		transferido, err := s.transferido(ctx, pagamento)
+		if err != nil {
+			return nil, err
+		}
+		if !transferido {
+			return nil, &apperrors.ValidationError{Field: "equinoid", Message: "o valor só é liberado depois da transferência do equino para o comprador", Value: pagamento.Equinoid}
+		}
+	}
+
+	if err := s.liberar(ctx, pagamento); err != nil {
+		return nil, err
+	}
+	return s.repo.Find(ctx, id)
+}
+
+// Estornar devolve ao comprador um pagamento ainda em custódia, a pedido do vendedor ou de um administrador
+func (s *service) Estornar(ctx context.Context, id uint, userID uint, userType string, req *models.EstornarPagamentoRequest) (*models.Pagamento, error) {
+	pagamento, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pagamento.VendedorID != userID && userType != string(models.UserTypeAdmin) {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o vendedor ou um administrador pode estornar o pagamento"}).WithAction("estornar", "pagamento")
+	}
+	if err := s.estornar(ctx, pagamento, req.Motivo); err != nil {
+		return nil, err
+	}
+	return s.repo.Find(ctx, id)
+}
+
+// SimularPagamento confirma no provedor local a cobrança de um PIX ou boleto, como se o comprador tivesse pago
+func (s *service) SimularPagamento(ctx context.Context, id uint) (*models.Pagamento, error) {
+	simulador, ok := s.provider.(Simulador)
+	if !ok {
+		return nil, &apperrors.ValidationError{Field: "provedor", Message: "o provedor de pagamentos configurado não permite simulação"}
+	}
+	pagamento, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pagamento.Status != models.PagamentoAguardando || pagamento.CobrancaID == nil {
+		return nil, &apperrors.ConflictError{Resource: "pagamento", Message: "apenas cobranças aguardando pagamento podem ser simuladas", Value: pagamento.Status}
+	}
+	if err := simulador.Confirm(*pagamento.CobrancaID); err != nil {
+		return nil, &apperrors.ConflictError{Resource: "pagamento", Message: err.Error(), Value: pagamento.ID}
+	}
+	if _, err := s.aplicarCobranca(ctx, pagamento, payments.ChargePaid, ""); err != nil {
+		return nil, err
+	}
+	return s.repo.Find(ctx, id)
+}
+
+// ProcessarWebhook aplica a notificação assinada do provedor ao pagamento da cobrança; notificações repetidas não
+// alteram nada
+func (s *service) ProcessarWebhook(ctx context.Context, payload []byte, header http.Header) error {
+	if s.provider == nil {
+		return apperrors.NewBusinessError("payments_unavailable", "provedor de pagamentos não configurado", nil)
+	}
+	evento, err := s.provider.ParseWebhook(payload, header)
+	if err != nil {
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			return (&apperrors.AuthorizationError{Message: err.Error()}).WithAction("notificar", "pagamento")
+		}
+		return &apperrors.ValidationError{Field: "payload", Message: err.Error()}
+	}
+
+	pagamento, err := s.repo.FindByCobranca(ctx, evento.ChargeID)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "PagamentoService.ProcessarWebhook", logging.Fields{"cobranca_id": evento.ChargeID})
+		}
+		return err
+	}
+	_, err = s.aplicarCobranca(ctx, pagamento, evento.Status, "")
+	return err
+}
+
+// aplicarCobranca leva ao pagamento a situação da cobrança informada pelo provedor e diz se houve mudança
+func (s *service) aplicarCobranca(ctx context.Context, pagamento *models.Pagamento, status payments.ChargeStatus, motivo string) (bool, error) {
+	agora := time.Now()
+	aguardando := []models.SituacaoPagamento{models.PagamentoAguardando}
+
+	var (
+		para   models.SituacaoPagamento
+		de     = aguardando
+		campos = map[string]interface{}{}
+	)
+	switch status {
+	case payments.ChargePaid:
+		para = models.PagamentoEmCustodia
+		liberarAte := agora.Add(s.prazoCustodia)
+		campos["pago_em"] = agora
+		campos["liberar_ate"] = liberarAte
+	case payments.ChargeFailed:
+		para = models.PagamentoRecusado
+		if motivo == "" {
+			motivo = "pagamento recusado pelo provedor"
+		}
+		campos["motivo_falha"] = motivo
+	case payments.ChargeExpired:
+		para = models.PagamentoExpirado
+	case payments.ChargeRefunded:
+		para = models.PagamentoEstornado
+		de = []models.SituacaoPagamento{models.PagamentoAguardando, models.PagamentoEmCustodia}
+		campos["estornado_em"] = agora
+		campos["motivo_estorno"] = motivoEstornoProvedor
+	default:
+		return false, nil
+	}
+
+	ok, err := s.repo.AlterarStatus(ctx, pagamento.ID, de, para, campos)
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.aplicarCobranca", logging.Fields{"pagamento_id": pagamento.ID, "status": status})
+		return false, err
+	}
+	if !ok {
+		return false, nil
+	}
+
+	atualizado, err := s.repo.Find(ctx, pagamento.ID)
+	if err != nil {
+		return true, err
+	}
+	s.recordChange(ctx, atualizado, string(para), pagamento, atualizado)
+	s.sincronizarLancamentos(ctx, atualizado)
+
+	switch para {
+	case models.PagamentoEmCustodia:
+		s.notificar(ctx, []uint{atualizado.CompradorID, atualizado.VendedorID}, models.NotificacaoPagamentoConfirmado, "Pagamento em custódia",
+			fmt.Sprintf("O pagamento de R$ %.2f (%s) foi confirmado e ficará retido até a entrega.", atualizado.Valor, atualizado.Descricao), atualizado)
+	case models.PagamentoEstornado:
+		s.notificar(ctx, []uint{atualizado.CompradorID, atualizado.VendedorID}, models.NotificacaoPagamentoEstornado, "Pagamento estornado",
+			fmt.Sprintf("O pagamento de R$ %.2f (%s) foi estornado ao comprador.", atualizado.Valor, atualizado.Descricao), atualizado)
+	}
+	*pagamento = *atualizado
+	return true, nil
+}
+
+// liberar tira o pagamento da custódia e envia os repasses
+func (s *service) liberar(ctx context.Context, pagamento *models.Pagamento) error {
+	if s.provider == nil {
+		return apperrors.NewBusinessError("payments_unavailable", "provedor de pagamentos não configurado", nil)
+	}
+	before := *pagamento
+	ok, err := s.repo.AlterarStatus(ctx, pagamento.ID, []models.SituacaoPagamento{models.PagamentoEmCustodia}, models.PagamentoEmLiberacao, nil)
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.liberar", logging.Fields{"pagamento_id": pagamento.ID})
+		return err
+	}
+	if !ok {
+		return &apperrors.ConflictError{Resource: "pagamento", Message: "o pagamento não está mais em custódia", Value: pagamento.ID}
+	}
+	pagamento.Status = models.PagamentoEmLiberacao
+	s.recordChange(ctx, pagamento, "liberar", &before, pagamento)
+
+	return s.enviarRepasses(ctx, pagamento)
+}
+
+// enviarRepasses envia os repasses ainda não pagos. A taxa da plataforma já está na conta da plataforma e só é
+// registrada; falhas ficam para a próxima execução da rotina e o pagamento segue em liberação
+func (s *service) enviarRepasses(ctx context.Context, pagamento *models.Pagamento) error {
+	agora := time.Now()
+	falhas := 0
+	for i := range pagamento.Repasses {
+		repasse := &pagamento.Repasses[i]
+		if repasse.Status == models.RepassePago {
+			continue
+		}
+
+		if repasse.Beneficiario == models.BeneficiarioPlataforma || repasse.UsuarioID == nil {
+			repasse.Status = models.RepassePago
+			repasse.EnviadoEm = &agora
+		} else if err := s.enviarRepasse(ctx, pagamento, repasse); err != nil {
+			falhas++
+			repasse.Status = models.RepasseFalhou
+			repasse.UltimoErro = truncar(err.Error(), 500)
+			s.logger.LogError(err, "PagamentoService.enviarRepasses", logging.Fields{"pagamento_id": pagamento.ID, "repasse_id": repasse.ID})
+		}
+
+		if err := s.repo.UpdateRepasse(ctx, repasse); err != nil {
+			s.logger.LogError(err, "PagamentoService.enviarRepasses", logging.Fields{"pagamento_id": pagamento.ID, "repasse_id": repasse.ID})
+			return err
+		}
+	}
+	if falhas > 0 {
+		s.sincronizarLancamentos(ctx, pagamento)
+		return nil
+	}
+
+	ok, err := s.repo.AlterarStatus(ctx, pagamento.ID, []models.SituacaoPagamento{models.PagamentoEmLiberacao}, models.PagamentoLiberado,
+		map[string]interface{}{"liberado_em": agora})
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.enviarRepasses", logging.Fields{"pagamento_id": pagamento.ID})
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	pagamento.Status = models.PagamentoLiberado
+	pagamento.LiberadoEm = &agora
+	s.sincronizarLancamentos(ctx, pagamento)
+
+	destinatarios := []uint{pagamento.VendedorID}
+	if pagamento.LeiloeiroID != nil && pagamento.ComissaoLeiloeiro > 0 {
+		destinatarios = append(destinatarios, *pagamento.LeiloeiroID)
+	}
+	s.notificar(ctx, destinatarios, models.NotificacaoPagamentoLiberado, "Pagamento liberado",
+		fmt.Sprintf("Os valores de %s foram repassados. Repasse ao vendedor: R$ %.2f.", pagamento.Descricao, pagamento.ValorVendedor), pagamento)
+
+	s.logger.WithFields(logging.Fields{
+		"pagamento_id":   pagamento.ID,
+		"valor_vendedor": pagamento.ValorVendedor,
+		"comissao":       pagamento.ComissaoLeiloeiro,
+		"taxa":           pagamento.TaxaPlataforma,
+	}).Info("Pagamento liberado")
+	return nil
+}
+
+func (s *service) enviarRepasse(ctx context.Context, pagamento *models.Pagamento, repasse *models.RepassePagamento) error {
+	repasse.Tentativas++
+	beneficiario, err := s.repo.FindUsuario(ctx, *repasse.UsuarioID)
+	if err != nil {
+		return err
+	}
+	payout, err := s.provider.Payout(ctx, payments.PayoutRequest{
+		Reference:   fmt.Sprintf("repasse:%d", repasse.ID),
+		AmountCents: payments.ToCents(repasse.Valor),
+		Recipient:   parte(beneficiario),
+		Description: pagamento.Descricao,
+	})
+	if err != nil {
+		return err
+	}
+	if payout.Status == payments.PayoutFailed {
+		return fmt.Errorf("repasse recusado pelo provedor: %s", payout.FailureReason)
+	}
+	agora := time.Now()
+	repasse.Status = models.RepassePago
+	repasse.RepasseID = &payout.ID
+	repasse.UltimoErro = ""
+	repasse.EnviadoEm = &agora
+	return nil
+}
+
+// estornar devolve o valor ao comprador. A custódia é encerrada antes da chamada ao provedor para que uma liberação
+// concorrente não repasse o mesmo dinheiro; se o provedor falhar, o pagamento volta para a custódia
+func (s *service) estornar(ctx context.Context, pagamento *models.Pagamento, motivo string) error {
+	if s.provider == nil {
+		return apperrors.NewBusinessError("payments_unavailable", "provedor de pagamentos não configurado", nil)
+	}
+	if pagamento.Status != models.PagamentoEmCustodia || pagamento.CobrancaID == nil {
+		return &apperrors.ConflictError{Resource: "pagamento", Message: "apenas pagamentos em custódia podem ser estornados", Value: pagamento.Status}
+	}
+
+	before := *pagamento
+	agora := time.Now()
+	custodia := []models.SituacaoPagamento{models.PagamentoEmCustodia}
+	ok, err := s.repo.AlterarStatus(ctx, pagamento.ID, custodia, models.PagamentoEstornado,
+		map[string]interface{}{"estornado_em": agora, "motivo_estorno": motivo})
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.estornar", logging.Fields{"pagamento_id": pagamento.ID})
+		return err
+	}
+	if !ok {
+		return &apperrors.ConflictError{Resource: "pagamento", Message: "o pagamento não está mais em custódia", Value: pagamento.ID}
+	}
+
+	estornoID, err := s.provider.Refund(ctx, payments.RefundRequest{
+		Reference:   fmt.Sprintf("estorno:%d", pagamento.ID),
+		ChargeID:    *pagamento.CobrancaID,
+		AmountCents: payments.ToCents(pagamento.Valor),
+		Reason:      motivo,
+	})
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.estornar", logging.Fields{"pagamento_id": pagamento.ID, "provedor": pagamento.Provedor})
+		if _, errStatus := s.repo.AlterarStatus(ctx, pagamento.ID, []models.SituacaoPagamento{models.PagamentoEstornado}, models.PagamentoEmCustodia,
+			map[string]interface{}{"estornado_em": nil, "motivo_estorno": ""}); errStatus != nil {
+			s.logger.LogError(errStatus, "PagamentoService.estornar", logging.Fields{"pagamento_id": pagamento.ID})
+		}
+		return apperrors.NewBusinessError("payments_unavailable", "não foi possível estornar o pagamento no provedor", nil)
+	}
+	if _, err := s.repo.AlterarStatus(ctx, pagamento.ID, []models.SituacaoPagamento{models.PagamentoEstornado}, models.PagamentoEstornado,
+		map[string]interface{}{"estorno_id": estornoID}); err != nil {
+		s.logger.LogError(err, "PagamentoService.estornar", logging.Fields{"pagamento_id": pagamento.ID, "estorno_id": estornoID})
+	}
+
+	pagamento.Status = models.PagamentoEstornado
+	pagamento.EstornadoEm = &agora
+	pagamento.MotivoEstorno = motivo
+	pagamento.EstornoID = &estornoID
+	s.recordChange(ctx, pagamento, "estornar", &before, pagamento)
+	s.sincronizarLancamentos(ctx, pagamento)
+	s.notificar(ctx, []uint{pagamento.CompradorID, pagamento.VendedorID}, models.NotificacaoPagamentoEstornado, "Pagamento estornado",
+		fmt.Sprintf("O pagamento de R$ %.2f (%s) foi estornado ao comprador. Motivo: %s", pagamento.Valor, pagamento.Descricao, motivo), pagamento)
+	return nil
+}
+
+// transferido confere se o equino vendido já está em nome do comprador
+func (s *service) transferido(ctx context.Context, pagamento *models.Pagamento) (bool, error) {
+	if pagamento.Equinoid == nil {
+		return false, nil
+	}
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, *pagamento.Equinoid)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return false, nil
+		}
+		s.logger.LogError(err, "PagamentoService.transferido", logging.Fields{"pagamento_id": pagamento.ID, "equinoid": *pagamento.Equinoid})
+		return false, err
+	}
+	return equino.ProprietarioID == pagamento.CompradorID, nil
+}
+
+// ProcessarPagamentos concilia os pagamentos com o provedor e aplica os prazos da custódia: cobranças pendentes são
+// consultadas (webhooks perdidos) ou expiradas; pagamentos em custódia são liberados quando o equino já foi
+// transferido ao comprador ou, para os demais itens, ao fim do prazo; animais não transferidos no prazo são
+// estornados; repasses que falharam são reenviados
+func (s *service) ProcessarPagamentos(ctx context.Context) (int, error) {
+	if s.provider == nil {
+		return 0, nil
+	}
+	processados := 0
+
+	err := s.percorrer(ctx, models.PagamentoAguardando, func(pagamento *models.Pagamento) {
+		if s.consultarCobranca(ctx, pagamento) {
+			processados++
+		}
+	})
+	if err != nil {
+		return processados, err
+	}
+
+	err = s.percorrer(ctx, models.PagamentoEmCustodia, func(pagamento *models.Pagamento) {
+		if s.verificarCustodia(ctx, pagamento) {
+			processados++
+		}
+	})
+	if err != nil {
+		return processados, err
+	}
+
+	err = s.percorrer(ctx, models.PagamentoEmLiberacao, func(pagamento *models.Pagamento) {
+		if err := s.enviarRepasses(ctx, pagamento); err != nil {
+			return
+		}
+		if pagamento.Status == models.PagamentoLiberado {
+			processados++
+		}
+	})
+	return processados, err
+}
+
+// percorrer aplica fn a todos os pagamentos no status, em lotes
+func (s *service) percorrer(ctx context.Context, status models.SituacaoPagamento, fn func(*models.Pagamento)) error {
+	var aposID uint
+	for {
+		pagamentos, err := s.repo.ListPorStatus(ctx, status, aposID, lotePagamentos)
+		if err != nil {
+			s.logger.LogError(err, "PagamentoService.ProcessarPagamentos", logging.Fields{"status": status})
+			return err
+		}
+		for _, pagamento := range pagamentos {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fn(pagamento)
+			aposID = pagamento.ID
+		}
+		if len(pagamentos) < lotePagamentos {
+			return nil
+		}
+	}
+}
+
+func (s *service) consultarCobranca(ctx context.Context, pagamento *models.Pagamento) bool {
+	vencido := pagamento.VenceEm != nil && time.Now().After(*pagamento.VenceEm)
+	status := payments.ChargePending
+	motivo := ""
+	if pagamento.CobrancaID != nil {
+		charge, err := s.provider.GetCharge(ctx, *pagamento.CobrancaID)
+		switch {
+		case err == nil:
+			status, motivo = charge.Status, charge.FailureReason
+		case errors.Is(err, payments.ErrChargeNotFound) && vencido:
+		default:
+			s.logger.LogError(err, "PagamentoService.consultarCobranca", logging.Fields{"pagamento_id": pagamento.ID})
+			return false
+		}
+	}
+	if status == payments.ChargePending && vencido {
+		status = payments.ChargeExpired
+	}
+	alterado, err := s.aplicarCobranca(ctx, pagamento, status, motivo)
+	if err != nil {
+		return false
+	}
+	return alterado
+}
+
+func (s *service) verificarCustodia(ctx context.Context, pagamento *models.Pagamento) bool {
+	prazoVencido := pagamento.LiberarAte != nil && time.Now().After(*pagamento.LiberarAte)
+
+	if pagamento.ExigeTransferencia {
+		transferido, err := s.transferido(ctx, pagamento)
+		if err != nil {
+			return false
+		}
+		if transferido {
+			return s.liberar(ctx, pagamento) == nil
+		}
+		if prazoVencido {
+			return s.estornar(ctx, pagamento, motivoPrazoTransferencia) == nil
+		}
+		return false
+	}
+	if prazoVencido {
+		return s.liberar(ctx, pagamento) == nil
+	}
+	return false
+}
+
+// Conciliacao confronta o estado dos pagamentos criados no período com os lançamentos financeiros gerados por eles.
+// Com corrigir, os lançamentos ausentes ou divergentes são regravados a partir do pagamento
+func (s *service) Conciliacao(ctx context.Context, de, ate time.Time, corrigir bool) (*models.ResumoConciliacao, error) {
+	if !ate.After(de) {
+		return nil, &apperrors.ValidationError{Field: "ate", Message: "o fim do período deve ser posterior ao início"}
+	}
+	pagamentos, err := s.repo.ListPeriodo(ctx, de, ate)
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.Conciliacao", nil)
+		return nil, err
+	}
+	ids := make([]uint, len(pagamentos))
+	for i, pagamento := range pagamentos {
+		ids[i] = pagamento.ID
+	}
+	lancados, err := s.repo.ListLancamentos(ctx, ids)
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.Conciliacao", nil)
+		return nil, err
+	}
+	porReferencia := make(map[string]*models.TransacaoFinanceira, len(lancados))
+	for _, lancamento := range lancados {
+		if lancamento.Referencia != nil {
+			porReferencia[*lancamento.Referencia] = lancamento
+		}
+	}
+
+	resumo := &models.ResumoConciliacao{De: de, Ate: ate, Pagamentos: len(pagamentos), Divergencias: []models.DivergenciaConciliacao{}}
+	var recebido, custodia, vendedor, comissoes, taxas, estornado int64
+	for _, pagamento := range pagamentos {
+		if pagamento.PagoEm != nil {
+			recebido += payments.ToCents(pagamento.Valor)
+		}
+		switch pagamento.Status {
+		case models.PagamentoEstornado:
+			if pagamento.PagoEm != nil {
+				estornado += payments.ToCents(pagamento.Valor)
+			}
+		case models.PagamentoEmCustodia, models.PagamentoEmLiberacao, models.PagamentoLiberado:
+			retido := payments.ToCents(pagamento.Valor)
+			for _, repasse := range pagamento.Repasses {
+				if repasse.Status != models.RepassePago {
+					continue
+				}
+				valor := payments.ToCents(repasse.Valor)
+				retido -= valor
+				switch repasse.Beneficiario {
+				case models.BeneficiarioVendedor:
+					vendedor += valor
+				case models.BeneficiarioLeiloeiro:
+					comissoes += valor
+				case models.BeneficiarioPlataforma:
+					taxas += valor
+				}
+			}
+			custodia += retido
+		}
+
+		esperados := lancamentosEsperados(pagamento)
+		divergentes := 0
+		for _, esperado := range esperados {
+			lancado := porReferencia[*esperado.Referencia]
+			if lancado != nil && lancado.Status == esperado.Status && payments.ToCents(lancado.Valor) == payments.ToCents(esperado.Valor) {
+				continue
+			}
+			divergencia := models.DivergenciaConciliacao{
+				PagamentoID: pagamento.ID,
+				Referencia:  *esperado.Referencia,
+				Esperado:    esperado.Status,
+				Valor:       esperado.Valor,
+			}
+			if lancado != nil {
+				divergencia.Lancado = lancado.Status
+				divergencia.ValorLancado = lancado.Valor
+			}
+			resumo.Divergencias = append(resumo.Divergencias, divergencia)
+			divergentes++
+		}
+		if corrigir && divergentes > 0 {
+			if err := s.repo.SalvarLancamentos(ctx, esperados); err != nil {
+				s.logger.LogError(err, "PagamentoService.Conciliacao", logging.Fields{"pagamento_id": pagamento.ID})
+				return nil, err
+			}
+			resumo.Corrigidas += divergentes
+		}
+	}
+
+	resumo.Recebido = payments.FromCents(recebido)
+	resumo.EmCustodia = payments.FromCents(custodia)
+	resumo.RepassadoVendedor = payments.FromCents(vendedor)
+	resumo.Comissoes = payments.FromCents(comissoes)
+	resumo.TaxasPlataforma = payments.FromCents(taxas)
+	resumo.Estornado = payments.FromCents(estornado)
+	return resumo, nil
+}
+
+// sincronizarLancamentos grava os lançamentos financeiros do estado atual do pagamento; falhas são corrigidas pela
+// conciliação
+func (s *service) sincronizarLancamentos(ctx context.Context, pagamento *models.Pagamento) {
+	if err := s.repo.SalvarLancamentos(ctx, lancamentosEsperados(pagamento)); err != nil {
+		s.logger.LogError(err, "PagamentoService.sincronizarLancamentos", logging.Fields{"pagamento_id": pagamento.ID})
+	}
+}
+
+// lancamentosEsperados lançamentos que o pagamento deve ter no financeiro: a compra (despesa do comprador, pendente
+// em custódia, paga na liberação e cancelada no estorno) e, a partir da liberação, um recebimento por repasse
+func lancamentosEsperados(pagamento *models.Pagamento) []*models.TransacaoFinanceira {
+	if pagamento.PagoEm == nil {
+		return nil
+	}
+
+	statusCompra := models.StatusPagamentoPendente
+	switch pagamento.Status {
+	case models.PagamentoLiberado:
+		statusCompra = models.StatusPagamentoPago
+	case models.PagamentoEstornado:
+		statusCompra = models.StatusPagamentoCancelado
+	}
+	categoriaCompra, categoriaVenda := "Compra no marketplace", "Venda no marketplace"
+	if pagamento.Origem == models.OrigemPagamentoLeilao {
+		categoriaCompra, categoriaVenda = "Compra em leilão", "Venda em leilão"
+	}
+
+	compradorID := pagamento.CompradorID
+	lancamentos := []*models.TransacaoFinanceira{
+		lancamento(pagamento, "compra", models.TipoDespesa, categoriaCompra, &compradorID, pagamento.Valor, statusCompra, *pagamento.PagoEm),
+	}
+	if pagamento.Status != models.PagamentoEmLiberacao && pagamento.Status != models.PagamentoLiberado {
+		return lancamentos
+	}
+
+	for _, repasse := range pagamento.Repasses {
+		categoria := categoriaVenda
+		switch repasse.Beneficiario {
+		case models.BeneficiarioLeiloeiro:
+			categoria = "Comissão de leilão"
+		case models.BeneficiarioPlataforma:
+			categoria = "Taxa da plataforma"
+		}
+		status := models.StatusPagamentoPendente
+		data := time.Now()
+		if repasse.Status == models.RepassePago {
+			status = models.StatusPagamentoPago
+			if repasse.EnviadoEm != nil {
+				data = *repasse.EnviadoEm
+			}
+		}
+		lancamentos = append(lancamentos, lancamento(pagamento, "repasse:"+string(repasse.Beneficiario), models.TipoReceita, categoria, repasse.UsuarioID, repasse.Valor, status, data))
+	}
+	return lancamentos
+}
+
+func lancamento(pagamento *models.Pagamento, chave string, tipo models.TipoTransacao, categoria string, usuarioID *uint, valor float64, status models.StatusPagamento, data time.Time) *models.TransacaoFinanceira {
+	referencia := fmt.Sprintf("pagamento:%d:%s", pagamento.ID, chave)
+	pagamentoID := pagamento.ID
+	return &models.TransacaoFinanceira{
+		Tipo:        tipo,
+		Categoria:   categoria,
+		Descricao:   truncar(pagamento.Descricao, 500),
+		Valor:       valor,
+		Data:        data,
+		EquinoID:    pagamento.EquinoID,
+		Status:      status,
+		UsuarioID:   usuarioID,
+		PagamentoID: &pagamentoID,
+		Referencia:  &referencia,
+	}
+}
+
+func (s *service) notificar(ctx context.Context, destinatarios []uint, tipo models.TipoNotificacao, titulo, mensagem string, pagamento *models.Pagamento) {
+	if s.notificador == nil {
+		return
+	}
+	err := s.notificador.Notificar(ctx, destinatarios, models.NovaNotificacao{
+		Tipo:           tipo,
+		Titulo:         titulo,
+		Mensagem:       mensagem,
+		ReferenciaTipo: "pagamento",
+		ReferenciaID:   &pagamento.ID,
+	})
+	if err != nil {
+		s.logger.LogError(err, "PagamentoService.notificar", logging.Fields{"pagamento_id": pagamento.ID, "tipo": tipo})
+	}
+}
+
+func (s *service) recordChange(ctx context.Context, pagamento *models.Pagamento, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, "pagamento", fmt.Sprint(pagamento.ID), operation, before, after); err != nil {
+		s.logger.LogError(err, "PagamentoService.recordChange", logging.Fields{"pagamento_id": pagamento.ID, "operation": operation})
+	}
+}
+
+func parte(user *models.User) payments.Party {
+	return payments.Party{Name: user.Name, Email: user.Email, Document: user.CPFCNPJ}
+}
+
+func truncar(texto string, limite int) string {
+	runes := []rune(texto)
+	if len(runes) <= limite {
+		return texto
+	}
+	return string(runes[:limite])
+}
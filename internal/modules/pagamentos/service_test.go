@@ -0,0 +1,321 @@
+package pagamentos
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	"github.com/equinoid/backend/internal/modules/leiloes"
+	"github.com/equinoid/backend/internal/modules/marketplace"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/equinoid/backend/pkg/payments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	leiloeiroID uint = 1
+	compradorID uint = 2
+	vendedorID  uint = 3
+)
+
+func setupPagamentosService(t *testing.T) (Service, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Skip("sqlite driver unavailable for tests")
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Equino{}, &models.EquinoIdentificador{}, &models.Leilao{},
+		&models.ParticipacaoLeilao{}, &models.Pagamento{}, &models.RepassePagamento{}, &models.TransacaoFinanceira{}))
+
+	for id, nome := range map[uint]string{leiloeiroID: "Leiloeiro", compradorID: "Comprador", vendedorID: "Vendedor"} {
+		user := &models.User{
+			ID:          id,
+			SupabaseID:  fmt.Sprintf("supabase-%d", id),
+			KeycloakSub: fmt.Sprintf("keycloak-%d", id),
+			Email:       fmt.Sprintf("usuario%d@equinoid.test", id),
+			Name:        nome,
+			UserType:    models.UserTypeCriador,
+			CPFCNPJ:     fmt.Sprintf("0000000000%d", id),
+		}
+		require.NoError(t, db.Omit(clause.Associations).Create(user).Error)
+	}
+
+	cfg := &config.Config{PaymentPlatformFeePercent: 2.5, PaymentEscrowPeriod: 30 * 24 * time.Hour}
+	service := NewService(NewRepository(db), leiloes.NewRepository(db), marketplace.NewRepository(db), equinos.NewRepository(db),
+		payments.NewFakeProvider("segredo"), nil, nil, cfg, logging.NewLogger("error"))
+	return service, db
+}
+
+// loteVendido lote arrematado pelo comprador com o equino ainda em nome do vendedor
+func loteVendido(t *testing.T, db *gorm.DB, valor, comissao float64) *models.ParticipacaoLeilao {
+	nascimento := time.Now().AddDate(-3, 0, 0)
+	equino := &models.Equino{
+		Equinoid:       "BRA-2024-0000000001",
+		MicrochipID:    "985000000000001",
+		Nome:           "Relâmpago",
+		DataNascimento: &nascimento,
+		Sexo:           models.SexoMacho,
+		Pelagem:        "Alazã",
+		Raca:           "Mangalarga Marchador",
+		PaisOrigem:     "BRA",
+		ProprietarioID: vendedorID,
+	}
+	require.NoError(t, db.Omit(clause.Associations).Create(equino).Error)
+
+	leilao := &models.Leilao{
+		Nome:                   "Leilão de Potros",
+		LeiloeiroID:            leiloeiroID,
+		TaxaComissaoPercentual: 5,
+		DataInicio:             time.Now().Add(-2 * time.Hour),
+		DataFim:                time.Now().Add(-time.Hour),
+		TipoLeilao:             models.TipoLeilaoPresencial,
+		Status:                 models.StatusLeilaoEncerrado,
+	}
+	require.NoError(t, db.Omit(clause.Associations).Create(leilao).Error)
+
+	comprador := compradorID
+	participacao := &models.ParticipacaoLeilao{
+		LeilaoID:          leilao.ID,
+		EquinoID:          equino.ID,
+		CriadorID:         vendedorID,
+		ValorInicial:      valor,
+		ValorVendido:      &valor,
+		ComissaoLeiloeiro: &comissao,
+		CompradorID:       &comprador,
+		Status:            models.StatusParticipacaoVendido,
+	}
+	require.NoError(t, db.Omit(clause.Associations).Create(participacao).Error)
+	return participacao
+}
+
+// pagamentoEmCustodia paga o lote por PIX e confirma a cobrança no provedor local
+func pagamentoEmCustodia(t *testing.T, service Service, db *gorm.DB, valor, comissao float64) *models.Pagamento {
+	ctx := context.Background()
+	lote := loteVendido(t, db, valor, comissao)
+	pagamento, err := service.PagarLeilao(ctx, lote.ID, compradorID, &models.CheckoutPagamentoRequest{Metodo: models.MetodoPagamentoPix})
+	require.NoError(t, err)
+	pagamento, err = service.SimularPagamento(ctx, pagamento.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.PagamentoEmCustodia, pagamento.Status)
+	return pagamento
+}
+
+func lancamentos(t *testing.T, db *gorm.DB, pagamentoID uint) map[string]*models.TransacaoFinanceira {
+	var lista []*models.TransacaoFinanceira
+	require.NoError(t, db.Where("pagamento_id = ?", pagamentoID).Find(&lista).Error)
+	porReferencia := make(map[string]*models.TransacaoFinanceira, len(lista))
+	for _, lancamento := range lista {
+		porReferencia[*lancamento.Referencia] = lancamento
+	}
+	return porReferencia
+}
+
+func TestPagamentoService_CobrancaFicaEmCustodiaAteAEntrega(t *testing.T) {
+	service, db := setupPagamentosService(t)
+	ctx := context.Background()
+	lote := loteVendido(t, db, 50000, 2500)
+	pix := &models.CheckoutPagamentoRequest{Metodo: models.MetodoPagamentoPix}
+
+	_, err := service.PagarLeilao(ctx, lote.ID, vendedorID, pix)
+	assert.True(t, apperrors.IsAuthorization(err))
+
+	pagamento, err := service.PagarLeilao(ctx, lote.ID, compradorID, pix)
+	require.NoError(t, err)
+	assert.Equal(t, models.PagamentoAguardando, pagamento.Status)
+	assert.True(t, pagamento.ExigeTransferencia)
+	require.NotNil(t, pagamento.CobrancaID)
+	assert.NotEmpty(t, pagamento.PixCopiaECola)
+
+	repetido, err := service.PagarLeilao(ctx, lote.ID, compradorID, pix)
+	require.NoError(t, err)
+	assert.Equal(t, pagamento.ID, repetido.ID)
+
+	_, err = service.PagarLeilao(ctx, lote.ID, compradorID, &models.CheckoutPagamentoRequest{Metodo: models.MetodoPagamentoBoleto})
+	assert.True(t, apperrors.IsConflict(err))
+
+	pago, err := service.SimularPagamento(ctx, pagamento.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PagamentoEmCustodia, pago.Status)
+	require.NotNil(t, pago.PagoEm)
+	require.NotNil(t, pago.LiberarAte)
+	assert.WithinDuration(t, pago.PagoEm.Add(30*24*time.Hour), *pago.LiberarAte, time.Minute)
+	for _, repasse := range pago.Repasses {
+		assert.Equal(t, models.RepassePendente, repasse.Status)
+	}
+
+	compra := lancamentos(t, db, pago.ID)[fmt.Sprintf("pagamento:%d:compra", pago.ID)]
+	require.NotNil(t, compra)
+	assert.Equal(t, models.StatusPagamentoPendente, compra.Status)
+	assert.Equal(t, 50000.0, compra.Valor)
+
+	_, err = service.SimularPagamento(ctx, pagamento.ID)
+	assert.True(t, apperrors.IsConflict(err))
+}
+
+func TestPagamentoService_DivisaoArredondaAoCentavo(t *testing.T) {
+	casos := []struct {
+		nome     string
+		valor    float64
+		comissao float64
+		taxa     float64
+		vendedor float64
+	}{
+		{nome: "taxa com fração de centavo", valor: 3333.33, comissao: 166.67, taxa: 83.33, vendedor: 3083.33},
+		{nome: "meio centavo arredonda para cima", valor: 1000.20, comissao: 50.01, taxa: 25.01, vendedor: 925.18},
+		{nome: "sem comissão", valor: 0.99, comissao: 0, taxa: 0.02, vendedor: 0.97},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			service, db := setupPagamentosService(t)
+			lote := loteVendido(t, db, caso.valor, caso.comissao)
+
+			pagamento, err := service.PagarLeilao(context.Background(), lote.ID, compradorID, &models.CheckoutPagamentoRequest{Metodo: models.MetodoPagamentoPix})
+			require.NoError(t, err)
+
+			assert.Equal(t, caso.valor, pagamento.Valor)
+			assert.Equal(t, caso.comissao, pagamento.ComissaoLeiloeiro)
+			assert.Equal(t, caso.taxa, pagamento.TaxaPlataforma)
+			assert.Equal(t, caso.vendedor, pagamento.ValorVendedor)
+			assert.Equal(t, payments.ToCents(pagamento.Valor),
+				payments.ToCents(pagamento.ValorVendedor)+payments.ToCents(pagamento.ComissaoLeiloeiro)+payments.ToCents(pagamento.TaxaPlataforma))
+
+			porBeneficiario := map[models.TipoBeneficiario]float64{}
+			var repassado int64
+			for _, repasse := range pagamento.Repasses {
+				porBeneficiario[repasse.Beneficiario] = repasse.Valor
+				repassado += payments.ToCents(repasse.Valor)
+			}
+			assert.Equal(t, payments.ToCents(caso.valor), repassado)
+			assert.Equal(t, caso.vendedor, porBeneficiario[models.BeneficiarioVendedor])
+			assert.Equal(t, caso.taxa, porBeneficiario[models.BeneficiarioPlataforma])
+			_, temComissao := porBeneficiario[models.BeneficiarioLeiloeiro]
+			assert.Equal(t, caso.comissao > 0, temComissao)
+		})
+	}
+}
+
+func TestPagamentoService_EstornoVoltaParaCustodiaQuandoOProvedorFalha(t *testing.T) {
+	service, db := setupPagamentosService(t)
+	ctx := context.Background()
+	pagamento := pagamentoEmCustodia(t, service, db, 20000, 1000)
+	motivo := &models.EstornarPagamentoRequest{Motivo: "Animal não entregue"}
+
+	_, err := service.Estornar(ctx, pagamento.ID, compradorID, string(models.UserTypeCriador), motivo)
+	assert.True(t, apperrors.IsAuthorization(err))
+
+	cobrancaID := *pagamento.CobrancaID
+	require.NoError(t, db.Model(&models.Pagamento{}).Where("id = ?", pagamento.ID).Update("cobranca_id", "fake_ch_inexistente").Error)
+
+	_, err = service.Estornar(ctx, pagamento.ID, vendedorID, string(models.UserTypeCriador), motivo)
+	assert.True(t, apperrors.IsBusiness(err))
+
+	var revertido models.Pagamento
+	require.NoError(t, db.First(&revertido, pagamento.ID).Error)
+	assert.Equal(t, models.PagamentoEmCustodia, revertido.Status)
+	assert.Nil(t, revertido.EstornadoEm)
+	assert.Empty(t, revertido.MotivoEstorno)
+	assert.Nil(t, revertido.EstornoID)
+
+	require.NoError(t, db.Model(&models.Pagamento{}).Where("id = ?", pagamento.ID).Update("cobranca_id", cobrancaID).Error)
+
+	estornado, err := service.Estornar(ctx, pagamento.ID, vendedorID, string(models.UserTypeCriador), motivo)
+	require.NoError(t, err)
+	assert.Equal(t, models.PagamentoEstornado, estornado.Status)
+	assert.NotNil(t, estornado.EstornadoEm)
+	assert.Equal(t, motivo.Motivo, estornado.MotivoEstorno)
+	require.NotNil(t, estornado.EstornoID)
+
+	compra := lancamentos(t, db, pagamento.ID)[fmt.Sprintf("pagamento:%d:compra", pagamento.ID)]
+	require.NotNil(t, compra)
+	assert.Equal(t, models.StatusPagamentoCancelado, compra.Status)
+
+	_, err = service.Liberar(ctx, pagamento.ID, compradorID, string(models.UserTypeCriador))
+	assert.True(t, apperrors.IsConflict(err))
+}
+
+func TestPagamentoService_LiberacaoExigeTransferenciaDoEquino(t *testing.T) {
+	service, db := setupPagamentosService(t)
+	ctx := context.Background()
+	pagamento := pagamentoEmCustodia(t, service, db, 40000, 2000)
+
+	_, err := service.Liberar(ctx, pagamento.ID, vendedorID, string(models.UserTypeCriador))
+	assert.True(t, apperrors.IsAuthorization(err))
+
+	_, err = service.Liberar(ctx, pagamento.ID, compradorID, string(models.UserTypeCriador))
+	assert.True(t, apperrors.IsValidation(err))
+
+	processados, err := service.ProcessarPagamentos(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, processados)
+
+	var emCustodia models.Pagamento
+	require.NoError(t, db.First(&emCustodia, pagamento.ID).Error)
+	assert.Equal(t, models.PagamentoEmCustodia, emCustodia.Status)
+
+	require.NoError(t, db.Model(&models.Equino{}).Where("equinoid = ?", *pagamento.Equinoid).Update("proprietario_id", compradorID).Error)
+
+	liberado, err := service.Liberar(ctx, pagamento.ID, compradorID, string(models.UserTypeCriador))
+	require.NoError(t, err)
+	assert.Equal(t, models.PagamentoLiberado, liberado.Status)
+	assert.NotNil(t, liberado.LiberadoEm)
+	for _, repasse := range liberado.Repasses {
+		assert.Equal(t, models.RepassePago, repasse.Status, string(repasse.Beneficiario))
+		assert.NotNil(t, repasse.EnviadoEm)
+	}
+
+	registrados := lancamentos(t, db, pagamento.ID)
+	assert.Len(t, registrados, 4)
+	assert.Equal(t, models.StatusPagamentoPago, registrados[fmt.Sprintf("pagamento:%d:compra", pagamento.ID)].Status)
+}
+
+func TestPagamentoService_ConciliacaoRegravaLancamentosAusentes(t *testing.T) {
+	service, db := setupPagamentosService(t)
+	ctx := context.Background()
+	pagamento := pagamentoEmCustodia(t, service, db, 10000, 500)
+	require.NoError(t, db.Model(&models.Equino{}).Where("equinoid = ?", *pagamento.Equinoid).Update("proprietario_id", compradorID).Error)
+	_, err := service.Liberar(ctx, pagamento.ID, compradorID, string(models.UserTypeCriador))
+	require.NoError(t, err)
+
+	de, ate := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	_, err = service.Conciliacao(ctx, ate, de, false)
+	assert.True(t, apperrors.IsValidation(err))
+
+	resumo, err := service.Conciliacao(ctx, de, ate, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resumo.Pagamentos)
+	assert.Equal(t, 10000.0, resumo.Recebido)
+	assert.Equal(t, 0.0, resumo.EmCustodia)
+	assert.Equal(t, 9250.0, resumo.RepassadoVendedor)
+	assert.Equal(t, 500.0, resumo.Comissoes)
+	assert.Equal(t, 250.0, resumo.TaxasPlataforma)
+	assert.Equal(t, 0.0, resumo.Estornado)
+	assert.Empty(t, resumo.Divergencias)
+
+	require.NoError(t, db.Where("pagamento_id = ?", pagamento.ID).Delete(&models.TransacaoFinanceira{}).Error)
+
+	resumo, err = service.Conciliacao(ctx, de, ate, false)
+	require.NoError(t, err)
+	assert.Len(t, resumo.Divergencias, 4)
+	assert.Equal(t, 0, resumo.Corrigidas)
+	assert.Empty(t, lancamentos(t, db, pagamento.ID))
+
+	resumo, err = service.Conciliacao(ctx, de, ate, true)
+	require.NoError(t, err)
+	assert.Equal(t, 4, resumo.Corrigidas)
+	assert.Len(t, lancamentos(t, db, pagamento.ID), 4)
+
+	resumo, err = service.Conciliacao(ctx, de, ate, false)
+	require.NoError(t, err)
+	assert.Empty(t, resumo.Divergencias)
+}
@@ -0,0 +1,285 @@
+package passaportes
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// maxVerificationUpload tamanho máximo do PDF enviado para verificação pública
+const maxVerificationUpload = 10 << 20
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Emitir godoc
+// @Summary Emitir passaporte do equino
+// @Description Gera o passaporte/certificado de genealogia em PDF (identificação, resenha, pedigree de 4 gerações, resumo vacinal e QR code de verificação) assinado com o selo institucional; substitui o passaporte vigente
+// @Tags Passaportes
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 201 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/passaportes [post]
+// @Security BearerAuth
+func (h *Handler) Emitir(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	passaporte, err := h.service.Emitir(c.Request.Context(), c.Param("equinoid"), userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao emitir passaporte")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Passaporte emitido",
+		Timestamp: time.Now(),
+		Data:      passaporte,
+	})
+}
+
+// ListByEquino godoc
+// @Summary Listar passaportes do equino
+// @Description Histórico de emissões do equino com a situação de cada uma
+// @Tags Passaportes
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/passaportes [get]
+// @Security BearerAuth
+func (h *Handler) ListByEquino(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	passaportes, err := h.service.ListByEquino(c.Request.Context(), c.Param("equinoid"), userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar passaportes")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Passaportes do equino (total: %d)", len(passaportes)),
+		Timestamp: time.Now(),
+		Data:      passaportes,
+	})
+}
+
+// Download godoc
+// @Summary Baixar passaporte em PDF
+// @Description PDF assinado exatamente como emitido
+// @Tags Passaportes
+// @Produce application/pdf
+// @Param codigo path string true "Código do passaporte"
+// @Success 200 {file} file
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /passaportes/{codigo}/pdf [get]
+// @Security BearerAuth
+func (h *Handler) Download(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	reader, passaporte, err := h.service.OpenPDF(c.Request.Context(), c.Param("codigo"), userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao baixar passaporte")
+		return
+	}
+	defer reader.Close()
+
+	filename := fmt.Sprintf("passaporte-%s.pdf", passaporte.Equinoid)
+	c.DataFromReader(http.StatusOK, passaporte.Tamanho, "application/pdf", reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": filename}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+// Revogar godoc
+// @Summary Revogar passaporte
+// @Description Cancela o passaporte; a verificação pública passa a informá-lo como revogado
+// @Tags Passaportes
+// @Accept json
+// @Produce json
+// @Param codigo path string true "Código do passaporte"
+// @Param request body models.RevogarPassaporteRequest true "Motivo da revogação"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /passaportes/{codigo}/revogar [post]
+// @Security BearerAuth
+func (h *Handler) Revogar(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.RevogarPassaporteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	passaporte, err := h.service.Revogar(c.Request.Context(), c.Param("codigo"), userID, userType, req.Motivo)
+	if err != nil {
+		h.respondError(c, err, "Erro ao revogar passaporte")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Passaporte revogado",
+		Timestamp: time.Now(),
+		Data:      passaporte,
+	})
+}
+
+// VerificarCodigo godoc
+// @Summary Verificar passaporte pelo código
+// @Description Endereço do QR code impresso no passaporte: confere o arquivo emitido contra o hash registrado, a assinatura do selo institucional e a situação da emissão
+// @Tags Passaportes
+// @Produce json
+// @Param codigo path string true "Código do passaporte"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /public/passaportes/{codigo} [get]
+func (h *Handler) VerificarCodigo(c *gin.Context) {
+	verificacao, err := h.service.VerificarCodigo(c.Request.Context(), c.Param("codigo"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao verificar passaporte")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   verificacao.Mensagem,
+		Timestamp: time.Now(),
+		Data:      verificacao,
+	})
+}
+
+// VerificarArquivo godoc
+// @Summary Verificar arquivo de passaporte
+// @Description Confere um PDF apresentado: localiza a emissão pelo SHA-256 do arquivo e valida as assinaturas
+// @Tags Passaportes
+// @Accept multipart/form-data
+// @Produce json
+// @Param arquivo formData file true "PDF do passaporte"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /public/passaportes/verificar [post]
+func (h *Handler) VerificarArquivo(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxVerificationUpload+(1<<20))
+	header, err := c.FormFile("arquivo")
+	if err != nil || header.Size > maxVerificationUpload {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Arquivo não enviado ou maior que o limite permitido",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		h.respondError(c, err, "Erro ao ler arquivo enviado")
+		return
+	}
+	defer file.Close()
+	arquivo, err := io.ReadAll(io.LimitReader(file, maxVerificationUpload))
+	if err != nil {
+		h.respondError(c, err, "Erro ao ler arquivo enviado")
+		return
+	}
+
+	verificacao, err := h.service.VerificarArquivo(c.Request.Context(), arquivo)
+	if err != nil {
+		h.respondError(c, err, "Erro ao verificar passaporte")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   verificacao.Mensagem,
+		Timestamp: time.Now(),
+		Data:      verificacao,
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, string, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, "", false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	return userID, userType, true
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	case apperrors.IsBusiness(err):
+		status = http.StatusServiceUnavailable
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,351 @@
+package passaportes
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/pkg/pdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// geracoesPedigree gerações impressas no pedigree do passaporte
+const geracoesPedigree = 4
+
+// maxVacinasImpressas vacinas distintas listadas no resumo vacinal
+const maxVacinasImpressas = 8
+
+const (
+	margem         = 30.0
+	larguraUtil    = pdf.A4Width - 2*margem
+	corpoTexto     = 9.0
+	corpoRotulo    = 7.0
+	alturaSecao    = 16.0
+	ladoQRCode     = 92.0
+	formatoData    = "02/01/2006"
+	textoRodape    = "Documento assinado digitalmente com o selo institucional (PAdES) e carimbo de tempo. Qualquer alteração invalida a assinatura."
+	textoAusente   = "—"
+	semResenha     = "Resenha não informada."
+	semVacinas     = "Nenhuma vacina registrada."
+	tituloDoc      = "PASSAPORTE EQUINO"
+	subtituloDoc   = "Certificado de identificação e genealogia"
+	nomePlataforma = "EQUINOID"
+)
+
+var (
+	corPrimaria = pdf.Color{R: 0.11, G: 0.27, B: 0.22}
+	corSuave    = pdf.Color{R: 0.93, G: 0.95, B: 0.94}
+	corBorda    = pdf.Color{R: 0.70, G: 0.75, B: 0.73}
+	corRotulo   = pdf.Color{R: 0.40, G: 0.40, B: 0.40}
+)
+
+// conteudoPassaporte dados reunidos para a emissão
+type conteudoPassaporte struct {
+	Codigo         string
+	URLVerificacao string
+	EmitidoEm      time.Time
+	Equino         *models.Equino
+	Proprietario   string
+	Arvore         *models.ArvoreGenealogica
+	Vacinas        []resumoVacina
+	Foto           []byte
+}
+
+// resumoVacina última dose de cada vacina aplicada
+type resumoVacina struct {
+	Nome        string
+	UltimaDose  time.Time
+	Doses       int
+	Responsavel string
+}
+
+// renderPassaporte monta o PDF de uma página: identificação, resenha, pedigree de quatro gerações, resumo vacinal e QR
+// code de verificação
+func renderPassaporte(c *conteudoPassaporte) ([]byte, error) {
+	doc := pdf.New(pdf.Info{
+		Title:    fmt.Sprintf("Passaporte equino - %s (%s)", c.Equino.Nome, c.Equino.Equinoid),
+		Author:   nomePlataforma,
+		Subject:  subtituloDoc,
+		Creator:  nomePlataforma,
+		Created:  c.EmitidoEm,
+		Keywords: []string{c.Equino.Equinoid, c.Codigo},
+	})
+	page := doc.AddPage()
+
+	y := drawCabecalho(page, c)
+	y = drawIdentificacao(doc, page, c, y)
+	y = drawResenha(page, c.Equino.Resenha, y)
+	y = drawPedigree(page, c.Arvore, y)
+	drawVacinas(page, c.Vacinas, y)
+	if err := drawVerificacao(page, c); err != nil {
+		return nil, err
+	}
+
+	return doc.Bytes(), nil
+}
+
+func drawCabecalho(page *pdf.Page, c *conteudoPassaporte) float64 {
+	top := page.Height() - margem
+	page.FillRect(margem, top-52, larguraUtil, 52, corPrimaria)
+	page.Text(margem+14, top-24, pdf.HelveticaBold, 18, pdf.White, tituloDoc)
+	page.Text(margem+14, top-40, pdf.Helvetica, 9, pdf.White, subtituloDoc)
+	page.TextRight(margem+larguraUtil-14, top-22, pdf.HelveticaBold, 10, pdf.White, nomePlataforma)
+	page.TextRight(margem+larguraUtil-14, top-34, pdf.Helvetica, 7, pdf.White, "Nº "+c.Codigo)
+	page.TextRight(margem+larguraUtil-14, top-44, pdf.Helvetica, 7, pdf.White, "Emitido em "+c.EmitidoEm.Format("02/01/2006 15:04")+" UTC")
+	return top - 64
+}
+
+func drawIdentificacao(doc *pdf.Document, page *pdf.Page, c *conteudoPassaporte, y float64) float64 {
+	y = drawTituloSecao(page, "Identificação", y)
+
+	const fotoLargura, fotoAltura = 120.0, 140.0
+	fotoY := y - fotoAltura
+	page.Rect(margem, fotoY, fotoLargura, fotoAltura, 0.75, corBorda)
+	if img := embedFoto(doc, c.Foto); img != nil {
+		w, h := fitInside(float64(img.Width()), float64(img.Height()), fotoLargura-4, fotoAltura-4)
+		page.DrawImage(img, margem+2+(fotoLargura-4-w)/2, fotoY+2+(fotoAltura-4-h)/2, w, h)
+	} else {
+		page.TextCentered(margem+fotoLargura/2, fotoY+fotoAltura/2, pdf.Helvetica, corpoRotulo, corRotulo, "Sem foto")
+	}
+
+	e := c.Equino
+	campos := [][2]string{
+		{"Nome", e.Nome},
+		{"EquinoId", e.Equinoid},
+		{"Microchip", e.MicrochipID},
+		{"Sexo", formatSexo(e.Sexo)},
+		{"Nascimento", formatDataPtr(e.DataNascimento)},
+		{"Raça", e.Raca},
+		{"Pelagem", e.Pelagem},
+		{"País de origem", e.PaisOrigem},
+		{"Proprietário", c.Proprietario},
+		{"Situação", string(e.Status)},
+	}
+
+	x0 := margem + fotoLargura + 16
+	colWidth := (margem + larguraUtil - x0) / 2
+	for i, campo := range campos {
+		x := x0 + float64(i%2)*colWidth
+		linha := y - 4 - float64(i/2)*28
+		valor := campo[1]
+		if valor == "" {
+			valor = textoAusente
+		}
+		page.Text(x, linha-corpoRotulo, pdf.Helvetica, corpoRotulo, corRotulo, strings.ToUpper(campo[0]))
+		page.Text(x, linha-corpoRotulo-12, pdf.HelveticaBold, corpoTexto+1, pdf.Black, pdf.Truncate(valor, pdf.HelveticaBold, corpoTexto+1, colWidth-8))
+	}
+
+	return fotoY - 12
+}
+
+func drawResenha(page *pdf.Page, resenha string, y float64) float64 {
+	y = drawTituloSecao(page, "Resenha (sinais e marcas)", y)
+	if strings.TrimSpace(resenha) == "" {
+		resenha = semResenha
+	}
+	linhas := pdf.Wrap(resenha, pdf.Helvetica, corpoTexto, larguraUtil-8)
+	if len(linhas) > 3 {
+		linhas = linhas[:3]
+		linhas[2] = pdf.Truncate(linhas[2]+" ...", pdf.Helvetica, corpoTexto, larguraUtil-8)
+	}
+	for _, linha := range linhas {
+		y -= corpoTexto + 3
+		page.Text(margem+4, y, pdf.Helvetica, corpoTexto, pdf.Black, linha)
+	}
+	return y - 14
+}
+
+// drawPedigree desenha o pedigree em colunas, uma por geração, com o pai acima da mãe em cada par
+func drawPedigree(page *pdf.Page, arvore *models.ArvoreGenealogica, y float64) float64 {
+	y = drawTituloSecao(page, fmt.Sprintf("Pedigree (%d gerações)", geracoesPedigree), y)
+
+	const altura = 272.0
+	colWidth := larguraUtil / geracoesPedigree
+	base := y - altura
+
+	var ancestrais *models.Ancestrais
+	if arvore != nil {
+		ancestrais = arvore.Ancestrais
+	}
+	for geracao := 1; geracao <= geracoesPedigree; geracao++ {
+		slots := 1 << geracao
+		slotHeight := altura / float64(slots)
+		x := margem + float64(geracao-1)*colWidth
+		for i := 0; i < slots; i++ {
+			top := y - float64(i)*slotHeight
+			node := ancestralAt(ancestrais, geracao, i)
+			drawAncestral(page, node, i%2 == 0, x+2, top-slotHeight+1, colWidth-4, slotHeight-2)
+		}
+	}
+	return base - 12
+}
+
+func drawAncestral(page *pdf.Page, node *models.AncestralNode, pai bool, x, y, w, h float64) {
+	page.Rect(x, y, w, h, 0.5, corBorda)
+	papel := "Mãe"
+	if pai {
+		papel = "Pai"
+	}
+
+	nome, equinoid := "Não registrado", ""
+	if node != nil {
+		nome, equinoid = node.Nome, node.Equinoid
+	}
+
+	size := corpoTexto
+	if h < 20 {
+		// Quarta geração: nome e EquinoId na mesma linha
+		size = corpoRotulo
+		text := nome
+		if equinoid != "" {
+			text += " · " + equinoid
+		}
+		page.Text(x+3, y+(h-size)/2+1.5, pdf.HelveticaBold, size, pdf.Black, pdf.Truncate(text, pdf.HelveticaBold, size, w-6))
+		return
+	}
+
+	mid := y + h/2
+	page.Text(x+4, mid+size/2+2, pdf.Helvetica, corpoRotulo-1, corRotulo, strings.ToUpper(papel))
+	page.Text(x+4, mid-size/2+1, pdf.HelveticaBold, size, pdf.Black, pdf.Truncate(nome, pdf.HelveticaBold, size, w-8))
+	if equinoid != "" {
+		page.Text(x+4, mid-size/2-9, pdf.Helvetica, corpoRotulo, corRotulo, equinoid)
+	}
+}
+
+// ancestralAt ancestral na posição i (0 = mais acima) da geração, seguindo pai (par) e mãe (ímpar) a partir do
+// equino
+func ancestralAt(ancestrais *models.Ancestrais, geracao, i int) *models.AncestralNode {
+	var node *models.AncestralNode
+	for nivel := geracao - 1; nivel >= 0; nivel-- {
+		if ancestrais == nil {
+			return nil
+		}
+		if (i>>nivel)&1 == 0 {
+			node = ancestrais.Pai
+		} else {
+			node = ancestrais.Mae
+		}
+		if node == nil {
+			return nil
+		}
+		ancestrais = node.Ancestrais
+	}
+	return node
+}
+
+func drawVacinas(page *pdf.Page, vacinas []resumoVacina, y float64) {
+	y = drawTituloSecao(page, "Resumo vacinal", y)
+	// A tabela ocupa a largura à esquerda do QR code
+	largura := larguraUtil - ladoQRCode - 16
+	colunas := []struct {
+		titulo  string
+		largura float64
+	}{
+		{"VACINA", largura * 0.42},
+		{"ÚLTIMA DOSE", largura * 0.18},
+		{"DOSES", largura * 0.10},
+		{"RESPONSÁVEL", largura * 0.30},
+	}
+
+	if len(vacinas) == 0 {
+		page.Text(margem+4, y-corpoTexto-3, pdf.Helvetica, corpoTexto, pdf.Black, semVacinas)
+		return
+	}
+
+	x := margem + 4
+	y -= corpoRotulo + 4
+	for _, col := range colunas {
+		page.Text(x, y, pdf.HelveticaBold, corpoRotulo, corRotulo, col.titulo)
+		x += col.largura
+	}
+	y -= 4
+	page.Line(margem, y, margem+largura, y, 0.5, corBorda)
+
+	if len(vacinas) > maxVacinasImpressas {
+		vacinas = vacinas[:maxVacinasImpressas]
+	}
+	for _, v := range vacinas {
+		y -= corpoTexto + 3
+		valores := []string{v.Nome, v.UltimaDose.Format(formatoData), fmt.Sprintf("%d", v.Doses), v.Responsavel}
+		x = margem + 4
+		for i, col := range colunas {
+			valor := valores[i]
+			if valor == "" {
+				valor = textoAusente
+			}
+			page.Text(x, y, pdf.Helvetica, corpoTexto-1, pdf.Black, pdf.Truncate(valor, pdf.Helvetica, corpoTexto-1, col.largura-6))
+			x += col.largura
+		}
+	}
+}
+
+// drawVerificacao QR code vetorial com o endereço público de verificação, no canto inferior direito, e o rodapé
+func drawVerificacao(page *pdf.Page, c *conteudoPassaporte) error {
+	qr, err := qrcode.New(c.URLVerificacao, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("failed to encode verification QR code: %w", err)
+	}
+	bitmap := qr.Bitmap()
+
+	x := margem + larguraUtil - ladoQRCode
+	y := margem + 34
+	module := ladoQRCode / float64(len(bitmap))
+	for row, cells := range bitmap {
+		for col, dark := range cells {
+			if dark {
+				page.FillRect(x+float64(col)*module, y+ladoQRCode-float64(row+1)*module, module, module, pdf.Black)
+			}
+		}
+	}
+	page.TextCentered(x+ladoQRCode/2, y-8, pdf.HelveticaBold, corpoRotulo, corPrimaria, "Verifique a autenticidade")
+
+	page.Line(margem, margem+18, margem+larguraUtil, margem+18, 0.5, corBorda)
+	page.Text(margem, margem+8, pdf.Helvetica, corpoRotulo-1, corRotulo, textoRodape)
+	page.Text(margem, margem, pdf.Helvetica, corpoRotulo-1, corRotulo, pdf.Truncate(c.URLVerificacao, pdf.Helvetica, corpoRotulo-1, larguraUtil))
+	return nil
+}
+
+func drawTituloSecao(page *pdf.Page, titulo string, y float64) float64 {
+	page.FillRect(margem, y-alturaSecao, larguraUtil, alturaSecao, corSuave)
+	page.Text(margem+6, y-alturaSecao+5, pdf.HelveticaBold, corpoTexto, corPrimaria, strings.ToUpper(titulo))
+	return y - alturaSecao - 4
+}
+
+// embedFoto incorpora a foto de perfil; formatos sem decodificador (WebP) são omitidos
+func embedFoto(doc *pdf.Document, foto []byte) *pdf.Image {
+	if len(foto) == 0 {
+		return nil
+	}
+	img, err := doc.AddImage(foto)
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+// fitInside dimensões da imagem escalada para caber na caixa mantendo a proporção
+func fitInside(w, h, maxW, maxH float64) (float64, float64) {
+	scale := maxW / w
+	if h*scale > maxH {
+		scale = maxH / h
+	}
+	return w * scale, h * scale
+}
+
+func formatSexo(sexo models.SexoEquino) string {
+	switch sexo {
+	case models.SexoMacho:
+		return "Macho"
+	case models.SexoFemea:
+		return "Fêmea"
+	}
+	return string(sexo)
+}
+
+func formatDataPtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(formatoData)
+}
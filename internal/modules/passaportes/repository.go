@@ -0,0 +1,141 @@
+package passaportes
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// maxVacinas doses de vacina lidas para o resumo vacinal
+const maxVacinas = 200
+
+type Repository interface {
+	FindByCodigo(ctx context.Context, codigo string) (*models.PassaporteEquino, error)
+	FindByHash(ctx context.Context, hash string) (*models.PassaporteEquino, error)
+	FindByEquinoID(ctx context.Context, equinoID uint) ([]*models.PassaporteEquino, error)
+	Create(ctx context.Context, passaporte *models.PassaporteEquino) error
+	Revogar(ctx context.Context, id uint, userID uint, motivo string, at time.Time) error
+
+	FindVacinas(ctx context.Context, equinoID uint) ([]*models.Evento, error)
+	FindFotoPerfil(ctx context.Context, equinoID uint) (*models.DocumentoVersao, error)
+	FindNomeUsuario(ctx context.Context, userID uint) (string, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByCodigo(ctx context.Context, codigo string) (*models.PassaporteEquino, error) {
+	var passaporte models.PassaporteEquino
+	if err := r.db.WithContext(ctx).Where("codigo = ?", codigo).First(&passaporte).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "passaporte", Message: "passaporte não encontrado", ID: codigo}
+		}
+		return nil, apperrors.NewDatabaseError("find_passaporte", "erro ao buscar passaporte", err)
+	}
+	return &passaporte, nil
+}
+
+func (r *repository) FindByHash(ctx context.Context, hash string) (*models.PassaporteEquino, error) {
+	var passaporte models.PassaporteEquino
+	if err := r.db.WithContext(ctx).Where("hash = ?", hash).First(&passaporte).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "passaporte", Message: "documento não corresponde a nenhum passaporte emitido", ID: hash}
+		}
+		return nil, apperrors.NewDatabaseError("find_passaporte_hash", "erro ao buscar passaporte pelo hash", err)
+	}
+	return &passaporte, nil
+}
+
+func (r *repository) FindByEquinoID(ctx context.Context, equinoID uint) ([]*models.PassaporteEquino, error) {
+	var passaportes []*models.PassaporteEquino
+	if err := r.db.WithContext(ctx).Where("equino_id = ?", equinoID).Order("emitido_em DESC").Find(&passaportes).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("find_passaportes_equino", "erro ao listar passaportes do equino", err)
+	}
+	return passaportes, nil
+}
+
+// Create registra a nova emissão e marca as emissões vigentes do equino como substituídas
+func (r *repository) Create(ctx context.Context, passaporte *models.PassaporteEquino) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.PassaporteEquino{}).
+			Where("equino_id = ? AND status = ?", passaporte.EquinoID, models.PassaporteValido).
+			Update("status", models.PassaporteSubstituido).Error
+		if err != nil {
+			return apperrors.NewDatabaseError("substituir_passaportes", "erro ao substituir passaportes anteriores", err)
+		}
+		if err := tx.Create(passaporte).Error; err != nil {
+			return apperrors.NewDatabaseError("create_passaporte", "erro ao registrar passaporte", err)
+		}
+		return nil
+	})
+}
+
+// Revogar cancela o passaporte somente se ele ainda não foi revogado
+func (r *repository) Revogar(ctx context.Context, id uint, userID uint, motivo string, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.PassaporteEquino{}).
+		Where("id = ? AND status <> ?", id, models.PassaporteRevogado).
+		Updates(map[string]interface{}{
+			"status":           models.PassaporteRevogado,
+			"revogado_em":      at,
+			"revogado_por":     userID,
+			"motivo_revogacao": motivo,
+		})
+	if result.Error != nil {
+		return apperrors.NewDatabaseError("revogar_passaporte", "erro ao revogar passaporte", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.ConflictError{Resource: "passaporte", Message: "passaporte já revogado", Value: id}
+	}
+	return nil
+}
+
+func (r *repository) FindVacinas(ctx context.Context, equinoID uint) ([]*models.Evento, error) {
+	var vacinas []*models.Evento
+	err := r.db.WithContext(ctx).
+		Preload("Veterinario").
+		Where("equino_id = ? AND tipo_evento = ?", equinoID, models.TipoEventoVacina).
+		Order("data_evento DESC").
+		Limit(maxVacinas).
+		Find(&vacinas).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("find_vacinas", "erro ao buscar vacinas do equino", err)
+	}
+	return vacinas, nil
+}
+
+// FindFotoPerfil versão atual da foto de perfil mais recente do cofre; nil quando o equino não tem foto
+func (r *repository) FindFotoPerfil(ctx context.Context, equinoID uint) (*models.DocumentoVersao, error) {
+	var versao models.DocumentoVersao
+	err := r.db.WithContext(ctx).
+		Joins("JOIN documentos ON documentos.id = documento_versoes.documento_id AND documentos.versao_atual = documento_versoes.numero").
+		Where("documentos.equino_id = ? AND documentos.categoria = ? AND documentos.deleted_at IS NULL", equinoID, models.CategoriaFotoPerfil).
+		Order("documentos.created_at DESC").
+		First(&versao).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperrors.NewDatabaseError("find_foto_perfil", "erro ao buscar foto de perfil", err)
+	}
+	return &versao, nil
+}
+
+func (r *repository) FindNomeUsuario(ctx context.Context, userID uint) (string, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Select("id", "name").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", apperrors.NewDatabaseError("find_usuario", "erro ao buscar proprietário", err)
+	}
+	return user.Name, nil
+}
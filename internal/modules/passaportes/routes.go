@@ -0,0 +1,25 @@
+package passaportes
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	equinos := rg.Group("/equinos")
+	equinos.Use(authMiddleware)
+	{
+		equinos.GET("/:equinoid/passaportes", handler.ListByEquino)
+		equinos.POST("/:equinoid/passaportes", handler.Emitir)
+	}
+
+	passaportes := rg.Group("/passaportes")
+	passaportes.Use(authMiddleware)
+	{
+		passaportes.GET("/:codigo/pdf", handler.Download)
+		passaportes.POST("/:codigo/revogar", handler.Revogar)
+	}
+
+	// Verificação pública: endereço do QR code e conferência de arquivos apresentados
+	rg.GET("/public/passaportes/:codigo", handler.VerificarCodigo)
+	rg.POST("/public/passaportes/verificar", handler.VerificarArquivo)
+}
@@ -0,0 +1,510 @@
+package passaportes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/security/pki"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/equinoid/backend/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// maxFotoSize fotos maiores são omitidas do passaporte quando o cofre não tem miniatura
+const maxFotoSize = 4 << 20
+
+// EquinoRepository busca o equino do passaporte
+type EquinoRepository interface {
+	FindByEquinoid(ctx context.Context, equinoid string) (*models.Equino, error)
+}
+
+// PedigreeProvider monta a árvore genealógica do equino
+type PedigreeProvider interface {
+	GetArvoreGenealogica(ctx context.Context, equinoid string, geracoes int) (*models.ArvoreGenealogica, error)
+}
+
+// AccessChecker decide se um usuário pode exercer um escopo sobre um equino (proprietário, admin ou acesso delegado)
+type AccessChecker interface {
+	CheckEquinoAccess(ctx context.Context, userID uint, userType string, equinoid string, escopo string) error
+}
+
+// Sealer assina os passaportes com o selo institucional e verifica as assinaturas PAdES
+type Sealer interface {
+	SealPDF(ctx context.Context, pdf []byte, opts pki.PDFSignatureOptions) ([]byte, error)
+	VerifyPDF(ctx context.Context, pdf []byte) ([]pki.SignatureVerification, error)
+}
+
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+type Service interface {
+	Emitir(ctx context.Context, equinoid string, userID uint, userType string) (*models.PassaporteEquino, error)
+	ListByEquino(ctx context.Context, equinoid string, userID uint, userType string) ([]*models.PassaporteEquino, error)
+	OpenPDF(ctx context.Context, codigo string, userID uint, userType string) (io.ReadCloser, *models.PassaporteEquino, error)
+	Revogar(ctx context.Context, codigo string, userID uint, userType string, motivo string) (*models.PassaporteEquino, error)
+
+	VerificarCodigo(ctx context.Context, codigo string) (*models.VerificacaoPassaporte, error)
+	VerificarArquivo(ctx context.Context, arquivo []byte) (*models.VerificacaoPassaporte, error)
+}
+
+type service struct {
+	repo          Repository
+	equinoRepo    EquinoRepository
+	pedigree      PedigreeProvider
+	accessChecker AccessChecker
+	sealer        Sealer
+	store         storage.Storage
+	audit         AuditLogger
+	verifyBaseURL string
+	logger        *logging.Logger
+}
+
+// NewService cria o serviço de passaportes; sem armazenamento configurado ou sem selo institucional a emissão responde
+// como indisponível
+func NewService(repo Repository, equinoRepo EquinoRepository, pedigree PedigreeProvider, accessChecker AccessChecker, sealer Sealer, store storage.Storage, audit AuditLogger, cfg *config.Config, logger *logging.Logger) Service {
+	return &service{
+		repo:          repo,
+		equinoRepo:    equinoRepo,
+		pedigree:      pedigree,
+		accessChecker: accessChecker,
+		sealer:        sealer,
+		store:         store,
+		audit:         audit,
+		verifyBaseURL: strings.TrimRight(cfg.PassportVerifyBaseURL, "/"),
+		logger:        logger,
+	}
+}
+
+// Emitir gera o PDF com identificação, pedigree de quatro gerações e resumo vacinal, assina com o selo institucional e
+// substitui o passaporte vigente do equino
+func (s *service) Emitir(ctx context.Context, equinoid string, userID uint, userType string) (*models.PassaporteEquino, error) {
+	if s.store == nil {
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos não configurado", nil)
+	}
+
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if userType != string(models.UserTypeAdmin) && equino.ProprietarioID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o proprietário pode emitir o passaporte do equino"}).WithAction("emitir_passaporte", "equino")
+	}
+
+	conteudo, err := s.reunirConteudo(ctx, equino)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := renderPassaporte(conteudo)
+	if err != nil {
+		s.logger.LogError(err, "PassaporteService.Emitir", logging.Fields{"equinoid": equinoid, "action": "render"})
+		return nil, err
+	}
+
+	sealed, err := s.sealer.SealPDF(ctx, rendered, pki.PDFSignatureOptions{
+		Reason:      fmt.Sprintf("Passaporte equino %s", conteudo.Codigo),
+		Location:    nomePlataforma,
+		SigningTime: conteudo.EmitidoEm,
+	})
+	if err != nil {
+		if errors.Is(err, pki.ErrSealUnavailable) || errors.Is(err, pki.ErrCANotInitialized) {
+			return nil, apperrors.NewBusinessError("seal_unavailable", "selo institucional indisponível para assinar o passaporte", nil)
+		}
+		s.logger.LogError(err, "PassaporteService.Emitir", logging.Fields{"equinoid": equinoid, "action": "seal"})
+		return nil, err
+	}
+
+	digest := sha256.Sum256(sealed)
+	passaporte := &models.PassaporteEquino{
+		Codigo:         conteudo.Codigo,
+		EquinoID:       equino.ID,
+		Equinoid:       equino.Equinoid,
+		Status:         models.PassaporteValido,
+		Hash:           hex.EncodeToString(digest[:]),
+		Tamanho:        int64(len(sealed)),
+		StorageBackend: s.store.Name(),
+		StorageKey:     fmt.Sprintf("equinos/%s/passaportes/%s.pdf", equino.Equinoid, conteudo.Codigo),
+		URLVerificacao: conteudo.URLVerificacao,
+		EmitidoPor:     userID,
+		EmitidoEm:      conteudo.EmitidoEm,
+	}
+	// Conferência da assinatura recém-aplicada; o hash da assinatura permite localizar a emissão pelo CMS
+	verified, err := s.sealer.VerifyPDF(ctx, sealed)
+	if err != nil || len(verified) == 0 || !verified[len(verified)-1].Valid() {
+		s.logger.LogError(fmt.Errorf("sealed passport failed verification: %v", err), "PassaporteService.Emitir", logging.Fields{"equinoid": equinoid})
+		return nil, apperrors.NewBusinessError("seal_unavailable", "a assinatura do passaporte não pôde ser validada", nil)
+	}
+	passaporte.SignatureHash = verified[len(verified)-1].SignatureHash
+	passaporte.CertificadoSerial = verified[len(verified)-1].SignerSerial
+
+	if err := s.store.Put(ctx, passaporte.StorageKey, sealed, "application/pdf"); err != nil {
+		s.logger.LogError(err, "PassaporteService.Emitir", logging.Fields{"backend": s.store.Name(), "equinoid": equinoid})
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos indisponível", nil)
+	}
+	if err := s.repo.Create(ctx, passaporte); err != nil {
+		s.logger.LogError(err, "PassaporteService.Emitir", logging.Fields{"equinoid": equinoid})
+		if delErr := s.store.Delete(ctx, passaporte.StorageKey); delErr != nil {
+			s.logger.LogError(delErr, "PassaporteService.Emitir", logging.Fields{"key": passaporte.StorageKey})
+		}
+		return nil, err
+	}
+
+	s.recordChange(ctx, passaporte.Codigo, "issue", nil, passaporte)
+	s.logger.WithFields(logging.Fields{
+		"equinoid": equinoid,
+		"codigo":   passaporte.Codigo,
+		"hash":     passaporte.Hash,
+	}).Info("Passaporte equino emitido")
+
+	return passaporte, nil
+}
+
+func (s *service) ListByEquino(ctx context.Context, equinoid string, userID uint, userType string) ([]*models.PassaporteEquino, error) {
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accessChecker.CheckEquinoAccess(ctx, userID, userType, equinoid, models.EscopoLerSaude); err != nil {
+		return nil, err
+	}
+
+	passaportes, err := s.repo.FindByEquinoID(ctx, equino.ID)
+	if err != nil {
+		s.logger.LogError(err, "PassaporteService.ListByEquino", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+	return passaportes, nil
+}
+
+// OpenPDF PDF assinado para o proprietário e profissionais com acesso de leitura ao prontuário (inclui o resumo
+// vacinal)
+func (s *service) OpenPDF(ctx context.Context, codigo string, userID uint, userType string) (io.ReadCloser, *models.PassaporteEquino, error) {
+	passaporte, err := s.repo.FindByCodigo(ctx, codigo)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.accessChecker.CheckEquinoAccess(ctx, userID, userType, passaporte.Equinoid, models.EscopoLerSaude); err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := s.open(ctx, passaporte)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, passaporte, nil
+}
+
+func (s *service) Revogar(ctx context.Context, codigo string, userID uint, userType string, motivo string) (*models.PassaporteEquino, error) {
+	motivo = strings.TrimSpace(motivo)
+	if motivo == "" {
+		return nil, &apperrors.ValidationError{Field: "motivo", Message: "motivo da revogação é obrigatório"}
+	}
+
+	passaporte, err := s.repo.FindByCodigo(ctx, codigo)
+	if err != nil {
+		return nil, err
+	}
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, passaporte.Equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if userType != string(models.UserTypeAdmin) && equino.ProprietarioID != userID {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o proprietário pode revogar o passaporte"}).WithAction("revogar_passaporte", "equino")
+	}
+
+	if err := s.repo.Revogar(ctx, passaporte.ID, userID, motivo, time.Now()); err != nil {
+		if !apperrors.IsConflict(err) {
+			s.logger.LogError(err, "PassaporteService.Revogar", logging.Fields{"codigo": codigo})
+		}
+		return nil, err
+	}
+
+	revogado, err := s.repo.FindByCodigo(ctx, codigo)
+	if err != nil {
+		return nil, err
+	}
+	s.recordChange(ctx, codigo, "revoke", passaporte, revogado)
+	return revogado, nil
+}
+
+// VerificarCodigo verificação pública pelo código do QR code: confere o arquivo guardado contra o hash registrado e
+// valida a assinatura do selo
+func (s *service) VerificarCodigo(ctx context.Context, codigo string) (*models.VerificacaoPassaporte, error) {
+	if _, err := uuid.Parse(codigo); err != nil {
+		return nil, &apperrors.ValidationError{Field: "codigo", Message: "código de passaporte inválido", Value: codigo}
+	}
+	passaporte, err := s.repo.FindByCodigo(ctx, codigo)
+	if err != nil {
+		return nil, err
+	}
+	if s.store == nil {
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos não configurado", nil)
+	}
+
+	reader, err := s.open(ctx, passaporte)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	arquivo, err := io.ReadAll(reader)
+	if err != nil {
+		s.logger.LogError(err, "PassaporteService.VerificarCodigo", logging.Fields{"codigo": codigo})
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos indisponível", nil)
+	}
+
+	verificacao, err := s.verificar(ctx, passaporte, arquivo)
+	if err != nil {
+		return nil, err
+	}
+	if !verificacao.Integro {
+		// O arquivo guardado não corresponde ao emitido: indício de adulteração no armazenamento
+		s.logger.LogSecurityEvent("passport_storage_mismatch", fmt.Sprintf("Passaporte %s diverge do hash registrado", codigo), 0, "")
+	}
+	return verificacao, nil
+}
+
+// VerificarArquivo verificação pública de um PDF apresentado: localiza a emissão pelo hash e valida as assinaturas
+func (s *service) VerificarArquivo(ctx context.Context, arquivo []byte) (*models.VerificacaoPassaporte, error) {
+	if len(arquivo) == 0 {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: "arquivo vazio"}
+	}
+	if !bytes.HasPrefix(arquivo, []byte("%PDF-")) {
+		return nil, &apperrors.ValidationError{Field: "arquivo", Message: "o passaporte deve ser enviado em PDF"}
+	}
+
+	digest := sha256.Sum256(arquivo)
+	passaporte, err := s.repo.FindByHash(ctx, hex.EncodeToString(digest[:]))
+	if err != nil && !apperrors.IsNotFound(err) {
+		s.logger.LogError(err, "PassaporteService.VerificarArquivo", nil)
+		return nil, err
+	}
+	return s.verificar(ctx, passaporte, arquivo)
+}
+
+// verificar compõe o resultado: autêntico quando o arquivo é idêntico a uma emissão registrada, todas as assinaturas
+// conferem e o passaporte continua vigente
+func (s *service) verificar(ctx context.Context, passaporte *models.PassaporteEquino, arquivo []byte) (*models.VerificacaoPassaporte, error) {
+	digest := sha256.Sum256(arquivo)
+	verificacao := &models.VerificacaoPassaporte{
+		Hash:         hex.EncodeToString(digest[:]),
+		Assinaturas:  []models.SignatureVerificationResult{},
+		VerificadoEm: time.Now(),
+	}
+
+	verified, err := s.sealer.VerifyPDF(ctx, arquivo)
+	if err != nil && !errors.Is(err, pki.ErrPDFNotSigned) && !errors.Is(err, pki.ErrInvalidPDF) {
+		s.logger.LogError(err, "PassaporteService.verificar", logging.Fields{"hash": verificacao.Hash})
+		return nil, apperrors.NewBusinessError("verification_unavailable", "não foi possível verificar as assinaturas do documento", nil)
+	}
+
+	assinaturasValidas := len(verified) > 0
+	for i := range verified {
+		// Passaportes assinados por um certificado de selo já renovado: o registro da emissão atesta o certificado
+		if passaporte != nil && verified[i].CertificateStatus == pki.CertificateStatusUnknown && verified[i].SignerSerial == passaporte.CertificadoSerial {
+			verified[i].CertificateStatus = models.CertificateStatusValid
+		}
+		result := signatureResult(&verified[i])
+		assinaturasValidas = assinaturasValidas && result.Valid
+		verificacao.Assinaturas = append(verificacao.Assinaturas, result)
+	}
+
+	if passaporte == nil {
+		verificacao.Mensagem = "Documento não corresponde a nenhum passaporte emitido pela plataforma"
+		return verificacao, nil
+	}
+
+	emitidoEm := passaporte.EmitidoEm
+	verificacao.Registrado = true
+	verificacao.Integro = verificacao.Hash == passaporte.Hash
+	verificacao.Status = passaporte.Status
+	verificacao.Codigo = passaporte.Codigo
+	verificacao.Equinoid = passaporte.Equinoid
+	verificacao.EmitidoEm = &emitidoEm
+	verificacao.RevogadoEm = passaporte.RevogadoEm
+	if equino, err := s.equinoRepo.FindByEquinoid(ctx, passaporte.Equinoid); err == nil {
+		verificacao.NomeEquino = equino.Nome
+	}
+
+	verificacao.Autentico = verificacao.Integro && assinaturasValidas && passaporte.Status == models.PassaporteValido
+	switch {
+	case !verificacao.Integro:
+		verificacao.Mensagem = "O arquivo difere do passaporte emitido"
+	case !assinaturasValidas:
+		verificacao.Mensagem = "A assinatura do selo institucional não confere"
+	case passaporte.Status == models.PassaporteRevogado:
+		verificacao.Mensagem = "Passaporte revogado"
+	case passaporte.Status == models.PassaporteSubstituido:
+		verificacao.Mensagem = "Passaporte substituído por uma emissão mais recente"
+	default:
+		verificacao.Mensagem = "Passaporte autêntico e vigente"
+	}
+	return verificacao, nil
+}
+
+// reunirConteudo dados impressos no passaporte; a foto é opcional e falhas ao lê-la não impedem a emissão
+func (s *service) reunirConteudo(ctx context.Context, equino *models.Equino) (*conteudoPassaporte, error) {
+	arvore, err := s.pedigree.GetArvoreGenealogica(ctx, equino.Equinoid, geracoesPedigree)
+	if err != nil {
+		s.logger.LogError(err, "PassaporteService.reunirConteudo", logging.Fields{"equinoid": equino.Equinoid, "action": "pedigree"})
+		return nil, err
+	}
+
+	eventos, err := s.repo.FindVacinas(ctx, equino.ID)
+	if err != nil {
+		s.logger.LogError(err, "PassaporteService.reunirConteudo", logging.Fields{"equinoid": equino.Equinoid, "action": "vacinas"})
+		return nil, err
+	}
+
+	proprietario, err := s.repo.FindNomeUsuario(ctx, equino.ProprietarioID)
+	if err != nil {
+		s.logger.LogError(err, "PassaporteService.reunirConteudo", logging.Fields{"equinoid": equino.Equinoid, "action": "proprietario"})
+		return nil, err
+	}
+
+	codigo := uuid.New().String()
+	return &conteudoPassaporte{
+		Codigo:         codigo,
+		URLVerificacao: s.verifyBaseURL + "/" + codigo,
+		EmitidoEm:      time.Now().UTC().Truncate(time.Second),
+		Equino:         equino,
+		Proprietario:   proprietario,
+		Arvore:         arvore,
+		Vacinas:        resumirVacinas(eventos),
+		Foto:           s.loadFoto(ctx, equino),
+	}, nil
+}
+
+// loadFoto foto de perfil do cofre, preferindo a miniatura
+func (s *service) loadFoto(ctx context.Context, equino *models.Equino) []byte {
+	versao, err := s.repo.FindFotoPerfil(ctx, equino.ID)
+	if err != nil {
+		s.logger.LogError(err, "PassaporteService.loadFoto", logging.Fields{"equinoid": equino.Equinoid})
+		return nil
+	}
+	if versao == nil {
+		return nil
+	}
+
+	key := versao.StorageKey
+	if versao.ThumbnailKey != nil {
+		key = *versao.ThumbnailKey
+	} else if versao.Tamanho > maxFotoSize {
+		return nil
+	}
+
+	reader, err := s.store.Get(ctx, key)
+	if err != nil {
+		s.logger.Warnf("Foto de perfil do equino %s indisponível para o passaporte: %v", equino.Equinoid, err)
+		return nil
+	}
+	defer reader.Close()
+	foto, err := io.ReadAll(io.LimitReader(reader, maxFotoSize))
+	if err != nil {
+		s.logger.Warnf("Foto de perfil do equino %s indisponível para o passaporte: %v", equino.Equinoid, err)
+		return nil
+	}
+	return foto
+}
+
+func (s *service) open(ctx context.Context, passaporte *models.PassaporteEquino) (io.ReadCloser, error) {
+	if s.store == nil {
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos não configurado", nil)
+	}
+	reader, err := s.store.Get(ctx, passaporte.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "arquivo", Message: "arquivo do passaporte não encontrado no armazenamento", ID: passaporte.Codigo}
+		}
+		s.logger.LogError(err, "PassaporteService.open", logging.Fields{"backend": s.store.Name(), "codigo": passaporte.Codigo})
+		return nil, apperrors.NewBusinessError("storage_unavailable", "armazenamento de documentos indisponível", nil)
+	}
+	return reader, nil
+}
+
+func (s *service) recordChange(ctx context.Context, key, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, "passaporte", key, operation, before, after); err != nil {
+		s.logger.LogError(err, "PassaporteService.recordChange", logging.Fields{"codigo": key, "operation": operation})
+	}
+}
+
+// resumirVacinas uma linha por vacina com a dose mais recente, ordenadas da aplicação mais recente para a mais antiga.
+// Os eventos chegam ordenados por data decrescente
+func resumirVacinas(eventos []*models.Evento) []resumoVacina {
+	indice := make(map[string]int)
+	var resumo []resumoVacina
+	for _, evento := range eventos {
+		nome := strings.TrimSpace(evento.NomeEvento)
+		if nome == "" {
+			nome = strings.TrimSpace(evento.Descricao)
+		}
+		if nome == "" {
+			nome = "Vacina"
+		}
+		chave := strings.ToLower(nome)
+
+		if i, ok := indice[chave]; ok {
+			resumo[i].Doses++
+			continue
+		}
+		responsavel := ""
+		if evento.Veterinario != nil {
+			responsavel = evento.Veterinario.Name
+		}
+		indice[chave] = len(resumo)
+		resumo = append(resumo, resumoVacina{Nome: nome, UltimaDose: evento.DataEvento, Doses: 1, Responsavel: responsavel})
+	}
+
+	sort.SliceStable(resumo, func(i, j int) bool { return resumo[i].UltimaDose.After(resumo[j].UltimaDose) })
+	return resumo
+}
+
+func signatureResult(v *pki.SignatureVerification) models.SignatureVerificationResult {
+	result := models.SignatureVerificationResult{
+		Valid:               v.Valid(),
+		SignatureValid:      v.SignatureValid,
+		ChainValid:          v.ChainValid,
+		CertificateStatus:   v.CertificateStatus,
+		Signer:              v.Signer,
+		SignerSerial:        v.SignerSerial,
+		Issuer:              v.Issuer,
+		SigningTime:         v.SigningTime,
+		CoversWholeDocument: v.CoversWholeDocument,
+		Reason:              v.Error,
+	}
+	if v.Timestamp != nil || v.TimestampError != "" {
+		result.Timestamp = &models.TimestampVerification{Error: v.TimestampError}
+		if info := v.Timestamp; info != nil {
+			timestampTime := info.Time
+			result.Timestamp.Valid = info.Trusted
+			result.Timestamp.Trusted = info.Trusted
+			result.Timestamp.Authority = info.Authority
+			result.Timestamp.Time = &timestampTime
+			result.Timestamp.SerialNumber = info.SerialNumber
+			result.Timestamp.Policy = info.Policy
+			if result.Timestamp.Error == "" {
+				result.Timestamp.Error = info.TrustError
+			}
+		}
+	}
+	if !v.CoversWholeDocument {
+		result.Valid = false
+		result.Reason = strings.TrimSpace(result.Reason + " documento alterado após esta assinatura")
+	}
+	return result
+}
@@ -0,0 +1,640 @@
+package privacidade
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/security/compliance"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateRequest godoc
+// @Summary Registrar solicitação do titular
+// @Description Registra pedido de acesso, portabilidade ou exclusão de dados pessoais (LGPD Art. 18) com prazo de resposta de 15 dias. Acesso e portabilidade geram um pacote assinado; exclusões passam por análise
+// @Tags Privacidade
+// @Accept json
+// @Produce json
+// @Param request body models.CriarSolicitacaoPrivacidadeRequest true "Solicitação"
+// @Success 202 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/me/privacy/requests [post]
+// @Security BearerAuth
+func (h *Handler) CreateRequest(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.CriarSolicitacaoPrivacidadeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	resp, err := h.service.CreateRequest(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar solicitação")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Solicitação registrada; prazo de resposta até %s", resp.DueAt.Format("02/01/2006")),
+		Timestamp: time.Now(),
+		Data:      resp,
+	})
+}
+
+// ListMyRequests godoc
+// @Summary Listar minhas solicitações de privacidade
+// @Description Lista as solicitações do titular autenticado com status, prazo e disponibilidade do pacote
+// @Tags Privacidade
+// @Produce json
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/me/privacy/requests [get]
+// @Security BearerAuth
+func (h *Handler) ListMyRequests(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	page, limit := parsePagination(c)
+
+	requests, total, err := h.service.ListMyRequests(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar solicitações")
+		return
+	}
+
+	h.respondPage(c, fmt.Sprintf("Solicitações de privacidade (total: %d)", total), requests, page, limit, total)
+}
+
+// GetMyRequest godoc
+// @Summary Consultar solicitação de privacidade
+// @Description Retorna uma solicitação do titular autenticado
+// @Tags Privacidade
+// @Produce json
+// @Param id path int true "ID da solicitação"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /users/me/privacy/requests/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetMyRequest(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	request, err := h.service.GetMyRequest(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondError(c, err, "Erro ao consultar solicitação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Solicitação de privacidade",
+		Timestamp: time.Now(),
+		Data:      request,
+	})
+}
+
+// DownloadExport godoc
+// @Summary Baixar pacote de dados
+// @Description Baixa o pacote ZIP/JSON assinado de uma solicitação de acesso ou portabilidade concluída. O hash SHA-256 e a assinatura HMAC do manifesto seguem nos cabeçalhos X-Export-Checksum e X-Export-Signature
+// @Tags Privacidade
+// @Produce application/zip
+// @Produce json
+// @Param id path int true "ID da solicitação"
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /users/me/privacy/requests/{id}/download [get]
+// @Security BearerAuth
+func (h *Handler) DownloadExport(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	file, err := h.service.OpenExport(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondError(c, err, "Erro ao obter pacote de dados")
+		return
+	}
+
+	c.Header("Content-Type", file.ContentType)
+	c.Header("X-Export-Checksum", file.Checksum)
+	c.Header("X-Export-Signature", file.Signature)
+	c.FileAttachment(file.Path, file.Filename)
+}
+
+// ListQueue godoc
+// @Summary Fila de solicitações de titulares
+// @Description Lista solicitações LGPD ordenadas pelo prazo legal, com filtros por tipo, status e vencidas (apenas admin)
+// @Tags Privacidade
+// @Produce json
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Param tipo query string false "Tipo (data_access, data_portability, data_deletion)"
+// @Param status query string false "Status (pending, processing, completed, rejected, failed)"
+// @Param vencidas query bool false "Somente solicitações com prazo vencido"
+// @Param user_id query int false "ID do titular"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/privacy/requests [get]
+// @Security BearerAuth
+func (h *Handler) ListQueue(c *gin.Context) {
+	page, limit := parsePagination(c)
+
+	filter := &compliance.RequestFilter{
+		RequestType: c.Query("tipo"),
+		Status:      c.Query("status"),
+	}
+	if raw := c.Query("vencidas"); raw != "" {
+		overdue, err := strconv.ParseBool(raw)
+		if err != nil {
+			h.respondError(c, &apperrors.ValidationError{Field: "vencidas", Message: "vencidas deve ser true ou false", Value: raw}, "Filtros inválidos")
+			return
+		}
+		filter.OverdueOnly = overdue
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			h.respondError(c, &apperrors.ValidationError{Field: "user_id", Message: "user_id inválido", Value: raw}, "Filtros inválidos")
+			return
+		}
+		uid := uint(id)
+		filter.UserID = &uid
+	}
+
+	requests, total, err := h.service.ListQueue(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar fila de privacidade")
+		return
+	}
+
+	h.respondPage(c, fmt.Sprintf("Fila de solicitações de titulares (total: %d)", total), requests, page, limit, total)
+}
+
+// ProcessRequest godoc
+// @Summary Atender solicitação de titular
+// @Description Executa a solicitação: exclusões anonimizam o titular preservando a genealogia usada por outros proprietários; acesso e portabilidade têm o pacote gerado novamente (apenas admin)
+// @Tags Privacidade
+// @Produce json
+// @Param id path int true "ID da solicitação"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/privacy/requests/{id}/process [post]
+// @Security BearerAuth
+func (h *Handler) ProcessRequest(c *gin.Context) {
+	adminID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	request, err := h.service.Process(c.Request.Context(), id, adminID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao processar solicitação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Solicitação atendida",
+		Timestamp: time.Now(),
+		Data:      request,
+	})
+}
+
+// RejectRequest godoc
+// @Summary Recusar solicitação de titular
+// @Description Recusa a solicitação com justificativa (ex.: obrigação legal de guarda) (apenas admin)
+// @Tags Privacidade
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da solicitação"
+// @Param request body models.RecusarSolicitacaoPrivacidadeRequest true "Justificativa"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/privacy/requests/{id}/reject [post]
+// @Security BearerAuth
+func (h *Handler) RejectRequest(c *gin.Context) {
+	adminID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RecusarSolicitacaoPrivacidadeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	request, err := h.service.Reject(c.Request.Context(), id, adminID, req.Motivo)
+	if err != nil {
+		h.respondError(c, err, "Erro ao recusar solicitação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Solicitação recusada",
+		Timestamp: time.Now(),
+		Data:      request,
+	})
+}
+
+// GetStats godoc
+// @Summary Estatísticas de privacidade
+// @Description Totais de solicitações por tipo e status, em aberto e vencidas (apenas admin)
+// @Tags Privacidade
+// @Produce json
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/privacy/stats [get]
+// @Security BearerAuth
+func (h *Handler) GetStats(c *gin.Context) {
+	stats, err := h.service.GetStats(c.Request.Context())
+	if err != nil {
+		h.respondError(c, err, "Erro ao calcular estatísticas de privacidade")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Estatísticas de privacidade",
+		Timestamp: time.Now(),
+		Data:      stats,
+	})
+}
+
+// ListConsents godoc
+// @Summary Meus consentimentos
+// @Description Situação do consentimento do titular para cada finalidade (perfil social público, compartilhamento com terceiros, KYC de tokenização), com a versão aceita e a versão vigente da política
+// @Tags Privacidade
+// @Produce json
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/me/privacy/consents [get]
+// @Security BearerAuth
+func (h *Handler) ListConsents(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	consents, err := h.service.ListConsents(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar consentimentos")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Consentimentos do titular",
+		Timestamp: time.Now(),
+		Data:      consents,
+	})
+}
+
+// ConsentHistory godoc
+// @Summary Histórico de consentimentos
+// @Description Lista aceites, revogações, expirações e substituições de consentimento do titular, do mais recente para o mais antigo
+// @Tags Privacidade
+// @Produce json
+// @Param finalidade query string false "Finalidade (perfil_social_publico, compartilhamento_terceiros, kyc_tokenizacao)"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/me/privacy/consents/history [get]
+// @Security BearerAuth
+func (h *Handler) ConsentHistory(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	page, limit := parsePagination(c)
+
+	records, total, err := h.service.ConsentHistory(c.Request.Context(), userID, c.Query("finalidade"), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao consultar histórico de consentimentos")
+		return
+	}
+
+	h.respondPage(c, fmt.Sprintf("Histórico de consentimentos (total: %d)", total), records, page, limit, total)
+}
+
+// GrantConsent godoc
+// @Summary Conceder consentimento
+// @Description Registra o aceite do titular à versão vigente da política de uma finalidade, com IP e user agent como evidência
+// @Tags Privacidade
+// @Accept json
+// @Produce json
+// @Param request body models.ConcederConsentimentoRequest true "Finalidade e versão aceita"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/me/privacy/consents [post]
+// @Security BearerAuth
+func (h *Handler) GrantConsent(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConcederConsentimentoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	record, err := h.service.GrantConsent(c.Request.Context(), userID, &req, consentEvidence(c))
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar consentimento")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Consentimento registrado",
+		Timestamp: time.Now(),
+		Data:      record,
+	})
+}
+
+// WithdrawConsent godoc
+// @Summary Revogar consentimento
+// @Description Revoga o consentimento de uma finalidade; as funcionalidades que dependem dele são desativadas imediatamente (perfis sociais passam a privados, webhooks e acessos de laboratórios são interrompidos, ofertas de tokens são canceladas)
+// @Tags Privacidade
+// @Accept json
+// @Produce json
+// @Param finalidade path string true "Finalidade"
+// @Param request body models.RevogarConsentimentoRequest false "Motivo"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/me/privacy/consents/{finalidade} [delete]
+// @Security BearerAuth
+func (h *Handler) WithdrawConsent(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.RevogarConsentimentoRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success:   false,
+				Error:     "Dados inválidos: " + err.Error(),
+				Timestamp: time.Now(),
+			})
+			return
+		}
+	}
+
+	record, err := h.service.WithdrawConsent(c.Request.Context(), userID, c.Param("finalidade"), req.Motivo, consentEvidence(c))
+	if err != nil {
+		h.respondError(c, err, "Erro ao revogar consentimento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Consentimento revogado",
+		Timestamp: time.Now(),
+		Data:      record,
+	})
+}
+
+// ListPolicies godoc
+// @Summary Políticas de consentimento
+// @Description Lista as versões publicadas dos textos de consentimento, da mais recente para a mais antiga
+// @Tags Privacidade
+// @Produce json
+// @Param finalidade query string false "Finalidade"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /privacy/policies [get]
+func (h *Handler) ListPolicies(c *gin.Context) {
+	policies, err := h.service.ListPolicies(c.Request.Context(), c.Query("finalidade"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar políticas de consentimento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Políticas de consentimento",
+		Timestamp: time.Now(),
+		Data:      policies,
+	})
+}
+
+// PublishPolicy godoc
+// @Summary Publicar política de consentimento
+// @Description Publica nova versão do texto de uma finalidade. Com exige_novo_aceite, os consentimentos dados a versões anteriores deixam de valer imediatamente (apenas admin)
+// @Tags Privacidade
+// @Accept json
+// @Produce json
+// @Param request body models.PublicarPoliticaRequest true "Nova versão"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/privacy/policies [post]
+// @Security BearerAuth
+func (h *Handler) PublishPolicy(c *gin.Context) {
+	adminID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.PublicarPoliticaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	policy, err := h.service.PublishPolicy(c.Request.Context(), adminID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao publicar política de consentimento")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Versão %d da política publicada", policy.Versao),
+		Timestamp: time.Now(),
+		Data:      policy,
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *Handler) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func parsePagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func (h *Handler) respondPage(c *gin.Context, message string, data interface{}, page, limit int, total int64) {
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: data,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: totalPages,
+			},
+		},
+	})
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
+
+// consentEvidence dados da requisição guardados como evidência do aceite ou da revogação
+func consentEvidence(c *gin.Context) map[string]interface{} {
+	return map[string]interface{}{
+		"ip":         c.ClientIP(),
+		"user_agent": c.Request.UserAgent(),
+	}
+}
@@ -0,0 +1,34 @@
+package privacidade
+
+import (
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	rg.GET("/privacy/policies", handler.ListPolicies)
+
+	privacy := rg.Group("/users/me/privacy")
+	privacy.Use(authMiddleware)
+	{
+		privacy.GET("/requests", handler.ListMyRequests)
+		privacy.POST("/requests", handler.CreateRequest)
+		privacy.GET("/requests/:id", handler.GetMyRequest)
+		privacy.GET("/requests/:id/download", handler.DownloadExport)
+
+		privacy.GET("/consents", handler.ListConsents)
+		privacy.GET("/consents/history", handler.ConsentHistory)
+		privacy.POST("/consents", handler.GrantConsent)
+		privacy.DELETE("/consents/:finalidade", handler.WithdrawConsent)
+	}
+
+	admin := rg.Group("/admin/privacy")
+	admin.Use(authMiddleware, middleware.RequireAdminMiddleware())
+	{
+		admin.GET("/requests", handler.ListQueue)
+		admin.GET("/stats", handler.GetStats)
+		admin.POST("/requests/:id/process", handler.ProcessRequest)
+		admin.POST("/requests/:id/reject", handler.RejectRequest)
+		admin.POST("/policies", handler.PublishPolicy)
+	}
+}
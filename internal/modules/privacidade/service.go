@@ -0,0 +1,318 @@
+package privacidade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/security/compliance"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+// Store solicitações de titulares (implementada por compliance.LGPDService)
+type Store interface {
+	RequestDataAccess(ctx context.Context, req *compliance.DataRequest) (*compliance.DataResponse, error)
+	RequestDataPortability(ctx context.Context, req *compliance.DataRequest) (*compliance.DataResponse, error)
+	RequestDataDeletion(ctx context.Context, req *compliance.DataRequest) (*compliance.DataResponse, error)
+	ListRequests(ctx context.Context, filter *compliance.RequestFilter) ([]models.ComplianceRecord, int64, error)
+	GetUserRequest(ctx context.Context, userID, requestID uint) (*models.ComplianceRecord, error)
+	OpenExport(ctx context.Context, userID, requestID uint) (*compliance.ExportFile, error)
+	ProcessRequest(ctx context.Context, requestID uint, processedByID uint) (*models.ComplianceRecord, error)
+	RejectRequest(ctx context.Context, requestID uint, processedByID uint, reason string) (*models.ComplianceRecord, error)
+	GetComplianceStats(ctx context.Context) (map[string]interface{}, error)
+
+	ConsentStatus(ctx context.Context, userID uint) ([]models.ConsentimentoStatus, error)
+	ConsentHistory(ctx context.Context, userID uint, purpose string, limit, offset int) ([]models.ComplianceRecord, int64, error)
+	GrantConsent(ctx context.Context, userID uint, purpose string, version int, metadata map[string]interface{}) (*models.ComplianceRecord, error)
+	WithdrawConsent(ctx context.Context, userID uint, purpose, reason string, metadata map[string]interface{}) (*models.ComplianceRecord, error)
+	ListPolicies(ctx context.Context, purpose string) ([]models.PoliticaConsentimento, error)
+	PublishPolicy(ctx context.Context, req *models.PublicarPoliticaRequest, publishedByID uint) (*models.PoliticaConsentimento, error)
+}
+
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+type Service interface {
+	CreateRequest(ctx context.Context, userID uint, req *models.CriarSolicitacaoPrivacidadeRequest) (*compliance.DataResponse, error)
+	ListMyRequests(ctx context.Context, userID uint, page, limit int) ([]models.SolicitacaoPrivacidadeResponse, int64, error)
+	GetMyRequest(ctx context.Context, userID, requestID uint) (*models.SolicitacaoPrivacidadeResponse, error)
+	OpenExport(ctx context.Context, userID, requestID uint) (*compliance.ExportFile, error)
+	ListQueue(ctx context.Context, filter *compliance.RequestFilter, page, limit int) ([]models.SolicitacaoPrivacidadeResponse, int64, error)
+	Process(ctx context.Context, requestID, adminID uint) (*models.SolicitacaoPrivacidadeResponse, error)
+	Reject(ctx context.Context, requestID, adminID uint, reason string) (*models.SolicitacaoPrivacidadeResponse, error)
+	GetStats(ctx context.Context) (map[string]interface{}, error)
+
+	ListConsents(ctx context.Context, userID uint) ([]models.ConsentimentoStatus, error)
+	ConsentHistory(ctx context.Context, userID uint, finalidade string, page, limit int) ([]models.ComplianceRecord, int64, error)
+	GrantConsent(ctx context.Context, userID uint, req *models.ConcederConsentimentoRequest, metadata map[string]interface{}) (*models.ComplianceRecord, error)
+	WithdrawConsent(ctx context.Context, userID uint, finalidade, motivo string, metadata map[string]interface{}) (*models.ComplianceRecord, error)
+	ListPolicies(ctx context.Context, finalidade string) ([]models.PoliticaConsentimento, error)
+	PublishPolicy(ctx context.Context, adminID uint, req *models.PublicarPoliticaRequest) (*models.PoliticaConsentimento, error)
+}
+
+type service struct {
+	store  Store
+	audit  AuditLogger
+	logger *logging.Logger
+}
+
+func NewService(store Store, audit AuditLogger, logger *logging.Logger) Service {
+	return &service{
+		store:  store,
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+func (s *service) CreateRequest(ctx context.Context, userID uint, req *models.CriarSolicitacaoPrivacidadeRequest) (*compliance.DataResponse, error) {
+	dataReq := &compliance.DataRequest{
+		UserID:      userID,
+		RequestType: req.Tipo,
+		DataTypes:   req.TiposDado,
+		Reason:      req.Motivo,
+		Format:      req.Formato,
+	}
+
+	var (
+		resp *compliance.DataResponse
+		err  error
+	)
+	switch req.Tipo {
+	case models.SolicitacaoAcesso:
+		resp, err = s.store.RequestDataAccess(ctx, dataReq)
+	case models.SolicitacaoPortabilidade:
+		resp, err = s.store.RequestDataPortability(ctx, dataReq)
+	case models.SolicitacaoExclusao:
+		resp, err = s.store.RequestDataDeletion(ctx, dataReq)
+	default:
+		return nil, &apperrors.ValidationError{Field: "tipo", Message: "tipo deve ser access, portability ou deletion", Value: req.Tipo}
+	}
+	if err != nil {
+		return nil, s.mapError(err, "PrivacidadeService.CreateRequest", logging.Fields{"user_id": userID, "tipo": req.Tipo})
+	}
+
+	s.logger.WithFields(logging.Fields{
+		"user_id":    userID,
+		"request_id": resp.RequestID,
+		"tipo":       req.Tipo,
+		"due_at":     resp.DueAt,
+	}).Info("Solicitação de titular registrada")
+
+	return resp, nil
+}
+
+func (s *service) ListMyRequests(ctx context.Context, userID uint, page, limit int) ([]models.SolicitacaoPrivacidadeResponse, int64, error) {
+	return s.list(ctx, &compliance.RequestFilter{UserID: &userID}, page, limit)
+}
+
+func (s *service) GetMyRequest(ctx context.Context, userID, requestID uint) (*models.SolicitacaoPrivacidadeResponse, error) {
+	record, err := s.store.GetUserRequest(ctx, userID, requestID)
+	if err != nil {
+		return nil, s.mapError(err, "PrivacidadeService.GetMyRequest", logging.Fields{"user_id": userID, "request_id": requestID})
+	}
+	resp := models.NewSolicitacaoPrivacidadeResponse(*record, time.Now())
+	return &resp, nil
+}
+
+func (s *service) OpenExport(ctx context.Context, userID, requestID uint) (*compliance.ExportFile, error) {
+	file, err := s.store.OpenExport(ctx, userID, requestID)
+	if err != nil {
+		return nil, s.mapError(err, "PrivacidadeService.OpenExport", logging.Fields{"user_id": userID, "request_id": requestID})
+	}
+	return file, nil
+}
+
+func (s *service) ListQueue(ctx context.Context, filter *compliance.RequestFilter, page, limit int) ([]models.SolicitacaoPrivacidadeResponse, int64, error) {
+	return s.list(ctx, filter, page, limit)
+}
+
+func (s *service) Process(ctx context.Context, requestID, adminID uint) (*models.SolicitacaoPrivacidadeResponse, error) {
+	record, err := s.store.ProcessRequest(ctx, requestID, adminID)
+	if err != nil {
+		return nil, s.mapError(err, "PrivacidadeService.Process", logging.Fields{"request_id": requestID, "admin_id": adminID})
+	}
+
+	s.recordChange(ctx, record, "process")
+	resp := models.NewSolicitacaoPrivacidadeResponse(*record, time.Now())
+	return &resp, nil
+}
+
+func (s *service) Reject(ctx context.Context, requestID, adminID uint, reason string) (*models.SolicitacaoPrivacidadeResponse, error) {
+	record, err := s.store.RejectRequest(ctx, requestID, adminID, reason)
+	if err != nil {
+		return nil, s.mapError(err, "PrivacidadeService.Reject", logging.Fields{"request_id": requestID, "admin_id": adminID})
+	}
+
+	s.recordChange(ctx, record, "reject")
+	resp := models.NewSolicitacaoPrivacidadeResponse(*record, time.Now())
+	return &resp, nil
+}
+
+func (s *service) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	stats, err := s.store.GetComplianceStats(ctx)
+	if err != nil {
+		s.logger.LogError(err, "PrivacidadeService.GetStats", nil)
+		return nil, apperrors.NewDatabaseError("privacy_stats", "erro ao calcular estatísticas de privacidade", err)
+	}
+	return stats, nil
+}
+
+func (s *service) ListConsents(ctx context.Context, userID uint) ([]models.ConsentimentoStatus, error) {
+	statuses, err := s.store.ConsentStatus(ctx, userID)
+	if err != nil {
+		return nil, s.mapError(err, "PrivacidadeService.ListConsents", logging.Fields{"user_id": userID})
+	}
+	return statuses, nil
+}
+
+func (s *service) ConsentHistory(ctx context.Context, userID uint, finalidade string, page, limit int) ([]models.ComplianceRecord, int64, error) {
+	if finalidade != "" && !models.IsValidFinalidade(finalidade) {
+		return nil, 0, &apperrors.ValidationError{Field: "finalidade", Message: "finalidade inválida", Value: finalidade}
+	}
+
+	records, total, err := s.store.ConsentHistory(ctx, userID, finalidade, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, s.mapError(err, "PrivacidadeService.ConsentHistory", logging.Fields{"user_id": userID})
+	}
+	return records, total, nil
+}
+
+func (s *service) GrantConsent(ctx context.Context, userID uint, req *models.ConcederConsentimentoRequest, metadata map[string]interface{}) (*models.ComplianceRecord, error) {
+	record, err := s.store.GrantConsent(ctx, userID, req.Finalidade, req.Versao, metadata)
+	if err != nil {
+		return nil, s.mapError(err, "PrivacidadeService.GrantConsent", logging.Fields{"user_id": userID, "finalidade": req.Finalidade})
+	}
+
+	s.recordChange(ctx, record, "grant")
+	s.logger.WithFields(logging.Fields{
+		"user_id":    userID,
+		"finalidade": req.Finalidade,
+		"versao":     req.Versao,
+	}).Info("Consentimento concedido")
+
+	return record, nil
+}
+
+func (s *service) WithdrawConsent(ctx context.Context, userID uint, finalidade, motivo string, metadata map[string]interface{}) (*models.ComplianceRecord, error) {
+	record, err := s.store.WithdrawConsent(ctx, userID, finalidade, motivo, metadata)
+	if record == nil {
+		return nil, s.mapError(err, "PrivacidadeService.WithdrawConsent", logging.Fields{"user_id": userID, "finalidade": finalidade})
+	}
+	// A revogação já está registrada; falhas ao desativar funcionalidades não podem desfazê-la
+	if err != nil {
+		s.logger.LogError(err, "PrivacidadeService.WithdrawConsent", logging.Fields{"user_id": userID, "finalidade": finalidade})
+	}
+
+	s.recordChange(ctx, record, "withdraw")
+	s.logger.WithFields(logging.Fields{
+		"user_id":    userID,
+		"finalidade": finalidade,
+	}).Info("Consentimento revogado")
+
+	return record, nil
+}
+
+func (s *service) ListPolicies(ctx context.Context, finalidade string) ([]models.PoliticaConsentimento, error) {
+	policies, err := s.store.ListPolicies(ctx, finalidade)
+	if err != nil {
+		return nil, s.mapError(err, "PrivacidadeService.ListPolicies", logging.Fields{"finalidade": finalidade})
+	}
+	return policies, nil
+}
+
+func (s *service) PublishPolicy(ctx context.Context, adminID uint, req *models.PublicarPoliticaRequest) (*models.PoliticaConsentimento, error) {
+	policy, err := s.store.PublishPolicy(ctx, req, adminID)
+	if policy == nil {
+		return nil, s.mapError(err, "PrivacidadeService.PublishPolicy", logging.Fields{"admin_id": adminID, "finalidade": req.Finalidade})
+	}
+	if err != nil {
+		s.logger.LogError(err, "PrivacidadeService.PublishPolicy", logging.Fields{"policy_id": policy.ID})
+	}
+
+	if s.audit != nil {
+		after := map[string]interface{}{
+			"finalidade":        policy.Finalidade,
+			"versao":            policy.Versao,
+			"texto_hash":        policy.TextoHash,
+			"exige_novo_aceite": policy.ExigeNovoAceite,
+		}
+		if err := s.audit.LogChange(ctx, "politica_consentimento", fmt.Sprintf("%d", policy.ID), "create", nil, after); err != nil {
+			s.logger.LogError(err, "PrivacidadeService.PublishPolicy", logging.Fields{"policy_id": policy.ID})
+		}
+	}
+
+	s.logger.WithFields(logging.Fields{
+		"finalidade":        policy.Finalidade,
+		"versao":            policy.Versao,
+		"exige_novo_aceite": policy.ExigeNovoAceite,
+	}).Info("Política de consentimento publicada")
+
+	return policy, nil
+}
+
+func (s *service) list(ctx context.Context, filter *compliance.RequestFilter, page, limit int) ([]models.SolicitacaoPrivacidadeResponse, int64, error) {
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+
+	records, total, err := s.store.ListRequests(ctx, filter)
+	if err != nil {
+		s.logger.LogError(err, "PrivacidadeService.List", nil)
+		return nil, 0, apperrors.NewDatabaseError("list_privacy_requests", "erro ao listar solicitações de privacidade", err)
+	}
+
+	now := time.Now()
+	responses := make([]models.SolicitacaoPrivacidadeResponse, len(records))
+	for i, record := range records {
+		responses[i] = models.NewSolicitacaoPrivacidadeResponse(record, now)
+	}
+	return responses, total, nil
+}
+
+func (s *service) recordChange(ctx context.Context, record *models.ComplianceRecord, operation string) {
+	if s.audit == nil {
+		return
+	}
+	after := map[string]interface{}{
+		"compliance_type": record.ComplianceType,
+		"request_type":    record.RequestType,
+		"status":          record.Status,
+		"policy_version":  record.PolicyVersion,
+		"user_id":         record.UserID,
+		"processed_by_id": record.ProcessedByID,
+		"notes":           record.Notes,
+	}
+	if err := s.audit.LogChange(ctx, "compliance_record", fmt.Sprintf("%d", record.ID), operation, nil, after); err != nil {
+		s.logger.LogError(err, "PrivacidadeService.recordChange", logging.Fields{"id": record.ID, "operation": operation})
+	}
+}
+
+func (s *service) mapError(err error, op string, fields logging.Fields) error {
+	switch {
+	case errors.Is(err, compliance.ErrUserNotFound):
+		return &apperrors.NotFoundError{Resource: "usuario", Message: "usuário não encontrado"}
+	case errors.Is(err, compliance.ErrRequestNotFound):
+		return &apperrors.NotFoundError{Resource: "solicitacao_privacidade", Message: "solicitação não encontrada"}
+	case errors.Is(err, compliance.ErrRequestOpen):
+		return &apperrors.ValidationError{Field: "tipo", Message: "já existe uma solicitação deste tipo em andamento"}
+	case errors.Is(err, compliance.ErrRequestProcessed):
+		return &apperrors.ValidationError{Field: "status", Message: "solicitação já foi processada"}
+	case errors.Is(err, compliance.ErrExportUnavailable):
+		return &apperrors.NotFoundError{Resource: "exportacao", Message: "pacote de dados indisponível ou expirado"}
+	case errors.Is(err, compliance.ErrInvalidPurpose):
+		return &apperrors.ValidationError{Field: "finalidade", Message: "finalidade inválida"}
+	case errors.Is(err, compliance.ErrPolicyNotFound):
+		return &apperrors.NotFoundError{Resource: "politica_consentimento", Message: "política de consentimento não encontrada"}
+	case errors.Is(err, compliance.ErrPolicyOutdated):
+		return &apperrors.ValidationError{Field: "versao", Message: "a versão informada não é a versão vigente da política"}
+	case errors.Is(err, compliance.ErrConsentNotFound):
+		return &apperrors.NotFoundError{Resource: "consentimento", Message: "não há consentimento ativo para esta finalidade"}
+	}
+
+	s.logger.LogError(err, op, fields)
+	return apperrors.NewDatabaseError("privacy_request", "erro ao processar solicitação de privacidade", err)
+}
@@ -0,0 +1,298 @@
+package reproducao
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegistrarCobertura godoc
+// @Summary Registrar cobertura
+// @Description Registra uma cobertura entre um reprodutor (macho) e uma matriz (fêmea); o usuário autenticado fica como veterinário responsável
+// @Tags Reprodução
+// @Accept json
+// @Produce json
+// @Param cobertura body models.RegistrarCoberturaRequest true "Dados da cobertura"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /reproducao/coberturas [post]
+// @Security BearerAuth
+func (h *Handler) RegistrarCobertura(c *gin.Context) {
+	var req models.RegistrarCoberturaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	cobertura, err := h.service.RegistrarCobertura(c.Request.Context(), &req, userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar cobertura")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Cobertura registrada com sucesso",
+		Timestamp: time.Now(),
+		Data:      cobertura,
+	})
+}
+
+// ConfirmarGestacao godoc
+// @Summary Confirmar gestação
+// @Description Abre a gestação da cobertura, com parto previsto em 11 meses, e marca a cobertura como confirmada
+// @Tags Reprodução
+// @Produce json
+// @Param id path int true "ID da cobertura"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /reproducao/coberturas/{id}/gestacao [post]
+// @Security BearerAuth
+func (h *Handler) ConfirmarGestacao(c *gin.Context) {
+	coberturaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	gestacao, err := h.service.ConfirmarGestacao(c.Request.Context(), uint(coberturaID), userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao confirmar gestação")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Gestação confirmada com sucesso",
+		Timestamp: time.Now(),
+		Data:      gestacao,
+	})
+}
+
+// ListCoberturas godoc
+// @Summary Listar coberturas do equino
+// @Description Coberturas do equino como reprodutor (machos) ou como matriz (fêmeas), mais recentes primeiro
+// @Tags Reprodução
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/coberturas [get]
+// @Security BearerAuth
+func (h *Handler) ListCoberturas(c *gin.Context) {
+	coberturas, err := h.service.ListCoberturas(c.Request.Context(), c.Param("equinoid"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar coberturas")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Coberturas do equino (total: %d)", len(coberturas)),
+		Timestamp: time.Now(),
+		Data:      coberturas,
+	})
+}
+
+// ListGestacoes godoc
+// @Summary Listar gestações da matriz
+// @Description Gestações da matriz com a cobertura e o reprodutor de origem
+// @Tags Reprodução
+// @Produce json
+// @Param equinoid path string true "Equinoid da matriz"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/gestacoes [get]
+// @Security BearerAuth
+func (h *Handler) ListGestacoes(c *gin.Context) {
+	gestacoes, err := h.service.ListGestacoes(c.Request.Context(), c.Param("equinoid"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar gestações")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Gestações da matriz (total: %d)", len(gestacoes)),
+		Timestamp: time.Now(),
+		Data:      gestacoes,
+	})
+}
+
+// RegistrarAvaliacaoSemen godoc
+// @Summary Registrar avaliação de sêmen
+// @Description Registra a análise de sêmen do reprodutor; qualidade geral e aptidão reprodutiva são calculadas pela motilidade, morfologia e viabilidade
+// @Tags Reprodução
+// @Accept json
+// @Produce json
+// @Param equinoid path string true "Equinoid do reprodutor"
+// @Param avaliacao body models.CreateAvaliacaoSemenRequest true "Resultados da análise"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/avaliacoes-semen [post]
+// @Security BearerAuth
+func (h *Handler) RegistrarAvaliacaoSemen(c *gin.Context) {
+	var req models.CreateAvaliacaoSemenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	avaliacao, err := h.service.RegistrarAvaliacaoSemen(c.Request.Context(), c.Param("equinoid"), &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar avaliação de sêmen")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Avaliação de sêmen registrada com sucesso",
+		Timestamp: time.Now(),
+		Data:      avaliacao,
+	})
+}
+
+// ListAvaliacoesSemen godoc
+// @Summary Listar avaliações de sêmen
+// @Description Histórico de avaliações de sêmen do reprodutor, coletas mais recentes primeiro
+// @Tags Reprodução
+// @Produce json
+// @Param equinoid path string true "Equinoid do reprodutor"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/avaliacoes-semen [get]
+// @Security BearerAuth
+func (h *Handler) ListAvaliacoesSemen(c *gin.Context) {
+	avaliacoes, err := h.service.ListAvaliacoesSemen(c.Request.Context(), c.Param("equinoid"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar avaliações de sêmen")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Avaliações de sêmen (total: %d)", len(avaliacoes)),
+		Timestamp: time.Now(),
+		Data:      avaliacoes,
+	})
+}
+
+// GetRanking godoc
+// @Summary Ranking reprodutivo
+// @Description Reprodutores ou matrizes com coberturas registradas, ordenados pela taxa de concepção
+// @Tags Reprodução
+// @Produce json
+// @Param sexo query string true "macho (reprodutores) ou femea (matrizes)"
+// @Param limit query int false "Quantidade de posições (máx. 100)" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /reproducao/ranking [get]
+// @Security BearerAuth
+func (h *Handler) GetRanking(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	ranking, err := h.service.GetRanking(c.Request.Context(), models.SexoEquino(c.Query("sexo")), limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao obter ranking reprodutivo")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Ranking reprodutivo",
+		Timestamp: time.Now(),
+		Data:      ranking,
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
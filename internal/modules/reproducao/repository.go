@@ -0,0 +1,156 @@
+package reproducao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	CreateCobertura(ctx context.Context, cobertura *models.Cobertura) error
+	FindCoberturaByID(ctx context.Context, id uint) (*models.Cobertura, error)
+	ListCoberturasByReprodutor(ctx context.Context, equinoid string) ([]*models.Cobertura, error)
+	ListCoberturasByMatriz(ctx context.Context, equinoid string) ([]*models.Cobertura, error)
+
+	CreateAvaliacaoSemen(ctx context.Context, avaliacao *models.AvaliacaoSemen) error
+	ListAvaliacoesSemen(ctx context.Context, equinoid string) ([]*models.AvaliacaoSemen, error)
+
+	ExistsGestacaoByCobertura(ctx context.Context, coberturaID uint) (bool, error)
+	CreateGestacao(ctx context.Context, gestacao *models.Gestacao) error
+	ListGestacoesByMatriz(ctx context.Context, equinoid string) ([]*models.Gestacao, error)
+
+	Ranking(ctx context.Context, sexo models.SexoEquino, limit int) ([]*models.DesempenhoReprodutivo, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateCobertura(ctx context.Context, cobertura *models.Cobertura) error {
+	if err := r.db.WithContext(ctx).Create(cobertura).Error; err != nil {
+		return apperrors.NewDatabaseError("create_cobertura", "erro ao registrar cobertura", err)
+	}
+	return nil
+}
+
+func (r *repository) FindCoberturaByID(ctx context.Context, id uint) (*models.Cobertura, error) {
+	var cobertura models.Cobertura
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&cobertura).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "cobertura", Message: "cobertura não encontrada", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_cobertura", "erro ao buscar cobertura", err)
+	}
+	return &cobertura, nil
+}
+
+func (r *repository) ListCoberturasByReprodutor(ctx context.Context, equinoid string) ([]*models.Cobertura, error) {
+	var coberturas []*models.Cobertura
+	if err := r.db.WithContext(ctx).Where("reprodutor_equinoid = ?", equinoid).
+		Preload("Matriz").
+		Order("data_cobertura DESC").
+		Find(&coberturas).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("list_coberturas", "erro ao listar coberturas do reprodutor", err)
+	}
+	return coberturas, nil
+}
+
+func (r *repository) ListCoberturasByMatriz(ctx context.Context, equinoid string) ([]*models.Cobertura, error) {
+	var coberturas []*models.Cobertura
+	if err := r.db.WithContext(ctx).Where("matriz_equinoid = ?", equinoid).
+		Preload("Reprodutor").
+		Order("data_cobertura DESC").
+		Find(&coberturas).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("list_coberturas", "erro ao listar coberturas da matriz", err)
+	}
+	return coberturas, nil
+}
+
+func (r *repository) CreateAvaliacaoSemen(ctx context.Context, avaliacao *models.AvaliacaoSemen) error {
+	if err := r.db.WithContext(ctx).Create(avaliacao).Error; err != nil {
+		return apperrors.NewDatabaseError("create_avaliacao_semen", "erro ao registrar avaliação de sêmen", err)
+	}
+	return nil
+}
+
+func (r *repository) ListAvaliacoesSemen(ctx context.Context, equinoid string) ([]*models.AvaliacaoSemen, error) {
+	var avaliacoes []*models.AvaliacaoSemen
+	if err := r.db.WithContext(ctx).Where("reprodutor_equinoid = ?", equinoid).
+		Order("data_coleta DESC").
+		Find(&avaliacoes).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("list_avaliacoes_semen", "erro ao listar avaliações de sêmen", err)
+	}
+	return avaliacoes, nil
+}
+
+func (r *repository) ExistsGestacaoByCobertura(ctx context.Context, coberturaID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Gestacao{}).Where("cobertura_id = ?", coberturaID).Count(&count).Error; err != nil {
+		return false, apperrors.NewDatabaseError("exists_gestacao", "erro ao verificar gestação da cobertura", err)
+	}
+	return count > 0, nil
+}
+
+// CreateGestacao registra a gestação e confirma a cobertura de origem na mesma transação
+func (r *repository) CreateGestacao(ctx context.Context, gestacao *models.Gestacao) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(gestacao).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Cobertura{}).Where("id = ?", gestacao.CoberturaID).Updates(map[string]interface{}{
+			"status_cobertura": models.StatusCoberturaConfirmada,
+			"data_confirmacao": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return apperrors.NewDatabaseError("create_gestacao", "erro ao registrar gestação", err)
+	}
+	return nil
+}
+
+func (r *repository) ListGestacoesByMatriz(ctx context.Context, equinoid string) ([]*models.Gestacao, error) {
+	var gestacoes []*models.Gestacao
+	if err := r.db.WithContext(ctx).Where("matriz_equinoid = ?", equinoid).
+		Preload("Cobertura").
+		Preload("Cobertura.Reprodutor").
+		Order("data_cobertura DESC").
+		Find(&gestacoes).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("list_gestacoes", "erro ao listar gestações da matriz", err)
+	}
+	return gestacoes, nil
+}
+
+// Ranking reprodutores (pelas coberturas como reprodutor) ou matrizes (como matriz) ordenados pela taxa de concepção
+func (r *repository) Ranking(ctx context.Context, sexo models.SexoEquino, limit int) ([]*models.DesempenhoReprodutivo, error) {
+	coluna := "c.matriz_equinoid"
+	if sexo == models.SexoMacho {
+		coluna = "c.reprodutor_equinoid"
+	}
+
+	var ranking []*models.DesempenhoReprodutivo
+	err := r.db.WithContext(ctx).Table("equinos e").
+		Select(`e.equinoid, e.nome, e.sexo,
+			COUNT(DISTINCT c.id) AS total_coberturas,
+			COUNT(DISTINCT g.id) AS total_gestacoes,
+			COUNT(DISTINCT CASE WHEN g.status_gestacao = ? THEN g.id END) AS partos_concluidos,
+			ROUND(COUNT(DISTINCT g.id) * 100.0 / COUNT(DISTINCT c.id), 2) AS taxa_concepcao`, models.StatusGestacaoConcluida).
+		Joins("JOIN coberturas c ON e.equinoid = "+coluna+" AND c.deleted_at IS NULL").
+		Joins("LEFT JOIN gestacaos g ON g.cobertura_id = c.id AND g.deleted_at IS NULL").
+		Where("e.sexo = ? AND e.deleted_at IS NULL", sexo).
+		Group("e.equinoid, e.nome, e.sexo").
+		Order("taxa_concepcao DESC, total_coberturas DESC").
+		Limit(limit).
+		Scan(&ranking).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("ranking_reprodutivo", "erro ao calcular ranking reprodutivo", err)
+	}
+	return ranking, nil
+}
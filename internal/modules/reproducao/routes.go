@@ -0,0 +1,25 @@
+package reproducao
+
+import (
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	reproducao := rg.Group("/reproducao")
+	reproducao.Use(authMiddleware)
+	{
+		reproducao.GET("/ranking", handler.GetRanking)
+		reproducao.POST("/coberturas", middleware.RequireVeterinarioOrAdminMiddleware(), handler.RegistrarCobertura)
+		reproducao.POST("/coberturas/:id/gestacao", middleware.RequireVeterinarioOrAdminMiddleware(), handler.ConfirmarGestacao)
+	}
+
+	equinos := rg.Group("/equinos")
+	equinos.Use(authMiddleware)
+	{
+		equinos.GET("/:equinoid/coberturas", handler.ListCoberturas)
+		equinos.GET("/:equinoid/gestacoes", handler.ListGestacoes)
+		equinos.GET("/:equinoid/avaliacoes-semen", handler.ListAvaliacoesSemen)
+		equinos.POST("/:equinoid/avaliacoes-semen", middleware.RequireRoleMiddleware("laboratorio", "veterinario", "admin"), handler.RegistrarAvaliacaoSemen)
+	}
+}
@@ -0,0 +1,273 @@
+package reproducao
+
+import (
+	"context"
+
+	"github.com/equinoid/backend/internal/constants"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	"github.com/equinoid/backend/pkg/cache"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+// limiteRankingMaximo quantidade máxima de posições devolvidas no ranking reprodutivo
+const limiteRankingMaximo = 100
+
+type Service interface {
+	RegistrarCobertura(ctx context.Context, req *models.RegistrarCoberturaRequest, veterinarioID uint) (*models.Cobertura, error)
+	ListCoberturas(ctx context.Context, equinoid string) ([]*models.Cobertura, error)
+	ConfirmarGestacao(ctx context.Context, coberturaID uint, veterinarioID uint) (*models.Gestacao, error)
+	ListGestacoes(ctx context.Context, equinoid string) ([]*models.Gestacao, error)
+
+	RegistrarAvaliacaoSemen(ctx context.Context, equinoid string, req *models.CreateAvaliacaoSemenRequest) (*models.AvaliacaoSemen, error)
+	ListAvaliacoesSemen(ctx context.Context, equinoid string) ([]*models.AvaliacaoSemen, error)
+
+	GetRanking(ctx context.Context, sexo models.SexoEquino, limit int) ([]*models.DesempenhoReprodutivo, error)
+}
+
+type service struct {
+	repo       Repository
+	equinoRepo equinos.Repository
+	cache      cache.CacheInterface
+	logger     *logging.Logger
+}
+
+func NewService(repo Repository, equinoRepo equinos.Repository, cache cache.CacheInterface, logger *logging.Logger) Service {
+	return &service{
+		repo:       repo,
+		equinoRepo: equinoRepo,
+		cache:      cache,
+		logger:     logger,
+	}
+}
+
+func (s *service) RegistrarCobertura(ctx context.Context, req *models.RegistrarCoberturaRequest, veterinarioID uint) (*models.Cobertura, error) {
+	reprodutor, err := s.findEquino(ctx, req.ReprodutorEquinoid, "reprodutor não encontrado")
+	if err != nil {
+		return nil, err
+	}
+	if !reprodutor.IsMacho() {
+		return nil, &apperrors.ValidationError{Field: "reprodutor_equinoid", Message: "reprodutor deve ser macho", Value: reprodutor.Sexo}
+	}
+
+	matriz, err := s.findEquino(ctx, req.MatrizEquinoid, "matriz não encontrada")
+	if err != nil {
+		return nil, err
+	}
+	if !matriz.IsFemea() {
+		return nil, &apperrors.ValidationError{Field: "matriz_equinoid", Message: "matriz deve ser fêmea", Value: matriz.Sexo}
+	}
+
+	if req.DataCobertura.IsZero() {
+		return nil, &apperrors.ValidationError{Field: "data_cobertura", Message: "data da cobertura é obrigatória"}
+	}
+	switch req.TipoCobertura {
+	case models.TipoCoberturaNatural, models.TipoCoberturaInseminacao, models.TipoCoberturaEmbriao:
+	default:
+		return nil, &apperrors.ValidationError{Field: "tipo_cobertura", Message: "tipo de cobertura inválido", Value: req.TipoCobertura}
+	}
+
+	cobertura := &models.Cobertura{
+		ReprodutorEquinoid:     reprodutor.Equinoid,
+		MatrizEquinoid:         matriz.Equinoid,
+		DataCobertura:          req.DataCobertura,
+		TipoCobertura:          req.TipoCobertura,
+		MetodoCobertura:        req.MetodoCobertura,
+		VeterinarioResponsavel: veterinarioID,
+		LaboratorioID:          req.LaboratorioID,
+		StatusCobertura:        models.StatusCoberturaPendente,
+		ProbabilidadeConcepcao: req.ProbabilidadeConcepcao,
+		Observacoes:            req.Observacoes,
+	}
+
+	if err := s.repo.CreateCobertura(ctx, cobertura); err != nil {
+		s.logger.LogError(err, "ReproducaoService.RegistrarCobertura", logging.Fields{
+			"reprodutor": reprodutor.Equinoid,
+			"matriz":     matriz.Equinoid,
+		})
+		return nil, err
+	}
+
+	s.logger.LogBusinessEvent("cobertura_registrada", "Cobertura registrada", veterinarioID, matriz.Equinoid, logging.Fields{"cobertura_id": cobertura.ID})
+	return cobertura, nil
+}
+
+// ListCoberturas coberturas do equino como reprodutor ou como matriz, conforme o sexo
+func (s *service) ListCoberturas(ctx context.Context, equinoid string) ([]*models.Cobertura, error) {
+	equino, err := s.findEquino(ctx, equinoid, "equino não encontrado")
+	if err != nil {
+		return nil, err
+	}
+
+	var coberturas []*models.Cobertura
+	if equino.IsMacho() {
+		coberturas, err = s.repo.ListCoberturasByReprodutor(ctx, equino.Equinoid)
+	} else {
+		coberturas, err = s.repo.ListCoberturasByMatriz(ctx, equino.Equinoid)
+	}
+	if err != nil {
+		s.logger.LogError(err, "ReproducaoService.ListCoberturas", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+	return coberturas, nil
+}
+
+// ConfirmarGestacao abre a gestação da cobertura com parto previsto em 11 meses e confirma a cobertura
+func (s *service) ConfirmarGestacao(ctx context.Context, coberturaID uint, veterinarioID uint) (*models.Gestacao, error) {
+	cobertura, err := s.repo.FindCoberturaByID(ctx, coberturaID)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "ReproducaoService.ConfirmarGestacao", logging.Fields{"cobertura_id": coberturaID})
+		}
+		return nil, err
+	}
+
+	if cobertura.StatusCobertura == models.StatusCoberturaFalhou {
+		return nil, &apperrors.ValidationError{Field: "status_cobertura", Message: "cobertura registrada como falha", Value: cobertura.StatusCobertura}
+	}
+
+	exists, err := s.repo.ExistsGestacaoByCobertura(ctx, coberturaID)
+	if err != nil {
+		s.logger.LogError(err, "ReproducaoService.ConfirmarGestacao", logging.Fields{"cobertura_id": coberturaID})
+		return nil, err
+	}
+	if exists {
+		return nil, &apperrors.ConflictError{Resource: "gestacao", Message: "já existe gestação para esta cobertura", Value: coberturaID}
+	}
+
+	gestacao := &models.Gestacao{
+		CoberturaID:            coberturaID,
+		MatrizEquinoid:         cobertura.MatrizEquinoid,
+		DataCobertura:          cobertura.DataCobertura,
+		DataPrevistaParto:      cobertura.DataCobertura.AddDate(0, constants.MesesGestacaoEquino, 0),
+		VeterinarioResponsavel: veterinarioID,
+		StatusGestacao:         models.StatusGestacaoAtiva,
+	}
+
+	if err := s.repo.CreateGestacao(ctx, gestacao); err != nil {
+		s.logger.LogError(err, "ReproducaoService.ConfirmarGestacao", logging.Fields{"cobertura_id": coberturaID})
+		return nil, err
+	}
+
+	s.logger.LogBusinessEvent("gestacao_confirmada", "Gestação confirmada", veterinarioID, cobertura.MatrizEquinoid, logging.Fields{"gestacao_id": gestacao.ID})
+	return gestacao, nil
+}
+
+func (s *service) ListGestacoes(ctx context.Context, equinoid string) ([]*models.Gestacao, error) {
+	matriz, err := s.findEquino(ctx, equinoid, "matriz não encontrada")
+	if err != nil {
+		return nil, err
+	}
+	if !matriz.IsFemea() {
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "gestações só existem para fêmeas", Value: matriz.Sexo}
+	}
+
+	gestacoes, err := s.repo.ListGestacoesByMatriz(ctx, matriz.Equinoid)
+	if err != nil {
+		s.logger.LogError(err, "ReproducaoService.ListGestacoes", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+	return gestacoes, nil
+}
+
+func (s *service) RegistrarAvaliacaoSemen(ctx context.Context, equinoid string, req *models.CreateAvaliacaoSemenRequest) (*models.AvaliacaoSemen, error) {
+	reprodutor, err := s.findEquino(ctx, equinoid, "reprodutor não encontrado")
+	if err != nil {
+		return nil, err
+	}
+	if !reprodutor.IsMacho() {
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "avaliação de sêmen só pode ser feita em machos", Value: reprodutor.Sexo}
+	}
+
+	if req.LaboratorioID == 0 {
+		return nil, &apperrors.ValidationError{Field: "laboratorio_id", Message: "laboratório responsável é obrigatório"}
+	}
+	if req.DataColeta.IsZero() || req.DataAnalise.IsZero() {
+		return nil, &apperrors.ValidationError{Field: "data_coleta", Message: "datas de coleta e análise são obrigatórias"}
+	}
+	if req.DataAnalise.Before(req.DataColeta) {
+		return nil, &apperrors.ValidationError{Field: "data_analise", Message: "análise não pode ser anterior à coleta", Value: req.DataAnalise}
+	}
+
+	if req.CoberturaID != nil {
+		cobertura, err := s.repo.FindCoberturaByID(ctx, *req.CoberturaID)
+		if err != nil {
+			return nil, err
+		}
+		if cobertura.ReprodutorEquinoid != reprodutor.Equinoid {
+			return nil, &apperrors.ValidationError{Field: "cobertura_id", Message: "cobertura não pertence ao reprodutor", Value: *req.CoberturaID}
+		}
+	}
+
+	qualidade, aptidao := req.Classificar()
+
+	avaliacao := &models.AvaliacaoSemen{
+		ReprodutorEquinoid:          reprodutor.Equinoid,
+		CoberturaID:                 req.CoberturaID,
+		DataColeta:                  req.DataColeta,
+		DataAnalise:                 req.DataAnalise,
+		LaboratorioID:               req.LaboratorioID,
+		VolumeSemen:                 req.VolumeSemen,
+		ConcentracaoEspermatozoides: req.ConcentracaoEspermatozoides,
+		MotiliadeProgressiva:        req.MotilidadeProgressiva,
+		MotiliadeTotal:              req.MotilidadeTotal,
+		Viabilidade:                 req.Viabilidade,
+		MorfologiaNormal:            req.MorfologiaNormal,
+		QualidadeGeral:              qualidade,
+		AptidaoReprodutiva:          aptidao,
+		DataValidade:                req.DataValidade,
+		TemperaturaArmazenamento:    req.TemperaturaArmazenamento,
+		TecnicoResponsavel:          req.TecnicoResponsavel,
+		Observacoes:                 req.Observacoes,
+	}
+
+	if err := s.repo.CreateAvaliacaoSemen(ctx, avaliacao); err != nil {
+		s.logger.LogError(err, "ReproducaoService.RegistrarAvaliacaoSemen", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+
+	return avaliacao, nil
+}
+
+func (s *service) ListAvaliacoesSemen(ctx context.Context, equinoid string) ([]*models.AvaliacaoSemen, error) {
+	reprodutor, err := s.findEquino(ctx, equinoid, "reprodutor não encontrado")
+	if err != nil {
+		return nil, err
+	}
+
+	avaliacoes, err := s.repo.ListAvaliacoesSemen(ctx, reprodutor.Equinoid)
+	if err != nil {
+		s.logger.LogError(err, "ReproducaoService.ListAvaliacoesSemen", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+	return avaliacoes, nil
+}
+
+func (s *service) GetRanking(ctx context.Context, sexo models.SexoEquino, limit int) ([]*models.DesempenhoReprodutivo, error) {
+	if sexo != models.SexoMacho && sexo != models.SexoFemea {
+		return nil, &apperrors.ValidationError{Field: "sexo", Message: "sexo deve ser macho ou femea", Value: sexo}
+	}
+	if limit <= 0 || limit > limiteRankingMaximo {
+		limit = limiteRankingMaximo
+	}
+
+	ranking, err := s.repo.Ranking(ctx, sexo, limit)
+	if err != nil {
+		s.logger.LogError(err, "ReproducaoService.GetRanking", logging.Fields{"sexo": sexo})
+		return nil, err
+	}
+	return ranking, nil
+}
+
+func (s *service) findEquino(ctx context.Context, equinoid, mensagem string) (*models.Equino, error) {
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return nil, &apperrors.NotFoundError{Resource: "equino", Message: mensagem, ID: equinoid}
+		}
+		s.logger.LogError(err, "ReproducaoService", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+	return equino, nil
+}
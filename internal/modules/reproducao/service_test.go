@@ -0,0 +1,239 @@
+package reproducao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupReproducaoService(t *testing.T) (Service, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Skip("sqlite driver unavailable for tests")
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(
+		&models.Equino{},
+		&models.EquinoIdentificador{},
+		&models.Cobertura{},
+		&models.Gestacao{},
+		&models.AvaliacaoSemen{},
+	))
+
+	return NewService(NewRepository(db), equinos.NewRepository(db), nil, logging.NewLogger("error")), db
+}
+
+func criarEquino(t *testing.T, db *gorm.DB, equinoid string, sexo models.SexoEquino) *models.Equino {
+	dataNascimento := time.Now().AddDate(-5, 0, 0)
+	equino := &models.Equino{
+		Equinoid:       equinoid,
+		MicrochipID:    "CHIP_" + equinoid,
+		Nome:           "Equino " + equinoid,
+		Sexo:           sexo,
+		Raca:           "Mangalarga",
+		Pelagem:        "Alazão",
+		PaisOrigem:     "BRA",
+		DataNascimento: &dataNascimento,
+		Status:         models.StatusAtivo,
+		ProprietarioID: 1,
+	}
+	require.NoError(t, db.Create(equino).Error)
+	return equino
+}
+
+func TestReproducaoService_RegistrarCobertura(t *testing.T) {
+	service, db := setupReproducaoService(t)
+
+	criarEquino(t, db, "BRA-2015-00000001", models.SexoMacho)
+	criarEquino(t, db, "BRA-2015-00000002", models.SexoFemea)
+
+	novaRequisicao := func(reprodutor, matriz string) *models.RegistrarCoberturaRequest {
+		return &models.RegistrarCoberturaRequest{
+			ReprodutorEquinoid: reprodutor,
+			MatrizEquinoid:     matriz,
+			CreateCoberturaRequest: models.CreateCoberturaRequest{
+				DataCobertura:   time.Now(),
+				TipoCobertura:   models.TipoCoberturaNatural,
+				MetodoCobertura: "Monta natural",
+			},
+		}
+	}
+
+	t.Run("Registrar cobertura com sucesso", func(t *testing.T) {
+		cobertura, err := service.RegistrarCobertura(context.Background(), novaRequisicao("BRA-2015-00000001", "BRA-2015-00000002"), 1)
+
+		require.NoError(t, err)
+		assert.NotZero(t, cobertura.ID)
+		assert.Equal(t, "BRA-2015-00000001", cobertura.ReprodutorEquinoid)
+		assert.Equal(t, "BRA-2015-00000002", cobertura.MatrizEquinoid)
+		assert.Equal(t, models.StatusCoberturaPendente, cobertura.StatusCobertura)
+		assert.Equal(t, uint(1), cobertura.VeterinarioResponsavel)
+	})
+
+	t.Run("Reprodutor deve ser macho", func(t *testing.T) {
+		_, err := service.RegistrarCobertura(context.Background(), novaRequisicao("BRA-2015-00000002", "BRA-2015-00000002"), 1)
+
+		require.True(t, apperrors.IsValidation(err))
+		assert.Contains(t, err.Error(), "reprodutor deve ser macho")
+	})
+
+	t.Run("Matriz deve ser fêmea", func(t *testing.T) {
+		_, err := service.RegistrarCobertura(context.Background(), novaRequisicao("BRA-2015-00000001", "BRA-2015-00000001"), 1)
+
+		require.True(t, apperrors.IsValidation(err))
+		assert.Contains(t, err.Error(), "matriz deve ser fêmea")
+	})
+
+	t.Run("Tipo de cobertura inválido", func(t *testing.T) {
+		req := novaRequisicao("BRA-2015-00000001", "BRA-2015-00000002")
+		req.TipoCobertura = "clonagem"
+
+		_, err := service.RegistrarCobertura(context.Background(), req, 1)
+
+		assert.True(t, apperrors.IsValidation(err))
+	})
+
+	t.Run("Reprodutor não encontrado", func(t *testing.T) {
+		_, err := service.RegistrarCobertura(context.Background(), novaRequisicao("BRA-9999-99999999", "BRA-2015-00000002"), 1)
+
+		assert.True(t, apperrors.IsNotFound(err))
+	})
+}
+
+func TestReproducaoService_RegistrarAvaliacaoSemen(t *testing.T) {
+	service, db := setupReproducaoService(t)
+
+	criarEquino(t, db, "BRA-2015-00000010", models.SexoMacho)
+	criarEquino(t, db, "BRA-2015-00000011", models.SexoFemea)
+
+	motProgressiva, motTotal, morfologia, viabilidade := 70.0, 80.0, 75.0, 85.0
+
+	t.Run("Avaliação de sêmen excelente", func(t *testing.T) {
+		req := &models.CreateAvaliacaoSemenRequest{
+			LaboratorioID:         1,
+			DataColeta:            time.Now(),
+			DataAnalise:           time.Now(),
+			MotilidadeProgressiva: &motProgressiva,
+			MotilidadeTotal:       &motTotal,
+			MorfologiaNormal:      &morfologia,
+			Viabilidade:           &viabilidade,
+		}
+
+		avaliacao, err := service.RegistrarAvaliacaoSemen(context.Background(), "BRA-2015-00000010", req)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.QualidadeExcelente, avaliacao.QualidadeGeral)
+		assert.Equal(t, models.AptidaoAlta, avaliacao.AptidaoReprodutiva)
+
+		avaliacoes, err := service.ListAvaliacoesSemen(context.Background(), "BRA-2015-00000010")
+		require.NoError(t, err)
+		assert.Len(t, avaliacoes, 1)
+	})
+
+	t.Run("Apenas machos podem ter avaliação de sêmen", func(t *testing.T) {
+		req := &models.CreateAvaliacaoSemenRequest{LaboratorioID: 1, DataColeta: time.Now(), DataAnalise: time.Now()}
+
+		_, err := service.RegistrarAvaliacaoSemen(context.Background(), "BRA-2015-00000011", req)
+
+		require.True(t, apperrors.IsValidation(err))
+		assert.Contains(t, err.Error(), "só pode ser feita em machos")
+	})
+
+	t.Run("Análise anterior à coleta", func(t *testing.T) {
+		req := &models.CreateAvaliacaoSemenRequest{LaboratorioID: 1, DataColeta: time.Now(), DataAnalise: time.Now().AddDate(0, 0, -1)}
+
+		_, err := service.RegistrarAvaliacaoSemen(context.Background(), "BRA-2015-00000010", req)
+
+		assert.True(t, apperrors.IsValidation(err))
+	})
+}
+
+func TestCreateAvaliacaoSemenRequest_Classificar(t *testing.T) {
+	casos := []struct {
+		nome        string
+		motProg     float64
+		motTotal    float64
+		morfologia  float64
+		viabilidade float64
+		qualidade   models.QualidadeSemen
+		aptidao     models.AptidaoReprodutiva
+	}{
+		{"Excelente", 70, 80, 75, 85, models.QualidadeExcelente, models.AptidaoAlta},
+		{"Boa", 60, 70, 70, 70, models.QualidadeBoa, models.AptidaoMedia},
+		{"Regular", 50, 60, 60, 70, models.QualidadeRegular, models.AptidaoBaixa},
+		{"Ruim", 40, 50, 50, 50, models.QualidadeRuim, models.AptidaoInadequada},
+		{"Inadequada", 20, 30, 30, 40, models.QualidadeInadequada, models.AptidaoInadequada},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			req := &models.CreateAvaliacaoSemenRequest{
+				MotilidadeProgressiva: &caso.motProg,
+				MotilidadeTotal:       &caso.motTotal,
+				MorfologiaNormal:      &caso.morfologia,
+				Viabilidade:           &caso.viabilidade,
+			}
+
+			qualidade, aptidao := req.Classificar()
+
+			assert.Equal(t, caso.qualidade, qualidade)
+			assert.Equal(t, caso.aptidao, aptidao)
+		})
+	}
+}
+
+func TestReproducaoService_ConfirmarGestacao(t *testing.T) {
+	service, db := setupReproducaoService(t)
+
+	criarEquino(t, db, "BRA-2015-00000020", models.SexoMacho)
+	criarEquino(t, db, "BRA-2015-00000021", models.SexoFemea)
+
+	dataCobertura := time.Now().AddDate(0, -1, 0)
+	cobertura := &models.Cobertura{
+		ReprodutorEquinoid:     "BRA-2015-00000020",
+		MatrizEquinoid:         "BRA-2015-00000021",
+		DataCobertura:          dataCobertura,
+		TipoCobertura:          models.TipoCoberturaNatural,
+		VeterinarioResponsavel: 1,
+		StatusCobertura:        models.StatusCoberturaPendente,
+	}
+	require.NoError(t, db.Create(cobertura).Error)
+
+	t.Run("Confirmar gestação com sucesso", func(t *testing.T) {
+		gestacao, err := service.ConfirmarGestacao(context.Background(), cobertura.ID, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, cobertura.ID, gestacao.CoberturaID)
+		assert.Equal(t, "BRA-2015-00000021", gestacao.MatrizEquinoid)
+		assert.Equal(t, models.StatusGestacaoAtiva, gestacao.StatusGestacao)
+		assert.True(t, gestacao.DataPrevistaParto.Equal(dataCobertura.AddDate(0, 11, 0)))
+
+		gestacoes, err := service.ListGestacoes(context.Background(), "BRA-2015-00000021")
+		require.NoError(t, err)
+		assert.Len(t, gestacoes, 1)
+	})
+
+	t.Run("Não permitir gestação duplicada", func(t *testing.T) {
+		_, err := service.ConfirmarGestacao(context.Background(), cobertura.ID, 1)
+
+		require.True(t, apperrors.IsConflict(err))
+		assert.Contains(t, err.Error(), "já existe gestação")
+	})
+
+	t.Run("Cobertura não encontrada", func(t *testing.T) {
+		_, err := service.ConfirmarGestacao(context.Background(), 99999, 1)
+
+		require.True(t, apperrors.IsNotFound(err))
+		assert.Contains(t, err.Error(), "cobertura não encontrada")
+	})
+}
@@ -0,0 +1,811 @@
+package social
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreatePerfil godoc
+// @Summary Criar perfil social
+// @Description Cria o perfil social do equino (somente o proprietário). Perfis públicos e comerciais exigem o consentimento de perfil social público do proprietário; permissões omitidas ficam habilitadas
+// @Tags Social
+// @Accept json
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param perfil body models.CreatePerfilSocialRequest false "Dados do perfil"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/perfil-social [post]
+// @Security BearerAuth
+func (h *Handler) CreatePerfil(c *gin.Context) {
+	var req models.CreatePerfilSocialRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.badRequest(c, err)
+			return
+		}
+	}
+
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	perfil, err := h.service.CreatePerfil(c.Request.Context(), c.Param("equinoid"), userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao criar perfil social")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Perfil social criado com sucesso",
+		Timestamp: time.Now(),
+		Data:      perfil,
+	})
+}
+
+// GetPerfil godoc
+// @Summary Buscar perfil social
+// @Description Perfil social do equino com os contadores; perfis privados só são visíveis ao proprietário e a seguidores aprovados
+// @Tags Social
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/perfil-social [get]
+// @Security BearerAuth
+func (h *Handler) GetPerfil(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	perfil, err := h.service.GetPerfil(c.Request.Context(), c.Param("equinoid"), userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar perfil social")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Perfil social encontrado",
+		Timestamp: time.Now(),
+		Data:      perfil,
+	})
+}
+
+// UpdatePerfil godoc
+// @Summary Atualizar perfil social
+// @Description Atualiza os campos informados do perfil (somente o proprietário). Tornar o perfil público ou comercial exige consentimento e aprova as solicitações de seguidores pendentes
+// @Tags Social
+// @Accept json
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param perfil body models.UpdatePerfilSocialRequest true "Campos a atualizar"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/perfil-social [put]
+// @Security BearerAuth
+func (h *Handler) UpdatePerfil(c *gin.Context) {
+	var req models.UpdatePerfilSocialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	perfil, err := h.service.UpdatePerfil(c.Request.Context(), c.Param("equinoid"), userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao atualizar perfil social")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Perfil social atualizado com sucesso",
+		Timestamp: time.Now(),
+		Data:      perfil,
+	})
+}
+
+// Seguir godoc
+// @Summary Seguir equino
+// @Description Segue o equino; em perfis privados a solicitação fica pendente até o proprietário aprovar. Chamadas repetidas devolvem o vínculo existente
+// @Tags Social
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/seguir [post]
+// @Security BearerAuth
+func (h *Handler) Seguir(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	seguimento, err := h.service.Seguir(c.Request.Context(), c.Param("equinoid"), userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao seguir equino")
+		return
+	}
+
+	message := "Você está seguindo o equino"
+	if seguimento.Status == models.StatusSeguirPendente {
+		message = "Solicitação enviada ao proprietário"
+	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data:      seguimento,
+	})
+}
+
+// DeixarDeSeguir godoc
+// @Summary Deixar de seguir equino
+// @Description Desfaz o vínculo ou cancela a solicitação pendente; idempotente
+// @Tags Social
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/seguir [delete]
+// @Security BearerAuth
+func (h *Handler) DeixarDeSeguir(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	seguimento, err := h.service.DeixarDeSeguir(c.Request.Context(), c.Param("equinoid"), userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao deixar de seguir equino")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Você deixou de seguir o equino",
+		Timestamp: time.Now(),
+		Data:      seguimento,
+	})
+}
+
+// ListSeguidores godoc
+// @Summary Listar seguidores
+// @Description Seguidores ativos do equino ou, para o proprietário, as solicitações pendentes
+// @Tags Social
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param status query string false "ativo (padrão) ou pendente"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/seguidores [get]
+// @Security BearerAuth
+func (h *Handler) ListSeguidores(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := paginacao(c)
+
+	seguidores, total, err := h.service.ListSeguidores(c.Request.Context(), c.Param("equinoid"), userID, userType, models.StatusSeguir(c.Query("status")), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar seguidores")
+		return
+	}
+
+	h.respondPaginated(c, fmt.Sprintf("Seguidores (total: %d)", total), seguidores, page, limit, total)
+}
+
+// AprovarSeguidor godoc
+// @Summary Aprovar seguidor
+// @Description Aprova a solicitação pendente de um usuário para seguir o perfil privado (somente o proprietário)
+// @Tags Social
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param user_id path int true "ID do usuário solicitante"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/seguidores/{user_id}/aprovar [post]
+// @Security BearerAuth
+func (h *Handler) AprovarSeguidor(c *gin.Context) {
+	seguidorID, ok := h.parseUintParam(c, "user_id")
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.AprovarSeguidor(c.Request.Context(), c.Param("equinoid"), seguidorID, userID, userType); err != nil {
+		h.respondError(c, err, "Erro ao aprovar seguidor")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Seguidor aprovado",
+		Timestamp: time.Now(),
+	})
+}
+
+// RemoverSeguidor godoc
+// @Summary Remover seguidor
+// @Description Recusa a solicitação pendente ou remove um seguidor ativo (somente o proprietário)
+// @Tags Social
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param user_id path int true "ID do seguidor"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/seguidores/{user_id} [delete]
+// @Security BearerAuth
+func (h *Handler) RemoverSeguidor(c *gin.Context) {
+	seguidorID, ok := h.parseUintParam(c, "user_id")
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoverSeguidor(c.Request.Context(), c.Param("equinoid"), seguidorID, userID, userType); err != nil {
+		h.respondError(c, err, "Erro ao remover seguidor")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Seguidor removido",
+		Timestamp: time.Now(),
+	})
+}
+
+// ListSeguindo godoc
+// @Summary Equinos seguidos
+// @Description Equinos que o usuário autenticado segue ou aguarda aprovação para seguir
+// @Tags Social
+// @Produce json
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/seguindo [get]
+// @Security BearerAuth
+func (h *Handler) ListSeguindo(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := paginacao(c)
+
+	seguindo, total, err := h.service.ListSeguindo(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar equinos seguidos")
+		return
+	}
+
+	h.respondPaginated(c, fmt.Sprintf("Equinos seguidos (total: %d)", total), seguindo, page, limit, total)
+}
+
+// CreatePost godoc
+// @Summary Publicar post
+// @Description Publica um post no perfil social do equino (somente o proprietário). A legenda passa pelos filtros de moderação: pode ser recusada, publicada oculta até revisão ou enviada para a fila
+// @Tags Social
+// @Accept json
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param post body models.CreatePostRequest true "Dados do post"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/posts [post]
+// @Security BearerAuth
+func (h *Handler) CreatePost(c *gin.Context) {
+	var req models.CreatePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	post, err := h.service.CreatePost(c.Request.Context(), c.Param("equinoid"), userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao publicar post")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Post publicado com sucesso",
+		Timestamp: time.Now(),
+		Data:      post,
+	})
+}
+
+// ListPosts godoc
+// @Summary Listar posts do equino
+// @Description Posts ativos do perfil, do mais recente para o mais antigo, com paginação por cursor
+// @Tags Social
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param cursor query string false "Cursor devolvido em proximo_cursor"
+// @Param limit query int false "Itens por página (máx. 100)" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/posts [get]
+// @Security BearerAuth
+func (h *Handler) ListPosts(c *gin.Context) {
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	feed, err := h.service.ListPosts(c.Request.Context(), c.Param("equinoid"), userID, userType, c.Query("cursor"), limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar posts")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Posts do equino",
+		Timestamp: time.Now(),
+		Data:      feed,
+	})
+}
+
+// GetFeed godoc
+// @Summary Feed
+// @Description Posts dos equinos seguidos pelo usuário, do mais recente para o mais antigo, com paginação por cursor
+// @Tags Social
+// @Produce json
+// @Param cursor query string false "Cursor devolvido em proximo_cursor"
+// @Param limit query int false "Itens por página (máx. 100)" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/feed [get]
+// @Security BearerAuth
+func (h *Handler) GetFeed(c *gin.Context) {
+	userID, _, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	feed, err := h.service.GetFeed(c.Request.Context(), userID, c.Query("cursor"), limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao montar o feed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Feed",
+		Timestamp: time.Now(),
+		Data:      feed,
+	})
+}
+
+// GetPost godoc
+// @Summary Buscar post
+// @Description Post com os contadores de engajamento
+// @Tags Social
+// @Produce json
+// @Param id path int true "ID do post"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/posts/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetPost(c *gin.Context) {
+	id, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	post, err := h.service.GetPost(c.Request.Context(), id, userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar post")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Post encontrado",
+		Timestamp: time.Now(),
+		Data:      post,
+	})
+}
+
+// RemoverPost godoc
+// @Summary Remover post
+// @Description Retira o post do perfil e do feed (somente o proprietário)
+// @Tags Social
+// @Produce json
+// @Param id path int true "ID do post"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/posts/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) RemoverPost(c *gin.Context) {
+	id, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoverPost(c.Request.Context(), id, userID, userType); err != nil {
+		h.respondError(c, err, "Erro ao remover post")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Post removido",
+		Timestamp: time.Now(),
+	})
+}
+
+// Interagir godoc
+// @Summary Curtir, compartilhar ou reagir
+// @Description Registra a interação do usuário no post; repetir a chamada não duplica a interação nem os contadores
+// @Tags Social
+// @Produce json
+// @Param id path int true "ID do post"
+// @Param tipo path string true "curtida, compartilhamento, salvar, interesse, amor, risada ou surpresa"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/posts/{id}/interacoes/{tipo} [put]
+// @Security BearerAuth
+func (h *Handler) Interagir(c *gin.Context) {
+	id, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	post, err := h.service.Interagir(c.Request.Context(), id, models.TipoInteracao(c.Param("tipo")), userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao registrar interação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Interação registrada",
+		Timestamp: time.Now(),
+		Data:      post,
+	})
+}
+
+// RemoverInteracao godoc
+// @Summary Desfazer interação
+// @Description Remove a curtida, compartilhamento ou reação do usuário; idempotente
+// @Tags Social
+// @Produce json
+// @Param id path int true "ID do post"
+// @Param tipo path string true "Tipo da interação"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/posts/{id}/interacoes/{tipo} [delete]
+// @Security BearerAuth
+func (h *Handler) RemoverInteracao(c *gin.Context) {
+	id, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	post, err := h.service.RemoverInteracao(c.Request.Context(), id, models.TipoInteracao(c.Param("tipo")), userID, userType)
+	if err != nil {
+		h.respondError(c, err, "Erro ao remover interação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Interação removida",
+		Timestamp: time.Now(),
+		Data:      post,
+	})
+}
+
+// Comentar godoc
+// @Summary Comentar post
+// @Description Comenta o post ou responde a um comentário (parent_id), até 3 níveis por thread. O texto passa pelos filtros de moderação
+// @Tags Social
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do post"
+// @Param comentario body models.CreateComentarioRequest true "Comentário"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/posts/{id}/comentarios [post]
+// @Security BearerAuth
+func (h *Handler) Comentar(c *gin.Context) {
+	id, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateComentarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	comentario, err := h.service.Comentar(c.Request.Context(), id, userID, userType, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao comentar post")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Comentário publicado",
+		Timestamp: time.Now(),
+		Data:      comentario,
+	})
+}
+
+// ListComentarios godoc
+// @Summary Listar comentários
+// @Description Comentários de primeiro nível em ordem cronológica, com as respostas aninhadas
+// @Tags Social
+// @Produce json
+// @Param id path int true "ID do post"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/posts/{id}/comentarios [get]
+// @Security BearerAuth
+func (h *Handler) ListComentarios(c *gin.Context) {
+	id, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+	page, limit := paginacao(c)
+
+	comentarios, total, err := h.service.ListComentarios(c.Request.Context(), id, userID, userType, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar comentários")
+		return
+	}
+
+	h.respondPaginated(c, fmt.Sprintf("Comentários (total: %d)", total), comentarios, page, limit, total)
+}
+
+// RemoverComentario godoc
+// @Summary Excluir comentário
+// @Description Exclui o comentário e as respostas da thread (autor ou proprietário do equino)
+// @Tags Social
+// @Produce json
+// @Param id path int true "ID do comentário"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /social/comentarios/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) RemoverComentario(c *gin.Context) {
+	id, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, userType, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoverComentario(c.Request.Context(), id, userID, userType); err != nil {
+		h.respondError(c, err, "Erro ao excluir comentário")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Comentário excluído",
+		Timestamp: time.Now(),
+	})
+}
+
+func paginacao(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > limitePaginaMaximo {
+		limit = limitePaginaMaximo
+	}
+	return page, limit
+}
+
+func (h *Handler) respondPaginated(c *gin.Context, message string, data interface{}, page, limit int, total int64) {
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: data,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: int((total + int64(limit) - 1) / int64(limit)),
+			},
+		},
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, string, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, "", false
+	}
+	userType, _ := middleware.GetUserTypeFromContext(c)
+	return userID, userType, true
+}
+
+func (h *Handler) parseUintParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *Handler) badRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Success:   false,
+		Error:     "Dados inválidos: " + err.Error(),
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsAuthorization(err):
+		status = http.StatusForbidden
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	case apperrors.IsBusiness(err):
+		// limite de publicação de autores reincidentes
+		status = http.StatusTooManyRequests
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,601 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// colunasContador contador do post e do perfil alimentado por cada tipo de interação; os demais tipos não são contados
+var colunasContador = map[models.TipoInteracao]string{
+	models.TipoInteracaoCurtida:          "total_curtidas",
+	models.TipoInteracaoCompartilhamento: "total_compartilhamentos",
+}
+
+// CursorFeed posição após o último post da página anterior
+type CursorFeed struct {
+	DataPostagem time.Time
+	ID           uint
+}
+
+// FiltroPosts posts de um equino ou dos equinos seguidos por um usuário; sem Status, apenas os ativos
+type FiltroPosts struct {
+	Equinoid         string
+	SeguidorID       uint
+	Status           []models.StatusPost
+	IncluirExpirados bool
+	Agora            time.Time
+	Cursor           *CursorFeed
+	Limit            int
+}
+
+type Repository interface {
+	FindPerfil(ctx context.Context, equinoid string) (*models.PerfilSocial, error)
+	CreatePerfil(ctx context.Context, perfil *models.PerfilSocial) error
+	UpdatePerfil(ctx context.Context, perfil *models.PerfilSocial) error
+	RestringirPerfis(ctx context.Context, userID uint) (int64, error)
+
+	FindSeguir(ctx context.Context, userID uint, equinoid string) (*models.SeguirEquino, error)
+	Seguir(ctx context.Context, seguir *models.SeguirEquino) (bool, error)
+	DeixarDeSeguir(ctx context.Context, userID uint, equinoid string) (bool, error)
+	AprovarSeguidor(ctx context.Context, userID uint, equinoid string) (bool, error)
+	ListSeguidores(ctx context.Context, equinoid string, status models.StatusSeguir, page, limit int) ([]*models.SeguirEquino, int64, error)
+	ListSeguindo(ctx context.Context, userID uint, page, limit int) ([]*models.SeguirEquino, int64, error)
+
+	CreatePost(ctx context.Context, post *models.PostSocial) error
+	FindPost(ctx context.Context, id uint) (*models.PostSocial, error)
+	AlterarStatusPost(ctx context.Context, id uint, de []models.StatusPost, para models.StatusPost) (bool, error)
+	ListPosts(ctx context.Context, filtro FiltroPosts) ([]*models.PostSocial, error)
+
+	Interagir(ctx context.Context, interacao *models.InteracaoSocial, perfilID uint) (bool, error)
+	RemoverInteracao(ctx context.Context, postID, userID uint, tipo models.TipoInteracao, perfilID uint) (bool, error)
+
+	CreateComentario(ctx context.Context, comentario *models.ComentarioSocial, perfilID uint) error
+	FindComentario(ctx context.Context, id uint) (*models.ComentarioSocial, error)
+	AlterarStatusComentario(ctx context.Context, comentario *models.ComentarioSocial, perfilID uint, de []models.StatusPost, para models.StatusPost) (bool, error)
+	RemoverComentario(ctx context.Context, comentario *models.ComentarioSocial, perfilID uint) (int64, error)
+	ListComentarios(ctx context.Context, postID uint, viewerID uint, page, limit int) ([]*models.ComentarioSocial, int64, error)
+
+	RecalcularContadores(ctx context.Context) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindPerfil(ctx context.Context, equinoid string) (*models.PerfilSocial, error) {
+	var perfil models.PerfilSocial
+	if err := r.db.WithContext(ctx).Preload("Equino").Where("equinoid = ?", equinoid).First(&perfil).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "perfil_social", Message: "perfil social não encontrado", ID: equinoid}
+		}
+		return nil, apperrors.NewDatabaseError("find_perfil_social", "erro ao buscar perfil social", err)
+	}
+	return &perfil, nil
+}
+
+func (r *repository) CreatePerfil(ctx context.Context, perfil *models.PerfilSocial) error {
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(perfil).Error; err != nil {
+		return apperrors.NewDatabaseError("create_perfil_social", "erro ao criar perfil social", err)
+	}
+	return nil
+}
+
+// UpdatePerfil grava apenas os campos editáveis, sem sobrescrever os contadores; perfis que deixam de ser privados
+// aprovam as solicitações pendentes na mesma transação
+func (r *repository) UpdatePerfil(ctx context.Context, perfil *models.PerfilSocial) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(perfil).
+			Select("nome_perfil", "bio", "localizacao", "status_disponibilidade", "tipo_perfil",
+				"mostrar_localizacao", "permitir_ofertas", "permitir_contato", "permitir_seguir", "updated_at").
+			Updates(perfil).Error
+		if err != nil || perfil.TipoPerfil == models.TipoPerfilPrivado {
+			return err
+		}
+
+		result := tx.Model(&models.SeguirEquino{}).
+			Where("equinoid = ? AND status = ?", perfil.Equinoid, models.StatusSeguirPendente).
+			Updates(map[string]interface{}{"status": models.StatusSeguirAtivo, "aprovado_em": time.Now()})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.PerfilSocial{}).Where("id = ?", perfil.ID).
+			UpdateColumn("total_seguidores", gorm.Expr("total_seguidores + ?", result.RowsAffected)).Error
+	})
+	if err != nil {
+		return apperrors.NewDatabaseError("update_perfil_social", "erro ao atualizar perfil social", err)
+	}
+	return nil
+}
+
+// RestringirPerfis torna privados os perfis públicos criados pelo usuário ou dos seus equinos
+func (r *repository) RestringirPerfis(ctx context.Context, userID uint) (int64, error) {
+	equinoids := r.db.Model(&models.Equino{}).Select("equinoid").Where("proprietario_id = ?", userID)
+
+	result := r.db.WithContext(ctx).Model(&models.PerfilSocial{}).
+		Where("tipo_perfil = ?", models.TipoPerfilPublico).
+		Where("criado_por = ? OR equinoid IN (?)", userID, equinoids).
+		Updates(map[string]interface{}{
+			"tipo_perfil": models.TipoPerfilPrivado,
+			"updated_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return 0, apperrors.NewDatabaseError("restringir_perfis", "erro ao restringir perfis sociais", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *repository) FindSeguir(ctx context.Context, userID uint, equinoid string) (*models.SeguirEquino, error) {
+	var seguir models.SeguirEquino
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND equinoid = ?", userID, equinoid).First(&seguir).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "seguidor", Message: "usuário não segue este equino", ID: equinoid}
+		}
+		return nil, apperrors.NewDatabaseError("find_seguir", "erro ao buscar seguidor", err)
+	}
+	return &seguir, nil
+}
+
+// Seguir cria o vínculo se ainda não existir; o contador de seguidores só muda quando a linha é criada já ativa
+func (r *repository) Seguir(ctx context.Context, seguir *models.SeguirEquino) (bool, error) {
+	criado := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(seguir)
+		if result.Error != nil {
+			return result.Error
+		}
+		criado = result.RowsAffected == 1
+		if !criado || seguir.Status != models.StatusSeguirAtivo {
+			return nil
+		}
+		return tx.Model(&models.PerfilSocial{}).Where("equinoid = ?", seguir.Equinoid).
+			UpdateColumn("total_seguidores", gorm.Expr("total_seguidores + 1")).Error
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("seguir_equino", "erro ao seguir equino", err)
+	}
+	return criado, nil
+}
+
+// DeixarDeSeguir exclui o vínculo ativo ou a solicitação pendente; só vínculos ativos descontam do contador
+func (r *repository) DeixarDeSeguir(ctx context.Context, userID uint, equinoid string) (bool, error) {
+	removido := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ativos := tx.Unscoped().Where("user_id = ? AND equinoid = ? AND status = ?", userID, equinoid, models.StatusSeguirAtivo).
+			Delete(&models.SeguirEquino{})
+		if ativos.Error != nil {
+			return ativos.Error
+		}
+		pendentes := tx.Unscoped().Where("user_id = ? AND equinoid = ?", userID, equinoid).Delete(&models.SeguirEquino{})
+		if pendentes.Error != nil {
+			return pendentes.Error
+		}
+		removido = ativos.RowsAffected+pendentes.RowsAffected > 0
+		if ativos.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Model(&models.PerfilSocial{}).Where("equinoid = ?", equinoid).
+			UpdateColumn("total_seguidores", gorm.Expr("total_seguidores - ?", ativos.RowsAffected)).Error
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("deixar_de_seguir", "erro ao deixar de seguir equino", err)
+	}
+	return removido, nil
+}
+
+func (r *repository) AprovarSeguidor(ctx context.Context, userID uint, equinoid string) (bool, error) {
+	aprovado := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.SeguirEquino{}).
+			Where("user_id = ? AND equinoid = ? AND status = ?", userID, equinoid, models.StatusSeguirPendente).
+			Updates(map[string]interface{}{"status": models.StatusSeguirAtivo, "aprovado_em": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		aprovado = result.RowsAffected == 1
+		if !aprovado {
+			return nil
+		}
+		return tx.Model(&models.PerfilSocial{}).Where("equinoid = ?", equinoid).
+			UpdateColumn("total_seguidores", gorm.Expr("total_seguidores + 1")).Error
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("aprovar_seguidor", "erro ao aprovar seguidor", err)
+	}
+	return aprovado, nil
+}
+
+func (r *repository) ListSeguidores(ctx context.Context, equinoid string, status models.StatusSeguir, page, limit int) ([]*models.SeguirEquino, int64, error) {
+	var seguidores []*models.SeguirEquino
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.SeguirEquino{}).Where("equinoid = ? AND status = ?", equinoid, status)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_seguidores", "erro ao contar seguidores", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Preload("User").Offset(offset).Limit(limit).Order("created_at DESC, id DESC").Find(&seguidores).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_seguidores", "erro ao listar seguidores", err)
+	}
+	return seguidores, total, nil
+}
+
+func (r *repository) ListSeguindo(ctx context.Context, userID uint, page, limit int) ([]*models.SeguirEquino, int64, error) {
+	var seguindo []*models.SeguirEquino
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.SeguirEquino{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_seguindo", "erro ao contar equinos seguidos", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Preload("Equino").Offset(offset).Limit(limit).Order("created_at DESC, id DESC").Find(&seguindo).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_seguindo", "erro ao listar equinos seguidos", err)
+	}
+	return seguindo, total, nil
+}
+
+// CreatePost grava o post; posts ocultos pela moderação não entram no contador do perfil
+func (r *repository) CreatePost(ctx context.Context, post *models.PostSocial) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(post).Error; err != nil {
+			return err
+		}
+		if post.StatusPost != models.StatusPostAtivo {
+			return nil
+		}
+		return tx.Model(&models.PerfilSocial{}).Where("id = ?", post.PerfilSocialID).
+			UpdateColumn("total_posts", gorm.Expr("total_posts + 1")).Error
+	})
+	if err != nil {
+		return apperrors.NewDatabaseError("create_post", "erro ao criar post", err)
+	}
+	return nil
+}
+
+func (r *repository) FindPost(ctx context.Context, id uint) (*models.PostSocial, error) {
+	var post models.PostSocial
+	if err := r.db.WithContext(ctx).Preload("PerfilSocial.Equino").Where("id = ?", id).First(&post).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "post", Message: "post não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_post", "erro ao buscar post", err)
+	}
+	return &post, nil
+}
+
+// AlterarStatusPost muda o status do post se ele estiver em um dos status de origem; ao entrar ou sair do ar
+// (status ativo) o perfil ganha ou perde o próprio post e o engajamento que ele soma
+func (r *repository) AlterarStatusPost(ctx context.Context, id uint, de []models.StatusPost, para models.StatusPost) (bool, error) {
+	alterado := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var post models.PostSocial
+		if err := tx.Where("id = ? AND status_post IN ?", id, de).First(&post).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if post.StatusPost == para {
+			return nil
+		}
+
+		result := tx.Model(&models.PostSocial{}).Where("id = ? AND status_post = ?", id, post.StatusPost).
+			Updates(map[string]interface{}{"status_post": para, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		alterado = result.RowsAffected == 1
+
+		var sinal int
+		switch {
+		case !alterado:
+			return nil
+		case post.StatusPost == models.StatusPostAtivo:
+			sinal = -1
+		case para == models.StatusPostAtivo:
+			sinal = 1
+		default:
+			return nil
+		}
+		return tx.Model(&models.PerfilSocial{}).Where("id = ?", post.PerfilSocialID).UpdateColumns(map[string]interface{}{
+			"total_posts":             gorm.Expr("total_posts + ?", sinal),
+			"total_curtidas":          gorm.Expr("total_curtidas + ?", sinal*post.TotalCurtidas),
+			"total_comentarios":       gorm.Expr("total_comentarios + ?", sinal*post.TotalComentarios),
+			"total_compartilhamentos": gorm.Expr("total_compartilhamentos + ?", sinal*post.TotalCompartilhamentos),
+		}).Error
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("alterar_status_post", "erro ao alterar status do post", err)
+	}
+	return alterado, nil
+}
+
+// ListPosts posts do mais recente para o mais antigo, com um item a mais que o limite para indicar a próxima página
+func (r *repository) ListPosts(ctx context.Context, filtro FiltroPosts) ([]*models.PostSocial, error) {
+	status := filtro.Status
+	if len(status) == 0 {
+		status = []models.StatusPost{models.StatusPostAtivo}
+	}
+	query := r.db.WithContext(ctx).Model(&models.PostSocial{}).
+		Preload("PerfilSocial").
+		Where("post_socials.status_post IN ?", status)
+
+	if filtro.Equinoid != "" {
+		query = query.Where("post_socials.equinoid = ?", filtro.Equinoid)
+	}
+	if filtro.SeguidorID != 0 {
+		query = query.Joins("JOIN seguir_equinos ON seguir_equinos.equinoid = post_socials.equinoid AND seguir_equinos.user_id = ? AND seguir_equinos.status = ? AND seguir_equinos.deleted_at IS NULL",
+			filtro.SeguidorID, models.StatusSeguirAtivo)
+	}
+	if !filtro.IncluirExpirados {
+		query = query.Where("(post_socials.data_expiracao IS NULL OR post_socials.data_expiracao > ?)", filtro.Agora)
+	}
+	if filtro.Cursor != nil {
+		query = query.Where("(post_socials.data_postagem < ? OR (post_socials.data_postagem = ? AND post_socials.id < ?))",
+			filtro.Cursor.DataPostagem, filtro.Cursor.DataPostagem, filtro.Cursor.ID)
+	}
+
+	var posts []*models.PostSocial
+	if err := query.Order("post_socials.data_postagem DESC, post_socials.id DESC").Limit(filtro.Limit + 1).Find(&posts).Error; err != nil {
+		return nil, apperrors.NewDatabaseError("list_posts", "erro ao listar posts", err)
+	}
+	return posts, nil
+}
+
+// Interagir registra a interação uma única vez; repetir a mesma interação não altera os contadores
+func (r *repository) Interagir(ctx context.Context, interacao *models.InteracaoSocial, perfilID uint) (bool, error) {
+	criada := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(interacao)
+		if result.Error != nil {
+			return result.Error
+		}
+		criada = result.RowsAffected == 1
+		coluna, contada := colunasContador[interacao.TipoInteracao]
+		if !criada || !contada {
+			return nil
+		}
+		return incrementar(tx, coluna, interacao.PostID, perfilID, 1)
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("create_interacao", "erro ao registrar interação", err)
+	}
+	return criada, nil
+}
+
+func (r *repository) RemoverInteracao(ctx context.Context, postID, userID uint, tipo models.TipoInteracao, perfilID uint) (bool, error) {
+	removida := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("post_id = ? AND user_id = ? AND tipo_interacao = ?", postID, userID, tipo).
+			Delete(&models.InteracaoSocial{})
+		if result.Error != nil {
+			return result.Error
+		}
+		removida = result.RowsAffected > 0
+		coluna, contada := colunasContador[tipo]
+		if !removida || !contada {
+			return nil
+		}
+		return incrementar(tx, coluna, postID, perfilID, -result.RowsAffected)
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("remover_interacao", "erro ao remover interação", err)
+	}
+	return removida, nil
+}
+
+// CreateComentario grava o comentário; comentários ocultos pela moderação não entram nos contadores
+func (r *repository) CreateComentario(ctx context.Context, comentario *models.ComentarioSocial, perfilID uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(comentario).Error; err != nil {
+			return err
+		}
+		if comentario.Status != models.StatusPostAtivo {
+			return nil
+		}
+		return incrementar(tx, "total_comentarios", comentario.PostID, perfilID, 1)
+	})
+	if err != nil {
+		return apperrors.NewDatabaseError("create_comentario", "erro ao criar comentário", err)
+	}
+	return nil
+}
+
+func (r *repository) FindComentario(ctx context.Context, id uint) (*models.ComentarioSocial, error) {
+	var comentario models.ComentarioSocial
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&comentario).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "comentario", Message: "comentário não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_comentario", "erro ao buscar comentário", err)
+	}
+	return &comentario, nil
+}
+
+// AlterarStatusComentario muda o status do comentário se ele estiver em um dos status de origem, ajustando os
+// contadores quando o comentário entra ou sai do ar
+func (r *repository) AlterarStatusComentario(ctx context.Context, comentario *models.ComentarioSocial, perfilID uint, de []models.StatusPost, para models.StatusPost) (bool, error) {
+	alterado := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var atual models.ComentarioSocial
+		if err := tx.Where("id = ? AND status IN ?", comentario.ID, de).First(&atual).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if atual.Status == para {
+			return nil
+		}
+
+		result := tx.Model(&models.ComentarioSocial{}).Where("id = ? AND status = ?", atual.ID, atual.Status).
+			Updates(map[string]interface{}{"status": para, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		alterado = result.RowsAffected == 1
+
+		switch {
+		case !alterado:
+			return nil
+		case atual.Status == models.StatusPostAtivo:
+			return incrementar(tx, "total_comentarios", atual.PostID, perfilID, -1)
+		case para == models.StatusPostAtivo:
+			return incrementar(tx, "total_comentarios", atual.PostID, perfilID, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, apperrors.NewDatabaseError("alterar_status_comentario", "erro ao alterar status do comentário", err)
+	}
+	if alterado {
+		comentario.Status = para
+	}
+	return alterado, nil
+}
+
+// RemoverComentario exclui o comentário com todas as respostas da thread e devolve quantos foram excluídos
+func (r *repository) RemoverComentario(ctx context.Context, comentario *models.ComentarioSocial, perfilID uint) (int64, error) {
+	var removidos int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := []uint{comentario.ID}
+		for nivel := ids; len(nivel) > 0; {
+			var respostas []uint
+			if err := tx.Model(&models.ComentarioSocial{}).Where("parent_id IN ?", nivel).Pluck("id", &respostas).Error; err != nil {
+				return err
+			}
+			ids = append(ids, respostas...)
+			nivel = respostas
+		}
+
+		var ativos int64
+		if err := tx.Model(&models.ComentarioSocial{}).Where("id IN ? AND status = ?", ids, models.StatusPostAtivo).Count(&ativos).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id IN ?", ids).Delete(&models.ComentarioSocial{})
+		if result.Error != nil {
+			return result.Error
+		}
+		removidos = result.RowsAffected
+		if ativos == 0 {
+			return nil
+		}
+		return incrementar(tx, "total_comentarios", comentario.PostID, perfilID, -ativos)
+	})
+	if err != nil {
+		return 0, apperrors.NewDatabaseError("remover_comentario", "erro ao remover comentário", err)
+	}
+	return removidos, nil
+}
+
+// ListComentarios comentários de primeiro nível em ordem cronológica, com as respostas aninhadas; comentários
+// ocultos ou retirados pela moderação aparecem apenas para o próprio autor
+func (r *repository) ListComentarios(ctx context.Context, postID uint, viewerID uint, page, limit int) ([]*models.ComentarioSocial, int64, error) {
+	var comentarios []*models.ComentarioSocial
+	var total int64
+
+	visivel := "(status = ? OR (user_id = ? AND status IN ?))"
+	moderados := []models.StatusPost{models.StatusPostOculto, models.StatusPostRetirado}
+
+	query := r.db.WithContext(ctx).Model(&models.ComentarioSocial{}).
+		Where("post_id = ? AND parent_id IS NULL", postID).
+		Where(visivel, models.StatusPostAtivo, viewerID, moderados)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_comentarios", "erro ao contar comentários", err)
+	}
+
+	cronologica := func(db *gorm.DB) *gorm.DB {
+		return db.Where(visivel, models.StatusPostAtivo, viewerID, moderados).Order("created_at ASC, id ASC")
+	}
+	query = query.Preload("User")
+	respostas := "Respostas"
+	for nivel := 1; nivel < maxProfundidadeComentario; nivel++ {
+		query = query.Preload(respostas, cronologica).Preload(respostas + ".User")
+		respostas += ".Respostas"
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Offset(offset).Limit(limit).Order("created_at ASC, id ASC").Find(&comentarios).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_comentarios", "erro ao listar comentários", err)
+	}
+	return comentarios, total, nil
+}
+
+// contador coluna recalculada a partir da subconsulta sobre a tabela de origem
+type contador struct {
+	coluna      string
+	subconsulta string
+	args        []interface{}
+}
+
+// RecalcularContadores reconstrói os contadores de posts e perfis a partir de interações, comentários e seguidores e
+// devolve quantas linhas estavam divergentes
+func (r *repository) RecalcularContadores(ctx context.Context) (int64, error) {
+	postsAtivos := "FROM post_socials WHERE post_socials.perfil_social_id = perfil_socials.id AND post_socials.status_post = ? AND post_socials.deleted_at IS NULL"
+	interacoes := "SELECT COUNT(*) FROM interacao_socials WHERE interacao_socials.post_id = post_socials.id AND interacao_socials.tipo_interacao = ? AND interacao_socials.deleted_at IS NULL"
+
+	var corrigidos int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		posts, err := recalcular(tx, "post_socials", []contador{
+			{"total_curtidas", interacoes, []interface{}{models.TipoInteracaoCurtida}},
+			{"total_compartilhamentos", interacoes, []interface{}{models.TipoInteracaoCompartilhamento}},
+			{"total_comentarios", "SELECT COUNT(*) FROM comentario_socials WHERE comentario_socials.post_id = post_socials.id AND comentario_socials.status = ? AND comentario_socials.deleted_at IS NULL", []interface{}{models.StatusPostAtivo}},
+		})
+		if err != nil {
+			return err
+		}
+
+		perfis, err := recalcular(tx, "perfil_socials", []contador{
+			{"total_seguidores", "SELECT COUNT(*) FROM seguir_equinos WHERE seguir_equinos.equinoid = perfil_socials.equinoid AND seguir_equinos.status = ? AND seguir_equinos.deleted_at IS NULL", []interface{}{models.StatusSeguirAtivo}},
+			{"total_posts", "SELECT COUNT(*) " + postsAtivos, []interface{}{models.StatusPostAtivo}},
+			{"total_curtidas", "SELECT COALESCE(SUM(post_socials.total_curtidas), 0) " + postsAtivos, []interface{}{models.StatusPostAtivo}},
+			{"total_comentarios", "SELECT COALESCE(SUM(post_socials.total_comentarios), 0) " + postsAtivos, []interface{}{models.StatusPostAtivo}},
+			{"total_compartilhamentos", "SELECT COALESCE(SUM(post_socials.total_compartilhamentos), 0) " + postsAtivos, []interface{}{models.StatusPostAtivo}},
+		})
+		corrigidos = posts + perfis
+		return err
+	})
+	if err != nil {
+		return 0, apperrors.NewDatabaseError("recalcular_contadores", "erro ao recalcular contadores sociais", err)
+	}
+	return corrigidos, nil
+}
+
+// recalcular atualiza só as linhas em que algum contador diverge da contagem de origem
+func recalcular(tx *gorm.DB, tabela string, contadores []contador) (int64, error) {
+	sets := make([]string, 0, len(contadores))
+	divergencias := make([]string, 0, len(contadores))
+	var setArgs, divergenciaArgs []interface{}
+	for _, c := range contadores {
+		sets = append(sets, fmt.Sprintf("%s = (%s)", c.coluna, c.subconsulta))
+		divergencias = append(divergencias, fmt.Sprintf("%s <> (%s)", c.coluna, c.subconsulta))
+		setArgs = append(setArgs, c.args...)
+		divergenciaArgs = append(divergenciaArgs, c.args...)
+	}
+
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s", tabela, strings.Join(sets, ", "), strings.Join(divergencias, " OR "))
+	result := tx.Exec(sql, append(setArgs, divergenciaArgs...)...)
+	return result.RowsAffected, result.Error
+}
+
+// incrementar aplica o delta ao contador do post e ao do perfil na mesma transação da alteração de origem
+func incrementar(tx *gorm.DB, coluna string, postID, perfilID uint, delta int64) error {
+	if err := tx.Model(&models.PostSocial{}).Where("id = ?", postID).UpdateColumn(coluna, gorm.Expr(coluna+" + ?", delta)).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.PerfilSocial{}).Where("id = ?", perfilID).UpdateColumn(coluna, gorm.Expr(coluna+" + ?", delta)).Error
+}
@@ -0,0 +1,36 @@
+package social
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	equinos := rg.Group("/equinos")
+	equinos.Use(authMiddleware)
+	{
+		equinos.GET("/:equinoid/perfil-social", handler.GetPerfil)
+		equinos.POST("/:equinoid/perfil-social", handler.CreatePerfil)
+		equinos.PUT("/:equinoid/perfil-social", handler.UpdatePerfil)
+		equinos.GET("/:equinoid/posts", handler.ListPosts)
+		equinos.POST("/:equinoid/posts", handler.CreatePost)
+		equinos.POST("/:equinoid/seguir", handler.Seguir)
+		equinos.DELETE("/:equinoid/seguir", handler.DeixarDeSeguir)
+		equinos.GET("/:equinoid/seguidores", handler.ListSeguidores)
+		equinos.POST("/:equinoid/seguidores/:user_id/aprovar", handler.AprovarSeguidor)
+		equinos.DELETE("/:equinoid/seguidores/:user_id", handler.RemoverSeguidor)
+	}
+
+	social := rg.Group("/social")
+	social.Use(authMiddleware)
+	{
+		social.GET("/feed", handler.GetFeed)
+		social.GET("/seguindo", handler.ListSeguindo)
+		social.GET("/posts/:id", handler.GetPost)
+		social.DELETE("/posts/:id", handler.RemoverPost)
+		social.PUT("/posts/:id/interacoes/:tipo", handler.Interagir)
+		social.DELETE("/posts/:id/interacoes/:tipo", handler.RemoverInteracao)
+		social.GET("/posts/:id/comentarios", handler.ListComentarios)
+		social.POST("/posts/:id/comentarios", handler.Comentar)
+		social.DELETE("/comentarios/:id", handler.RemoverComentario)
+	}
+}
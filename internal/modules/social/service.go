@@ -0,0 +1,857 @@
+package social
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+const (
+	// maxProfundidadeComentario níveis de uma thread: comentário, resposta e resposta à resposta
+	maxProfundidadeComentario = 3
+	maxConteudoComentario     = 2000
+	limitePaginaMaximo        = 100
+)
+
+// statusVisiveisAutor status em que o post continua aparecendo para o proprietário
+var statusVisiveisAutor = []models.StatusPost{models.StatusPostAtivo, models.StatusPostOculto, models.StatusPostRetirado}
+
+// ConsentChecker verifica o consentimento do titular antes de tratamentos que dependem dele
+type ConsentChecker interface {
+	RequireConsent(ctx context.Context, userID uint, purpose string) error
+}
+
+// Moderador filtros aplicados antes da publicação e encaminhamento de conteúdo para a fila de moderação
+type Moderador interface {
+	AvaliarPublicacao(ctx context.Context, autorID uint, texto string) (*models.AvaliacaoModeracao, error)
+	EncaminharRevisao(ctx context.Context, avaliacao *models.AvaliacaoModeracao, tipo models.TipoConteudoModerado, conteudoID, autorID uint) error
+}
+
+// Seguimento estado do vínculo do usuário com o equino após seguir ou deixar de seguir
+type Seguimento struct {
+	Equinoid        string              `json:"equinoid"`
+	Status          models.StatusSeguir `json:"status,omitempty"`
+	Seguindo        bool                `json:"seguindo"`
+	TotalSeguidores int                 `json:"total_seguidores"`
+}
+
+type Service interface {
+	CreatePerfil(ctx context.Context, equinoid string, userID uint, userType string, req *models.CreatePerfilSocialRequest) (*models.PerfilSocial, error)
+	GetPerfil(ctx context.Context, equinoid string, userID uint, userType string) (*models.PerfilSocial, error)
+	UpdatePerfil(ctx context.Context, equinoid string, userID uint, userType string, req *models.UpdatePerfilSocialRequest) (*models.PerfilSocial, error)
+	RestringirPerfisPublicos(ctx context.Context, userID uint) error
+
+	Seguir(ctx context.Context, equinoid string, userID uint) (*Seguimento, error)
+	DeixarDeSeguir(ctx context.Context, equinoid string, userID uint) (*Seguimento, error)
+	ListSeguidores(ctx context.Context, equinoid string, userID uint, userType string, status models.StatusSeguir, page, limit int) ([]*models.SeguirEquino, int64, error)
+	AprovarSeguidor(ctx context.Context, equinoid string, seguidorID uint, userID uint, userType string) error
+	RemoverSeguidor(ctx context.Context, equinoid string, seguidorID uint, userID uint, userType string) error
+	ListSeguindo(ctx context.Context, userID uint, page, limit int) ([]*models.SeguirEquino, int64, error)
+
+	CreatePost(ctx context.Context, equinoid string, userID uint, userType string, req *models.CreatePostRequest) (*models.PostSocial, error)
+	GetPost(ctx context.Context, id uint, userID uint, userType string) (*models.PostSocial, error)
+	RemoverPost(ctx context.Context, id uint, userID uint, userType string) error
+	ListPosts(ctx context.Context, equinoid string, userID uint, userType string, cursor string, limit int) (*models.FeedSocial, error)
+	GetFeed(ctx context.Context, userID uint, cursor string, limit int) (*models.FeedSocial, error)
+
+	Interagir(ctx context.Context, postID uint, tipo models.TipoInteracao, userID uint, userType string) (*models.PostSocial, error)
+	RemoverInteracao(ctx context.Context, postID uint, tipo models.TipoInteracao, userID uint, userType string) (*models.PostSocial, error)
+
+	Comentar(ctx context.Context, postID uint, userID uint, userType string, req *models.CreateComentarioRequest) (*models.ComentarioSocial, error)
+	ListComentarios(ctx context.Context, postID uint, userID uint, userType string, page, limit int) ([]*models.ComentarioSocial, int64, error)
+	RemoverComentario(ctx context.Context, id uint, userID uint, userType string) error
+
+	RecalcularContadores(ctx context.Context) (int64, error)
+}
+
+type service struct {
+	repo       Repository
+	equinoRepo equinos.Repository
+	consent    ConsentChecker
+	moderador  Moderador
+	logger     *logging.Logger
+}
+
+func NewService(repo Repository, equinoRepo equinos.Repository, consent ConsentChecker, moderador Moderador, logger *logging.Logger) Service {
+	return &service{
+		repo:       repo,
+		equinoRepo: equinoRepo,
+		consent:    consent,
+		moderador:  moderador,
+		logger:     logger,
+	}
+}
+
+// cursorFeed conteúdo opaco do cursor: data de postagem e id do último post da página
+type cursorFeed struct {
+	DataPostagem time.Time `json:"d"`
+	ID           uint      `json:"id"`
+}
+
+func (s *service) CreatePerfil(ctx context.Context, equinoid string, userID uint, userType string, req *models.CreatePerfilSocialRequest) (*models.PerfilSocial, error) {
+	equino, err := s.findEquino(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if !isOwner(equino, userID, userType) {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o proprietário pode criar o perfil social do equino"}).WithAction("criar_perfil_social", "equino")
+	}
+
+	if _, err := s.repo.FindPerfil(ctx, equino.Equinoid); err == nil {
+		return nil, &apperrors.ConflictError{Resource: "perfil_social", Message: "perfil social já existe para este equino", Value: equino.Equinoid}
+	} else if !apperrors.IsNotFound(err) {
+		s.logger.LogError(err, "SocialService.CreatePerfil", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+
+	tipo := req.TipoPerfil
+	if tipo == "" {
+		tipo = models.TipoPerfilPublico
+	}
+	if err := s.checkTipoPerfil(ctx, tipo, equino.ProprietarioID); err != nil {
+		return nil, err
+	}
+	disponibilidade := req.StatusDisponibilidade
+	if disponibilidade == "" {
+		disponibilidade = models.StatusDisponivel
+	}
+	if !isValidDisponibilidade(disponibilidade) {
+		return nil, &apperrors.ValidationError{Field: "status_disponibilidade", Message: "status de disponibilidade inválido", Value: disponibilidade}
+	}
+	nome := strings.TrimSpace(req.NomePerfil)
+	if nome == "" {
+		nome = equino.Nome
+	}
+
+	perfil := &models.PerfilSocial{
+		Equinoid:              equino.Equinoid,
+		NomePerfil:            nome,
+		Bio:                   req.Bio,
+		Localizacao:           req.Localizacao,
+		StatusDisponibilidade: disponibilidade,
+		TipoPerfil:            tipo,
+		MostrarLocalizacao:    valorOuPadrao(req.MostrarLocalizacao),
+		PermitirOfertas:       valorOuPadrao(req.PermitirOfertas),
+		PermitirContato:       valorOuPadrao(req.PermitirContato),
+		PermitirSeguir:        valorOuPadrao(req.PermitirSeguir),
+		CriadoPor:             userID,
+	}
+	if err := s.repo.CreatePerfil(ctx, perfil); err != nil {
+		s.logger.LogError(err, "SocialService.CreatePerfil", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+
+	s.logger.LogBusinessEvent("perfil_social_criado", "Perfil social criado", userID, equino.Equinoid, logging.Fields{"tipo_perfil": tipo})
+	return perfil, nil
+}
+
+func (s *service) GetPerfil(ctx context.Context, equinoid string, userID uint, userType string) (*models.PerfilSocial, error) {
+	perfil, err := s.findPerfil(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkVisivel(ctx, perfil, userID, userType); err != nil {
+		return nil, err
+	}
+	if !perfil.MostrarLocalizacao && !isOwner(perfil.Equino, userID, userType) {
+		perfil.Localizacao = ""
+	}
+	return perfil, nil
+}
+
+func (s *service) UpdatePerfil(ctx context.Context, equinoid string, userID uint, userType string, req *models.UpdatePerfilSocialRequest) (*models.PerfilSocial, error) {
+	perfil, err := s.findPerfilAsOwner(ctx, equinoid, userID, userType, "editar_perfil_social")
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TipoPerfil != nil && *req.TipoPerfil != perfil.TipoPerfil {
+		if err := s.checkTipoPerfil(ctx, *req.TipoPerfil, perfil.Equino.ProprietarioID); err != nil {
+			return nil, err
+		}
+		perfil.TipoPerfil = *req.TipoPerfil
+	}
+	if req.StatusDisponibilidade != nil {
+		if !isValidDisponibilidade(*req.StatusDisponibilidade) {
+			return nil, &apperrors.ValidationError{Field: "status_disponibilidade", Message: "status de disponibilidade inválido", Value: *req.StatusDisponibilidade}
+		}
+		perfil.StatusDisponibilidade = *req.StatusDisponibilidade
+	}
+	if req.NomePerfil != nil && strings.TrimSpace(*req.NomePerfil) != "" {
+		perfil.NomePerfil = strings.TrimSpace(*req.NomePerfil)
+	}
+	if req.Bio != nil {
+		perfil.Bio = *req.Bio
+	}
+	if req.Localizacao != nil {
+		perfil.Localizacao = *req.Localizacao
+	}
+	if req.MostrarLocalizacao != nil {
+		perfil.MostrarLocalizacao = *req.MostrarLocalizacao
+	}
+	if req.PermitirOfertas != nil {
+		perfil.PermitirOfertas = *req.PermitirOfertas
+	}
+	if req.PermitirContato != nil {
+		perfil.PermitirContato = *req.PermitirContato
+	}
+	if req.PermitirSeguir != nil {
+		perfil.PermitirSeguir = *req.PermitirSeguir
+	}
+
+	if err := s.repo.UpdatePerfil(ctx, perfil); err != nil {
+		s.logger.LogError(err, "SocialService.UpdatePerfil", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+	return s.findPerfil(ctx, perfil.Equinoid)
+}
+
+// RestringirPerfisPublicos torna privados os perfis sociais criados pelo usuário ou dos seus equinos;
+// executado quando o consentimento de perfil público é revogado ou expira
+func (s *service) RestringirPerfisPublicos(ctx context.Context, userID uint) error {
+	restringidos, err := s.repo.RestringirPerfis(ctx, userID)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.RestringirPerfisPublicos", logging.Fields{"user_id": userID})
+		return err
+	}
+
+	s.logger.WithFields(logging.Fields{"user_id": userID, "perfis": restringidos}).Info("Perfis sociais restringidos após revogação de consentimento")
+	return nil
+}
+
+// Seguir segue o equino; em perfis privados o vínculo fica pendente até o proprietário aprovar. Repetir a chamada
+// devolve o vínculo existente
+func (s *service) Seguir(ctx context.Context, equinoid string, userID uint) (*Seguimento, error) {
+	perfil, err := s.findPerfil(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if perfil.Equino != nil && perfil.Equino.ProprietarioID == userID {
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "o proprietário não pode seguir o próprio equino", Value: perfil.Equinoid}
+	}
+
+	if existente, err := s.repo.FindSeguir(ctx, userID, perfil.Equinoid); err == nil {
+		return s.seguimento(ctx, perfil.Equinoid, existente)
+	} else if !apperrors.IsNotFound(err) {
+		s.logger.LogError(err, "SocialService.Seguir", logging.Fields{"equinoid": equinoid, "user_id": userID})
+		return nil, err
+	}
+
+	if !perfil.PermitirSeguir {
+		return nil, &apperrors.ValidationError{Field: "permitir_seguir", Message: "este perfil não aceita novos seguidores", Value: false}
+	}
+
+	seguir := &models.SeguirEquino{UserID: userID, Equinoid: perfil.Equinoid, Status: models.StatusSeguirAtivo}
+	if perfil.TipoPerfil == models.TipoPerfilPrivado {
+		seguir.Status = models.StatusSeguirPendente
+	} else {
+		now := time.Now()
+		seguir.AprovadoEm = &now
+	}
+
+	criado, err := s.repo.Seguir(ctx, seguir)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.Seguir", logging.Fields{"equinoid": equinoid, "user_id": userID})
+		return nil, err
+	}
+	if criado {
+		s.logger.LogBusinessEvent("equino_seguido", "Usuário passou a seguir o equino", userID, perfil.Equinoid, logging.Fields{"status": seguir.Status})
+	}
+
+	// Em chamadas concorrentes a linha pode ter sido criada pela outra requisição
+	atual, err := s.repo.FindSeguir(ctx, userID, perfil.Equinoid)
+	if err != nil {
+		return nil, err
+	}
+	return s.seguimento(ctx, perfil.Equinoid, atual)
+}
+
+func (s *service) DeixarDeSeguir(ctx context.Context, equinoid string, userID uint) (*Seguimento, error) {
+	perfil, err := s.findPerfil(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+
+	removido, err := s.repo.DeixarDeSeguir(ctx, userID, perfil.Equinoid)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.DeixarDeSeguir", logging.Fields{"equinoid": equinoid, "user_id": userID})
+		return nil, err
+	}
+	if removido {
+		s.logger.LogBusinessEvent("equino_deixou_de_ser_seguido", "Usuário deixou de seguir o equino", userID, perfil.Equinoid, nil)
+	}
+	return s.seguimento(ctx, perfil.Equinoid, nil)
+}
+
+// ListSeguidores seguidores ativos seguem a visibilidade do perfil; solicitações pendentes só o proprietário vê
+func (s *service) ListSeguidores(ctx context.Context, equinoid string, userID uint, userType string, status models.StatusSeguir, page, limit int) ([]*models.SeguirEquino, int64, error) {
+	perfil, err := s.findPerfil(ctx, equinoid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch status {
+	case "", models.StatusSeguirAtivo:
+		status = models.StatusSeguirAtivo
+		if err := s.checkVisivel(ctx, perfil, userID, userType); err != nil {
+			return nil, 0, err
+		}
+	case models.StatusSeguirPendente:
+		if !isOwner(perfil.Equino, userID, userType) {
+			return nil, 0, (&apperrors.AuthorizationError{Message: "apenas o proprietário vê as solicitações pendentes"}).WithAction("listar_solicitacoes", "perfil_social")
+		}
+	default:
+		return nil, 0, &apperrors.ValidationError{Field: "status", Message: "status deve ser ativo ou pendente", Value: status}
+	}
+
+	seguidores, total, err := s.repo.ListSeguidores(ctx, perfil.Equinoid, status, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.ListSeguidores", logging.Fields{"equinoid": equinoid})
+		return nil, 0, err
+	}
+	return seguidores, total, nil
+}
+
+func (s *service) AprovarSeguidor(ctx context.Context, equinoid string, seguidorID uint, userID uint, userType string) error {
+	perfil, err := s.findPerfilAsOwner(ctx, equinoid, userID, userType, "aprovar_seguidor")
+	if err != nil {
+		return err
+	}
+
+	aprovado, err := s.repo.AprovarSeguidor(ctx, seguidorID, perfil.Equinoid)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.AprovarSeguidor", logging.Fields{"equinoid": equinoid, "seguidor_id": seguidorID})
+		return err
+	}
+	if !aprovado {
+		return &apperrors.NotFoundError{Resource: "seguidor", Message: "solicitação pendente não encontrada", ID: seguidorID}
+	}
+
+	s.logger.LogBusinessEvent("seguidor_aprovado", "Solicitação de seguidor aprovada", userID, perfil.Equinoid, logging.Fields{"seguidor_id": seguidorID})
+	return nil
+}
+
+// RemoverSeguidor recusa a solicitação pendente ou remove um seguidor ativo
+func (s *service) RemoverSeguidor(ctx context.Context, equinoid string, seguidorID uint, userID uint, userType string) error {
+	perfil, err := s.findPerfilAsOwner(ctx, equinoid, userID, userType, "remover_seguidor")
+	if err != nil {
+		return err
+	}
+
+	removido, err := s.repo.DeixarDeSeguir(ctx, seguidorID, perfil.Equinoid)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.RemoverSeguidor", logging.Fields{"equinoid": equinoid, "seguidor_id": seguidorID})
+		return err
+	}
+	if !removido {
+		return &apperrors.NotFoundError{Resource: "seguidor", Message: "seguidor não encontrado", ID: seguidorID}
+	}
+
+	s.logger.LogBusinessEvent("seguidor_removido", "Seguidor removido pelo proprietário", userID, perfil.Equinoid, logging.Fields{"seguidor_id": seguidorID})
+	return nil
+}
+
+func (s *service) ListSeguindo(ctx context.Context, userID uint, page, limit int) ([]*models.SeguirEquino, int64, error) {
+	seguindo, total, err := s.repo.ListSeguindo(ctx, userID, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.ListSeguindo", logging.Fields{"user_id": userID})
+		return nil, 0, err
+	}
+	return seguindo, total, nil
+}
+
+func (s *service) CreatePost(ctx context.Context, equinoid string, userID uint, userType string, req *models.CreatePostRequest) (*models.PostSocial, error) {
+	perfil, err := s.findPerfilAsOwner(ctx, equinoid, userID, userType, "publicar_post")
+	if err != nil {
+		return nil, err
+	}
+
+	if !isValidTipoConteudo(req.TipoConteudo) {
+		return nil, &apperrors.ValidationError{Field: "tipo_conteudo", Message: "tipo de conteúdo inválido", Value: req.TipoConteudo}
+	}
+	now := time.Now()
+	if req.DataExpiracao != nil && !req.DataExpiracao.After(now) {
+		return nil, &apperrors.ValidationError{Field: "data_expiracao", Message: "data de expiração deve estar no futuro"}
+	}
+	if len(req.ArquivosMidia) == 0 {
+		return nil, &apperrors.ValidationError{Field: "arquivos_midia", Message: "o post exige ao menos um arquivo de mídia"}
+	}
+	avaliacao, err := s.avaliar(ctx, userID, req.Legenda+"\n"+req.LocalizacaoPost)
+	if err != nil {
+		return nil, err
+	}
+
+	post := &models.PostSocial{
+		Equinoid:                 perfil.Equinoid,
+		PerfilSocialID:           perfil.ID,
+		TipoConteudo:             req.TipoConteudo,
+		Legenda:                  req.Legenda,
+		LocalizacaoPost:          req.LocalizacaoPost,
+		ThumbnailURL:             req.ThumbnailURL,
+		DuracaoVideo:             req.DuracaoVideo,
+		DataPostagem:             now,
+		DataExpiracao:            req.DataExpiracao,
+		StatusPost:               avaliacao.Status,
+		PermitirComentarios:      valorOuPadrao(req.PermitirComentarios),
+		PermitirCompartilhamento: valorOuPadrao(req.PermitirCompartilhamento),
+		CriadoPor:                userID,
+	}
+	post.ArquivosMidia = models.JSONB{"arquivos": req.ArquivosMidia}
+
+	if err := s.repo.CreatePost(ctx, post); err != nil {
+		s.logger.LogError(err, "SocialService.CreatePost", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+
+	s.encaminhar(ctx, avaliacao, models.ConteudoModeradoPost, post.ID, userID)
+	s.logger.LogBusinessEvent("post_social_publicado", "Post publicado no perfil social", userID, perfil.Equinoid, logging.Fields{"post_id": post.ID, "status": post.StatusPost})
+	return post, nil
+}
+
+func (s *service) GetPost(ctx context.Context, id uint, userID uint, userType string) (*models.PostSocial, error) {
+	return s.findPostVisivel(ctx, id, userID, userType)
+}
+
+func (s *service) RemoverPost(ctx context.Context, id uint, userID uint, userType string) error {
+	post, err := s.findPost(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !isOwner(post.PerfilSocial.Equino, userID, userType) {
+		return (&apperrors.AuthorizationError{Message: "apenas o proprietário pode remover o post"}).WithAction("remover_post", "post")
+	}
+
+	removido, err := s.repo.AlterarStatusPost(ctx, id, statusVisiveisAutor, models.StatusPostRemovido)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.RemoverPost", logging.Fields{"post_id": id})
+		return err
+	}
+	if !removido {
+		return &apperrors.NotFoundError{Resource: "post", Message: "post não encontrado", ID: id}
+	}
+
+	s.logger.LogBusinessEvent("post_social_removido", "Post removido do perfil social", userID, post.Equinoid, logging.Fields{"post_id": id})
+	return nil
+}
+
+// ListPosts posts do perfil; stories expirados e posts ocultos ou retirados pela moderação continuam visíveis
+// apenas para o proprietário
+func (s *service) ListPosts(ctx context.Context, equinoid string, userID uint, userType string, cursor string, limit int) (*models.FeedSocial, error) {
+	perfil, err := s.findPerfil(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkVisivel(ctx, perfil, userID, userType); err != nil {
+		return nil, err
+	}
+
+	filtro, err := filtroPosts(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	filtro.Equinoid = perfil.Equinoid
+	if isOwner(perfil.Equino, userID, userType) {
+		filtro.IncluirExpirados = true
+		filtro.Status = statusVisiveisAutor
+	}
+	return s.pagina(ctx, filtro)
+}
+
+// GetFeed posts dos equinos seguidos, montado na leitura; só vínculos ativos entram, o que cobre os perfis privados
+func (s *service) GetFeed(ctx context.Context, userID uint, cursor string, limit int) (*models.FeedSocial, error) {
+	filtro, err := filtroPosts(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	filtro.SeguidorID = userID
+	return s.pagina(ctx, filtro)
+}
+
+// Interagir registra curtidas, compartilhamentos e reações de forma idempotente
+func (s *service) Interagir(ctx context.Context, postID uint, tipo models.TipoInteracao, userID uint, userType string) (*models.PostSocial, error) {
+	if !models.IsValidTipoInteracao(tipo) {
+		return nil, &apperrors.ValidationError{Field: "tipo_interacao", Message: "tipo de interação inválido", Value: tipo}
+	}
+	post, err := s.findPostEngajavel(ctx, postID, userID, userType)
+	if err != nil {
+		return nil, err
+	}
+	if tipo == models.TipoInteracaoCompartilhamento && !post.PermitirCompartilhamento {
+		return nil, &apperrors.ValidationError{Field: "tipo_interacao", Message: "este post não permite compartilhamento", Value: tipo}
+	}
+
+	criada, err := s.repo.Interagir(ctx, &models.InteracaoSocial{PostID: post.ID, UserID: userID, TipoInteracao: tipo}, post.PerfilSocialID)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.Interagir", logging.Fields{"post_id": postID, "tipo": tipo})
+		return nil, err
+	}
+	if criada {
+		s.logger.LogBusinessEvent("post_social_interacao", "Interação registrada no post", userID, post.Equinoid, logging.Fields{"post_id": postID, "tipo": tipo})
+	}
+	return s.findPost(ctx, postID)
+}
+
+func (s *service) RemoverInteracao(ctx context.Context, postID uint, tipo models.TipoInteracao, userID uint, userType string) (*models.PostSocial, error) {
+	if !models.IsValidTipoInteracao(tipo) {
+		return nil, &apperrors.ValidationError{Field: "tipo_interacao", Message: "tipo de interação inválido", Value: tipo}
+	}
+	post, err := s.findPostEngajavel(ctx, postID, userID, userType)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.RemoverInteracao(ctx, post.ID, userID, tipo, post.PerfilSocialID); err != nil {
+		s.logger.LogError(err, "SocialService.RemoverInteracao", logging.Fields{"post_id": postID, "tipo": tipo})
+		return nil, err
+	}
+	return s.findPost(ctx, postID)
+}
+
+func (s *service) Comentar(ctx context.Context, postID uint, userID uint, userType string, req *models.CreateComentarioRequest) (*models.ComentarioSocial, error) {
+	conteudo := strings.TrimSpace(req.Conteudo)
+	if conteudo == "" || len([]rune(conteudo)) > maxConteudoComentario {
+		return nil, &apperrors.ValidationError{Field: "conteudo", Message: "comentário deve ter entre 1 e 2000 caracteres"}
+	}
+	post, err := s.findPostEngajavel(ctx, postID, userID, userType)
+	if err != nil {
+		return nil, err
+	}
+	if !post.PermitirComentarios {
+		return nil, &apperrors.ValidationError{Field: "post_id", Message: "este post não permite comentários", Value: postID}
+	}
+
+	if req.ParentID != nil {
+		if err := s.checkResposta(ctx, post.ID, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	avaliacao, err := s.avaliar(ctx, userID, conteudo)
+	if err != nil {
+		return nil, err
+	}
+
+	comentario := &models.ComentarioSocial{
+		PostID:   post.ID,
+		UserID:   userID,
+		ParentID: req.ParentID,
+		Conteudo: conteudo,
+		Status:   avaliacao.Status,
+	}
+	if err := s.repo.CreateComentario(ctx, comentario, post.PerfilSocialID); err != nil {
+		s.logger.LogError(err, "SocialService.Comentar", logging.Fields{"post_id": postID})
+		return nil, err
+	}
+	s.encaminhar(ctx, avaliacao, models.ConteudoModeradoComentario, comentario.ID, userID)
+	return comentario, nil
+}
+
+func (s *service) ListComentarios(ctx context.Context, postID uint, userID uint, userType string, page, limit int) ([]*models.ComentarioSocial, int64, error) {
+	post, err := s.findPostVisivel(ctx, postID, userID, userType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	comentarios, total, err := s.repo.ListComentarios(ctx, post.ID, userID, page, limit)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.ListComentarios", logging.Fields{"post_id": postID})
+		return nil, 0, err
+	}
+	return comentarios, total, nil
+}
+
+// RemoverComentario o autor ou o proprietário do equino excluem o comentário junto com as respostas
+func (s *service) RemoverComentario(ctx context.Context, id uint, userID uint, userType string) error {
+	comentario, err := s.repo.FindComentario(ctx, id)
+	if err != nil {
+		return err
+	}
+	post, err := s.findPost(ctx, comentario.PostID)
+	if err != nil {
+		return err
+	}
+	if comentario.UserID != userID && !isOwner(post.PerfilSocial.Equino, userID, userType) {
+		return (&apperrors.AuthorizationError{Message: "apenas o autor ou o proprietário podem excluir o comentário"}).WithAction("excluir_comentario", "comentario")
+	}
+	if post.StatusPost != models.StatusPostAtivo {
+		return &apperrors.NotFoundError{Resource: "post", Message: "post não encontrado", ID: post.ID}
+	}
+
+	removidos, err := s.repo.RemoverComentario(ctx, comentario, post.PerfilSocialID)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.RemoverComentario", logging.Fields{"comentario_id": id})
+		return err
+	}
+
+	s.logger.LogBusinessEvent("comentario_social_removido", "Comentário removido", userID, post.Equinoid, logging.Fields{"comentario_id": id, "removidos": removidos})
+	return nil
+}
+
+// RecalcularContadores corrige contadores que divergirem das tabelas de origem (falhas parciais, edições manuais)
+func (s *service) RecalcularContadores(ctx context.Context) (int64, error) {
+	corrigidos, err := s.repo.RecalcularContadores(ctx)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.RecalcularContadores", nil)
+		return 0, err
+	}
+	return corrigidos, nil
+}
+
+func (s *service) pagina(ctx context.Context, filtro FiltroPosts) (*models.FeedSocial, error) {
+	posts, err := s.repo.ListPosts(ctx, filtro)
+	if err != nil {
+		s.logger.LogError(err, "SocialService.ListPosts", logging.Fields{"equinoid": filtro.Equinoid, "seguidor_id": filtro.SeguidorID})
+		return nil, err
+	}
+
+	feed := &models.FeedSocial{Itens: posts}
+	if len(posts) > filtro.Limit {
+		feed.Itens = posts[:filtro.Limit]
+		feed.ProximoCursor = encodeCursor(feed.Itens[filtro.Limit-1])
+	}
+	if feed.Itens == nil {
+		feed.Itens = []*models.PostSocial{}
+	}
+	return feed, nil
+}
+
+// checkTipoPerfil perfis públicos e comerciais expõem dados do titular e exigem o consentimento dele
+func (s *service) checkTipoPerfil(ctx context.Context, tipo models.TipoPerfil, titularID uint) error {
+	switch tipo {
+	case models.TipoPerfilPrivado:
+		return nil
+	case models.TipoPerfilPublico, models.TipoPerfilComercial:
+		return s.consent.RequireConsent(ctx, titularID, models.FinalidadePerfilSocialPublico)
+	}
+	return &apperrors.ValidationError{Field: "tipo_perfil", Message: "tipo de perfil deve ser publico, privado ou comercial", Value: tipo}
+}
+
+// checkVisivel perfis privados só são vistos pelo proprietário, administradores e seguidores aprovados
+func (s *service) checkVisivel(ctx context.Context, perfil *models.PerfilSocial, userID uint, userType string) error {
+	if perfil.TipoPerfil != models.TipoPerfilPrivado || isOwner(perfil.Equino, userID, userType) {
+		return nil
+	}
+	seguir, err := s.repo.FindSeguir(ctx, userID, perfil.Equinoid)
+	if err == nil && seguir.Status == models.StatusSeguirAtivo {
+		return nil
+	}
+	if err != nil && !apperrors.IsNotFound(err) {
+		s.logger.LogError(err, "SocialService.checkVisivel", logging.Fields{"equinoid": perfil.Equinoid, "user_id": userID})
+		return err
+	}
+	return (&apperrors.AuthorizationError{Message: "perfil privado: apenas seguidores aprovados têm acesso"}).WithAction("visualizar", "perfil_social")
+}
+
+// checkResposta a resposta precisa ser do mesmo post e respeitar a profundidade máxima da thread
+func (s *service) checkResposta(ctx context.Context, postID uint, parentID uint) error {
+	parent, err := s.repo.FindComentario(ctx, parentID)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return &apperrors.ValidationError{Field: "parent_id", Message: "comentário respondido não encontrado", Value: parentID}
+		}
+		return err
+	}
+	if parent.Status != models.StatusPostAtivo {
+		return &apperrors.ValidationError{Field: "parent_id", Message: "comentário respondido não encontrado", Value: parentID}
+	}
+	if parent.PostID != postID {
+		return &apperrors.ValidationError{Field: "parent_id", Message: "comentário respondido pertence a outro post", Value: parentID}
+	}
+
+	profundidade := 2
+	for atual := parent; atual.ParentID != nil; profundidade++ {
+		if profundidade >= maxProfundidadeComentario {
+			return &apperrors.ValidationError{Field: "parent_id", Message: "a thread de comentários aceita no máximo 3 níveis", Value: parentID}
+		}
+		if atual, err = s.repo.FindComentario(ctx, *atual.ParentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) seguimento(ctx context.Context, equinoid string, seguir *models.SeguirEquino) (*Seguimento, error) {
+	perfil, err := s.findPerfil(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	resultado := &Seguimento{Equinoid: equinoid, TotalSeguidores: perfil.TotalSeguidores}
+	if seguir != nil {
+		resultado.Status = seguir.Status
+		resultado.Seguindo = seguir.Status == models.StatusSeguirAtivo
+	}
+	return resultado, nil
+}
+
+func (s *service) findEquino(ctx context.Context, equinoid string) (*models.Equino, error) {
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoid})
+		}
+		return nil, err
+	}
+	return equino, nil
+}
+
+// findPerfil aceita EquinoId, aliases e identificadores externos, como as demais rotas de equinos
+func (s *service) findPerfil(ctx context.Context, equinoid string) (*models.PerfilSocial, error) {
+	equino, err := s.findEquino(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	perfil, err := s.repo.FindPerfil(ctx, equino.Equinoid)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "SocialService.findPerfil", logging.Fields{"equinoid": equinoid})
+		}
+		return nil, err
+	}
+	if perfil.Equino == nil {
+		perfil.Equino = equino
+	}
+	return perfil, nil
+}
+
+func (s *service) findPerfilAsOwner(ctx context.Context, equinoid string, userID uint, userType string, action string) (*models.PerfilSocial, error) {
+	perfil, err := s.findPerfil(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+	if !isOwner(perfil.Equino, userID, userType) {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o proprietário pode gerenciar o perfil social"}).WithAction(action, "perfil_social")
+	}
+	return perfil, nil
+}
+
+func (s *service) findPost(ctx context.Context, id uint) (*models.PostSocial, error) {
+	post, err := s.repo.FindPost(ctx, id)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "SocialService.findPost", logging.Fields{"post_id": id})
+		}
+		return nil, err
+	}
+	if post.PerfilSocial == nil {
+		return nil, &apperrors.NotFoundError{Resource: "post", Message: "post não encontrado", ID: id}
+	}
+	return post, nil
+}
+
+// findPostVisivel posts removidos ou de perfis privados não seguidos respondem como inexistentes ou negados; posts
+// ocultos ou retirados pela moderação continuam visíveis para o proprietário
+func (s *service) findPostVisivel(ctx context.Context, id uint, userID uint, userType string) (*models.PostSocial, error) {
+	post, err := s.findPost(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	owner := isOwner(post.PerfilSocial.Equino, userID, userType)
+	expirado := post.DataExpiracao != nil && !post.DataExpiracao.After(time.Now())
+	visivel := post.StatusPost == models.StatusPostAtivo ||
+		(owner && (post.StatusPost == models.StatusPostOculto || post.StatusPost == models.StatusPostRetirado))
+	if !visivel || (expirado && !owner) {
+		return nil, &apperrors.NotFoundError{Resource: "post", Message: "post não encontrado", ID: id}
+	}
+	if err := s.checkVisivel(ctx, post.PerfilSocial, userID, userType); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// findPostEngajavel curtidas, compartilhamentos e comentários só em posts no ar; os contadores do perfil somam
+// apenas o engajamento de posts ativos
+func (s *service) findPostEngajavel(ctx context.Context, id uint, userID uint, userType string) (*models.PostSocial, error) {
+	post, err := s.findPostVisivel(ctx, id, userID, userType)
+	if err != nil {
+		return nil, err
+	}
+	if post.StatusPost != models.StatusPostAtivo {
+		return nil, &apperrors.ValidationError{Field: "post_id", Message: "post em moderação não aceita interações", Value: id}
+	}
+	return post, nil
+}
+
+// avaliar aplica os filtros de moderação ao texto; sem moderador configurado o conteúdo é publicado direto
+func (s *service) avaliar(ctx context.Context, autorID uint, texto string) (*models.AvaliacaoModeracao, error) {
+	if s.moderador == nil {
+		return &models.AvaliacaoModeracao{Status: models.StatusPostAtivo}, nil
+	}
+	return s.moderador.AvaliarPublicacao(ctx, autorID, texto)
+}
+
+// encaminhar abre o caso na fila para conteúdo oculto ou marcado para revisão; a publicação já foi gravada e não
+// é desfeita se o encaminhamento falhar
+func (s *service) encaminhar(ctx context.Context, avaliacao *models.AvaliacaoModeracao, tipo models.TipoConteudoModerado, conteudoID, autorID uint) {
+	if s.moderador == nil || (!avaliacao.Revisar && avaliacao.Status == models.StatusPostAtivo) {
+		return
+	}
+	if err := s.moderador.EncaminharRevisao(ctx, avaliacao, tipo, conteudoID, autorID); err != nil {
+		s.logger.LogError(err, "SocialService.encaminhar", logging.Fields{"tipo": tipo, "conteudo_id": conteudoID})
+	}
+}
+
+func filtroPosts(cursor string, limit int) (FiltroPosts, error) {
+	if limit <= 0 || limit > limitePaginaMaximo {
+		limit = 20
+	}
+	filtro := FiltroPosts{Agora: time.Now(), Limit: limit}
+	if cursor != "" {
+		decoded, err := decodeCursor(cursor)
+		if err != nil {
+			return FiltroPosts{}, err
+		}
+		filtro.Cursor = decoded
+	}
+	return filtro, nil
+}
+
+func encodeCursor(ultimo *models.PostSocial) string {
+	data, _ := json.Marshal(cursorFeed{DataPostagem: ultimo.DataPostagem, ID: ultimo.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (*CursorFeed, error) {
+	invalido := &apperrors.ValidationError{Field: "cursor", Message: "cursor inválido"}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalido
+	}
+	var pagina cursorFeed
+	if err := json.Unmarshal(data, &pagina); err != nil || pagina.ID == 0 || pagina.DataPostagem.IsZero() {
+		return nil, invalido
+	}
+	return &CursorFeed{DataPostagem: pagina.DataPostagem, ID: pagina.ID}, nil
+}
+
+// valorOuPadrao permissões omitidas na requisição ficam habilitadas
+func valorOuPadrao(valor *bool) bool {
+	return valor == nil || *valor
+}
+
+func isOwner(equino *models.Equino, userID uint, userType string) bool {
+	return userType == string(models.UserTypeAdmin) || (equino != nil && equino.ProprietarioID == userID)
+}
+
+func isValidTipoConteudo(tipo models.TipoConteudo) bool {
+	switch tipo {
+	case models.TipoConteudoFoto, models.TipoConteudoVideo, models.TipoConteudoCarrossel, models.TipoConteudoStory, models.TipoConteudoReel:
+		return true
+	}
+	return false
+}
+
+func isValidDisponibilidade(status models.StatusDisponibilidade) bool {
+	switch status {
+	case models.StatusDisponivel, models.StatusDispVendido, models.StatusIndisponivel:
+		return true
+	}
+	return false
+}
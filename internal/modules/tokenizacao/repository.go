@@ -3,6 +3,7 @@ package tokenizacao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/equinoid/backend/internal/models"
 	apperrors "github.com/equinoid/backend/pkg/errors"
@@ -26,6 +27,7 @@ type Repository interface {
 	
 	CreateOferta(ctx context.Context, oferta *models.OfertaToken) error
 	FindOfertasAtivasByTokenizacaoID(ctx context.Context, tokenizacaoID uint) ([]*models.OfertaToken, error)
+	CancelarOfertasAtivasByVendedorID(ctx context.Context, vendedorID uint) (int64, error)
 }
 
 type repository struct {
@@ -222,3 +224,16 @@ func (r *repository) FindOfertasAtivasByTokenizacaoID(ctx context.Context, token
 	}
 	return ofertas, nil
 }
+
+func (r *repository) CancelarOfertasAtivasByVendedorID(ctx context.Context, vendedorID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.OfertaToken{}).
+		Where("vendedor_id = ? AND status = ?", vendedorID, "ativa").
+		Updates(map[string]interface{}{
+			"status":     "cancelada",
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, apperrors.NewDatabaseError("cancelar_ofertas", "erro ao cancelar ofertas", result.Error)
+	}
+	return result.RowsAffected, nil
+}
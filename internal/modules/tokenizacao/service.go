@@ -13,6 +13,16 @@ import (
 	"github.com/equinoid/backend/pkg/logging"
 )
 
+// AuditLogger registra alterações de entidades na trilha de auditoria
+type AuditLogger interface {
+	LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error
+}
+
+// ConsentChecker verifica o consentimento do titular antes de tratamentos que dependem dele
+type ConsentChecker interface {
+	RequireConsent(ctx context.Context, userID uint, purpose string) error
+}
+
 type Service interface {
 	ListAll(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*models.TokenizacaoResponse, int64, error)
 	GetByID(ctx context.Context, id uint) (*models.TokenizacaoResponse, error)
@@ -22,6 +32,7 @@ type Service interface {
 	ListTransacoes(ctx context.Context, tokenizacaoID uint) ([]*models.TransacaoTokenResponse, error)
 	ExecutarOrdem(ctx context.Context, userID uint, req *models.OrdemCompraTokenRequest) (*models.TransacaoTokenResponse, error)
 	CriarOferta(ctx context.Context, userID uint, req *models.OfertaTokenRequest) error
+	CancelarOfertasUsuario(ctx context.Context, userID uint) error
 	
 	CalcularRatingRisco(ctx context.Context, equinoID uint) (models.RatingRisco, error)
 }
@@ -29,13 +40,17 @@ type Service interface {
 type service struct {
 	repo        Repository
 	equinoRepo  equinos.Repository
+	audit       AuditLogger
+	consent     ConsentChecker
 	logger      *logging.Logger
 }
 
-func NewService(repo Repository, equinoRepo equinos.Repository, logger *logging.Logger) Service {
+func NewService(repo Repository, equinoRepo equinos.Repository, audit AuditLogger, consent ConsentChecker, logger *logging.Logger) Service {
 	return &service{
 		repo:       repo,
 		equinoRepo: equinoRepo,
+		audit:      audit,
+		consent:    consent,
 		logger:     logger,
 	}
 }
@@ -93,6 +108,10 @@ func (s *service) GetByEquinoid(ctx context.Context, equinoid string) (*models.T
 }
 
 func (s *service) Create(ctx context.Context, userID uint, req *models.CreateTokenizacaoRequest) (*models.TokenizacaoResponse, error) {
+	if err := s.consent.RequireConsent(ctx, userID, models.FinalidadeKYCTokenizacao); err != nil {
+		return nil, err
+	}
+
 	_, err := s.equinoRepo.FindByID(ctx, req.EquinoID)
 	if err != nil {
 		return nil, &apperrors.NotFoundError{Resource: "equino", Message: "equino não encontrado"}
@@ -203,6 +222,8 @@ func (s *service) Create(ctx context.Context, userID uint, req *models.CreateTok
 		})
 	}
 
+	s.recordChange(ctx, "tokenizacao", tokenizacao.ID, "create", nil, tokenizacao)
+
 	s.logger.WithFields(logging.Fields{
 		"tokenizacao_id": tokenizacao.ID,
 		"equino_id":      tokenizacao.EquinoID,
@@ -229,6 +250,10 @@ func (s *service) ListTransacoes(ctx context.Context, tokenizacaoID uint) ([]*mo
 }
 
 func (s *service) ExecutarOrdem(ctx context.Context, userID uint, req *models.OrdemCompraTokenRequest) (*models.TransacaoTokenResponse, error) {
+	if err := s.consent.RequireConsent(ctx, userID, models.FinalidadeKYCTokenizacao); err != nil {
+		return nil, err
+	}
+
 	tokenizacao, err := s.repo.FindByID(ctx, req.TokenizacaoID)
 	if err != nil {
 		return nil, err
@@ -291,6 +316,8 @@ func (s *service) ExecutarOrdem(ctx context.Context, userID uint, req *models.Or
 		})
 	}
 
+	s.recordChange(ctx, "transacao_token", transacao.ID, "create", nil, transacao)
+
 	s.logger.WithFields(logging.Fields{
 		"transacao_id":   transacao.ID,
 		"tokenizacao_id": req.TokenizacaoID,
@@ -303,6 +330,10 @@ func (s *service) ExecutarOrdem(ctx context.Context, userID uint, req *models.Or
 }
 
 func (s *service) CriarOferta(ctx context.Context, userID uint, req *models.OfertaTokenRequest) error {
+	if err := s.consent.RequireConsent(ctx, userID, models.FinalidadeKYCTokenizacao); err != nil {
+		return err
+	}
+
 	tokenizacao, err := s.repo.FindByID(ctx, req.TokenizacaoID)
 	if err != nil {
 		return err
@@ -366,6 +397,8 @@ func (s *service) CriarOferta(ctx context.Context, userID uint, req *models.Ofer
 		return err
 	}
 
+	s.recordChange(ctx, "oferta_token", oferta.ID, "create", nil, oferta)
+
 	s.logger.WithFields(logging.Fields{
 		"oferta_id":      oferta.ID,
 		"tokenizacao_id": req.TokenizacaoID,
@@ -377,6 +410,25 @@ func (s *service) CriarOferta(ctx context.Context, userID uint, req *models.Ofer
 	return nil
 }
 
+// CancelarOfertasUsuario retira do mercado as ofertas ativas do usuário; executado quando o consentimento
+// de KYC para tokenização é revogado ou expira
+func (s *service) CancelarOfertasUsuario(ctx context.Context, userID uint) error {
+	canceladas, err := s.repo.CancelarOfertasAtivasByVendedorID(ctx, userID)
+	if err != nil {
+		s.logger.LogError(err, "TokenizacaoService.CancelarOfertasUsuario", logging.Fields{"user_id": userID})
+		return err
+	}
+
+	if canceladas > 0 {
+		s.recordChange(ctx, "oferta_token", userID, "cancel", nil, map[string]interface{}{
+			"vendedor_id": userID,
+			"canceladas":  canceladas,
+			"motivo":      "consentimento_revogado",
+		})
+	}
+	return nil
+}
+
 func (s *service) CalcularRatingRisco(ctx context.Context, equinoID uint) (models.RatingRisco, error) {
 	equino, err := s.equinoRepo.FindByID(ctx, equinoID)
 	if err != nil {
@@ -426,6 +478,15 @@ func (s *service) CalcularRatingRisco(ctx context.Context, equinoID uint) (model
 	return models.RatingC, nil
 }
 
+func (s *service) recordChange(ctx context.Context, resource string, id uint, operation string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogChange(ctx, resource, fmt.Sprintf("%d", id), operation, before, after); err != nil {
+		s.logger.LogError(err, "TokenizacaoService.recordChange", logging.Fields{"resource": resource, "id": id})
+	}
+}
+
 func (s *service) gerarHashBlockchain(tokenizacaoID uint, userID uint, quantidade int) string {
 	data := fmt.Sprintf("%d-%d-%d-%d", tokenizacaoID, userID, quantidade, time.Now().Unix())
 	hash := sha256.Sum256([]byte(data))
@@ -0,0 +1,314 @@
+package valorizacao
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateRegistro godoc
+// @Summary Criar registro de valorização
+// @Description Registra uma conquista do equino (competição, reprodução, saúde, mídia etc.); os pontos são calculados pela categoria e nível de importância e só contam após a validação
+// @Tags Valorização
+// @Accept json
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param registro body models.CreateValorizacaoRequest true "Dados do registro"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/valorizacoes [post]
+// @Security BearerAuth
+func (h *Handler) CreateRegistro(c *gin.Context) {
+	var req models.CreateValorizacaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	registro, err := h.service.CreateRegistro(c.Request.Context(), c.Param("equinoid"), &req, userID)
+	if err != nil {
+		h.respondError(c, err, "Erro ao criar registro de valorização")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success:   true,
+		Message:   "Registro de valorização criado com sucesso",
+		Timestamp: time.Now(),
+		Data:      registro,
+	})
+}
+
+// ListRegistros godoc
+// @Summary Listar registros de valorização
+// @Description Registros de valorização do equino com filtros e paginação
+// @Tags Valorização
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Param categoria query string false "Filtrar por categoria"
+// @Param status_validacao query string false "Filtrar por status de validação (pendente, aprovado, rejeitado)"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/valorizacoes [get]
+// @Security BearerAuth
+func (h *Handler) ListRegistros(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 100
+	}
+
+	filters := make(map[string]interface{})
+	if categoria := c.Query("categoria"); categoria != "" {
+		filters["categoria"] = categoria
+	}
+	if status := c.Query("status_validacao"); status != "" {
+		filters["status_validacao"] = status
+	}
+
+	registros, total, err := h.service.List(c.Request.Context(), c.Param("equinoid"), page, limit, filters)
+	if err != nil {
+		h.respondError(c, err, "Erro ao listar registros de valorização")
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Registros de valorização (total: %d)", total),
+		Timestamp: time.Now(),
+		Data: models.PaginatedResponse{
+			Data: registros,
+			Pagination: &models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+				Pages: totalPages,
+			},
+		},
+	})
+}
+
+// GetPontuacao godoc
+// @Summary Pontuação de valorização
+// @Description Soma dos pontos dos registros de valorização aprovados do equino
+// @Tags Valorização
+// @Produce json
+// @Param equinoid path string true "Equinoid do equino"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /equinos/{equinoid}/valorizacoes/pontos [get]
+// @Security BearerAuth
+func (h *Handler) GetPontuacao(c *gin.Context) {
+	pontuacao, err := h.service.GetPontuacao(c.Request.Context(), c.Param("equinoid"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao calcular pontuação")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Pontuação de valorização",
+		Timestamp: time.Now(),
+		Data:      pontuacao,
+	})
+}
+
+// GetRegistro godoc
+// @Summary Buscar registro de valorização
+// @Description Registro de valorização com o equino, o criador e o validador
+// @Tags Valorização
+// @Produce json
+// @Param id path int true "ID do registro"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /valorizacoes/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetRegistro(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	registro, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Erro ao buscar registro de valorização")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Registro de valorização encontrado",
+		Timestamp: time.Now(),
+		Data:      registro,
+	})
+}
+
+// ValidarRegistro godoc
+// @Summary Validar registro de valorização
+// @Description Aprova ou rejeita um registro pendente (somente administradores)
+// @Tags Valorização
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do registro"
+// @Param validacao body models.ValidarValorizacaoRequest true "Decisão da validação"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /valorizacoes/{id}/validar [post]
+// @Security BearerAuth
+func (h *Handler) ValidarRegistro(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ValidarValorizacaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Dados inválidos: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	registro, err := h.service.Validar(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Erro ao validar registro de valorização")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Registro de valorização validado",
+		Timestamp: time.Now(),
+		Data:      registro,
+	})
+}
+
+// GetRanking godoc
+// @Summary Ranking de valorização
+// @Description Equinos ordenados pela soma dos pontos aprovados, geral ou por categoria
+// @Tags Valorização
+// @Produce json
+// @Param categoria query string false "Categoria de valorização"
+// @Param limit query int false "Quantidade de posições (máx. 100)" default(20)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /valorizacoes/ranking [get]
+// @Security BearerAuth
+func (h *Handler) GetRanking(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	ranking, err := h.service.GetRanking(c.Request.Context(), models.CategoriaValorizacao(c.Query("categoria")), limit)
+	if err != nil {
+		h.respondError(c, err, "Erro ao obter ranking de valorização")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   "Ranking de valorização",
+		Timestamp: time.Now(),
+		Data:      ranking,
+	})
+}
+
+func (h *Handler) requireUser(c *gin.Context) (uint, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success:   false,
+			Error:     "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *Handler) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "ID inválido",
+			Timestamp: time.Now(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	case apperrors.IsConflict(err):
+		status = http.StatusConflict
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
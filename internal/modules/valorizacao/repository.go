@@ -0,0 +1,109 @@
+package valorizacao
+
+import (
+	"context"
+	"errors"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(ctx context.Context, registro *models.RegistroValorizacao) error
+	FindByID(ctx context.Context, id uint) (*models.RegistroValorizacao, error)
+	Update(ctx context.Context, registro *models.RegistroValorizacao) error
+	List(ctx context.Context, equinoid string, page, limit int, filters map[string]interface{}) ([]*models.RegistroValorizacao, int64, error)
+	SumPontosAprovados(ctx context.Context, equinoid string) (int, error)
+	Ranking(ctx context.Context, categoria models.CategoriaValorizacao, limit int) ([]*models.RankingItem, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, registro *models.RegistroValorizacao) error {
+	if err := r.db.WithContext(ctx).Create(registro).Error; err != nil {
+		return apperrors.NewDatabaseError("create_registro", "erro ao criar registro de valorização", err)
+	}
+	return nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id uint) (*models.RegistroValorizacao, error) {
+	var registro models.RegistroValorizacao
+	if err := r.db.WithContext(ctx).Preload("Equino").Preload("Criador").Preload("Validador").Where("id = ?", id).First(&registro).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "registro_valorizacao", Message: "registro de valorização não encontrado", ID: id}
+		}
+		return nil, apperrors.NewDatabaseError("find_by_id", "erro ao buscar registro de valorização", err)
+	}
+	return &registro, nil
+}
+
+func (r *repository) Update(ctx context.Context, registro *models.RegistroValorizacao) error {
+	if err := r.db.WithContext(ctx).Omit("Equino", "Criador", "Validador").Save(registro).Error; err != nil {
+		return apperrors.NewDatabaseError("update_registro", "erro ao atualizar registro de valorização", err)
+	}
+	return nil
+}
+
+func (r *repository) List(ctx context.Context, equinoid string, page, limit int, filters map[string]interface{}) ([]*models.RegistroValorizacao, int64, error) {
+	var registros []*models.RegistroValorizacao
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.RegistroValorizacao{}).Where("equinoid = ?", equinoid)
+	if categoria, ok := filters["categoria"].(string); ok {
+		query = query.Where("categoria = ?", categoria)
+	}
+	if status, ok := filters["status_validacao"].(string); ok {
+		query = query.Where("status_validacao = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_registros", "erro ao contar registros de valorização", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Offset(offset).Limit(limit).Order("data_registro DESC").Find(&registros).Error; err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_registros", "erro ao listar registros de valorização", err)
+	}
+
+	return registros, total, nil
+}
+
+func (r *repository) SumPontosAprovados(ctx context.Context, equinoid string) (int, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&models.RegistroValorizacao{}).
+		Where("equinoid = ? AND status_validacao = ?", equinoid, models.StatusAprovado).
+		Select("COALESCE(SUM(pontos_valorizacao), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, apperrors.NewDatabaseError("sum_pontos", "erro ao somar pontos de valorização", err)
+	}
+	return int(total), nil
+}
+
+// Ranking equinos pela soma dos pontos aprovados, opcionalmente restrita a uma categoria
+func (r *repository) Ranking(ctx context.Context, categoria models.CategoriaValorizacao, limit int) ([]*models.RankingItem, error) {
+	query := r.db.WithContext(ctx).Table("equinos e").
+		Select("e.equinoid, e.nome, COALESCE(SUM(rv.pontos_valorizacao), 0) AS total_pontos, COUNT(rv.id) AS total_registros").
+		Joins("JOIN registro_valorizacaos rv ON rv.equinoid = e.equinoid AND rv.status_validacao = ? AND rv.deleted_at IS NULL", models.StatusAprovado).
+		Where("e.deleted_at IS NULL")
+	if categoria != "" {
+		query = query.Where("rv.categoria = ?", categoria)
+	}
+
+	var ranking []*models.RankingItem
+	err := query.Group("e.equinoid, e.nome").
+		Order("total_pontos DESC, total_registros DESC").
+		Limit(limit).
+		Scan(&ranking).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("ranking_valorizacao", "erro ao calcular ranking de valorização", err)
+	}
+	return ranking, nil
+}
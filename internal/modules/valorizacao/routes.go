@@ -0,0 +1,24 @@
+package valorizacao
+
+import (
+	"github.com/equinoid/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+	equinos := rg.Group("/equinos")
+	equinos.Use(authMiddleware)
+	{
+		equinos.GET("/:equinoid/valorizacoes", handler.ListRegistros)
+		equinos.POST("/:equinoid/valorizacoes", handler.CreateRegistro)
+		equinos.GET("/:equinoid/valorizacoes/pontos", handler.GetPontuacao)
+	}
+
+	valorizacoes := rg.Group("/valorizacoes")
+	valorizacoes.Use(authMiddleware)
+	{
+		valorizacoes.GET("/ranking", handler.GetRanking)
+		valorizacoes.GET("/:id", handler.GetRegistro)
+		valorizacoes.POST("/:id/validar", middleware.RequireAdminMiddleware(), handler.ValidarRegistro)
+	}
+}
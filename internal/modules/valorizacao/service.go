@@ -0,0 +1,208 @@
+package valorizacao
+
+import (
+	"context"
+	"time"
+
+	"github.com/equinoid/backend/internal/constants"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	"github.com/equinoid/backend/pkg/cache"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+// limiteRankingMaximo quantidade máxima de posições devolvidas no ranking de valorização
+const limiteRankingMaximo = 100
+
+// Pontuacao pontos de valorização aprovados de um equino
+type Pontuacao struct {
+	Equinoid    string `json:"equinoid"`
+	TotalPontos int    `json:"total_pontos"`
+}
+
+type Service interface {
+	CreateRegistro(ctx context.Context, equinoid string, req *models.CreateValorizacaoRequest, userID uint) (*models.RegistroValorizacao, error)
+	GetByID(ctx context.Context, id uint) (*models.RegistroValorizacao, error)
+	List(ctx context.Context, equinoid string, page, limit int, filters map[string]interface{}) ([]*models.RegistroValorizacao, int64, error)
+	Validar(ctx context.Context, id uint, validadorID uint, req *models.ValidarValorizacaoRequest) (*models.RegistroValorizacao, error)
+	GetPontuacao(ctx context.Context, equinoid string) (*Pontuacao, error)
+	GetRanking(ctx context.Context, categoria models.CategoriaValorizacao, limit int) ([]*models.RankingItem, error)
+}
+
+type service struct {
+	repo       Repository
+	equinoRepo equinos.Repository
+	cache      cache.CacheInterface
+	logger     *logging.Logger
+}
+
+func NewService(repo Repository, equinoRepo equinos.Repository, cache cache.CacheInterface, logger *logging.Logger) Service {
+	return &service{
+		repo:       repo,
+		equinoRepo: equinoRepo,
+		cache:      cache,
+		logger:     logger,
+	}
+}
+
+func (s *service) CreateRegistro(ctx context.Context, equinoid string, req *models.CreateValorizacaoRequest, userID uint) (*models.RegistroValorizacao, error) {
+	equino, err := s.findEquino(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+
+	pontos, err := calcularPontos(req.Categoria, req.NivelImportancia)
+	if err != nil {
+		return nil, err
+	}
+	if req.TipoRegistro == "" || req.Titulo == "" {
+		return nil, &apperrors.ValidationError{Field: "titulo", Message: "tipo de registro e título são obrigatórios"}
+	}
+	if req.DataRegistro.IsZero() {
+		return nil, &apperrors.ValidationError{Field: "data_registro", Message: "data do registro é obrigatória"}
+	}
+
+	registro := &models.RegistroValorizacao{
+		Equinoid:                 equino.Equinoid,
+		Categoria:                req.Categoria,
+		TipoRegistro:             req.TipoRegistro,
+		Titulo:                   req.Titulo,
+		Descricao:                req.Descricao,
+		DataRegistro:             req.DataRegistro,
+		DataValidade:             req.DataValidade,
+		LocalEvento:              req.LocalEvento,
+		Pais:                     req.Pais,
+		Estado:                   req.Estado,
+		Cidade:                   req.Cidade,
+		Organizacao:              req.Organizacao,
+		InstituicaoCertificadora: req.InstituicaoCertificadora,
+		NumeroCertificado:        req.NumeroCertificado,
+		ValorMonetario:           req.ValorMonetario,
+		PontosValorizacao:        pontos,
+		NivelImportancia:         req.NivelImportancia,
+		StatusValidacao:          models.StatusPendente,
+		CriadoPor:                userID,
+	}
+
+	if err := s.repo.Create(ctx, registro); err != nil {
+		s.logger.LogError(err, "ValorizacaoService.CreateRegistro", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+
+	s.logger.LogBusinessEvent("valorizacao_registrada", "Registro de valorização criado", userID, equino.Equinoid, logging.Fields{"registro_id": registro.ID})
+	return registro, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id uint) (*models.RegistroValorizacao, error) {
+	registro, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "ValorizacaoService.GetByID", logging.Fields{"id": id})
+		}
+		return nil, err
+	}
+	return registro, nil
+}
+
+func (s *service) List(ctx context.Context, equinoid string, page, limit int, filters map[string]interface{}) ([]*models.RegistroValorizacao, int64, error) {
+	equino, err := s.findEquino(ctx, equinoid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	registros, total, err := s.repo.List(ctx, equino.Equinoid, page, limit, filters)
+	if err != nil {
+		s.logger.LogError(err, "ValorizacaoService.List", logging.Fields{"equinoid": equinoid, "filters": filters})
+		return nil, 0, err
+	}
+	return registros, total, nil
+}
+
+// Validar aprova ou rejeita um registro pendente; só registros aprovados contam pontos
+func (s *service) Validar(ctx context.Context, id uint, validadorID uint, req *models.ValidarValorizacaoRequest) (*models.RegistroValorizacao, error) {
+	registro, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if registro.StatusValidacao != models.StatusPendente {
+		return nil, &apperrors.ConflictError{Resource: "registro_valorizacao", Message: "registro de valorização já foi validado", Value: registro.StatusValidacao}
+	}
+
+	now := time.Now()
+	registro.StatusValidacao = models.StatusRejeitado
+	if *req.Aprovado {
+		registro.StatusValidacao = models.StatusAprovado
+	}
+	registro.ValidadoPor = &validadorID
+	registro.DataValidacao = &now
+	registro.ObservacoesValidacao = req.Observacoes
+
+	if err := s.repo.Update(ctx, registro); err != nil {
+		s.logger.LogError(err, "ValorizacaoService.Validar", logging.Fields{"id": id})
+		return nil, err
+	}
+
+	s.logger.LogBusinessEvent("valorizacao_validada", "Registro de valorização validado", validadorID, registro.Equinoid, logging.Fields{
+		"registro_id": registro.ID,
+		"status":      registro.StatusValidacao,
+	})
+	return registro, nil
+}
+
+func (s *service) GetPontuacao(ctx context.Context, equinoid string) (*Pontuacao, error) {
+	equino, err := s.findEquino(ctx, equinoid)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.repo.SumPontosAprovados(ctx, equino.Equinoid)
+	if err != nil {
+		s.logger.LogError(err, "ValorizacaoService.GetPontuacao", logging.Fields{"equinoid": equinoid})
+		return nil, err
+	}
+	return &Pontuacao{Equinoid: equino.Equinoid, TotalPontos: total}, nil
+}
+
+func (s *service) GetRanking(ctx context.Context, categoria models.CategoriaValorizacao, limit int) ([]*models.RankingItem, error) {
+	if categoria != "" {
+		if _, ok := constants.PontosBasePorCategoria[string(categoria)]; !ok {
+			return nil, &apperrors.ValidationError{Field: "categoria", Message: "categoria de valorização inválida", Value: categoria}
+		}
+	}
+	if limit <= 0 || limit > limiteRankingMaximo {
+		limit = limiteRankingMaximo
+	}
+
+	ranking, err := s.repo.Ranking(ctx, categoria, limit)
+	if err != nil {
+		s.logger.LogError(err, "ValorizacaoService.GetRanking", logging.Fields{"categoria": categoria})
+		return nil, err
+	}
+	return ranking, nil
+}
+
+func (s *service) findEquino(ctx context.Context, equinoid string) (*models.Equino, error) {
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			s.logger.LogError(err, "ValorizacaoService", logging.Fields{"equinoid": equinoid})
+		}
+		return nil, err
+	}
+	return equino, nil
+}
+
+// calcularPontos pontos base da categoria multiplicados pelo nível de importância
+func calcularPontos(categoria models.CategoriaValorizacao, nivel models.NivelImportancia) (int, error) {
+	base, ok := constants.PontosBasePorCategoria[string(categoria)]
+	if !ok {
+		return 0, &apperrors.ValidationError{Field: "categoria", Message: "categoria de valorização inválida", Value: categoria}
+	}
+	multiplicador, ok := constants.MultiplicadoresPorNivel[string(nivel)]
+	if !ok {
+		return 0, &apperrors.ValidationError{Field: "nivel_importancia", Message: "nível de importância inválido", Value: nivel}
+	}
+	return int(float64(base) * multiplicador), nil
+}
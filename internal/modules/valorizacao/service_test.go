@@ -0,0 +1,212 @@
+package valorizacao
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/modules/equinos"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	criadorID   uint = 1
+	validadorID uint = 2
+)
+
+func setupValorizacaoService(t *testing.T) (Service, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Skip("sqlite driver unavailable for tests")
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Equino{}, &models.EquinoIdentificador{}, &models.RegistroValorizacao{}))
+
+	for id, nome := range map[uint]string{criadorID: "Criador", validadorID: "Validador"} {
+		user := &models.User{
+			ID:          id,
+			SupabaseID:  fmt.Sprintf("supabase-%d", id),
+			KeycloakSub: fmt.Sprintf("keycloak-%d", id),
+			Email:       fmt.Sprintf("usuario%d@equinoid.test", id),
+			Name:        nome,
+			UserType:    models.UserTypeCriador,
+			CPFCNPJ:     fmt.Sprintf("0000000000%d", id),
+		}
+		require.NoError(t, db.Omit(clause.Associations).Create(user).Error)
+	}
+
+	dataNascimento := time.Now().AddDate(-5, 0, 0)
+	equino := &models.Equino{
+		Equinoid:       "BRA-2020-00000001",
+		MicrochipID:    "CHIP_VALORIZACAO",
+		Nome:           "Campeão",
+		Sexo:           models.SexoMacho,
+		Raca:           "Mangalarga",
+		Pelagem:        "Alazão",
+		PaisOrigem:     "BRA",
+		DataNascimento: &dataNascimento,
+		Status:         models.StatusAtivo,
+		ProprietarioID: criadorID,
+	}
+	require.NoError(t, db.Omit(clause.Associations).Create(equino).Error)
+
+	return NewService(NewRepository(db), equinos.NewRepository(db), nil, logging.NewLogger("error")), db
+}
+
+func novoRegistro(categoria models.CategoriaValorizacao, nivel models.NivelImportancia) *models.CreateValorizacaoRequest {
+	return &models.CreateValorizacaoRequest{
+		Categoria:        categoria,
+		TipoRegistro:     "campeonato",
+		Titulo:           "Campeonato Nacional",
+		DataRegistro:     time.Now(),
+		NivelImportancia: nivel,
+	}
+}
+
+func TestValorizacaoService_CreateRegistro(t *testing.T) {
+	service, _ := setupValorizacaoService(t)
+
+	t.Run("Criar registro com sucesso", func(t *testing.T) {
+		registro, err := service.CreateRegistro(context.Background(), "BRA-2020-00000001", novoRegistro(models.CategoriaCompeticao, models.NivelAlto), criadorID)
+
+		require.NoError(t, err)
+		assert.NotZero(t, registro.ID)
+		assert.Equal(t, "BRA-2020-00000001", registro.Equinoid)
+		assert.Equal(t, models.StatusPendente, registro.StatusValidacao)
+		assert.Equal(t, 200, registro.PontosValorizacao)
+		assert.Equal(t, criadorID, registro.CriadoPor)
+	})
+
+	t.Run("Equino não encontrado", func(t *testing.T) {
+		_, err := service.CreateRegistro(context.Background(), "BRA-9999-99999999", novoRegistro(models.CategoriaCompeticao, models.NivelAlto), criadorID)
+
+		assert.True(t, apperrors.IsNotFound(err))
+	})
+
+	t.Run("Categoria inválida", func(t *testing.T) {
+		_, err := service.CreateRegistro(context.Background(), "BRA-2020-00000001", novoRegistro("astrologia", models.NivelAlto), criadorID)
+
+		assert.True(t, apperrors.IsValidation(err))
+	})
+}
+
+func TestCalcularPontos(t *testing.T) {
+	casos := []struct {
+		nome      string
+		categoria models.CategoriaValorizacao
+		nivel     models.NivelImportancia
+		esperado  int
+	}{
+		{"Competição nível médio", models.CategoriaCompeticao, models.NivelMedio, 100},
+		{"Competição nível alto", models.CategoriaCompeticao, models.NivelAlto, 200},
+		{"Competição nível excepcional", models.CategoriaCompeticao, models.NivelExcepcional, 500},
+		{"Reprodução nível alto", models.CategoriaReproducao, models.NivelAlto, 160},
+		{"Saúde nível baixo", models.CategoriaSaude, models.NivelBaixo, 25},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			pontos, err := calcularPontos(caso.categoria, caso.nivel)
+
+			require.NoError(t, err)
+			assert.Equal(t, caso.esperado, pontos)
+		})
+	}
+
+	_, err := calcularPontos(models.CategoriaCompeticao, "lendario")
+	assert.True(t, apperrors.IsValidation(err))
+}
+
+func TestValorizacaoService_GetPontuacao(t *testing.T) {
+	service, db := setupValorizacaoService(t)
+
+	registros := []struct {
+		pontos int
+		status models.StatusValidacao
+	}{
+		{100, models.StatusAprovado},
+		{200, models.StatusAprovado},
+		{50, models.StatusPendente},
+		{80, models.StatusRejeitado},
+	}
+	for _, r := range registros {
+		require.NoError(t, db.Omit(clause.Associations).Create(&models.RegistroValorizacao{
+			Equinoid:          "BRA-2020-00000001",
+			Categoria:         models.CategoriaCompeticao,
+			TipoRegistro:      "campeonato",
+			Titulo:            "Prova",
+			DataRegistro:      time.Now(),
+			PontosValorizacao: r.pontos,
+			NivelImportancia:  models.NivelMedio,
+			StatusValidacao:   r.status,
+			CriadoPor:         criadorID,
+		}).Error)
+	}
+
+	pontuacao, err := service.GetPontuacao(context.Background(), "BRA-2020-00000001")
+
+	require.NoError(t, err)
+	assert.Equal(t, "BRA-2020-00000001", pontuacao.Equinoid)
+	assert.Equal(t, 300, pontuacao.TotalPontos)
+}
+
+func TestValorizacaoService_Validar(t *testing.T) {
+	service, _ := setupValorizacaoService(t)
+	aprovado, rejeitado := true, false
+
+	t.Run("Aprovar registro", func(t *testing.T) {
+		registro, err := service.CreateRegistro(context.Background(), "BRA-2020-00000001", novoRegistro(models.CategoriaCompeticao, models.NivelMedio), criadorID)
+		require.NoError(t, err)
+
+		validado, err := service.Validar(context.Background(), registro.ID, validadorID, &models.ValidarValorizacaoRequest{Aprovado: &aprovado})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusAprovado, validado.StatusValidacao)
+		require.NotNil(t, validado.ValidadoPor)
+		assert.Equal(t, validadorID, *validado.ValidadoPor)
+		assert.NotNil(t, validado.DataValidacao)
+
+		t.Run("Não validar duas vezes", func(t *testing.T) {
+			_, err := service.Validar(context.Background(), registro.ID, validadorID, &models.ValidarValorizacaoRequest{Aprovado: &rejeitado})
+
+			assert.True(t, apperrors.IsConflict(err))
+		})
+	})
+
+	t.Run("Rejeitar registro", func(t *testing.T) {
+		registro, err := service.CreateRegistro(context.Background(), "BRA-2020-00000001", novoRegistro(models.CategoriaSaude, models.NivelBaixo), criadorID)
+		require.NoError(t, err)
+
+		validado, err := service.Validar(context.Background(), registro.ID, validadorID, &models.ValidarValorizacaoRequest{
+			Aprovado:    &rejeitado,
+			Observacoes: "Documentação insuficiente",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusRejeitado, validado.StatusValidacao)
+		assert.Equal(t, "Documentação insuficiente", validado.ObservacoesValidacao)
+
+		salvo, err := service.GetByID(context.Background(), registro.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusRejeitado, salvo.StatusValidacao)
+	})
+
+	t.Run("Registro não encontrado", func(t *testing.T) {
+		_, err := service.Validar(context.Background(), 99999, validadorID, &models.ValidarValorizacaoRequest{Aprovado: &aprovado})
+
+		assert.True(t, apperrors.IsNotFound(err))
+	})
+
+	pontuacao, err := service.GetPontuacao(context.Background(), "BRA-2020-00000001")
+	require.NoError(t, err)
+	assert.Equal(t, 100, pontuacao.TotalPontos)
+}
@@ -0,0 +1,94 @@
+package verificacao
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+	logger  *logging.Logger
+}
+
+func NewHandler(service Service, logger *logging.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// VerificarEquinoid godoc
+// @Summary Verificar EquinoId
+// @Description Confere o formato e os dígitos verificadores do EquinoId e, se o equino estiver registrado, devolve o cartão público (nome, raça, sexo, ano de nascimento e foto, quando publicada pelo proprietário). Não requer autenticação
+// @Tags Verificação Pública
+// @Produce json
+// @Param equinoid path string true "EquinoId a verificar"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /public/verify/equinoid/{equinoid} [get]
+func (h *Handler) VerificarEquinoid(c *gin.Context) {
+	verificacao, err := h.service.VerificarEquinoid(c.Request.Context(), c.Param("equinoid"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao verificar EquinoId")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   verificacao.Mensagem,
+		Timestamp: time.Now(),
+		Data:      verificacao,
+	})
+}
+
+// VerificarDocumento godoc
+// @Summary Verificar documento ou certificado
+// @Description Localiza certificados, passaportes e assinaturas emitidos pela plataforma pelo número de série (ou código do passaporte) ou pelo SHA-256 do arquivo/assinatura e informa se continuam válidos. Não requer autenticação
+// @Tags Verificação Pública
+// @Produce json
+// @Param serial query string false "Número de série do certificado ou código do passaporte"
+// @Param hash query string false "SHA-256 em hexadecimal do documento ou da assinatura"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /public/verify/documento [get]
+func (h *Handler) VerificarDocumento(c *gin.Context) {
+	verificacao, err := h.service.VerificarDocumento(c.Request.Context(), c.Query("serial"), c.Query("hash"))
+	if err != nil {
+		h.respondError(c, err, "Erro ao verificar documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:   true,
+		Message:   verificacao.Mensagem,
+		Timestamp: time.Now(),
+		Data:      verificacao,
+	})
+}
+
+func (h *Handler) respondError(c *gin.Context, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+
+	switch {
+	case apperrors.IsValidation(err):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case apperrors.IsNotFound(err):
+		status = http.StatusNotFound
+		message = err.Error()
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,99 @@
+package verificacao
+
+import (
+	"context"
+	"errors"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// maxResultadosHash registros de cada tipo devolvidos para um mesmo hash
+const maxResultadosHash = 20
+
+type Repository interface {
+	FindFotoPublica(ctx context.Context, equinoID uint) (*models.DocumentoVersao, error)
+	FindCertificado(ctx context.Context, serial string) (*models.Certificate, error)
+	FindPassaporte(ctx context.Context, codigo string) (*models.PassaporteEquino, error)
+	FindPassaportesByHash(ctx context.Context, hash string) ([]*models.PassaporteEquino, error)
+	FindAssinaturasByHash(ctx context.Context, hash string) ([]*models.DigitalSignature, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// FindFotoPublica versão atual da foto de perfil mais recente marcada como pública pelo proprietário; nil quando não há
+func (r *repository) FindFotoPublica(ctx context.Context, equinoID uint) (*models.DocumentoVersao, error) {
+	var versao models.DocumentoVersao
+	err := r.db.WithContext(ctx).
+		Joins("JOIN documentos ON documentos.id = documento_versoes.documento_id AND documentos.versao_atual = documento_versoes.numero").
+		Where("documentos.equino_id = ? AND documentos.categoria = ? AND documentos.visibilidade = ? AND documentos.deleted_at IS NULL",
+			equinoID, models.CategoriaFotoPerfil, models.VisibilidadePublico).
+		Order("documentos.created_at DESC").
+		First(&versao).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperrors.NewDatabaseError("find_foto_publica", "erro ao buscar foto pública do equino", err)
+	}
+	return &versao, nil
+}
+
+// FindCertificado certificado pelo número de série; nil quando não existe
+func (r *repository) FindCertificado(ctx context.Context, serial string) (*models.Certificate, error) {
+	var certificado models.Certificate
+	if err := r.db.WithContext(ctx).Where("serial_number = ?", serial).First(&certificado).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperrors.NewDatabaseError("find_certificado", "erro ao buscar certificado", err)
+	}
+	return &certificado, nil
+}
+
+// FindPassaporte passaporte pelo código impresso; nil quando não existe
+func (r *repository) FindPassaporte(ctx context.Context, codigo string) (*models.PassaporteEquino, error) {
+	var passaporte models.PassaporteEquino
+	if err := r.db.WithContext(ctx).Where("codigo = ?", codigo).First(&passaporte).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperrors.NewDatabaseError("find_passaporte", "erro ao buscar passaporte", err)
+	}
+	return &passaporte, nil
+}
+
+// FindPassaportesByHash passaportes cujo arquivo ou assinatura embutida tem o hash informado
+func (r *repository) FindPassaportesByHash(ctx context.Context, hash string) ([]*models.PassaporteEquino, error) {
+	var passaportes []*models.PassaporteEquino
+	err := r.db.WithContext(ctx).
+		Where("hash = ? OR signature_hash = ?", hash, hash).
+		Order("emitido_em DESC").
+		Limit(maxResultadosHash).
+		Find(&passaportes).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("find_passaportes_hash", "erro ao buscar passaportes pelo hash", err)
+	}
+	return passaportes, nil
+}
+
+// FindAssinaturasByHash assinaturas do documento assinado ou da própria assinatura com o hash informado
+func (r *repository) FindAssinaturasByHash(ctx context.Context, hash string) ([]*models.DigitalSignature, error) {
+	var assinaturas []*models.DigitalSignature
+	err := r.db.WithContext(ctx).
+		Where("document_hash = ? OR signature_hash = ? OR pdf_signature_hash = ?", hash, hash, hash).
+		Order("timestamp DESC").
+		Limit(maxResultadosHash).
+		Find(&assinaturas).Error
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("find_assinaturas_hash", "erro ao buscar assinaturas pelo hash", err)
+	}
+	return assinaturas, nil
+}
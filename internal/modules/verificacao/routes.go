@@ -0,0 +1,15 @@
+package verificacao
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes rotas públicas, sem autenticação; rateLimit é mais restritivo que o limite global da API
+func RegisterRoutes(rg *gin.RouterGroup, handler *Handler, rateLimit gin.HandlerFunc) {
+	verify := rg.Group("/public/verify")
+	verify.Use(rateLimit)
+	{
+		verify.GET("/equinoid/:equinoid", handler.VerificarEquinoid)
+		verify.GET("/documento", handler.VerificarDocumento)
+	}
+}
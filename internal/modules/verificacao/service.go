@@ -0,0 +1,297 @@
+package verificacao
+
+import (
+	"context"
+	"encoding/hex"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/utils"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/equinoid/backend/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// statusAssinaturaRegistrada assinatura registrada cujo certificado não estava revogado no momento da assinatura
+const statusAssinaturaRegistrada = "registrada"
+
+// EquinoRepository busca o equino do EquinoId consultado
+type EquinoRepository interface {
+	FindByEquinoid(ctx context.Context, equinoid string) (*models.Equino, error)
+}
+
+type Service interface {
+	VerificarEquinoid(ctx context.Context, equinoid string) (*models.VerificacaoEquinoid, error)
+	VerificarDocumento(ctx context.Context, serial, hash string) (*models.VerificacaoDocumento, error)
+}
+
+type service struct {
+	repo       Repository
+	equinoRepo EquinoRepository
+	store      storage.Storage
+	urlTTL     time.Duration
+	logger     *logging.Logger
+}
+
+// NewService cria o serviço de verificação pública; sem armazenamento configurado o cartão é emitido sem foto
+func NewService(repo Repository, equinoRepo EquinoRepository, store storage.Storage, cfg *config.Config, logger *logging.Logger) Service {
+	return &service{
+		repo:       repo,
+		equinoRepo: equinoRepo,
+		store:      store,
+		urlTTL:     cfg.DocumentURLTTL,
+		logger:     logger,
+	}
+}
+
+// VerificarEquinoid confere formato e dígitos verificadores da versão do EquinoId e, se o equino estiver registrado
+// (inclusive pelo alias v2), devolve o cartão público
+func (s *service) VerificarEquinoid(ctx context.Context, equinoid string) (*models.VerificacaoEquinoid, error) {
+	equinoid = utils.NormalizeEquinoId(equinoid)
+	if equinoid == "" {
+		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "EquinoId é obrigatório"}
+	}
+
+	result := &models.VerificacaoEquinoid{Equinoid: equinoid, VerificadoEm: time.Now()}
+	info, err := utils.ParseEquinoId(equinoid)
+	if err != nil {
+		result.Mensagem = "EquinoId em formato inválido: " + err.Error()
+		return result, nil
+	}
+	result.Versao = info.Versao
+	result.FormatoValido = true
+
+	if err := utils.ValidateEquinoIdChecksum(equinoid); err != nil {
+		result.Mensagem = "Dígitos verificadores não conferem: o EquinoId foi digitado incorretamente ou adulterado"
+		return result, nil
+	}
+	result.ChecksumValido = true
+
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			result.Mensagem = "EquinoId válido, mas não registrado na plataforma"
+			return result, nil
+		}
+		return nil, err
+	}
+
+	result.Registrado = true
+	result.Cartao = s.cartao(ctx, equino)
+	result.Mensagem = "EquinoId válido e registrado"
+	return result, nil
+}
+
+// VerificarDocumento localiza certificados, passaportes e assinaturas emitidos pela plataforma pelo serial ou pelo
+// SHA-256 (hex) do arquivo ou da assinatura
+func (s *service) VerificarDocumento(ctx context.Context, serial, hash string) (*models.VerificacaoDocumento, error) {
+	serial = strings.TrimSpace(serial)
+	hash = strings.ToLower(strings.TrimSpace(hash))
+
+	var (
+		resultados []models.DocumentoVerificado
+		err        error
+		consulta   string
+	)
+	switch {
+	case serial != "" && hash != "":
+		return nil, &apperrors.ValidationError{Field: "serial", Message: "informe o serial ou o hash, não ambos"}
+	case serial != "":
+		consulta = serial
+		resultados, err = s.verificarSerial(ctx, serial)
+	case hash != "":
+		if _, decodeErr := hex.DecodeString(hash); decodeErr != nil || len(hash) != 64 {
+			return nil, &apperrors.ValidationError{Field: "hash", Message: "hash deve ser um SHA-256 em hexadecimal", Value: hash}
+		}
+		consulta = hash
+		resultados, err = s.verificarHash(ctx, hash)
+	default:
+		return nil, &apperrors.ValidationError{Field: "serial", Message: "informe o serial ou o hash do documento"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.VerificacaoDocumento{
+		Consulta:     consulta,
+		Encontrado:   len(resultados) > 0,
+		Resultados:   resultados,
+		VerificadoEm: time.Now(),
+	}
+	switch {
+	case !result.Encontrado:
+		result.Resultados = []models.DocumentoVerificado{}
+		result.Mensagem = "Nenhum documento emitido pela plataforma corresponde à consulta"
+	case algumValido(resultados):
+		result.Mensagem = "Documento emitido pela plataforma e válido"
+	default:
+		result.Mensagem = "Documento emitido pela plataforma, mas não está mais válido"
+	}
+	return result, nil
+}
+
+// verificarSerial códigos de passaporte são UUIDs; os demais seriais são de certificados
+func (s *service) verificarSerial(ctx context.Context, serial string) ([]models.DocumentoVerificado, error) {
+	if _, err := uuid.Parse(serial); err == nil {
+		passaporte, err := s.repo.FindPassaporte(ctx, strings.ToLower(serial))
+		if err != nil || passaporte == nil {
+			return nil, err
+		}
+		return []models.DocumentoVerificado{passaporteVerificado(passaporte)}, nil
+	}
+
+	certificado, err := s.repo.FindCertificado(ctx, serial)
+	if err != nil || certificado == nil {
+		return nil, err
+	}
+	return []models.DocumentoVerificado{certificadoVerificado(certificado, time.Now())}, nil
+}
+
+func (s *service) verificarHash(ctx context.Context, hash string) ([]models.DocumentoVerificado, error) {
+	passaportes, err := s.repo.FindPassaportesByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	assinaturas, err := s.repo.FindAssinaturasByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	resultados := make([]models.DocumentoVerificado, 0, len(passaportes)+len(assinaturas))
+	for _, passaporte := range passaportes {
+		resultados = append(resultados, passaporteVerificado(passaporte))
+	}
+	certificados := make(map[string]*models.Certificate)
+	for _, assinatura := range assinaturas {
+		certificado, cached := certificados[assinatura.CertificateSerial]
+		if !cached {
+			if certificado, err = s.repo.FindCertificado(ctx, assinatura.CertificateSerial); err != nil {
+				return nil, err
+			}
+			certificados[assinatura.CertificateSerial] = certificado
+		}
+		resultados = append(resultados, assinaturaVerificada(assinatura, certificado))
+	}
+	return resultados, nil
+}
+
+// cartao identificação pública do equino; proprietário, microchip e demais dados cadastrais não são expostos
+func (s *service) cartao(ctx context.Context, equino *models.Equino) *models.CartaoPublicoEquino {
+	cartao := &models.CartaoPublicoEquino{
+		Equinoid: equino.Equinoid,
+		Nome:     equino.Nome,
+		Raca:     equino.Raca,
+		Sexo:     equino.Sexo,
+	}
+	if equino.DataNascimento != nil {
+		ano := equino.DataNascimento.Year()
+		cartao.AnoNascimento = &ano
+	}
+	cartao.FotoURL = s.fotoURL(ctx, equino)
+	return cartao
+}
+
+// fotoURL URL temporária da miniatura da foto de perfil, somente quando o proprietário a publicou no cofre; falhas
+// no armazenamento apenas omitem a foto
+func (s *service) fotoURL(ctx context.Context, equino *models.Equino) *string {
+	if s.store == nil {
+		return nil
+	}
+	versao, err := s.repo.FindFotoPublica(ctx, equino.ID)
+	if err != nil {
+		s.logger.LogError(err, "VerificacaoService.fotoURL", logging.Fields{"equinoid": equino.Equinoid})
+		return nil
+	}
+	if versao == nil {
+		return nil
+	}
+
+	key, filename := versao.StorageKey, versao.NomeArquivo
+	if versao.ThumbnailKey != nil {
+		key = *versao.ThumbnailKey
+		filename = strings.TrimSuffix(filename, path.Ext(filename)) + "-miniatura.jpg"
+	}
+	url, err := s.store.PresignGet(ctx, key, s.urlTTL, filename)
+	if err != nil {
+		s.logger.LogError(err, "VerificacaoService.fotoURL", logging.Fields{"equinoid": equino.Equinoid, "documento_id": versao.DocumentoID})
+		return nil
+	}
+	return &url
+}
+
+func passaporteVerificado(passaporte *models.PassaporteEquino) models.DocumentoVerificado {
+	return models.DocumentoVerificado{
+		Tipo:          models.DocumentoVerificadoPassaporte,
+		Identificador: passaporte.Codigo,
+		Valido:        passaporte.Status == models.PassaporteValido,
+		Status:        string(passaporte.Status),
+		Equinoid:      passaporte.Equinoid,
+		EmitidoEm:     passaporte.EmitidoEm,
+		RevogadoEm:    passaporte.RevogadoEm,
+	}
+}
+
+func certificadoVerificado(certificado *models.Certificate, now time.Time) models.DocumentoVerificado {
+	status := models.CertificateStatusValid
+	switch {
+	case certificado.IsRevoked:
+		status = models.CertificateStatusRevoked
+	case now.After(certificado.ExpiresAt):
+		status = models.CertificateStatusExpired
+	case now.Before(certificado.ValidFrom):
+		status = models.CertificateStatusNotYetValid
+	}
+	validoAte := certificado.ValidTo
+	return models.DocumentoVerificado{
+		Tipo:          models.DocumentoVerificadoCertificado,
+		Identificador: certificado.SerialNumber,
+		Subtipo:       certificado.CertificateType,
+		Valido:        status == models.CertificateStatusValid,
+		Status:        status,
+		Equinoid:      certificado.Equinoid,
+		EmitidoEm:     certificado.IssuedAt,
+		ValidoAte:     &validoAte,
+		RevogadoEm:    certificado.RevokedAt,
+	}
+}
+
+// assinaturaVerificada a assinatura continua válida se o certificado foi revogado depois do momento da assinatura
+// (carimbo de tempo, quando houver); certificados fora do banco são os de serviço, como o selo institucional
+func assinaturaVerificada(assinatura *models.DigitalSignature, certificado *models.Certificate) models.DocumentoVerificado {
+	assinadaEm := assinatura.Timestamp
+	if assinatura.TimestampTime != nil {
+		assinadaEm = *assinatura.TimestampTime
+	}
+
+	status := statusAssinaturaRegistrada
+	var revogadoEm *time.Time
+	if certificado != nil && certificado.IsRevoked {
+		revogadoEm = certificado.RevokedAt
+		if certificado.RevokedAt == nil || !certificado.RevokedAt.After(assinadaEm) {
+			status = models.CertificateStatusRevoked
+		}
+	}
+
+	return models.DocumentoVerificado{
+		Tipo:          models.DocumentoVerificadoAssinatura,
+		Identificador: assinatura.SignatureHash,
+		Subtipo:       assinatura.DocumentType,
+		Valido:        status == statusAssinaturaRegistrada,
+		Status:        status,
+		EmitidoEm:     assinadaEm,
+		RevogadoEm:    revogadoEm,
+	}
+}
+
+func algumValido(resultados []models.DocumentoVerificado) bool {
+	for _, resultado := range resultados {
+		if resultado.Valido {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,382 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// auditChainLockKey chave do advisory lock que serializa a escrita da cadeia entre instâncias (Postgres)
+const auditChainLockKey = 0x45515541
+
+const (
+	verifyBatchSize = 1000
+	maxReportBreaks = 100
+)
+
+// Tipos de quebra detectados na verificação da cadeia
+const (
+	BreakHashMismatch     = "hash_mismatch"
+	BreakPrevHashMismatch = "prev_hash_mismatch"
+	BreakMissingHash      = "missing_hash"
+	BreakCheckpointRoot   = "checkpoint_root_mismatch"
+	BreakCheckpointAnchor = "checkpoint_anchor_invalid"
+)
+
+// ChainBreak representa uma inconsistência encontrada na cadeia de auditoria
+type ChainBreak struct {
+	Type         string `json:"type"`
+	LogID        uint   `json:"log_id,omitempty"`
+	CheckpointID uint   `json:"checkpoint_id,omitempty"`
+	Expected     string `json:"expected,omitempty"`
+	Actual       string `json:"actual,omitempty"`
+	Message      string `json:"message"`
+}
+
+// ChainReport resultado da verificação da cadeia de auditoria
+type ChainReport struct {
+	Valid              bool         `json:"valid"`
+	FirstLogID         uint         `json:"first_log_id"`
+	LastLogID          uint         `json:"last_log_id"`
+	VerifiedEntries    int          `json:"verified_entries"`
+	UnchainedEntries   int          `json:"unchained_entries"`
+	CheckedCheckpoints int          `json:"checked_checkpoints"`
+	Breaks             []ChainBreak `json:"breaks"`
+	Truncated          bool         `json:"truncated"`
+	VerifiedAt         time.Time    `json:"verified_at"`
+}
+
+func (r *ChainReport) addBreak(b ChainBreak) {
+	r.Valid = false
+	if len(r.Breaks) >= maxReportBreaks {
+		r.Truncated = true
+		return
+	}
+	r.Breaks = append(r.Breaks, b)
+}
+
+// chainedContent conteúdo canônico de um registro usado no cálculo do hash
+type chainedContent struct {
+	PrevHash   string          `json:"prev_hash"`
+	UserID     string          `json:"user_id"`
+	ActorID    *uint           `json:"actor_id"`
+	Action     string          `json:"action"`
+	Resource   string          `json:"resource"`
+	ResourceID string          `json:"resource_id"`
+	Details    json.RawMessage `json:"details"`
+	IPAddress  string          `json:"ip_address"`
+	UserAgent  string          `json:"user_agent"`
+	Location   string          `json:"location"`
+	Success    bool            `json:"success"`
+	ErrorMsg   string          `json:"error_msg"`
+	RiskLevel  string          `json:"risk_level"`
+	Timestamp  string          `json:"timestamp"`
+}
+
+// ComputeEntryHash calcula o hash SHA-256 do registro encadeado ao seu PrevHash
+func ComputeEntryHash(log *models.AuditLog) (string, error) {
+	details, err := json.Marshal(log.Details)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit details: %w", err)
+	}
+
+	content := chainedContent{
+		PrevHash:  log.PrevHash,
+		ActorID:   log.ActorID,
+		Action:    log.Action,
+		Resource:  log.Resource,
+		Details:   details,
+		IPAddress: log.IPAddress,
+		UserAgent: log.UserAgent,
+		Location:  log.Location,
+		Success:   log.Success,
+		ErrorMsg:  log.ErrorMsg,
+		RiskLevel: log.RiskLevel,
+		Timestamp: log.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	if log.UserID != nil {
+		content.UserID = log.UserID.String()
+	}
+	if log.ResourceID != nil {
+		content.ResourceID = log.ResourceID.String()
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// MerkleRoot calcula a raiz de Merkle (SHA-256) de uma sequência de hashes hexadecimais
+func MerkleRoot(hashes []string) (string, error) {
+	if len(hashes) == 0 {
+		return "", errors.New("merkle root requires at least one hash")
+	}
+
+	level := make([][]byte, len(hashes))
+	for i, h := range hashes {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return "", fmt.Errorf("invalid hash at position %d: %w", i, err)
+		}
+		level[i] = b
+	}
+
+	for len(level) > 1 {
+		// Nível ímpar: o último nó é duplicado
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		next := make([][]byte, 0, len(level)/2)
+		for i := 0; i < len(level); i += 2 {
+			sum := sha256.Sum256(append(append([]byte{}, level[i]...), level[i+1]...))
+			next = append(next, sum[:])
+		}
+		level = next
+	}
+
+	return hex.EncodeToString(level[0]), nil
+}
+
+// appendToChain grava o registro encadeando-o ao último hash da cadeia
+func (a *AuditLogger) appendToChain(ctx context.Context, auditLog *models.AuditLog) error {
+	a.chainMu.Lock()
+	defer a.chainMu.Unlock()
+
+	// Normaliza os campos para que o conteúdo relido do banco produza o mesmo hash
+	auditLog.Timestamp = auditLog.Timestamp.UTC().Truncate(time.Microsecond)
+	if auditLog.Details != nil {
+		normalized, err := normalizeDetails(auditLog.Details)
+		if err != nil {
+			return err
+		}
+		auditLog.Details = normalized
+	}
+
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+				return fmt.Errorf("failed to lock audit chain: %w", err)
+			}
+		}
+
+		prevHash, err := a.lastChainHash(tx)
+		if err != nil {
+			return err
+		}
+
+		auditLog.PrevHash = prevHash
+		hash, err := ComputeEntryHash(auditLog)
+		if err != nil {
+			return err
+		}
+		auditLog.Hash = hash
+
+		return tx.Create(auditLog).Error
+	})
+}
+
+// lastChainHash retorna o hash do último registro encadeado ou, se todos foram expurgados, o do último checkpoint
+func (a *AuditLogger) lastChainHash(tx *gorm.DB) (string, error) {
+	var last models.AuditLog
+	err := tx.Unscoped().
+		Where("hash IS NOT NULL AND hash <> ''").
+		Order("id DESC").
+		Limit(1).
+		Find(&last).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+	if last.ID != 0 {
+		return last.Hash, nil
+	}
+
+	var checkpoint models.AuditCheckpoint
+	if err := tx.Order("to_log_id DESC").Limit(1).Find(&checkpoint).Error; err != nil {
+		return "", fmt.Errorf("failed to read audit checkpoint: %w", err)
+	}
+	return checkpoint.LastHash, nil
+}
+
+// VerifyChain percorre a cadeia entre fromID e toID (0 = sem limite) e reporta quebras de integridade
+func (a *AuditLogger) VerifyChain(ctx context.Context, fromID, toID uint) (*ChainReport, error) {
+	report := &ChainReport{Valid: true, Breaks: []ChainBreak{}, VerifiedAt: time.Now()}
+
+	query := a.db.WithContext(ctx).Unscoped().Model(&models.AuditLog{}).Where("id >= ?", fromID)
+	if toID > 0 {
+		query = query.Where("id <= ?", toID)
+	}
+
+	var expectedPrev string
+	started := false
+
+	var batch []models.AuditLog
+	result := query.Order("id ASC").FindInBatches(&batch, verifyBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			entry := &batch[i]
+
+			if entry.Hash == "" {
+				if started {
+					report.addBreak(ChainBreak{
+						Type:    BreakMissingHash,
+						LogID:   entry.ID,
+						Message: "registro sem hash dentro da cadeia",
+					})
+				} else {
+					// Registros anteriores à ativação da cadeia
+					report.UnchainedEntries++
+				}
+				continue
+			}
+
+			if !started {
+				prev, err := a.expectedPrevHash(ctx, entry.ID)
+				if err != nil {
+					return err
+				}
+				expectedPrev = prev
+				report.FirstLogID = entry.ID
+				started = true
+			}
+
+			if entry.PrevHash != expectedPrev {
+				report.addBreak(ChainBreak{
+					Type:     BreakPrevHashMismatch,
+					LogID:    entry.ID,
+					Expected: expectedPrev,
+					Actual:   entry.PrevHash,
+					Message:  "registro não aponta para o anterior (remoção, inserção ou reordenação)",
+				})
+			}
+
+			computed, err := ComputeEntryHash(entry)
+			if err != nil {
+				return err
+			}
+			if computed != entry.Hash {
+				report.addBreak(ChainBreak{
+					Type:     BreakHashMismatch,
+					LogID:    entry.ID,
+					Expected: computed,
+					Actual:   entry.Hash,
+					Message:  "conteúdo do registro foi alterado",
+				})
+			}
+
+			expectedPrev = entry.Hash
+			report.LastLogID = entry.ID
+			report.VerifiedEntries++
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to verify audit chain: %w", result.Error)
+	}
+
+	if err := a.verifyCheckpoints(ctx, fromID, toID, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// expectedPrevHash determina o PrevHash esperado para o primeiro registro verificado
+func (a *AuditLogger) expectedPrevHash(ctx context.Context, firstID uint) (string, error) {
+	db := a.db.WithContext(ctx)
+
+	var prev models.AuditLog
+	if err := db.Unscoped().Where("id < ? AND hash IS NOT NULL AND hash <> ''", firstID).Order("id DESC").Limit(1).Find(&prev).Error; err != nil {
+		return "", err
+	}
+	if prev.ID != 0 {
+		return prev.Hash, nil
+	}
+
+	// Início da cadeia foi expurgado pela retenção: o elo é o último checkpoint anterior
+	var checkpoint models.AuditCheckpoint
+	if err := db.Where("to_log_id < ?", firstID).Order("to_log_id DESC").Limit(1).Find(&checkpoint).Error; err != nil {
+		return "", err
+	}
+	return checkpoint.LastHash, nil
+}
+
+// verifyCheckpoints recalcula a raiz de Merkle dos checkpoints cujo intervalo ainda está no banco
+func (a *AuditLogger) verifyCheckpoints(ctx context.Context, fromID, toID uint, report *ChainReport) error {
+	db := a.db.WithContext(ctx)
+
+	query := db.Where("to_log_id >= ?", fromID)
+	if toID > 0 {
+		query = query.Where("from_log_id <= ?", toID)
+	}
+
+	var checkpoints []models.AuditCheckpoint
+	if err := query.Order("to_log_id ASC").Find(&checkpoints).Error; err != nil {
+		return fmt.Errorf("failed to load audit checkpoints: %w", err)
+	}
+
+	for i := range checkpoints {
+		cp := &checkpoints[i]
+
+		var hashes []string
+		if err := db.Unscoped().Model(&models.AuditLog{}).
+			Where("id >= ? AND id <= ? AND hash IS NOT NULL AND hash <> ''", cp.FromLogID, cp.ToLogID).
+			Order("id ASC").
+			Pluck("hash", &hashes).Error; err != nil {
+			return fmt.Errorf("failed to load checkpoint entries: %w", err)
+		}
+
+		// Intervalo expurgado pela retenção: a integridade é garantida pela âncora externa
+		if len(hashes) == 0 {
+			continue
+		}
+		report.CheckedCheckpoints++
+
+		root, err := MerkleRoot(hashes)
+		if err != nil {
+			return err
+		}
+		if len(hashes) != cp.EntryCount || root != cp.MerkleRoot {
+			report.addBreak(ChainBreak{
+				Type:         BreakCheckpointRoot,
+				CheckpointID: cp.ID,
+				Expected:     cp.MerkleRoot,
+				Actual:       root,
+				Message:      fmt.Sprintf("raiz de Merkle não confere (%d de %d registros)", len(hashes), cp.EntryCount),
+			})
+		}
+
+		if verifier, ok := a.anchor.(AnchorVerifier); ok && cp.AnchorStatus == CheckpointAnchored && cp.AnchorNetwork == a.anchor.Network() {
+			if err := verifier.VerifyAnchor(ctx, cp); err != nil {
+				report.addBreak(ChainBreak{
+					Type:         BreakCheckpointAnchor,
+					CheckpointID: cp.ID,
+					Message:      err.Error(),
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+func normalizeDetails(details models.JSONB) (models.JSONB, error) {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit details: %w", err)
+	}
+	var normalized models.JSONB
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to normalize audit details: %w", err)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAuditLogger(t *testing.T, retention time.Duration) (*AuditLogger, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Skip("sqlite driver unavailable for tests")
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}, &models.AuditCheckpoint{}))
+
+	return NewAuditLogger(db, retention), db
+}
+
+// registrarEventos grava n eventos encadeados com o timestamp informado e retorna os ids na ordem
+func registrarEventos(t *testing.T, logger *AuditLogger, db *gorm.DB, n int, timestamp time.Time) []uint {
+	for i := 0; i < n; i++ {
+		require.NoError(t, logger.LogEvent(context.Background(), &AuditEvent{
+			Action:    "resource_updated",
+			Resource:  "equino",
+			Details:   map[string]interface{}{"campo": "nome", "versao": i},
+			Success:   true,
+			Timestamp: timestamp,
+		}))
+	}
+
+	var ids []uint
+	require.NoError(t, db.Unscoped().Model(&models.AuditLog{}).Order("id DESC").Limit(n).Pluck("id", &ids).Error)
+	require.Len(t, ids, n)
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+	return ids
+}
+
+func TestAuditLogger_VerifyChain(t *testing.T) {
+	logger, db := setupAuditLogger(t, 24*time.Hour)
+	ctx := context.Background()
+	ids := registrarEventos(t, logger, db, 5, time.Now())
+
+	t.Run("Cadeia íntegra", func(t *testing.T) {
+		report, err := logger.VerifyChain(ctx, 0, 0)
+
+		require.NoError(t, err)
+		assert.True(t, report.Valid, report.Breaks)
+		assert.Empty(t, report.Breaks)
+		assert.Equal(t, 5, report.VerifiedEntries)
+		assert.Equal(t, ids[0], report.FirstLogID)
+		assert.Equal(t, ids[4], report.LastLogID)
+	})
+
+	t.Run("Registro alterado diretamente no banco", func(t *testing.T) {
+		adulterado := ids[2]
+		require.NoError(t, db.Model(&models.AuditLog{}).Where("id = ?", adulterado).UpdateColumn("action", "resource_deleted").Error)
+
+		report, err := logger.VerifyChain(ctx, 0, 0)
+
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.Len(t, report.Breaks, 1)
+		assert.Equal(t, BreakHashMismatch, report.Breaks[0].Type)
+		assert.Equal(t, adulterado, report.Breaks[0].LogID)
+		assert.Equal(t, 5, report.VerifiedEntries)
+	})
+
+	t.Run("Registro removido da cadeia", func(t *testing.T) {
+		removido := ids[3]
+		require.NoError(t, db.Unscoped().Delete(&models.AuditLog{}, removido).Error)
+
+		report, err := logger.VerifyChain(ctx, ids[3], 0)
+
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.Len(t, report.Breaks, 1)
+		assert.Equal(t, BreakPrevHashMismatch, report.Breaks[0].Type)
+		assert.Equal(t, ids[4], report.Breaks[0].LogID)
+	})
+}
+
+func TestAuditLogger_CleanupOldLogs(t *testing.T) {
+	logger, db := setupAuditLogger(t, 24*time.Hour)
+	ctx := context.Background()
+
+	expirados := registrarEventos(t, logger, db, 3, time.Now().Add(-48*time.Hour))
+	checkpoint, err := logger.CreateCheckpoint(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.Equal(t, expirados[2], checkpoint.ToLogID)
+
+	// Expirados, porém ainda não consolidados em checkpoint
+	foraDoCheckpoint := registrarEventos(t, logger, db, 2, time.Now().Add(-48*time.Hour))
+	recentes := registrarEventos(t, logger, db, 2, time.Now())
+
+	removidos, err := logger.CleanupOldLogs(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), removidos)
+
+	var restantes []uint
+	require.NoError(t, db.Unscoped().Model(&models.AuditLog{}).Order("id ASC").Pluck("id", &restantes).Error)
+	assert.Equal(t, append(foraDoCheckpoint, recentes...), restantes)
+
+	t.Run("Cadeia remanescente continua no último hash do checkpoint", func(t *testing.T) {
+		report, err := logger.VerifyChain(ctx, 0, 0)
+
+		require.NoError(t, err)
+		assert.True(t, report.Valid, report.Breaks)
+		assert.Equal(t, 4, report.VerifiedEntries)
+		assert.Equal(t, foraDoCheckpoint[0], report.FirstLogID)
+	})
+
+	t.Run("Sem checkpoint expirado nada é removido", func(t *testing.T) {
+		removidos, err := logger.CleanupOldLogs(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, removidos)
+	})
+}
+
+func TestAuditLogger_CleanupOldLogsSemCheckpoint(t *testing.T) {
+	logger, db := setupAuditLogger(t, time.Hour)
+	registrarEventos(t, logger, db, 3, time.Now().Add(-48*time.Hour))
+
+	removidos, err := logger.CleanupOldLogs(context.Background())
+
+	require.NoError(t, err)
+	assert.Zero(t, removidos)
+	var total int64
+	require.NoError(t, db.Unscoped().Model(&models.AuditLog{}).Count(&total).Error)
+	assert.Equal(t, int64(3), total, "registros sem checkpoint não podem ser expurgados")
+}
@@ -0,0 +1,303 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/security/blockchain"
+)
+
+// maxCheckpointEntries limita a quantidade de registros consolidados em um único checkpoint
+const maxCheckpointEntries = 10000
+
+// Status de ancoragem de checkpoints
+const (
+	CheckpointPending  = "pending"
+	CheckpointAnchored = "anchored"
+	CheckpointFailed   = "failed"
+)
+
+// Anchor publica a raiz de Merkle de um checkpoint fora do banco de dados
+type Anchor interface {
+	Network() string
+	Anchor(ctx context.Context, checkpoint *models.AuditCheckpoint) (string, error)
+}
+
+// AnchorVerifier âncora capaz de conferir um checkpoint já publicado
+type AnchorVerifier interface {
+	VerifyAnchor(ctx context.Context, checkpoint *models.AuditCheckpoint) error
+}
+
+// SetAnchor define onde os checkpoints da cadeia são ancorados
+func (a *AuditLogger) SetAnchor(anchor Anchor) {
+	a.anchor = anchor
+}
+
+// CreateCheckpoint consolida os registros encadeados desde o último checkpoint em uma raiz de Merkle e a ancora
+func (a *AuditLogger) CreateCheckpoint(ctx context.Context) (*models.AuditCheckpoint, error) {
+	a.checkpointMu.Lock()
+	defer a.checkpointMu.Unlock()
+
+	db := a.db.WithContext(ctx)
+
+	var last models.AuditCheckpoint
+	if err := db.Order("to_log_id DESC").Limit(1).Find(&last).Error; err != nil {
+		return nil, fmt.Errorf("failed to load last checkpoint: %w", err)
+	}
+
+	var entries []models.AuditLog
+	if err := db.Unscoped().
+		Select("id", "hash", "timestamp").
+		Where("id > ? AND hash IS NOT NULL AND hash <> ''", last.ToLogID).
+		Order("id ASC").
+		Limit(maxCheckpointEntries).
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load audit entries: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]string, len(entries))
+	for i, e := range entries {
+		hashes[i] = e.Hash
+	}
+	root, err := MerkleRoot(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	lastEntry := entries[len(entries)-1]
+	checkpoint := &models.AuditCheckpoint{
+		FromLogID:          entries[0].ID,
+		ToLogID:            lastEntry.ID,
+		EntryCount:         len(entries),
+		MerkleRoot:         root,
+		LastHash:           lastEntry.Hash,
+		LastTimestamp:      lastEntry.Timestamp,
+		PrevCheckpointRoot: last.MerkleRoot,
+		AnchorStatus:       CheckpointPending,
+		CreatedAt:          time.Now(),
+	}
+
+	if err := db.Create(checkpoint).Error; err != nil {
+		return nil, fmt.Errorf("failed to save audit checkpoint: %w", err)
+	}
+
+	if a.anchor == nil {
+		return checkpoint, nil
+	}
+
+	checkpoint.AnchorNetwork = a.anchor.Network()
+	ref, anchorErr := a.anchor.Anchor(ctx, checkpoint)
+	if anchorErr != nil {
+		checkpoint.AnchorStatus = CheckpointFailed
+		checkpoint.AnchorError = anchorErr.Error()
+	} else {
+		now := time.Now()
+		checkpoint.AnchorStatus = CheckpointAnchored
+		checkpoint.AnchorRef = ref
+		checkpoint.AnchoredAt = &now
+	}
+
+	if err := db.Save(checkpoint).Error; err != nil {
+		return nil, fmt.Errorf("failed to update audit checkpoint: %w", err)
+	}
+	if anchorErr != nil {
+		return checkpoint, fmt.Errorf("failed to anchor audit checkpoint: %w", anchorErr)
+	}
+
+	return checkpoint, nil
+}
+
+// ListCheckpoints lista os checkpoints mais recentes da cadeia de auditoria
+func (a *AuditLogger) ListCheckpoints(ctx context.Context, limit, offset int) ([]models.AuditCheckpoint, int64, error) {
+	db := a.db.WithContext(ctx)
+
+	var total int64
+	if err := db.Model(&models.AuditCheckpoint{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var checkpoints []models.AuditCheckpoint
+	err := db.Order("to_log_id DESC").Limit(limit).Offset(offset).Find(&checkpoints).Error
+	return checkpoints, total, err
+}
+
+// BlockchainAnchor ancora checkpoints através do BlockchainManager
+type BlockchainAnchor struct {
+	manager *blockchain.BlockchainManager
+}
+
+// NewBlockchainAnchor cria uma âncora em blockchain
+func NewBlockchainAnchor(manager *blockchain.BlockchainManager) *BlockchainAnchor {
+	return &BlockchainAnchor{manager: manager}
+}
+
+func (b *BlockchainAnchor) Network() string {
+	return "blockchain"
+}
+
+// Anchor publica o checkpoint como um registro de auditoria; a raiz de Merkle vai no recurso, que compõe o hash on-chain
+func (b *BlockchainAnchor) Anchor(ctx context.Context, checkpoint *models.AuditCheckpoint) (string, error) {
+	record := &models.AuditLog{
+		ID:       checkpoint.ID,
+		Action:   "audit_checkpoint",
+		Resource: "audit_checkpoint:" + checkpoint.MerkleRoot,
+		Details: models.JSONB{
+			"merkle_root": checkpoint.MerkleRoot,
+			"from_log_id": checkpoint.FromLogID,
+			"to_log_id":   checkpoint.ToLogID,
+			"entry_count": checkpoint.EntryCount,
+			"last_hash":   checkpoint.LastHash,
+			"prev_root":   checkpoint.PrevCheckpointRoot,
+		},
+		Success:   true,
+		Timestamp: checkpoint.CreatedAt,
+	}
+
+	result, err := b.manager.StoreAuditLogOnBlockchain(ctx, record)
+	if err != nil {
+		return "", err
+	}
+	if result.Status == "failed" {
+		return "", fmt.Errorf("%s: %s", result.Network, result.Error)
+	}
+
+	return fmt.Sprintf("%s:%s", result.Network, result.TxHash), nil
+}
+
+// FileAnchor grava checkpoints assinados (HMAC-SHA256) em um arquivo local append-only
+type FileAnchor struct {
+	path string
+	key  []byte
+	mu   sync.Mutex
+}
+
+// NewFileAnchor cria uma âncora em arquivo local
+func NewFileAnchor(path string, signingKey string) *FileAnchor {
+	return &FileAnchor{
+		path: path,
+		key:  []byte(signingKey),
+	}
+}
+
+// signedCheckpoint linha do arquivo de checkpoints
+type signedCheckpoint struct {
+	CheckpointID       uint      `json:"checkpoint_id"`
+	FromLogID          uint      `json:"from_log_id"`
+	ToLogID            uint      `json:"to_log_id"`
+	EntryCount         int       `json:"entry_count"`
+	MerkleRoot         string    `json:"merkle_root"`
+	LastHash           string    `json:"last_hash"`
+	PrevCheckpointRoot string    `json:"prev_checkpoint_root"`
+	CreatedAt          time.Time `json:"created_at"`
+	Signature          string    `json:"signature"`
+}
+
+func (f *FileAnchor) Network() string {
+	return "local"
+}
+
+func (f *FileAnchor) Anchor(ctx context.Context, checkpoint *models.AuditCheckpoint) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry := signedCheckpoint{
+		CheckpointID:       checkpoint.ID,
+		FromLogID:          checkpoint.FromLogID,
+		ToLogID:            checkpoint.ToLogID,
+		EntryCount:         checkpoint.EntryCount,
+		MerkleRoot:         checkpoint.MerkleRoot,
+		LastHash:           checkpoint.LastHash,
+		PrevCheckpointRoot: checkpoint.PrevCheckpointRoot,
+		CreatedAt:          checkpoint.CreatedAt.UTC(),
+	}
+	entry.Signature = f.sign(&entry)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o750); err != nil {
+		return "", fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return "", fmt.Errorf("failed to open checkpoint file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return "", fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return "", fmt.Errorf("failed to sync checkpoint file: %w", err)
+	}
+
+	return fmt.Sprintf("file:%s#%d", f.path, checkpoint.ID), nil
+}
+
+// VerifyAnchor confere a assinatura da linha do checkpoint e se ela corresponde ao registro do banco
+func (f *FileAnchor) VerifyAnchor(ctx context.Context, checkpoint *models.AuditCheckpoint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("arquivo de checkpoints indisponível: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry signedCheckpoint
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.CheckpointID != checkpoint.ID {
+			continue
+		}
+
+		if !hmac.Equal([]byte(entry.Signature), []byte(f.sign(&entry))) {
+			return fmt.Errorf("assinatura do checkpoint %d inválida no arquivo", checkpoint.ID)
+		}
+		if entry.MerkleRoot != checkpoint.MerkleRoot || entry.LastHash != checkpoint.LastHash ||
+			entry.FromLogID != checkpoint.FromLogID || entry.ToLogID != checkpoint.ToLogID {
+			return fmt.Errorf("checkpoint %d no banco diverge do arquivo assinado", checkpoint.ID)
+		}
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("erro ao ler arquivo de checkpoints: %w", err)
+	}
+
+	return fmt.Errorf("checkpoint %d não encontrado no arquivo assinado", checkpoint.ID)
+}
+
+func (f *FileAnchor) sign(entry *signedCheckpoint) string {
+	payload := fmt.Sprintf("%d|%d|%d|%d|%s|%s|%s|%s",
+		entry.CheckpointID,
+		entry.FromLogID,
+		entry.ToLogID,
+		entry.EntryCount,
+		entry.MerkleRoot,
+		entry.LastHash,
+		entry.PrevCheckpointRoot,
+		entry.CreatedAt.Format(time.RFC3339Nano),
+	)
+	mac := hmac.New(sha256.New, f.key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// ignoredDiffFields são campos alterados automaticamente que não interessam à trilha de auditoria
+var ignoredDiffFields = map[string]bool{
+	"updated_at": true,
+}
+
+// FieldChange representa a alteração de um campo entre duas versões de uma entidade
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Diff compara duas versões de uma entidade (antes/depois) campo a campo, usando a
+// representação JSON de cada uma. Um dos lados pode ser nil para criação ou exclusão.
+func Diff(before, after interface{}) (map[string]FieldChange, error) {
+	beforeFields, err := toFieldMap(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFieldMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for key, value := range afterFields {
+		if ignoredDiffFields[key] {
+			continue
+		}
+		if old, ok := beforeFields[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = FieldChange{Before: beforeFields[key], After: value}
+		}
+	}
+	for key, value := range beforeFields {
+		if ignoredDiffFields[key] {
+			continue
+		}
+		if _, ok := afterFields[key]; !ok {
+			changes[key] = FieldChange{Before: value, After: nil}
+		}
+	}
+
+	return changes, nil
+}
+
+func toFieldMap(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil {
+		return fields, nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
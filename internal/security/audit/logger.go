@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/equinoid/backend/internal/models"
@@ -21,6 +22,9 @@ type AuditLogger struct {
 	riskLevels    map[string]string
 	anonymize     bool
 	retention     time.Duration
+	anchor        Anchor
+	chainMu       sync.Mutex
+	checkpointMu  sync.Mutex
 }
 
 // NewAuditLogger cria um novo logger de auditoria
@@ -43,6 +47,7 @@ func NewAuditLogger(db *gorm.DB, retention time.Duration) *AuditLogger {
 // AuditEvent representa um evento de auditoria
 type AuditEvent struct {
 	UserID     *uuid.UUID             `json:"user_id,omitempty"`
+	ActorID    *uint                  `json:"actor_id,omitempty"`
 	Action     string                 `json:"action"`
 	Resource   string                 `json:"resource"`
 	ResourceID *uuid.UUID             `json:"resource_id,omitempty"`
@@ -79,6 +84,7 @@ func (a *AuditLogger) LogEvent(ctx context.Context, event *AuditEvent) error {
 	// Criar registro de auditoria
 	auditLog := &models.AuditLog{
 		UserID:     event.UserID,
+		ActorID:    event.ActorID,
 		Action:     event.Action,
 		Resource:   event.Resource,
 		ResourceID: event.ResourceID,
@@ -93,8 +99,8 @@ func (a *AuditLogger) LogEvent(ctx context.Context, event *AuditEvent) error {
 		CreatedAt:  time.Now(),
 	}
 
-	// Salvar no banco de dados
-	if err := a.db.Create(auditLog).Error; err != nil {
+	// Salvar no banco de dados encadeado ao registro anterior
+	if err := a.appendToChain(ctx, auditLog); err != nil {
 		return fmt.Errorf("failed to save audit log: %w", err)
 	}
 
@@ -247,6 +253,83 @@ func (a *AuditLogger) LogComplianceRequest(ctx context.Context, userID uuid.UUID
 	return a.LogEvent(ctx, event)
 }
 
+// LogDelegatedAccess registra concessão, aceite, revogação e uso de acesso delegado a um equino
+func (a *AuditLogger) LogDelegatedAccess(ctx context.Context, actorID uint, action string, equinoid string, details map[string]interface{}) error {
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["equinoid"] = equinoid
+
+	event := &AuditEvent{
+		ActorID:   &actorID,
+		Action:    action,
+		Resource:  "equino",
+		Details:   details,
+		Success:   true,
+		Timestamp: time.Now(),
+	}
+
+	return a.LogEvent(ctx, event)
+}
+
+// LogChange registra a criação, alteração ou exclusão de uma entidade com o diff antes/depois
+func (a *AuditLogger) LogChange(ctx context.Context, resource, resourceKey, operation string, before, after interface{}) error {
+	action := "resource_updated"
+	switch {
+	case before == nil:
+		action = "resource_created"
+	case after == nil:
+		action = "resource_deleted"
+	}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		return fmt.Errorf("failed to diff %s: %w", resource, err)
+	}
+	if action == "resource_updated" && len(changes) == 0 {
+		return nil
+	}
+
+	event := &AuditEvent{
+		Action:   action,
+		Resource: resource,
+		Details: map[string]interface{}{
+			"resource_key": resourceKey,
+			"operation":    operation,
+			"changes":      changes,
+		},
+		Success:   true,
+		Timestamp: time.Now(),
+	}
+
+	return a.LogEvent(ctx, event)
+}
+
+// LogAPIRequest registra uma chamada de API que altera estado
+func (a *AuditLogger) LogAPIRequest(ctx context.Context, actorID *uint, method, route string, status int, details map[string]interface{}) error {
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["method"] = method
+	details["status"] = status
+
+	event := &AuditEvent{
+		ActorID:   actorID,
+		Action:    "api_mutation",
+		Resource:  route,
+		Details:   details,
+		Success:   status < http.StatusBadRequest,
+		Timestamp: time.Now(),
+	}
+	if status >= http.StatusInternalServerError {
+		event.RiskLevel = "high"
+	} else if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		event.RiskLevel = "medium"
+	}
+
+	return a.LogEvent(ctx, event)
+}
+
 // LogSecurityEvent registra evento de segurança
 func (a *AuditLogger) LogSecurityEvent(ctx context.Context, eventType, severity string, details map[string]interface{}) error {
 	event := &AuditEvent{
@@ -263,13 +346,38 @@ func (a *AuditLogger) LogSecurityEvent(ctx context.Context, eventType, severity
 
 // QueryLogs consulta logs de auditoria
 func (a *AuditLogger) QueryLogs(ctx context.Context, filter *AuditFilter) ([]models.AuditLog, error) {
-	query := a.db.Model(&models.AuditLog{})
+	query := a.applyFilter(a.db.WithContext(ctx).Model(&models.AuditLog{}), filter)
+
+	// Aplicar ordenação e limitação
+	query = query.Order("timestamp DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var logs []models.AuditLog
+	err := query.Preload("Actor").Find(&logs).Error
+	return logs, err
+}
+
+// CountLogs conta os logs de auditoria que atendem ao filtro, ignorando paginação
+func (a *AuditLogger) CountLogs(ctx context.Context, filter *AuditFilter) (int64, error) {
+	var total int64
+	err := a.applyFilter(a.db.WithContext(ctx).Model(&models.AuditLog{}), filter).Count(&total).Error
+	return total, err
+}
 
-	// Aplicar filtros
+func (a *AuditLogger) applyFilter(query *gorm.DB, filter *AuditFilter) *gorm.DB {
 	if filter.UserID != nil {
 		query = query.Where("user_id = ?", *filter.UserID)
 	}
 
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+
 	if filter.Action != "" {
 		query = query.Where("action = ?", filter.Action)
 	}
@@ -294,20 +402,13 @@ func (a *AuditLogger) QueryLogs(ctx context.Context, filter *AuditFilter) ([]mod
 		query = query.Where("success = ?", *filter.Success)
 	}
 
-	// Aplicar ordenação e limitação
-	query = query.Order("timestamp DESC")
-	if filter.Limit > 0 {
-		query = query.Limit(filter.Limit)
-	}
-
-	var logs []models.AuditLog
-	err := query.Preload("User").Find(&logs).Error
-	return logs, err
+	return query
 }
 
 // AuditFilter representa filtros para consulta de logs
 type AuditFilter struct {
 	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	ActorID   *uint      `json:"actor_id,omitempty"`
 	Action    string     `json:"action,omitempty"`
 	Resource  string     `json:"resource,omitempty"`
 	RiskLevel string     `json:"risk_level,omitempty"`
@@ -315,6 +416,7 @@ type AuditFilter struct {
 	EndDate   time.Time  `json:"end_date,omitempty"`
 	Success   *bool      `json:"success,omitempty"`
 	Limit     int        `json:"limit,omitempty"`
+	Offset    int        `json:"offset,omitempty"`
 }
 
 // GetAuditStats obtém estatísticas de auditoria
@@ -375,17 +477,27 @@ func (a *AuditLogger) GetAuditStats(ctx context.Context, period time.Duration) (
 	return stats, nil
 }
 
-// CleanupOldLogs limpa logs antigos baseado na retenção
-func (a *AuditLogger) CleanupOldLogs(ctx context.Context) error {
+// CleanupOldLogs remove definitivamente logs mais antigos que a retenção e retorna a quantidade removida.
+// A remoção só avança até o fim do último checkpoint inteiramente expirado, para que o primeiro registro
+// remanescente continue encadeado ao LastHash de um checkpoint ancorado.
+func (a *AuditLogger) CleanupOldLogs(ctx context.Context) (int64, error) {
 	cutoff := time.Now().Add(-a.retention)
+	db := a.db.WithContext(ctx)
 
-	result := a.db.Where("timestamp < ?", cutoff).Delete(&models.AuditLog{})
+	var checkpoint models.AuditCheckpoint
+	if err := db.Where("last_timestamp < ?", cutoff).Order("to_log_id DESC").Limit(1).Find(&checkpoint).Error; err != nil {
+		return 0, fmt.Errorf("failed to load audit checkpoint: %w", err)
+	}
+	if checkpoint.ID == 0 {
+		return 0, nil
+	}
+
+	result := db.Unscoped().Where("id <= ?", checkpoint.ToLogID).Delete(&models.AuditLog{})
 	if result.Error != nil {
-		return fmt.Errorf("failed to cleanup old logs: %w", result.Error)
+		return 0, fmt.Errorf("failed to cleanup old logs: %w", result.Error)
 	}
 
-	fmt.Printf("Cleaned up %d old audit logs\n", result.RowsAffected)
-	return nil
+	return result.RowsAffected, nil
 }
 
 // Funções auxiliares
@@ -398,6 +510,11 @@ func (a *AuditLogger) setupDefaultEvents() {
 		"certificate_issued", "certificate_revoked",
 		"digital_signature", "data_access", "data_deletion",
 		"compliance_request", "security_event",
+		"delegated_access_invited", "delegated_access_accepted",
+		"delegated_access_declined", "delegated_access_revoked",
+		"delegated_access_used",
+		"resource_created", "resource_updated", "resource_deleted",
+		"api_mutation",
 	}
 
 	for _, event := range events {
@@ -421,6 +538,15 @@ func (a *AuditLogger) setupRiskLevels() {
 	a.riskLevels["data_access"] = "low"
 	a.riskLevels["data_deletion"] = "critical"
 	a.riskLevels["compliance_request"] = "medium"
+	a.riskLevels["delegated_access_invited"] = "medium"
+	a.riskLevels["delegated_access_accepted"] = "medium"
+	a.riskLevels["delegated_access_declined"] = "low"
+	a.riskLevels["delegated_access_revoked"] = "high"
+	a.riskLevels["delegated_access_used"] = "low"
+	a.riskLevels["resource_created"] = "low"
+	a.riskLevels["resource_updated"] = "medium"
+	a.riskLevels["resource_deleted"] = "high"
+	a.riskLevels["api_mutation"] = "low"
 }
 
 func (a *AuditLogger) isEventEnabled(action string) bool {
@@ -486,6 +612,13 @@ func (a *AuditLogger) enrichEvent(ctx context.Context, event *AuditEvent) *Audit
 		}
 	}
 
+	// Usar o usuário autenticado da requisição como autor, quando não informado
+	if event.ActorID == nil {
+		if actorID, ok := ctx.Value("user_id").(uint); ok && actorID != 0 {
+			event.ActorID = &actorID
+		}
+	}
+
 	// Definir timestamp se não fornecido
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now()
@@ -566,17 +699,22 @@ func (a *AuditLogger) ExportLogs(ctx context.Context, filter *AuditFilter, forma
 }
 
 func (a *AuditLogger) exportLogsCSV(logs []models.AuditLog) []byte {
-	csv := "Timestamp,User ID,Action,Resource,Success,Risk Level,IP Address\n"
+	csv := "Timestamp,User ID,Actor ID,Action,Resource,Success,Risk Level,IP Address\n"
 
 	for _, log := range logs {
 		userID := ""
 		if log.UserID != nil {
 			userID = log.UserID.String()
 		}
+		actorID := ""
+		if log.ActorID != nil {
+			actorID = fmt.Sprintf("%d", *log.ActorID)
+		}
 
-		line := fmt.Sprintf("%s,%s,%s,%s,%t,%s,%s\n",
+		line := fmt.Sprintf("%s,%s,%s,%s,%s,%t,%s,%s\n",
 			log.Timestamp.Format(time.RFC3339),
 			userID,
+			actorID,
 			log.Action,
 			log.Resource,
 			log.Success,
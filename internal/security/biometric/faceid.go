@@ -15,7 +15,6 @@ import (
 
 	"github.com/equinoid/backend/internal/models"
 	"github.com/equinoid/backend/internal/security/crypto"
-	"github.com/google/uuid"
 )
 
 // FaceIDService gerencia autenticação biométrica facial
@@ -66,17 +65,17 @@ type Rectangle struct {
 
 // EnrollmentData representa dados de cadastro biométrico
 type EnrollmentData struct {
-	UserID        uuid.UUID `json:"user_id"`
-	BiometricType string    `json:"biometric_type"`
-	ImageData     []byte    `json:"image_data"`
-	Quality       float64   `json:"quality"`
+	UserID        uint    `json:"user_id"`
+	BiometricType string  `json:"biometric_type"`
+	ImageData     []byte  `json:"image_data"`
+	Quality       float64 `json:"quality"`
 }
 
 // VerificationRequest representa uma solicitação de verificação
 type VerificationRequest struct {
-	UserID    uuid.UUID `json:"user_id"`
-	ImageData []byte    `json:"image_data"`
-	Challenge string    `json:"challenge,omitempty"`
+	UserID    uint   `json:"user_id"`
+	ImageData []byte `json:"image_data"`
+	Challenge string `json:"challenge,omitempty"`
 }
 
 // VerificationResponse representa o resultado da verificação
@@ -5,6 +5,7 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
+	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -147,7 +148,7 @@ func (e *EthereumService) StoreSignature(signature *models.DigitalSignature) (*T
 		RecordID:   fmt.Sprintf("%d", signature.ID),
 		DataHash:   signature.SignatureHash,
 		Metadata: map[string]interface{}{
-			"user_id":        signature.UserID.String(),
+			"user_id":        strconv.FormatUint(uint64(signature.UserID), 10),
 			"document_id":    signature.DocumentID.String(),
 			"certificate_id": fmt.Sprintf("%d", signature.CertificateID),
 			"document_hash":  signature.DocumentHash,
@@ -3,6 +3,7 @@ package blockchain
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/equinoid/backend/internal/models"
@@ -142,7 +143,7 @@ func (h *HyperledgerService) StoreSignature(signature *models.DigitalSignature)
 		ID:   fmt.Sprintf("%d", signature.ID),
 		Type: "signature",
 		Data: map[string]interface{}{
-			"user_id":        signature.UserID.String(),
+			"user_id":        strconv.FormatUint(uint64(signature.UserID), 10),
 			"document_id":    signature.DocumentID.String(),
 			"certificate_id": fmt.Sprintf("%d", signature.CertificateID),
 			"signature_hash": signature.SignatureHash,
@@ -0,0 +1,424 @@
+package compliance
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ComplianceTypeConsent tipo dos registros de consentimento em compliance_records
+const ComplianceTypeConsent = "consent"
+
+// Status dos registros de consentimento
+const (
+	ConsentStatusApproved   = "approved"
+	ConsentStatusWithdrawn  = "withdrawn"
+	ConsentStatusExpired    = "expired"
+	ConsentStatusSuperseded = "superseded"
+)
+
+var (
+	ErrInvalidPurpose  = errors.New("invalid consent purpose")
+	ErrPolicyNotFound  = errors.New("consent policy not found")
+	ErrPolicyOutdated  = errors.New("consent policy version is not the current one")
+	ErrConsentNotFound = errors.New("no active consent for this purpose")
+)
+
+// ConsentHook desativa as funcionalidades que dependem de um consentimento que deixou de valer
+type ConsentHook func(ctx context.Context, userID uint) error
+
+// defaultPolicies texto inicial (versão 1) de cada finalidade
+var defaultPolicies = map[string]struct{ titulo, texto string }{
+	models.FinalidadePerfilSocialPublico: {
+		titulo: "Perfil social público dos equinos",
+		texto:  "Autorizo a exibição pública dos perfis sociais dos meus equinos, incluindo nome, fotos, localização e publicações, para qualquer visitante da plataforma. Ao revogar, os perfis passam a ser privados.",
+	},
+	models.FinalidadeCompartilhamentoTerceiros: {
+		titulo: "Compartilhamento com laboratórios, seguradoras e integrações",
+		texto:  "Autorizo o compartilhamento dos dados dos meus equinos e dos meus dados cadastrais com laboratórios, seguradoras e sistemas de terceiros que eu cadastrar (webhooks), exclusivamente para a prestação dos serviços solicitados. Ao revogar, as integrações e os acessos de laboratórios são interrompidos.",
+	},
+	models.FinalidadeKYCTokenizacao: {
+		titulo: "Verificação de identidade para tokenização",
+		texto:  "Autorizo o tratamento dos meus dados de identificação para verificação de identidade (KYC) e prevenção à lavagem de dinheiro nas operações de tokenização. Sem esta autorização não é possível emitir, negociar ou ofertar tokens.",
+	},
+}
+
+// OnConsentWithdrawn registra uma ação executada quando o consentimento de uma finalidade é revogado ou deixa de valer.
+// Deve ser chamado na inicialização, antes de o serviço atender requisições.
+func (l *LGPDService) OnConsentWithdrawn(purpose string, hook ConsentHook) {
+	if l.consentHooks == nil {
+		l.consentHooks = make(map[string][]ConsentHook)
+	}
+	l.consentHooks[purpose] = append(l.consentHooks[purpose], hook)
+}
+
+// EnsureDefaultPolicies publica a versão inicial da política das finalidades que ainda não possuem texto
+func (l *LGPDService) EnsureDefaultPolicies(ctx context.Context) error {
+	for _, purpose := range models.FinalidadesConsentimento {
+		var count int64
+		if err := l.db.WithContext(ctx).Model(&models.PoliticaConsentimento{}).
+			Where("finalidade = ?", purpose).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check consent policy: %w", err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		def := defaultPolicies[purpose]
+		policy := &models.PoliticaConsentimento{
+			Finalidade:   purpose,
+			Versao:       1,
+			Titulo:       def.titulo,
+			Texto:        def.texto,
+			TextoHash:    policyHash(def.texto),
+			VigenteDesde: time.Now(),
+		}
+		if err := l.db.WithContext(ctx).Create(policy).Error; err != nil {
+			return fmt.Errorf("failed to create default consent policy: %w", err)
+		}
+	}
+	return nil
+}
+
+// CurrentPolicy retorna a versão vigente da política de uma finalidade
+func (l *LGPDService) CurrentPolicy(ctx context.Context, purpose string) (*models.PoliticaConsentimento, error) {
+	if !models.IsValidFinalidade(purpose) {
+		return nil, ErrInvalidPurpose
+	}
+
+	var policy models.PoliticaConsentimento
+	if err := l.db.WithContext(ctx).
+		Where("finalidade = ?", purpose).
+		Order("versao DESC").
+		First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to load consent policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// ListPolicies lista as versões publicadas, da mais recente para a mais antiga; purpose vazio retorna todas as finalidades
+func (l *LGPDService) ListPolicies(ctx context.Context, purpose string) ([]models.PoliticaConsentimento, error) {
+	query := l.db.WithContext(ctx).Model(&models.PoliticaConsentimento{})
+	if purpose != "" {
+		if !models.IsValidFinalidade(purpose) {
+			return nil, ErrInvalidPurpose
+		}
+		query = query.Where("finalidade = ?", purpose)
+	}
+
+	var policies []models.PoliticaConsentimento
+	if err := query.Order("finalidade ASC, versao DESC").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to list consent policies: %w", err)
+	}
+	return policies, nil
+}
+
+// PublishPolicy publica uma nova versão do texto de uma finalidade. Quando a versão exige novo aceite, os
+// consentimentos dados a versões anteriores deixam de valer imediatamente e as funcionalidades dependentes são desativadas.
+// Falhas ao desativar funcionalidades são retornadas junto com a política já publicada.
+func (l *LGPDService) PublishPolicy(ctx context.Context, req *models.PublicarPoliticaRequest, publishedByID uint) (*models.PoliticaConsentimento, error) {
+	current, err := l.CurrentPolicy(ctx, req.Finalidade)
+	if err != nil && !errors.Is(err, ErrPolicyNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	policy := &models.PoliticaConsentimento{
+		Finalidade:      req.Finalidade,
+		Versao:          1,
+		Titulo:          req.Titulo,
+		Texto:           req.Texto,
+		TextoHash:       policyHash(req.Texto),
+		ExigeNovoAceite: req.ExigeNovoAceite,
+		PublicadaPorID:  &publishedByID,
+		VigenteDesde:    now,
+	}
+	if current != nil {
+		policy.Versao = current.Versao + 1
+	}
+
+	var outdated []models.ComplianceRecord
+	err = l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(policy).Error; err != nil {
+			return fmt.Errorf("failed to create consent policy: %w", err)
+		}
+		if !policy.ExigeNovoAceite {
+			return nil
+		}
+
+		if err := tx.Where("compliance_type = ? AND request_type = ? AND status = ? AND policy_version < ?",
+			ComplianceTypeConsent, policy.Finalidade, ConsentStatusApproved, policy.Versao).
+			Find(&outdated).Error; err != nil {
+			return fmt.Errorf("failed to load outdated consents: %w", err)
+		}
+		if len(outdated) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.ComplianceRecord{}).
+			Where("compliance_type = ? AND request_type = ? AND status = ? AND policy_version < ?",
+				ComplianceTypeConsent, policy.Finalidade, ConsentStatusApproved, policy.Versao).
+			Updates(map[string]interface{}{
+				"status":     ConsentStatusExpired,
+				"notes":      fmt.Sprintf("versão %d da política exige novo aceite", policy.Versao),
+				"updated_at": now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, record := range outdated {
+		errs = append(errs, l.runConsentHooks(ctx, record.UserID, record.RequestType))
+	}
+	return policy, errors.Join(errs...)
+}
+
+// GrantConsent registra o aceite do titular à versão vigente da política; aceites anteriores da mesma finalidade
+// ficam no histórico como substituídos
+func (l *LGPDService) GrantConsent(ctx context.Context, userID uint, purpose string, version int, metadata map[string]interface{}) (*models.ComplianceRecord, error) {
+	if err := l.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	policy, err := l.CurrentPolicy(ctx, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if version != policy.Versao {
+		return nil, ErrPolicyOutdated
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(l.dataRetention)
+	record := &models.ComplianceRecord{
+		UserID:         userID,
+		ComplianceType: ComplianceTypeConsent,
+		RequestType:    purpose,
+		Status:         ConsentStatusApproved,
+		RequestData: map[string]interface{}{
+			"policy_id":    policy.ID,
+			"policy_title": policy.Titulo,
+			"policy_hash":  policy.TextoHash,
+			"metadata":     metadata,
+		},
+		LegalBasis:    string(LegalBasisConsent),
+		PolicyVersion: policy.Versao,
+		ProcessedAt:   &now,
+		ProcessedByID: &userID,
+		ExpiresAt:     &expiresAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	err = l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ComplianceRecord{}).
+			Where("user_id = ? AND compliance_type = ? AND request_type = ? AND status = ?",
+				userID, ComplianceTypeConsent, purpose, ConsentStatusApproved).
+			Updates(map[string]interface{}{
+				"status":     ConsentStatusSuperseded,
+				"updated_at": now,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to supersede previous consent: %w", err)
+		}
+		if err := tx.Create(record).Error; err != nil {
+			return fmt.Errorf("failed to create consent record: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// WithdrawConsent revoga o consentimento ativo de uma finalidade e desativa imediatamente as funcionalidades
+// que dependem dele. Falhas ao desativar funcionalidades são retornadas junto com o registro já revogado.
+func (l *LGPDService) WithdrawConsent(ctx context.Context, userID uint, purpose, reason string, metadata map[string]interface{}) (*models.ComplianceRecord, error) {
+	if !models.IsValidFinalidade(purpose) {
+		return nil, ErrInvalidPurpose
+	}
+
+	record, err := l.activeConsent(ctx, userID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrConsentNotFound
+	}
+
+	now := time.Now()
+	record.Status = ConsentStatusWithdrawn
+	record.Notes = reason
+	if record.RequestData == nil {
+		record.RequestData = make(map[string]interface{})
+	}
+	record.RequestData["withdrawn_at"] = now
+	record.RequestData["withdrawal_metadata"] = metadata
+	record.UpdatedAt = now
+
+	if err := l.db.WithContext(ctx).Save(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to withdraw consent: %w", err)
+	}
+
+	return record, l.runConsentHooks(ctx, userID, purpose)
+}
+
+// HasConsent indica se o titular possui consentimento válido para a finalidade
+func (l *LGPDService) HasConsent(ctx context.Context, userID uint, purpose string) (bool, error) {
+	if !l.consentRequired {
+		return true, nil
+	}
+	record, err := l.activeConsent(ctx, userID, purpose)
+	if err != nil {
+		return false, err
+	}
+	return record != nil, nil
+}
+
+// RequireConsent retorna erro de autorização quando o titular não possui consentimento válido para a finalidade.
+// Deve ser chamado antes de qualquer tratamento que dependa de consentimento.
+func (l *LGPDService) RequireConsent(ctx context.Context, userID uint, purpose string) error {
+	ok, err := l.HasConsent(ctx, userID, purpose)
+	if err != nil {
+		return apperrors.NewDatabaseError("require_consent", "erro ao verificar consentimento", err)
+	}
+	if !ok {
+		return &apperrors.AuthorizationError{
+			Message: fmt.Sprintf("consentimento para '%s' não concedido ou desatualizado", purpose),
+		}
+	}
+	return nil
+}
+
+// ConsentStatus situação de cada finalidade para o titular
+func (l *LGPDService) ConsentStatus(ctx context.Context, userID uint) ([]models.ConsentimentoStatus, error) {
+	var active []models.ComplianceRecord
+	if err := l.db.WithContext(ctx).
+		Where("user_id = ? AND compliance_type = ? AND status = ?", userID, ComplianceTypeConsent, ConsentStatusApproved).
+		Find(&active).Error; err != nil {
+		return nil, fmt.Errorf("failed to load consents: %w", err)
+	}
+	byPurpose := make(map[string]models.ComplianceRecord, len(active))
+	for _, record := range active {
+		byPurpose[record.RequestType] = record
+	}
+
+	now := time.Now()
+	statuses := make([]models.ConsentimentoStatus, 0, len(models.FinalidadesConsentimento))
+	for _, purpose := range models.FinalidadesConsentimento {
+		status := models.ConsentimentoStatus{Finalidade: purpose}
+
+		policy, err := l.CurrentPolicy(ctx, purpose)
+		if err != nil && !errors.Is(err, ErrPolicyNotFound) {
+			return nil, err
+		}
+		if policy != nil {
+			status.VersaoVigente = policy.Versao
+		}
+
+		if record, ok := byPurpose[purpose]; ok && (record.ExpiresAt == nil || record.ExpiresAt.After(now)) {
+			status.Concedido = true
+			status.VersaoAceita = record.PolicyVersion
+			status.ConcedidoEm = record.ProcessedAt
+			status.ValidoAte = record.ExpiresAt
+		}
+		status.NovoAceiteNeeded = !status.Concedido || status.VersaoAceita < status.VersaoVigente
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// ConsentHistory histórico de aceites e revogações do titular, do mais recente para o mais antigo
+func (l *LGPDService) ConsentHistory(ctx context.Context, userID uint, purpose string, limit, offset int) ([]models.ComplianceRecord, int64, error) {
+	query := l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
+		Where("user_id = ? AND compliance_type = ?", userID, ComplianceTypeConsent)
+	if purpose != "" {
+		query = query.Where("request_type = ?", purpose)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count consent history: %w", err)
+	}
+
+	var records []models.ComplianceRecord
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&records).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load consent history: %w", err)
+	}
+	return records, total, nil
+}
+
+// CleanupExpiredConsents marca como expirados os consentimentos vencidos e desativa as funcionalidades dependentes
+func (l *LGPDService) CleanupExpiredConsents(ctx context.Context) (int64, error) {
+	var expired []models.ComplianceRecord
+	if err := l.db.WithContext(ctx).
+		Where("compliance_type = ? AND status = ? AND expires_at < ?", ComplianceTypeConsent, ConsentStatusApproved, time.Now()).
+		Find(&expired).Error; err != nil {
+		return 0, fmt.Errorf("failed to load expired consents: %w", err)
+	}
+
+	var errs []error
+	var count int64
+	for _, record := range expired {
+		result := l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
+			Where("id = ? AND status = ?", record.ID, ConsentStatusApproved).
+			Updates(map[string]interface{}{
+				"status":     ConsentStatusExpired,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			errs = append(errs, fmt.Errorf("failed to expire consent %d: %w", record.ID, result.Error))
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		count++
+		errs = append(errs, l.runConsentHooks(ctx, record.UserID, record.RequestType))
+	}
+	return count, errors.Join(errs...)
+}
+
+func (l *LGPDService) activeConsent(ctx context.Context, userID uint, purpose string) (*models.ComplianceRecord, error) {
+	var record models.ComplianceRecord
+	err := l.db.WithContext(ctx).
+		Where("user_id = ? AND compliance_type = ? AND request_type = ? AND status = ?",
+			userID, ComplianceTypeConsent, purpose, ConsentStatusApproved).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").
+		First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load consent: %w", err)
+	}
+	return &record, nil
+}
+
+func (l *LGPDService) runConsentHooks(ctx context.Context, userID uint, purpose string) error {
+	var errs []error
+	for _, hook := range l.consentHooks[purpose] {
+		if err := hook(ctx, userID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", purpose, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func policyHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -66,7 +66,7 @@ const (
 
 // GDPRRequest representa solicitação GDPR
 type GDPRRequest struct {
-	UserID         uuid.UUID              `json:"user_id"`
+	UserID         uint                   `json:"user_id"`
 	RequestType    string                 `json:"request_type"` // access, rectification, erasure, portability, restriction, objection
 	LegalBasis     LegalBasis             `json:"legal_basis"`
 	Purpose        ProcessingPurpose      `json:"purpose"`
@@ -420,7 +420,7 @@ type DataBreach struct {
 
 // Funções auxiliares
 
-func (g *GDPRService) collectPersonalData(userID uuid.UUID, categories []DataCategory) (map[string]interface{}, error) {
+func (g *GDPRService) collectPersonalData(userID uint, categories []DataCategory) (map[string]interface{}, error) {
 	data := make(map[string]interface{})
 
 	// Dados básicos do usuário
@@ -543,7 +543,7 @@ func (g *GDPRService) isPortabilityApplicable(legalBasis LegalBasis) bool {
 	return legalBasis == LegalBasisConsent || legalBasis == LegalBasisContract
 }
 
-func (g *GDPRService) processDataErasure(userID uuid.UUID, categories []DataCategory) (map[string]interface{}, error) {
+func (g *GDPRService) processDataErasure(userID uint, categories []DataCategory) (map[string]interface{}, error) {
 	report := make(map[string]interface{})
 
 	for _, category := range categories {
@@ -583,7 +583,7 @@ func (g *GDPRService) processDataErasure(userID uuid.UUID, categories []DataCate
 	return report, nil
 }
 
-func (g *GDPRService) applyDataCorrections(userID uuid.UUID, corrections interface{}) error {
+func (g *GDPRService) applyDataCorrections(userID uint, corrections interface{}) error {
 	// Aplicar correções solicitadas pelo usuário
 	if corrMap, ok := corrections.(map[string]interface{}); ok {
 		return g.db.Model(&models.User{}).Where("id = ?", userID).Updates(corrMap).Error
@@ -591,7 +591,7 @@ func (g *GDPRService) applyDataCorrections(userID uuid.UUID, corrections interfa
 	return fmt.Errorf("invalid corrections format")
 }
 
-func (g *GDPRService) createStructuredExport(userID uuid.UUID, categories []DataCategory, metadata map[string]interface{}) (string, error) {
+func (g *GDPRService) createStructuredExport(userID uint, categories []DataCategory, metadata map[string]interface{}) (string, error) {
 	// Criar exportação estruturada dos dados
 	exportID := uuid.New().String()
 	exportURL := fmt.Sprintf("/api/gdpr/exports/%s", exportID)
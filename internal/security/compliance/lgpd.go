@@ -2,19 +2,51 @@ package compliance
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/equinoid/backend/internal/models"
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Tipos de solicitação do titular (LGPD Art. 18)
+const (
+	RequestTypeAccess      = "data_access"
+	RequestTypePortability = "data_portability"
+	RequestTypeDeletion    = "data_deletion"
+)
+
+// Status das solicitações do titular
+const (
+	RequestStatusPending    = "pending"
+	RequestStatusProcessing = "processing"
+	RequestStatusCompleted  = "completed"
+	RequestStatusRejected   = "rejected"
+	RequestStatusFailed     = "failed"
+)
+
+// ResponseDeadline prazo para atender o titular (LGPD Art. 19, II)
+const ResponseDeadline = 15 * 24 * time.Hour
+
+// SubjectRequestTypes tipos de solicitação expostos ao titular
+var SubjectRequestTypes = []string{RequestTypeAccess, RequestTypePortability, RequestTypeDeletion}
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrRequestNotFound   = errors.New("request not found")
+	ErrRequestOpen       = errors.New("there is already an open request of this type")
+	ErrRequestProcessed  = errors.New("request already processed")
+	ErrExportUnavailable = errors.New("export not available")
+)
+
 // LGPDService gerencia compliance com a Lei Geral de Proteção de Dados
 type LGPDService struct {
 	db              *gorm.DB
 	dataRetention   time.Duration
 	consentRequired bool
+	exports         *exportStore
+	consentHooks    map[string][]ConsentHook
 }
 
 // NewLGPDService cria um novo serviço LGPD
@@ -26,369 +58,209 @@ func NewLGPDService(db *gorm.DB, dataRetention time.Duration, consentRequired bo
 	}
 }
 
-// ConsentRequest representa uma solicitação de consentimento
-type ConsentRequest struct {
-	UserID      uuid.UUID              `json:"user_id"`
-	ConsentType string                 `json:"consent_type"` // data_processing, marketing, analytics
-	Purpose     string                 `json:"purpose"`
-	DataTypes   []string               `json:"data_types"`
-	Retention   time.Duration          `json:"retention"`
-	LegalBasis  string                 `json:"legal_basis"`
-	Metadata    map[string]interface{} `json:"metadata"`
-}
-
-// ConsentResponse representa resposta de consentimento
-type ConsentResponse struct {
-	ConsentID   uuid.UUID `json:"consent_id"`
-	Status      string    `json:"status"`
-	Message     string    `json:"message"`
-	ValidUntil  time.Time `json:"valid_until"`
-	CanWithdraw bool      `json:"can_withdraw"`
+// SetExportStorage define onde os pacotes de exportação são gravados, a chave que assina o manifesto e por quanto tempo ficam disponíveis
+func (l *LGPDService) SetExportStorage(dir, signingKey string, ttl time.Duration) {
+	l.exports = &exportStore{
+		dir: dir,
+		key: []byte(signingKey),
+		ttl: ttl,
+	}
 }
 
 // DataRequest representa solicitação de dados (acesso, portabilidade, exclusão)
 type DataRequest struct {
-	UserID      uuid.UUID              `json:"user_id"`
-	RequestType string                 `json:"request_type"` // access, portability, deletion, rectification
+	UserID      uint                   `json:"user_id"`
+	RequestType string                 `json:"request_type"` // access, portability, deletion
 	DataTypes   []string               `json:"data_types"`
 	Reason      string                 `json:"reason"`
+	Format      string                 `json:"format"` // zip ou json (portabilidade)
 	Metadata    map[string]interface{} `json:"metadata"`
 }
 
 // DataResponse representa resposta de solicitação de dados
 type DataResponse struct {
-	RequestID   uuid.UUID              `json:"request_id"`
-	Status      string                 `json:"status"`
-	Message     string                 `json:"message"`
-	Data        map[string]interface{} `json:"data,omitempty"`
-	ExportURL   string                 `json:"export_url,omitempty"`
-	CompletedAt *time.Time             `json:"completed_at,omitempty"`
-	ExpiresAt   time.Time              `json:"expires_at"`
+	RequestID   uint       `json:"request_id"`
+	Status      string     `json:"status"`
+	Message     string     `json:"message"`
+	DueAt       time.Time  `json:"due_at"`
+	ExportURL   string     `json:"export_url,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
 }
 
-// RequestConsent solicita consentimento do usuário
-func (l *LGPDService) RequestConsent(ctx context.Context, req *ConsentRequest) (*ConsentResponse, error) {
-	// Verificar se usuário existe
-	var user models.User
-	if err := l.db.First(&user, req.UserID).Error; err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
-	}
-
-	// Verificar se já existe consentimento ativo para este tipo
-	var existingConsent models.ComplianceRecord
-	err := l.db.Where("user_id = ? AND compliance_type = ? AND request_type = ? AND status = ?",
-		req.UserID, "consent", req.ConsentType, "approved").First(&existingConsent).Error
-
-	if err == nil {
-		return &ConsentResponse{
-			ConsentID:   uuid.New(), // Gerar UUID temporário já que ID é uint
-			Status:      "already_granted",
-			Message:     "Consent already granted for this purpose",
-			ValidUntil:  *existingConsent.ExpiresAt,
-			CanWithdraw: true,
-		}, nil
-	}
-
-	// Criar novo registro de consentimento
-	requestData := map[string]interface{}{
-		"consent_type": req.ConsentType,
-		"purpose":      req.Purpose,
-		"data_types":   req.DataTypes,
-		"legal_basis":  req.LegalBasis,
-		"metadata":     req.Metadata,
-	}
-
-	complianceRecord := &models.ComplianceRecord{
-		UserID:         req.UserID,
-		ComplianceType: "consent",
-		RequestType:    req.ConsentType,
-		Status:         "pending",
-		RequestData:    requestData,
-		LegalBasis:     req.LegalBasis,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-	}
-
-	// Definir expiração do consentimento
-	if req.Retention > 0 {
-		expiresAt := time.Now().Add(req.Retention)
-		complianceRecord.ExpiresAt = &expiresAt
-	} else {
-		// Usar retenção padrão
-		expiresAt := time.Now().Add(l.dataRetention)
-		complianceRecord.ExpiresAt = &expiresAt
-	}
-
-	// Salvar no banco
-	if err := l.db.Create(complianceRecord).Error; err != nil {
-		return nil, fmt.Errorf("failed to create consent record: %w", err)
-	}
-
-	return &ConsentResponse{
-		ConsentID:   uuid.New(), // Gerar UUID temporário já que ID é uint
-		Status:      "pending",
-		Message:     "Consent request created successfully",
-		ValidUntil:  *complianceRecord.ExpiresAt,
-		CanWithdraw: true,
-	}, nil
+// RequestFilter filtros da fila de solicitações de titulares
+type RequestFilter struct {
+	UserID      *uint
+	RequestType string
+	Status      string
+	OverdueOnly bool
+	Limit       int
+	Offset      int
 }
 
-// GrantConsent aprova um consentimento
-func (l *LGPDService) GrantConsent(ctx context.Context, consentID uuid.UUID, userID uuid.UUID) (*ConsentResponse, error) {
-	var record models.ComplianceRecord
-	err := l.db.Where("id = ? AND user_id = ? AND compliance_type = ?",
-		consentID, userID, "consent").First(&record).Error
-
+// RequestDataAccess solicita acesso aos dados pessoais; o pacote JSON assinado é gerado em segundo plano
+func (l *LGPDService) RequestDataAccess(ctx context.Context, req *DataRequest) (*DataResponse, error) {
+	record, err := l.createSubjectRequest(ctx, RequestTypeAccess, req, ExportFormatJSON)
 	if err != nil {
-		return nil, fmt.Errorf("consent record not found: %w", err)
-	}
-
-	if record.Status != "pending" {
-		return nil, fmt.Errorf("consent already processed")
-	}
-
-	// Atualizar status
-	record.Status = "approved"
-	if record.RequestData == nil {
-		record.RequestData = make(map[string]interface{})
+		return nil, err
 	}
-	record.RequestData["processed_at"] = time.Now()
-	record.RequestData["processed_by"] = userID.String()
-	record.UpdatedAt = time.Now()
 
-	if err := l.db.Save(&record).Error; err != nil {
-		return nil, fmt.Errorf("failed to grant consent: %w", err)
-	}
+	go l.processExportRequest(record.ID)
 
-	return &ConsentResponse{
-		ConsentID:   uuid.New(), // Gerar UUID temporário já que ID é uint
-		Status:      "approved",
-		Message:     "Consent granted successfully",
-		ValidUntil:  *record.ExpiresAt,
-		CanWithdraw: true,
+	return &DataResponse{
+		RequestID: record.ID,
+		Status:    record.Status,
+		Message:   "Data access request submitted successfully",
+		DueAt:     *record.DueAt,
 	}, nil
 }
 
-// WithdrawConsent revoga um consentimento
-func (l *LGPDService) WithdrawConsent(ctx context.Context, consentID uuid.UUID, userID uuid.UUID, reason string) error {
-	var record models.ComplianceRecord
-	err := l.db.Where("id = ? AND user_id = ? AND compliance_type = ?",
-		consentID, userID, "consent").First(&record).Error
-
-	if err != nil {
-		return fmt.Errorf("consent record not found: %w", err)
-	}
-
-	// Atualizar status
-	record.Status = "withdrawn"
-	if record.RequestData == nil {
-		record.RequestData = make(map[string]interface{})
-	}
-	record.RequestData["processed_at"] = time.Now()
-	record.RequestData["notes"] = reason
-	record.UpdatedAt = time.Now()
-
-	return l.db.Save(&record).Error
-}
-
-// RequestDataAccess solicita acesso aos dados pessoais
-func (l *LGPDService) RequestDataAccess(ctx context.Context, req *DataRequest) (*DataResponse, error) {
-	// Verificar se usuário existe
-	var user models.User
-	if err := l.db.First(&user, req.UserID).Error; err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
-	}
-
-	// Criar registro de solicitação
-	requestData := map[string]interface{}{
-		"data_types": req.DataTypes,
-		"reason":     req.Reason,
-		"metadata":   req.Metadata,
+// RequestDataPortability solicita portabilidade dos dados; o pacote ZIP (ou JSON) assinado é gerado em segundo plano
+func (l *LGPDService) RequestDataPortability(ctx context.Context, req *DataRequest) (*DataResponse, error) {
+	format := req.Format
+	if format == "" {
+		format = ExportFormatZIP
 	}
-
-	complianceRecord := &models.ComplianceRecord{
-		UserID:         req.UserID,
-		ComplianceType: "data_access",
-		RequestType:    req.RequestType,
-		Status:         "pending",
-		RequestData:    requestData,
-		LegalBasis:     "data_subject_right",
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+	if format != ExportFormatZIP && format != ExportFormatJSON {
+		return nil, fmt.Errorf("unsupported export format %q", format)
 	}
 
-	// Definir expiração da solicitação (30 dias para processar)
-	expiresAt := time.Now().Add(30 * 24 * time.Hour)
-	complianceRecord.ExpiresAt = &expiresAt
-
-	if err := l.db.Create(complianceRecord).Error; err != nil {
-		return nil, fmt.Errorf("failed to create data access request: %w", err)
+	record, err := l.createSubjectRequest(ctx, RequestTypePortability, req, format)
+	if err != nil {
+		return nil, err
 	}
 
-	// Processar solicitação automaticamente (converter uint para uuid temporário)
-	go l.processDataAccessRequest(uuid.New())
+	go l.processExportRequest(record.ID)
 
 	return &DataResponse{
-		RequestID: uuid.New(), // Gerar UUID temporário já que ID é uint
-		Status:    "pending",
-		Message:   "Data access request submitted successfully",
-		ExpiresAt: expiresAt,
+		RequestID: record.ID,
+		Status:    record.Status,
+		Message:   "Data portability request submitted successfully",
+		DueAt:     *record.DueAt,
 	}, nil
 }
 
-// RequestDataDeletion solicita exclusão de dados pessoais
+// RequestDataDeletion solicita exclusão de dados pessoais; a anonimização aguarda análise na fila administrativa
 func (l *LGPDService) RequestDataDeletion(ctx context.Context, req *DataRequest) (*DataResponse, error) {
-	// Verificar se usuário existe
-	var user models.User
-	if err := l.db.First(&user, req.UserID).Error; err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
-	}
-
-	// Criar registro de solicitação
-	requestData := map[string]interface{}{
-		"data_types": req.DataTypes,
-		"reason":     req.Reason,
-		"metadata":   req.Metadata,
-	}
-
-	complianceRecord := &models.ComplianceRecord{
-		UserID:         req.UserID,
-		ComplianceType: "data_deletion",
-		RequestType:    req.RequestType,
-		Status:         "pending",
-		RequestData:    requestData,
-		LegalBasis:     "data_subject_right",
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-	}
-
-	// Definir expiração da solicitação (30 dias para processar)
-	expiresAt := time.Now().Add(30 * 24 * time.Hour)
-	complianceRecord.ExpiresAt = &expiresAt
-
-	if err := l.db.Create(complianceRecord).Error; err != nil {
-		return nil, fmt.Errorf("failed to create data deletion request: %w", err)
+	record, err := l.createSubjectRequest(ctx, RequestTypeDeletion, req, "")
+	if err != nil {
+		return nil, err
 	}
 
 	return &DataResponse{
-		RequestID: uuid.New(), // Gerar UUID temporário já que ID é uint
-		Status:    "pending",
+		RequestID: record.ID,
+		Status:    record.Status,
 		Message:   "Data deletion request submitted successfully",
-		ExpiresAt: expiresAt,
+		DueAt:     *record.DueAt,
 	}, nil
 }
 
-// RequestDataPortability solicita portabilidade dos dados
-func (l *LGPDService) RequestDataPortability(ctx context.Context, req *DataRequest) (*DataResponse, error) {
-	// Verificar se usuário existe
-	var user models.User
-	if err := l.db.First(&user, req.UserID).Error; err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+// ProcessDataDeletion anonimiza os dados do titular preservando a genealogia da qual outros usuários dependem
+func (l *LGPDService) ProcessDataDeletion(ctx context.Context, requestID uint, processedByID uint) (*models.ComplianceRecord, error) {
+	record, err := l.loadOpenRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
 	}
-
-	// Criar registro de solicitação
-	requestData := map[string]interface{}{
-		"data_types": req.DataTypes,
-		"reason":     req.Reason,
-		"metadata":   req.Metadata,
+	if record.ComplianceType != RequestTypeDeletion {
+		return nil, fmt.Errorf("request %d is not a deletion request", requestID)
 	}
 
-	complianceRecord := &models.ComplianceRecord{
-		UserID:         req.UserID,
-		ComplianceType: "data_portability",
-		RequestType:    req.RequestType,
-		Status:         "pending",
-		RequestData:    requestData,
-		LegalBasis:     "data_subject_right",
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-	}
+	err = l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		report, err := l.anonymizeUserData(tx, record.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to anonymize user data: %w", err)
+		}
 
-	// Definir expiração da solicitação (30 dias para processar)
-	expiresAt := time.Now().Add(30 * 24 * time.Hour)
-	complianceRecord.ExpiresAt = &expiresAt
+		now := time.Now()
+		record.Status = RequestStatusCompleted
+		record.ProcessedAt = &now
+		record.ProcessedByID = &processedByID
+		if record.RequestData == nil {
+			record.RequestData = make(map[string]interface{})
+		}
+		record.RequestData["anonymization_report"] = report
+		record.UpdatedAt = now
 
-	if err := l.db.Create(complianceRecord).Error; err != nil {
-		return nil, fmt.Errorf("failed to create data portability request: %w", err)
+		return tx.Save(record).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Processar solicitação automaticamente (converter uint para uuid temporário)
-	go l.processDataPortabilityRequest(uuid.New())
-
-	return &DataResponse{
-		RequestID: uuid.New(), // Gerar UUID temporário já que ID é uint
-		Status:    "pending",
-		Message:   "Data portability request submitted successfully",
-		ExpiresAt: expiresAt,
-	}, nil
+	return record, nil
 }
 
-// ProcessDataDeletion processa exclusão de dados
-func (l *LGPDService) ProcessDataDeletion(ctx context.Context, requestID uuid.UUID) error {
-	var record models.ComplianceRecord
-	if err := l.db.First(&record, requestID).Error; err != nil {
-		return fmt.Errorf("request not found: %w", err)
+// ProcessRequest atende uma solicitação da fila: exclusões são anonimizadas, acesso e portabilidade têm o pacote (re)gerado
+func (l *LGPDService) ProcessRequest(ctx context.Context, requestID uint, processedByID uint) (*models.ComplianceRecord, error) {
+	record, err := l.loadOpenRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
 	}
 
-	if record.ComplianceType != "data_deletion" {
-		return fmt.Errorf("invalid request type")
+	switch record.ComplianceType {
+	case RequestTypeDeletion:
+		return l.ProcessDataDeletion(ctx, requestID, processedByID)
+	case RequestTypeAccess, RequestTypePortability:
+		if err := l.generateExport(ctx, record, &processedByID); err != nil {
+			return nil, err
+		}
+		return record, nil
+	default:
+		return nil, fmt.Errorf("request %d is not a data subject request", requestID)
 	}
+}
 
-	// Anonimizar/excluir dados do usuário
-	userID := record.UserID
-
-	tx := l.db.Begin()
-
-	// Excluir dados sensíveis mantendo registros de auditoria
-	if err := l.anonymizeUserData(tx, userID); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to anonymize user data: %w", err)
+// RejectRequest recusa uma solicitação registrando a justificativa (ex.: obrigação legal de guarda)
+func (l *LGPDService) RejectRequest(ctx context.Context, requestID uint, processedByID uint, reason string) (*models.ComplianceRecord, error) {
+	record, err := l.loadOpenRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Atualizar status da solicitação
-	record.Status = "completed"
-	if record.RequestData == nil {
-		record.RequestData = make(map[string]interface{})
-	}
-	record.RequestData["processed_at"] = time.Now()
-	record.RequestData["deletion_completed"] = true
-	record.RequestData["anonymization_applied"] = true
-	record.UpdatedAt = time.Now()
+	now := time.Now()
+	record.Status = RequestStatusRejected
+	record.ProcessedAt = &now
+	record.ProcessedByID = &processedByID
+	record.Notes = reason
+	record.UpdatedAt = now
 
-	if err := tx.Save(&record).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to update request: %w", err)
+	if err := l.db.WithContext(ctx).Save(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to reject request: %w", err)
 	}
-
-	return tx.Commit().Error
+	return record, nil
 }
 
-// GetUserConsentStatus obtém status de consentimento do usuário
-func (l *LGPDService) GetUserConsentStatus(ctx context.Context, userID uuid.UUID) (map[string]interface{}, error) {
-	var consents []models.ComplianceRecord
-	err := l.db.Where("user_id = ? AND compliance_type = ? AND status = ?",
-		userID, "consent", "approved").Find(&consents).Error
-
+// GetUserRequest busca uma solicitação do próprio titular
+func (l *LGPDService) GetUserRequest(ctx context.Context, userID, requestID uint) (*models.ComplianceRecord, error) {
+	var record models.ComplianceRecord
+	err := l.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND compliance_type IN ?", requestID, userID, SubjectRequestTypes).
+		First(&record).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to get consent status: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to load request: %w", err)
 	}
+	return &record, nil
+}
 
-	status := map[string]interface{}{
-		"user_id":        userID,
-		"consents":       consents,
-		"total_consents": len(consents),
+// ListRequests lista solicitações de titulares ordenadas pelo prazo de resposta
+func (l *LGPDService) ListRequests(ctx context.Context, filter *RequestFilter) ([]models.ComplianceRecord, int64, error) {
+	var total int64
+	if err := l.applyRequestFilter(l.db.WithContext(ctx).Model(&models.ComplianceRecord{}), filter).Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	// Verificar se há consentimentos próximos do vencimento
-	expiringConsents := 0
-	for _, consent := range consents {
-		if consent.ExpiresAt != nil && time.Until(*consent.ExpiresAt) < 30*24*time.Hour {
-			expiringConsents++
-		}
+	query := l.applyRequestFilter(l.db.WithContext(ctx), filter).Order("due_at ASC, id ASC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
 	}
-	status["expiring_consents"] = expiringConsents
 
-	return status, nil
+	var records []models.ComplianceRecord
+	if err := query.Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
 }
 
 // GetComplianceStats obtém estatísticas de compliance
@@ -397,7 +269,9 @@ func (l *LGPDService) GetComplianceStats(ctx context.Context) (map[string]interf
 
 	// Total de registros de compliance
 	var total int64
-	l.db.Model(&models.ComplianceRecord{}).Count(&total)
+	if err := l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
 	stats["total_records"] = total
 
 	// Por tipo de compliance
@@ -405,7 +279,7 @@ func (l *LGPDService) GetComplianceStats(ctx context.Context) (map[string]interf
 		ComplianceType string `json:"compliance_type"`
 		Count          int64  `json:"count"`
 	}
-	l.db.Model(&models.ComplianceRecord{}).
+	l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
 		Select("compliance_type, COUNT(*) as count").
 		Group("compliance_type").
 		Find(&typeStats)
@@ -416,17 +290,28 @@ func (l *LGPDService) GetComplianceStats(ctx context.Context) (map[string]interf
 		Status string `json:"status"`
 		Count  int64  `json:"count"`
 	}
-	l.db.Model(&models.ComplianceRecord{}).
+	l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
 		Select("status, COUNT(*) as count").
 		Group("status").
 		Find(&statusStats)
 	stats["by_status"] = statusStats
 
+	// Solicitações de titulares em aberto e vencidas
+	var open, overdue int64
+	l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
+		Where("compliance_type IN ? AND processed_at IS NULL", SubjectRequestTypes).
+		Count(&open)
+	l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
+		Where("compliance_type IN ? AND processed_at IS NULL AND due_at < ?", SubjectRequestTypes, time.Now()).
+		Count(&overdue)
+	stats["open_requests"] = open
+	stats["overdue_requests"] = overdue
+
 	// Consentimentos expirando
 	var expiring int64
-	l.db.Model(&models.ComplianceRecord{}).
+	l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
 		Where("compliance_type = ? AND status = ? AND expires_at <= ?",
-			"consent", "approved", time.Now().Add(30*24*time.Hour)).
+			ComplianceTypeConsent, ConsentStatusApproved, time.Now().Add(30*24*time.Hour)).
 		Count(&expiring)
 	stats["expiring_consents"] = expiring
 
@@ -435,119 +320,109 @@ func (l *LGPDService) GetComplianceStats(ctx context.Context) (map[string]interf
 
 // Funções auxiliares
 
-func (l *LGPDService) processDataAccessRequest(requestID uuid.UUID) {
-	var record models.ComplianceRecord
-	if err := l.db.First(&record, requestID).Error; err != nil {
-		return
+func (l *LGPDService) ensureUser(ctx context.Context, userID uint) error {
+	var user models.User
+	if err := l.db.WithContext(ctx).Select("id").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to load user: %w", err)
 	}
+	return nil
+}
 
-	// Coletar dados do usuário
-	userData, err := l.collectUserData(record.UserID)
-	if err != nil {
-		l.updateRequestStatus(requestID, "failed", err.Error())
-		return
+func (l *LGPDService) createSubjectRequest(ctx context.Context, complianceType string, req *DataRequest, format string) (*models.ComplianceRecord, error) {
+	if err := l.ensureUser(ctx, req.UserID); err != nil {
+		return nil, err
 	}
 
-	// Atualizar solicitação
-	record.Status = "completed"
-	if record.RequestData == nil {
-		record.RequestData = make(map[string]interface{})
+	// Uma solicitação em aberto por tipo evita gerar pacotes duplicados
+	var open int64
+	if err := l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
+		Where("user_id = ? AND compliance_type = ? AND status IN ?", req.UserID, complianceType,
+			[]string{RequestStatusPending, RequestStatusProcessing}).
+		Count(&open).Error; err != nil {
+		return nil, fmt.Errorf("failed to check open requests: %w", err)
 	}
-	record.RequestData["processed_at"] = time.Now()
-	record.RequestData["response_data"] = map[string]interface{}{
-		"data": userData,
+	if open > 0 {
+		return nil, ErrRequestOpen
 	}
-	record.UpdatedAt = time.Now()
 
-	l.db.Save(&record)
+	now := time.Now()
+	dueAt := now.Add(ResponseDeadline)
+	record := &models.ComplianceRecord{
+		UserID:         req.UserID,
+		ComplianceType: complianceType,
+		RequestType:    req.RequestType,
+		Status:         RequestStatusPending,
+		RequestData: map[string]interface{}{
+			"data_types": req.DataTypes,
+			"reason":     req.Reason,
+			"metadata":   req.Metadata,
+		},
+		LegalBasis:   "data_subject_right",
+		DueAt:        &dueAt,
+		ExportFormat: format,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := l.db.WithContext(ctx).Create(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", complianceType, err)
+	}
+	return record, nil
 }
 
-func (l *LGPDService) processDataPortabilityRequest(requestID uuid.UUID) {
+func (l *LGPDService) loadOpenRequest(ctx context.Context, requestID uint) (*models.ComplianceRecord, error) {
 	var record models.ComplianceRecord
-	if err := l.db.First(&record, requestID).Error; err != nil {
-		return
+	if err := l.db.WithContext(ctx).First(&record, requestID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to load request: %w", err)
 	}
-
-	// Criar exportação dos dados
-	exportURL, err := l.createDataExport(record.UserID)
-	if err != nil {
-		l.updateRequestStatus(requestID, "failed", err.Error())
-		return
+	if record.Status == RequestStatusCompleted || record.Status == RequestStatusRejected {
+		return nil, ErrRequestProcessed
 	}
-
-	// Atualizar solicitação
-	record.Status = "completed"
-	if record.RequestData == nil {
-		record.RequestData = make(map[string]interface{})
-	}
-	record.RequestData["processed_at"] = time.Now()
-	record.RequestData["export_url"] = exportURL
-	record.UpdatedAt = time.Now()
-
-	l.db.Save(&record)
+	return &record, nil
 }
 
-func (l *LGPDService) updateRequestStatus(requestID uuid.UUID, status, message string) {
-	l.db.Model(&models.ComplianceRecord{}).
-		Where("id = ?", requestID).
-		Updates(map[string]interface{}{
-			"status":       status,
-			"processed_at": time.Now(),
-			"notes":        message,
-			"updated_at":   time.Now(),
-		})
-}
-
-func (l *LGPDService) collectUserData(userID uuid.UUID) (map[string]interface{}, error) {
-	var user models.User
-	if err := l.db.First(&user, userID).Error; err != nil {
-		return nil, err
+func (l *LGPDService) applyRequestFilter(query *gorm.DB, filter *RequestFilter) *gorm.DB {
+	if filter.RequestType != "" {
+		query = query.Where("compliance_type = ?", filter.RequestType)
+	} else {
+		query = query.Where("compliance_type IN ?", SubjectRequestTypes)
 	}
-
-	// Preparar dados do usuário (removendo campos sensíveis)
-	userData := map[string]interface{}{
-		"id":                user.ID,
-		"email":             user.Email,
-		"is_active":         user.IsActive,
-		"is_email_verified": user.IsEmailVerified,
-		"created_at":        user.CreatedAt,
-		"updated_at":        user.UpdatedAt,
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
 	}
-
-	return userData, nil
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.OverdueOnly {
+		query = query.Where("processed_at IS NULL AND due_at < ?", time.Now())
+	}
+	return query
 }
 
-func (l *LGPDService) createDataExport(userID uuid.UUID) (string, error) {
-	// Simular criação de arquivo de exportação
-	exportID := uuid.New().String()
-	exportURL := fmt.Sprintf("/api/exports/%s", exportID)
-
-	// Em produção, criar arquivo real com dados estruturados
-	return exportURL, nil
-}
+func (l *LGPDService) processExportRequest(requestID uint) {
+	ctx := context.Background()
 
-func (l *LGPDService) anonymizeUserData(tx *gorm.DB, userID uuid.UUID) error {
-	// Anonimizar dados do usuário mantendo integridade referencial
-	updates := map[string]interface{}{
-		"email":         fmt.Sprintf("anonymous_%s@deleted.local", uuid.New().String()[:8]),
-		"password_hash": "",
-		"salt":          "",
-		"is_active":     false,
-		"consent_given": false,
-		"consent_date":  nil,
-		"updated_at":    time.Now(),
+	var record models.ComplianceRecord
+	if err := l.db.WithContext(ctx).First(&record, requestID).Error; err != nil {
+		return
 	}
 
-	return tx.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error
+	// Falhas ficam registradas na solicitação e podem ser reprocessadas pela fila administrativa
+	_ = l.generateExport(ctx, &record, nil)
 }
 
-// CleanupExpiredConsents limpa consentimentos expirados
-func (l *LGPDService) CleanupExpiredConsents(ctx context.Context) error {
-	result := l.db.Model(&models.ComplianceRecord{}).
-		Where("compliance_type = ? AND status = ? AND expires_at < ?",
-			"consent", "approved", time.Now()).
-		Update("status", "expired")
-
-	fmt.Printf("Marked %d consents as expired\n", result.RowsAffected)
-	return result.Error
+func (l *LGPDService) updateRequestStatus(ctx context.Context, requestID uint, status, message string) {
+	l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
+		Where("id = ?", requestID).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"notes":      message,
+			"updated_at": time.Now(),
+		})
 }
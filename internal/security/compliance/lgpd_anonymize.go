@@ -0,0 +1,177 @@
+package compliance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// anonymizedName nome exibido no lugar do titular anonimizado
+const anonymizedName = "Titular anonimizado"
+
+// anonymizeUserData remove os dados pessoais do titular mantendo a integridade dos registros dos quais terceiros dependem.
+// Equinos que compõem a genealogia de animais de outros proprietários ou que foram tokenizados permanecem (inativos);
+// transações, tokens e trilha de auditoria são mantidos por obrigação legal, apenas desvinculados de dados identificáveis.
+func (l *LGPDService) anonymizeUserData(tx *gorm.DB, userID uint) (map[string]interface{}, error) {
+	now := time.Now()
+	report := make(map[string]interface{})
+
+	var equinos []models.Equino
+	if err := tx.Select("id", "equinoid", "genitor", "genitora").
+		Where("proprietario_id = ?", userID).
+		Find(&equinos).Error; err != nil {
+		return nil, fmt.Errorf("failed to load horses: %w", err)
+	}
+
+	preserved, err := preservedEquinos(tx, userID, equinos)
+	if err != nil {
+		return nil, err
+	}
+
+	var keepIDs, removeIDs []uint
+	var removeEquinoids []string
+	for _, e := range equinos {
+		if preserved[e.Equinoid] {
+			keepIDs = append(keepIDs, e.ID)
+			continue
+		}
+		removeIDs = append(removeIDs, e.ID)
+		removeEquinoids = append(removeEquinoids, e.Equinoid)
+	}
+
+	if len(keepIDs) > 0 {
+		if err := tx.Model(&models.Equino{}).Where("id IN ?", keepIDs).Updates(map[string]interface{}{
+			"status":     models.StatusInativo,
+			"updated_at": now,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to deactivate preserved horses: %w", err)
+		}
+	}
+	if len(removeIDs) > 0 {
+		if err := tx.Where("id IN ?", removeIDs).Delete(&models.Equino{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to remove horses: %w", err)
+		}
+		if err := tx.Where("equinoid IN ?", removeEquinoids).Delete(&models.PerfilSocial{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to remove social profiles: %w", err)
+		}
+		if err := tx.Where("equinoid IN ?", removeEquinoids).Delete(&models.PostSocial{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to remove social posts: %w", err)
+		}
+	}
+	report["equinos_preservados"] = len(keepIDs)
+	report["equinos_removidos"] = len(removeIDs)
+
+	// Conteúdo social publicado pelo titular
+	posts := tx.Where("criado_por = ?", userID).Delete(&models.PostSocial{})
+	if posts.Error != nil {
+		return nil, fmt.Errorf("failed to remove posts: %w", posts.Error)
+	}
+	comentarios := tx.Where("user_id = ?", userID).Delete(&models.ComentarioSocial{})
+	if comentarios.Error != nil {
+		return nil, fmt.Errorf("failed to remove comments: %w", comentarios.Error)
+	}
+	interacoes := tx.Where("user_id = ?", userID).Delete(&models.InteracaoSocial{})
+	if interacoes.Error != nil {
+		return nil, fmt.Errorf("failed to remove interactions: %w", interacoes.Error)
+	}
+	report["posts_removidos"] = posts.RowsAffected
+	report["comentarios_removidos"] = comentarios.RowsAffected
+	report["interacoes_removidas"] = interacoes.RowsAffected
+
+	// Acessos delegados concedidos ao titular ou sobre seus equinos removidos
+	acessos := tx.Model(&models.EquinoVeterinario{}).
+		Where("status IN ?", []models.StatusDelegado{models.StatusDelegadoPendente, models.StatusDelegadoAtivo}).
+		Where("veterinario_id = ? OR equino_id IN ?", userID, removeIDs).
+		Updates(map[string]interface{}{
+			"status":      models.StatusDelegadoRevogado,
+			"revogado_em": now,
+			"updated_at":  now,
+		})
+	if acessos.Error != nil {
+		return nil, fmt.Errorf("failed to revoke delegated access: %w", acessos.Error)
+	}
+	report["acessos_revogados"] = acessos.RowsAffected
+
+	// Cadastro: o registro permanece (desativado) para manter as chaves de transações e genealogia
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"name":              anonymizedName,
+		"email":             fmt.Sprintf("anonimizado_%d@removido.equinoid.local", userID),
+		"password":          "",
+		"CPFCNPJ":           "",
+		"supabase_id":       nil,
+		"keycloak_sub":      nil,
+		"is_email_verified": false,
+		"is_active":         false,
+		"updated_at":        now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	if err := tx.Where("id = ?", userID).Delete(&models.User{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to remove user: %w", err)
+	}
+
+	report["dados_mantidos"] = []string{"transacoes", "tokens", "leiloes", "exames", "auditoria"}
+	report["anonimizado_em"] = now
+
+	return report, nil
+}
+
+// preservedEquinos identifica os equinos do titular que precisam permanecer: ancestrais de animais de outros
+// proprietários (incluindo a ascendência desses ancestrais) e animais com tokenização
+func preservedEquinos(tx *gorm.DB, userID uint, equinos []models.Equino) (map[string]bool, error) {
+	preserved := make(map[string]bool)
+	if len(equinos) == 0 {
+		return preserved, nil
+	}
+
+	byEquinoid := make(map[string]models.Equino, len(equinos))
+	equinoids := make([]string, 0, len(equinos))
+	ids := make([]uint, 0, len(equinos))
+	for _, e := range equinos {
+		byEquinoid[e.Equinoid] = e
+		equinoids = append(equinoids, e.Equinoid)
+		ids = append(ids, e.ID)
+	}
+
+	var descendentes []models.Equino
+	if err := tx.Select("genitor", "genitora").
+		Where("proprietario_id <> ?", userID).
+		Where("genitor IN ? OR genitora IN ?", equinoids, equinoids).
+		Find(&descendentes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load dependent pedigrees: %w", err)
+	}
+
+	var queue []string
+	for _, d := range descendentes {
+		queue = append(queue, d.Genitor, d.Genitora)
+	}
+
+	var tokenizados []models.Tokenizacao
+	if err := tx.Select("equino_id").Where("equino_id IN ?", ids).Find(&tokenizados).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tokenizations: %w", err)
+	}
+	for _, t := range tokenizados {
+		for _, e := range equinos {
+			if e.ID == t.EquinoID {
+				queue = append(queue, e.Equinoid)
+			}
+		}
+	}
+
+	// A ascendência de um equino preservado também precisa permanecer para a genealogia continuar navegável
+	for len(queue) > 0 {
+		equinoid := queue[0]
+		queue = queue[1:]
+
+		e, ok := byEquinoid[equinoid]
+		if !ok || preserved[equinoid] {
+			continue
+		}
+		preserved[equinoid] = true
+		queue = append(queue, e.Genitor, e.Genitora)
+	}
+
+	return preserved, nil
+}
@@ -0,0 +1,392 @@
+package compliance
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Formatos do pacote de exportação do titular
+const (
+	ExportFormatZIP  = "zip"
+	ExportFormatJSON = "json"
+)
+
+// exportFormatVersion versão do layout do pacote; muda quando seções ou manifesto mudam
+const exportFormatVersion = "1"
+
+// exportStore grava pacotes de exportação assinados em disco
+type exportStore struct {
+	dir string
+	key []byte
+	ttl time.Duration
+}
+
+// ExportManifest descreve o conteúdo do pacote; a assinatura HMAC-SHA256 cobre o manifesto serializado
+type ExportManifest struct {
+	Version     string               `json:"version"`
+	RequestID   uint                 `json:"request_id"`
+	UserID      uint                 `json:"user_id"`
+	RequestType string               `json:"request_type"`
+	GeneratedAt time.Time            `json:"generated_at"`
+	Files       []ExportManifestFile `json:"files"`
+}
+
+// ExportManifestFile seção do pacote com seu hash SHA-256
+type ExportManifestFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Bytes  int    `json:"bytes"`
+}
+
+// ExportFile pacote pronto para download
+type ExportFile struct {
+	Path        string
+	Filename    string
+	ContentType string
+	Checksum    string
+	Signature   string
+}
+
+// exportSection seção nomeada dos dados do titular
+type exportSection struct {
+	name string
+	data interface{}
+}
+
+// OpenExport localiza o pacote de uma solicitação concluída do titular
+func (l *LGPDService) OpenExport(ctx context.Context, userID, requestID uint) (*ExportFile, error) {
+	record, err := l.GetUserRequest(ctx, userID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Status != RequestStatusCompleted || record.ExportPath == "" {
+		return nil, ErrExportUnavailable
+	}
+	if record.ExpiresAt != nil && time.Now().After(*record.ExpiresAt) {
+		return nil, ErrExportUnavailable
+	}
+	if _, err := os.Stat(record.ExportPath); err != nil {
+		return nil, ErrExportUnavailable
+	}
+
+	contentType := "application/zip"
+	if record.ExportFormat == ExportFormatJSON {
+		contentType = "application/json"
+	}
+
+	return &ExportFile{
+		Path:        record.ExportPath,
+		Filename:    filepath.Base(record.ExportPath),
+		ContentType: contentType,
+		Checksum:    record.ExportChecksum,
+		Signature:   record.ExportSignature,
+	}, nil
+}
+
+// VerifyExportManifest confere a assinatura de um manifesto de exportação
+func (l *LGPDService) VerifyExportManifest(manifest []byte, signature string) bool {
+	if l.exports == nil {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(l.exports.sign(manifest)))
+}
+
+// CleanupExpiredExports remove do disco os pacotes cujo prazo de download expirou
+func (l *LGPDService) CleanupExpiredExports(ctx context.Context) (int64, error) {
+	var records []models.ComplianceRecord
+	if err := l.db.WithContext(ctx).
+		Where("compliance_type IN ? AND export_path <> '' AND expires_at < ?",
+			[]string{RequestTypeAccess, RequestTypePortability}, time.Now()).
+		Find(&records).Error; err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, record := range records {
+		if err := os.Remove(record.ExportPath); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove export %d: %w", record.ID, err)
+		}
+		if err := l.db.WithContext(ctx).Model(&models.ComplianceRecord{}).
+			Where("id = ?", record.ID).
+			Update("export_path", "").Error; err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// generateExport coleta os dados do titular, grava o pacote assinado e conclui a solicitação
+func (l *LGPDService) generateExport(ctx context.Context, record *models.ComplianceRecord, processedByID *uint) error {
+	if l.exports == nil {
+		err := errors.New("export storage not configured")
+		l.updateRequestStatus(ctx, record.ID, RequestStatusFailed, err.Error())
+		return err
+	}
+
+	l.updateRequestStatus(ctx, record.ID, RequestStatusProcessing, "")
+
+	sections, err := l.collectUserData(ctx, record.UserID)
+	if err != nil {
+		l.updateRequestStatus(ctx, record.ID, RequestStatusFailed, err.Error())
+		return fmt.Errorf("failed to collect user data: %w", err)
+	}
+
+	format := record.ExportFormat
+	if format == "" {
+		format = ExportFormatJSON
+	}
+
+	content, signature, err := l.exports.build(record, sections, format)
+	if err != nil {
+		l.updateRequestStatus(ctx, record.ID, RequestStatusFailed, err.Error())
+		return err
+	}
+
+	path, err := l.exports.write(record, format, content)
+	if err != nil {
+		l.updateRequestStatus(ctx, record.ID, RequestStatusFailed, err.Error())
+		return err
+	}
+
+	// Um pacote regenerado substitui o anterior
+	if record.ExportPath != "" && record.ExportPath != path {
+		_ = os.Remove(record.ExportPath)
+	}
+
+	checksum := sha256.Sum256(content)
+	now := time.Now()
+	expiresAt := now.Add(l.exports.ttl)
+
+	record.Status = RequestStatusCompleted
+	record.ProcessedAt = &now
+	record.ProcessedByID = processedByID
+	record.ExportPath = path
+	record.ExportFormat = format
+	record.ExportChecksum = hex.EncodeToString(checksum[:])
+	record.ExportSignature = signature
+	record.ExpiresAt = &expiresAt
+	record.Notes = ""
+	record.UpdatedAt = now
+
+	if err := l.db.WithContext(ctx).Save(record).Error; err != nil {
+		return fmt.Errorf("failed to update request: %w", err)
+	}
+	return nil
+}
+
+// collectUserData reúne os dados do titular: cadastro, equinos, eventos, exames, transações e atividade social
+func (l *LGPDService) collectUserData(ctx context.Context, userID uint) ([]exportSection, error) {
+	db := func() *gorm.DB { return l.db.WithContext(ctx) }
+
+	var user models.User
+	if err := db().First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	var equinos []models.Equino
+	if err := db().Where("proprietario_id = ?", userID).Order("id").Find(&equinos).Error; err != nil {
+		return nil, err
+	}
+	equinoIDs := make([]uint, 0, len(equinos))
+	equinoids := make([]string, 0, len(equinos))
+	for _, e := range equinos {
+		equinoIDs = append(equinoIDs, e.ID)
+		equinoids = append(equinoids, e.Equinoid)
+	}
+
+	var eventos []models.Evento
+	if err := db().Where("equino_id IN ? OR veterinario_id = ?", equinoIDs, userID).Order("id").Find(&eventos).Error; err != nil {
+		return nil, err
+	}
+
+	var exames []models.ExameLaboratorial
+	if err := db().Where("equinoid IN ? OR veterinario_solicitante_id = ? OR laboratorio_id = ?", equinoids, userID, userID).
+		Order("id").Find(&exames).Error; err != nil {
+		return nil, err
+	}
+
+	var transacoesToken []models.TransacaoToken
+	if err := db().Where("vendedor_id = ? OR comprador_id = ?", userID, userID).Order("id").Find(&transacoesToken).Error; err != nil {
+		return nil, err
+	}
+	var participacoesToken []models.ParticipacaoToken
+	if err := db().Where("investidor_id = ?", userID).Order("id").Find(&participacoesToken).Error; err != nil {
+		return nil, err
+	}
+	var ofertasToken []models.OfertaToken
+	if err := db().Where("vendedor_id = ?", userID).Order("id").Find(&ofertasToken).Error; err != nil {
+		return nil, err
+	}
+	var participacoesLeilao []models.ParticipacaoLeilao
+	if err := db().Where("criador_id = ? OR comprador_id = ?", userID, userID).Order("id").Find(&participacoesLeilao).Error; err != nil {
+		return nil, err
+	}
+	var transacoesFinanceiras []models.TransacaoFinanceira
+	if err := db().Where("equino_id IN ?", equinoIDs).Order("id").Find(&transacoesFinanceiras).Error; err != nil {
+		return nil, err
+	}
+
+	var perfis []models.PerfilSocial
+	if err := db().Where("criado_por = ?", userID).Order("id").Find(&perfis).Error; err != nil {
+		return nil, err
+	}
+	var posts []models.PostSocial
+	if err := db().Where("criado_por = ?", userID).Order("id").Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	var comentarios []models.ComentarioSocial
+	if err := db().Where("user_id = ?", userID).Order("id").Find(&comentarios).Error; err != nil {
+		return nil, err
+	}
+	var interacoes []models.InteracaoSocial
+	if err := db().Where("user_id = ?", userID).Order("id").Find(&interacoes).Error; err != nil {
+		return nil, err
+	}
+
+	var acessos []models.EquinoVeterinario
+	if err := db().Where("veterinario_id = ? OR nomeado_por_id = ?", userID, userID).Order("id").Find(&acessos).Error; err != nil {
+		return nil, err
+	}
+
+	return []exportSection{
+		{name: "titular", data: map[string]interface{}{
+			"id":                user.ID,
+			"name":              user.Name,
+			"email":             user.Email,
+			"user_type":         user.UserType,
+			"cpf_cnpj":          user.CPFCNPJ,
+			"role":              user.Role,
+			"is_email_verified": user.IsEmailVerified,
+			"is_active":         user.IsActive,
+			"created_at":        user.CreatedAt,
+			"updated_at":        user.UpdatedAt,
+		}},
+		{name: "equinos", data: equinos},
+		{name: "eventos", data: eventos},
+		{name: "exames", data: exames},
+		{name: "transacoes", data: map[string]interface{}{
+			"transacoes_token":       transacoesToken,
+			"participacoes_token":    participacoesToken,
+			"ofertas_token":          ofertasToken,
+			"participacoes_leilao":   participacoesLeilao,
+			"transacoes_financeiras": transacoesFinanceiras,
+		}},
+		{name: "social", data: map[string]interface{}{
+			"perfis":      perfis,
+			"posts":       posts,
+			"comentarios": comentarios,
+			"interacoes":  interacoes,
+		}},
+		{name: "acessos_delegados", data: acessos},
+	}, nil
+}
+
+// build serializa as seções, monta o manifesto assinado e empacota em ZIP ou JSON
+func (s *exportStore) build(record *models.ComplianceRecord, sections []exportSection, format string) ([]byte, string, error) {
+	manifest := ExportManifest{
+		Version:     exportFormatVersion,
+		RequestID:   record.ID,
+		UserID:      record.UserID,
+		RequestType: record.ComplianceType,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	payloads := make(map[string]json.RawMessage, len(sections))
+	for _, section := range sections {
+		data, err := json.Marshal(section.data)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal %s: %w", section.name, err)
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, ExportManifestFile{
+			Name:   section.name + ".json",
+			SHA256: hex.EncodeToString(sum[:]),
+			Bytes:  len(data),
+		})
+		payloads[section.name] = data
+	}
+
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	signature := s.sign(manifestJSON)
+
+	if format == ExportFormatJSON {
+		bundle, err := json.Marshal(map[string]interface{}{
+			"manifest":  json.RawMessage(manifestJSON),
+			"signature": signature,
+			"data":      payloads,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal bundle: %w", err)
+		}
+		return bundle, signature, nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, section := range sections {
+		if err := writeZipEntry(zw, section.name+".json", payloads[section.name]); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writeZipEntry(zw, "manifest.json", manifestJSON); err != nil {
+		return nil, "", err
+	}
+	if err := writeZipEntry(zw, "manifest.sig", []byte(signature)); err != nil {
+		return nil, "", err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close zip: %w", err)
+	}
+
+	return buf.Bytes(), signature, nil
+}
+
+func (s *exportStore) write(record *models.ComplianceRecord, format string, content []byte) (string, error) {
+	dir := filepath.Join(s.dir, fmt.Sprintf("%d", record.UserID))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	name := fmt.Sprintf("equinoid_dados_%d_%s.%s", record.ID, time.Now().Format("20060102_150405"), format)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+	return path, nil
+}
+
+func (s *exportStore) sign(manifest []byte) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(manifest)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func writeZipEntry(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to zip: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to zip: %w", name, err)
+	}
+	return nil
+}
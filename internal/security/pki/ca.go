@@ -1,18 +1,39 @@
 package pki
 
 import (
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/equinoid/backend/internal/models"
-	"github.com/equinoid/backend/internal/security/crypto"
+	equinoidcrypto "github.com/equinoid/backend/internal/security/crypto"
+)
+
+// Origem do par de chaves de um certificado emitido
+const (
+	KeyOriginCSR    = "csr"
+	KeyOriginServer = "server"
+)
+
+// minRSAKeyBits tamanho mínimo aceito para chaves RSA de CSRs
+const minRSAKeyBits = 2048
+
+var (
+	ErrCANotInitialized = errors.New("CA not initialized")
+	ErrInvalidCSR       = errors.New("invalid certificate signing request")
+	ErrWeakKey          = errors.New("public key does not meet minimum strength")
 )
 
 // CAService gerencia a Autoridade Certificadora (CA)
@@ -22,11 +43,13 @@ type CAService struct {
 	caCert            *x509.Certificate
 	caKey             *rsa.PrivateKey
 	validityDays      int
-	encryptionService *crypto.EncryptionService
+	encryptionService *equinoidcrypto.EncryptionService
+	crlURL            string
+	ocspURL           string
 }
 
 // NewCAService cria um novo serviço de CA
-func NewCAService(caCertPath, caKeyPath string, validityDays int, encryptionService *crypto.EncryptionService) *CAService {
+func NewCAService(caCertPath, caKeyPath string, validityDays int, encryptionService *equinoidcrypto.EncryptionService) *CAService {
 	return &CAService{
 		caCertPath:        caCertPath,
 		caKeyPath:         caKeyPath,
@@ -47,6 +70,9 @@ type CertificateRequest struct {
 	KeySize      int      `json:"key_size"`
 	ValidityDays int      `json:"validity_days"`
 	KeyUsage     []string `json:"key_usage"` // digital_signature, key_encipherment, etc.
+
+	Equinoid        string `json:"equinoid,omitempty"`
+	CertificateType string `json:"certificate_type,omitempty"`
 }
 
 // CertificateResponse representa o resultado da geração do certificado
@@ -111,9 +137,11 @@ func (ca *CAService) createCA() error {
 		return fmt.Errorf("failed to create CA certificate: %w", err)
 	}
 
-	// Criar diretório se não existir
-	if err := os.MkdirAll("./certs", 0755); err != nil {
-		return fmt.Errorf("failed to create certs directory: %w", err)
+	// Criar diretórios se não existirem
+	for _, dir := range []string{filepath.Dir(ca.caCertPath), filepath.Dir(ca.caKeyPath)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create certs directory: %w", err)
+		}
 	}
 
 	// Salvar certificado CA
@@ -210,10 +238,11 @@ func (ca *CAService) loadCA() error {
 	return nil
 }
 
-// IssueCertificate emite um novo certificado
+// IssueCertificate emite um novo certificado com par de chaves gerado no servidor. A chave privada é devolvida
+// uma única vez na resposta e guardada no modelo criptografada; prefira IssueFromCSR sempre que o titular puder gerar a própria chave.
 func (ca *CAService) IssueCertificate(req *CertificateRequest) (*CertificateResponse, error) {
-	if ca.caCert == nil || ca.caKey == nil {
-		return nil, fmt.Errorf("CA not initialized")
+	if !ca.Ready() {
+		return nil, ErrCANotInitialized
 	}
 
 	// Definir valores padrão
@@ -222,44 +251,117 @@ func (ca *CAService) IssueCertificate(req *CertificateRequest) (*CertificateResp
 		keySize = 2048
 	}
 
-	validityDays := req.ValidityDays
-	if validityDays == 0 {
-		validityDays = ca.validityDays
-	}
-
 	// Gerar chave privada para o certificado
 	privateKey, err := rsa.GenerateKey(rand.Reader, keySize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate private key: %w", err)
 	}
 
+	response, err := ca.sign(req, &privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Codificar chave privada em PEM
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+
+	// Criptografar chave privada antes de salvar no modelo
+	encryptedPrivateKey, err := ca.encryptionService.Encrypt(string(privateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt private key: %w", err)
+	}
+
+	response.PrivateKeyPEM = privateKeyPEM
+	response.Certificate.PrivateKeyPEM = encryptedPrivateKey
+	response.Certificate.KeyOrigin = KeyOriginServer
+
+	return response, nil
+}
+
+// IssueFromCSR emite um certificado para a chave pública de uma CSR (PKCS#10); a chave privada nunca chega ao servidor.
+// O assunto do certificado vem da solicitação e não da CSR, para que o titular não declare uma identidade diferente.
+func (ca *CAService) IssueFromCSR(req *CertificateRequest, csrPEM []byte) (*CertificateResponse, error) {
+	if !ca.Ready() {
+		return nil, ErrCANotInitialized
+	}
+
+	csrBlock, _ := pem.Decode(csrPEM)
+	if csrBlock == nil || csrBlock.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("%w: PEM block CERTIFICATE REQUEST not found", ErrInvalidCSR)
+	}
+
+	csr, err := x509.ParseCertificateRequest(csrBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSR, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("%w: signature check failed: %v", ErrInvalidCSR, err)
+	}
+	if err := checkKeyStrength(csr.PublicKey); err != nil {
+		return nil, err
+	}
+
+	response, err := ca.sign(req, csr.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	response.Certificate.KeyOrigin = KeyOriginCSR
+
+	return response, nil
+}
+
+// Ready indica se o certificado e a chave da CA foram carregados
+func (ca *CAService) Ready() bool {
+	return ca.caCert != nil && ca.caKey != nil
+}
+
+// sign assina com a CA um certificado para a chave pública informada
+func (ca *CAService) sign(req *CertificateRequest, publicKey crypto.PublicKey) (*CertificateResponse, error) {
+	validityDays := req.ValidityDays
+	if validityDays == 0 {
+		validityDays = ca.validityDays
+	}
+
 	// Gerar número serial único
 	serialNumber, err := generateSerialNumber()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate serial number: %w", err)
 	}
 
+	now := time.Now()
+	notAfter := now.Add(time.Duration(validityDays) * 24 * time.Hour)
+	// O certificado da entidade final não pode ultrapassar a validade da CA
+	if notAfter.After(ca.caCert.NotAfter) {
+		notAfter = ca.caCert.NotAfter
+	}
+
 	// Template para o certificado
 	template := &x509.Certificate{
-		SerialNumber: serialNumber,
-		Subject: pkix.Name{
-			CommonName:   req.CommonName,
-			Organization: []string{req.Organization},
-			Country:      []string{req.Country},
-			Province:     []string{req.Province},
-			Locality:     []string{req.Locality},
-		},
-		EmailAddresses:        []string{req.EmailAddress},
-		NotBefore:             time.Now(),
-		NotAfter:              time.Now().Add(time.Duration(validityDays) * 24 * time.Hour),
+		SerialNumber:          serialNumber,
+		Subject:               req.subject(),
+		NotBefore:             now,
+		NotAfter:              notAfter,
 		KeyUsage:              ca.parseKeyUsage(req.KeyUsage),
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageEmailProtection},
 		BasicConstraintsValid: true,
 		IsCA:                  false,
+		AuthorityKeyId:        ca.caCert.SubjectKeyId,
+	}
+	if req.EmailAddress != "" {
+		template.EmailAddresses = []string{req.EmailAddress}
+	}
+	if ca.crlURL != "" {
+		template.CRLDistributionPoints = []string{ca.crlURL}
+	}
+	if ca.ocspURL != "" {
+		template.OCSPServer = []string{ca.ocspURL}
 	}
 
 	// Criar certificado
-	certBytes, err := x509.CreateCertificate(rand.Reader, template, ca.caCert, &privateKey.PublicKey, ca.caKey)
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, ca.caCert, publicKey, ca.caKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create certificate: %w", err)
 	}
@@ -270,14 +372,8 @@ func (ca *CAService) IssueCertificate(req *CertificateRequest) (*CertificateResp
 		Bytes: certBytes,
 	})
 
-	// Codificar chave privada em PEM
-	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
-	})
-
 	// Codificar chave pública em PEM
-	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal public key: %w", err)
 	}
@@ -290,37 +386,67 @@ func (ca *CAService) IssueCertificate(req *CertificateRequest) (*CertificateResp
 	// Calcular fingerprint
 	fingerprint := ca.calculateFingerprint(certBytes)
 
-	// Criptografar chave privada antes de salvar no modelo
-	encryptedPrivateKey, err := ca.encryptionService.Encrypt(string(privateKeyPEM))
-	if err != nil {
-		return nil, fmt.Errorf("failed to encrypt private key: %w", err)
-	}
-
 	// Criar modelo de certificado
 	certificate := &models.Certificate{
-		UserID:         req.UserID,
-		SerialNumber:   serialNumber.String(),
-		CommonName:     req.CommonName,
-		CertificatePEM: string(certPEM),
-		PrivateKeyPEM:  encryptedPrivateKey,
-		PublicKeyPEM:   string(publicKeyPEM),
-		IssuedAt:       template.NotBefore,
-		ExpiresAt:      template.NotAfter,
-		IsRevoked:      false,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-	}
-
-	response := &CertificateResponse{
+		UserID:          req.UserID,
+		Equinoid:        req.Equinoid,
+		CertificateType: req.CertificateType,
+		SerialNumber:    serialNumber.String(),
+		CommonName:      req.CommonName,
+		CertificatePEM:  string(certPEM),
+		PublicKeyPEM:    string(publicKeyPEM),
+		Fingerprint:     fingerprint,
+		IssuedAt:        template.NotBefore,
+		ValidFrom:       template.NotBefore,
+		ValidTo:         template.NotAfter,
+		ExpiresAt:       template.NotAfter,
+		IsRevoked:       false,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	return &CertificateResponse{
 		Certificate:    certificate,
 		CertificatePEM: certPEM,
-		PrivateKeyPEM:  privateKeyPEM,
 		PublicKeyPEM:   publicKeyPEM,
 		SerialNumber:   serialNumber.String(),
 		Fingerprint:    fingerprint,
+	}, nil
+}
+
+// subject monta o nome distinto omitindo atributos vazios
+func (req *CertificateRequest) subject() pkix.Name {
+	name := pkix.Name{CommonName: req.CommonName}
+	if req.Organization != "" {
+		name.Organization = []string{req.Organization}
+	}
+	if req.Country != "" {
+		name.Country = []string{req.Country}
 	}
+	if req.Province != "" {
+		name.Province = []string{req.Province}
+	}
+	if req.Locality != "" {
+		name.Locality = []string{req.Locality}
+	}
+	return name
+}
 
-	return response, nil
+// checkKeyStrength rejeita chaves RSA menores que 2048 bits e curvas abaixo de P-256
+func checkKeyStrength(publicKey crypto.PublicKey) error {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return fmt.Errorf("%w: RSA key has %d bits, minimum is %d", ErrWeakKey, key.N.BitLen(), minRSAKeyBits)
+		}
+	case *ecdsa.PublicKey:
+		if key.Curve.Params().BitSize < 256 {
+			return fmt.Errorf("%w: ECDSA curve %s is too small", ErrWeakKey, key.Curve.Params().Name)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported key type %T", ErrWeakKey, publicKey)
+	}
+	return nil
 }
 
 // RevokeCertificate revoga um certificado
@@ -330,8 +456,7 @@ func (ca *CAService) RevokeCertificate(certificate *models.Certificate, reason s
 	certificate.RevocationReason = reason
 	certificate.UpdatedAt = time.Now()
 
-	// Em produção, adicionar à CRL (Certificate Revocation List)
-	fmt.Printf("Certificate %s has been revoked. Reason: %s\n", certificate.SerialNumber, reason)
+	// A CRL é republicada pelo PKIManager (PublishCRL) e o OCSP consulta o status diretamente no banco
 
 	return nil
 }
@@ -349,6 +474,10 @@ func (ca *CAService) VerifyCertificate(certPEM []byte) (*CertificateInfo, error)
 		return nil, fmt.Errorf("failed to parse certificate: %w", err)
 	}
 
+	if !ca.Ready() {
+		return nil, ErrCANotInitialized
+	}
+
 	// Verificar se foi emitido por nossa CA
 	if err := cert.CheckSignatureFrom(ca.caCert); err != nil {
 		return nil, fmt.Errorf("certificate not issued by this CA: %w", err)
@@ -453,15 +582,16 @@ func (ca *CAService) formatKeyUsage(keyUsage x509.KeyUsage) []string {
 	return usages
 }
 
-// calculateFingerprint calcula o fingerprint SHA-256 do certificado
+// calculateFingerprint calcula o fingerprint SHA-256 do certificado (DER)
 func (ca *CAService) calculateFingerprint(certBytes []byte) string {
-	return fmt.Sprintf("%x", certBytes[:32]) // Simplificado para exemplo
+	sum := sha256.Sum256(certBytes)
+	return hex.EncodeToString(sum[:])
 }
 
 // GetCACertificate retorna o certificado da CA em PEM
 func (ca *CAService) GetCACertificate() ([]byte, error) {
 	if ca.caCert == nil {
-		return nil, fmt.Errorf("CA not initialized")
+		return nil, ErrCANotInitialized
 	}
 
 	return pem.EncodeToMemory(&pem.Block{
@@ -472,6 +602,10 @@ func (ca *CAService) GetCACertificate() ([]byte, error) {
 
 // ValidateCertificateChain valida a cadeia de certificados
 func (ca *CAService) ValidateCertificateChain(certPEM []byte) error {
+	if ca.caCert == nil {
+		return ErrCANotInitialized
+	}
+
 	// Decodificar certificado
 	certBlock, _ := pem.Decode(certPEM)
 	if certBlock == nil {
@@ -488,7 +622,10 @@ func (ca *CAService) ValidateCertificateChain(certPEM []byte) error {
 	roots.AddCert(ca.caCert)
 
 	// Verificar cadeia
-	_, err = cert.Verify(x509.VerifyOptions{Roots: roots})
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
 	if err != nil {
 		return fmt.Errorf("certificate chain validation failed: %w", err)
 	}
@@ -0,0 +1,485 @@
+package pki
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid CMS signature")
+	ErrDigestMismatch   = errors.New("document digest does not match the signature")
+)
+
+// OIDs de CMS (RFC 5652) e dos atributos CAdES (ETSI EN 319 122)
+var (
+	oidData                     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidAttrContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttrMessageDigest        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttrSigningTime          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidAttrSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidAttrTimestampToken       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+	oidSHA256                   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384                   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512                   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidSHA256WithRSA            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidECDSAWithSHA256          = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+// digestAlgorithms algoritmos de resumo aceitos em assinaturas de terceiros (carimbos de TSAs externas)
+var digestAlgorithms = map[string]crypto.Hash{
+	oidSHA256.String(): crypto.SHA256,
+	oidSHA384.String(): crypto.SHA384,
+	oidSHA512.String(): crypto.SHA512,
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     asn1.RawValue `asn1:"optional,explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type signerInfo struct {
+	Version            int
+	SID                issuerAndSerialNumber
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type essCertIDv2 struct {
+	CertHash     []byte
+	IssuerSerial essIssuerSerial
+}
+
+type essIssuerSerial struct {
+	Issuer       []asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// CAdESOptions parâmetros de uma assinatura CAdES-BES destacada
+type CAdESOptions struct {
+	// SigningTime é gravado como atributo assinado; o perfil PAdES omite o atributo e usa /M do dicionário
+	SigningTime        time.Time
+	OmitSigningTime    bool
+	IntermediateChains []*x509.Certificate
+}
+
+// CMSSignature dados extraídos de uma assinatura CMS verificada
+type CMSSignature struct {
+	Signer       *x509.Certificate
+	Certificates []*x509.Certificate
+	SigningTime  *time.Time
+	Digest       []byte
+}
+
+// SignCAdES gera uma assinatura CAdES-BES destacada (CMS SignedData sem conteúdo encapsulado) sobre o SHA-256 do documento
+func SignCAdES(digest []byte, certificate *x509.Certificate, signer crypto.Signer, opts CAdESOptions) ([]byte, error) {
+	if len(digest) != sha256.Size {
+		return nil, fmt.Errorf("expected SHA-256 digest, got %d bytes", len(digest))
+	}
+
+	content := signedContent{contentType: oidData, digest: digest}
+	if !opts.OmitSigningTime {
+		signingTime := opts.SigningTime
+		content.signingTime = &signingTime
+	}
+
+	return signCMS(content, certificate, signer, append([]*x509.Certificate{certificate}, opts.IntermediateChains...))
+}
+
+// signedContent conteúdo coberto por uma assinatura CMS: destacado (somente o digest) ou encapsulado, como o TSTInfo
+// dos carimbos de tempo
+type signedContent struct {
+	contentType asn1.ObjectIdentifier
+	content     []byte
+	digest      []byte
+	signingTime *time.Time
+}
+
+// signCMS monta o SignedData com um único signatário e atributos assinados contentType, messageDigest e
+// signingCertificateV2; certificates vazio omite os certificados da estrutura
+func signCMS(sc signedContent, certificate *x509.Certificate, signer crypto.Signer, certificates []*x509.Certificate) ([]byte, error) {
+	signatureAlgorithm, err := signatureAlgorithmFor(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	certHash := sha256.Sum256(certificate.Raw)
+	signingCert, err := asn1.Marshal(signingCertificateV2{
+		Certs: []essCertIDv2{{
+			CertHash: certHash[:],
+			IssuerSerial: essIssuerSerial{
+				Issuer:       []asn1.RawValue{{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: certificate.RawIssuer}},
+				SerialNumber: certificate.SerialNumber,
+			},
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing certificate attribute: %w", err)
+	}
+
+	contentType, _ := asn1.Marshal(sc.contentType)
+	messageDigest, _ := asn1.Marshal(sc.digest)
+	attrs := []attribute{
+		newAttribute(oidAttrContentType, contentType),
+		newAttribute(oidAttrMessageDigest, messageDigest),
+		newAttribute(oidAttrSigningCertificateV2, signingCert),
+	}
+	if sc.signingTime != nil {
+		signingTime, err := asn1.Marshal(sc.signingTime.UTC())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode signing time: %w", err)
+		}
+		attrs = append(attrs, newAttribute(oidAttrSigningTime, signingTime))
+	}
+
+	signedAttrs, err := encodeAttributes(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	// A assinatura cobre o DER do SET OF atributos (tag universal 17), não a forma implícita [0]
+	attrsDigest := sha256.Sum256(signedAttrs)
+	signature, err := signer.Sign(rand.Reader, attrsDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign attributes: %w", err)
+	}
+
+	// RFC 5652: conteúdo diferente de id-data exige SignedData versão 3
+	sd := signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: encapsulatedContentInfo{EContentType: sc.contentType},
+		SignerInfos: []signerInfo{{
+			Version: 1,
+			SID: issuerAndSerialNumber{
+				Issuer:       asn1.RawValue{FullBytes: certificate.RawIssuer},
+				SerialNumber: certificate.SerialNumber,
+			},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedAttrs[headerLength(signedAttrs):]},
+			SignatureAlgorithm: signatureAlgorithm,
+			Signature:          signature,
+		}},
+	}
+	if !sc.contentType.Equal(oidData) {
+		sd.Version = 3
+	}
+	if sc.content != nil {
+		eContent, err := asn1.Marshal(sc.content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode content: %w", err)
+		}
+		sd.EncapContentInfo.EContent = asn1.RawValue{FullBytes: wrapExplicit(eContent)}
+	}
+	if len(certificates) > 0 {
+		var rawCertificates []byte
+		for _, cert := range certificates {
+			rawCertificates = append(rawCertificates, cert.Raw...)
+		}
+		sd.Certificates = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: rawCertificates}
+	}
+
+	return marshalSignedData(&sd)
+}
+
+// VerifyCAdES confere a assinatura CMS destacada contra o SHA-256 do documento e devolve o signatário.
+// A cadeia e a revogação do certificado são verificadas separadamente pelo chamador.
+func VerifyCAdES(signatureDER, digest []byte) (*CMSSignature, error) {
+	sd, err := parseSignedData(signatureDER)
+	if err != nil {
+		return nil, err
+	}
+
+	return sd.verify(func(hash crypto.Hash) ([]byte, error) {
+		if hash != crypto.SHA256 {
+			return nil, fmt.Errorf("%w: unsupported digest algorithm", ErrInvalidSignature)
+		}
+		return digest, nil
+	})
+}
+
+// AddSignatureTimestamp inclui o carimbo de tempo como atributo não assinado (signature-time-stamp), tornando a
+// assinatura CAdES-T; o carimbo deve cobrir o SHA-256 do valor da assinatura
+func AddSignatureTimestamp(signatureDER, token []byte) ([]byte, error) {
+	sd, err := parseSignedData(signatureDER)
+	if err != nil {
+		return nil, err
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, fmt.Errorf("%w: expected one signer, found %d", ErrInvalidSignature, len(sd.SignerInfos))
+	}
+
+	attr, err := asn1.Marshal(newAttribute(oidAttrTimestampToken, token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode timestamp attribute: %w", err)
+	}
+
+	si := &sd.SignerInfos[0]
+	si.UnsignedAttrs = asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        1,
+		IsCompound: true,
+		Bytes:      append(append([]byte{}, si.UnsignedAttrs.Bytes...), attr...),
+	}
+
+	return marshalSignedData(sd)
+}
+
+// SignatureTimestampToken devolve o carimbo de tempo da assinatura (nil se não houver) e o valor assinado que ele cobre
+func SignatureTimestampToken(signatureDER []byte) (token, signatureValue []byte, err error) {
+	sd, err := parseSignedData(signatureDER)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, nil, fmt.Errorf("%w: expected one signer, found %d", ErrInvalidSignature, len(sd.SignerInfos))
+	}
+
+	si := sd.SignerInfos[0]
+	rest := si.UnsignedAttrs.Bytes
+	for len(rest) > 0 {
+		var attr attribute
+		rest, err = asn1.Unmarshal(rest, &attr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+		}
+		if attr.Type.Equal(oidAttrTimestampToken) {
+			var value asn1.RawValue
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &value); err != nil {
+				return nil, nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+			}
+			return value.FullBytes, si.Signature, nil
+		}
+	}
+	return nil, si.Signature, nil
+}
+
+func parseSignedData(der []byte) (*signedData, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("%w: content type is not signedData", ErrInvalidSignature)
+	}
+
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return &sd, nil
+}
+
+func marshalSignedData(sd *signedData) ([]byte, error) {
+	content, err := asn1.Marshal(*sd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signed data: %w", err)
+	}
+
+	return asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{FullBytes: wrapExplicit(content)},
+	})
+}
+
+// encapsulatedContent conteúdo encapsulado em eContent (OCTET STRING)
+func (sd *signedData) encapsulatedContent() ([]byte, error) {
+	if len(sd.EncapContentInfo.EContent.Bytes) == 0 {
+		return nil, fmt.Errorf("%w: no encapsulated content", ErrInvalidSignature)
+	}
+	var content []byte
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent.Bytes, &content); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return content, nil
+}
+
+// verify confere o único signatário: messageDigest contra o digest do conteúdo no algoritmo declarado, contentType
+// contra o conteúdo encapsulado e a assinatura sobre os atributos assinados
+func (sd *signedData) verify(contentDigest func(crypto.Hash) ([]byte, error)) (*CMSSignature, error) {
+	if len(sd.SignerInfos) != 1 {
+		return nil, fmt.Errorf("%w: expected one signer, found %d", ErrInvalidSignature, len(sd.SignerInfos))
+	}
+
+	certificates, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	si := sd.SignerInfos[0]
+	var signer *x509.Certificate
+	for _, cert := range certificates {
+		if cert.SerialNumber.Cmp(si.SID.SerialNumber) == 0 && bytes.Equal(cert.RawIssuer, si.SID.Issuer.FullBytes) {
+			signer = cert
+			break
+		}
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("%w: signer certificate not included", ErrInvalidSignature)
+	}
+	if len(si.SignedAttrs.FullBytes) == 0 {
+		return nil, fmt.Errorf("%w: signed attributes are required", ErrInvalidSignature)
+	}
+
+	hash, ok := digestAlgorithms[si.DigestAlgorithm.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported digest algorithm %s", ErrInvalidSignature, si.DigestAlgorithm.Algorithm)
+	}
+
+	// Reconstruir o SET OF assinado a partir da forma implícita [0]
+	signedAttrs := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+
+	result := &CMSSignature{Signer: signer, Certificates: certificates}
+	var contentType asn1.ObjectIdentifier
+	rest := si.SignedAttrs.Bytes
+	for len(rest) > 0 {
+		var attr attribute
+		var err error
+		rest, err = asn1.Unmarshal(rest, &attr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+		}
+
+		switch {
+		case attr.Type.Equal(oidAttrContentType):
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &contentType); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+			}
+		case attr.Type.Equal(oidAttrMessageDigest):
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &result.Digest); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+			}
+		case attr.Type.Equal(oidAttrSigningTime):
+			var signingTime time.Time
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &signingTime); err == nil {
+				result.SigningTime = &signingTime
+			}
+		}
+	}
+	if !contentType.Equal(sd.EncapContentInfo.EContentType) {
+		return result, fmt.Errorf("%w: content type attribute does not match the content", ErrInvalidSignature)
+	}
+
+	digest, err := contentDigest(hash)
+	if err != nil {
+		return result, err
+	}
+	if !bytes.Equal(result.Digest, digest) {
+		return result, ErrDigestMismatch
+	}
+
+	if err := signer.CheckSignature(signatureAlgorithmOf(signer.PublicKey, hash), signedAttrs, si.Signature); err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	return result, nil
+}
+
+func newAttribute(attrType asn1.ObjectIdentifier, value []byte) attribute {
+	return attribute{
+		Type:   attrType,
+		Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: value},
+	}
+}
+
+// encodeAttributes codifica os atributos como SET OF em DER, com os elementos ordenados pela codificação
+func encodeAttributes(attrs []attribute) ([]byte, error) {
+	encoded := make([][]byte, 0, len(attrs))
+	for _, attr := range attrs {
+		der, err := asn1.Marshal(attr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode attribute %s: %w", attr.Type, err)
+		}
+		encoded = append(encoded, der)
+	}
+	sort.Slice(encoded, func(i, j int) bool { return bytes.Compare(encoded[i], encoded[j]) < 0 })
+
+	return asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: bytes.Join(encoded, nil)})
+}
+
+func signatureAlgorithmFor(publicKey crypto.PublicKey) (pkix.AlgorithmIdentifier, error) {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidSHA256WithRSA, Parameters: asn1.NullRawValue}, nil
+	case *ecdsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256}, nil
+	default:
+		return pkix.AlgorithmIdentifier{}, fmt.Errorf("unsupported signing key %T", publicKey)
+	}
+}
+
+// signatureAlgorithmOf algoritmo x509 correspondente à chave do signatário e ao resumo declarado
+func signatureAlgorithmOf(publicKey crypto.PublicKey, hash crypto.Hash) x509.SignatureAlgorithm {
+	_, isECDSA := publicKey.(*ecdsa.PublicKey)
+	switch {
+	case hash == crypto.SHA384 && isECDSA:
+		return x509.ECDSAWithSHA384
+	case hash == crypto.SHA384:
+		return x509.SHA384WithRSA
+	case hash == crypto.SHA512 && isECDSA:
+		return x509.ECDSAWithSHA512
+	case hash == crypto.SHA512:
+		return x509.SHA512WithRSA
+	case isECDSA:
+		return x509.ECDSAWithSHA256
+	default:
+		return x509.SHA256WithRSA
+	}
+}
+
+// headerLength tamanho do cabeçalho (tag + comprimento) de um elemento DER
+func headerLength(der []byte) int {
+	if der[1] < 0x80 {
+		return 2
+	}
+	return 2 + int(der[1]&0x7f)
+}
+
+// wrapExplicit envolve o conteúdo na tag explícita [0] exigida por ContentInfo
+func wrapExplicit(content []byte) []byte {
+	wrapped, _ := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content})
+	return wrapped
+}
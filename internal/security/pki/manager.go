@@ -2,63 +2,105 @@ package pki
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"math/big"
+	"sync"
 	"time"
 
 	"github.com/equinoid/backend/internal/models"
-	"github.com/google/uuid"
+	"golang.org/x/crypto/ocsp"
 	"gorm.io/gorm"
 )
 
+const (
+	// renewalWindow antecedência a partir da qual um certificado pode ser renovado
+	renewalWindow = 30 * 24 * time.Hour
+	// defaultCRLValidity intervalo entre thisUpdate e nextUpdate de cada CRL publicada
+	defaultCRLValidity = 24 * time.Hour
+	// ocspResponseValidity validade das respostas OCSP, que consultam o status atual no banco
+	ocspResponseValidity = time.Hour
+)
+
+var (
+	ErrCertificateNotFound = errors.New("certificate not found")
+	ErrCertificateExists   = errors.New("a valid certificate already exists for this subject")
+	ErrCertificateRevoked  = errors.New("certificate has been revoked")
+	ErrRenewalNotEligible  = errors.New("certificate not yet eligible for renewal")
+)
+
 // PKIManager gerencia todos os aspectos de PKI
 type PKIManager struct {
-	db        *gorm.DB
-	caService *CAService
+	db          *gorm.DB
+	caService   *CAService
+	crlValidity time.Duration
+	crlMu       sync.Mutex
+
+	tsa            *TimestampAuthority
+	timestamper    Timestamper
+	timestampRoots []*x509.Certificate
+
+	seal *SealAuthority
 }
 
 // NewPKIManager cria um novo gerenciador PKI
 func NewPKIManager(db *gorm.DB, caService *CAService) *PKIManager {
 	return &PKIManager{
-		db:        db,
-		caService: caService,
+		db:          db,
+		caService:   caService,
+		crlValidity: defaultCRLValidity,
 	}
 }
 
-// RequestCertificate solicita um novo certificado para um usuário
-func (p *PKIManager) RequestCertificate(ctx context.Context, req *CertificateRequest) (*models.Certificate, error) {
+// SetCRLValidity define por quanto tempo cada CRL publicada permanece válida
+func (p *PKIManager) SetCRLValidity(validity time.Duration) {
+	if validity > 0 {
+		p.crlValidity = validity
+	}
+}
+
+// RequestCertificate emite um certificado para o usuário: pela CSR quando informada, senão com par de chaves gerado no servidor.
+// Cada usuário pode ter apenas um certificado válido por nome comum.
+func (p *PKIManager) RequestCertificate(ctx context.Context, req *CertificateRequest, csrPEM []byte) (*CertificateResponse, error) {
 	// Verificar se usuário existe
 	var user models.User
-	if err := p.db.First(&user, req.UserID).Error; err != nil {
+	if err := p.db.WithContext(ctx).First(&user, req.UserID).Error; err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	// Verificar se usuário já tem certificado válido
-	var existingCert models.Certificate
-	err := p.db.Where("user_id = ? AND is_revoked = ? AND expires_at > ?",
-		req.UserID, false, time.Now()).First(&existingCert).Error
-
-	if err == nil {
-		return nil, fmt.Errorf("user already has a valid certificate")
-	} else if err != gorm.ErrRecordNotFound {
+	// Verificar se usuário já tem certificado válido para o mesmo assunto
+	var existing int64
+	if err := p.db.WithContext(ctx).Model(&models.Certificate{}).
+		Where("user_id = ? AND common_name = ? AND is_revoked = ? AND expires_at > ?",
+			req.UserID, req.CommonName, false, time.Now()).
+		Count(&existing).Error; err != nil {
 		return nil, fmt.Errorf("failed to check existing certificates: %w", err)
 	}
+	if existing > 0 {
+		return nil, ErrCertificateExists
+	}
 
-	// Emitir novo certificado
-	certResponse, err := p.caService.IssueCertificate(req)
+	if req.EmailAddress == "" {
+		req.EmailAddress = user.Email
+	}
+
+	certResponse, err := p.issue(req, csrPEM)
 	if err != nil {
-		return nil, fmt.Errorf("failed to issue certificate: %w", err)
+		return nil, err
 	}
 
 	// Salvar no banco de dados
-	if err := p.db.Create(certResponse.Certificate).Error; err != nil {
+	if err := p.db.WithContext(ctx).Create(certResponse.Certificate).Error; err != nil {
 		return nil, fmt.Errorf("failed to save certificate: %w", err)
 	}
 
-	return certResponse.Certificate, nil
+	return certResponse, nil
 }
 
 // GetUserCertificates retorna todos os certificados de um usuário
-func (p *PKIManager) GetUserCertificates(ctx context.Context, userID uuid.UUID) ([]models.Certificate, error) {
+func (p *PKIManager) GetUserCertificates(ctx context.Context, userID uint) ([]models.Certificate, error) {
 	var certificates []models.Certificate
 	err := p.db.Where("user_id = ?", userID).
 		Order("created_at DESC").
@@ -68,7 +110,7 @@ func (p *PKIManager) GetUserCertificates(ctx context.Context, userID uuid.UUID)
 }
 
 // GetActiveCertificate retorna o certificado ativo de um usuário
-func (p *PKIManager) GetActiveCertificate(ctx context.Context, userID uuid.UUID) (*models.Certificate, error) {
+func (p *PKIManager) GetActiveCertificate(ctx context.Context, userID uint) (*models.Certificate, error) {
 	var certificate models.Certificate
 	err := p.db.Where("user_id = ? AND is_revoked = ? AND expires_at > ?",
 		userID, false, time.Now()).
@@ -83,7 +125,7 @@ func (p *PKIManager) GetActiveCertificate(ctx context.Context, userID uuid.UUID)
 }
 
 // RevokeCertificate revoga um certificado
-func (p *PKIManager) RevokeCertificate(ctx context.Context, userID, certificateID uuid.UUID, reason string) error {
+func (p *PKIManager) RevokeCertificate(ctx context.Context, userID, certificateID uint, reason string) error {
 	var certificate models.Certificate
 	err := p.db.Where("id = ? AND user_id = ?", certificateID, userID).
 		First(&certificate).Error
@@ -110,7 +152,7 @@ func (p *PKIManager) RevokeCertificate(ctx context.Context, userID, certificateI
 }
 
 // VerifyCertificate verifica a validade de um certificado
-func (p *PKIManager) VerifyCertificate(ctx context.Context, certificateID uuid.UUID) (*CertificateInfo, error) {
+func (p *PKIManager) VerifyCertificate(ctx context.Context, certificateID uint) (*CertificateInfo, error) {
 	var certificate models.Certificate
 	if err := p.db.First(&certificate, certificateID).Error; err != nil {
 		return nil, fmt.Errorf("certificate not found: %w", err)
@@ -131,55 +173,219 @@ func (p *PKIManager) VerifyCertificate(ctx context.Context, certificateID uuid.U
 	return info, nil
 }
 
-// RenewCertificate renova um certificado próximo do vencimento
-func (p *PKIManager) RenewCertificate(ctx context.Context, userID, certificateID uuid.UUID) (*models.Certificate, error) {
+// RenewCertificate renova um certificado a menos de 30 dias do vencimento (ou já vencido), mantendo assunto, usos de chave
+// e período de validade. Com CSR a nova chave é do titular; sem CSR o servidor gera um novo par. O certificado anterior é
+// revogado como "superseded" na mesma transação em que o novo é gravado.
+func (p *PKIManager) RenewCertificate(ctx context.Context, userID, certificateID uint, csrPEM []byte) (*CertificateResponse, error) {
 	var oldCertificate models.Certificate
-	err := p.db.Where("id = ? AND user_id = ?", certificateID, userID).
+	err := p.db.WithContext(ctx).Where("id = ? AND user_id = ?", certificateID, userID).
 		First(&oldCertificate).Error
-
 	if err != nil {
-		return nil, fmt.Errorf("certificate not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCertificateNotFound
+		}
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	if oldCertificate.IsRevoked {
+		return nil, ErrCertificateRevoked
 	}
 
 	// Verificar se está próximo do vencimento (30 dias)
-	if time.Until(oldCertificate.ExpiresAt) > 30*24*time.Hour {
-		return nil, fmt.Errorf("certificate not yet eligible for renewal")
+	if time.Until(oldCertificate.ExpiresAt) > renewalWindow {
+		return nil, ErrRenewalNotEligible
+	}
+
+	req, err := p.renewalRequest(ctx, &oldCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Emitir novo certificado
+	certResponse, err := p.issue(req, csrPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew certificate: %w", err)
+	}
+	certResponse.Certificate.ReplacesID = &oldCertificate.ID
+
+	err = p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(certResponse.Certificate).Error; err != nil {
+			return fmt.Errorf("failed to save renewed certificate: %w", err)
+		}
+
+		// Revogar certificado antigo
+		if err := p.caService.RevokeCertificate(&oldCertificate, "superseded"); err != nil {
+			return fmt.Errorf("failed to revoke previous certificate: %w", err)
+		}
+		if err := tx.Save(&oldCertificate).Error; err != nil {
+			return fmt.Errorf("failed to update previous certificate: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return certResponse, nil
+}
+
+// renewalRequest reconstrói a solicitação original a partir do certificado emitido
+func (p *PKIManager) renewalRequest(ctx context.Context, certificate *models.Certificate) (*CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(certificate.CertificatePEM))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
 	}
 
-	// Buscar dados do usuário
 	var user models.User
-	if err := p.db.Where("id = ?", userID).First(&user).Error; err != nil {
+	if err := p.db.WithContext(ctx).Where("id = ?", certificate.UserID).First(&user).Error; err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
+
 	req := &CertificateRequest{
-		UserID:       user.ID,
-		CommonName:   oldCertificate.CommonName,
-		Organization: "Agent4 Security",
-		Country:      "BR",
-		Province:     "SP",
-		Locality:     "São Paulo",
-		EmailAddress: user.Email,
-		KeySize:      2048,
-		ValidityDays: 365,
-		KeyUsage:     []string{"digital_signature", "key_encipherment"},
+		UserID:          certificate.UserID,
+		CommonName:      certificate.CommonName,
+		EmailAddress:    user.Email,
+		ValidityDays:    int(cert.NotAfter.Sub(cert.NotBefore).Hours() / 24),
+		KeyUsage:        p.caService.formatKeyUsage(cert.KeyUsage),
+		Equinoid:        certificate.Equinoid,
+		CertificateType: certificate.CertificateType,
+	}
+	if len(cert.Subject.Organization) > 0 {
+		req.Organization = cert.Subject.Organization[0]
+	}
+	if len(cert.Subject.Country) > 0 {
+		req.Country = cert.Subject.Country[0]
 	}
+	if len(cert.Subject.Province) > 0 {
+		req.Province = cert.Subject.Province[0]
+	}
+	if len(cert.Subject.Locality) > 0 {
+		req.Locality = cert.Subject.Locality[0]
+	}
+	return req, nil
+}
 
-	// Emitir novo certificado
-	certResponse, err := p.caService.IssueCertificate(req)
+// issue emite pela CSR quando informada, senão com chave gerada no servidor
+func (p *PKIManager) issue(req *CertificateRequest, csrPEM []byte) (*CertificateResponse, error) {
+	if len(csrPEM) > 0 {
+		return p.caService.IssueFromCSR(req, csrPEM)
+	}
+	return p.caService.IssueCertificate(req)
+}
+
+// CACertificate retorna o certificado da CA em PEM para montagem da cadeia pelos clientes
+func (p *PKIManager) CACertificate() ([]byte, error) {
+	return p.caService.GetCACertificate()
+}
+
+// PublishCRL assina e grava uma nova CRL com todos os certificados revogados ainda dentro da validade.
+// Deve ser chamada após cada revogação; o job agendado a republica antes do nextUpdate.
+func (p *PKIManager) PublishCRL(ctx context.Context) (*models.CertificateRevocationList, error) {
+	if !p.caService.Ready() {
+		return nil, ErrCANotInitialized
+	}
+
+	p.crlMu.Lock()
+	defer p.crlMu.Unlock()
+
+	now := time.Now()
+
+	// Certificados vencidos deixam de constar na CRL (RFC 5280, seção 3.3)
+	var revoked []models.Certificate
+	if err := p.db.WithContext(ctx).
+		Where("is_revoked = ? AND expires_at > ?", true, now).
+		Order("revoked_at ASC").
+		Find(&revoked).Error; err != nil {
+		return nil, fmt.Errorf("failed to load revoked certificates: %w", err)
+	}
+
+	var lastNumber int64
+	if err := p.db.WithContext(ctx).Model(&models.CertificateRevocationList{}).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&lastNumber).Error; err != nil {
+		return nil, fmt.Errorf("failed to load CRL number: %w", err)
+	}
+
+	crl := &models.CertificateRevocationList{
+		Number:       lastNumber + 1,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(p.crlValidity),
+		RevokedCount: len(revoked),
+	}
+
+	crlDER, err := p.caService.CreateCRL(revoked, big.NewInt(crl.Number), crl.ThisUpdate, crl.NextUpdate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to renew certificate: %w", err)
+		return nil, err
+	}
+	crl.CRLDER = crlDER
+
+	if err := p.db.WithContext(ctx).Create(crl).Error; err != nil {
+		return nil, fmt.Errorf("failed to save CRL: %w", err)
+	}
+
+	return crl, nil
+}
+
+// CurrentCRL retorna a última CRL publicada, publicando uma nova se não houver nenhuma ou se a atual já venceu
+func (p *PKIManager) CurrentCRL(ctx context.Context) (*models.CertificateRevocationList, error) {
+	var crl models.CertificateRevocationList
+	err := p.db.WithContext(ctx).Order("number DESC").First(&crl).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load CRL: %w", err)
+	}
+	if err == nil && time.Now().Before(crl.NextUpdate) {
+		return &crl, nil
 	}
 
-	// Salvar novo certificado
-	if err := p.db.Create(certResponse.Certificate).Error; err != nil {
-		return nil, fmt.Errorf("failed to save renewed certificate: %w", err)
+	return p.PublishCRL(ctx)
+}
+
+// OCSPResponse responde a uma requisição OCSP (RFC 6960) em DER com o status atual do certificado.
+// Requisições malformadas ou de outro emissor recebem as respostas de erro assinadas previstas na RFC.
+func (p *PKIManager) OCSPResponse(ctx context.Context, requestDER []byte) ([]byte, error) {
+	if !p.caService.Ready() {
+		return nil, ErrCANotInitialized
 	}
 
-	// Revogar certificado antigo
-	p.caService.RevokeCertificate(&oldCertificate, "superseded")
-	p.db.Save(&oldCertificate)
+	req, err := ocsp.ParseRequest(requestDER)
+	if err != nil {
+		return ocsp.MalformedRequestErrorResponse, nil
+	}
+	if !p.caService.IssuedByCA(req) {
+		return ocsp.UnauthorizedErrorResponse, nil
+	}
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	template := ocsp.Response{
+		Status:       ocsp.Unknown,
+		SerialNumber: req.SerialNumber,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(ocspResponseValidity),
+		IssuerHash:   req.HashAlgorithm,
+	}
+
+	var certificate models.Certificate
+	err = p.db.WithContext(ctx).Where("serial_number = ?", req.SerialNumber.String()).First(&certificate).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	case certificate.IsRevoked:
+		template.Status = ocsp.Revoked
+		template.RevokedAt = certificate.UpdatedAt.UTC()
+		if certificate.RevokedAt != nil {
+			template.RevokedAt = certificate.RevokedAt.UTC()
+		}
+		template.RevocationReason = RevocationReasonCode(certificate.RevocationReason)
+	default:
+		template.Status = ocsp.Good
+	}
 
-	return certResponse.Certificate, nil
+	return p.caService.SignOCSPResponse(template)
 }
 
 // ListExpiringCertificates lista certificados que vencerão em X dias
@@ -236,7 +442,7 @@ func (p *PKIManager) GetCertificateStats(ctx context.Context) (map[string]interf
 }
 
 // ExportCertificate exporta um certificado para diferentes formatos
-func (p *PKIManager) ExportCertificate(ctx context.Context, certificateID uuid.UUID, format string) ([]byte, error) {
+func (p *PKIManager) ExportCertificate(ctx context.Context, certificateID uint, format string) ([]byte, error) {
 	var certificate models.Certificate
 	if err := p.db.First(&certificate, certificateID).Error; err != nil {
 		return nil, fmt.Errorf("certificate not found: %w", err)
@@ -257,7 +463,7 @@ func (p *PKIManager) ExportCertificate(ctx context.Context, certificateID uuid.U
 }
 
 // ValidateCertificateChain valida a cadeia de um certificado
-func (p *PKIManager) ValidateCertificateChain(ctx context.Context, certificateID uuid.UUID) error {
+func (p *PKIManager) ValidateCertificateChain(ctx context.Context, certificateID uint) error {
 	var certificate models.Certificate
 	if err := p.db.First(&certificate, certificateID).Error; err != nil {
 		return fmt.Errorf("certificate not found: %w", err)
@@ -267,7 +473,7 @@ func (p *PKIManager) ValidateCertificateChain(ctx context.Context, certificateID
 }
 
 // ImportCertificate importa um certificado externo
-func (p *PKIManager) ImportCertificate(ctx context.Context, userID uuid.UUID, certPEM []byte) (*models.Certificate, error) {
+func (p *PKIManager) ImportCertificate(ctx context.Context, userID uint, certPEM []byte) (*models.Certificate, error) {
 	// Verificar o certificado
 	info, err := p.caService.VerifyCertificate(certPEM)
 	if err != nil {
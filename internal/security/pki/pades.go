@@ -0,0 +1,455 @@
+package pki
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+// padesContentsSize bytes reservados em /Contents para o CMS (certificados da cadeia e carimbo de tempo da assinatura)
+const padesContentsSize = 16384
+
+var (
+	ErrInvalidPDF        = errors.New("invalid or unsupported PDF")
+	ErrEncryptedPDF      = errors.New("encrypted PDFs cannot be signed")
+	ErrPDFNotSigned      = errors.New("PDF has no signatures")
+	ErrSignatureTooLarge = errors.New("CMS signature does not fit the reserved space")
+)
+
+var (
+	reStartXref  = regexp.MustCompile(`startxref\s+(\d+)`)
+	reRoot       = regexp.MustCompile(`/Root\s+(\d+)\s+(\d+)\s+R`)
+	reSize       = regexp.MustCompile(`/Size\s+(\d+)`)
+	reID         = regexp.MustCompile(`/ID\s*\[[^\]]*\]`)
+	reObjHeader  = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	reAcroRef    = regexp.MustCompile(`/AcroForm\s+(\d+)\s+(\d+)\s+R`)
+	reSigFlags   = regexp.MustCompile(`/SigFlags\s+\d+`)
+	reByteRange  = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+	reSigDate    = regexp.MustCompile(`/M\s*\(D:(\d{14})`)
+	reObjStmN    = regexp.MustCompile(`/N\s+(\d+)`)
+	reObjStmHead = regexp.MustCompile(`/First\s+(\d+)`)
+)
+
+// PDFSignatureOptions dados do dicionário de assinatura gravado no PDF
+type PDFSignatureOptions struct {
+	Name        string
+	Reason      string
+	Location    string
+	SigningTime time.Time
+}
+
+// PDFSignature assinatura encontrada em um PDF com os bytes que ela cobre
+type PDFSignature struct {
+	CMS                 []byte
+	Digest              []byte
+	SigningTime         *time.Time
+	CoversWholeDocument bool
+}
+
+// SignPDF acrescenta ao PDF uma assinatura PAdES (ETSI.CAdES.detached) por atualização incremental, preservando os
+// bytes originais. sign recebe o SHA-256 dos intervalos cobertos por /ByteRange e devolve o CMS em DER.
+func SignPDF(pdf []byte, opts PDFSignatureOptions, sign func(digest []byte) ([]byte, error)) ([]byte, error) {
+	trailer, err := readTrailer(pdf)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := findObject(pdf, trailer.rootNum, trailer.rootGen)
+	if err != nil {
+		return nil, fmt.Errorf("%w: catalog: %v", ErrInvalidPDF, err)
+	}
+
+	sigNum := trailer.size
+	fieldNum := trailer.size + 1
+	newSize := trailer.size + 2
+	fieldRef := fmt.Sprintf("%d 0 R", fieldNum)
+
+	var buf bytes.Buffer
+	buf.Write(pdf)
+	if len(pdf) > 0 && pdf[len(pdf)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	offsets := map[int]int{}
+	gens := map[int]int{}
+
+	// Dicionário de assinatura com espaço reservado para ByteRange e Contents
+	offsets[sigNum] = buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /ETSI.CAdES.detached /ByteRange ", sigNum)
+	byteRangePos := buf.Len()
+	buf.WriteString("[0 0000000000 0000000000 0000000000]")
+	buf.WriteString(" /Contents ")
+	contentsStart := buf.Len()
+	buf.WriteByte('<')
+	buf.Write(bytes.Repeat([]byte("0"), padesContentsSize*2))
+	buf.WriteByte('>')
+	contentsEnd := buf.Len()
+	fmt.Fprintf(&buf, " /M (D:%s+00'00')", opts.SigningTime.UTC().Format("20060102150405"))
+	if opts.Name != "" {
+		fmt.Fprintf(&buf, " /Name %s", pdfTextString(opts.Name))
+	}
+	if opts.Reason != "" {
+		fmt.Fprintf(&buf, " /Reason %s", pdfTextString(opts.Reason))
+	}
+	if opts.Location != "" {
+		fmt.Fprintf(&buf, " /Location %s", pdfTextString(opts.Location))
+	}
+	buf.WriteString(" >>\nendobj\n")
+
+	// Campo de assinatura invisível
+	offsets[fieldNum] = buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<< /FT /Sig /Type /Annot /Subtype /Widget /T %s /V %d 0 R /Rect [0 0 0 0] /F 132 >>\nendobj\n",
+		fieldNum, pdfTextString(fmt.Sprintf("Assinatura%d", sigNum)), sigNum)
+
+	// Registrar o campo no AcroForm: reescreve o objeto AcroForm ou o catálogo
+	if m := reAcroRef.FindSubmatch(catalog); m != nil {
+		acroNum, _ := strconv.Atoi(string(m[1]))
+		acroGen, _ := strconv.Atoi(string(m[2]))
+		acroForm, err := findObject(pdf, acroNum, acroGen)
+		if err != nil {
+			return nil, fmt.Errorf("%w: AcroForm: %v", ErrInvalidPDF, err)
+		}
+		updated, err := addSignatureField(acroForm, fieldRef)
+		if err != nil {
+			return nil, err
+		}
+		offsets[acroNum] = buf.Len()
+		gens[acroNum] = acroGen
+		fmt.Fprintf(&buf, "%d %d obj\n%s\nendobj\n", acroNum, acroGen, updated)
+	} else {
+		updated, err := addAcroForm(catalog, fieldRef)
+		if err != nil {
+			return nil, err
+		}
+		offsets[trailer.rootNum] = buf.Len()
+		gens[trailer.rootNum] = trailer.rootGen
+		fmt.Fprintf(&buf, "%d %d obj\n%s\nendobj\n", trailer.rootNum, trailer.rootGen, updated)
+	}
+
+	// Tabela xref da atualização, uma subseção por objeto
+	xrefOffset := buf.Len()
+	buf.WriteString("xref\n")
+	for _, num := range sortedKeys(offsets) {
+		fmt.Fprintf(&buf, "%d 1\n%010d %05d n \n", num, offsets[num], gens[num])
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d %d R /Prev %d", newSize, trailer.rootNum, trailer.rootGen, trailer.startXref)
+	if trailer.id != "" {
+		buf.WriteString(" " + trailer.id)
+	}
+	fmt.Fprintf(&buf, " >>\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	signed := buf.Bytes()
+
+	byteRange := fmt.Sprintf("[0 %010d %010d %010d]", contentsStart, contentsEnd, len(signed)-contentsEnd)
+	copy(signed[byteRangePos:], byteRange)
+
+	h := sha256.New()
+	h.Write(signed[:contentsStart])
+	h.Write(signed[contentsEnd:])
+
+	cms, err := sign(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	if len(cms) > padesContentsSize {
+		return nil, ErrSignatureTooLarge
+	}
+	hex.Encode(signed[contentsStart+1:], cms)
+
+	return signed, nil
+}
+
+// ExtractPDFSignatures localiza as assinaturas do PDF e calcula o SHA-256 dos bytes cobertos por cada uma
+func ExtractPDFSignatures(pdf []byte) ([]PDFSignature, error) {
+	matches := reByteRange.FindAllSubmatchIndex(pdf, -1)
+	if len(matches) == 0 {
+		return nil, ErrPDFNotSigned
+	}
+
+	signatures := make([]PDFSignature, 0, len(matches))
+	for _, m := range matches {
+		var r [4]int
+		for i := range r {
+			r[i], _ = strconv.Atoi(string(pdf[m[2+2*i]:m[3+2*i]]))
+		}
+		contentsStart, contentsEnd, tailLen := r[1], r[2], r[3]
+		if r[0] != 0 || contentsStart >= contentsEnd || contentsEnd+tailLen > len(pdf) ||
+			pdf[contentsStart] != '<' || pdf[contentsEnd-1] != '>' {
+			return nil, fmt.Errorf("%w: malformed ByteRange", ErrInvalidPDF)
+		}
+
+		// O espaço reservado é completado com zeros após o DER, que o parser ASN.1 ignora
+		cmsHex := pdf[contentsStart+1 : contentsEnd-1]
+		cms := make([]byte, hex.DecodedLen(len(cmsHex)))
+		if _, err := hex.Decode(cms, cmsHex); err != nil {
+			return nil, fmt.Errorf("%w: Contents: %v", ErrInvalidPDF, err)
+		}
+
+		h := sha256.New()
+		h.Write(pdf[:contentsStart])
+		h.Write(pdf[contentsEnd : contentsEnd+tailLen])
+
+		sig := PDFSignature{
+			CMS:                 cms,
+			Digest:              h.Sum(nil),
+			CoversWholeDocument: contentsEnd+tailLen == len(pdf),
+		}
+
+		// /M fica no mesmo dicionário, logo após /Contents
+		if d := reSigDate.FindSubmatch(pdf[contentsEnd:min(len(pdf), contentsEnd+512)]); d != nil {
+			if t, err := time.Parse("20060102150405", string(d[1])); err == nil {
+				sig.SigningTime = &t
+			}
+		}
+		signatures = append(signatures, sig)
+	}
+
+	return signatures, nil
+}
+
+type pdfTrailer struct {
+	rootNum   int
+	rootGen   int
+	size      int
+	startXref int
+	id        string
+}
+
+// readTrailer lê o último trailer (tabela xref clássica ou xref stream)
+func readTrailer(pdf []byte) (*pdfTrailer, error) {
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: missing PDF header", ErrInvalidPDF)
+	}
+
+	all := reStartXref.FindAllSubmatch(pdf, -1)
+	if len(all) == 0 {
+		return nil, fmt.Errorf("%w: startxref not found", ErrInvalidPDF)
+	}
+	startXref, _ := strconv.Atoi(string(all[len(all)-1][1]))
+	if startXref <= 0 || startXref >= len(pdf) {
+		return nil, fmt.Errorf("%w: invalid startxref", ErrInvalidPDF)
+	}
+
+	section := pdf[startXref:]
+	var dict []byte
+	if bytes.HasPrefix(section, []byte("xref")) {
+		idx := bytes.Index(section, []byte("trailer"))
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: trailer not found", ErrInvalidPDF)
+		}
+		dict = readDict(section[idx:])
+	} else {
+		dict = readDict(section)
+	}
+	if dict == nil {
+		return nil, fmt.Errorf("%w: trailer dictionary not found", ErrInvalidPDF)
+	}
+	if bytes.Contains(dict, []byte("/Encrypt")) {
+		return nil, ErrEncryptedPDF
+	}
+
+	root := reRoot.FindSubmatch(dict)
+	size := reSize.FindSubmatch(dict)
+	if root == nil || size == nil {
+		return nil, fmt.Errorf("%w: trailer without /Root or /Size", ErrInvalidPDF)
+	}
+
+	trailer := &pdfTrailer{startXref: startXref}
+	trailer.rootNum, _ = strconv.Atoi(string(root[1]))
+	trailer.rootGen, _ = strconv.Atoi(string(root[2]))
+	trailer.size, _ = strconv.Atoi(string(size[1]))
+	if id := reID.Find(dict); id != nil {
+		trailer.id = string(id)
+	}
+	return trailer, nil
+}
+
+// findObject devolve o dicionário da versão mais recente do objeto, buscando também em object streams
+func findObject(pdf []byte, num, gen int) ([]byte, error) {
+	header := regexp.MustCompile(fmt.Sprintf(`(?:^|[^\d])%d\s+%d\s+obj\b`, num, gen))
+	if matches := header.FindAllIndex(pdf, -1); len(matches) > 0 {
+		last := matches[len(matches)-1]
+		if dict := readDict(pdf[last[1]:]); dict != nil {
+			return dict, nil
+		}
+	}
+
+	if gen == 0 {
+		if dict := findInObjectStreams(pdf, num); dict != nil {
+			return dict, nil
+		}
+	}
+	return nil, fmt.Errorf("object %d %d not found", num, gen)
+}
+
+// findInObjectStreams procura o objeto em object streams (/Type /ObjStm) comprimidos com FlateDecode
+func findInObjectStreams(pdf []byte, num int) []byte {
+	var found []byte
+	for _, loc := range reObjHeader.FindAllIndex(pdf, -1) {
+		dict := readDict(pdf[loc[1]:])
+		if dict == nil || !bytes.Contains(dict, []byte("/ObjStm")) || !bytes.Contains(dict, []byte("/FlateDecode")) {
+			continue
+		}
+
+		rest := pdf[loc[1]:]
+		start := bytes.Index(rest, []byte("stream"))
+		end := bytes.Index(rest, []byte("endstream"))
+		if start < 0 || end < start {
+			continue
+		}
+		data := rest[start+len("stream") : end]
+		data = bytes.TrimLeft(data, "\r\n")
+
+		reader, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		decoded, err := io.ReadAll(reader)
+		if err != nil && len(decoded) == 0 {
+			continue
+		}
+
+		n, first := reObjStmN.FindSubmatch(dict), reObjStmHead.FindSubmatch(dict)
+		if n == nil || first == nil {
+			continue
+		}
+		count, _ := strconv.Atoi(string(n[1]))
+		firstOffset, _ := strconv.Atoi(string(first[1]))
+		if firstOffset > len(decoded) {
+			continue
+		}
+
+		fields := bytes.Fields(decoded[:firstOffset])
+		for i := 0; i+1 < len(fields) && i/2 < count; i += 2 {
+			objNum, _ := strconv.Atoi(string(fields[i]))
+			if objNum != num {
+				continue
+			}
+			offset, _ := strconv.Atoi(string(fields[i+1]))
+			if firstOffset+offset < len(decoded) {
+				// Versões posteriores sobrescrevem as anteriores
+				found = readDict(decoded[firstOffset+offset:])
+			}
+		}
+	}
+	return found
+}
+
+// readDict extrai o primeiro dicionário << ... >> balanceado a partir de data
+func readDict(data []byte) []byte {
+	start := bytes.Index(data, []byte("<<"))
+	if start < 0 {
+		return nil
+	}
+	depth := 0
+	for i := start; i < len(data)-1; i++ {
+		switch {
+		case data[i] == '(':
+			// Pular strings literais, que podem conter delimitadores
+			i = skipLiteralString(data, i)
+		case data[i] == '<' && data[i+1] == '<':
+			depth++
+			i++
+		case data[i] == '>' && data[i+1] == '>':
+			depth--
+			i++
+			if depth == 0 {
+				return data[start : i+1]
+			}
+		}
+	}
+	return nil
+}
+
+func skipLiteralString(data []byte, i int) int {
+	depth := 0
+	for ; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// addAcroForm acrescenta ao catálogo um AcroForm com o campo de assinatura, ou atualiza o AcroForm embutido
+func addAcroForm(catalog []byte, fieldRef string) ([]byte, error) {
+	idx := bytes.Index(catalog, []byte("/AcroForm"))
+	if idx < 0 {
+		body := bytes.TrimSuffix(catalog, []byte(">>"))
+		return []byte(fmt.Sprintf("%s /AcroForm << /Fields [%s] /SigFlags 3 >> >>", body, fieldRef)), nil
+	}
+
+	inline := readDict(catalog[idx:])
+	if inline == nil {
+		return nil, fmt.Errorf("%w: unsupported AcroForm", ErrInvalidPDF)
+	}
+	updated, err := addSignatureField(inline, fieldRef)
+	if err != nil {
+		return nil, err
+	}
+	pos := idx + bytes.Index(catalog[idx:], inline)
+	return append(append(append([]byte{}, catalog[:pos]...), updated...), catalog[pos+len(inline):]...), nil
+}
+
+// addSignatureField inclui o campo em /Fields e define /SigFlags 3 (SignaturesExist | AppendOnly)
+func addSignatureField(acroForm []byte, fieldRef string) ([]byte, error) {
+	var updated []byte
+	if idx := bytes.Index(acroForm, []byte("/Fields")); idx >= 0 {
+		open := bytes.IndexByte(acroForm[idx:], '[')
+		if open < 0 {
+			return nil, fmt.Errorf("%w: indirect /Fields array is not supported", ErrInvalidPDF)
+		}
+		closing := bytes.IndexByte(acroForm[idx+open:], ']')
+		if closing < 0 {
+			return nil, fmt.Errorf("%w: malformed /Fields", ErrInvalidPDF)
+		}
+		pos := idx + open + closing
+		updated = append(append(append([]byte{}, acroForm[:pos]...), []byte(" "+fieldRef)...), acroForm[pos:]...)
+	} else {
+		body := bytes.TrimSuffix(acroForm, []byte(">>"))
+		updated = append(append([]byte{}, body...), []byte(fmt.Sprintf(" /Fields [%s] >>", fieldRef))...)
+	}
+
+	if reSigFlags.Match(updated) {
+		return reSigFlags.ReplaceAll(updated, []byte("/SigFlags 3")), nil
+	}
+	body := bytes.TrimSuffix(updated, []byte(">>"))
+	return append(append([]byte{}, body...), []byte(" /SigFlags 3 >>")...), nil
+}
+
+// pdfTextString codifica texto como string hexadecimal UTF-16BE, aceita por qualquer leitor PDF
+func pdfTextString(s string) string {
+	var buf bytes.Buffer
+	buf.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&buf, "%04X", u)
+	}
+	buf.WriteString(">")
+	return buf.String()
+}
+
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
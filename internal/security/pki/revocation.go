@@ -0,0 +1,117 @@
+package pki
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"golang.org/x/crypto/ocsp"
+)
+
+// revocationReasonCodes motivos de revogação aceitos e seus códigos na CRL e no OCSP (RFC 5280, seção 5.3.1)
+var revocationReasonCodes = map[string]int{
+	"unspecified":            ocsp.Unspecified,
+	"key_compromise":         ocsp.KeyCompromise,
+	"ca_compromise":          ocsp.CACompromise,
+	"affiliation_changed":    ocsp.AffiliationChanged,
+	"superseded":             ocsp.Superseded,
+	"cessation_of_operation": ocsp.CessationOfOperation,
+	"privilege_withdrawn":    ocsp.PrivilegeWithdrawn,
+}
+
+// RevocationReasonCode converte o motivo registrado no certificado para o código RFC 5280; motivos livres viram "unspecified"
+func RevocationReasonCode(reason string) int {
+	if code, ok := revocationReasonCodes[reason]; ok {
+		return code
+	}
+	return ocsp.Unspecified
+}
+
+// SetDistributionPoints define as URLs públicas de CRL e OCSP gravadas nos certificados emitidos
+func (ca *CAService) SetDistributionPoints(crlURL, ocspURL string) {
+	ca.crlURL = crlURL
+	ca.ocspURL = ocspURL
+}
+
+// CreateCRL assina com a chave da CA uma lista de certificados revogados (DER)
+func (ca *CAService) CreateCRL(revoked []models.Certificate, number *big.Int, thisUpdate, nextUpdate time.Time) ([]byte, error) {
+	if !ca.Ready() {
+		return nil, ErrCANotInitialized
+	}
+
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, certificate := range revoked {
+		serial, ok := new(big.Int).SetString(certificate.SerialNumber, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid serial number %q", certificate.SerialNumber)
+		}
+
+		revokedAt := certificate.UpdatedAt
+		if certificate.RevokedAt != nil {
+			revokedAt = *certificate.RevokedAt
+		}
+
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: revokedAt.UTC(),
+			ReasonCode:     RevocationReasonCode(certificate.RevocationReason),
+		})
+	}
+
+	template := &x509.RevocationList{
+		Number:                    number,
+		ThisUpdate:                thisUpdate.UTC(),
+		NextUpdate:                nextUpdate.UTC(),
+		RevokedCertificateEntries: entries,
+	}
+
+	crlBytes, err := x509.CreateRevocationList(rand.Reader, template, ca.caCert, ca.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CRL: %w", err)
+	}
+	return crlBytes, nil
+}
+
+// IssuedByCA indica se a requisição OCSP se refere a certificados desta CA (hashes do nome e da chave do emissor)
+func (ca *CAService) IssuedByCA(req *ocsp.Request) bool {
+	if !ca.Ready() || !req.HashAlgorithm.Available() {
+		return false
+	}
+
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(ca.caCert.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return false
+	}
+
+	return equalHash(req.HashAlgorithm, ca.caCert.RawSubject, req.IssuerNameHash) &&
+		equalHash(req.HashAlgorithm, spki.PublicKey.RightAlign(), req.IssuerKeyHash)
+}
+
+// SignOCSPResponse assina com a chave da CA a resposta OCSP; a própria CA atua como respondedor
+func (ca *CAService) SignOCSPResponse(template ocsp.Response) ([]byte, error) {
+	if !ca.Ready() {
+		return nil, ErrCANotInitialized
+	}
+
+	response, err := ocsp.CreateResponse(ca.caCert, ca.caCert, template, ca.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCSP response: %w", err)
+	}
+	return response, nil
+}
+
+func equalHash(algorithm crypto.Hash, data, expected []byte) bool {
+	h := algorithm.New()
+	h.Write(data)
+	return bytes.Equal(h.Sum(nil), expected)
+}
@@ -0,0 +1,134 @@
+package pki
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSealUnavailable selo institucional não configurado ou sem chave carregada
+var ErrSealUnavailable = errors.New("institutional seal unavailable")
+
+const (
+	// sealCertValidity validade do certificado do selo, limitada à da CA
+	sealCertValidity = 3 * 365 * 24 * time.Hour
+	// sealRenewBefore antecedência com que o certificado do selo é reemitido na inicialização
+	sealRenewBefore = 30 * 24 * time.Hour
+	sealKeyBits     = 3072
+)
+
+// SealAuthority selo eletrônico da plataforma: chave própria com certificado emitido pela CA interna, usada para
+// assinar os documentos emitidos em nome do registro (passaportes, certidões de genealogia)
+type SealAuthority struct {
+	certPath string
+	keyPath  string
+	ca       *CAService
+
+	mu   sync.RWMutex
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+}
+
+// NewSealAuthority cria o selo institucional; o certificado e a chave são carregados ou gerados em Initialize
+func NewSealAuthority(certPath, keyPath string, ca *CAService) *SealAuthority {
+	return &SealAuthority{
+		certPath: certPath,
+		keyPath:  keyPath,
+		ca:       ca,
+	}
+}
+
+// Initialize carrega o certificado do selo ou emite um novo pela CA quando ausente, próximo do vencimento ou emitido
+// por outra CA
+func (s *SealAuthority) Initialize() error {
+	if !s.ca.Ready() || s.ca.encryptionService == nil {
+		return ErrCANotInitialized
+	}
+
+	k := s.serviceKey()
+	cert, key, err := k.load()
+	if err != nil || k.needsRenewal(cert, sealRenewBefore) {
+		cert, key, err = k.create(&x509.Certificate{
+			Subject:  k.subjectFor("Selo Institucional"),
+			KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+		}, sealKeyBits, sealCertValidity)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.cert, s.key = cert, key
+	s.mu.Unlock()
+	return nil
+}
+
+// Ready indica se o selo tem certificado e chave carregados
+func (s *SealAuthority) Ready() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cert != nil && s.key != nil
+}
+
+// Certificate certificado atual do selo
+func (s *SealAuthority) Certificate() *x509.Certificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cert
+}
+
+func (s *SealAuthority) credentials() (*x509.Certificate, *rsa.PrivateKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.cert == nil || s.key == nil {
+		return nil, nil, ErrSealUnavailable
+	}
+	return s.cert, s.key, nil
+}
+
+func (s *SealAuthority) serviceKey() serviceKey {
+	return serviceKey{label: "seal", certPath: s.certPath, keyPath: s.keyPath, ca: s.ca}
+}
+
+// SetSealAuthority registra o selo institucional usado em SealPDF
+func (p *PKIManager) SetSealAuthority(seal *SealAuthority) {
+	p.seal = seal
+}
+
+// SealPDF assina o PDF com o selo institucional (PAdES com carimbo de tempo, quando disponível)
+func (p *PKIManager) SealPDF(ctx context.Context, pdf []byte, opts PDFSignatureOptions) ([]byte, error) {
+	if p.seal == nil {
+		return nil, ErrSealUnavailable
+	}
+	cert, key, err := p.seal.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Name == "" {
+		opts.Name = cert.Subject.CommonName
+	}
+	return SignPDF(pdf, opts, func(digest []byte) ([]byte, error) {
+		signature, err := SignCAdES(digest, cert, key, CAdESOptions{
+			OmitSigningTime:    true,
+			IntermediateChains: []*x509.Certificate{p.caService.caCert},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return p.addSignatureTimestamp(ctx, signature)
+	})
+}
+
+// isSealCertificate indica se o signatário é o certificado do selo institucional, que não consta da tabela de
+// certificados de usuários
+func (p *PKIManager) isSealCertificate(signer *x509.Certificate) bool {
+	if p.seal == nil {
+		return false
+	}
+	cert := p.seal.Certificate()
+	return cert != nil && cert.Equal(signer)
+}
@@ -0,0 +1,136 @@
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// serviceKey chave de um serviço da plataforma (TSA, selo institucional) com certificado emitido pela CA interna e
+// chave privada cifrada em disco
+type serviceKey struct {
+	label    string
+	certPath string
+	keyPath  string
+	ca       *CAService
+}
+
+// needsRenewal indica se o certificado deve ser reemitido: ausente, emitido por outra CA ou próximo do vencimento
+func (k serviceKey) needsRenewal(cert *x509.Certificate, renewBefore time.Duration) bool {
+	return cert == nil || cert.CheckSignatureFrom(k.ca.caCert) != nil || time.Until(cert.NotAfter) < renewBefore
+}
+
+func (k serviceKey) load() (*x509.Certificate, *rsa.PrivateKey, error) {
+	certPEM, err := os.ReadFile(k.certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, nil, fmt.Errorf("failed to decode %s certificate", k.label)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s certificate: %w", k.label, err)
+	}
+
+	keyPEM, err := os.ReadFile(k.keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("failed to decode %s private key", k.label)
+	}
+	keyBytes, err := k.ca.encryptionService.DecryptBytes(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt %s private key: %w", k.label, err)
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s private key: %w", k.label, err)
+	}
+
+	return cert, key, nil
+}
+
+// create gera a chave, emite o certificado do template pela CA (validade limitada à da CA, pontos de distribuição da
+// CA) e grava certificado e chave cifrada nos caminhos configurados
+func (k serviceKey) create(template *x509.Certificate, keyBits int, validity time.Duration) (*x509.Certificate, *rsa.PrivateKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate %s key: %w", k.label, err)
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	notAfter := now.Add(validity)
+	if notAfter.After(k.ca.caCert.NotAfter) {
+		notAfter = k.ca.caCert.NotAfter
+	}
+
+	template.SerialNumber = serial
+	template.NotBefore = now
+	template.NotAfter = notAfter
+	template.BasicConstraintsValid = true
+	if k.ca.crlURL != "" {
+		template.CRLDistributionPoints = []string{k.ca.crlURL}
+	}
+	if k.ca.ocspURL != "" {
+		template.OCSPServer = []string{k.ca.ocspURL}
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, k.ca.caCert, &key.PublicKey, k.ca.caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create %s certificate: %w", k.label, err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s certificate: %w", k.label, err)
+	}
+
+	encryptedKey, err := k.ca.encryptionService.EncryptBytes(x509.MarshalPKCS1PrivateKey(key))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encrypt %s private key: %w", k.label, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(k.certPath), 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create %s directory: %w", k.label, err)
+	}
+	if err := os.WriteFile(k.certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644); err != nil {
+		return nil, nil, fmt.Errorf("failed to write %s certificate: %w", k.label, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(k.keyPath), 0700); err != nil {
+		return nil, nil, fmt.Errorf("failed to create %s key directory: %w", k.label, err)
+	}
+	if err := os.WriteFile(k.keyPath, pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED RSA PRIVATE KEY", Bytes: encryptedKey}), 0600); err != nil {
+		return nil, nil, fmt.Errorf("failed to write %s private key: %w", k.label, err)
+	}
+
+	return cert, key, nil
+}
+
+// subjectFor nome do certificado de serviço na organização da CA
+func (k serviceKey) subjectFor(unit string) pkix.Name {
+	organization := k.ca.caCert.Subject.Organization
+	commonName := unit
+	if len(organization) > 0 {
+		commonName = organization[0] + " " + unit
+	}
+	return pkix.Name{
+		CommonName:         commonName,
+		Organization:       organization,
+		OrganizationalUnit: []string{unit},
+		Country:            k.ca.caCert.Subject.Country,
+	}
+}
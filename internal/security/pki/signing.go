@@ -0,0 +1,365 @@
+package pki
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrServerKeyUnavailable o certificado foi emitido por CSR e a chave privada está somente com o titular
+var ErrServerKeyUnavailable = errors.New("certificate private key is not held by the server")
+
+// CertificateStatusUnknown certificado que não consta entre os emitidos pela CA interna
+const CertificateStatusUnknown = "unknown"
+
+// SignatureVerification resultado da verificação de uma assinatura CAdES ou PAdES
+type SignatureVerification struct {
+	SignatureValid      bool
+	ChainValid          bool
+	CertificateStatus   string
+	Signer              string
+	SignerSerial        string
+	Issuer              string
+	SigningTime         *time.Time
+	CoversWholeDocument bool
+	SignatureHash       string
+	Timestamp           *TimestampInfo
+	TimestampError      string
+	Error               string
+}
+
+// Valid indica se a assinatura confere, foi emitida pela CA interna, o certificado era válido no momento da assinatura
+// e o carimbo de tempo, quando presente, confere
+func (v *SignatureVerification) Valid() bool {
+	return v.SignatureValid && v.ChainValid && v.CertificateStatus == models.CertificateStatusValid && v.TimestampError == ""
+}
+
+// SignatureHash SHA-256 (hex) da estrutura CMS, desconsiderando o preenchimento de /Contents nos PDFs
+func SignatureHash(signatureDER []byte) string {
+	var raw asn1.RawValue
+	if rest, err := asn1.Unmarshal(signatureDER, &raw); err == nil {
+		signatureDER = signatureDER[:len(signatureDER)-len(rest)]
+	}
+	sum := sha256.Sum256(signatureDER)
+	return hex.EncodeToString(sum[:])
+}
+
+// signingKey descriptografa a chave privada de um certificado emitido com chave gerada no servidor
+func (ca *CAService) signingKey(certificate *models.Certificate) (crypto.Signer, *x509.Certificate, error) {
+	if certificate.KeyOrigin == KeyOriginCSR || certificate.PrivateKeyPEM == "" {
+		return nil, nil, ErrServerKeyUnavailable
+	}
+
+	block, _ := pem.Decode([]byte(certificate.CertificatePEM))
+	if block == nil {
+		return nil, nil, fmt.Errorf("failed to decode certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	keyPEM, err := ca.encryptionService.Decrypt(certificate.PrivateKeyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt private key: %w", err)
+	}
+	keyBlock, _ := pem.Decode([]byte(keyPEM))
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("failed to decode private key")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	return key, cert, nil
+}
+
+// SigningCertificate certificado usado para assinar em nome do usuário: o informado, se pertencer a ele, ou o ativo
+func (p *PKIManager) SigningCertificate(ctx context.Context, userID uint, certificateID *uint) (*models.Certificate, error) {
+	if certificateID == nil {
+		certificate, err := p.GetActiveCertificate(ctx, userID)
+		if err != nil {
+			return nil, ErrCertificateNotFound
+		}
+		return certificate, nil
+	}
+
+	var certificate models.Certificate
+	err := p.db.WithContext(ctx).Where("id = ? AND user_id = ?", *certificateID, userID).First(&certificate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCertificateNotFound
+		}
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	if certificate.IsRevoked {
+		return nil, ErrCertificateRevoked
+	}
+	if !certificate.IsValid() {
+		return nil, ErrCertificateNotFound
+	}
+	return &certificate, nil
+}
+
+// SignCAdES assina o SHA-256 do documento com a chave do certificado, gerando CAdES-BES destacada
+func (p *PKIManager) SignCAdES(ctx context.Context, certificate *models.Certificate, digest []byte, signingTime time.Time) ([]byte, error) {
+	if !p.caService.Ready() {
+		return nil, ErrCANotInitialized
+	}
+
+	key, cert, err := p.caService.signingKey(certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := SignCAdES(digest, cert, key, CAdESOptions{
+		SigningTime:        signingTime,
+		IntermediateChains: []*x509.Certificate{p.caService.caCert},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.addSignatureTimestamp(ctx, signature)
+}
+
+// SignPDF embute no PDF uma assinatura PAdES com a chave do certificado
+func (p *PKIManager) SignPDF(ctx context.Context, certificate *models.Certificate, pdf []byte, opts PDFSignatureOptions) ([]byte, error) {
+	if !p.caService.Ready() {
+		return nil, ErrCANotInitialized
+	}
+
+	key, cert, err := p.caService.signingKey(certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	return SignPDF(pdf, opts, func(digest []byte) ([]byte, error) {
+		// PAdES baseline: o horário de assinatura vai em /M e não como atributo assinado
+		signature, err := SignCAdES(digest, cert, key, CAdESOptions{
+			OmitSigningTime:    true,
+			IntermediateChains: []*x509.Certificate{p.caService.caCert},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return p.addSignatureTimestamp(ctx, signature)
+	})
+}
+
+// VerifyCAdES verifica uma assinatura CMS destacada contra o SHA-256 do documento, a cadeia até a CA interna e o
+// status do certificado no momento da assinatura
+func (p *PKIManager) VerifyCAdES(ctx context.Context, signatureDER, digest []byte) (*SignatureVerification, error) {
+	return p.verifyCMS(ctx, signatureDER, digest, nil)
+}
+
+// VerifyPDF verifica todas as assinaturas PAdES de um PDF
+func (p *PKIManager) VerifyPDF(ctx context.Context, pdf []byte) ([]SignatureVerification, error) {
+	signatures, err := ExtractPDFSignatures(pdf)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SignatureVerification, 0, len(signatures))
+	for _, sig := range signatures {
+		// No PAdES o horário de assinatura vem de /M
+		result, err := p.verifyCMS(ctx, sig.CMS, sig.Digest, sig.SigningTime)
+		if err != nil {
+			return nil, err
+		}
+		result.CoversWholeDocument = sig.CoversWholeDocument
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+func (p *PKIManager) verifyCMS(ctx context.Context, signatureDER, digest []byte, signingTime *time.Time) (*SignatureVerification, error) {
+	result := &SignatureVerification{
+		CertificateStatus: CertificateStatusUnknown,
+		SigningTime:       signingTime,
+		SignatureHash:     SignatureHash(signatureDER),
+	}
+
+	cms, err := VerifyCAdES(signatureDER, digest)
+	if cms != nil {
+		result.Signer = cms.Signer.Subject.CommonName
+		result.SignerSerial = cms.Signer.SerialNumber.String()
+		result.Issuer = cms.Signer.Issuer.CommonName
+		if cms.SigningTime != nil {
+			result.SigningTime = cms.SigningTime
+		}
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrDigestMismatch) {
+			result.Error = err.Error()
+			return result, nil
+		}
+		return nil, err
+	}
+	result.SignatureValid = true
+	p.checkSignatureTimestamp(signatureDER, result)
+
+	if err := p.checkSigner(ctx, cms.Signer, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkSigner valida a cadeia do signatário e o status do certificado no horário declarado da assinatura.
+// Revogações posteriores à assinatura só a invalidam quando o motivo é comprometimento de chave.
+func (p *PKIManager) checkSigner(ctx context.Context, signer *x509.Certificate, result *SignatureVerification) error {
+	if !p.caService.Ready() {
+		return ErrCANotInitialized
+	}
+	if err := signer.CheckSignatureFrom(p.caService.caCert); err != nil {
+		result.Error = fmt.Sprintf("certificate not issued by this CA: %v", err)
+		return nil
+	}
+	result.ChainValid = true
+
+	at := time.Now()
+	if result.SigningTime != nil {
+		at = *result.SigningTime
+	}
+
+	var certificate models.Certificate
+	err := p.db.WithContext(ctx).Where("serial_number = ?", result.SignerSerial).First(&certificate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		result.CertificateStatus = CertificateStatusUnknown
+		// O selo institucional não tem registro próprio: vale a validade do certificado no horário da assinatura
+		if p.isSealCertificate(signer) {
+			result.CertificateStatus = models.CertificateStatusValid
+			if at.Before(signer.NotBefore) || at.After(signer.NotAfter) {
+				result.CertificateStatus = models.CertificateStatusExpired
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load signer certificate: %w", err)
+	}
+
+	// A validade vem do próprio certificado: o horário de assinatura tem precisão de segundos, como NotBefore/NotAfter
+	switch {
+	case at.Before(signer.NotBefore):
+		result.CertificateStatus = models.CertificateStatusNotYetValid
+	case at.After(signer.NotAfter):
+		result.CertificateStatus = models.CertificateStatusExpired
+	case certificate.IsRevoked && (certificate.RevokedAt == nil || !certificate.RevokedAt.After(at) ||
+		certificate.RevocationReason == "key_compromise"):
+		result.CertificateStatus = models.CertificateStatusRevoked
+	default:
+		result.CertificateStatus = models.CertificateStatusValid
+	}
+	return nil
+}
+
+// SetTimestampAuthority registra a TSA interna, que atende /pki/tsa e carimba as assinaturas quando não há TSA externa
+func (p *PKIManager) SetTimestampAuthority(tsa *TimestampAuthority) {
+	p.tsa = tsa
+	if p.timestamper == nil {
+		p.timestamper = tsa
+	}
+}
+
+// SetTimestamper define quem emite os carimbos de tempo (TSA interna ou externa) e as raízes confiáveis adicionais à
+// CA interna para verificá-los
+func (p *PKIManager) SetTimestamper(timestamper Timestamper, trustedRoots []*x509.Certificate) {
+	p.timestamper = timestamper
+	p.timestampRoots = trustedRoots
+}
+
+// Timestamp obtém um carimbo de tempo RFC 3161 sobre o SHA-256 informado e devolve o token com os dados conferidos
+func (p *PKIManager) Timestamp(ctx context.Context, digest []byte) (*models.TimestampToken, error) {
+	if p.timestamper == nil {
+		return nil, ErrTimestampUnavailable
+	}
+
+	token, err := p.timestamper.Timestamp(ctx, digest)
+	if err != nil {
+		return nil, err
+	}
+	info, err := p.VerifyTimestamp(token, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TimestampToken{
+		Token:        token,
+		Time:         info.Time,
+		Authority:    info.Authority,
+		SerialNumber: info.SerialNumber,
+	}, nil
+}
+
+// VerifyTimestamp confere o carimbo contra o digest; a confiança é avaliada contra a CA interna e as raízes configuradas
+func (p *PKIManager) VerifyTimestamp(token, digest []byte) (*TimestampInfo, error) {
+	roots := x509.NewCertPool()
+	if p.caService.Ready() {
+		roots.AddCert(p.caService.caCert)
+	}
+	for _, root := range p.timestampRoots {
+		roots.AddCert(root)
+	}
+	return VerifyTimestampToken(token, digest, roots)
+}
+
+// RespondTimestamp atende uma requisição RFC 3161 pela TSA interna
+func (p *PKIManager) RespondTimestamp(requestDER []byte) ([]byte, error) {
+	if p.tsa == nil || !p.tsa.Ready() {
+		return nil, ErrTimestampUnavailable
+	}
+	return p.tsa.Respond(requestDER), nil
+}
+
+// addSignatureTimestamp carimba o valor da assinatura (CAdES-T) quando há emissor de carimbos configurado
+func (p *PKIManager) addSignatureTimestamp(ctx context.Context, signature []byte) ([]byte, error) {
+	if p.timestamper == nil {
+		return signature, nil
+	}
+
+	_, signatureValue, err := SignatureTimestampToken(signature)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(signatureValue)
+	token, err := p.timestamper.Timestamp(ctx, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	return AddSignatureTimestamp(signature, token)
+}
+
+// checkSignatureTimestamp confere o carimbo da assinatura; um carimbo confiável passa a ser o horário de referência
+// para o status do certificado, no lugar do horário declarado pelo signatário
+func (p *PKIManager) checkSignatureTimestamp(signatureDER []byte, result *SignatureVerification) {
+	token, signatureValue, err := SignatureTimestampToken(signatureDER)
+	if err != nil || token == nil {
+		return
+	}
+
+	digest := sha256.Sum256(signatureValue)
+	info, err := p.VerifyTimestamp(token, digest[:])
+	if err != nil {
+		result.TimestampError = err.Error()
+		return
+	}
+
+	result.Timestamp = info
+	if !info.Trusted {
+		result.TimestampError = info.TrustError
+		return
+	}
+	signedAt := info.Time
+	result.SigningTime = &signedAt
+}
@@ -0,0 +1,184 @@
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	equinoidcrypto "github.com/equinoid/backend/internal/security/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// newTestCA CA interna com certificado e chave gravados em um diretório temporário
+func newTestCA(t *testing.T) *CAService {
+	encryption, err := equinoidcrypto.NewEncryptionService(&config.Config{JWTSecret: strings.Repeat("s", 32)})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	ca := NewCAService(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"), 365, encryption)
+	require.NoError(t, ca.Initialize())
+	return ca
+}
+
+func setupPKIManager(t *testing.T) *PKIManager {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Skip("sqlite driver unavailable for tests")
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.Certificate{}))
+
+	return NewPKIManager(db, newTestCA(t))
+}
+
+// issueSigningCertificate certificado com chave guardada no servidor, registrado como os emitidos pela API
+func issueSigningCertificate(t *testing.T, manager *PKIManager) *models.Certificate {
+	response, err := manager.caService.IssueCertificate(&CertificateRequest{
+		UserID:     1,
+		CommonName: "Haras Teste",
+		KeyUsage:   []string{"digital_signature"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, manager.db.Omit(clause.Associations).Create(response.Certificate).Error)
+	return response.Certificate
+}
+
+// testPDF PDF de uma página com tabela xref clássica
+func testPDF() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+	return buf.Bytes()
+}
+
+func TestPKIManager_SignPDFVerifyPDF(t *testing.T) {
+	manager := setupPKIManager(t)
+	certificate := issueSigningCertificate(t, manager)
+	ctx := context.Background()
+
+	original := testPDF()
+	signed, err := manager.SignPDF(ctx, certificate, original, PDFSignatureOptions{
+		Name:        "Haras Teste",
+		Reason:      "Contrato de compra e venda",
+		SigningTime: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(signed, original), "a atualização incremental preserva os bytes originais")
+
+	t.Run("Assinatura confere", func(t *testing.T) {
+		results, err := manager.VerifyPDF(ctx, signed)
+
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.True(t, results[0].Valid(), results[0].Error)
+		assert.True(t, results[0].CoversWholeDocument)
+		assert.Equal(t, "Haras Teste", results[0].Signer)
+		assert.Equal(t, certificate.SerialNumber, results[0].SignerSerial)
+		assert.NotNil(t, results[0].SigningTime)
+	})
+
+	t.Run("Byte alterado no intervalo coberto", func(t *testing.T) {
+		adulterado := append([]byte{}, signed...)
+		i := bytes.Index(adulterado, []byte("/MediaBox [0 0 200"))
+		require.Positive(t, i)
+		adulterado[i+len("/MediaBox [0 0 ")] = '9'
+
+		results, err := manager.VerifyPDF(ctx, adulterado)
+
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.False(t, results[0].SignatureValid)
+		assert.False(t, results[0].Valid())
+		assert.NotEmpty(t, results[0].Error)
+	})
+
+	t.Run("Conteúdo acrescentado após a assinatura", func(t *testing.T) {
+		results, err := manager.VerifyPDF(ctx, append(append([]byte{}, signed...), "% comentário\n"...))
+
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.True(t, results[0].SignatureValid)
+		assert.False(t, results[0].CoversWholeDocument)
+	})
+
+	t.Run("PDF sem assinatura", func(t *testing.T) {
+		_, err := manager.VerifyPDF(ctx, original)
+
+		assert.ErrorIs(t, err, ErrPDFNotSigned)
+	})
+}
+
+func TestPKIManager_VerifyCAdES(t *testing.T) {
+	manager := setupPKIManager(t)
+	certificate := issueSigningCertificate(t, manager)
+	ctx := context.Background()
+
+	digest := sha256.Sum256([]byte("laudo de exame de mormo"))
+	signature, err := manager.SignCAdES(ctx, certificate, digest[:], time.Now())
+	require.NoError(t, err)
+
+	t.Run("Digest do documento assinado", func(t *testing.T) {
+		result, err := manager.VerifyCAdES(ctx, signature, digest[:])
+
+		require.NoError(t, err)
+		assert.True(t, result.Valid(), result.Error)
+		assert.Equal(t, "Haras Teste", result.Signer)
+		assert.Equal(t, models.CertificateStatusValid, result.CertificateStatus)
+		assert.Equal(t, SignatureHash(signature), result.SignatureHash)
+	})
+
+	t.Run("Digest de outro documento", func(t *testing.T) {
+		outro := sha256.Sum256([]byte("laudo de exame de anemia infecciosa"))
+
+		result, err := manager.VerifyCAdES(ctx, signature, outro[:])
+
+		require.NoError(t, err)
+		assert.False(t, result.SignatureValid)
+		assert.False(t, result.Valid())
+		assert.Contains(t, result.Error, ErrDigestMismatch.Error())
+	})
+
+	t.Run("Certificado revogado antes da assinatura", func(t *testing.T) {
+		revogadoEm := time.Now().Add(-time.Minute)
+		require.NoError(t, manager.db.Model(certificate).Updates(map[string]interface{}{
+			"is_revoked": true,
+			"revoked_at": revogadoEm,
+		}).Error)
+
+		result, err := manager.VerifyCAdES(ctx, signature, digest[:])
+
+		require.NoError(t, err)
+		assert.True(t, result.SignatureValid)
+		assert.Equal(t, models.CertificateStatusRevoked, result.CertificateStatus)
+		assert.False(t, result.Valid())
+	})
+}
@@ -0,0 +1,543 @@
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTimestampUnavailable = errors.New("timestamp authority unavailable")
+	ErrTimestampRejected    = errors.New("timestamp request rejected")
+	ErrInvalidTimestamp     = errors.New("invalid timestamp token")
+	ErrTimestampMismatch    = errors.New("timestamp does not cover the given digest")
+)
+
+// DefaultTimestampPolicy política padrão da TSA interna, sob o PEN de documentação da IANA (RFC 5612); em produção
+// configure um OID do arco da organização
+const DefaultTimestampPolicy = "1.3.6.1.4.1.32473.3161.1"
+
+const (
+	// tsaCertValidity validade do certificado da TSA, limitada à da CA
+	tsaCertValidity = 5 * 365 * 24 * time.Hour
+	// tsaRenewBefore antecedência com que o certificado da TSA é reemitido na inicialização
+	tsaRenewBefore = 30 * 24 * time.Hour
+	tsaKeyBits     = 3072
+	// maxTimestampResponseSize limite da resposta de uma TSA externa
+	maxTimestampResponseSize = 1 << 20
+)
+
+var (
+	oidTSTInfo        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidExtKeyUsage    = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidKPTimeStamping = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 8}
+)
+
+// PKIStatus e PKIFailureInfo (RFC 3161, seção 2.4.2)
+const (
+	statusGranted           = 0
+	statusGrantedWithMods   = 1
+	statusRejection         = 2
+	failBadAlg              = 0
+	failBadDataFormat       = 5
+	failUnacceptedPolicy    = 15
+	failUnacceptedExtension = 16
+	failSystemFailure       = 25
+)
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type tsAccuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time        `asn1:"generalized"`
+	Accuracy       tsAccuracy       `asn1:"optional"`
+	Ordering       bool             `asn1:"optional"`
+	Nonce          *big.Int         `asn1:"optional"`
+	TSA            asn1.RawValue    `asn1:"optional,tag:0"`
+	Extensions     []pkix.Extension `asn1:"optional,tag:1"`
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	ReqPolicy      asn1.ObjectIdentifier `asn1:"optional"`
+	Nonce          *big.Int              `asn1:"optional"`
+	CertReq        bool                  `asn1:"optional"`
+	Extensions     []pkix.Extension      `asn1:"optional,tag:0"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []asn1.RawValue `asn1:"optional"`
+	FailInfo     asn1.BitString  `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+// Timestamper emissor de carimbos de tempo RFC 3161 sobre um SHA-256: a TSA interna ou o cliente de uma TSA externa
+type Timestamper interface {
+	Timestamp(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// TimestampInfo dados de um carimbo de tempo cuja assinatura confere. Trusted indica se o certificado da TSA encadeia
+// até uma raiz confiável e é autorizado para carimbo de tempo.
+type TimestampInfo struct {
+	Time            time.Time
+	Accuracy        time.Duration
+	Authority       string
+	AuthoritySerial string
+	SerialNumber    string
+	Policy          string
+	Trusted         bool
+	TrustError      string
+}
+
+// TimestampAuthority TSA interna (RFC 3161) com chave própria e certificado emitido pela CA interna
+type TimestampAuthority struct {
+	certPath string
+	keyPath  string
+	policy   asn1.ObjectIdentifier
+	ca       *CAService
+
+	mu   sync.RWMutex
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+}
+
+// NewTimestampAuthority cria a TSA interna; o certificado e a chave são carregados ou gerados em Initialize
+func NewTimestampAuthority(certPath, keyPath, policy string, ca *CAService) (*TimestampAuthority, error) {
+	if policy == "" {
+		policy = DefaultTimestampPolicy
+	}
+	oid, err := parseObjectIdentifier(policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp policy %q: %w", policy, err)
+	}
+
+	return &TimestampAuthority{
+		certPath: certPath,
+		keyPath:  keyPath,
+		policy:   oid,
+		ca:       ca,
+	}, nil
+}
+
+// Initialize carrega o certificado da TSA ou emite um novo pela CA quando ausente, próximo do vencimento ou emitido
+// por outra CA
+func (t *TimestampAuthority) Initialize() error {
+	if !t.ca.Ready() || t.ca.encryptionService == nil {
+		return ErrCANotInitialized
+	}
+
+	cert, key, err := t.load()
+	if err != nil || t.serviceKey().needsRenewal(cert, tsaRenewBefore) {
+		cert, key, err = t.create()
+		if err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	t.cert, t.key = cert, key
+	t.mu.Unlock()
+	return nil
+}
+
+// Ready indica se a TSA tem certificado e chave carregados
+func (t *TimestampAuthority) Ready() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.cert != nil && t.key != nil
+}
+
+// Timestamp emite um carimbo de tempo sobre o SHA-256 informado, incluindo os certificados da TSA e da CA
+func (t *TimestampAuthority) Timestamp(ctx context.Context, digest []byte) ([]byte, error) {
+	if len(digest) != sha256.Size {
+		return nil, fmt.Errorf("expected SHA-256 digest, got %d bytes", len(digest))
+	}
+	return t.issue(sha256Imprint(digest), nil, true)
+}
+
+// Respond atende uma TimeStampReq (application/timestamp-query) e devolve a TimeStampResp correspondente;
+// requisições inválidas recebem resposta de rejeição com o motivo em failInfo
+func (t *TimestampAuthority) Respond(requestDER []byte) []byte {
+	var req timeStampReq
+	rest, err := asn1.Unmarshal(requestDER, &req)
+	if err != nil || len(rest) > 0 || req.Version != 1 {
+		return rejectionResponse(failBadDataFormat, "malformed timestamp request")
+	}
+
+	hash, ok := digestAlgorithms[req.MessageImprint.HashAlgorithm.Algorithm.String()]
+	if !ok || len(req.MessageImprint.HashedMessage) != hash.Size() {
+		return rejectionResponse(failBadAlg, "unsupported hash algorithm")
+	}
+	if len(req.ReqPolicy) > 0 && !req.ReqPolicy.Equal(t.policy) {
+		return rejectionResponse(failUnacceptedPolicy, "unsupported policy")
+	}
+	if len(req.Extensions) > 0 {
+		return rejectionResponse(failUnacceptedExtension, "extensions are not supported")
+	}
+
+	token, err := t.issue(req.MessageImprint, req.Nonce, req.CertReq)
+	if err != nil {
+		return rejectionResponse(failSystemFailure, "timestamp authority unavailable")
+	}
+
+	response, err := asn1.Marshal(timeStampResp{
+		Status:         pkiStatusInfo{Status: statusGranted},
+		TimeStampToken: asn1.RawValue{FullBytes: token},
+	})
+	if err != nil {
+		return rejectionResponse(failSystemFailure, "failed to encode response")
+	}
+	return response
+}
+
+func (t *TimestampAuthority) issue(imprint messageImprint, nonce *big.Int, includeCertificates bool) ([]byte, error) {
+	t.mu.RLock()
+	cert, key := t.cert, t.key
+	t.mu.RUnlock()
+	if cert == nil || key == nil {
+		return nil, ErrTimestampUnavailable
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	tsaName, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: cert.RawSubject})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode TSA name: %w", err)
+	}
+
+	info, err := asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         t.policy,
+		MessageImprint: imprint,
+		SerialNumber:   serial,
+		// GeneralizedTime em DER não leva fração aqui; a precisão declarada é de um segundo
+		GenTime:  time.Now().UTC().Truncate(time.Second),
+		Accuracy: tsAccuracy{Seconds: 1},
+		Nonce:    nonce,
+		TSA:      asn1.RawValue{FullBytes: wrapExplicit(tsaName)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode TSTInfo: %w", err)
+	}
+
+	var certificates []*x509.Certificate
+	if includeCertificates {
+		certificates = []*x509.Certificate{cert, t.ca.caCert}
+	}
+
+	digest := sha256.Sum256(info)
+	return signCMS(signedContent{contentType: oidTSTInfo, content: info, digest: digest[:]}, cert, key, certificates)
+}
+
+func (t *TimestampAuthority) load() (*x509.Certificate, *rsa.PrivateKey, error) {
+	return t.serviceKey().load()
+}
+
+// create gera a chave da TSA e o certificado com extendedKeyUsage timeStamping crítico (RFC 3161, seção 2.3)
+func (t *TimestampAuthority) create() (*x509.Certificate, *rsa.PrivateKey, error) {
+	eku, err := asn1.Marshal([]asn1.ObjectIdentifier{oidKPTimeStamping})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode extended key usage: %w", err)
+	}
+
+	k := t.serviceKey()
+	subject := k.subjectFor("Time Stamping Authority")
+	if len(subject.Organization) > 0 {
+		subject.CommonName = subject.Organization[0] + " TSA"
+	}
+
+	return k.create(&x509.Certificate{
+		Subject:         subject,
+		KeyUsage:        x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+		ExtraExtensions: []pkix.Extension{{Id: oidExtKeyUsage, Critical: true, Value: eku}},
+	}, tsaKeyBits, tsaCertValidity)
+}
+
+func (t *TimestampAuthority) serviceKey() serviceKey {
+	return serviceKey{label: "TSA", certPath: t.certPath, keyPath: t.keyPath, ca: t.ca}
+}
+
+// HTTPTimestampClient cliente RFC 3161 de uma TSA externa (POST application/timestamp-query)
+type HTTPTimestampClient struct {
+	url    string
+	policy asn1.ObjectIdentifier
+	client *http.Client
+}
+
+// NewHTTPTimestampClient cria o cliente; policy vazia deixa a política a critério da TSA
+func NewHTTPTimestampClient(url, policy string, timeout time.Duration) (*HTTPTimestampClient, error) {
+	client := &HTTPTimestampClient{url: url, client: &http.Client{Timeout: timeout}}
+	if policy != "" {
+		oid, err := parseObjectIdentifier(policy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp policy %q: %w", policy, err)
+		}
+		client.policy = oid
+	}
+	return client, nil
+}
+
+// Timestamp solicita o carimbo à TSA externa e confere que ele cobre o digest e devolve o nonce enviado.
+// A assinatura e a cadeia da TSA são conferidas na verificação, contra as raízes confiáveis configuradas.
+func (c *HTTPTimestampClient) Timestamp(ctx context.Context, digest []byte) ([]byte, error) {
+	if len(digest) != sha256.Size {
+		return nil, fmt.Errorf("expected SHA-256 digest, got %d bytes", len(digest))
+	}
+
+	nonce, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	requestDER, err := asn1.Marshal(timeStampReq{
+		Version:        1,
+		MessageImprint: sha256Imprint(digest),
+		ReqPolicy:      c.policy,
+		Nonce:          nonce,
+		CertReq:        true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode timestamp request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(requestDER))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create timestamp request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/timestamp-query")
+	req.Header.Set("Accept", "application/timestamp-reply")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTimestampUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP %d", ErrTimestampUnavailable, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTimestampResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTimestampUnavailable, err)
+	}
+
+	var tsResp timeStampResp
+	if _, err := asn1.Unmarshal(body, &tsResp); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTimestamp, err)
+	}
+	if tsResp.Status.Status != statusGranted && tsResp.Status.Status != statusGrantedWithMods {
+		return nil, fmt.Errorf("%w: %s", ErrTimestampRejected, statusText(tsResp.Status))
+	}
+
+	token := tsResp.TimeStampToken.FullBytes
+	_, tst, _, err := parseTimestampToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(tst.MessageImprint.HashedMessage, digest) {
+		return nil, ErrTimestampMismatch
+	}
+	if tst.Nonce == nil || tst.Nonce.Cmp(nonce) != 0 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidTimestamp)
+	}
+
+	return token, nil
+}
+
+// VerifyTimestampToken confere um carimbo de tempo contra o SHA-256 informado usando apenas o próprio token e as
+// raízes confiáveis, sem depender de registros locais
+func VerifyTimestampToken(token, digest []byte, roots *x509.CertPool) (*TimestampInfo, error) {
+	sd, tst, tstDER, err := parseTimestampToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if !tst.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) || !bytes.Equal(tst.MessageImprint.HashedMessage, digest) {
+		return nil, ErrTimestampMismatch
+	}
+
+	cms, err := sd.verify(func(hash crypto.Hash) ([]byte, error) {
+		h := hash.New()
+		h.Write(tstDER)
+		return h.Sum(nil), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTimestamp, err)
+	}
+
+	info := &TimestampInfo{
+		Time:            tst.GenTime,
+		Accuracy:        tst.Accuracy.duration(),
+		Authority:       authorityName(cms.Signer),
+		AuthoritySerial: cms.Signer.SerialNumber.String(),
+		SerialNumber:    tst.SerialNumber.String(),
+		Policy:          tst.Policy.String(),
+	}
+
+	if !hasTimestampingUsage(cms.Signer) {
+		info.TrustError = "certificate is not authorized for time stamping"
+		return info, nil
+	}
+
+	// A autorização de carimbo é conferida acima no certificado da TSA; a CA interna restringe EKU a TLS e o Go
+	// exigiria que toda a cadeia permitisse timeStamping
+	intermediates := x509.NewCertPool()
+	for _, cert := range cms.Certificates {
+		if cert != cms.Signer {
+			intermediates.AddCert(cert)
+		}
+	}
+	_, err = cms.Signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   tst.GenTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		info.TrustError = err.Error()
+		return info, nil
+	}
+
+	info.Trusted = true
+	return info, nil
+}
+
+func parseTimestampToken(token []byte) (*signedData, *tstInfo, []byte, error) {
+	if len(token) == 0 {
+		return nil, nil, nil, fmt.Errorf("%w: empty token", ErrInvalidTimestamp)
+	}
+
+	sd, err := parseSignedData(token)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidTimestamp, err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, nil, nil, fmt.Errorf("%w: content is not TSTInfo", ErrInvalidTimestamp)
+	}
+
+	content, err := sd.encapsulatedContent()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidTimestamp, err)
+	}
+	var tst tstInfo
+	if _, err := asn1.Unmarshal(content, &tst); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidTimestamp, err)
+	}
+	return sd, &tst, content, nil
+}
+
+func (a tsAccuracy) duration() time.Duration {
+	return time.Duration(a.Seconds)*time.Second + time.Duration(a.Millis)*time.Millisecond + time.Duration(a.Micros)*time.Microsecond
+}
+
+func sha256Imprint(digest []byte) messageImprint {
+	return messageImprint{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+		HashedMessage: digest,
+	}
+}
+
+func authorityName(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if len(cert.Subject.Organization) > 0 {
+		return cert.Subject.Organization[0]
+	}
+	return cert.Subject.String()
+}
+
+func hasTimestampingUsage(cert *x509.Certificate) bool {
+	for _, usage := range cert.ExtKeyUsage {
+		if usage == x509.ExtKeyUsageTimeStamping {
+			return true
+		}
+	}
+	return false
+}
+
+func rejectionResponse(failure int, message string) []byte {
+	failInfo := asn1.BitString{Bytes: make([]byte, failure/8+1), BitLength: failure + 1}
+	failInfo.Bytes[failure/8] |= 0x80 >> uint(failure%8)
+
+	response, _ := asn1.Marshal(timeStampResp{
+		Status: pkiStatusInfo{
+			Status:       statusRejection,
+			StatusString: []asn1.RawValue{{Class: asn1.ClassUniversal, Tag: asn1.TagUTF8String, Bytes: []byte(message)}},
+			FailInfo:     failInfo,
+		},
+	})
+	return response
+}
+
+func statusText(status pkiStatusInfo) string {
+	parts := []string{fmt.Sprintf("status %d", status.Status)}
+	for _, text := range status.StatusString {
+		parts = append(parts, string(text.Bytes))
+	}
+	for bit := 0; bit < status.FailInfo.BitLength; bit++ {
+		if status.FailInfo.At(bit) == 1 {
+			parts = append(parts, fmt.Sprintf("failInfo %d", bit))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
+
+func parseObjectIdentifier(value string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("object identifier needs at least two arcs")
+	}
+
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, part := range parts {
+		arc, err := strconv.Atoi(part)
+		if err != nil || arc < 0 {
+			return nil, fmt.Errorf("invalid arc %q", part)
+		}
+		oid[i] = arc
+	}
+	return oid, nil
+}
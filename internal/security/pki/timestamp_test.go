@@ -0,0 +1,229 @@
+package pki
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTSA(t *testing.T, ca *CAService) *TimestampAuthority {
+	dir := t.TempDir()
+	tsa, err := NewTimestampAuthority(filepath.Join(dir, "tsa.crt"), filepath.Join(dir, "tsa.key"), "", ca)
+	require.NoError(t, err)
+	require.NoError(t, tsa.Initialize())
+	return tsa
+}
+
+func caRoots(ca *CAService) *x509.CertPool {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.caCert)
+	return roots
+}
+
+func TestTimestampAuthority_Timestamp(t *testing.T) {
+	ca := newTestCA(t)
+	tsa := newTestTSA(t, ca)
+	digest := sha256.Sum256([]byte("contrato de compra e venda"))
+
+	antes := time.Now().Truncate(time.Second)
+	token, err := tsa.Timestamp(context.Background(), digest[:])
+	require.NoError(t, err)
+	depois := time.Now()
+
+	t.Run("Token confere com o digest", func(t *testing.T) {
+		info, err := VerifyTimestampToken(token, digest[:], caRoots(ca))
+
+		require.NoError(t, err)
+		assert.True(t, info.Trusted, info.TrustError)
+		assert.Equal(t, tsa.cert.Subject.CommonName, info.Authority)
+		assert.Equal(t, tsa.cert.SerialNumber.String(), info.AuthoritySerial)
+		assert.Equal(t, DefaultTimestampPolicy, info.Policy)
+		assert.Equal(t, time.Second, info.Accuracy)
+		assert.False(t, info.Time.Before(antes), "genTime %v anterior à emissão %v", info.Time, antes)
+		assert.False(t, info.Time.After(depois), "genTime %v posterior à emissão %v", info.Time, depois)
+	})
+
+	t.Run("Digest diferente", func(t *testing.T) {
+		outro := sha256.Sum256([]byte("outro contrato"))
+
+		_, err := VerifyTimestampToken(token, outro[:], caRoots(ca))
+
+		assert.ErrorIs(t, err, ErrTimestampMismatch)
+	})
+
+	t.Run("TSA de outra CA não é confiável", func(t *testing.T) {
+		info, err := VerifyTimestampToken(token, digest[:], caRoots(newTestCA(t)))
+
+		require.NoError(t, err)
+		assert.False(t, info.Trusted)
+		assert.NotEmpty(t, info.TrustError)
+	})
+
+	t.Run("Digest que não é SHA-256", func(t *testing.T) {
+		_, err := tsa.Timestamp(context.Background(), digest[:20])
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTimestampAuthority_Respond(t *testing.T) {
+	ca := newTestCA(t)
+	tsa := newTestTSA(t, ca)
+	digest := sha256.Sum256([]byte("laudo veterinário"))
+
+	requisicao := func(t *testing.T, req timeStampReq) timeStampResp {
+		der, err := asn1.Marshal(req)
+		require.NoError(t, err)
+
+		var resp timeStampResp
+		_, err = asn1.Unmarshal(tsa.Respond(der), &resp)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Requisição válida com nonce", func(t *testing.T) {
+		nonce := big.NewInt(424242)
+		resp := requisicao(t, timeStampReq{Version: 1, MessageImprint: sha256Imprint(digest[:]), Nonce: nonce, CertReq: true})
+
+		require.Equal(t, statusGranted, resp.Status.Status)
+		_, tst, _, err := parseTimestampToken(resp.TimeStampToken.FullBytes)
+		require.NoError(t, err)
+		assert.Equal(t, 0, tst.Nonce.Cmp(nonce))
+
+		info, err := VerifyTimestampToken(resp.TimeStampToken.FullBytes, digest[:], caRoots(ca))
+		require.NoError(t, err)
+		assert.True(t, info.Trusted, info.TrustError)
+	})
+
+	casos := []struct {
+		nome  string
+		req   timeStampReq
+		falha int
+	}{
+		{"Algoritmo não suportado", timeStampReq{Version: 1, MessageImprint: messageImprint{HashAlgorithm: sha256Imprint(nil).HashAlgorithm, HashedMessage: digest[:20]}}, failBadAlg},
+		{"Política diferente", timeStampReq{Version: 1, MessageImprint: sha256Imprint(digest[:]), ReqPolicy: asn1.ObjectIdentifier{1, 2, 3}}, failUnacceptedPolicy},
+		{"Versão inválida", timeStampReq{Version: 2, MessageImprint: sha256Imprint(digest[:])}, failBadDataFormat},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			resp := requisicao(t, caso.req)
+
+			assert.Equal(t, statusRejection, resp.Status.Status)
+			assert.Equal(t, 1, resp.Status.FailInfo.At(caso.falha))
+			assert.Empty(t, resp.TimeStampToken.FullBytes)
+		})
+	}
+
+	t.Run("Corpo malformado", func(t *testing.T) {
+		var resp timeStampResp
+		_, err := asn1.Unmarshal(tsa.Respond([]byte("não é DER")), &resp)
+
+		require.NoError(t, err)
+		assert.Equal(t, statusRejection, resp.Status.Status)
+		assert.Equal(t, 1, resp.Status.FailInfo.At(failBadDataFormat))
+	})
+}
+
+func TestHTTPTimestampClient_Timestamp(t *testing.T) {
+	ca := newTestCA(t)
+	tsa := newTestTSA(t, ca)
+	digest := sha256.Sum256([]byte("documento carimbado por TSA externa"))
+
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		w.Write(tsa.Respond(body))
+	}))
+	defer server.Close()
+
+	t.Run("Carimbo emitido pela TSA externa", func(t *testing.T) {
+		client, err := NewHTTPTimestampClient(server.URL, "", 5*time.Second)
+		require.NoError(t, err)
+
+		token, err := client.Timestamp(context.Background(), digest[:])
+
+		require.NoError(t, err)
+		assert.Equal(t, "application/timestamp-query", contentType)
+		info, err := VerifyTimestampToken(token, digest[:], caRoots(ca))
+		require.NoError(t, err)
+		assert.True(t, info.Trusted, info.TrustError)
+		assert.Equal(t, tsa.cert.Subject.CommonName, info.Authority)
+	})
+
+	t.Run("Política recusada pela TSA", func(t *testing.T) {
+		client, err := NewHTTPTimestampClient(server.URL, "1.2.3.4", 5*time.Second)
+		require.NoError(t, err)
+
+		_, err = client.Timestamp(context.Background(), digest[:])
+
+		assert.ErrorIs(t, err, ErrTimestampRejected)
+	})
+
+	t.Run("Carimbo de outro digest", func(t *testing.T) {
+		outro := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			var req timeStampReq
+			if _, err := asn1.Unmarshal(body, &req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			// Mesmo nonce, imprint de outro documento
+			trocado := sha256.Sum256([]byte("documento trocado"))
+			req.MessageImprint = sha256Imprint(trocado[:])
+			der, _ := asn1.Marshal(req)
+			w.Write(tsa.Respond(der))
+		}))
+		defer outro.Close()
+		client, err := NewHTTPTimestampClient(outro.URL, "", 5*time.Second)
+		require.NoError(t, err)
+
+		_, err = client.Timestamp(context.Background(), digest[:])
+
+		assert.ErrorIs(t, err, ErrTimestampMismatch)
+	})
+
+	t.Run("TSA indisponível", func(t *testing.T) {
+		indisponivel := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer indisponivel.Close()
+		client, err := NewHTTPTimestampClient(indisponivel.URL, "", 5*time.Second)
+		require.NoError(t, err)
+
+		_, err = client.Timestamp(context.Background(), digest[:])
+
+		assert.ErrorIs(t, err, ErrTimestampUnavailable)
+	})
+}
+
+func TestPKIManager_SignCAdESComCarimboDeTempo(t *testing.T) {
+	manager := setupPKIManager(t)
+	manager.SetTimestampAuthority(newTestTSA(t, manager.caService))
+	certificate := issueSigningCertificate(t, manager)
+	ctx := context.Background()
+
+	digest := sha256.Sum256([]byte("atestado de vacinação"))
+	signature, err := manager.SignCAdES(ctx, certificate, digest[:], time.Now())
+	require.NoError(t, err)
+
+	result, err := manager.VerifyCAdES(ctx, signature, digest[:])
+
+	require.NoError(t, err)
+	assert.True(t, result.Valid(), result.Error)
+	require.NotNil(t, result.Timestamp)
+	assert.True(t, result.Timestamp.Trusted)
+	assert.Empty(t, result.TimestampError)
+}
@@ -6,18 +6,12 @@ import (
 	"github.com/equinoid/backend/internal/models"
 )
 
-func (d *D4SignService) RegisterDocument(ctx context.Context, equinoid string, docType string, filePath string) (string, error) {
-	req := models.CreateD4SignDocumentRequest{
-		Base64File:   filePath,
-		Name:         docType,
-		DocumentType: docType,
-		Signers:      []models.D4SignSigner{},
-	}
-	
-	doc, err := d.CreateDocument(ctx, equinoid, req, 1)
+// RegisterDocument envia o documento com os signatários para assinatura e devolve o UUID na D4Sign
+func (d *D4SignService) RegisterDocument(ctx context.Context, createdBy uint, req models.CreateD4SignDocumentRequest) (string, error) {
+	doc, err := d.CreateContract(ctx, req, createdBy)
 	if err != nil {
 		return "", err
 	}
-	
+
 	return doc.DocumentUUID, nil
 }
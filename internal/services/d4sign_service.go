@@ -3,22 +3,69 @@ package services
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/equinoid/backend/internal/config"
 	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
 	"github.com/equinoid/backend/pkg/logging"
 	"gorm.io/gorm"
 )
 
+const (
+	d4signRequestTimeout    = 30 * time.Second
+	d4signMaxSignedFileSize = 50 << 20
+	d4signSyncBatchSize     = 100
+
+	// statusId da API D4Sign
+	d4signStatusFinished  = "4"
+	d4signStatusCancelled = "6"
+
+	d4signDateLayout = "2006-01-02 15:04:05"
+)
+
+// d4signSignerActs ação de cada papel na D4Sign: 1 assinar, 4 assinar como parte, 5 como testemunha, 6 como interveniente
+var d4signSignerActs = map[string]string{
+	models.D4SignRoleVendedor:   "4",
+	models.D4SignRoleComprador:  "4",
+	models.D4SignRoleSignatario: "1",
+	models.D4SignRoleTestemunha: "5",
+	models.D4SignRoleLeiloeiro:  "6",
+}
+
+// d4signRoleOrder posição padrão de cada papel na assinatura sequencial
+var d4signRoleOrder = map[string]int{
+	models.D4SignRoleVendedor:   1,
+	models.D4SignRoleComprador:  2,
+	models.D4SignRoleSignatario: 3,
+	models.D4SignRoleTestemunha: 4,
+	models.D4SignRoleLeiloeiro:  5,
+}
+
+// Timestamper emite carimbos de tempo RFC 3161 sobre um digest SHA-256
+type Timestamper interface {
+	Timestamp(ctx context.Context, digest []byte) (*models.TimestampToken, error)
+}
+
 type D4SignService struct {
-	db     *gorm.DB
-	logger *logging.Logger
-	config *config.Config
+	db          *gorm.DB
+	logger      *logging.Logger
+	config      *config.Config
+	client      *http.Client
+	timestamper Timestamper
 }
 
 func NewD4SignService(db *gorm.DB, logger *logging.Logger, config *config.Config) *D4SignService {
@@ -26,9 +73,15 @@ func NewD4SignService(db *gorm.DB, logger *logging.Logger, config *config.Config
 		db:     db,
 		logger: logger,
 		config: config,
+		client: &http.Client{Timeout: d4signRequestTimeout},
 	}
 }
 
+// SetTimestamper carimba o PDF final dos documentos concluídos; sem TSA os documentos ficam sem carimbo
+func (s *D4SignService) SetTimestamper(timestamper Timestamper) {
+	s.timestamper = timestamper
+}
+
 func (s *D4SignService) createRequest(method, endpoint string, body interface{}) (*http.Request, error) {
 	u, err := url.Parse(s.config.D4SignAPIURL + endpoint)
 	if err != nil {
@@ -66,8 +119,58 @@ func (s *D4SignService) doRequest(ctx context.Context, method, endpoint string,
 		return nil, err
 	}
 
-	client := &http.Client{}
-	return client.Do(req.WithContext(ctx))
+	return s.client.Do(req.WithContext(ctx))
+}
+
+// call executa a requisição e decodifica a resposta em dest (quando informado)
+func (s *D4SignService) call(ctx context.Context, method, endpoint string, body, dest interface{}) error {
+	resp, err := s.doRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to call D4Sign API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("D4Sign API returned status %d on %s %s", resp.StatusCode, method, endpoint)
+	}
+	if dest == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("failed to decode D4Sign response: %w", err)
+	}
+	return nil
+}
+
+// CreateContract envia o documento, cadastra os signatários na ordem de assinatura e dispara o envio para assinatura
+func (s *D4SignService) CreateContract(ctx context.Context, req models.CreateD4SignDocumentRequest, createdBy uint) (*models.D4SignDocument, error) {
+	if len(req.Signers) == 0 {
+		return nil, &apperrors.ValidationError{Field: "signers", Message: "informe ao menos um signatário"}
+	}
+	if _, err := orderSigners(req.Signers); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.CreateDocument(ctx, req.SafeUUID, req, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.AddSigners(ctx, doc.DocumentUUID, req.Signers); err != nil {
+		return nil, err
+	}
+	if err := s.registerWebhook(ctx, doc.DocumentUUID); err != nil {
+		return nil, err
+	}
+	if err := s.SendToSign(ctx, doc.DocumentUUID); err != nil {
+		return nil, err
+	}
+
+	s.logger.LogBusinessEvent("d4sign_contract_sent", "Contrato enviado para assinatura na D4Sign", createdBy, "", logging.Fields{
+		"document_uuid": doc.DocumentUUID,
+		"signers":       len(req.Signers),
+		"sequential":    doc.SequentialSigning,
+	})
+	return s.GetDocumentByUUID(ctx, doc.DocumentUUID)
 }
 
 func (s *D4SignService) CreateDocument(ctx context.Context, safeUUID string, req models.CreateD4SignDocumentRequest, createdBy uint) (*models.D4SignDocument, error) {
@@ -75,36 +178,36 @@ func (s *D4SignService) CreateDocument(ctx context.Context, safeUUID string, req
 		safeUUID = s.config.D4SignSafeUUID
 	}
 
-	endpoint := fmt.Sprintf("/documents/%s/upload", safeUUID)
+	endpoint := fmt.Sprintf("/documents/%s/uploadbinary", safeUUID)
 
 	s.logger.WithContext(ctx).Infof("Enviando documento '%s' para D4Sign no cofre %s", req.Name, safeUUID)
 
-	resp, err := s.doRequest(ctx, "POST", endpoint, req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call D4Sign API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("D4Sign API returned status %d", resp.StatusCode)
+	payload := map[string]string{
+		"base64_binary_file": req.Base64File,
+		"mime_type":          "application/pdf",
+		"name":               req.Name,
 	}
 
 	var result struct {
 		UUID string `json:"uuid"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode D4Sign response: %w", err)
+	if err := s.call(ctx, http.MethodPost, endpoint, payload, &result); err != nil {
+		return nil, err
+	}
+	if result.UUID == "" {
+		return nil, fmt.Errorf("D4Sign API returned no document uuid")
 	}
 
 	doc := &models.D4SignDocument{
 		DocumentUUID:      result.UUID,
 		SafeUUID:          safeUUID,
 		Name:              req.Name,
-		Status:            "pending",
+		Status:            models.D4SignStatusPending,
 		DocumentType:      req.DocumentType,
 		RelatedEntityID:   req.RelatedEntityID,
 		RelatedEntityType: req.RelatedEntityType,
 		CreatedBy:         createdBy,
+		SequentialSigning: req.Sequential == nil || *req.Sequential,
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
 	}
@@ -116,72 +219,177 @@ func (s *D4SignService) CreateDocument(ctx context.Context, safeUUID string, req
 	return doc, nil
 }
 
+// AddSigners cadastra os signatários na D4Sign e localmente, com a posição de cada um na ordem de assinatura
 func (s *D4SignService) AddSigners(ctx context.Context, documentUUID string, signers []models.D4SignSigner) error {
-	endpoint := fmt.Sprintf("/documents/%s/createsigner", documentUUID)
+	doc, err := s.GetDocumentByUUID(ctx, documentUUID)
+	if err != nil {
+		return err
+	}
+	ordered, err := orderSigners(signers)
+	if err != nil {
+		return err
+	}
 
-	s.logger.WithContext(ctx).Infof("Adicionando %d signatários ao documento %s", len(signers), documentUUID)
+	endpoint := fmt.Sprintf("/documents/%s/createlist", documentUUID)
 
-	payload := map[string]interface{}{
-		"signers": signers,
+	s.logger.WithContext(ctx).Infof("Adicionando %d signatários ao documento %s", len(ordered), documentUUID)
+
+	list := make([]map[string]string, len(ordered))
+	for i, signer := range ordered {
+		list[i] = map[string]string{
+			"email":                 signer.Email,
+			"act":                   d4signSignerActs[signer.Role],
+			"foreign":               "0",
+			"certificadoicpbr":      "0",
+			"assinatura_presencial": "0",
+			"embed_methodauth":      "email",
+			"upload_allow":          "0",
+		}
 	}
 
-	resp, err := s.doRequest(ctx, "POST", endpoint, payload)
-	if err != nil {
+	var result struct {
+		Message []struct {
+			KeySigner string `json:"key_signer"`
+			Email     string `json:"email"`
+		} `json:"message"`
+	}
+	if err := s.call(ctx, http.MethodPost, endpoint, map[string]interface{}{"signers": list}, &result); err != nil {
 		return fmt.Errorf("failed to add signers to D4Sign: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("D4Sign API returned status %d when adding signers", resp.StatusCode)
+	keys := make(map[string]string, len(result.Message))
+	for _, created := range result.Message {
+		keys[strings.ToLower(created.Email)] = created.KeySigner
 	}
 
+	var offset int64
+	if err := s.db.WithContext(ctx).Model(&models.D4SignDocumentSigner{}).Where("document_id = ?", doc.ID).Count(&offset).Error; err != nil {
+		return fmt.Errorf("failed to count signers: %w", err)
+	}
+
+	records := make([]models.D4SignDocumentSigner, len(ordered))
+	for i, signer := range ordered {
+		records[i] = models.D4SignDocumentSigner{
+			DocumentID:   doc.ID,
+			Email:        signer.Email,
+			Name:         signer.Name,
+			Role:         signer.Role,
+			SigningOrder: int(offset) + i + 1,
+			KeySigner:    keys[strings.ToLower(signer.Email)],
+			Status:       models.D4SignSignerPending,
+		}
+	}
+	if err := s.db.WithContext(ctx).Create(&records).Error; err != nil {
+		return fmt.Errorf("failed to save signers: %w", err)
+	}
+
+	return nil
+}
+
+// registerWebhook cadastra na D4Sign a URL que recebe os eventos do documento, quando configurada
+func (s *D4SignService) registerWebhook(ctx context.Context, documentUUID string) error {
+	if s.config.D4SignWebhookURL == "" {
+		return nil
+	}
+
+	endpoint := fmt.Sprintf("/documents/%s/webhooks", documentUUID)
+	if err := s.call(ctx, http.MethodPost, endpoint, map[string]string{"url": s.config.D4SignWebhookURL}, nil); err != nil {
+		return fmt.Errorf("failed to register D4Sign webhook: %w", err)
+	}
 	return nil
 }
 
 func (s *D4SignService) SendToSign(ctx context.Context, documentUUID string) error {
+	doc, err := s.GetDocumentByUUID(ctx, documentUUID)
+	if err != nil {
+		return err
+	}
+
 	endpoint := fmt.Sprintf("/documents/%s/sendtosigner", documentUUID)
 
 	s.logger.WithContext(ctx).Infof("Enviando documento %s para assinatura", documentUUID)
 
+	workflow := "0" // Assinatura em qualquer ordem
+	if doc.SequentialSigning {
+		workflow = "1"
+	}
 	payload := map[string]interface{}{
-		"message":  "Assinatura de documento EquinoId",
-		"workflow": "0", // Assinatura em qualquer ordem
+		"message":    "Assinatura de documento EquinoId",
+		"skip_email": "0",
+		"workflow":   workflow,
 	}
 
-	resp, err := s.doRequest(ctx, "POST", endpoint, payload)
-	if err != nil {
+	if err := s.call(ctx, http.MethodPost, endpoint, payload, nil); err != nil {
 		return fmt.Errorf("failed to send to sign: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("D4Sign API returned status %d when sending to sign", resp.StatusCode)
-	}
 
 	return nil
 }
 
+// GetDocumentStatus consulta na D4Sign o status do documento e de cada signatário
 func (s *D4SignService) GetDocumentStatus(ctx context.Context, documentUUID string) (*models.D4SignDocumentStatusResponse, error) {
-	endpoint := fmt.Sprintf("/documents/%s", documentUUID)
-
 	s.logger.WithContext(ctx).Infof("Buscando status do documento %s na D4Sign", documentUUID)
 
-	resp, err := s.doRequest(ctx, "GET", endpoint, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call D4Sign API: %w", err)
+	var documents []struct {
+		UUIDDoc    string `json:"uuidDoc"`
+		NameDoc    string `json:"nameDoc"`
+		StatusID   string `json:"statusId"`
+		StatusName string `json:"statusName"`
+	}
+	if err := s.call(ctx, http.MethodGet, fmt.Sprintf("/documents/%s", documentUUID), nil, &documents); err != nil {
+		return nil, err
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("D4Sign API returned no document %s", documentUUID)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("D4Sign API returned status %d", resp.StatusCode)
+	var lists []struct {
+		List []struct {
+			Email      string `json:"email"`
+			UserName   string `json:"user_name"`
+			KeySigner  string `json:"key_signer"`
+			Signed     string `json:"signed"`
+			SignedDate string `json:"date_signed"`
+		} `json:"list"`
+	}
+	if err := s.call(ctx, http.MethodGet, fmt.Sprintf("/documents/%s/list", documentUUID), nil, &lists); err != nil {
+		return nil, err
 	}
 
-	var result models.D4SignDocumentStatusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode D4Sign response: %w", err)
+	result := &models.D4SignDocumentStatusResponse{
+		UUID:   documents[0].UUIDDoc,
+		Name:   documents[0].NameDoc,
+		Status: models.D4SignStatusPending,
+	}
+	switch documents[0].StatusID {
+	case d4signStatusFinished:
+		result.Status = models.D4SignStatusSigned
+	case d4signStatusCancelled:
+		result.Status = models.D4SignStatusCancelled
 	}
 
-	return &result, nil
+	for _, list := range lists {
+		for _, signer := range list.List {
+			status := models.D4SignSignerStatus{
+				Email:     signer.Email,
+				Name:      signer.UserName,
+				KeySigner: signer.KeySigner,
+				Status:    models.D4SignSignerPending,
+			}
+			if signer.Signed == "1" {
+				status.Status = models.D4SignSignerSigned
+				if signedAt, err := time.ParseInLocation(d4signDateLayout, signer.SignedDate, time.Local); err == nil {
+					status.SignedAt = &signedAt
+				}
+			}
+			if status.SignedAt != nil && (result.SignedAt == nil || status.SignedAt.After(*result.SignedAt)) {
+				result.SignedAt = status.SignedAt
+			}
+			result.Signers = append(result.Signers, status)
+		}
+	}
+
+	return result, nil
 }
 
 func (s *D4SignService) SaveDocument(ctx context.Context, doc *models.D4SignDocument) error {
@@ -213,17 +421,456 @@ func (s *D4SignService) UpdateDocumentStatus(ctx context.Context, documentUUID s
 
 func (s *D4SignService) GetDocumentByUUID(ctx context.Context, documentUUID string) (*models.D4SignDocument, error) {
 	var doc models.D4SignDocument
-	if err := s.db.WithContext(ctx).
+	err := s.db.WithContext(ctx).
+		Preload("Signers", func(db *gorm.DB) *gorm.DB { return db.Order("signing_order ASC") }).
 		Where("document_uuid = ?", documentUUID).
-		First(&doc).Error; err != nil {
+		First(&doc).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.NotFoundError{Resource: "d4sign_document", Message: "documento não encontrado", ID: documentUUID}
+		}
 		return nil, fmt.Errorf("document not found: %w", err)
 	}
 	return &doc, nil
 }
 
+// GetDocumentForUser documento com signatários, visível ao criador, aos signatários e a administradores
+func (s *D4SignService) GetDocumentForUser(ctx context.Context, documentUUID string, userID uint, isAdmin bool) (*models.D4SignDocument, error) {
+	doc, err := s.GetDocumentByUUID(ctx, documentUUID)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin || doc.CreatedBy == userID {
+		return doc, nil
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "email").First(&user, userID).Error; err == nil {
+		for _, signer := range doc.Signers {
+			if strings.EqualFold(signer.Email, user.Email) {
+				return doc, nil
+			}
+		}
+	}
+	return nil, &apperrors.AuthorizationError{Message: "sem acesso a este documento"}
+}
+
+// SignedFile conteúdo do PDF final assinado, já baixado da D4Sign
+func (s *D4SignService) SignedFile(ctx context.Context, documentUUID string, userID uint, isAdmin bool) (*models.D4SignDocument, []byte, error) {
+	doc, err := s.GetDocumentForUser(ctx, documentUUID, userID, isAdmin)
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc.SignedFilePath == "" {
+		return nil, nil, &apperrors.NotFoundError{Resource: "d4sign_signed_file", Message: "documento assinado ainda não disponível", ID: documentUUID}
+	}
+
+	content, err := os.ReadFile(doc.SignedFilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read signed file: %w", err)
+	}
+	return doc, content, nil
+}
+
+// SyncDocument reconcilia o documento com a D4Sign: status dos signatários, conclusão e download do PDF assinado
+func (s *D4SignService) SyncDocument(ctx context.Context, documentUUID string) (*models.D4SignDocument, error) {
+	doc, err := s.GetDocumentByUUID(ctx, documentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := s.GetDocumentStatus(ctx, documentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, signer := range remote.Signers {
+		if signer.Status != models.D4SignSignerSigned {
+			continue
+		}
+		signedAt := time.Now()
+		if signer.SignedAt != nil {
+			signedAt = *signer.SignedAt
+		}
+		_, err := s.updateSignerStatus(ctx, doc, signer.KeySigner, signer.Email, models.D4SignSignerSigned, signedAt)
+		if err != nil && !apperrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	switch remote.Status {
+	case models.D4SignStatusSigned:
+		signedAt := time.Now()
+		if remote.SignedAt != nil {
+			signedAt = *remote.SignedAt
+		}
+		if _, err := s.completeDocument(ctx, doc, signedAt); err != nil {
+			return nil, err
+		}
+	case models.D4SignStatusCancelled:
+		if _, err := s.transitionDocument(ctx, doc, models.D4SignStatusCancelled, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&models.D4SignDocument{}).Where("id = ?", doc.ID).Update("last_synced_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to update sync time: %w", err)
+	}
+
+	return s.GetDocumentByUUID(ctx, documentUUID)
+}
+
+// Reconcile sincroniza documentos pendentes e assinados sem PDF final ou sem carimbo de tempo, cobrindo webhooks
+// perdidos e falhas da TSA; devolve quantos foram sincronizados
+func (s *D4SignService) Reconcile(ctx context.Context) (int, error) {
+	var docs []models.D4SignDocument
+	err := s.db.WithContext(ctx).
+		Where("status = ? OR (status = ? AND (signed_file_path = '' OR signed_file_path IS NULL OR ((timestamp_token = '' OR timestamp_token IS NULL) AND ?)))",
+			models.D4SignStatusPending, models.D4SignStatusSigned, s.timestamper != nil).
+		Order("last_synced_at IS NOT NULL, last_synced_at ASC").
+		Limit(d4signSyncBatchSize).
+		Find(&docs).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list documents to reconcile: %w", err)
+	}
+
+	synced := 0
+	for _, doc := range docs {
+		if _, err := s.SyncDocument(ctx, doc.DocumentUUID); err != nil {
+			s.logger.LogError(err, "D4SignService.Reconcile", logging.Fields{"document_uuid": doc.DocumentUUID})
+			continue
+		}
+		synced++
+	}
+	return synced, nil
+}
+
+// SendReminders reenvia o pedido de assinatura aos signatários pendentes sem lembrete no intervalo configurado. Na
+// assinatura sequencial só o próximo da fila é lembrado.
+func (s *D4SignService) SendReminders(ctx context.Context) (int, error) {
+	interval := s.config.D4SignReminderInterval
+	if interval <= 0 {
+		return 0, nil
+	}
+
+	var docs []models.D4SignDocument
+	err := s.db.WithContext(ctx).
+		Preload("Signers", func(db *gorm.DB) *gorm.DB { return db.Order("signing_order ASC") }).
+		Where("status = ?", models.D4SignStatusPending).
+		Find(&docs).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending documents: %w", err)
+	}
+
+	cutoff := time.Now().Add(-interval)
+	sent := 0
+	for _, doc := range docs {
+		for _, signer := range awaitingSigners(&doc) {
+			last := doc.CreatedAt
+			if signer.LastReminderAt != nil {
+				last = *signer.LastReminderAt
+			}
+			if last.After(cutoff) {
+				continue
+			}
+
+			if err := s.remind(ctx, &doc, signer); err != nil {
+				s.logger.LogError(err, "D4SignService.SendReminders", logging.Fields{"document_uuid": doc.DocumentUUID, "signer_id": signer.ID})
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *D4SignService) remind(ctx context.Context, doc *models.D4SignDocument, signer *models.D4SignDocumentSigner) error {
+	payload := map[string]string{
+		"email":      signer.Email,
+		"key_signer": signer.KeySigner,
+	}
+	if err := s.call(ctx, http.MethodPost, fmt.Sprintf("/documents/%s/resend", doc.DocumentUUID), payload, nil); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.db.WithContext(ctx).Model(&models.D4SignDocumentSigner{}).Where("id = ?", signer.ID).Updates(map[string]interface{}{
+		"last_reminder_at": now,
+		"reminder_count":   gorm.Expr("reminder_count + 1"),
+		"updated_at":       now,
+	}).Error
+}
+
+// DownloadSignedFile baixa o PDF final assinado e o guarda no diretório de documentos
+func (s *D4SignService) DownloadSignedFile(ctx context.Context, doc *models.D4SignDocument) error {
+	var link struct {
+		URL  string `json:"url"`
+		Name string `json:"name"`
+	}
+	payload := map[string]string{"type": "PDF", "language": "pt"}
+	if err := s.call(ctx, http.MethodPost, fmt.Sprintf("/documents/%s/download", doc.DocumentUUID), payload, &link); err != nil {
+		return err
+	}
+	if link.URL == "" {
+		return fmt.Errorf("D4Sign API returned no download url for %s", doc.DocumentUUID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download signed file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("signed file download returned status %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, d4signMaxSignedFileSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read signed file: %w", err)
+	}
+	if len(content) > d4signMaxSignedFileSize {
+		return fmt.Errorf("signed file exceeds %d bytes", d4signMaxSignedFileSize)
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF")) {
+		return fmt.Errorf("signed file for %s is not a PDF", doc.DocumentUUID)
+	}
+
+	dir := filepath.Join(s.config.UploadPath, "d4sign")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create signed file directory: %w", err)
+	}
+	path := filepath.Join(dir, doc.DocumentUUID+".pdf")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write signed file: %w", err)
+	}
+
+	digest := sha256.Sum256(content)
+	doc.SignedFilePath = path
+	doc.SignedFileHash = hex.EncodeToString(digest[:])
+	return s.db.WithContext(ctx).Model(&models.D4SignDocument{}).Where("id = ?", doc.ID).Updates(map[string]interface{}{
+		"signed_file_path": doc.SignedFilePath,
+		"signed_file_hash": doc.SignedFileHash,
+		"updated_at":       time.Now(),
+	}).Error
+}
+
 func (s *D4SignService) GetEmbedURL(ctx context.Context, documentUUID string, signerEmail string) (string, error) {
 	// Endpoint para gerar link de assinatura embutida
 	s.logger.WithContext(ctx).Infof("Gerando URL de embed para %s assinar o documento %s", signerEmail, documentUUID)
 
 	return fmt.Sprintf("https://secure.d4sign.com.br/embed/%s?email=%s", documentUUID, signerEmail), nil
 }
+
+// transitionDocument muda o status de um documento pendente. Assinado, cancelado e expirado são finais: eventos
+// atrasados ou fora de ordem não os sobrescrevem e a função devolve false.
+func (s *D4SignService) transitionDocument(ctx context.Context, doc *models.D4SignDocument, status string, signedAt *time.Time) (bool, error) {
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	}
+	if signedAt != nil {
+		updates["signed_at"] = signedAt
+	}
+
+	result := s.db.WithContext(ctx).
+		Model(&models.D4SignDocument{}).
+		Where("id = ? AND status = ?", doc.ID, models.D4SignStatusPending).
+		Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update document status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var current models.D4SignDocument
+		if err := s.db.WithContext(ctx).Select("status").First(&current, doc.ID).Error; err == nil {
+			doc.Status = current.Status
+		}
+		return false, nil
+	}
+
+	doc.Status = status
+	doc.SignedAt = signedAt
+	s.logger.WithContext(ctx).Infof("Status do documento %s atualizado para %s", doc.DocumentUUID, status)
+	return true, nil
+}
+
+// completeDocument marca o documento como assinado por todos e baixa o PDF final; falhas no download ficam para a
+// reconciliação. Devolve false quando o documento já estava concluído ou foi cancelado antes.
+func (s *D4SignService) completeDocument(ctx context.Context, doc *models.D4SignDocument, signedAt time.Time) (bool, error) {
+	applied, err := s.transitionDocument(ctx, doc, models.D4SignStatusSigned, &signedAt)
+	if err != nil {
+		return false, err
+	}
+	if applied {
+		err := s.db.WithContext(ctx).Model(&models.D4SignDocumentSigner{}).
+			Where("document_id = ? AND status = ?", doc.ID, models.D4SignSignerPending).
+			Updates(map[string]interface{}{"status": models.D4SignSignerSigned, "signed_at": signedAt, "updated_at": time.Now()}).Error
+		if err != nil {
+			return false, fmt.Errorf("failed to update signers: %w", err)
+		}
+	}
+
+	if doc.Status == models.D4SignStatusSigned && doc.SignedFilePath == "" {
+		if err := s.DownloadSignedFile(ctx, doc); err != nil {
+			s.logger.LogError(err, "D4SignService.DownloadSignedFile", logging.Fields{"document_uuid": doc.DocumentUUID})
+		}
+	}
+	if doc.Status == models.D4SignStatusSigned && doc.SignedFileHash != "" && doc.TimestampToken == "" {
+		if err := s.stampSignedFile(ctx, doc); err != nil {
+			s.logger.LogError(err, "D4SignService.stampSignedFile", logging.Fields{"document_uuid": doc.DocumentUUID})
+		}
+	}
+	return applied, nil
+}
+
+// stampSignedFile carimba o SHA-256 do PDF final: o token da TSA é a prova do momento da conclusão, no lugar do
+// horário informado pela D4Sign ou do relógio do servidor
+func (s *D4SignService) stampSignedFile(ctx context.Context, doc *models.D4SignDocument) error {
+	if s.timestamper == nil {
+		return nil
+	}
+	digest, err := hex.DecodeString(doc.SignedFileHash)
+	if err != nil {
+		return fmt.Errorf("invalid signed file hash: %w", err)
+	}
+
+	token, err := s.timestamper.Timestamp(ctx, digest)
+	if err != nil {
+		return err
+	}
+
+	doc.TimestampToken = base64.StdEncoding.EncodeToString(token.Token)
+	doc.TimestampTime = &token.Time
+	doc.TimestampAuthority = token.Authority
+	return s.db.WithContext(ctx).Model(&models.D4SignDocument{}).Where("id = ?", doc.ID).Updates(map[string]interface{}{
+		"timestamp_token":     doc.TimestampToken,
+		"timestamp_time":      doc.TimestampTime,
+		"timestamp_authority": doc.TimestampAuthority,
+		"updated_at":          time.Now(),
+	}).Error
+}
+
+// updateSignerStatus atualiza o signatário identificado pela chave da D4Sign ou, na falta dela, pelo e-mail. Só
+// signatários pendentes mudam: uma recusa atrasada não desfaz uma assinatura.
+func (s *D4SignService) updateSignerStatus(ctx context.Context, doc *models.D4SignDocument, keySigner, email, status string, occurredAt time.Time) (bool, error) {
+	var signer *models.D4SignDocumentSigner
+	for i := range doc.Signers {
+		candidate := &doc.Signers[i]
+		if (keySigner != "" && candidate.KeySigner == keySigner) || (keySigner == "" && strings.EqualFold(candidate.Email, email)) {
+			signer = candidate
+			break
+		}
+	}
+	if signer == nil {
+		return false, &apperrors.NotFoundError{Resource: "d4sign_signer", Message: "signatário não encontrado no documento", ID: email}
+	}
+	if signer.Status != models.D4SignSignerPending {
+		return false, nil
+	}
+
+	updates := map[string]interface{}{"status": status, "updated_at": time.Now()}
+	switch status {
+	case models.D4SignSignerSigned:
+		updates["signed_at"] = occurredAt
+	case models.D4SignSignerRejected:
+		updates["rejected_at"] = occurredAt
+	}
+	result := s.db.WithContext(ctx).Model(&models.D4SignDocumentSigner{}).
+		Where("id = ? AND status = ?", signer.ID, models.D4SignSignerPending).
+		Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update signer status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	signer.Status = status
+	switch status {
+	case models.D4SignSignerSigned:
+		signer.SignedAt = &occurredAt
+	case models.D4SignSignerRejected:
+		signer.RejectedAt = &occurredAt
+	}
+
+	s.logger.WithFields(logging.Fields{
+		"document_uuid": doc.DocumentUUID,
+		"signer_id":     signer.ID,
+		"role":          signer.Role,
+		"status":        status,
+	}).Info("Status de signatário D4Sign atualizado")
+	return true, nil
+}
+
+// awaitingSigners signatários que ainda precisam assinar agora: todos os pendentes ou, na assinatura sequencial,
+// apenas o primeiro da fila (nenhum, se alguém recusou)
+func awaitingSigners(doc *models.D4SignDocument) []*models.D4SignDocumentSigner {
+	var awaiting []*models.D4SignDocumentSigner
+	for i := range doc.Signers {
+		signer := &doc.Signers[i]
+		switch signer.Status {
+		case models.D4SignSignerRejected:
+			if doc.SequentialSigning {
+				return nil
+			}
+		case models.D4SignSignerPending:
+			awaiting = append(awaiting, signer)
+			if doc.SequentialSigning {
+				return awaiting
+			}
+		}
+	}
+	return awaiting
+}
+
+// orderSigners valida os signatários e os ordena pela ordem explícita ou, sem ela, pelo papel
+func orderSigners(signers []models.D4SignSigner) ([]models.D4SignSigner, error) {
+	ordered := make([]models.D4SignSigner, len(signers))
+	copy(ordered, signers)
+
+	explicit := 0
+	emails := make(map[string]bool, len(ordered))
+	positions := make(map[int]bool, len(ordered))
+	for i := range ordered {
+		signer := &ordered[i]
+		signer.Email = strings.TrimSpace(signer.Email)
+		if signer.Email == "" {
+			return nil, &apperrors.ValidationError{Field: "signers.email", Message: "e-mail do signatário é obrigatório"}
+		}
+		key := strings.ToLower(signer.Email)
+		if emails[key] {
+			return nil, &apperrors.ValidationError{Field: "signers.email", Message: "signatário repetido", Value: signer.Email}
+		}
+		emails[key] = true
+
+		if signer.Role == "" {
+			signer.Role = models.D4SignRoleSignatario
+		}
+		if _, ok := d4signSignerActs[signer.Role]; !ok {
+			return nil, &apperrors.ValidationError{Field: "signers.role", Message: "papel de signatário inválido", Value: signer.Role}
+		}
+
+		if signer.Order > 0 {
+			if positions[signer.Order] {
+				return nil, &apperrors.ValidationError{Field: "signers.order", Message: "ordem de assinatura repetida", Value: signer.Order}
+			}
+			positions[signer.Order] = true
+			explicit++
+		}
+	}
+	if explicit > 0 && explicit != len(ordered) {
+		return nil, &apperrors.ValidationError{Field: "signers.order", Message: "informe a ordem de todos os signatários ou de nenhum"}
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if explicit > 0 {
+			return ordered[i].Order < ordered[j].Order
+		}
+		return d4signRoleOrder[ordered[i].Role] < d4signRoleOrder[ordered[j].Role]
+	})
+	return ordered, nil
+}
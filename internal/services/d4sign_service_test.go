@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/equinoid/backend/internal/config"
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeD4Sign servidor local que imita os endpoints da API D4Sign usados pelo serviço
+type fakeD4Sign struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	signers  []map[string]string
+	workflow string
+	statusID string
+	signed   map[string]bool
+	resent   []string
+}
+
+func newFakeD4Sign(t *testing.T) *fakeD4Sign {
+	fake := &fakeD4Sign{statusID: "2", signed: map[string]bool{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/documents/", fake.handle)
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7 contrato assinado"))
+	})
+	fake.server = httptest.NewServer(mux)
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeD4Sign) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Query().Get("tokenAPI") != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var body map[string]json.RawMessage
+	json.NewDecoder(r.Body).Decode(&body)
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	action := ""
+	if len(parts) > 2 {
+		action = parts[2]
+	}
+
+	switch action {
+	case "uploadbinary":
+		writeJSON(w, map[string]string{"uuid": "doc-1"})
+	case "createlist":
+		var signers []map[string]string
+		json.Unmarshal(body["signers"], &signers)
+		created := make([]map[string]string, len(signers))
+		for i, signer := range signers {
+			signer["key_signer"] = fmt.Sprintf("key-%d", i+1)
+			created[i] = map[string]string{"email": signer["email"], "key_signer": signer["key_signer"]}
+		}
+		f.signers = signers
+		writeJSON(w, map[string]interface{}{"message": created})
+	case "sendtosigner":
+		json.Unmarshal(body["workflow"], &f.workflow)
+		writeJSON(w, map[string]string{"message": "File sent to successfully signing"})
+	case "resend":
+		var email string
+		json.Unmarshal(body["email"], &email)
+		f.resent = append(f.resent, email)
+		writeJSON(w, map[string]string{"message": "Email resent"})
+	case "list":
+		list := make([]map[string]string, len(f.signers))
+		for i, signer := range f.signers {
+			entry := map[string]string{"email": signer["email"], "key_signer": signer["key_signer"], "signed": "0"}
+			if f.signed[signer["email"]] {
+				entry["signed"] = "1"
+				entry["date_signed"] = "2024-05-10 14:30:00"
+			}
+			list[i] = entry
+		}
+		writeJSON(w, []map[string]interface{}{{"uuidDoc": "doc-1", "list": list}})
+	case "download":
+		writeJSON(w, map[string]string{"url": f.server.URL + "/files/doc-1.pdf", "name": "contrato"})
+	case "":
+		writeJSON(w, []map[string]string{{"uuidDoc": "doc-1", "nameDoc": "contrato", "statusId": f.statusID}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// fakeTimestamper TSA local que registra os digests carimbados e pode ficar indisponível
+type fakeTimestamper struct {
+	mu      sync.Mutex
+	err     error
+	digests [][]byte
+}
+
+func (f *fakeTimestamper) Timestamp(ctx context.Context, digest []byte) (*models.TimestampToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.digests = append(f.digests, digest)
+	return &models.TimestampToken{Token: append([]byte("tst:"), digest...), Time: time.Now(), Authority: "TSA de teste"}, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func setupD4SignService(t *testing.T) (*D4SignService, *fakeD4Sign, *gorm.DB) {
+	db := setupTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.D4SignDocument{}, &models.D4SignDocumentSigner{}, &models.D4SignWebhookLog{}, &models.D4SignWebhookEvent{}))
+
+	fake := newFakeD4Sign(t)
+	cfg := &config.Config{
+		D4SignAPIURL:           fake.server.URL,
+		D4SignTokenAPI:         "token",
+		D4SignSafeUUID:         "cofre",
+		D4SignReminderInterval: time.Hour,
+		D4SignWebhookSecret:    "segredo",
+		UploadPath:             t.TempDir(),
+	}
+	return NewD4SignService(db, logging.NewLogger("error"), cfg), fake, db
+}
+
+func signedWebhook(t *testing.T, secret string, payload models.D4SignWebhookPayload) *D4SignWebhookRequest {
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return &D4SignWebhookRequest{Body: body, Signature: "sha256=" + hex.EncodeToString(mac.Sum(nil)), RemoteIP: "127.0.0.1"}
+}
+
+func contractRequest() models.CreateD4SignDocumentRequest {
+	return models.CreateD4SignDocumentRequest{
+		Base64File:   "JVBERi0xLjc=",
+		Name:         "contrato",
+		DocumentType: "contrato",
+		Signers: []models.D4SignSigner{
+			{Email: "testemunha@example.com", Role: models.D4SignRoleTestemunha},
+			{Email: "comprador@example.com", Role: models.D4SignRoleComprador},
+			{Email: "vendedor@example.com", Role: models.D4SignRoleVendedor},
+		},
+	}
+}
+
+func TestD4SignService_CreateContractOrdersSigners(t *testing.T) {
+	service, fake, _ := setupD4SignService(t)
+
+	doc, err := service.CreateContract(context.Background(), contractRequest(), 7)
+	require.NoError(t, err)
+
+	assert.Equal(t, "doc-1", doc.DocumentUUID)
+	assert.Equal(t, uint(7), doc.CreatedBy)
+	assert.True(t, doc.SequentialSigning)
+	assert.Equal(t, "1", fake.workflow)
+	require.Len(t, doc.Signers, 3)
+	assert.Equal(t, "vendedor@example.com", doc.Signers[0].Email)
+	assert.Equal(t, "comprador@example.com", doc.Signers[1].Email)
+	assert.Equal(t, "testemunha@example.com", doc.Signers[2].Email)
+	assert.Equal(t, "key-1", doc.Signers[0].KeySigner)
+	assert.Equal(t, "4", fake.signers[0]["act"])
+	assert.Equal(t, "5", fake.signers[2]["act"])
+}
+
+func TestD4SignService_CreateContractRejectsInvalidSigners(t *testing.T) {
+	service, _, _ := setupD4SignService(t)
+
+	req := contractRequest()
+	req.Signers[0].Role = "padrinho"
+	_, err := service.CreateContract(context.Background(), req, 7)
+	assert.Error(t, err)
+
+	req = contractRequest()
+	req.Signers[0].Order = 1
+	_, err = service.CreateContract(context.Background(), req, 7)
+	assert.Error(t, err)
+}
+
+func TestD4SignService_WebhookAndReminders(t *testing.T) {
+	service, fake, db := setupD4SignService(t)
+	ctx := context.Background()
+
+	doc, err := service.CreateContract(ctx, contractRequest(), 7)
+	require.NoError(t, err)
+	db.Model(&models.D4SignDocument{}).Where("id = ?", doc.ID).Update("created_at", time.Now().Add(-2*time.Hour))
+
+	sent, err := service.SendReminders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []string{"vendedor@example.com"}, fake.resent)
+
+	result, err := service.ReceiveWebhook(ctx, signedWebhook(t, "segredo", models.D4SignWebhookPayload{
+		ID:       "evt-1",
+		Event:    "signer_signed",
+		Document: models.D4SignWebhookDocument{UUID: "doc-1"},
+		Signer:   models.D4SignWebhookSigner{Email: "vendedor@example.com", KeySigner: "key-1"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, models.D4SignWebhookProcessed, result.Status)
+	doc = result.Document
+	assert.Equal(t, models.D4SignSignerSigned, doc.Signers[0].Status)
+	assert.Equal(t, models.D4SignStatusPending, doc.Status)
+
+	sent, err = service.SendReminders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, "comprador@example.com", fake.resent[1])
+
+	sent, err = service.SendReminders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestD4SignService_ReconcileDownloadsSignedFile(t *testing.T) {
+	service, fake, _ := setupD4SignService(t)
+	tsa := &fakeTimestamper{err: fmt.Errorf("TSA indisponível")}
+	service.SetTimestamper(tsa)
+	ctx := context.Background()
+
+	_, err := service.CreateContract(ctx, contractRequest(), 7)
+	require.NoError(t, err)
+
+	fake.mu.Lock()
+	fake.signed["vendedor@example.com"] = true
+	fake.mu.Unlock()
+
+	synced, err := service.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, synced)
+
+	doc, err := service.GetDocumentByUUID(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.D4SignStatusPending, doc.Status)
+	assert.Equal(t, models.D4SignSignerSigned, doc.Signers[0].Status)
+	assert.Equal(t, models.D4SignSignerPending, doc.Signers[1].Status)
+	assert.NotNil(t, doc.LastSyncedAt)
+
+	fake.mu.Lock()
+	fake.statusID = "4"
+	fake.mu.Unlock()
+
+	_, err = service.Reconcile(ctx)
+	require.NoError(t, err)
+
+	doc, content, err := service.SignedFile(ctx, "doc-1", 7, false)
+	require.NoError(t, err)
+	assert.Equal(t, models.D4SignStatusSigned, doc.Status)
+	assert.NotEmpty(t, doc.SignedFileHash)
+	assert.True(t, strings.HasPrefix(string(content), "%PDF"))
+	for _, signer := range doc.Signers {
+		assert.Equal(t, models.D4SignSignerSigned, signer.Status)
+	}
+
+	_, _, err = service.SignedFile(ctx, "doc-1", 99, false)
+	assert.Error(t, err)
+
+	_, err = os.Stat(doc.SignedFilePath)
+	assert.NoError(t, err)
+
+	// TSA fora do ar na conclusão: o documento fica sem carimbo e volta na próxima reconciliação
+	assert.Empty(t, doc.TimestampToken)
+	tsa.mu.Lock()
+	tsa.err = nil
+	tsa.mu.Unlock()
+
+	synced, err = service.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, synced)
+
+	doc, err = service.GetDocumentByUUID(ctx, "doc-1")
+	require.NoError(t, err)
+	digest := sha256.Sum256(content)
+	require.Len(t, tsa.digests, 1)
+	assert.Equal(t, digest[:], tsa.digests[0])
+	assert.NotEmpty(t, doc.TimestampToken)
+	assert.Equal(t, "TSA de teste", doc.TimestampAuthority)
+	assert.NotNil(t, doc.TimestampTime)
+
+	synced, err = service.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, synced)
+}
+
+func TestD4SignService_ReceiveWebhookRejectsUnauthenticated(t *testing.T) {
+	service, _, db := setupD4SignService(t)
+	ctx := context.Background()
+
+	_, err := service.CreateContract(ctx, contractRequest(), 7)
+	require.NoError(t, err)
+
+	payload := models.D4SignWebhookPayload{ID: "evt-1", Event: "document_cancelled", Document: models.D4SignWebhookDocument{UUID: "doc-1"}}
+
+	_, err = service.ReceiveWebhook(ctx, signedWebhook(t, "outro", payload))
+	assert.Error(t, err)
+
+	unsigned := signedWebhook(t, "segredo", payload)
+	unsigned.Signature = ""
+	_, err = service.ReceiveWebhook(ctx, unsigned)
+	assert.Error(t, err)
+
+	payload.Document.UUID = "inexistente"
+	_, err = service.ReceiveWebhook(ctx, signedWebhook(t, "segredo", payload))
+	assert.Error(t, err)
+
+	doc, err := service.GetDocumentByUUID(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.D4SignStatusPending, doc.Status)
+
+	var rejected int64
+	db.Model(&models.D4SignWebhookLog{}).Where("status = ?", models.D4SignWebhookRejected).Count(&rejected)
+	assert.Equal(t, int64(3), rejected)
+}
+
+func TestD4SignService_ReceiveWebhookIdempotentAndOrdered(t *testing.T) {
+	service, _, _ := setupD4SignService(t)
+	ctx := context.Background()
+
+	_, err := service.CreateContract(ctx, contractRequest(), 7)
+	require.NoError(t, err)
+
+	signed := models.D4SignWebhookPayload{ID: "evt-signed", Event: "document_signed", Document: models.D4SignWebhookDocument{UUID: "doc-1"}}
+	result, err := service.ReceiveWebhook(ctx, signedWebhook(t, "segredo", signed))
+	require.NoError(t, err)
+	assert.Equal(t, models.D4SignWebhookProcessed, result.Status)
+
+	result, err = service.ReceiveWebhook(ctx, signedWebhook(t, "segredo", signed))
+	require.NoError(t, err)
+	assert.Equal(t, models.D4SignWebhookDuplicate, result.Status)
+
+	cancelled := models.D4SignWebhookPayload{ID: "evt-cancelled", Event: "document_cancelled", Document: models.D4SignWebhookDocument{UUID: "doc-1"}}
+	result, err = service.ReceiveWebhook(ctx, signedWebhook(t, "segredo", cancelled))
+	require.NoError(t, err)
+	assert.Equal(t, models.D4SignWebhookStale, result.Status)
+
+	rejected := models.D4SignWebhookPayload{
+		ID:       "evt-rejected",
+		Event:    "signer_rejected",
+		Document: models.D4SignWebhookDocument{UUID: "doc-1"},
+		Signer:   models.D4SignWebhookSigner{KeySigner: "key-2"},
+	}
+	result, err = service.ReceiveWebhook(ctx, signedWebhook(t, "segredo", rejected))
+	require.NoError(t, err)
+	assert.Equal(t, models.D4SignWebhookStale, result.Status)
+
+	doc, err := service.GetDocumentByUUID(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.D4SignStatusSigned, doc.Status)
+	for _, signer := range doc.Signers {
+		assert.Equal(t, models.D4SignSignerSigned, signer.Status)
+	}
+}
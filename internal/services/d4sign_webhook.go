@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"gorm.io/gorm/clause"
+)
+
+// Cabeçalhos dos webhooks recebidos da D4Sign
+const (
+	D4SignHMACHeader    = "Content-Hmac"      // "sha256=<hex>" do HMAC-SHA256 do corpo com o segredo do cofre
+	D4SignSecretHeader  = "X-D4Sign-Secret"   // segredo compartilhado do cofre, alternativa ao HMAC
+	D4SignEventIDHeader = "X-D4Sign-Event-Id" // identificador do evento quando não vem no corpo
+)
+
+// maxD4SignWebhookLogPayload limite do corpo guardado no log de webhooks recebidos
+const maxD4SignWebhookLogPayload = 64 << 10
+
+// D4SignWebhookRequest webhook recebido com as credenciais enviadas pela D4Sign
+type D4SignWebhookRequest struct {
+	Body      []byte
+	Signature string
+	Secret    string
+	EventID   string
+	RemoteIP  string
+}
+
+// D4SignWebhookResult resultado do processamento: processado, repetido, fora de ordem ou ignorado
+type D4SignWebhookResult struct {
+	EventID  string                 `json:"event_id"`
+	Status   string                 `json:"status"`
+	Document *models.D4SignDocument `json:"-"`
+}
+
+// ReceiveWebhook autentica o webhook pelo segredo do cofre do documento, descarta eventos já processados e aplica o
+// evento sem sobrescrever estados finais. Todo webhook recebido fica registrado em d4sign_webhook_logs.
+func (s *D4SignService) ReceiveWebhook(ctx context.Context, req *D4SignWebhookRequest) (*D4SignWebhookResult, error) {
+	entry := &models.D4SignWebhookLog{
+		RemoteIP:   req.RemoteIP,
+		Payload:    string(req.Body),
+		ReceivedAt: time.Now(),
+	}
+	if len(entry.Payload) > maxD4SignWebhookLogPayload {
+		entry.Payload = entry.Payload[:maxD4SignWebhookLogPayload]
+	}
+
+	var payload models.D4SignWebhookPayload
+	if err := json.Unmarshal(req.Body, &payload); err != nil || payload.Document.UUID == "" || payload.Event == "" {
+		s.recordWebhook(ctx, entry, models.D4SignWebhookRejected, "payload inválido")
+		return nil, &apperrors.ValidationError{Field: "payload", Message: "payload de webhook inválido"}
+	}
+	entry.DocumentUUID = payload.Document.UUID
+	entry.Event = payload.Event
+
+	doc, err := s.GetDocumentByUUID(ctx, payload.Document.UUID)
+	if err != nil && !apperrors.IsNotFound(err) {
+		s.recordWebhook(ctx, entry, models.D4SignWebhookFailed, err.Error())
+		return nil, err
+	}
+	// Documento desconhecido e credencial inválida recebem a mesma resposta, para não revelar UUIDs existentes
+	if doc == nil || !s.authenticWebhook(doc.SafeUUID, req) {
+		s.recordWebhook(ctx, entry, models.D4SignWebhookRejected, "autenticação inválida")
+		s.logger.LogSecurityEvent("d4sign_webhook_rejected", "Webhook D4Sign sem autenticação válida para "+payload.Document.UUID, 0, req.RemoteIP)
+		return nil, &apperrors.AuthorizationError{Message: "webhook não autenticado"}
+	}
+
+	eventID := webhookEventID(&payload, req.EventID)
+	entry.EventID = eventID
+	result := &D4SignWebhookResult{EventID: eventID, Document: doc}
+
+	claimed, err := s.claimWebhookEvent(ctx, eventID, &payload)
+	if err != nil {
+		s.recordWebhook(ctx, entry, models.D4SignWebhookFailed, err.Error())
+		return nil, err
+	}
+	if !claimed {
+		result.Status = models.D4SignWebhookDuplicate
+		s.recordWebhook(ctx, entry, result.Status, "")
+		return result, nil
+	}
+
+	result.Status, err = s.applyWebhookEvent(ctx, doc, &payload)
+	if err != nil {
+		// Libera o evento para que o reenvio da D4Sign seja processado
+		if releaseErr := s.db.WithContext(ctx).Where("event_id = ?", eventID).Delete(&models.D4SignWebhookEvent{}).Error; releaseErr != nil {
+			s.logger.LogError(releaseErr, "D4SignService.ReceiveWebhook", logging.Fields{"event_id": eventID})
+		}
+		s.recordWebhook(ctx, entry, models.D4SignWebhookFailed, err.Error())
+		return nil, err
+	}
+
+	s.recordWebhook(ctx, entry, result.Status, "")
+	if refreshed, err := s.GetDocumentByUUID(ctx, doc.DocumentUUID); err == nil {
+		result.Document = refreshed
+	}
+	return result, nil
+}
+
+// applyWebhookEvent aplica o evento ao documento e aos signatários; eventos que chegam depois de um estado final
+// (assinado, cancelado, expirado) são reportados como fora de ordem e não alteram nada
+func (s *D4SignService) applyWebhookEvent(ctx context.Context, doc *models.D4SignDocument, payload *models.D4SignWebhookPayload) (string, error) {
+	occurredAt := payload.Timestamp
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+
+	var applied bool
+	var err error
+	switch payload.Event {
+	case "signer_signed":
+		applied, err = s.updateSignerStatus(ctx, doc, payload.Signer.KeySigner, payload.Signer.Email, models.D4SignSignerSigned, occurredAt)
+	case "signer_rejected":
+		if doc.Status != models.D4SignStatusPending {
+			break
+		}
+		applied, err = s.updateSignerStatus(ctx, doc, payload.Signer.KeySigner, payload.Signer.Email, models.D4SignSignerRejected, occurredAt)
+	case "document_signed":
+		applied, err = s.completeDocument(ctx, doc, occurredAt)
+	case "document_cancelled":
+		applied, err = s.transitionDocument(ctx, doc, models.D4SignStatusCancelled, nil)
+	case "document_expired":
+		applied, err = s.transitionDocument(ctx, doc, models.D4SignStatusExpired, nil)
+	default:
+		s.logger.Warnf("Evento D4Sign desconhecido ignorado: %s (documento %s)", payload.Event, doc.DocumentUUID)
+		return models.D4SignWebhookIgnored, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if !applied {
+		s.logger.WithFields(logging.Fields{
+			"document_uuid": doc.DocumentUUID,
+			"event":         payload.Event,
+			"status":        doc.Status,
+		}).Warn("Evento D4Sign fora de ordem ignorado")
+		return models.D4SignWebhookStale, nil
+	}
+	return models.D4SignWebhookProcessed, nil
+}
+
+// authenticWebhook confere o HMAC do corpo ou o segredo compartilhado com o segredo configurado para o cofre
+func (s *D4SignService) authenticWebhook(safeUUID string, req *D4SignWebhookRequest) bool {
+	secret := s.webhookSecret(safeUUID)
+	if secret == "" {
+		s.logger.Warnf("Nenhum segredo de webhook D4Sign configurado para o cofre %s", safeUUID)
+		return false
+	}
+
+	if req.Signature != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(req.Body)
+		expected := hex.EncodeToString(mac.Sum(nil))
+		signature := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(req.Signature), "sha256="))
+		return hmac.Equal([]byte(signature), []byte(expected))
+	}
+	if req.Secret != "" {
+		return hmac.Equal([]byte(req.Secret), []byte(secret))
+	}
+	return false
+}
+
+// webhookSecret segredo do cofre em D4SIGN_WEBHOOK_SECRETS ("cofre:segredo,...") ou o segredo padrão
+func (s *D4SignService) webhookSecret(safeUUID string) string {
+	for _, pair := range strings.Split(s.config.D4SignWebhookSecrets, ",") {
+		safe, secret, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if found && safe == safeUUID && secret != "" {
+			return secret
+		}
+	}
+	return s.config.D4SignWebhookSecret
+}
+
+// claimWebhookEvent registra o evento como processado; devolve false se ele já havia sido registrado
+func (s *D4SignService) claimWebhookEvent(ctx context.Context, eventID string, payload *models.D4SignWebhookPayload) (bool, error) {
+	event := &models.D4SignWebhookEvent{
+		EventID:      eventID,
+		DocumentUUID: payload.Document.UUID,
+		Event:        payload.Event,
+		ProcessedAt:  time.Now(),
+	}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *D4SignService) recordWebhook(ctx context.Context, entry *models.D4SignWebhookLog, status, reason string) {
+	entry.Status = status
+	entry.Error = reason
+	if err := s.db.WithContext(ctx).Create(entry).Error; err != nil {
+		s.logger.LogError(err, "D4SignService.recordWebhook", logging.Fields{"document_uuid": entry.DocumentUUID, "status": status})
+	}
+}
+
+// webhookEventID identificador do evento: o enviado pela D4Sign ou, na falta dele, um hash estável do conteúdo
+func webhookEventID(payload *models.D4SignWebhookPayload, headerID string) string {
+	if payload.ID != "" {
+		return payload.ID
+	}
+	if headerID != "" {
+		return headerID
+	}
+
+	signer := payload.Signer.KeySigner
+	if signer == "" {
+		signer = strings.ToLower(payload.Signer.Email)
+	}
+	digest := sha256.Sum256([]byte(strings.Join([]string{
+		payload.Document.UUID,
+		payload.Event,
+		signer,
+		payload.Timestamp.UTC().Format(time.RFC3339Nano),
+	}, "|")))
+	return hex.EncodeToString(digest[:])
+}
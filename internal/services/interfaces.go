@@ -6,6 +6,11 @@ import (
 	"github.com/equinoid/backend/internal/models"
 )
 
+// ConsentChecker verifica o consentimento do titular antes de tratamentos que dependem dele (LGPD Art. 8)
+type ConsentChecker interface {
+	RequireConsent(ctx context.Context, userID uint, purpose string) error
+}
+
 type UserServiceInterface interface {
 	GetByID(ctx context.Context, id uint) (*models.User, error)
 	UpdateProfile(ctx context.Context, id uint, req *models.UpdateProfileRequest) (*models.User, error)
@@ -58,17 +63,6 @@ type ReproducaoServiceInterface interface {
 	GetRankingReprodutivo(ctx context.Context, sexo string, limit int) ([]*models.RankingReprodutivo, error)
 }
 
-type SocialServiceInterface interface {
-	CreatePerfilSocial(ctx context.Context, equinoidID string, userID uint) (*models.PerfilSocial, error)
-	GetPerfilSocial(ctx context.Context, equinoidID string) (*models.PerfilSocial, error)
-	UpdatePerfilSocial(ctx context.Context, equinoidID string, nomePerfil, bio, localizacao string) (*models.PerfilSocial, error)
-	CreatePost(ctx context.Context, equinoidID string, req *models.CreatePostRequest, userID uint) (*models.PostSocial, error)
-	GetPosts(ctx context.Context, equinoidID string, page, limit int) ([]*models.PostSocial, int64, error)
-	CreateInteracao(ctx context.Context, postID uint, userID uint, tipoInteracao models.TipoInteracao) error
-	CreateOferta(ctx context.Context, equinoidID string, req *models.CreateOfertaRequest, userID uint) (*models.Oferta, error)
-	GetOfertas(ctx context.Context, equinoidID string) ([]*models.Oferta, error)
-}
-
 type CertificateServiceInterface interface {
 	GenerateCertificate(ctx context.Context, equinoidID string, certificateType string) (string, error)
 	ValidateCertificate(ctx context.Context, serialNumber string) (bool, error)
@@ -60,44 +60,6 @@ func (s *LeilaoService) Create(leilao *models.Leilao) error {
 	return nil
 }
 
-// Finalizar encerra um leilão e calcula totais
-func (s *LeilaoService) Finalizar(id uint) (*models.Leilao, error) {
-	var leilao models.Leilao
-	if err := s.db.First(&leilao, id).Error; err != nil {
-		s.logger.Errorf("Erro ao buscar leilão %d: %v", id, err)
-		return nil, err
-	}
-
-	// Calcular totais das participações vendidas
-	var participacoes []models.ParticipacaoLeilao
-	s.db.Where("leilao_id = ? AND status = ?", id, "vendido").Find(&participacoes)
-
-	var totalArrecadado, totalComissoes float64
-	for _, p := range participacoes {
-		if p.ValorVendido != nil {
-			totalArrecadado += *p.ValorVendido
-		}
-		if p.ComissaoLeiloeiro != nil {
-			totalComissoes += *p.ComissaoLeiloeiro
-		}
-	}
-
-	// Atualizar leilão
-	leilao.Status = "encerrado"
-	leilao.TotalArrecadado = &totalArrecadado
-	leilao.TotalComissoes = &totalComissoes
-
-	if err := s.db.Save(&leilao).Error; err != nil {
-		s.logger.Errorf("Erro ao finalizar leilão %d: %v", id, err)
-		return nil, err
-	}
-
-	s.logger.Infof("Leilão %d finalizado. Total arrecadado: R$ %.2f, Comissões: R$ %.2f",
-		id, totalArrecadado, totalComissoes)
-
-	return &leilao, nil
-}
-
 // ListParticipacoes retorna participações de um leilão
 func (s *LeilaoService) ListParticipacoes(leilaoID uint) ([]models.ParticipacaoLeilao, error) {
 	var participacoes []models.ParticipacaoLeilao
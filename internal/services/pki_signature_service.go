@@ -0,0 +1,430 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	"github.com/equinoid/backend/internal/security/biometric"
+	"github.com/equinoid/backend/internal/security/pki"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// pkiSignatureAlgorithm algoritmo registrado nas assinaturas da PKI interna
+const pkiSignatureAlgorithm = "CAdES-BES/SHA256-RSA"
+
+// DocumentSigner assinatura e verificação de documentos com os certificados da CA interna (implementada por pki.PKIManager)
+type DocumentSigner interface {
+	SigningCertificate(ctx context.Context, userID uint, certificateID *uint) (*models.Certificate, error)
+	SignCAdES(ctx context.Context, certificate *models.Certificate, digest []byte, signingTime time.Time) ([]byte, error)
+	SignPDF(ctx context.Context, certificate *models.Certificate, pdf []byte, opts pki.PDFSignatureOptions) ([]byte, error)
+	VerifyCAdES(ctx context.Context, signatureDER, digest []byte) (*pki.SignatureVerification, error)
+	VerifyPDF(ctx context.Context, pdf []byte) ([]pki.SignatureVerification, error)
+	VerifyTimestamp(token, digest []byte) (*pki.TimestampInfo, error)
+	RespondTimestamp(requestDER []byte) ([]byte, error)
+}
+
+// BiometricVerifier confirmação facial do signatário (implementada por biometric.SignatureService)
+type BiometricVerifier interface {
+	VerifySigner(userID uint, biometricData, storedBiometric []byte) (*biometric.VerificationResponse, error)
+}
+
+// PKISignatureService assinaturas Tier 2 e Tier 3 pela PKI interna: CAdES destacada sobre o hash do documento e
+// PAdES embutida quando o documento é um PDF
+type PKISignatureService struct {
+	db        *gorm.DB
+	signer    DocumentSigner
+	biometric BiometricVerifier
+	router    *SignatureRouter
+	logger    *logging.Logger
+}
+
+// NewPKISignatureService cria o serviço; sem verificador biométrico, tiers que exigem step-up recusam a assinatura
+func NewPKISignatureService(db *gorm.DB, signer DocumentSigner, biometric BiometricVerifier, logger *logging.Logger) *PKISignatureService {
+	return &PKISignatureService{
+		db:        db,
+		signer:    signer,
+		biometric: biometric,
+		router:    NewSignatureRouter(logger),
+		logger:    logger,
+	}
+}
+
+// Sign assina o documento com o certificado ativo do usuário (ou o informado) e registra a assinatura
+func (s *PKISignatureService) Sign(ctx context.Context, req *SignatureRequest) (*SignatureResponse, error) {
+	tier := s.router.DetermineTier(req.DocumentType)
+	if tier == Tier1Legal {
+		return nil, &apperrors.ValidationError{Field: "document_type", Message: "documento exige assinatura com validade legal (D4Sign)", Value: req.DocumentType}
+	}
+
+	digest, documentHash, err := documentDigest(req.Document, req.DocumentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := s.signer.SigningCertificate(ctx, req.SignerID, req.CertificateID)
+	if err != nil {
+		return nil, s.mapSignatureError(err, "sign", logging.Fields{"user_id": req.SignerID})
+	}
+
+	biometricResult, err := s.verifyBiometric(ctx, req, tier)
+	if err != nil {
+		return nil, err
+	}
+
+	signedAt := time.Now().UTC()
+	cades, err := s.signer.SignCAdES(ctx, certificate, digest, signedAt)
+	if err != nil {
+		return nil, s.mapSignatureError(err, "sign", logging.Fields{"user_id": req.SignerID, "certificate_id": certificate.ID})
+	}
+
+	signature := &models.DigitalSignature{
+		UserID:            req.SignerID,
+		DocumentID:        uuid.New(),
+		DocumentType:      req.DocumentType,
+		Tier:              string(tier),
+		Format:            models.SignatureFormatCAdES,
+		CertificateID:     certificate.ID,
+		CertificateSerial: certificate.SerialNumber,
+		Signature:         base64.StdEncoding.EncodeToString(cades),
+		SignatureHash:     pki.SignatureHash(cades),
+		DocumentHash:      documentHash,
+		Algorithm:         pkiSignatureAlgorithm,
+		Timestamp:         signedAt,
+		Location:          req.Location,
+		RelatedEntityID:   req.RelatedEntityID,
+		RelatedEntityType: req.RelatedEntityType,
+	}
+	if biometricResult != nil {
+		signature.BiometricVerified = true
+		signature.BiometricScore = biometricResult.Score
+	}
+	if err := s.attachTimestamp(signature, cades); err != nil {
+		return nil, s.mapSignatureError(err, "sign", logging.Fields{"user_id": req.SignerID})
+	}
+
+	var signedPDF []byte
+	if isPDF(req.Document) {
+		name := req.SignerName
+		if name == "" {
+			name = certificate.CommonName
+		}
+		signedPDF, err = s.signer.SignPDF(ctx, certificate, req.Document, pki.PDFSignatureOptions{
+			Name:        name,
+			Reason:      req.DocumentType,
+			Location:    req.Location,
+			SigningTime: signedAt,
+		})
+		if err != nil {
+			return nil, s.mapSignatureError(err, "sign_pdf", logging.Fields{"user_id": req.SignerID, "certificate_id": certificate.ID})
+		}
+
+		embedded, err := pki.ExtractPDFSignatures(signedPDF)
+		if err == nil && len(embedded) == 0 {
+			err = pki.ErrPDFNotSigned
+		}
+		if err != nil {
+			return nil, s.mapSignatureError(err, "sign_pdf", logging.Fields{"user_id": req.SignerID})
+		}
+		signature.Format = models.SignatureFormatPAdES
+		signature.PDFSignatureHash = pki.SignatureHash(embedded[len(embedded)-1].CMS)
+	}
+
+	if err := s.db.WithContext(ctx).Create(signature).Error; err != nil {
+		s.logger.LogError(err, "PKISignatureService.Sign", logging.Fields{"user_id": req.SignerID})
+		return nil, apperrors.NewDatabaseError("create_signature", "erro ao registrar assinatura", err)
+	}
+
+	s.logger.LogBusinessEvent("document_signed", "Documento assinado pela PKI interna", req.SignerID, "", logging.Fields{
+		"signature_id":       signature.ID,
+		"document_type":      req.DocumentType,
+		"tier":               tier,
+		"format":             signature.Format,
+		"certificate_serial": certificate.SerialNumber,
+		"biometric_verified": signature.BiometricVerified,
+	})
+
+	return &SignatureResponse{
+		Success:            true,
+		Method:             "pki_internal",
+		DocumentUUID:       signature.DocumentID.String(),
+		SignatureHash:      signature.SignatureHash,
+		Status:             "signed",
+		Message:            "Documento assinado com certificado da PKI interna",
+		SignatureID:        signature.ID,
+		CertificateSerial:  certificate.SerialNumber,
+		CAdES:              cades,
+		SignedPDF:          signedPDF,
+		SignedAt:           signedAt,
+		TimestampTime:      signature.TimestampTime,
+		TimestampAuthority: signature.TimestampAuthority,
+	}, nil
+}
+
+// Verify verifica as assinaturas de um arquivo: a CAdES destacada informada ou, em PDFs, as assinaturas PAdES embutidas
+func (s *PKISignatureService) Verify(ctx context.Context, file, detachedSignature []byte) (*models.SignatureVerificationResponse, error) {
+	if len(file) == 0 {
+		return nil, &apperrors.ValidationError{Field: "file", Message: "arquivo é obrigatório"}
+	}
+
+	sum := sha256.Sum256(file)
+	response := &models.SignatureVerificationResponse{
+		DocumentHash: hex.EncodeToString(sum[:]),
+		CheckedAt:    time.Now(),
+	}
+
+	var results []pki.SignatureVerification
+	switch {
+	case len(detachedSignature) > 0:
+		response.Format = models.SignatureFormatCAdES
+		result, err := s.signer.VerifyCAdES(ctx, decodeSignature(detachedSignature), sum[:])
+		if err != nil {
+			return nil, s.mapSignatureError(err, "verify", nil)
+		}
+		results = append(results, *result)
+	case isPDF(file):
+		response.Format = models.SignatureFormatPAdES
+		verified, err := s.signer.VerifyPDF(ctx, file)
+		if err != nil {
+			return nil, s.mapSignatureError(err, "verify", nil)
+		}
+		results = verified
+	default:
+		return nil, &apperrors.ValidationError{Field: "signature", Message: "assinatura destacada (.p7s) é obrigatória para arquivos que não são PDF"}
+	}
+
+	response.Valid = len(results) > 0
+	for i := range results {
+		result := s.verificationResult(ctx, &results[i], response.Format)
+		response.Valid = response.Valid && result.Valid
+		response.Signatures = append(response.Signatures, result)
+	}
+
+	return response, nil
+}
+
+// VerifyTimestamp verifica um carimbo de tempo RFC 3161 contra o arquivo ou o SHA-256 informado. A autoridade e o
+// horário vêm do próprio token, sem consulta aos registros locais.
+func (s *PKISignatureService) VerifyTimestamp(ctx context.Context, token, file []byte, documentHash string) (*models.TimestampVerification, error) {
+	if len(token) == 0 {
+		return nil, &apperrors.ValidationError{Field: "token", Message: "carimbo de tempo é obrigatório"}
+	}
+	if len(file) == 0 && documentHash == "" {
+		return nil, &apperrors.ValidationError{Field: "file", Message: "informe o arquivo ou o hash carimbado"}
+	}
+
+	digest, _, err := documentDigest(file, documentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.signer.VerifyTimestamp(decodeSignature(token), digest)
+	if err != nil {
+		if errors.Is(err, pki.ErrInvalidTimestamp) || errors.Is(err, pki.ErrTimestampMismatch) {
+			return &models.TimestampVerification{Error: err.Error()}, nil
+		}
+		return nil, s.mapSignatureError(err, "verify_timestamp", nil)
+	}
+	return timestampVerification(info, ""), nil
+}
+
+// RespondTimestamp atende requisições RFC 3161 (application/timestamp-query) pela TSA interna
+func (s *PKISignatureService) RespondTimestamp(ctx context.Context, requestDER []byte) ([]byte, error) {
+	response, err := s.signer.RespondTimestamp(requestDER)
+	if err != nil {
+		return nil, s.mapSignatureError(err, "respond_timestamp", nil)
+	}
+	return response, nil
+}
+
+// attachTimestamp registra no modelo o carimbo de tempo incluído na assinatura (CAdES-T)
+func (s *PKISignatureService) attachTimestamp(signature *models.DigitalSignature, cades []byte) error {
+	token, signatureValue, err := pki.SignatureTimestampToken(cades)
+	if err != nil || token == nil {
+		return err
+	}
+
+	digest := sha256.Sum256(signatureValue)
+	info, err := s.signer.VerifyTimestamp(token, digest[:])
+	if err != nil {
+		return err
+	}
+
+	timestampTime := info.Time
+	signature.TimestampToken = base64.StdEncoding.EncodeToString(token)
+	signature.TimestampTime = &timestampTime
+	signature.TimestampAuthority = info.Authority
+	return nil
+}
+
+// verifyBiometric exige a confirmação facial quando o tier pede step-up; sem biometria cadastrada a assinatura é recusada
+func (s *PKISignatureService) verifyBiometric(ctx context.Context, req *SignatureRequest, tier SignatureTier) (*biometric.VerificationResponse, error) {
+	if !s.router.RequiresBiometric(tier) && len(req.BiometricData) == 0 {
+		return nil, nil
+	}
+
+	var enrollment models.BiometricData
+	err := s.db.WithContext(ctx).Where("user_id = ? AND is_active = ?", req.SignerID, true).
+		Order("enrollment_date DESC").First(&enrollment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if s.router.RequiresBiometric(tier) {
+			s.logger.Warnf("Assinatura %s recusada: usuário %d não possui biometria cadastrada", tier, req.SignerID)
+			return nil, &apperrors.ValidationError{Field: "biometric_data", Message: "biometria obrigatória não cadastrada"}
+		}
+		return nil, &apperrors.ValidationError{Field: "biometric_data", Message: "usuário não possui biometria cadastrada"}
+	}
+	if err != nil {
+		s.logger.LogError(err, "PKISignatureService.verifyBiometric", logging.Fields{"user_id": req.SignerID})
+		return nil, apperrors.NewDatabaseError("find_biometric", "erro ao buscar biometria", err)
+	}
+
+	if len(req.BiometricData) == 0 {
+		return nil, &apperrors.ValidationError{Field: "biometric_data", Message: "confirmação biométrica é obrigatória para este documento"}
+	}
+	if s.biometric == nil {
+		return nil, apperrors.NewBusinessError("biometric_unavailable", "verificação biométrica indisponível", nil)
+	}
+
+	result, err := s.biometric.VerifySigner(req.SignerID, req.BiometricData, enrollment.BiometricTemplate)
+	if err != nil {
+		return nil, &apperrors.ValidationError{Field: "biometric_data", Message: err.Error()}
+	}
+	if !result.Success {
+		s.logger.LogBusinessEvent("signature_biometric_failed", "Confirmação biométrica recusada", req.SignerID, "", logging.Fields{
+			"score":   result.Score,
+			"message": result.Message,
+		})
+		return nil, &apperrors.AuthorizationError{Message: "confirmação biométrica não confere"}
+	}
+	return result, nil
+}
+
+// verificationResult converte o resultado da PKI e associa o registro da assinatura, quando emitida por este serviço
+func (s *PKISignatureService) verificationResult(ctx context.Context, v *pki.SignatureVerification, format string) models.SignatureVerificationResult {
+	result := models.SignatureVerificationResult{
+		Valid:               v.Valid(),
+		SignatureValid:      v.SignatureValid,
+		ChainValid:          v.ChainValid,
+		CertificateStatus:   v.CertificateStatus,
+		Signer:              v.Signer,
+		SignerSerial:        v.SignerSerial,
+		Issuer:              v.Issuer,
+		SigningTime:         v.SigningTime,
+		CoversWholeDocument: v.CoversWholeDocument,
+		Reason:              v.Error,
+	}
+	if v.Timestamp != nil || v.TimestampError != "" {
+		result.Timestamp = timestampVerification(v.Timestamp, v.TimestampError)
+	}
+	if format == models.SignatureFormatPAdES && !v.CoversWholeDocument {
+		result.Reason = strings.TrimSpace(result.Reason + " documento alterado após esta assinatura")
+	}
+
+	if !v.SignatureValid {
+		return result
+	}
+
+	column := "signature_hash"
+	if format == models.SignatureFormatPAdES {
+		column = "pdf_signature_hash"
+	}
+	var signature models.DigitalSignature
+	err := s.db.WithContext(ctx).Select("id").Where(column+" = ?", v.SignatureHash).First(&signature).Error
+	if err == nil {
+		result.SignatureID = &signature.ID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.LogError(err, "PKISignatureService.verificationResult", logging.Fields{"signature_hash": v.SignatureHash})
+	}
+	return result
+}
+
+func (s *PKISignatureService) mapSignatureError(err error, op string, fields logging.Fields) error {
+	switch {
+	case errors.Is(err, pki.ErrCertificateNotFound):
+		return &apperrors.NotFoundError{Resource: "certificate", Message: "usuário não possui certificado válido para assinatura"}
+	case errors.Is(err, pki.ErrCertificateRevoked):
+		return &apperrors.ValidationError{Field: "certificate_id", Message: "certificado revogado não pode assinar"}
+	case errors.Is(err, pki.ErrServerKeyUnavailable):
+		return &apperrors.ValidationError{Field: "certificate_id", Message: "certificado emitido por CSR: a chave privada não está no servidor"}
+	case errors.Is(err, pki.ErrInvalidPDF), errors.Is(err, pki.ErrEncryptedPDF), errors.Is(err, pki.ErrPDFNotSigned),
+		errors.Is(err, pki.ErrSignatureTooLarge):
+		return &apperrors.ValidationError{Field: "file", Message: err.Error()}
+	case errors.Is(err, pki.ErrInvalidSignature):
+		return &apperrors.ValidationError{Field: "signature", Message: err.Error()}
+	case errors.Is(err, pki.ErrCANotInitialized):
+		return apperrors.NewBusinessError("pki_unavailable", "autoridade certificadora indisponível", nil)
+	case errors.Is(err, pki.ErrTimestampUnavailable), errors.Is(err, pki.ErrTimestampRejected):
+		s.logger.LogError(err, "PKISignatureService", fields)
+		return apperrors.NewBusinessError("tsa_unavailable", "autoridade de carimbo de tempo indisponível", nil)
+	}
+
+	s.logger.LogError(err, "PKISignatureService", fields)
+	return apperrors.NewDatabaseError(op, "erro ao processar assinatura", err)
+}
+
+func timestampVerification(info *pki.TimestampInfo, verificationError string) *models.TimestampVerification {
+	result := &models.TimestampVerification{Error: verificationError}
+	if info == nil {
+		return result
+	}
+
+	timestampTime := info.Time
+	result.Valid = info.Trusted
+	result.Trusted = info.Trusted
+	result.Authority = info.Authority
+	result.Time = &timestampTime
+	result.SerialNumber = info.SerialNumber
+	result.Policy = info.Policy
+	if info.Accuracy > 0 {
+		result.Accuracy = info.Accuracy.String()
+	}
+	if result.Error == "" {
+		result.Error = info.TrustError
+	}
+	return result
+}
+
+// documentDigest SHA-256 do documento; com arquivo e hash informados, exige que correspondam
+func documentDigest(document []byte, documentHash string) ([]byte, string, error) {
+	documentHash = strings.ToLower(strings.TrimSpace(documentHash))
+
+	if len(document) > 0 {
+		sum := sha256.Sum256(document)
+		computed := hex.EncodeToString(sum[:])
+		if documentHash != "" && documentHash != computed {
+			return nil, "", &apperrors.ValidationError{Field: "document_hash", Message: "hash não corresponde ao arquivo enviado", Value: documentHash}
+		}
+		return sum[:], computed, nil
+	}
+
+	digest, err := hex.DecodeString(documentHash)
+	if err != nil || len(digest) != sha256.Size {
+		return nil, "", &apperrors.ValidationError{Field: "document_hash", Message: "informe o SHA-256 do documento em hexadecimal", Value: documentHash}
+	}
+	return digest, documentHash, nil
+}
+
+// decodeSignature aceita a assinatura destacada em DER, PEM ou base64
+func decodeSignature(data []byte) []byte {
+	if block, _ := pem.Decode(data); block != nil {
+		return block.Bytes
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+		return decoded
+	}
+	return data
+}
+
+func isPDF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("%PDF-"))
+}
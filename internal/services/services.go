@@ -4,14 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/equinoid/backend/internal/config"
-	"github.com/equinoid/backend/internal/constants"
 	"github.com/equinoid/backend/internal/models"
 	"github.com/equinoid/backend/internal/modules/equinos"
 	"github.com/equinoid/backend/internal/modules/users"
+	"github.com/equinoid/backend/internal/security/pki"
 	"github.com/equinoid/backend/internal/utils"
 	"github.com/equinoid/backend/pkg/cache"
 	apperrors "github.com/equinoid/backend/pkg/errors"
@@ -448,6 +449,7 @@ func (s *EquinoService) Create(ctx context.Context, req *models.CreateEquinoRequ
 		Raca:           req.Raca,
 		Pelagem:        req.Pelagem,
 		PaisOrigem:     req.PaisOrigem,
+		Resenha:        req.Resenha,
 		DataNascimento: req.DataNascimento,
 		Genitora:       req.GenitoraEquinoid,
 		Genitor:        req.GenitorEquinoid,
@@ -485,6 +487,9 @@ func (s *EquinoService) Update(ctx context.Context, equinoidID string, req *mode
 	if req.PaisOrigem != nil && *req.PaisOrigem != "" {
 		equino.PaisOrigem = *req.PaisOrigem
 	}
+	if req.Resenha != nil {
+		equino.Resenha = *req.Resenha
+	}
 	if req.Status != nil {
 		equino.Status = *req.Status
 	}
@@ -618,1248 +623,261 @@ func (s *EventoService) CreateEvento(ctx context.Context, equinoidID string, req
 	return evento, nil
 }
 
+// CertificateIssuer emissão, renovação e verificação de certificados pela CA interna (implementada por pki.PKIManager)
+type CertificateIssuer interface {
+	RequestCertificate(ctx context.Context, req *pki.CertificateRequest, csrPEM []byte) (*pki.CertificateResponse, error)
+	RenewCertificate(ctx context.Context, userID, certificateID uint, csrPEM []byte) (*pki.CertificateResponse, error)
+	VerifyCertificate(ctx context.Context, certificateID uint) (*pki.CertificateInfo, error)
+	ValidateCertificateChain(ctx context.Context, certificateID uint) error
+	CACertificate() ([]byte, error)
+	PublishCRL(ctx context.Context) (*models.CertificateRevocationList, error)
+	CurrentCRL(ctx context.Context) (*models.CertificateRevocationList, error)
+	OCSPResponse(ctx context.Context, requestDER []byte) ([]byte, error)
+}
+
 type CertificateService struct {
 	db         *gorm.DB
 	equinoRepo equinos.Repository
+	issuer     CertificateIssuer
 	cache      cache.CacheInterface
 	logger     *logging.Logger
 	config     *config.Config
 }
 
-func NewCertificateService(db *gorm.DB, cache cache.CacheInterface, logger *logging.Logger, config *config.Config) *CertificateService {
+func NewCertificateService(db *gorm.DB, cache cache.CacheInterface, logger *logging.Logger, config *config.Config, issuer CertificateIssuer) *CertificateService {
 	equinoRepo := equinos.NewRepository(db)
 	return &CertificateService{
 		db:         db,
 		equinoRepo: equinoRepo,
+		issuer:     issuer,
 		cache:      cache,
 		logger:     logger,
 		config:     config,
 	}
 }
 
-func (s *CertificateService) GenerateCertificate(ctx context.Context, userID uint, equinoid string, certificateType string, validDays int) (*models.Certificate, error) {
-	_, err := s.equinoRepo.FindByEquinoid(ctx, equinoid)
+// GenerateCertificate emite pela CA interna um certificado vinculado ao equino. Com CSR apenas a chave pública chega ao
+// servidor; sem CSR a chave privada gerada é devolvida uma única vez e guardada criptografada.
+func (s *CertificateService) GenerateCertificate(ctx context.Context, userID uint, userType string, req *models.GenerateCertificateRequest) (*models.GenerateCertificateResponse, error) {
+	equino, err := s.equinoRepo.FindByEquinoid(ctx, req.Equinoid)
 	if err != nil {
 		if apperrors.IsNotFound(err) {
-			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "equino não encontrado", ID: equinoid}
+			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "equino não encontrado", ID: req.Equinoid}
 		}
-		s.logger.LogError(err, "CertificateService", logging.Fields{"equinoid": equinoid})
+		s.logger.LogError(err, "CertificateService", logging.Fields{"equinoid": req.Equinoid})
 		return nil, apperrors.NewDatabaseError("generate_certificate", "erro ao buscar equino", err)
 	}
-
-	serialNumber := fmt.Sprintf("CERT-%s-%d", equinoid, time.Now().Unix())
-	now := time.Now()
-	validTo := now.AddDate(0, 0, validDays)
-
-	certificate := &models.Certificate{
-		UserID:         userID,
-		SerialNumber:   serialNumber,
-		CertificatePEM: "PEM_PLACEHOLDER", // Em cenário real, geraria o par de chaves e assinaria
-		PrivateKeyPEM:  "PRIVATE_KEY_PLACEHOLDER",
-		PublicKeyPEM:   "PUBLIC_KEY_PLACEHOLDER",
-		CommonName:     fmt.Sprintf("%s - %s", equinoid, certificateType),
-		IssuedAt:       now,
-		ValidFrom:      now,
-		ValidTo:        validTo,
-		ExpiresAt:      validTo,
-		IsRevoked:      false,
+	if equino.ProprietarioID != userID && userType != string(models.UserTypeAdmin) {
+		return nil, (&apperrors.AuthorizationError{Message: "apenas o proprietário pode emitir certificados do equino"}).WithAction("generate", "certificate")
 	}
 
-	if err := s.db.WithContext(ctx).Create(certificate).Error; err != nil {
-		s.logger.LogError(err, "CertificateService", logging.Fields{"equinoid": equinoid})
-		return nil, apperrors.NewDatabaseError("generate_certificate", "erro ao salvar certificado", err)
+	validDays := req.ValidDays
+	if validDays <= 0 {
+		validDays = 365
 	}
 
-	return certificate, nil
-}
-
-func (s *CertificateService) ListCertificates(ctx context.Context, userID uint) ([]*models.Certificate, error) {
-	var certificates []*models.Certificate
-	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&certificates).Error
-	if err != nil {
-		s.logger.LogError(err, "CertificateService", logging.Fields{"user_id": userID})
-		return nil, apperrors.NewDatabaseError("list_certificates", "erro ao listar certificados", err)
+	certReq := &pki.CertificateRequest{
+		UserID:          userID,
+		CommonName:      fmt.Sprintf("%s - %s", req.Equinoid, req.TipoCertificado),
+		Organization:    "Equinoid",
+		Country:         "BR",
+		ValidityDays:    validDays,
+		KeyUsage:        []string{"digital_signature"},
+		Equinoid:        req.Equinoid,
+		CertificateType: req.TipoCertificado,
 	}
-	return certificates, nil
-}
 
-func (s *CertificateService) GetBySerialNumber(ctx context.Context, serialNumber string) (*models.Certificate, error) {
-	var certificate models.Certificate
-	if err := s.db.WithContext(ctx).Where("serial_number = ?", serialNumber).First(&certificate).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "certificate", Message: "certificado não encontrado", ID: serialNumber}
-		}
-		return nil, apperrors.NewDatabaseError("get_by_serial", "erro ao buscar certificado", err)
-	}
-	return &certificate, nil
-}
-
-func (s *CertificateService) ValidateCertificate(ctx context.Context, serialNumber string) (*models.Certificate, error) {
-	certificate, err := s.GetBySerialNumber(ctx, serialNumber)
+	issued, err := s.issuer.RequestCertificate(ctx, certReq, []byte(req.CSR))
 	if err != nil {
-		return nil, err
+		return nil, s.mapPKIError(err, "generate_certificate", logging.Fields{"equinoid": req.Equinoid})
 	}
 
-	return certificate, nil
+	s.logger.LogBusinessEvent("certificate_issued", "Certificado emitido pela CA interna", userID, req.Equinoid, logging.Fields{
+		"serial":     issued.SerialNumber,
+		"key_origin": issued.Certificate.KeyOrigin,
+	})
+	return s.issuedResponse(issued), nil
 }
 
-func (s *CertificateService) RevokeCertificate(ctx context.Context, serialNumber string, reason string) error {
+// RenewCertificate renova um certificado do usuário próximo do vencimento, opcionalmente com nova CSR
+func (s *CertificateService) RenewCertificate(ctx context.Context, userID uint, serialNumber string, req *models.RenewCertificateRequest) (*models.GenerateCertificateResponse, error) {
 	certificate, err := s.GetBySerialNumber(ctx, serialNumber)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	certificate.Revoke(reason)
-
-	if err := s.db.WithContext(ctx).Save(certificate).Error; err != nil {
-		s.logger.LogError(err, "CertificateService", logging.Fields{"serial": serialNumber})
-		return apperrors.NewDatabaseError("revoke_certificate", "erro ao revogar certificado", err)
+	if certificate.UserID != userID {
+		return nil, &apperrors.NotFoundError{Resource: "certificate", Message: "certificado não encontrado", ID: serialNumber}
 	}
 
-	return nil
-}
-
-type ValorizacaoService struct {
-	db     *gorm.DB
-	cache  cache.CacheInterface
-	logger *logging.Logger
-}
-
-func NewValorizacaoService(db *gorm.DB, cache cache.CacheInterface, logger *logging.Logger) *ValorizacaoService {
-	return &ValorizacaoService{db: db, cache: cache, logger: logger}
-}
-
-func (s *ValorizacaoService) CreateRegistro(ctx context.Context, equinoidID string, req *models.CreateValorizacaoRequest, userID uint) (*models.RegistroValorizacao, error) {
-	equinoRepo := equinos.NewRepository(s.db)
-	_, err := equinoRepo.FindByEquinoid(ctx, equinoidID)
+	issued, err := s.issuer.RenewCertificate(ctx, userID, certificate.ID, []byte(req.CSR))
 	if err != nil {
-		if apperrors.IsNotFound(err) {
-			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "equino não encontrado", ID: equinoidID}
-		}
-		s.logger.LogError(err, "ValorizacaoService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("create_registro", "erro ao buscar equino", err)
-	}
-
-	pontos := s.calculatePoints(req.Categoria, req.TipoRegistro, req.NivelImportancia)
-
-	registro := &models.RegistroValorizacao{
-		Equinoid:                 equinoidID,
-		Categoria:                req.Categoria,
-		TipoRegistro:             req.TipoRegistro,
-		Titulo:                   req.Titulo,
-		Descricao:                req.Descricao,
-		DataRegistro:             req.DataRegistro,
-		DataValidade:             req.DataValidade,
-		LocalEvento:              req.LocalEvento,
-		Pais:                     req.Pais,
-		Estado:                   req.Estado,
-		Cidade:                   req.Cidade,
-		Organizacao:              req.Organizacao,
-		InstituicaoCertificadora: req.InstituicaoCertificadora,
-		NumeroCertificado:        req.NumeroCertificado,
-		ValorMonetario:           req.ValorMonetario,
-		PontosValorizacao:        pontos,
-		NivelImportancia:         req.NivelImportancia,
-		StatusValidacao:          models.StatusPendente,
-		CriadoPor:                userID,
-	}
-
-	if err := s.db.WithContext(ctx).Create(registro).Error; err != nil {
-		s.logger.LogError(err, "ValorizacaoService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("create_registro", "erro ao criar registro de valorização", err)
-	}
-
-	return registro, nil
-}
-
-func (s *ValorizacaoService) GetByID(ctx context.Context, id uint) (*models.RegistroValorizacao, error) {
-	var registro models.RegistroValorizacao
-	if err := s.db.WithContext(ctx).Preload("Equino").Preload("Criador").Preload("Validador").Where("id = ?", id).First(&registro).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "registro_valorizacao", Message: "registro não encontrado", ID: id}
-		}
-		s.logger.LogError(err, "ValorizacaoService", logging.Fields{"id": id})
-		return nil, apperrors.NewDatabaseError("get_by_id", "erro ao buscar registro", err)
-	}
-	return &registro, nil
-}
-
-func (s *ValorizacaoService) List(ctx context.Context, equinoidID string, page, limit int, filters map[string]interface{}) ([]*models.RegistroValorizacao, int64, error) {
-	var registros []*models.RegistroValorizacao
-	var total int64
-
-	query := s.db.WithContext(ctx).Model(&models.RegistroValorizacao{}).Where("equinoid = ?", equinoidID)
-
-	if categoria, ok := filters["categoria"].(string); ok && categoria != "" {
-		query = query.Where("categoria = ?", categoria)
-	}
-	if status, ok := filters["status_validacao"].(string); ok && status != "" {
-		query = query.Where("status_validacao = ?", status)
-	}
-
-	if err := query.Count(&total).Error; err != nil {
-		s.logger.LogError(err, "ValorizacaoService", nil)
-		return nil, 0, apperrors.NewDatabaseError("list", "erro ao contar registros", err)
+		return nil, s.mapPKIError(err, "renew_certificate", logging.Fields{"serial": serialNumber})
 	}
 
-	offset := (page - 1) * limit
-	if err := query.Offset(offset).Limit(limit).Order("data_registro DESC").Find(&registros).Error; err != nil {
-		s.logger.LogError(err, "ValorizacaoService", nil)
-		return nil, 0, apperrors.NewDatabaseError("list", "erro ao listar registros", err)
-	}
-
-	return registros, total, nil
-}
+	s.publishCRL(ctx, serialNumber)
 
-func (s *ValorizacaoService) ValidateRegistro(ctx context.Context, id uint, validadorID uint, aprovado bool, observacoes string) error {
-	registro := &models.RegistroValorizacao{}
-	if err := s.db.WithContext(ctx).First(registro, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return &apperrors.NotFoundError{Resource: "registro_valorizacao", Message: "registro não encontrado", ID: id}
-		}
-		return apperrors.NewDatabaseError("validate_registro", "erro ao buscar registro", err)
-	}
-
-	now := time.Now()
-	if aprovado {
-		registro.StatusValidacao = models.StatusAprovado
-	} else {
-		registro.StatusValidacao = models.StatusRejeitado
-	}
-	registro.ValidadoPor = &validadorID
-	registro.DataValidacao = &now
-	registro.ObservacoesValidacao = observacoes
-
-	if err := s.db.WithContext(ctx).Save(registro).Error; err != nil {
-		s.logger.LogError(err, "ValorizacaoService", logging.Fields{"registro_id": id})
-		return apperrors.NewDatabaseError("validate_registro", "erro ao validar registro", err)
-	}
-
-	return nil
+	s.logger.LogBusinessEvent("certificate_renewed", "Certificado renovado pela CA interna", userID, certificate.Equinoid, logging.Fields{
+		"serial":          issued.SerialNumber,
+		"previous_serial": serialNumber,
+	})
+	return s.issuedResponse(issued), nil
 }
 
-func (s *ValorizacaoService) GetTotalPoints(ctx context.Context, equinoidID string) (int, error) {
-	var total int64
-	err := s.db.WithContext(ctx).Model(&models.RegistroValorizacao{}).
-		Where("equinoid = ? AND status_validacao = ?", equinoidID, models.StatusAprovado).
-		Select("COALESCE(SUM(pontos_valorizacao), 0)").
-		Scan(&total).Error
-
+func (s *CertificateService) ListCertificates(ctx context.Context, userID uint) ([]*models.Certificate, error) {
+	var certificates []*models.Certificate
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&certificates).Error
 	if err != nil {
-		s.logger.LogError(err, "ValorizacaoService", logging.Fields{"equinoid": equinoidID})
-		return 0, apperrors.NewDatabaseError("get_total_points", "erro ao calcular pontos totais", err)
-	}
-
-	return int(total), nil
-}
-
-func (s *ValorizacaoService) GetRanking(ctx context.Context, categoria string, limit int) ([]*models.RankingItem, error) {
-	var rankings []*models.RankingItem
-
-	query := `
-		SELECT 
-			e.equinoid,
-			e.nome,
-			COALESCE(SUM(rv.pontos_valorizacao), 0) as total_pontos,
-			COUNT(rv.id) as total_registros
-		FROM equinos e
-		LEFT JOIN registro_valorizacaos rv ON e.equinoid = rv.equinoid 
-			AND rv.status_validacao = 'aprovado'
-	`
-
-	if categoria != "" {
-		query += " AND rv.categoria = ?"
-	}
-
-	query += `
-		GROUP BY e.equinoid, e.nome
-		ORDER BY total_pontos DESC
-		LIMIT ?
-	`
-
-	var args []interface{}
-	if categoria != "" {
-		args = append(args, categoria, limit)
-	} else {
-		args = append(args, limit)
-	}
-
-	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&rankings).Error; err != nil {
-		s.logger.LogError(err, "ValorizacaoService", logging.Fields{"categoria": categoria})
-		return nil, apperrors.NewDatabaseError("get_ranking", "erro ao obter ranking", err)
-	}
-
-	return rankings, nil
-}
-
-func (s *ValorizacaoService) calculatePoints(categoria models.CategoriaValorizacao, tipoRegistro string, nivel models.NivelImportancia) int {
-	basePoints := map[models.CategoriaValorizacao]int{
-		models.CategoriaCompeticao:      constants.PontosBaseCompeticao,
-		models.CategoriaReproducao:      constants.PontosBaseReproducao,
-		models.CategoriaSaude:           constants.PontosBaseSaude,
-		models.CategoriaTreinamento:     constants.PontosBaseTreinamento,
-		models.CategoriaComercial:       constants.PontosBaseComercial,
-		models.CategoriaMidia:           constants.PontosBaseMidia,
-		models.CategoriaEducacao:        constants.PontosBaseEducacao,
-		models.CategoriaViagens:         constants.PontosBaseViagens,
-		models.CategoriaReconhecimentos: constants.PontosBaseReconhecimentos,
-		models.CategoriaParcerias:       constants.PontosBaseParcerias,
-		models.CategoriaAnalise:         constants.PontosBaseAnalise,
-	}
-
-	nivelMultiplier := map[models.NivelImportancia]float64{
-		models.NivelBaixo:       constants.MultiplicadorNivelBaixo,
-		models.NivelMedio:       constants.MultiplicadorNivelMedio,
-		models.NivelAlto:        constants.MultiplicadorNivelAlto,
-		models.NivelCritico:     constants.MultiplicadorNivelCritico,
-		models.NivelExcepcional: constants.MultiplicadorNivelExcepcional,
+		s.logger.LogError(err, "CertificateService", logging.Fields{"user_id": userID})
+		return nil, apperrors.NewDatabaseError("list_certificates", "erro ao listar certificados", err)
 	}
-
-	points := basePoints[categoria]
-	multiplier := nivelMultiplier[nivel]
-
-	return int(float64(points) * multiplier)
+	return certificates, nil
 }
 
-func (s *ValorizacaoService) CreateLeilao(ctx context.Context, equinoidID string, registroValorizacaoID uint, req *models.DadosLeilaoRequest) (*models.LeilaoValorizacao, error) {
-	var registro models.RegistroValorizacao
-	if err := s.db.WithContext(ctx).First(&registro, registroValorizacaoID).Error; err != nil {
+func (s *CertificateService) GetBySerialNumber(ctx context.Context, serialNumber string) (*models.Certificate, error) {
+	var certificate models.Certificate
+	if err := s.db.WithContext(ctx).Where("serial_number = ?", serialNumber).First(&certificate).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "registro_valorizacao", Message: "registro de valorização não encontrado", ID: registroValorizacaoID}
+			return nil, &apperrors.NotFoundError{Resource: "certificate", Message: "certificado não encontrado", ID: serialNumber}
 		}
-		s.logger.LogError(err, "ValorizacaoService", logging.Fields{"registro_id": registroValorizacaoID})
-		return nil, apperrors.NewDatabaseError("create_leilao", "erro ao buscar registro de valorização", err)
-	}
-
-	if registro.Equinoid != equinoidID {
-		return nil, &apperrors.ValidationError{Field: "equinoid", Message: "registro de valorização não pertence ao equino", Value: equinoidID}
-	}
-
-	leilao := &models.LeilaoValorizacao{
-		RegistroValorizacaoID: registroValorizacaoID,
-		NomeLeilao:            req.NomeLeilao,
-		TipoLeilao:            req.TipoLeilao,
-		Especializacao:        req.Especializacao,
-		CasaLeiloeira:         req.CasaLeiloeira,
-		DataLeilao:            req.DataLeilao,
-		PrecoLance:            req.PrecoLance,
-		PrecoVenda:            req.PrecoVenda,
-		PosicaoLeilao:         req.PosicaoLeilao,
-		ValorizacaoPercentual: req.ValorizacaoPercentual,
-		StatusLeilao:          models.StatusLeilaoAgendado,
-		Resultado:             models.ResultadoNaoVendido,
-	}
-
-	if req.StatusLeilao != "" {
-		leilao.StatusLeilao = req.StatusLeilao
-	}
-	if req.Resultado != "" {
-		leilao.Resultado = req.Resultado
-	}
-
-	if err := s.db.WithContext(ctx).Create(leilao).Error; err != nil {
-		s.logger.LogError(err, "ValorizacaoService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("create_leilao", "erro ao criar leilão", err)
-	}
-
-	s.logger.LogBusinessEvent("leilao_created", "Leilão criado com sucesso", 0, equinoidID, logging.Fields{"leilao_id": leilao.ID})
-	return leilao, nil
-}
-
-func (s *ValorizacaoService) GetHistoricoLeiloes(ctx context.Context, equinoidID string) ([]*models.LeilaoValorizacao, error) {
-	var leiloes []*models.LeilaoValorizacao
-
-	query := s.db.WithContext(ctx).
-		Model(&models.LeilaoValorizacao{}).
-		Joins("JOIN registro_valorizacaos ON registro_valorizacaos.id = leilaos.registro_valorizacao_id").
-		Where("registro_valorizacaos.equinoid = ?", equinoidID).
-		Order("leilaos.data_leilao DESC")
-
-	if err := query.Find(&leiloes).Error; err != nil {
-		s.logger.LogError(err, "ValorizacaoService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("get_historico_leiloes", "erro ao buscar histórico de leilões", err)
-	}
-
-	return leiloes, nil
-}
-
-type LinhagemService struct {
-	equinoRepo equinos.Repository
-	cache      cache.CacheInterface
-	logger     *logging.Logger
-}
-
-func NewLinhagemService(db *gorm.DB, cache cache.CacheInterface, logger *logging.Logger) *LinhagemService {
-	equinoRepo := equinos.NewRepository(db)
-	return &LinhagemService{
-		equinoRepo: equinoRepo,
-		cache:      cache,
-		logger:     logger,
+		return nil, apperrors.NewDatabaseError("get_by_serial", "erro ao buscar certificado", err)
 	}
+	return &certificate, nil
 }
 
-func (s *LinhagemService) GetArvoreGenealogica(ctx context.Context, equinoidID string, geracoes int) (*models.ArvoreGenealogica, error) {
-	if geracoes > constants.MaxGeracoesArvoreGenealogica {
-		geracoes = constants.MaxGeracoesArvoreGenealogica
-	}
-
-	equino, err := s.getEquinoWithParents(ctx, equinoidID)
+// ValidateCertificate verifica revogação, período de validade, assinatura e cadeia até a CA interna
+func (s *CertificateService) ValidateCertificate(ctx context.Context, serialNumber string) (*models.ValidateCertificateResponse, error) {
+	certificate, err := s.GetBySerialNumber(ctx, serialNumber)
 	if err != nil {
 		return nil, err
 	}
 
-	arvore := &models.ArvoreGenealogica{
-		Equinoid: equinoidID,
-		Nome:     equino.Nome,
-		Geracoes: geracoes,
-	}
-
-	if geracoes > 0 {
-		arvore.Ancestrais = s.buildAncestorTree(ctx, equino, geracoes-1)
-	}
-
-	return arvore, nil
-}
-
-func (s *LinhagemService) getEquinoWithParents(ctx context.Context, equinoidID string) (*models.Equino, error) {
-	equino, err := s.equinoRepo.FindByEquinoid(ctx, equinoidID)
-	if err != nil {
-		if apperrors.IsNotFound(err) {
-			return nil, apperrors.ErrEquinoNotFound.WithID(equinoidID)
-		}
-		s.logger.LogError(err, "LinhagemService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("get_equino_with_parents", "erro ao buscar equino", err)
-	}
-	return equino, nil
-}
-
-func (s *LinhagemService) buildAncestorTree(ctx context.Context, equino *models.Equino, geracoesRestantes int) *models.Ancestrais {
-	if geracoesRestantes < 0 {
-		return nil
-	}
-
-	ancestrais := &models.Ancestrais{}
-
-	if equino.Genitor != "" {
-		genitor, err := s.getEquinoWithParents(ctx, equino.Genitor)
-		if err == nil {
-			ancestrais.Pai = &models.AncestralNode{
-				Equinoid: genitor.Equinoid,
-				Nome:     genitor.Nome,
-				Sexo:     string(genitor.Sexo),
-			}
-			if geracoesRestantes > 0 {
-				ancestrais.Pai.Ancestrais = s.buildAncestorTree(ctx, genitor, geracoesRestantes-1)
-			}
-		}
-	}
-
-	if equino.Genitora != "" {
-		genitora, err := s.getEquinoWithParents(ctx, equino.Genitora)
-		if err == nil {
-			ancestrais.Mae = &models.AncestralNode{
-				Equinoid: genitora.Equinoid,
-				Nome:     genitora.Nome,
-				Sexo:     string(genitora.Sexo),
-			}
-			if geracoesRestantes > 0 {
-				ancestrais.Mae.Ancestrais = s.buildAncestorTree(ctx, genitora, geracoesRestantes-1)
+	now := time.Now()
+	result := &models.ValidateCertificateResponse{
+		Status:      models.CertificateStatusValid,
+		CheckedAt:   now,
+		Certificate: certificate.ToResponse(),
+	}
+
+	switch {
+	case certificate.IsRevoked:
+		result.Status = models.CertificateStatusRevoked
+		result.Reason = certificate.RevocationReason
+	case now.After(certificate.ExpiresAt):
+		result.Status = models.CertificateStatusExpired
+	case now.Before(certificate.ValidFrom):
+		result.Status = models.CertificateStatusNotYetValid
+	default:
+		if err := s.issuer.ValidateCertificateChain(ctx, certificate.ID); err != nil {
+			if errors.Is(err, pki.ErrCANotInitialized) {
+				return nil, s.mapPKIError(err, "validate_certificate", logging.Fields{"serial": serialNumber})
 			}
+			result.Status = models.CertificateStatusInvalidChain
+			result.Reason = err.Error()
+			break
 		}
-	}
-
-	return ancestrais
-}
-
-func (s *LinhagemService) ValidarParentesco(ctx context.Context, equinoid1, equinoid2 string) (*models.ResultadoValidacaoParentesco, error) {
-	equino1, err := s.getEquinoWithParents(ctx, equinoid1)
-	if err != nil {
-		return nil, err
-	}
-
-	equino2, err := s.getEquinoWithParents(ctx, equinoid2)
-	if err != nil {
-		return nil, err
-	}
-
-	ancestrais1 := s.getAllAncestors(ctx, equino1, constants.MaxGeracoesAncestrais)
-	ancestrais2 := s.getAllAncestors(ctx, equino2, constants.MaxGeracoesAncestrais)
-
-	comum := s.findCommonAncestors(ancestrais1, ancestrais2)
-
-	resultado := &models.ResultadoValidacaoParentesco{
-		Equino1:                    equinoid1,
-		Equino2:                    equinoid2,
-		SaoParentes:                len(comum) > 0,
-		AncestaisComuns:            comum,
-		CoeficienteConsanguinidade: s.calculateConsanguinidade(comum),
-	}
-
-	if len(comum) > 0 {
-		resultado.GrauParentesco = s.determineRelationship(ctx, equinoid1, equinoid2, comum)
-	}
-
-	return resultado, nil
-}
-
-func (s *LinhagemService) getAllAncestors(ctx context.Context, equino *models.Equino, maxGeracoes int) []string {
-	ancestors := []string{}
-	visited := make(map[string]bool)
-
-	var collect func(equinoidID string, geracao int)
-	collect = func(equinoidID string, geracao int) {
-		if geracao > maxGeracoes || visited[equinoidID] {
-			return
-		}
-		visited[equinoidID] = true
-		ancestors = append(ancestors, equinoidID)
 
-		eq, err := s.getEquinoWithParents(ctx, equinoidID)
+		info, err := s.issuer.VerifyCertificate(ctx, certificate.ID)
 		if err != nil {
-			return
-		}
-
-		if eq.Genitor != "" {
-			collect(eq.Genitor, geracao+1)
-		}
-		if eq.Genitora != "" {
-			collect(eq.Genitora, geracao+1)
+			result.Status = models.CertificateStatusInvalidChain
+			result.Reason = err.Error()
+			break
 		}
+		result.Issuer = info.Issuer
+		result.Subject = info.Subject
 	}
 
-	if equino.Genitor != "" {
-		collect(equino.Genitor, 1)
-	}
-	if equino.Genitora != "" {
-		collect(equino.Genitora, 1)
-	}
-
-	return ancestors
-}
-
-func (s *LinhagemService) findCommonAncestors(list1, list2 []string) []string {
-	comum := []string{}
-	set := make(map[string]bool)
-
-	for _, id := range list1 {
-		set[id] = true
-	}
-
-	for _, id := range list2 {
-		if set[id] {
-			comum = append(comum, id)
-		}
-	}
-
-	return comum
-}
-
-func (s *LinhagemService) calculateConsanguinidade(ancestraisComuns []string) float64 {
-	if len(ancestraisComuns) == 0 {
-		return 0.0
-	}
-
-	return float64(len(ancestraisComuns)) * constants.CoeficienteConsanguinidadeBase
-}
-
-func (s *LinhagemService) determineRelationship(ctx context.Context, equino1, equino2 string, ancestraisComuns []string) string {
-	eq1, _ := s.getEquinoWithParents(ctx, equino1)
-	eq2, _ := s.getEquinoWithParents(ctx, equino2)
-
-	if eq1.Genitor == equino2 || eq1.Genitora == equino2 {
-		return "filho/filha"
-	}
-	if eq2.Genitor == equino1 || eq2.Genitora == equino1 {
-		return "pai/mãe"
-	}
-
-	if eq1.Genitor == eq2.Genitor || eq1.Genitora == eq2.Genitora {
-		return "irmão/irmã"
-	}
-
-	if len(ancestraisComuns) > 0 {
-		return fmt.Sprintf("parentes (%d ancestrais comuns)", len(ancestraisComuns))
-	}
-
-	return "não relacionados"
-}
-
-func (s *LinhagemService) GetDescendentes(ctx context.Context, equinoidID string) ([]*models.Equino, error) {
-	descendentesGenitor, err := s.equinoRepo.FindByGenitor(ctx, equinoidID)
-	if err != nil {
-		s.logger.LogError(err, "LinhagemService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("get_descendentes", "erro ao buscar descendentes do genitor", err)
-	}
-
-	descendentesGenitora, err := s.equinoRepo.FindByGenitora(ctx, equinoidID)
-	if err != nil {
-		s.logger.LogError(err, "LinhagemService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("get_descendentes", "erro ao buscar descendentes da genitora", err)
-	}
-
-	descendentes := append(descendentesGenitor, descendentesGenitora...)
-	return descendentes, nil
-}
-
-type ReproducaoService struct {
-	equinoRepo equinos.Repository
-	db         *gorm.DB
-	cache      cache.CacheInterface
-	logger     *logging.Logger
-}
-
-func NewReproducaoService(db *gorm.DB, cache cache.CacheInterface, logger *logging.Logger) *ReproducaoService {
-	equinoRepo := equinos.NewRepository(db)
-	return &ReproducaoService{
-		equinoRepo: equinoRepo,
-		db:         db,
-		cache:      cache,
-		logger:     logger,
-	}
-}
-
-func (s *ReproducaoService) GetAvaliacoesSemen(ctx context.Context, equinoidID string) ([]*models.AvaliacaoSemen, error) {
-	var avaliacoes []*models.AvaliacaoSemen
-	if err := s.db.WithContext(ctx).Where("reprodutor_equinoid = ?", equinoidID).Order("data_avaliacao DESC").Find(&avaliacoes).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService.GetAvaliacoesSemen", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("get_avaliacoes", "erro ao buscar avaliações de sêmen", err)
-	}
-	return avaliacoes, nil
+	result.IsValid = result.Status == models.CertificateStatusValid
+	return result, nil
 }
 
-func (s *ReproducaoService) CreateCobertura(ctx context.Context, reprodutorID, matrizID string, req *models.CreateCoberturaRequest, veterinarioID uint) (*models.Cobertura, error) {
-	reprodutor, err := s.equinoRepo.FindByEquinoid(ctx, reprodutorID)
-	if err != nil {
-		if apperrors.IsNotFound(err) {
-			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "reprodutor não encontrado", ID: reprodutorID}
-		}
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"reprodutor": reprodutorID})
-		return nil, apperrors.NewDatabaseError("create_cobertura", "erro ao buscar reprodutor", err)
-	}
-
-	matriz, err := s.equinoRepo.FindByEquinoid(ctx, matrizID)
+// RevokeCertificate revoga um certificado do usuário; administradores podem revogar qualquer certificado
+func (s *CertificateService) RevokeCertificate(ctx context.Context, userID uint, userType string, serialNumber string, reason string) error {
+	certificate, err := s.GetBySerialNumber(ctx, serialNumber)
 	if err != nil {
-		if apperrors.IsNotFound(err) {
-			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "matriz não encontrada", ID: matrizID}
-		}
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"matriz": matrizID})
-		return nil, apperrors.NewDatabaseError("create_cobertura", "erro ao buscar matriz", err)
-	}
-
-	if reprodutor.Sexo != models.SexoMacho {
-		return nil, &apperrors.ValidationError{Field: "sexo", Message: "reprodutor deve ser macho", Value: reprodutor.Sexo}
-	}
-	if matriz.Sexo != models.SexoFemea {
-		return nil, &apperrors.ValidationError{Field: "sexo", Message: "matriz deve ser fêmea", Value: matriz.Sexo}
+		return err
 	}
-
-	cobertura := &models.Cobertura{
-		ReprodutorEquinoid:     reprodutorID,
-		MatrizEquinoid:         matrizID,
-		DataCobertura:          req.DataCobertura,
-		TipoCobertura:          req.TipoCobertura,
-		MetodoCobertura:        req.MetodoCobertura,
-		VeterinarioResponsavel: veterinarioID,
-		LaboratorioID:          req.LaboratorioID,
-		StatusCobertura:        models.StatusCoberturaPendente,
-		ProbabilidadeConcepcao: req.ProbabilidadeConcepcao,
-		Observacoes:            req.Observacoes,
+	if certificate.UserID != userID && userType != string(models.UserTypeAdmin) {
+		return &apperrors.NotFoundError{Resource: "certificate", Message: "certificado não encontrado", ID: serialNumber}
 	}
-
-	if err := s.db.WithContext(ctx).Create(cobertura).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{
-			"reprodutor": reprodutorID,
-			"matriz":     matrizID,
-		})
-		return nil, apperrors.NewDatabaseError("create_cobertura", "erro ao criar cobertura", err)
+	if certificate.IsRevoked {
+		return &apperrors.ValidationError{Field: "serial_number", Message: "certificado já revogado", Value: serialNumber}
 	}
 
-	return cobertura, nil
-}
+	certificate.Revoke(reason)
 
-func (s *ReproducaoService) GetCoberturasReprodutor(ctx context.Context, reprodutorID string) ([]*models.Cobertura, error) {
-	var coberturas []*models.Cobertura
-	if err := s.db.WithContext(ctx).Where("reprodutor_equinoid = ?", reprodutorID).
-		Preload("Matriz").
-		Preload("Veterinario").
-		Order("data_cobertura DESC").
-		Find(&coberturas).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"reprodutor": reprodutorID})
-		return nil, apperrors.NewDatabaseError("get_coberturas_reprodutor", "erro ao buscar coberturas", err)
+	if err := s.db.WithContext(ctx).Save(certificate).Error; err != nil {
+		s.logger.LogError(err, "CertificateService", logging.Fields{"serial": serialNumber})
+		return apperrors.NewDatabaseError("revoke_certificate", "erro ao revogar certificado", err)
 	}
-	return coberturas, nil
-}
 
-func (s *ReproducaoService) GetCoberturasMatriz(ctx context.Context, matrizID string) ([]*models.Cobertura, error) {
-	var coberturas []*models.Cobertura
-	if err := s.db.WithContext(ctx).Where("matriz_equinoid = ?", matrizID).
-		Preload("Reprodutor").
-		Preload("Veterinario").
-		Order("data_cobertura DESC").
-		Find(&coberturas).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"matriz": matrizID})
-		return nil, apperrors.NewDatabaseError("get_coberturas_matriz", "erro ao buscar coberturas", err)
-	}
-	return coberturas, nil
+	s.publishCRL(ctx, serialNumber)
+	return nil
 }
 
-func (s *ReproducaoService) CreateAvaliacaoSemen(ctx context.Context, reprodutorID string, req *models.CreateAvaliacaoSemenRequest, laboratorioID uint) (*models.AvaliacaoSemen, error) {
-	reprodutor, err := s.equinoRepo.FindByEquinoid(ctx, reprodutorID)
+// CurrentCRL lista de certificados revogados vigente, assinada pela CA interna
+func (s *CertificateService) CurrentCRL(ctx context.Context) (*models.CertificateRevocationList, error) {
+	crl, err := s.issuer.CurrentCRL(ctx)
 	if err != nil {
-		if apperrors.IsNotFound(err) {
-			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "reprodutor não encontrado", ID: reprodutorID}
-		}
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"reprodutor": reprodutorID})
-		return nil, apperrors.NewDatabaseError("create_avaliacao_semen", "erro ao buscar reprodutor", err)
-	}
-
-	if reprodutor.Sexo != models.SexoMacho {
-		return nil, &apperrors.ValidationError{Field: "sexo", Message: "avaliação de sêmen só pode ser feita em machos", Value: reprodutor.Sexo}
-	}
-
-	qualidade := s.determineQualidadeSemen(req)
-	aptidao := s.determineAptidaoReprodutiva(req)
-
-	avaliacao := &models.AvaliacaoSemen{
-		ReprodutorEquinoid:          reprodutorID,
-		CoberturaID:                 req.CoberturaID,
-		DataColeta:                  req.DataColeta,
-		DataAnalise:                 req.DataAnalise,
-		LaboratorioID:               laboratorioID,
-		VolumeSemen:                 req.VolumeSemen,
-		ConcentracaoEspermatozoides: req.ConcentracaoEspermatozoides,
-		MotiliadeProgressiva:        req.MotilidadeProgressiva,
-		MotiliadeTotal:              req.MotilidadeTotal,
-		Viabilidade:                 req.Viabilidade,
-		MorfologiaNormal:            req.MorfologiaNormal,
-		QualidadeGeral:              qualidade,
-		AptidaoReprodutiva:          aptidao,
-		DataValidade:                req.DataValidade,
-		TemperaturaArmazenamento:    req.TemperaturaArmazenamento,
-		TecnicoResponsavel:          req.TecnicoResponsavel,
-		Observacoes:                 req.Observacoes,
-	}
-
-	if err := s.db.WithContext(ctx).Create(avaliacao).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"reprodutor": reprodutorID})
-		return nil, apperrors.NewDatabaseError("create_avaliacao_semen", "erro ao criar avaliação de sêmen", err)
-	}
-
-	return avaliacao, nil
-}
-
-func (s *ReproducaoService) CreateGestacao(ctx context.Context, coberturaID uint, veterinarioID uint) (*models.Gestacao, error) {
-	var cobertura models.Cobertura
-	if err := s.db.WithContext(ctx).Preload("Matriz").First(&cobertura, coberturaID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "cobertura", Message: "cobertura não encontrada", ID: coberturaID}
-		}
-		return nil, apperrors.NewDatabaseError("create_gestacao", "erro ao buscar cobertura", err)
-	}
-
-	var existingGestacao models.Gestacao
-	if err := s.db.WithContext(ctx).Where("cobertura_id = ?", coberturaID).First(&existingGestacao).Error; err == nil {
-		return nil, &apperrors.ConflictError{Resource: "gestacao", Message: "já existe gestação para esta cobertura", Value: coberturaID}
-	}
-
-	dataPrevisaoParto := cobertura.DataCobertura.AddDate(0, constants.MesesGestacaoEquino, 0)
-
-	gestacao := &models.Gestacao{
-		CoberturaID:             coberturaID,
-		MatrizEquinoid:          cobertura.MatrizEquinoid,
-		DataCobertura:           cobertura.DataCobertura,
-		DataPrevistaParto:       dataPrevisaoParto,
-		VeterinarioResponsavel:  veterinarioID,
-		StatusGestacao:          models.StatusGestacaoAtiva,
-		NumeroUltrassonografias: 0,
-	}
-
-	if err := s.db.WithContext(ctx).Create(gestacao).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"cobertura_id": coberturaID})
-		return nil, apperrors.NewDatabaseError("create_gestacao", "erro ao criar gestação", err)
-	}
-
-	now := time.Now()
-	cobertura.StatusCobertura = models.StatusCoberturaConfirmada
-	cobertura.DataConfirmacao = &now
-	if err := s.db.WithContext(ctx).Save(&cobertura).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"cobertura_id": coberturaID})
-	}
-
-	return gestacao, nil
-}
-
-func (s *ReproducaoService) GetGestacoes(ctx context.Context, matrizID string) ([]*models.Gestacao, error) {
-	var gestacoes []*models.Gestacao
-	if err := s.db.WithContext(ctx).Where("matriz_equinoid = ?", matrizID).
-		Preload("Cobertura").
-		Preload("Cobertura.Reprodutor").
-		Order("data_cobertura DESC").
-		Find(&gestacoes).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"matriz": matrizID})
-		return nil, apperrors.NewDatabaseError("get_gestacoes", "erro ao buscar gestações", err)
-	}
-	return gestacoes, nil
-}
-
-func (s *ReproducaoService) RegistrarParto(ctx context.Context, gestacaoID uint, req *models.RegistrarPartoRequest) (*models.Gestacao, error) {
-	var gestacao models.Gestacao
-	if err := s.db.WithContext(ctx).First(&gestacao, gestacaoID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "gestacao", Message: "gestação não encontrada", ID: gestacaoID}
-		}
-		return nil, apperrors.NewDatabaseError("registrar_parto", "erro ao buscar gestação", err)
+		return nil, s.mapPKIError(err, "current_crl", nil)
 	}
-
-	gestacao.DataRealParto = &req.DataParto
-	gestacao.TipoParto = req.TipoParto
-	gestacao.StatusGestacao = models.StatusGestacaoConcluida
-	gestacao.Observacoes = req.ObservacoesParto
-
-	if err := s.db.WithContext(ctx).Save(&gestacao).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"gestacao_id": gestacaoID})
-		return nil, apperrors.NewDatabaseError("registrar_parto", "erro ao registrar parto", err)
-	}
-
-	return &gestacao, nil
+	return crl, nil
 }
 
-func (s *ReproducaoService) GetRankingReprodutivo(ctx context.Context, sexo string, limit int) ([]*models.RankingReprodutivo, error) {
-	var rankings []*models.RankingReprodutivo
-
-	query := `
-		SELECT 
-			e.equinoid,
-			e.nome,
-			e.sexo,
-			COUNT(DISTINCT c.id) as total_coberturas,
-			COUNT(DISTINCT g.id) as total_gestacoes,
-			COUNT(DISTINCT CASE WHEN g.resultado_parto = 'sucesso' THEN g.id END) as partos_sucesso,
-			CASE 
-				WHEN COUNT(DISTINCT c.id) > 0 
-				THEN ROUND((COUNT(DISTINCT g.id)::numeric / COUNT(DISTINCT c.id)::numeric) * 100, 2)
-				ELSE 0
-			END as taxa_concepcao
-		FROM equinos e
-	`
-
-	if sexo == "macho" {
-		query += " LEFT JOIN coberturas c ON e.equinoid = c.reprodutor_equinoid"
-	} else {
-		query += " LEFT JOIN coberturas c ON e.equinoid = c.matriz_equinoid"
-	}
-
-	query += `
-		LEFT JOIN gestacoes g ON c.id = g.cobertura_id
-		WHERE e.sexo = ?
-		GROUP BY e.equinoid, e.nome, e.sexo
-		HAVING COUNT(DISTINCT c.id) > 0
-		ORDER BY taxa_concepcao DESC, total_coberturas DESC
-		LIMIT ?
-	`
-
-	if err := s.db.WithContext(ctx).Raw(query, sexo, limit).Scan(&rankings).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"sexo": sexo})
-		return nil, apperrors.NewDatabaseError("get_ranking_reprodutivo", "erro ao obter ranking reprodutivo", err)
-	}
-
-	return rankings, nil
-}
-
-func (s *ReproducaoService) CreatePerformanceMaterna(ctx context.Context, matrizID string, gestacaoID uint, req *models.CreatePerformanceMaternaRequest) (*models.PerformanceMaterna, error) {
-	matriz, err := s.equinoRepo.FindByEquinoid(ctx, matrizID)
+// OCSPResponse resposta OCSP assinada para a requisição DER de um terceiro
+func (s *CertificateService) OCSPResponse(ctx context.Context, requestDER []byte) ([]byte, error) {
+	response, err := s.issuer.OCSPResponse(ctx, requestDER)
 	if err != nil {
-		if apperrors.IsNotFound(err) {
-			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "matriz não encontrada", ID: matrizID}
-		}
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"matriz": matrizID})
-		return nil, apperrors.NewDatabaseError("create_performance_materna", "erro ao buscar matriz", err)
-	}
-
-	if matriz.Sexo != models.SexoFemea {
-		return nil, &apperrors.ValidationError{Field: "sexo", Message: "performance materna só pode ser registrada para fêmeas", Value: matriz.Sexo}
-	}
-
-	var gestacao models.Gestacao
-	if err := s.db.WithContext(ctx).First(&gestacao, gestacaoID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "gestacao", Message: "gestação não encontrada", ID: gestacaoID}
-		}
-		return nil, apperrors.NewDatabaseError("create_performance_materna", "erro ao buscar gestação", err)
-	}
-
-	performance := &models.PerformanceMaterna{
-		MatrizEquinoid:           matrizID,
-		GestacaoID:               gestacaoID,
-		PesoInicioGestacao:       req.PesoInicioGestacao,
-		PesoFimGestacao:          req.PesoFimGestacao,
-		GanhoPesoGestacao:        req.GanhoPesoGestacao,
-		ProducaoLeiteDiaria:      req.ProducaoLeiteDiaria,
-		QualidadeLeite:           req.QualidadeLeite,
-		CuidadoMaterno:           req.CuidadoMaterno,
-		TempoDesmame:             req.TempoDesmame,
-		PesoPotroDesmame:         req.PesoPotroDesmame,
-		TempoRecuperacaoPosParto: req.TempoRecuperacaoPosParto,
-		IntervaloProximoParto:    req.IntervaloProximoParto,
-		Observacoes:              req.Observacoes,
-	}
-
-	if req.GanhoPesoGestacao == nil && req.PesoInicioGestacao != nil && req.PesoFimGestacao != nil {
-		ganho := *req.PesoFimGestacao - *req.PesoInicioGestacao
-		performance.GanhoPesoGestacao = &ganho
-	}
-
-	if err := s.db.WithContext(ctx).Create(performance).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"matriz": matrizID, "gestacao_id": gestacaoID})
-		return nil, apperrors.NewDatabaseError("create_performance_materna", "erro ao criar performance materna", err)
-	}
-
-	s.logger.LogBusinessEvent("performance_materna_created", "Performance materna registrada com sucesso", 0, matrizID, logging.Fields{"performance_id": performance.ID})
-	return performance, nil
-}
-
-func (s *ReproducaoService) CreateUltrassonografia(ctx context.Context, gestacaoID uint, req *models.CreateUltrassonografiaRequest, veterinarioID uint) (*models.Ultrassonografia, error) {
-	var gestacao models.Gestacao
-	if err := s.db.WithContext(ctx).First(&gestacao, gestacaoID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "gestacao", Message: "gestação não encontrada", ID: gestacaoID}
-		}
-		return nil, apperrors.NewDatabaseError("create_ultrassonografia", "erro ao buscar gestação", err)
-	}
-
-	ultrassonografia := &models.Ultrassonografia{
-		GestacaoID:             gestacaoID,
-		DataExame:              req.DataExame,
-		IdadeGestacional:       req.IdadeGestacional,
-		VeterinarioResponsavel: veterinarioID,
-		PresencaEmbriao:        req.PresencaEmbriao,
-		NumeroEmbrioes:         req.NumeroEmbrioes,
-		BatimentoCardiaco:      req.BatimentoCardiaco,
-		DesenvolvimentoNormal:  req.DesenvolvimentoNormal,
-		TamanhoEmbriao:         req.TamanhoEmbriao,
-		FrequenciaCardiaca:     req.FrequenciaCardiaca,
-		Diagnostico:            req.Diagnostico,
-		Observacoes:            req.Observacoes,
-		ProximoExame:           req.ProximoExame,
-	}
-
-	if err := s.db.WithContext(ctx).Create(ultrassonografia).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"gestacao_id": gestacaoID})
-		return nil, apperrors.NewDatabaseError("create_ultrassonografia", "erro ao criar ultrassonografia", err)
-	}
-
-	gestacao.NumeroUltrassonografias++
-	if req.DataExame.After(gestacao.DataPrevistaParto.Add(-30 * 24 * time.Hour)) {
-		now := time.Now()
-		gestacao.UltimaUltrassonografia = &now
-	}
-	if err := s.db.WithContext(ctx).Save(&gestacao).Error; err != nil {
-		s.logger.LogError(err, "ReproducaoService", logging.Fields{"gestacao_id": gestacaoID})
-	}
-
-	s.logger.LogBusinessEvent("ultrassonografia_created", "Ultrassonografia registrada com sucesso", veterinarioID, gestacao.MatrizEquinoid, logging.Fields{"ultrassonografia_id": ultrassonografia.ID})
-	return ultrassonografia, nil
-}
-
-func (s *ReproducaoService) determineQualidadeSemen(req *models.CreateAvaliacaoSemenRequest) models.QualidadeSemen {
-	score := 0
-	checks := 0
-
-	if req.MotilidadeProgressiva != nil {
-		checks++
-		if *req.MotilidadeProgressiva >= 60 {
-			score += 2
-		} else if *req.MotilidadeProgressiva >= 40 {
-			score += 1
-		}
-	}
-
-	if req.MotilidadeTotal != nil {
-		checks++
-		if *req.MotilidadeTotal >= 70 {
-			score += 2
-		} else if *req.MotilidadeTotal >= 50 {
-			score += 1
-		}
-	}
-
-	if req.MorfologiaNormal != nil {
-		checks++
-		if *req.MorfologiaNormal >= 70 {
-			score += 2
-		} else if *req.MorfologiaNormal >= 50 {
-			score += 1
-		}
+		return nil, s.mapPKIError(err, "ocsp_response", nil)
 	}
-
-	if req.Viabilidade != nil {
-		checks++
-		if *req.Viabilidade >= 80 {
-			score += 2
-		} else if *req.Viabilidade >= 60 {
-			score += 1
-		}
-	}
-
-	if checks == 0 {
-		return models.QualidadeRegular
-	}
-
-	avg := float64(score) / float64(checks*2)
-
-	if avg >= 0.9 {
-		return models.QualidadeExcelente
-	} else if avg >= 0.7 {
-		return models.QualidadeBoa
-	} else if avg >= 0.5 {
-		return models.QualidadeRegular
-	} else if avg >= 0.3 {
-		return models.QualidadeRuim
-	}
-	return models.QualidadeInadequada
-}
-
-func (s *ReproducaoService) determineAptidaoReprodutiva(req *models.CreateAvaliacaoSemenRequest) models.AptidaoReprodutiva {
-	qualidade := s.determineQualidadeSemen(req)
-
-	switch qualidade {
-	case models.QualidadeExcelente:
-		return models.AptidaoAlta
-	case models.QualidadeBoa:
-		return models.AptidaoMedia
-	case models.QualidadeRegular:
-		return models.AptidaoBaixa
-	default:
-		return models.AptidaoInadequada
-	}
-}
-
-type SocialService struct {
-	equinoRepo equinos.Repository
-	db         *gorm.DB
-	cache      cache.CacheInterface
-	logger     *logging.Logger
+	return response, nil
 }
 
-func NewSocialService(db *gorm.DB, cache cache.CacheInterface, logger *logging.Logger) *SocialService {
-	equinoRepo := equinos.NewRepository(db)
-	return &SocialService{
-		equinoRepo: equinoRepo,
-		db:         db,
-		cache:      cache,
-		logger:     logger,
+// publishCRL republica a CRL após uma revogação; em caso de falha o job agendado publica no próximo ciclo
+func (s *CertificateService) publishCRL(ctx context.Context, serialNumber string) {
+	if _, err := s.issuer.PublishCRL(ctx); err != nil {
+		s.logger.LogError(err, "CertificateService.publishCRL", logging.Fields{"serial": serialNumber})
 	}
 }
 
-func (s *SocialService) CreatePerfilSocial(ctx context.Context, equinoidID string, userID uint) (*models.PerfilSocial, error) {
-	_, err := s.equinoRepo.FindByEquinoid(ctx, equinoidID)
+// CACertificate certificado da CA interna em PEM
+func (s *CertificateService) CACertificate() ([]byte, error) {
+	caPEM, err := s.issuer.CACertificate()
 	if err != nil {
-		if apperrors.IsNotFound(err) {
-			return nil, &apperrors.NotFoundError{Resource: "equino", Message: "equino não encontrado", ID: equinoidID}
-		}
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("create_perfil_social", "erro ao buscar equino", err)
-	}
-
-	var existingPerfil models.PerfilSocial
-	if err := s.db.WithContext(ctx).Where("equinoid = ?", equinoidID).First(&existingPerfil).Error; err == nil {
-		return nil, &apperrors.ConflictError{Resource: "perfil_social", Message: "perfil social já existe para este equino", Value: equinoidID}
-	}
-
-	equino, _ := s.equinoRepo.FindByEquinoid(ctx, equinoidID)
-	perfil := &models.PerfilSocial{
-		Equinoid:              equinoidID,
-		NomePerfil:            equino.Nome,
-		StatusDisponibilidade: models.StatusDisponivel,
-		TipoPerfil:            models.TipoPerfilPublico,
-		MostrarLocalizacao:    true,
-		PermitirOfertas:       true,
-		PermitirContato:       true,
-		PermitirSeguir:        true,
-		CriadoPor:             userID,
-	}
-
-	if err := s.db.WithContext(ctx).Create(perfil).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("create_perfil_social", "erro ao criar perfil social", err)
-	}
-
-	return perfil, nil
-}
-
-func (s *SocialService) GetPerfilSocial(ctx context.Context, equinoidID string) (*models.PerfilSocial, error) {
-	var perfil models.PerfilSocial
-	if err := s.db.WithContext(ctx).Where("equinoid = ?", equinoidID).Preload("Equino").First(&perfil).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "perfil_social", Message: "perfil social não encontrado", ID: equinoidID}
-		}
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("get_perfil_social", "erro ao buscar perfil social", err)
-	}
-	return &perfil, nil
-}
-
-func (s *SocialService) UpdatePerfilSocial(ctx context.Context, equinoidID string, nomePerfil, bio, localizacao string) (*models.PerfilSocial, error) {
-	var perfil models.PerfilSocial
-	if err := s.db.WithContext(ctx).Where("equinoid = ?", equinoidID).First(&perfil).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "perfil_social", Message: "perfil social não encontrado", ID: equinoidID}
-		}
-		return nil, apperrors.NewDatabaseError("update_perfil_social", "erro ao buscar perfil social", err)
-	}
-
-	if nomePerfil != "" {
-		perfil.NomePerfil = nomePerfil
-	}
-	if bio != "" {
-		perfil.Bio = bio
-	}
-	if localizacao != "" {
-		perfil.Localizacao = localizacao
-	}
-
-	if err := s.db.WithContext(ctx).Save(&perfil).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("update_perfil_social", "erro ao atualizar perfil social", err)
-	}
-
-	return &perfil, nil
-}
-
-func (s *SocialService) CreatePost(ctx context.Context, equinoidID string, req *models.CreatePostRequest, userID uint) (*models.PostSocial, error) {
-	var perfil models.PerfilSocial
-	if err := s.db.WithContext(ctx).Where("equinoid = ?", equinoidID).First(&perfil).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "perfil_social", Message: "perfil social não encontrado", ID: equinoidID}
-		}
-		return nil, apperrors.NewDatabaseError("create_post", "erro ao buscar perfil social", err)
-	}
-
-	post := &models.PostSocial{
-		Equinoid:                 equinoidID,
-		PerfilSocialID:           perfil.ID,
-		TipoConteudo:             req.TipoConteudo,
-		Legenda:                  req.Legenda,
-		LocalizacaoPost:          req.LocalizacaoPost,
-		DataPostagem:             time.Now(),
-		StatusPost:               models.StatusPostAtivo,
-		PermitirComentarios:      true,
-		PermitirCompartilhamento: true,
-		CriadoPor:                userID,
-	}
-
-	if err := s.db.WithContext(ctx).Create(post).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("create_post", "erro ao criar post", err)
+		return nil, s.mapPKIError(err, "ca_certificate", nil)
 	}
-
-	perfil.TotalPosts++
-	if err := s.db.WithContext(ctx).Save(&perfil).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-	}
-
-	return post, nil
+	return caPEM, nil
 }
 
-func (s *SocialService) GetPosts(ctx context.Context, equinoidID string, page, limit int) ([]*models.PostSocial, int64, error) {
-	var posts []*models.PostSocial
-	var total int64
-
-	query := s.db.WithContext(ctx).Model(&models.PostSocial{}).Where("equinoid = ? AND status_post = ?", equinoidID, models.StatusPostAtivo)
-
-	if err := query.Count(&total).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-		return nil, 0, apperrors.NewDatabaseError("get_posts", "erro ao contar posts", err)
+func (s *CertificateService) issuedResponse(issued *pki.CertificateResponse) *models.GenerateCertificateResponse {
+	resp := &models.GenerateCertificateResponse{
+		Certificate:    issued.Certificate.ToResponse(),
+		CertificatePEM: string(issued.CertificatePEM),
+		PrivateKey:     string(issued.PrivateKeyPEM),
 	}
-
-	offset := (page - 1) * limit
-	if err := query.Offset(offset).Limit(limit).Order("data_postagem DESC").Find(&posts).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-		return nil, 0, apperrors.NewDatabaseError("get_posts", "erro ao listar posts", err)
+	if caPEM, err := s.issuer.CACertificate(); err == nil {
+		resp.CAChainPEM = string(caPEM)
 	}
-
-	return posts, total, nil
+	return resp
 }
 
-func (s *SocialService) CreateInteracao(ctx context.Context, postID uint, userID uint, tipoInteracao models.TipoInteracao) error {
-	var post models.PostSocial
-	if err := s.db.WithContext(ctx).First(&post, postID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return &apperrors.NotFoundError{Resource: "post", Message: "post não encontrado", ID: postID}
-		}
-		return apperrors.NewDatabaseError("create_interacao", "erro ao buscar post", err)
-	}
-
-	var existingInteracao models.InteracaoSocial
-	if err := s.db.WithContext(ctx).Where("post_id = ? AND usuario_id = ? AND tipo_interacao = ?", postID, userID, tipoInteracao).First(&existingInteracao).Error; err == nil {
-		return &apperrors.ConflictError{Resource: "interacao", Message: "interação já existe", Value: postID}
-	}
-
-	interacao := &models.InteracaoSocial{
-		PostID:        postID,
-		UserID:        userID,
-		TipoInteracao: tipoInteracao,
+func (s *CertificateService) mapPKIError(err error, op string, fields logging.Fields) error {
+	switch {
+	case errors.Is(err, pki.ErrInvalidCSR), errors.Is(err, pki.ErrWeakKey):
+		return &apperrors.ValidationError{Field: "csr", Message: err.Error()}
+	case errors.Is(err, pki.ErrCertificateExists):
+		return &apperrors.ConflictError{Resource: "certificate", Message: "já existe certificado válido para este equino e tipo"}
+	case errors.Is(err, pki.ErrRenewalNotEligible):
+		return &apperrors.ValidationError{Field: "serial_number", Message: "certificado só pode ser renovado nos 30 dias anteriores ao vencimento"}
+	case errors.Is(err, pki.ErrCertificateRevoked):
+		return &apperrors.ValidationError{Field: "serial_number", Message: "certificado revogado não pode ser renovado"}
+	case errors.Is(err, pki.ErrCertificateNotFound):
+		return &apperrors.NotFoundError{Resource: "certificate", Message: "certificado não encontrado"}
+	case errors.Is(err, pki.ErrCANotInitialized):
+		return apperrors.NewBusinessError("pki_unavailable", "autoridade certificadora indisponível", nil)
 	}
 
-	if err := s.db.WithContext(ctx).Create(interacao).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"post_id": postID, "user_id": userID})
-		return apperrors.NewDatabaseError("create_interacao", "erro ao criar interação", err)
-	}
-
-	if tipoInteracao == models.TipoInteracaoCurtida {
-		post.TotalCurtidas++
-	} else if tipoInteracao == models.TipoInteracaoCompartilhamento {
-		post.TotalCompartilhamentos++
-	}
-
-	if err := s.db.WithContext(ctx).Save(&post).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"post_id": postID})
-	}
-
-	return nil
-}
-
-func (s *SocialService) CreateOferta(ctx context.Context, equinoidID string, req *models.CreateOfertaRequest, userID uint) (*models.Oferta, error) {
-	var perfil models.PerfilSocial
-	if err := s.db.WithContext(ctx).Where("equinoid = ?", equinoidID).First(&perfil).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, &apperrors.NotFoundError{Resource: "perfil_social", Message: "perfil social não encontrado", ID: equinoidID}
-		}
-		return nil, apperrors.NewDatabaseError("create_oferta", "erro ao buscar perfil social", err)
-	}
-
-	if !perfil.PermitirOfertas {
-		return nil, &apperrors.ValidationError{Field: "permitir_ofertas", Message: "este perfil não permite ofertas", Value: false}
-	}
-
-	oferta := &models.Oferta{
-		Equinoid:        equinoidID,
-		OfertantePorID:  userID,
-		TipoOferta:      req.TipoOferta,
-		ValorOferta:     req.ValorOferta,
-		Moeda:           req.Moeda,
-		CondicoesOferta: req.CondicoesOferta,
-		PrazoOferta:     req.PrazoOferta,
-		StatusOferta:    models.StatusOfertaPendente,
-	}
-
-	if err := s.db.WithContext(ctx).Create(oferta).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("create_oferta", "erro ao criar oferta", err)
-	}
-
-	return oferta, nil
-}
-
-func (s *SocialService) GetOfertas(ctx context.Context, equinoidID string) ([]*models.Oferta, error) {
-	var ofertas []*models.Oferta
-	if err := s.db.WithContext(ctx).Where("equinoid = ?", equinoidID).
-		Order("created_at DESC").
-		Find(&ofertas).Error; err != nil {
-		s.logger.LogError(err, "SocialService", logging.Fields{"equinoid": equinoidID})
-		return nil, apperrors.NewDatabaseError("get_ofertas", "erro ao listar ofertas", err)
-	}
-	return ofertas, nil
+	s.logger.LogError(err, "CertificateService", fields)
+	return apperrors.NewDatabaseError(op, "erro ao processar certificado", err)
 }
 
 type IntegrationService struct {
@@ -2058,12 +1076,14 @@ func NewSearchService(db *gorm.DB, cache cache.CacheInterface, logger *logging.L
 	}
 }
 
+// SearchEquinos busca por nome ou EquinoId no banco, com total e paginação do resultado filtrado; a busca com facetas
+// e texto sem acentos fica no módulo busca
 func (s *SearchService) SearchEquinos(ctx context.Context, query string, filters map[string]interface{}, page, limit int) ([]*models.Equino, int64, error) {
 	if query != "" {
 		if filters == nil {
 			filters = make(map[string]interface{})
 		}
-		filters["nome"] = query
+		filters["search"] = query
 	}
 
 	equinos, total, err := s.equinoRepo.List(ctx, page, limit, filters)
@@ -2072,24 +1092,15 @@ func (s *SearchService) SearchEquinos(ctx context.Context, query string, filters
 		return nil, 0, apperrors.NewDatabaseError("search_equinos", "erro ao buscar equinos", err)
 	}
 
-	if query != "" {
-		filtered := []*models.Equino{}
-		for _, equino := range equinos {
-			if strings.Contains(strings.ToLower(equino.Nome), strings.ToLower(query)) || strings.Contains(strings.ToLower(equino.Equinoid), strings.ToLower(query)) {
-				filtered = append(filtered, equino)
-			}
-		}
-		return filtered, int64(len(filtered)), nil
-	}
-
 	return equinos, total, nil
 }
 
+// SearchAdvanced converte a faixa de idade em datas de nascimento para que o filtro seja aplicado no banco
 func (s *SearchService) SearchAdvanced(ctx context.Context, criteria *models.SearchCriteria) ([]*models.Equino, int64, error) {
 	filters := make(map[string]interface{})
 
 	if criteria.Nome != "" {
-		filters["nome"] = criteria.Nome
+		filters["search"] = criteria.Nome
 	}
 	if criteria.Raca != "" {
 		filters["raca"] = criteria.Raca
@@ -2097,6 +1108,13 @@ func (s *SearchService) SearchAdvanced(ctx context.Context, criteria *models.Sea
 	if criteria.Sexo != "" {
 		filters["sexo"] = criteria.Sexo
 	}
+	now := time.Now()
+	if criteria.IdadeMin != nil {
+		filters["nascido_ate"] = now.AddDate(-*criteria.IdadeMin, 0, 0)
+	}
+	if criteria.IdadeMax != nil {
+		filters["nascido_apos"] = now.AddDate(-(*criteria.IdadeMax + 1), 0, 0)
+	}
 
 	equinos, total, err := s.equinoRepo.List(ctx, criteria.Page, criteria.Limit, filters)
 	if err != nil {
@@ -2104,43 +1122,33 @@ func (s *SearchService) SearchAdvanced(ctx context.Context, criteria *models.Sea
 		return nil, 0, apperrors.NewDatabaseError("search_advanced", "erro ao buscar equinos", err)
 	}
 
-	if criteria.IdadeMin != nil || criteria.IdadeMax != nil {
-		filtered := []*models.Equino{}
-		now := time.Now()
-		for _, equino := range equinos {
-			if equino.DataNascimento == nil {
-				continue
-			}
-			idade := now.Year() - equino.DataNascimento.Year()
-			if criteria.IdadeMin != nil && idade < *criteria.IdadeMin {
-				continue
-			}
-			if criteria.IdadeMax != nil && idade > *criteria.IdadeMax {
-				continue
-			}
-			filtered = append(filtered, equino)
-		}
-		return filtered, int64(len(filtered)), nil
-	}
-
 	return equinos, total, nil
 }
 
 type WebhookService struct {
-	db     *gorm.DB
-	cache  cache.CacheInterface
-	logger *logging.Logger
+	db      *gorm.DB
+	cache   cache.CacheInterface
+	consent ConsentChecker
+	client  *http.Client
+	logger  *logging.Logger
 }
 
-func NewWebhookService(db *gorm.DB, cache cache.CacheInterface, logger *logging.Logger) *WebhookService {
+func NewWebhookService(db *gorm.DB, cache cache.CacheInterface, consent ConsentChecker, logger *logging.Logger) *WebhookService {
 	return &WebhookService{
-		db:     db,
-		cache:  cache,
-		logger: logger,
+		db:      db,
+		cache:   cache,
+		consent: consent,
+		client:  &http.Client{Timeout: webhookTimeout},
+		logger:  logger,
 	}
 }
 
 func (s *WebhookService) RegisterWebhook(ctx context.Context, userID uint, req *models.WebhookRequest) (*models.Webhook, error) {
+	// Webhooks enviam dados do titular a sistemas de terceiros
+	if err := s.consent.RequireConsent(ctx, userID, models.FinalidadeCompartilhamentoTerceiros); err != nil {
+		return nil, err
+	}
+
 	webhook := &models.Webhook{
 		UserID:   userID,
 		URL:      req.URL,
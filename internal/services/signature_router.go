@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/equinoid/backend/pkg/logging"
 )
@@ -43,6 +44,11 @@ func (r *SignatureRouter) ShouldUseD4Sign(documentType string) bool {
 	return r.DetermineTier(documentType) == Tier1Legal
 }
 
+// RequiresBiometric indica se o tier exige confirmação biométrica do signatário (step-up) antes da assinatura
+func (r *SignatureRouter) RequiresBiometric(tier SignatureTier) bool {
+	return tier == Tier2Operational
+}
+
 func (r *SignatureRouter) GetSignatureMethod(ctx context.Context, documentType string) (string, error) {
 	tier := r.DetermineTier(documentType)
 
@@ -60,6 +66,8 @@ type SignatureRequest struct {
 	DocumentType      string
 	DocumentHash      string
 	DocumentData      interface{}
+	Document          []byte
+	Location          string
 	SignerID          uint
 	SignerEmail       string
 	SignerName        string
@@ -70,10 +78,17 @@ type SignatureRequest struct {
 }
 
 type SignatureResponse struct {
-	Success       bool
-	Method        string
-	DocumentUUID  string
-	SignatureHash string
-	Status        string
-	Message       string
+	Success            bool
+	Method             string
+	DocumentUUID       string
+	SignatureHash      string
+	Status             string
+	Message            string
+	SignatureID        uint
+	CertificateSerial  string
+	CAdES              []byte
+	SignedPDF          []byte
+	SignedAt           time.Time
+	TimestampTime      *time.Time
+	TimestampAuthority string
 }
@@ -104,7 +104,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		t.Skip("sqlite driver unavailable for tests")
 	}
 
-	db.AutoMigrate(&models.User{}, &models.Equino{})
+	db.AutoMigrate(&models.User{}, &models.Equino{}, &models.EquinoIdentificador{})
 	return db
 }
 
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equinoid/backend/internal/models"
+	apperrors "github.com/equinoid/backend/pkg/errors"
+	"github.com/equinoid/backend/pkg/logging"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// Cabeçalhos enviados em cada entrega de webhook
+const (
+	WebhookEventHeader     = "X-Equinoid-Event"
+	WebhookTimestampHeader = "X-Equinoid-Timestamp"
+	WebhookSignatureHeader = "X-Equinoid-Signature"
+)
+
+// DispatchEvent entrega um evento aos webhooks ativos do usuário inscritos nele. Nada é enviado sem o
+// consentimento de compartilhamento com terceiros; falhas de entrega individuais são registradas e não interrompem as demais.
+func (s *WebhookService) DispatchEvent(ctx context.Context, userID uint, event string, payload interface{}) (int, error) {
+	if err := s.consent.RequireConsent(ctx, userID, models.FinalidadeCompartilhamentoTerceiros); err != nil {
+		return 0, err
+	}
+
+	var webhooks []models.Webhook
+	if err := s.db.WithContext(ctx).Where("user_id = ? AND is_active = ?", userID, true).Find(&webhooks).Error; err != nil {
+		s.logger.LogError(err, "WebhookService.DispatchEvent", logging.Fields{"user_id": userID})
+		return 0, apperrors.NewDatabaseError("dispatch_webhook", "erro ao buscar webhooks", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"event":     event,
+		"timestamp": time.Now().UTC(),
+		"data":      payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	delivered := 0
+	for _, webhook := range webhooks {
+		if !webhookSubscribed(webhook, event) {
+			continue
+		}
+		if err := s.deliver(ctx, webhook, event, body); err != nil {
+			s.logger.LogError(err, "WebhookService.DispatchEvent", logging.Fields{"webhook_id": webhook.ID, "event": event})
+			continue
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// DeactivateUserWebhooks desativa os webhooks do usuário; executado quando o consentimento de compartilhamento
+// com terceiros é revogado ou expira
+func (s *WebhookService) DeactivateUserWebhooks(ctx context.Context, userID uint) error {
+	result := s.db.WithContext(ctx).Model(&models.Webhook{}).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Updates(map[string]interface{}{
+			"is_active":  false,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		s.logger.LogError(result.Error, "WebhookService.DeactivateUserWebhooks", logging.Fields{"user_id": userID})
+		return apperrors.NewDatabaseError("deactivate_webhooks", "erro ao desativar webhooks", result.Error)
+	}
+
+	s.logger.WithFields(logging.Fields{"user_id": userID, "webhooks": result.RowsAffected}).Info("Webhooks desativados após revogação de consentimento")
+	return nil
+}
+
+func (s *WebhookService) deliver(ctx context.Context, webhook models.Webhook, event string, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, signWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signWebhookPayload assina "<timestamp>.<corpo>" com HMAC-SHA256 usando o segredo do webhook
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookSubscribed(webhook models.Webhook, event string) bool {
+	switch events := webhook.Events["events"].(type) {
+	case []string:
+		for _, e := range events {
+			if e == event || e == "*" {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, e := range events {
+			if name, ok := e.(string); ok && (name == event || name == "*") {
+				return true
+			}
+		}
+	}
+	return false
+}
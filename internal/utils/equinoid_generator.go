@@ -13,16 +13,34 @@ import (
 )
 
 const (
-	EquinoIdVersion = "1"
-	EquinoIdPrefix  = "EQ"
-	EquinoIdLength  = 24
+	// EquinoIdVersion versão emitida para novos equinos
+	EquinoIdVersion   = EquinoIdVersionV2
+	EquinoIdVersionV1 = "1"
+	EquinoIdVersionV2 = "2"
+	EquinoIdPrefix    = "EQ"
+	EquinoIdLength    = 24
 
 	EquinoIdNamespace = "a3f5d8e7-1c4b-4a9e-8f2d-6b3c5e7a9d1f"
+
+	// paisDesconhecido país usado nos aliases v2 de equinos legados sem país de origem válido
+	paisDesconhecido = "XXX"
 )
 
-// GenerateEquinoId gera um EquinoId único e compacto
-// Formato: EQ + VER(1) + PAIS(3) + UUID4(6) + UUID5(8) + CRC16(4) = 24 chars
-// Exemplo: EQ1BRA3F8A2C5D7E9B1F2A4C (24 caracteres)
+// iso7064Alphabet alfabeto do ISO 7064 MOD 1271-36: o índice de cada caractere é o seu valor
+const iso7064Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// EquinoIdInfo componentes de um EquinoId, conforme a versão
+type EquinoIdInfo struct {
+	Versao   string
+	Pais     string
+	Corpo    string // identificador aleatório + derivado dos dados do equino
+	Checksum string
+	Completo string
+}
+
+// GenerateEquinoId gera um EquinoId único e compacto na versão atual (v2)
+// Formato: EQ + VER(1) + PAIS(3) + UUID4(8) + UUID5(8) + ISO 7064 MOD 1271-36(2) = 24 chars
+// Exemplo: EQ2BRA3F8A2C5D7E9B1F2AUD (24 caracteres)
 func GenerateEquinoId(paisOrigem, microchipID, nome string, dataNascimento time.Time, sexo, pelagem, raca string) (string, error) {
 	if len(paisOrigem) != 3 {
 		return "", errors.New("paisOrigem deve ter exatamente 3 caracteres")
@@ -35,9 +53,12 @@ func GenerateEquinoId(paisOrigem, microchipID, nome string, dataNascimento time.
 	}
 
 	paisOrigem = strings.ToUpper(paisOrigem)
+	if !isAlpha(paisOrigem) {
+		return "", errors.New("paisOrigem deve conter apenas letras")
+	}
 
 	uuidv4 := uuid.New().String()
-	uuidv4Part := strings.ToUpper(strings.ReplaceAll(uuidv4, "-", "")[:6])
+	uuidv4Part := strings.ToUpper(strings.ReplaceAll(uuidv4, "-", "")[:8])
 
 	namespace := uuid.MustParse(EquinoIdNamespace)
 	dataForUUIDv5 := fmt.Sprintf("%s|%s|%s|%s|%s",
@@ -50,16 +71,35 @@ func GenerateEquinoId(paisOrigem, microchipID, nome string, dataNascimento time.
 	uuidv5 := uuid.NewSHA1(namespace, []byte(dataForUUIDv5)).String()
 	uuidv5Part := strings.ToUpper(strings.ReplaceAll(uuidv5, "-", "")[:8])
 
-	baseId := fmt.Sprintf("%s%s%s%s%s", EquinoIdPrefix, EquinoIdVersion, paisOrigem, uuidv4Part, uuidv5Part)
+	baseId := EquinoIdPrefix + EquinoIdVersionV2 + paisOrigem + uuidv4Part + uuidv5Part
+	return baseId + calculateISO7064Mod1271_36(baseId), nil
+}
+
+// EquinoIdV2FromLegacy alias v2 determinístico de um EquinoId anterior (v1 ou legado); o corpo é derivado do SHA-256
+// do identificador original. Deve produzir o mesmo resultado que a função SQL usada no backfill da migração 018
+func EquinoIdV2FromLegacy(paisOrigem, equinoId string) (string, error) {
+	if equinoId == "" {
+		return "", errors.New("EquinoId é obrigatório")
+	}
+
+	paisOrigem = strings.ToUpper(paisOrigem)
+	if len(paisOrigem) != 3 || !isAlpha(paisOrigem) {
+		paisOrigem = paisDesconhecido
+	}
 
-	checksum := calculateCRC16Checksum(baseId)
+	hash := sha256.Sum256([]byte(equinoId))
+	corpo := strings.ToUpper(hex.EncodeToString(hash[:])[:16])
 
-	equinoId := fmt.Sprintf("%s%s", baseId, checksum)
+	baseId := EquinoIdPrefix + EquinoIdVersionV2 + paisOrigem + corpo
+	return baseId + calculateISO7064Mod1271_36(baseId), nil
+}
 
-	return equinoId, nil
+// NormalizeEquinoId remove espaços e padroniza em maiúsculas, como os EquinoIds são emitidos
+func NormalizeEquinoId(equinoId string) string {
+	return strings.ToUpper(strings.TrimSpace(equinoId))
 }
 
-// calculateCRC16Checksum calcula um checksum CRC-16 (4 caracteres hex)
+// calculateCRC16Checksum checksum da v1: 16 bits baixos do CRC-32 (4 caracteres hex)
 func calculateCRC16Checksum(data string) string {
 	crc := crc32.ChecksumIEEE([]byte(data))
 	return strings.ToUpper(fmt.Sprintf("%04X", crc&0xFFFF))
@@ -71,60 +111,132 @@ func calculateSHA256Checksum(data string) string {
 	return strings.ToUpper(hex.EncodeToString(hash[:])[:4])
 }
 
-// ValidateEquinoIdFormat valida se um EquinoId está no formato correto
-func ValidateEquinoIdFormat(equinoId string) error {
-	if len(equinoId) != EquinoIdLength {
-		return fmt.Errorf("EquinoId deve ter %d caracteres, tem %d", EquinoIdLength, len(equinoId))
+// calculateISO7064Mod1271_36 dois caracteres de verificação do ISO 7064 MOD 1271-36 (sistema puro), que detectam
+// qualquer substituição de um caractere e qualquer transposição de caracteres adjacentes. data deve conter apenas
+// dígitos e letras maiúsculas
+func calculateISO7064Mod1271_36(data string) string {
+	const modulus, radix = 1271, 36
+
+	p := 0
+	for i := 0; i < len(data); i++ {
+		p = ((p + strings.IndexByte(iso7064Alphabet, data[i])) * radix) % modulus
 	}
+	p = (p * radix) % modulus
+	check := (modulus + 1 - p) % modulus
+
+	return string([]byte{iso7064Alphabet[check/radix], iso7064Alphabet[check%radix]})
+}
 
+// EquinoIdVersionOf versão declarada no EquinoId (terceiro caractere)
+func EquinoIdVersionOf(equinoId string) (string, error) {
+	if len(equinoId) != EquinoIdLength {
+		return "", fmt.Errorf("EquinoId deve ter %d caracteres, tem %d", EquinoIdLength, len(equinoId))
+	}
 	if !strings.HasPrefix(equinoId, EquinoIdPrefix) {
-		return fmt.Errorf("EquinoId deve começar com %s", EquinoIdPrefix)
+		return "", fmt.Errorf("EquinoId deve começar com %s", EquinoIdPrefix)
 	}
 
 	version := string(equinoId[2])
-	if version != EquinoIdVersion {
-		return fmt.Errorf("versão inválida: %s (esperado: %s)", version, EquinoIdVersion)
+	switch version {
+	case EquinoIdVersionV1, EquinoIdVersionV2:
+		return version, nil
 	}
+	return "", fmt.Errorf("versão inválida: %s (suportadas: %s, %s)", version, EquinoIdVersionV1, EquinoIdVersionV2)
+}
 
-	hexPart := equinoId[6:]
-	if _, err := hex.DecodeString(hexPart); err != nil {
-		return errors.New("EquinoId contém caracteres inválidos")
+// ValidateEquinoIdFormat valida se um EquinoId está no formato da sua versão
+func ValidateEquinoIdFormat(equinoId string) error {
+	version, err := EquinoIdVersionOf(equinoId)
+	if err != nil {
+		return err
+	}
+
+	if version == EquinoIdVersionV1 {
+		hexPart := equinoId[6:]
+		if _, err := hex.DecodeString(hexPart); err != nil {
+			return errors.New("EquinoId contém caracteres inválidos")
+		}
+		return nil
 	}
 
+	if !isAlpha(equinoId[3:6]) {
+		return errors.New("país de origem do EquinoId deve conter apenas letras maiúsculas")
+	}
+	if !isUpperHex(equinoId[6:22]) {
+		return errors.New("EquinoId contém caracteres inválidos")
+	}
+	if !isAlphanumeric(equinoId[22:]) {
+		return errors.New("caracteres de verificação do EquinoId inválidos")
+	}
 	return nil
 }
 
-// ValidateEquinoIdChecksum valida o checksum de um EquinoId
+// ValidateEquinoIdChecksum valida o checksum de um EquinoId conforme a sua versão
 func ValidateEquinoIdChecksum(equinoId string) error {
-	if err := ValidateEquinoIdFormat(equinoId); err != nil {
+	info, err := ParseEquinoId(equinoId)
+	if err != nil {
 		return err
 	}
 
-	baseId := equinoId[:20]
-	providedChecksum := equinoId[20:]
-
-	calculatedChecksum := calculateCRC16Checksum(baseId)
+	var calculatedChecksum string
+	switch info.Versao {
+	case EquinoIdVersionV1:
+		calculatedChecksum = calculateCRC16Checksum(equinoId[:20])
+	default:
+		calculatedChecksum = calculateISO7064Mod1271_36(equinoId[:22])
+	}
 
-	if providedChecksum != calculatedChecksum {
+	if info.Checksum != calculatedChecksum {
 		return errors.New("checksum inválido")
 	}
 
 	return nil
 }
 
-// ParseEquinoId extrai informações do EquinoId
-func ParseEquinoId(equinoId string) (map[string]string, error) {
+// ParseEquinoId extrai os componentes do EquinoId conforme a versão
+func ParseEquinoId(equinoId string) (*EquinoIdInfo, error) {
 	if err := ValidateEquinoIdFormat(equinoId); err != nil {
 		return nil, err
 	}
 
-	return map[string]string{
-		"prefix":   equinoId[:2],
-		"version":  equinoId[2:3],
-		"pais":     equinoId[3:6],
-		"uuid4":    equinoId[6:12],
-		"uuid5":    equinoId[12:20],
-		"checksum": equinoId[20:24],
-		"full":     equinoId,
-	}, nil
+	info := &EquinoIdInfo{
+		Versao:   equinoId[2:3],
+		Pais:     equinoId[3:6],
+		Completo: equinoId,
+	}
+	if info.Versao == EquinoIdVersionV1 {
+		info.Corpo = equinoId[6:20]
+		info.Checksum = equinoId[20:24]
+	} else {
+		info.Corpo = equinoId[6:22]
+		info.Checksum = equinoId[22:24]
+	}
+	return info, nil
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isUpperHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9') && !(s[i] >= 'A' && s[i] <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9') && !(s[i] >= 'A' && s[i] <= 'Z') {
+			return false
+		}
+	}
+	return true
 }
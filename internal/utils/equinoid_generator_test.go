@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateISO7064Mod1271_36(t *testing.T) {
+	casos := []struct {
+		dados    string
+		esperado string
+	}{
+		{dados: "ISO79", esperado: "3W"}, // exemplo da própria norma
+		{dados: "EQ2BRA3F8A2C5D7E9B1F2A", esperado: "UD"},
+		{dados: "EQ2BRA0000000000000000", esperado: "Z9"},
+		{dados: "EQ2XXXFFFFFFFFFFFFFFFF", esperado: "PB"},
+		{dados: "EQ2PRTA1B2C3D4E5F60718", esperado: "0U"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.dados, func(t *testing.T) {
+			assert.Equal(t, caso.esperado, calculateISO7064Mod1271_36(caso.dados))
+		})
+	}
+}
+
+func TestValidateEquinoIdChecksum_RejeitaTransposicaoAdjacente(t *testing.T) {
+	const valido = "EQ2BRA3F8A2C5D7E9B1F2AUD"
+	require.NoError(t, ValidateEquinoIdChecksum(valido))
+
+	for i := 0; i < len(valido)-1; i++ {
+		if valido[i] == valido[i+1] {
+			continue
+		}
+		trocado := []byte(valido)
+		trocado[i], trocado[i+1] = trocado[i+1], trocado[i]
+		assert.Error(t, ValidateEquinoIdChecksum(string(trocado)), "transposição nas posições %d e %d: %s", i, i+1, trocado)
+	}
+}
+
+func TestValidateEquinoIdChecksum_RejeitaSubstituicao(t *testing.T) {
+	const valido = "EQ2BRA3F8A2C5D7E9B1F2AUD"
+	for i := 6; i < 22; i++ {
+		trocado := []byte(valido)
+		if trocado[i] == '0' {
+			trocado[i] = '1'
+		} else {
+			trocado[i] = '0'
+		}
+		assert.Error(t, ValidateEquinoIdChecksum(string(trocado)), "substituição na posição %d: %s", i, trocado)
+	}
+}
+
+func TestGenerateEquinoId_EmiteV2Valido(t *testing.T) {
+	nascimento := time.Date(2021, 9, 14, 0, 0, 0, 0, time.UTC)
+	equinoId, err := GenerateEquinoId("bra", "985000000000001", "Relâmpago", nascimento, "macho", "Alazã", "Mangalarga Marchador")
+	require.NoError(t, err)
+
+	assert.Len(t, equinoId, EquinoIdLength)
+	assert.Equal(t, "EQ2BRA", equinoId[:6])
+	assert.NoError(t, ValidateEquinoIdChecksum(equinoId))
+
+	_, err = GenerateEquinoId("BR", "985000000000001", "Relâmpago", nascimento, "macho", "Alazã", "Mangalarga Marchador")
+	assert.Error(t, err)
+}
+
+func TestParseEquinoId_PorVersao(t *testing.T) {
+	casos := []struct {
+		nome     string
+		equinoId string
+		versao   string
+		pais     string
+		corpo    string
+		checksum string
+	}{
+		{nome: "v1", equinoId: "EQ1BRA3F8A2C5D7E9B1F7350", versao: EquinoIdVersionV1, pais: "BRA", corpo: "3F8A2C5D7E9B1F", checksum: "7350"},
+		{nome: "v1 outro país", equinoId: "EQ1USA0123456789ABCD11DE", versao: EquinoIdVersionV1, pais: "USA", corpo: "0123456789ABCD", checksum: "11DE"},
+		{nome: "v2", equinoId: "EQ2BRA3F8A2C5D7E9B1F2AUD", versao: EquinoIdVersionV2, pais: "BRA", corpo: "3F8A2C5D7E9B1F2A", checksum: "UD"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			info, err := ParseEquinoId(caso.equinoId)
+			require.NoError(t, err)
+			assert.Equal(t, caso.versao, info.Versao)
+			assert.Equal(t, caso.pais, info.Pais)
+			assert.Equal(t, caso.corpo, info.Corpo)
+			assert.Equal(t, caso.checksum, info.Checksum)
+			assert.Equal(t, caso.equinoId, info.Completo)
+			assert.NoError(t, ValidateEquinoIdChecksum(caso.equinoId))
+		})
+	}
+
+	invalidos := map[string]string{
+		"versão desconhecida":     "EQ3BRA3F8A2C5D7E9B1F2AUD",
+		"prefixo":                 "XX2BRA3F8A2C5D7E9B1F2AUD",
+		"tamanho":                 "EQ2BRA3F8A2C5D7E9B1F2A",
+		"v1 com corpo não hex":    "EQ1BRA3F8A2C5D7E9B1Z7350",
+		"v2 com país numérico":    "EQ2B1A3F8A2C5D7E9B1F2AUD",
+		"v2 com corpo minúsculas": "EQ2BRA3f8a2c5d7e9b1f2aUD",
+	}
+	for nome, equinoId := range invalidos {
+		t.Run(nome, func(t *testing.T) {
+			_, err := ParseEquinoId(equinoId)
+			assert.Error(t, err)
+		})
+	}
+
+	assert.EqualError(t, ValidateEquinoIdChecksum("EQ1BRA3F8A2C5D7E9B1F7351"), "checksum inválido")
+	assert.EqualError(t, ValidateEquinoIdChecksum("EQ2BRA3F8A2C5D7E9B1F2AUE"), "checksum inválido")
+}
+
+// Os valores esperados saem da expressão SQL do backfill da migração 018 (SHA-256 do EquinoId em UTF-8, 16 primeiros
+// dígitos hex em maiúsculas, equinoid_iso7064_mod1271_36); se um dos lados mudar, o alias deixa de coincidir
+func TestEquinoIdV2FromLegacy_IgualAMigracao018(t *testing.T) {
+	casos := []struct {
+		pais     string
+		equinoId string
+		esperado string
+	}{
+		{pais: "BRA", equinoId: "EQ1BRA3F8A2C5D7E9B1F2A4C", esperado: "EQ2BRAE9ADEBB3219ECF6AMN"},
+		{pais: "bra", equinoId: "EQ1BRA3F8A2C5D7E9B1F2A4C", esperado: "EQ2BRAE9ADEBB3219ECF6AMN"},
+		{pais: "", equinoId: "LEGADO-0001", esperado: "EQ2XXX71F9BFB1510D91C0UJ"},
+		{pais: "BR", equinoId: "ABCCMM 12345", esperado: "EQ2XXX0710A0AD82BED2C7A0"},
+		{pais: "USA", equinoId: "Relâmpago-01", esperado: "EQ2USA4FDF476BD491DB84U3"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.equinoId, func(t *testing.T) {
+			alias, err := EquinoIdV2FromLegacy(caso.pais, caso.equinoId)
+			require.NoError(t, err)
+			assert.Equal(t, caso.esperado, alias)
+			assert.NoError(t, ValidateEquinoIdChecksum(alias))
+		})
+	}
+
+	_, err := EquinoIdV2FromLegacy("BRA", "")
+	assert.Error(t, err)
+}
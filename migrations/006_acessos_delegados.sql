@@ -0,0 +1,73 @@
+-- Acesso delegado por equino (veterinário, laboratório, treinador, nutricionista)
+
+CREATE TABLE IF NOT EXISTS equino_veterinarios (
+    id SERIAL PRIMARY KEY,
+    equino_id INTEGER NOT NULL REFERENCES equinos(id) ON DELETE CASCADE,
+    veterinario_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    nomeado_por_id INTEGER NOT NULL REFERENCES users(id),
+    data_nomeacao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    is_principal BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE equino_veterinarios
+    ADD COLUMN IF NOT EXISTS papel VARCHAR(20) NOT NULL DEFAULT 'veterinario',
+    ADD COLUMN IF NOT EXISTS escopos VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pendente',
+    ADD COLUMN IF NOT EXISTS expira_em TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS aceito_em TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS revogado_em TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS revogado_por_id INTEGER REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Nomeações anteriores ao convite (sem escopos) continuam valendo: ficam ativas com todos os escopos
+UPDATE equino_veterinarios
+SET status = 'ativo',
+    escopos = 'ler_saude,escrever_exames,assinar_registros',
+    aceito_em = COALESCE(aceito_em, data_nomeacao)
+WHERE escopos IS NULL OR escopos = '';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_equino_veterinarios_papel') THEN
+        ALTER TABLE equino_veterinarios
+            ADD CONSTRAINT chk_equino_veterinarios_papel CHECK (papel IN ('veterinario', 'laboratorio', 'treinador', 'nutricionista'));
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_equino_veterinarios_status') THEN
+        ALTER TABLE equino_veterinarios
+            ADD CONSTRAINT chk_equino_veterinarios_status CHECK (status IN ('pendente', 'ativo', 'recusado', 'revogado', 'expirado'));
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_equino_veterinarios_equino_id ON equino_veterinarios(equino_id);
+CREATE INDEX IF NOT EXISTS idx_equino_veterinarios_veterinario_id ON equino_veterinarios(veterinario_id);
+CREATE INDEX IF NOT EXISTS idx_equino_veterinarios_status ON equino_veterinarios(status);
+CREATE INDEX IF NOT EXISTS idx_equino_veterinarios_ativo ON equino_veterinarios(equino_id, veterinario_id, status);
+
+-- Log de auditoria com referência ao usuário interno (users.id)
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id SERIAL PRIMARY KEY,
+    user_id UUID,
+    action VARCHAR(100) NOT NULL,
+    resource VARCHAR(100),
+    resource_id UUID,
+    details JSONB,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    location VARCHAR(255),
+    success BOOLEAN NOT NULL DEFAULT TRUE,
+    error_msg TEXT,
+    risk_level VARCHAR(20),
+    timestamp TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_timestamp ON audit_logs(timestamp);
+
+COMMENT ON COLUMN equino_veterinarios.escopos IS 'Escopos separados por vírgula: ler_saude, escrever_exames, assinar_registros';
+COMMENT ON COLUMN equino_veterinarios.veterinario_id IS 'Profissional convidado (veterinário, laboratório, treinador ou nutricionista)';
@@ -0,0 +1,31 @@
+-- Migration: Cadeia de hashes da trilha de auditoria e checkpoints de Merkle ancorados
+-- Cada registro guarda o hash do anterior; checkpoints periódicos consolidam intervalos da cadeia
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_hash ON audit_logs(hash);
+
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id SERIAL PRIMARY KEY,
+    from_log_id INTEGER NOT NULL,
+    to_log_id INTEGER NOT NULL,
+    entry_count INTEGER NOT NULL,
+    merkle_root VARCHAR(64) NOT NULL,
+    last_hash VARCHAR(64) NOT NULL,
+    last_timestamp TIMESTAMP,
+    prev_checkpoint_root VARCHAR(64),
+    anchor_network VARCHAR(50),
+    anchor_ref VARCHAR(255),
+    anchor_status VARCHAR(50) DEFAULT 'pending',
+    anchor_error TEXT,
+    anchored_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_audit_checkpoints_anchor_status CHECK (anchor_status IN ('pending', 'anchored', 'failed'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_checkpoints_to_log_id ON audit_checkpoints(to_log_id);
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_from_log_id ON audit_checkpoints(from_log_id);
+
+COMMENT ON COLUMN audit_logs.hash IS 'SHA-256 do conteúdo canônico do registro encadeado ao prev_hash';
+COMMENT ON TABLE audit_checkpoints IS 'Raízes de Merkle de intervalos da trilha de auditoria ancoradas em blockchain ou arquivo assinado';
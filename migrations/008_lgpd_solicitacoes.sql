@@ -0,0 +1,45 @@
+-- Migration: Solicitações de titulares (LGPD) vinculadas ao usuário e com prazo de resposta
+-- compliance_records passa a usar o ID inteiro de users e registra prazo, atendimento e pacote exportado
+
+CREATE TABLE IF NOT EXISTS compliance_records (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER,
+    compliance_type VARCHAR(50),
+    request_type VARCHAR(50),
+    status VARCHAR(20),
+    request_data JSONB,
+    legal_basis TEXT,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- Registros antigos referenciavam o titular por UUID, que não corresponde a users.id
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'compliance_records' AND column_name = 'user_id' AND data_type = 'uuid'
+    ) THEN
+        ALTER TABLE compliance_records ALTER COLUMN user_id TYPE INTEGER USING NULL;
+    END IF;
+END $$;
+
+ALTER TABLE compliance_records ADD COLUMN IF NOT EXISTS due_at TIMESTAMP;
+ALTER TABLE compliance_records ADD COLUMN IF NOT EXISTS processed_at TIMESTAMP;
+ALTER TABLE compliance_records ADD COLUMN IF NOT EXISTS processed_by_id INTEGER;
+ALTER TABLE compliance_records ADD COLUMN IF NOT EXISTS notes TEXT;
+ALTER TABLE compliance_records ADD COLUMN IF NOT EXISTS export_path VARCHAR(500);
+ALTER TABLE compliance_records ADD COLUMN IF NOT EXISTS export_format VARCHAR(10);
+ALTER TABLE compliance_records ADD COLUMN IF NOT EXISTS export_checksum VARCHAR(64);
+ALTER TABLE compliance_records ADD COLUMN IF NOT EXISTS export_signature VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_compliance_records_user_id ON compliance_records(user_id);
+CREATE INDEX IF NOT EXISTS idx_compliance_records_compliance_type ON compliance_records(compliance_type);
+CREATE INDEX IF NOT EXISTS idx_compliance_records_status ON compliance_records(status);
+CREATE INDEX IF NOT EXISTS idx_compliance_records_due_at ON compliance_records(due_at);
+CREATE INDEX IF NOT EXISTS idx_compliance_records_deleted_at ON compliance_records(deleted_at);
+
+COMMENT ON COLUMN compliance_records.due_at IS 'Prazo legal de resposta ao titular (LGPD Art. 19, II: 15 dias)';
+COMMENT ON COLUMN compliance_records.export_signature IS 'HMAC-SHA256 do manifesto do pacote de dados exportado';
@@ -0,0 +1,32 @@
+-- Migration: Consentimento por finalidade com texto de política versionado
+-- Cada aceite em compliance_records referencia a versão da política aceita; revogações desativam as funcionalidades dependentes
+
+CREATE TABLE IF NOT EXISTS politicas_consentimento (
+    id SERIAL PRIMARY KEY,
+    finalidade VARCHAR(50) NOT NULL,
+    versao INTEGER NOT NULL,
+    titulo VARCHAR(200) NOT NULL,
+    texto TEXT NOT NULL,
+    texto_hash VARCHAR(64) NOT NULL,
+    exige_novo_aceite BOOLEAN DEFAULT FALSE,
+    publicada_por_id INTEGER REFERENCES users(id),
+    vigente_desde TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_politica_finalidade_versao ON politicas_consentimento(finalidade, versao);
+
+ALTER TABLE compliance_records ADD COLUMN IF NOT EXISTS policy_version INTEGER DEFAULT 0;
+
+-- Consentimentos anteriores não registravam finalidade nem versão aceita e não podem ser reaproveitados
+UPDATE compliance_records
+SET status = 'superseded', updated_at = CURRENT_TIMESTAMP
+WHERE compliance_type = 'consent' AND status IN ('pending', 'approved')
+  AND request_type NOT IN ('marketing_email', 'perfil_social_publico', 'compartilhamento_terceiros', 'kyc_tokenizacao');
+
+CREATE INDEX IF NOT EXISTS idx_compliance_consent_user_purpose
+    ON compliance_records(user_id, request_type, status)
+    WHERE compliance_type = 'consent';
+
+COMMENT ON TABLE politicas_consentimento IS 'Versões dos textos de consentimento por finalidade (LGPD Art. 8)';
+COMMENT ON COLUMN compliance_records.policy_version IS 'Versão da política aceita no consentimento';
@@ -0,0 +1,21 @@
+-- Migration: Certificados X.509 emitidos pela CA interna
+-- Vincula o certificado ao equino, registra a origem da chave (CSR ou servidor), fingerprint e a cadeia de renovação
+
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS equinoid VARCHAR(25);
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS certificate_type VARCHAR(50);
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS key_origin VARCHAR(10);
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(64);
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS replaces_id INTEGER REFERENCES certificates(id);
+
+CREATE INDEX IF NOT EXISTS idx_certificates_equinoid ON certificates(equinoid);
+CREATE INDEX IF NOT EXISTS idx_certificates_user_valid ON certificates(user_id, common_name) WHERE is_revoked = FALSE;
+
+-- Certificados gerados antes da CA interna guardavam apenas um placeholder e não são verificáveis
+UPDATE certificates
+SET is_revoked = TRUE,
+    revoked_at = CURRENT_TIMESTAMP,
+    revocation_reason = 'superseded'
+WHERE certificate_pem = 'PEM_PLACEHOLDER' AND is_revoked = FALSE;
+
+COMMENT ON COLUMN certificates.key_origin IS 'csr: chave privada mantida pelo titular; server: chave gerada pelo servidor e armazenada criptografada';
+COMMENT ON COLUMN certificates.replaces_id IS 'Certificado substituído por esta renovação';
@@ -0,0 +1,19 @@
+-- Migration: Listas de certificados revogados (CRL) publicadas pela CA interna
+-- Cada publicação recebe número crescente; a de maior número é servida em /api/v1/pki/crl
+
+CREATE TABLE IF NOT EXISTS certificate_revocation_lists (
+    id SERIAL PRIMARY KEY,
+    number BIGINT NOT NULL,
+    this_update TIMESTAMP NOT NULL,
+    next_update TIMESTAMP NOT NULL,
+    revoked_count INTEGER NOT NULL DEFAULT 0,
+    crl_der BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_certificate_revocation_lists_number ON certificate_revocation_lists(number);
+
+-- Consultas OCSP buscam o status pelo número de série
+CREATE INDEX IF NOT EXISTS idx_certificates_revoked ON certificates(is_revoked, expires_at) WHERE is_revoked = TRUE;
+
+COMMENT ON TABLE certificate_revocation_lists IS 'CRLs X.509 assinadas pela CA interna (RFC 5280)';
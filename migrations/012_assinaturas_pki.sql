@@ -0,0 +1,54 @@
+-- Migration: Assinaturas de documentos pela PKI interna (Tier 2 e Tier 3)
+-- CAdES-BES destacada sobre o SHA-256 do documento e, para PDFs, PAdES embutida no arquivo
+
+CREATE TABLE IF NOT EXISTS digital_signatures (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    document_id UUID NOT NULL,
+    document_type VARCHAR(50),
+    tier VARCHAR(30),
+    format VARCHAR(10),
+    certificate_id INTEGER REFERENCES certificates(id),
+    certificate_serial VARCHAR(100),
+    signature TEXT NOT NULL,
+    signature_hash VARCHAR(64),
+    pdf_signature_hash VARCHAR(64),
+    document_hash VARCHAR(64),
+    algorithm VARCHAR(50),
+    timestamp TIMESTAMP NOT NULL,
+    location TEXT,
+    related_entity_id INTEGER,
+    related_entity_type VARCHAR(50),
+    biometric_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    biometric_score DOUBLE PRECISION,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_digital_signatures_document_id ON digital_signatures(document_id);
+CREATE INDEX IF NOT EXISTS idx_digital_signatures_user_id ON digital_signatures(user_id);
+CREATE INDEX IF NOT EXISTS idx_digital_signatures_certificate_id ON digital_signatures(certificate_id);
+CREATE INDEX IF NOT EXISTS idx_digital_signatures_document_hash ON digital_signatures(document_hash);
+-- A verificação associa a assinatura apresentada ao registro pelo hash da estrutura CMS
+CREATE INDEX IF NOT EXISTS idx_digital_signatures_signature_hash ON digital_signatures(signature_hash);
+CREATE INDEX IF NOT EXISTS idx_digital_signatures_pdf_signature_hash ON digital_signatures(pdf_signature_hash);
+CREATE INDEX IF NOT EXISTS idx_digital_signatures_deleted_at ON digital_signatures(deleted_at);
+
+-- Templates faciais (criptografados) usados no step-up biométrico das assinaturas Tier 2
+CREATE TABLE IF NOT EXISTS biometric_data (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    biometric_type VARCHAR(50),
+    biometric_template BYTEA,
+    quality DOUBLE PRECISION,
+    enrollment_date TIMESTAMP,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_biometric_data_user_id ON biometric_data(user_id);
+CREATE INDEX IF NOT EXISTS idx_biometric_data_deleted_at ON biometric_data(deleted_at);
+
+COMMENT ON TABLE digital_signatures IS 'Assinaturas CAdES/PAdES emitidas com certificados da CA interna';
@@ -0,0 +1,19 @@
+-- Migration: Carimbos de tempo RFC 3161 em assinaturas, eventos e laudos
+-- Os tokens são guardados em base64 e verificáveis sem o banco: autoridade e horário vêm do próprio token
+
+ALTER TABLE digital_signatures ADD COLUMN IF NOT EXISTS timestamp_token TEXT;
+ALTER TABLE digital_signatures ADD COLUMN IF NOT EXISTS timestamp_time TIMESTAMP;
+ALTER TABLE digital_signatures ADD COLUMN IF NOT EXISTS timestamp_authority VARCHAR(255);
+
+ALTER TABLE eventos ADD COLUMN IF NOT EXISTS carimbo_tempo TEXT;
+ALTER TABLE eventos ADD COLUMN IF NOT EXISTS carimbo_tempo_em TIMESTAMP;
+ALTER TABLE eventos ADD COLUMN IF NOT EXISTS carimbo_tempo_tsa VARCHAR(255);
+
+ALTER TABLE exames_laboratoriais ADD COLUMN IF NOT EXISTS laudo_hash VARCHAR(64);
+ALTER TABLE exames_laboratoriais ADD COLUMN IF NOT EXISTS laudo_carimbo_tempo TEXT;
+ALTER TABLE exames_laboratoriais ADD COLUMN IF NOT EXISTS laudo_carimbo_tempo_em TIMESTAMP;
+ALTER TABLE exames_laboratoriais ADD COLUMN IF NOT EXISTS laudo_carimbo_tempo_tsa VARCHAR(255);
+
+COMMENT ON COLUMN digital_signatures.timestamp_token IS 'TimeStampToken RFC 3161 sobre o valor da assinatura (CAdES-T)';
+COMMENT ON COLUMN eventos.carimbo_tempo IS 'TimeStampToken RFC 3161 sobre o SHA-256 da assinatura digital do evento';
+COMMENT ON COLUMN exames_laboratoriais.laudo_carimbo_tempo IS 'TimeStampToken RFC 3161 sobre laudo_hash';
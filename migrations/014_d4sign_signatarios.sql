@@ -0,0 +1,50 @@
+-- Migration: Contratos D4Sign com vários signatários em ordem
+-- Status por signatário vindo de webhooks e da reconciliação periódica; PDF final guardado localmente
+
+CREATE TABLE IF NOT EXISTS d4sign_documents (
+    id SERIAL PRIMARY KEY,
+    document_uuid VARCHAR(255) NOT NULL,
+    safe_uuid VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) DEFAULT 'pending',
+    document_type VARCHAR(50) NOT NULL,
+    related_entity_id INTEGER,
+    related_entity_type VARCHAR(50),
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    signed_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+ALTER TABLE d4sign_documents ADD COLUMN IF NOT EXISTS sequential_signing BOOLEAN DEFAULT FALSE;
+ALTER TABLE d4sign_documents ADD COLUMN IF NOT EXISTS signed_file_path VARCHAR(500);
+ALTER TABLE d4sign_documents ADD COLUMN IF NOT EXISTS signed_file_hash VARCHAR(64);
+ALTER TABLE d4sign_documents ADD COLUMN IF NOT EXISTS last_synced_at TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_d4sign_documents_document_uuid ON d4sign_documents(document_uuid);
+CREATE INDEX IF NOT EXISTS idx_d4sign_documents_deleted_at ON d4sign_documents(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_d4sign_documents_status ON d4sign_documents(status, last_synced_at);
+
+CREATE TABLE IF NOT EXISTS d4sign_document_signers (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES d4sign_documents(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    role VARCHAR(30) NOT NULL,
+    signing_order INTEGER NOT NULL,
+    key_signer VARCHAR(100),
+    status VARCHAR(20) DEFAULT 'pending',
+    signed_at TIMESTAMP,
+    rejected_at TIMESTAMP,
+    last_reminder_at TIMESTAMP,
+    reminder_count INTEGER DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_d4sign_document_signers_document_id ON d4sign_document_signers(document_id);
+CREATE INDEX IF NOT EXISTS idx_d4sign_document_signers_key_signer ON d4sign_document_signers(key_signer);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_d4sign_document_signers_order ON d4sign_document_signers(document_id, signing_order);
+
+COMMENT ON TABLE d4sign_document_signers IS 'Signatários de documentos D4Sign na ordem de assinatura, com status e lembretes';
@@ -0,0 +1,30 @@
+-- Migration: Webhooks D4Sign autenticados e idempotentes
+-- Log de todo webhook recebido e registro único de eventos já processados
+
+CREATE TABLE IF NOT EXISTS d4sign_webhook_logs (
+    id SERIAL PRIMARY KEY,
+    event_id VARCHAR(100),
+    document_uuid VARCHAR(255),
+    event VARCHAR(50),
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    remote_ip VARCHAR(64),
+    payload TEXT,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_d4sign_webhook_logs_event_id ON d4sign_webhook_logs(event_id);
+CREATE INDEX IF NOT EXISTS idx_d4sign_webhook_logs_document_uuid ON d4sign_webhook_logs(document_uuid);
+CREATE INDEX IF NOT EXISTS idx_d4sign_webhook_logs_status ON d4sign_webhook_logs(status);
+CREATE INDEX IF NOT EXISTS idx_d4sign_webhook_logs_received_at ON d4sign_webhook_logs(received_at);
+
+CREATE TABLE IF NOT EXISTS d4sign_webhook_events (
+    id SERIAL PRIMARY KEY,
+    event_id VARCHAR(100) NOT NULL,
+    document_uuid VARCHAR(255),
+    event VARCHAR(50),
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_d4sign_webhook_events_event_id ON d4sign_webhook_events(event_id);
+CREATE INDEX IF NOT EXISTS idx_d4sign_webhook_events_document_uuid ON d4sign_webhook_events(document_uuid);
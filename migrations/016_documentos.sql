@@ -0,0 +1,45 @@
+-- Migration: Cofre de documentos dos equinos
+-- Arquivos versionados em armazenamento local ou S3, com hash, tipo identificado pelo conteúdo e miniatura de imagens
+
+CREATE TABLE IF NOT EXISTS documentos (
+    id SERIAL PRIMARY KEY,
+    uuid VARCHAR(36) NOT NULL,
+    equino_id INTEGER NOT NULL REFERENCES equinos(id),
+    equinoid VARCHAR(24) NOT NULL,
+    categoria VARCHAR(30) NOT NULL,
+    titulo VARCHAR(255) NOT NULL,
+    descricao TEXT,
+    visibilidade VARCHAR(20) NOT NULL DEFAULT 'privado',
+    entidade_tipo VARCHAR(30),
+    entidade_id INTEGER,
+    versao_atual INTEGER NOT NULL DEFAULT 1,
+    criado_por INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_documentos_uuid ON documentos(uuid);
+CREATE INDEX IF NOT EXISTS idx_documentos_equino_id ON documentos(equino_id);
+CREATE INDEX IF NOT EXISTS idx_documentos_equinoid ON documentos(equinoid);
+CREATE INDEX IF NOT EXISTS idx_documentos_categoria ON documentos(categoria);
+CREATE INDEX IF NOT EXISTS idx_documentos_entidade ON documentos(entidade_tipo, entidade_id);
+CREATE INDEX IF NOT EXISTS idx_documentos_deleted_at ON documentos(deleted_at);
+
+CREATE TABLE IF NOT EXISTS documento_versoes (
+    id SERIAL PRIMARY KEY,
+    documento_id INTEGER NOT NULL REFERENCES documentos(id) ON DELETE CASCADE,
+    numero INTEGER NOT NULL,
+    nome_arquivo VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    tamanho BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    storage_backend VARCHAR(20) NOT NULL,
+    storage_key VARCHAR(500) NOT NULL,
+    thumbnail_key VARCHAR(500),
+    enviado_por INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_documento_versao ON documento_versoes(documento_id, numero);
+CREATE INDEX IF NOT EXISTS idx_documento_versoes_hash ON documento_versoes(hash);
@@ -0,0 +1,33 @@
+-- Migration: Passaportes equinos
+-- PDF com identificação, pedigree e resumo vacinal assinado com o selo institucional; verificação pública pelo QR code
+
+ALTER TABLE equinos ADD COLUMN IF NOT EXISTS resenha TEXT;
+
+CREATE TABLE IF NOT EXISTS passaportes_equinos (
+    id SERIAL PRIMARY KEY,
+    codigo VARCHAR(36) NOT NULL,
+    equino_id INTEGER NOT NULL REFERENCES equinos(id),
+    equinoid VARCHAR(24) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'valido',
+    hash VARCHAR(64) NOT NULL,
+    signature_hash VARCHAR(64) NOT NULL,
+    certificado_serial VARCHAR(64),
+    tamanho BIGINT NOT NULL,
+    storage_backend VARCHAR(20) NOT NULL,
+    storage_key VARCHAR(500) NOT NULL,
+    url_verificacao VARCHAR(500) NOT NULL,
+    emitido_por INTEGER NOT NULL REFERENCES users(id),
+    emitido_em TIMESTAMP NOT NULL,
+    revogado_em TIMESTAMP,
+    revogado_por INTEGER REFERENCES users(id),
+    motivo_revogacao TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_passaportes_equinos_codigo ON passaportes_equinos(codigo);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_passaportes_equinos_hash ON passaportes_equinos(hash);
+CREATE INDEX IF NOT EXISTS idx_passaportes_equinos_signature_hash ON passaportes_equinos(signature_hash);
+CREATE INDEX IF NOT EXISTS idx_passaportes_equinos_equino_id ON passaportes_equinos(equino_id);
+CREATE INDEX IF NOT EXISTS idx_passaportes_equinos_equinoid ON passaportes_equinos(equinoid);
+CREATE INDEX IF NOT EXISTS idx_passaportes_equinos_status ON passaportes_equinos(status);
@@ -0,0 +1,46 @@
+-- Migration: EquinoId v2 e identificadores alternativos
+-- Novos equinos recebem EquinoId v2 (caracteres de verificação ISO 7064 MOD 1271-36); os já registrados mantêm o
+-- EquinoId original e ganham um alias v2. UELN e números de stud book também localizam o equino
+
+CREATE TABLE IF NOT EXISTS equino_identificadores (
+    id SERIAL PRIMARY KEY,
+    equino_id INTEGER NOT NULL REFERENCES equinos(id),
+    tipo VARCHAR(30) NOT NULL,
+    emissor VARCHAR(100) NOT NULL DEFAULT '',
+    valor VARCHAR(64) NOT NULL,
+    criado_por INTEGER REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_equino_identificador_valor ON equino_identificadores(tipo, emissor, valor);
+CREATE INDEX IF NOT EXISTS idx_equino_identificadores_equino_id ON equino_identificadores(equino_id);
+
+-- Mesmo cálculo de utils.calculateISO7064Mod1271_36
+CREATE OR REPLACE FUNCTION equinoid_iso7064_mod1271_36(dados TEXT) RETURNS TEXT AS $$
+DECLARE
+    alfabeto CONSTANT TEXT := '0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ';
+    p INTEGER := 0;
+    verificador INTEGER;
+BEGIN
+    FOR i IN 1..length(dados) LOOP
+        p := ((p + position(substr(dados, i, 1) IN alfabeto) - 1) * 36) % 1271;
+    END LOOP;
+    p := (p * 36) % 1271;
+    verificador := (1271 + 1 - p) % 1271;
+    RETURN substr(alfabeto, verificador / 36 + 1, 1) || substr(alfabeto, verificador % 36 + 1, 1);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Alias v2 dos equinos existentes, igual a utils.EquinoIdV2FromLegacy
+INSERT INTO equino_identificadores (equino_id, tipo, emissor, valor, created_at)
+SELECT base.id, 'equinoid_v2', '', base.prefixo || equinoid_iso7064_mod1271_36(base.prefixo), CURRENT_TIMESTAMP
+FROM (
+    SELECT
+        id,
+        'EQ2'
+            || CASE WHEN upper(pais_origem) ~ '^[A-Z]{3}$' THEN upper(pais_origem) ELSE 'XXX' END
+            || upper(substr(encode(sha256(convert_to(equinoid, 'UTF8')), 'hex'), 1, 16)) AS prefixo
+    FROM equinos
+    WHERE equinoid NOT LIKE 'EQ2%'
+) AS base
+ON CONFLICT (tipo, emissor, valor) DO NOTHING;
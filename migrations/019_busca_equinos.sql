@@ -0,0 +1,18 @@
+-- Migration: Busca de equinos
+-- Texto completo sem acentos e busca aproximada por trigramas sobre nome, identificadores, microchip e proprietário
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() não é IMMUTABLE e não pode ser usada em índices; o dicionário fixo torna o resultado determinístico
+CREATE OR REPLACE FUNCTION equinoid_unaccent(texto TEXT) RETURNS TEXT AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, texto)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+CREATE INDEX IF NOT EXISTS idx_equinos_nome_fts ON equinos USING GIN (to_tsvector('simple', equinoid_unaccent(lower(nome))));
+CREATE INDEX IF NOT EXISTS idx_equinos_nome_trgm ON equinos USING GIN (equinoid_unaccent(lower(nome)) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_equinos_equinoid_prefixo ON equinos (equinoid text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_equinos_microchip_prefixo ON equinos (microchip_id text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_equinos_created_at_id ON equinos (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_equino_identificadores_valor_prefixo ON equino_identificadores (valor text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING GIN (equinoid_unaccent(lower(name)) gin_trgm_ops);
@@ -0,0 +1,37 @@
+-- Migration: Rede social dos equinos
+-- Seguidores com aprovação para perfis privados, interações idempotentes e contadores recalculados a partir das tabelas de origem
+
+ALTER TABLE seguir_equinos
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'ativo',
+    ADD COLUMN IF NOT EXISTS aprovado_em TIMESTAMP;
+
+ALTER TABLE seguir_equinos
+    ADD CONSTRAINT chk_seguir_equinos_status CHECK (status IN ('ativo', 'pendente'));
+
+-- Deixar de seguir e descurtir passam a excluir a linha; as exclusões lógicas antigas e duplicatas impediriam os índices únicos
+DELETE FROM seguir_equinos WHERE deleted_at IS NOT NULL;
+DELETE FROM seguir_equinos a USING seguir_equinos b
+    WHERE a.user_id = b.user_id AND a.equinoid = b.equinoid AND a.id > b.id;
+
+DELETE FROM interacao_socials WHERE deleted_at IS NOT NULL;
+DELETE FROM interacao_socials a USING interacao_socials b
+    WHERE a.post_id = b.post_id AND a.user_id = b.user_id AND a.tipo_interacao = b.tipo_interacao AND a.id > b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_seguir_equinos_user_equinoid ON seguir_equinos(user_id, equinoid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_interacao_socials_post_user_tipo ON interacao_socials(post_id, user_id, tipo_interacao);
+CREATE INDEX IF NOT EXISTS idx_seguir_equinos_equinoid_status ON seguir_equinos(equinoid, status);
+CREATE INDEX IF NOT EXISTS idx_post_socials_feed ON post_socials(equinoid, status_post, data_postagem DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_comentario_socials_post_parent ON comentario_socials(post_id, parent_id);
+
+-- Contadores mantidos até aqui de forma avulsa
+UPDATE post_socials p SET
+    total_curtidas = (SELECT COUNT(*) FROM interacao_socials i WHERE i.post_id = p.id AND i.tipo_interacao = 'curtida'),
+    total_compartilhamentos = (SELECT COUNT(*) FROM interacao_socials i WHERE i.post_id = p.id AND i.tipo_interacao = 'compartilhamento'),
+    total_comentarios = (SELECT COUNT(*) FROM comentario_socials c WHERE c.post_id = p.id AND c.deleted_at IS NULL);
+
+UPDATE perfil_socials ps SET
+    total_seguidores = (SELECT COUNT(*) FROM seguir_equinos s WHERE s.equinoid = ps.equinoid AND s.status = 'ativo'),
+    total_posts = (SELECT COUNT(*) FROM post_socials p WHERE p.perfil_social_id = ps.id AND p.status_post = 'ativo' AND p.deleted_at IS NULL),
+    total_curtidas = (SELECT COALESCE(SUM(p.total_curtidas), 0) FROM post_socials p WHERE p.perfil_social_id = ps.id AND p.status_post = 'ativo' AND p.deleted_at IS NULL),
+    total_comentarios = (SELECT COALESCE(SUM(p.total_comentarios), 0) FROM post_socials p WHERE p.perfil_social_id = ps.id AND p.status_post = 'ativo' AND p.deleted_at IS NULL),
+    total_compartilhamentos = (SELECT COALESCE(SUM(p.total_compartilhamentos), 0) FROM post_socials p WHERE p.perfil_social_id = ps.id AND p.status_post = 'ativo' AND p.deleted_at IS NULL);
@@ -0,0 +1,94 @@
+-- Migration: Moderação da rede social
+-- Denúncias, fila de moderação, filtros configuráveis aplicados antes da publicação, infrações para o limite de
+-- reincidência e recursos contra retiradas de conteúdo
+
+-- Posts e comentários ocultos (shadow-hide) ou retirados (takedown) pela moderação
+ALTER TABLE comentario_socials
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'ativo';
+
+CREATE INDEX IF NOT EXISTS idx_comentario_socials_user_data ON comentario_socials(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_post_socials_criado_por_data ON post_socials(criado_por, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS moderacao_regras (
+    id SERIAL PRIMARY KEY,
+    tipo VARCHAR(20) NOT NULL CHECK (tipo IN ('palavra_chave', 'regex', 'link')),
+    padrao VARCHAR(500) NOT NULL,
+    acao VARCHAR(20) NOT NULL CHECK (acao IN ('bloquear', 'ocultar', 'revisar')),
+    descricao VARCHAR(255),
+    ativa BOOLEAN NOT NULL DEFAULT TRUE,
+    criado_por INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderacao_regras_ativa ON moderacao_regras(ativa);
+CREATE INDEX IF NOT EXISTS idx_moderacao_regras_deleted_at ON moderacao_regras(deleted_at);
+
+CREATE TABLE IF NOT EXISTS moderacao_casos (
+    id SERIAL PRIMARY KEY,
+    conteudo_tipo VARCHAR(20) NOT NULL CHECK (conteudo_tipo IN ('post', 'comentario')),
+    conteudo_id INTEGER NOT NULL,
+    autor_id INTEGER NOT NULL REFERENCES users(id),
+    origem VARCHAR(20) NOT NULL CHECK (origem IN ('denuncia', 'filtro')),
+    regras_acionadas JSONB,
+    total_denuncias INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pendente' CHECK (status IN ('pendente', 'resolvido', 'revertido')),
+    decisao VARCHAR(20) CHECK (decisao IN ('manter', 'ocultar', 'retirar')),
+    motivo_decisao TEXT,
+    moderador_id INTEGER REFERENCES users(id),
+    decidido_em TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Um único caso pendente por conteúdo: denúncias simultâneas caem no mesmo caso
+CREATE UNIQUE INDEX IF NOT EXISTS idx_moderacao_casos_aberto ON moderacao_casos(conteudo_tipo, conteudo_id) WHERE status = 'pendente';
+CREATE INDEX IF NOT EXISTS idx_moderacao_casos_fila ON moderacao_casos(status, total_denuncias DESC, created_at);
+CREATE INDEX IF NOT EXISTS idx_moderacao_casos_autor_id ON moderacao_casos(autor_id);
+
+CREATE TABLE IF NOT EXISTS moderacao_denuncias (
+    id SERIAL PRIMARY KEY,
+    caso_id INTEGER NOT NULL REFERENCES moderacao_casos(id) ON DELETE CASCADE,
+    denunciante_id INTEGER NOT NULL REFERENCES users(id),
+    motivo VARCHAR(30) NOT NULL CHECK (motivo IN ('spam', 'abuso', 'fraude', 'conteudo_improprio', 'outro')),
+    descricao TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_moderacao_denuncias_caso_denunciante ON moderacao_denuncias(caso_id, denunciante_id);
+
+CREATE TABLE IF NOT EXISTS moderacao_recursos (
+    id SERIAL PRIMARY KEY,
+    caso_id INTEGER NOT NULL REFERENCES moderacao_casos(id) ON DELETE CASCADE,
+    autor_id INTEGER NOT NULL REFERENCES users(id),
+    justificativa TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pendente' CHECK (status IN ('pendente', 'deferido', 'indeferido')),
+    revisor_id INTEGER REFERENCES users(id),
+    resposta TEXT,
+    decidido_em TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_moderacao_recursos_caso_id ON moderacao_recursos(caso_id);
+CREATE INDEX IF NOT EXISTS idx_moderacao_recursos_status ON moderacao_recursos(status);
+
+CREATE TABLE IF NOT EXISTS moderacao_infracoes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    caso_id INTEGER REFERENCES moderacao_casos(id) ON DELETE SET NULL,
+    regra_id INTEGER REFERENCES moderacao_regras(id),
+    conteudo_tipo VARCHAR(20) NOT NULL,
+    conteudo_id INTEGER,
+    motivo VARCHAR(255),
+    anulada BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderacao_infracoes_user_data ON moderacao_infracoes(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_moderacao_infracoes_caso_id ON moderacao_infracoes(caso_id);
+
+-- Comentários passam a contar apenas quando ativos
+UPDATE post_socials p SET
+    total_comentarios = (SELECT COUNT(*) FROM comentario_socials c WHERE c.post_id = p.id AND c.status = 'ativo' AND c.deleted_at IS NULL);
@@ -0,0 +1,52 @@
+-- Migration: Negociação de ofertas
+-- Contrapropostas encadeadas na mesma negociação, expiração, contrato do negócio aceito com transferência de
+-- propriedade na assinatura e notificações internas às partes
+
+ALTER TABLE oferta
+    ADD COLUMN IF NOT EXISTS negociacao_id INTEGER,
+    ADD COLUMN IF NOT EXISTS oferta_anterior_id INTEGER REFERENCES oferta(id),
+    ADD COLUMN IF NOT EXISTS rodada INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS comprador_id INTEGER REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS proprietario_id INTEGER REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS contrato_uuid VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS concluida_em TIMESTAMP;
+
+-- Ofertas anteriores viram negociações de uma única proposta feita pelo interessado
+UPDATE oferta o SET
+    negociacao_id = COALESCE(o.negociacao_id, o.id),
+    comprador_id = COALESCE(o.comprador_id, o.ofertante_por_id),
+    proprietario_id = COALESCE(o.proprietario_id, (SELECT e.proprietario_id FROM equinos e WHERE e.equinoid = o.equinoid));
+
+UPDATE oferta SET status_oferta = 'pendente' WHERE status_oferta IS NULL OR status_oferta = '';
+
+ALTER TABLE oferta
+    ALTER COLUMN comprador_id SET NOT NULL,
+    ALTER COLUMN proprietario_id SET NOT NULL;
+
+ALTER TABLE oferta
+    ADD CONSTRAINT chk_oferta_status CHECK (status_oferta IN ('pendente', 'contraproposta', 'aceita', 'recusada', 'expirada', 'cancelada', 'concluida'));
+
+CREATE INDEX IF NOT EXISTS idx_oferta_negociacao_id ON oferta(negociacao_id);
+CREATE INDEX IF NOT EXISTS idx_oferta_equinoid_status ON oferta(equinoid, status_oferta);
+CREATE INDEX IF NOT EXISTS idx_oferta_comprador_id ON oferta(comprador_id);
+CREATE INDEX IF NOT EXISTS idx_oferta_proprietario_id ON oferta(proprietario_id);
+CREATE INDEX IF NOT EXISTS idx_oferta_contrato_uuid ON oferta(contrato_uuid);
+CREATE INDEX IF NOT EXISTS idx_oferta_prazo_pendentes ON oferta(prazo_oferta) WHERE status_oferta = 'pendente';
+
+-- Uma única venda acertada por equino enquanto o contrato não é concluído
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oferta_compra_aceita ON oferta(equinoid) WHERE status_oferta = 'aceita' AND tipo_oferta = 'compra' AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notificacoes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tipo VARCHAR(40) NOT NULL,
+    titulo VARCHAR(150) NOT NULL,
+    mensagem TEXT,
+    referencia_tipo VARCHAR(40),
+    referencia_id INTEGER,
+    lida_em TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notificacoes_user_lida ON notificacoes(user_id, lida_em);
+CREATE INDEX IF NOT EXISTS idx_notificacoes_user_data ON notificacoes(user_id, created_at DESC);
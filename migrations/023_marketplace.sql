@@ -0,0 +1,84 @@
+-- Migration: Marketplace
+-- Ciclo de vida dos anúncios (rascunho, ativo, pausado, vendido, expirado), galeria de fotos no armazenamento de
+-- arquivos, favoritos e avaliação do vendedor pelo comprador depois da venda
+
+ALTER TABLE marketplace.anuncios
+    ADD COLUMN IF NOT EXISTS quantidade INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS avaliacao_semen_id INTEGER,
+    ADD COLUMN IF NOT EXISTS estado VARCHAR(2),
+    ADD COLUMN IF NOT EXISTS cidade VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS publicado_em TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS expira_em TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS vendido_em TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS comprador_id INTEGER REFERENCES users.users(id),
+    ADD COLUMN IF NOT EXISTS total_favoritos INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Anúncios anteriores ao ciclo de vida já estavam no ar: passam a valer 60 dias a partir de agora
+UPDATE marketplace.anuncios SET status = 'ativo' WHERE status IS NULL OR status = '';
+UPDATE marketplace.anuncios SET
+    publicado_em = COALESCE(publicado_em, created_at),
+    expira_em = COALESCE(expira_em, NOW() + INTERVAL '60 days')
+WHERE status IN ('ativo', 'pausado');
+
+ALTER TABLE marketplace.anuncios
+    ALTER COLUMN status SET DEFAULT 'rascunho',
+    ALTER COLUMN status SET NOT NULL;
+
+ALTER TABLE marketplace.anuncios
+    ADD CONSTRAINT chk_marketplace_anuncios_tipo CHECK (tipo IN ('animal', 'semen', 'embrio', 'equipamento')),
+    ADD CONSTRAINT chk_marketplace_anuncios_status CHECK (status IN ('rascunho', 'ativo', 'pausado', 'vendido', 'expirado')),
+    ADD CONSTRAINT chk_marketplace_anuncios_quantidade CHECK (quantidade >= 1);
+
+COMMENT ON COLUMN marketplace.anuncios.fotos IS 'Obsoleta: as fotos ficam em marketplace.fotos';
+
+CREATE INDEX IF NOT EXISTS idx_marketplace_anuncios_status_tipo ON marketplace.anuncios(status, tipo) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_marketplace_anuncios_usuario_id ON marketplace.anuncios(usuario_id);
+CREATE INDEX IF NOT EXISTS idx_marketplace_anuncios_equinoid ON marketplace.anuncios(equinoid);
+CREATE INDEX IF NOT EXISTS idx_marketplace_anuncios_comprador_id ON marketplace.anuncios(comprador_id);
+CREATE INDEX IF NOT EXISTS idx_marketplace_anuncios_deleted_at ON marketplace.anuncios(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_marketplace_anuncios_expira_em ON marketplace.anuncios(expira_em)
+    WHERE status IN ('ativo', 'pausado') AND deleted_at IS NULL;
+
+-- Um único anúncio de venda no ar por animal
+CREATE UNIQUE INDEX IF NOT EXISTS idx_marketplace_anuncios_animal_em_venda ON marketplace.anuncios(equinoid)
+    WHERE tipo = 'animal' AND status IN ('ativo', 'pausado') AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS marketplace.fotos (
+    id SERIAL PRIMARY KEY,
+    anuncio_id INTEGER NOT NULL REFERENCES marketplace.anuncios(id) ON DELETE CASCADE,
+    storage_key VARCHAR(500) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    tamanho BIGINT,
+    ordem INTEGER NOT NULL DEFAULT 0,
+    enviado_por INTEGER NOT NULL REFERENCES users.users(id),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_marketplace_fotos_anuncio_id ON marketplace.fotos(anuncio_id);
+
+CREATE TABLE IF NOT EXISTS marketplace.favoritos (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES users.users(id),
+    anuncio_id INTEGER NOT NULL REFERENCES marketplace.anuncios(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_marketplace_favorito ON marketplace.favoritos(usuario_id, anuncio_id);
+CREATE INDEX IF NOT EXISTS idx_marketplace_favoritos_anuncio_id ON marketplace.favoritos(anuncio_id);
+
+CREATE TABLE IF NOT EXISTS marketplace.avaliacoes_vendedor (
+    id SERIAL PRIMARY KEY,
+    anuncio_id INTEGER NOT NULL REFERENCES marketplace.anuncios(id),
+    vendedor_id INTEGER NOT NULL REFERENCES users.users(id),
+    comprador_id INTEGER NOT NULL REFERENCES users.users(id),
+    nota INTEGER NOT NULL CHECK (nota BETWEEN 1 AND 5),
+    comentario TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_marketplace_avaliacoes_vendedor_anuncio_id ON marketplace.avaliacoes_vendedor(anuncio_id);
+CREATE INDEX IF NOT EXISTS idx_marketplace_avaliacoes_vendedor_vendedor_id ON marketplace.avaliacoes_vendedor(vendedor_id);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA marketplace TO equinoid_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA marketplace TO equinoid_app;
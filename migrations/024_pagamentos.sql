@@ -0,0 +1,84 @@
+-- Migration: Pagamentos
+-- Cobrança das vendas de leilão e marketplace (PIX, boleto ou cartão) com o valor em custódia até a transferência de
+-- propriedade ou a confirmação de recebimento, repasses ao vendedor, leiloeiro e plataforma, estornos e lançamentos
+-- conciliados em transacoes_financeiras
+
+CREATE TABLE IF NOT EXISTS pagamentos (
+    id SERIAL PRIMARY KEY,
+    origem VARCHAR(20) NOT NULL CHECK (origem IN ('leilao', 'marketplace')),
+    origem_id INTEGER NOT NULL,
+    comprador_id INTEGER NOT NULL REFERENCES users(id),
+    vendedor_id INTEGER NOT NULL REFERENCES users(id),
+    leiloeiro_id INTEGER REFERENCES users(id),
+    equino_id INTEGER,
+    equinoid VARCHAR(25),
+    exige_transferencia BOOLEAN NOT NULL DEFAULT FALSE,
+    descricao VARCHAR(500) NOT NULL,
+    valor DECIMAL(15,2) NOT NULL CHECK (valor > 0),
+    comissao_leiloeiro DECIMAL(15,2) NOT NULL DEFAULT 0,
+    taxa_plataforma DECIMAL(15,2) NOT NULL DEFAULT 0,
+    valor_vendedor DECIMAL(15,2) NOT NULL,
+    metodo VARCHAR(20) NOT NULL CHECK (metodo IN ('pix', 'boleto', 'cartao')),
+    parcelas INTEGER NOT NULL DEFAULT 1,
+    provedor VARCHAR(30) NOT NULL,
+    cobranca_id VARCHAR(100),
+    pix_copia_e_cola TEXT,
+    boleto_linha VARCHAR(100),
+    boleto_url VARCHAR(500),
+    status VARCHAR(30) NOT NULL DEFAULT 'aguardando_pagamento' CHECK (status IN ('aguardando_pagamento', 'em_custodia', 'em_liberacao', 'liberado', 'estornado', 'expirado', 'recusado')),
+    motivo_falha VARCHAR(500),
+    vence_em TIMESTAMP,
+    pago_em TIMESTAMP,
+    liberar_ate TIMESTAMP,
+    liberado_em TIMESTAMP,
+    estornado_em TIMESTAMP,
+    estorno_id VARCHAR(100),
+    motivo_estorno TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pagamentos_origem ON pagamentos(origem, origem_id);
+CREATE INDEX IF NOT EXISTS idx_pagamentos_comprador_id ON pagamentos(comprador_id);
+CREATE INDEX IF NOT EXISTS idx_pagamentos_vendedor_id ON pagamentos(vendedor_id);
+CREATE INDEX IF NOT EXISTS idx_pagamentos_leiloeiro_id ON pagamentos(leiloeiro_id);
+CREATE INDEX IF NOT EXISTS idx_pagamentos_equinoid ON pagamentos(equinoid);
+CREATE INDEX IF NOT EXISTS idx_pagamentos_status ON pagamentos(status);
+CREATE INDEX IF NOT EXISTS idx_pagamentos_liberar_ate ON pagamentos(liberar_ate) WHERE status = 'em_custodia';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pagamentos_cobranca_id ON pagamentos(cobranca_id);
+
+-- Uma única cobrança em andamento ou concluída por venda
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pagamentos_venda_aberta ON pagamentos(origem, origem_id) WHERE status IN ('aguardando_pagamento', 'em_custodia', 'em_liberacao', 'liberado');
+
+CREATE TABLE IF NOT EXISTS pagamentos_repasses (
+    id SERIAL PRIMARY KEY,
+    pagamento_id INTEGER NOT NULL REFERENCES pagamentos(id) ON DELETE CASCADE,
+    beneficiario VARCHAR(20) NOT NULL CHECK (beneficiario IN ('vendedor', 'leiloeiro', 'plataforma')),
+    usuario_id INTEGER REFERENCES users(id),
+    valor DECIMAL(15,2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pendente' CHECK (status IN ('pendente', 'pago', 'falhou')),
+    repasse_id VARCHAR(100),
+    tentativas INTEGER NOT NULL DEFAULT 0,
+    ultimo_erro VARCHAR(500),
+    enviado_em TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pagamentos_repasses_pagamento_id ON pagamentos_repasses(pagamento_id);
+CREATE INDEX IF NOT EXISTS idx_pagamentos_repasses_usuario_id ON pagamentos_repasses(usuario_id);
+
+-- Lançamentos gerados pelos pagamentos identificados pela referência, para a conciliação regravar sem duplicar
+ALTER TABLE IF EXISTS transacoes_financeiras
+    ADD COLUMN IF NOT EXISTS usuario_id INTEGER REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS pagamento_id INTEGER REFERENCES pagamentos(id),
+    ADD COLUMN IF NOT EXISTS referencia VARCHAR(100);
+
+DO $$
+BEGIN
+    IF to_regclass('transacoes_financeiras') IS NOT NULL THEN
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_transacoes_financeiras_referencia ON transacoes_financeiras(referencia);
+        CREATE INDEX IF NOT EXISTS idx_transacoes_financeiras_pagamento_id ON transacoes_financeiras(pagamento_id);
+        CREATE INDEX IF NOT EXISTS idx_transacoes_financeiras_usuario_id ON transacoes_financeiras(usuario_id);
+    END IF;
+END $$;
@@ -0,0 +1,46 @@
+-- Migration: Catálogo de leilões
+-- Número de lote e sessão de cada participação aprovada e versões publicadas do catálogo (conteúdo congelado em JSON e
+-- PDF no armazenamento de documentos)
+
+ALTER TABLE IF EXISTS leilaos
+    ADD COLUMN IF NOT EXISTS publicado_em TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS versao_catalogo INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE IF EXISTS participacoes_leiloes
+    ADD COLUMN IF NOT EXISTS numero_lote INTEGER,
+    ADD COLUMN IF NOT EXISTS sessao INTEGER;
+
+DO $$
+BEGIN
+    IF to_regclass('participacoes_leiloes') IS NOT NULL THEN
+        CREATE INDEX IF NOT EXISTS idx_participacoes_leiloes_numero_lote ON participacoes_leiloes(numero_lote);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS leiloes_sessoes (
+    id SERIAL PRIMARY KEY,
+    leilao_id INTEGER NOT NULL,
+    numero INTEGER NOT NULL CHECK (numero > 0),
+    titulo VARCHAR(100),
+    inicio_em TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leiloes_sessoes_numero ON leiloes_sessoes(leilao_id, numero);
+
+CREATE TABLE IF NOT EXISTS leiloes_catalogos (
+    id SERIAL PRIMARY KEY,
+    leilao_id INTEGER NOT NULL,
+    versao INTEGER NOT NULL CHECK (versao > 0),
+    motivo VARCHAR(500),
+    lotes INTEGER NOT NULL,
+    conteudo JSONB NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    tamanho BIGINT NOT NULL,
+    storage_backend VARCHAR(20) NOT NULL,
+    storage_key VARCHAR(500) NOT NULL,
+    publicado_por INTEGER NOT NULL REFERENCES users(id),
+    publicado_em TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leiloes_catalogos_versao ON leiloes_catalogos(leilao_id, versao);
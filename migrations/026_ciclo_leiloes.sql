@@ -0,0 +1,16 @@
+-- Migration: Ciclo de vida dos leilões
+-- Datas de início e encerramento efetivos e motivo de cancelamento. As transições agendado → em_andamento →
+-- encerrado acontecem nas datas do leilão; o encerramento grava os totais de vendas e comissões
+
+ALTER TABLE IF EXISTS leilaos
+    ADD COLUMN IF NOT EXISTS iniciado_em TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS encerrado_em TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS motivo_cancelamento VARCHAR(500);
+
+DO $$
+BEGIN
+    IF to_regclass('leilaos') IS NOT NULL THEN
+        CREATE INDEX IF NOT EXISTS idx_leilaos_status_data_inicio ON leilaos(status, data_inicio);
+        CREATE INDEX IF NOT EXISTS idx_leilaos_status_data_fim ON leilaos(status, data_fim);
+    END IF;
+END $$;
@@ -0,0 +1,44 @@
+-- Migration: Habilitação de arrematantes
+-- Cadastro do comprador por leilão com documentos, limite de crédito ou caução aprovados pelo leiloeiro e número de
+-- paleta usado no pregão. Vendas só são registradas para arrematantes aprovados e dentro do limite
+
+CREATE TABLE IF NOT EXISTS leiloes_habilitacoes (
+    id SERIAL PRIMARY KEY,
+    leilao_id INTEGER NOT NULL,
+    usuario_id INTEGER NOT NULL REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'pendente' CHECK (status IN ('pendente', 'aguardando_caucao', 'aprovada', 'rejeitada', 'cancelada')),
+    limite_solicitado DECIMAL(15,2) NOT NULL CHECK (limite_solicitado > 0),
+    observacoes TEXT,
+    garantia VARCHAR(20) CHECK (garantia IN ('limite_credito', 'caucao')),
+    limite_aprovado DECIMAL(15,2),
+    valor_caucao DECIMAL(15,2),
+    caucao_referencia VARCHAR(100),
+    caucao_confirmada_em TIMESTAMP,
+    paleta INTEGER,
+    motivo_rejeicao VARCHAR(500),
+    analisado_por INTEGER REFERENCES users(id),
+    analisado_em TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leiloes_habilitacoes_usuario ON leiloes_habilitacoes(leilao_id, usuario_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leiloes_habilitacoes_paleta ON leiloes_habilitacoes(leilao_id, paleta);
+CREATE INDEX IF NOT EXISTS idx_leiloes_habilitacoes_usuario_id ON leiloes_habilitacoes(usuario_id);
+CREATE INDEX IF NOT EXISTS idx_leiloes_habilitacoes_status ON leiloes_habilitacoes(status);
+
+CREATE TABLE IF NOT EXISTS leiloes_habilitacoes_documentos (
+    id SERIAL PRIMARY KEY,
+    habilitacao_id INTEGER NOT NULL REFERENCES leiloes_habilitacoes(id) ON DELETE CASCADE,
+    tipo VARCHAR(30) NOT NULL CHECK (tipo IN ('documento_identidade', 'comprovante_endereco', 'comprovante_renda', 'referencia_bancaria', 'contrato_social', 'outro')),
+    nome_arquivo VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    tamanho BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    storage_backend VARCHAR(20) NOT NULL,
+    storage_key VARCHAR(500) NOT NULL,
+    enviado_por INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_leiloes_habilitacoes_documentos_habilitacao_id ON leiloes_habilitacoes_documentos(habilitacao_id);
@@ -0,0 +1,8 @@
+-- Migration: Carimbo de tempo RFC 3161 nos contratos concluídos na D4Sign
+-- O horário confiável da conclusão vem do token sobre o SHA-256 do PDF final, não do relógio do servidor
+
+ALTER TABLE d4sign_documents ADD COLUMN IF NOT EXISTS timestamp_token TEXT;
+ALTER TABLE d4sign_documents ADD COLUMN IF NOT EXISTS timestamp_time TIMESTAMP;
+ALTER TABLE d4sign_documents ADD COLUMN IF NOT EXISTS timestamp_authority VARCHAR(255);
+
+COMMENT ON COLUMN d4sign_documents.timestamp_token IS 'TimeStampToken RFC 3161 sobre signed_file_hash';
@@ -0,0 +1,6 @@
+-- Migration: Confirmação de recebimento pelo comprador no marketplace
+-- A avaliação do vendedor só é liberada com o pagamento liberado ou com a compra confirmada pelo comprador
+
+ALTER TABLE marketplace.anuncios ADD COLUMN IF NOT EXISTS confirmado_em TIMESTAMP;
+
+COMMENT ON COLUMN marketplace.anuncios.confirmado_em IS 'Recebimento confirmado pelo comprador registrado na venda';
@@ -0,0 +1,7 @@
+-- Migration: Remove a finalidade marketing_email
+-- A plataforma não envia e-mails de marketing; os aceites existentes deixam de valer e o histórico da política é mantido
+
+UPDATE compliance_records
+SET status = 'superseded', updated_at = CURRENT_TIMESTAMP
+WHERE compliance_type = 'consent' AND status IN ('pending', 'approved')
+  AND request_type = 'marketing_email';
@@ -0,0 +1,203 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeSignatureHeader cabeçalho com o HMAC-SHA256 (hex) do corpo dos webhooks do provedor local
+const FakeSignatureHeader = "X-Payment-Signature"
+
+// FakeDeclinedCardToken token de cartão que o provedor local sempre recusa
+const FakeDeclinedCardToken = "tok_recusado"
+
+// fakeWebhook corpo dos webhooks do provedor local
+type fakeWebhook struct {
+	ChargeID   string       `json:"charge_id"`
+	Status     ChargeStatus `json:"status"`
+	OccurredAt time.Time    `json:"occurred_at"`
+}
+
+// FakeProvider provedor em memória para desenvolvimento e testes: PIX e boleto ficam pendentes até Confirm, cartão é
+// aprovado na hora (exceto FakeDeclinedCardToken) e repasses são concluídos imediatamente. Nada sobrevive a um
+// reinício do processo
+type FakeProvider struct {
+	mu          sync.Mutex
+	secret      string
+	seq         int
+	charges     map[string]*Charge
+	references  map[string]string
+	payouts     map[string]*Payout
+	failPayouts bool
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		secret:     webhookSecret,
+		charges:    make(map[string]*Charge),
+		references: make(map[string]string),
+		payouts:    make(map[string]*Payout),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	if req.AmountCents <= 0 {
+		return nil, fmt.Errorf("valor da cobrança deve ser positivo")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if id, ok := p.references[req.Reference]; ok && req.Reference != "" {
+		charge := *p.charges[id]
+		return &charge, nil
+	}
+
+	p.seq++
+	charge := &Charge{
+		ID:          fmt.Sprintf("fake_ch_%d", p.seq),
+		Status:      ChargePending,
+		AmountCents: req.AmountCents,
+		DueAt:       req.DueAt,
+	}
+	switch req.Method {
+	case MethodPix:
+		charge.PixCode = fmt.Sprintf("00020126580014br.gov.bcb.pix0136%s5204000053039865802BR6304FAKE", charge.ID)
+	case MethodBoleto:
+		charge.BoletoLine = fmt.Sprintf("34191.79001 01043.510047 91020.150008 0 %014d", req.AmountCents)
+		charge.BoletoURL = "https://pagamentos.invalid/boletos/" + charge.ID
+	case MethodCard:
+		if req.CardToken == FakeDeclinedCardToken {
+			charge.Status = ChargeFailed
+			charge.FailureReason = "cartão recusado pelo emissor"
+		} else {
+			agora := time.Now()
+			charge.Status = ChargePaid
+			charge.PaidAt = &agora
+		}
+	default:
+		return nil, fmt.Errorf("meio de pagamento não suportado: %s", req.Method)
+	}
+
+	p.charges[charge.ID] = charge
+	if req.Reference != "" {
+		p.references[req.Reference] = charge.ID
+	}
+	copia := *charge
+	return &copia, nil
+}
+
+func (p *FakeProvider) GetCharge(ctx context.Context, chargeID string) (*Charge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[chargeID]
+	if !ok {
+		return nil, ErrChargeNotFound
+	}
+	if charge.Status == ChargePending && !charge.DueAt.IsZero() && time.Now().After(charge.DueAt) {
+		charge.Status = ChargeExpired
+	}
+	copia := *charge
+	return &copia, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, req RefundRequest) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[req.ChargeID]
+	if !ok {
+		return "", ErrChargeNotFound
+	}
+	if charge.Status != ChargePaid && charge.Status != ChargeRefunded {
+		return "", fmt.Errorf("cobrança %s não está paga", req.ChargeID)
+	}
+	if req.AmountCents > charge.AmountCents {
+		return "", fmt.Errorf("estorno maior que o valor pago")
+	}
+	charge.Status = ChargeRefunded
+	return "fake_rf_" + strings.TrimPrefix(charge.ID, "fake_ch_"), nil
+}
+
+func (p *FakeProvider) Payout(ctx context.Context, req PayoutRequest) (*Payout, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failPayouts {
+		return nil, fmt.Errorf("repasse recusado pelo provedor")
+	}
+	if existente, ok := p.payouts[req.Reference]; ok && req.Reference != "" {
+		copia := *existente
+		return &copia, nil
+	}
+	p.seq++
+	payout := &Payout{ID: fmt.Sprintf("fake_po_%d", p.seq), Status: PayoutPaid}
+	if req.Reference != "" {
+		p.payouts[req.Reference] = payout
+	}
+	copia := *payout
+	return &copia, nil
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte, header http.Header) (*Event, error) {
+	if p.secret == "" || !hmac.Equal([]byte(header.Get(FakeSignatureHeader)), []byte(p.sign(payload))) {
+		return nil, ErrInvalidSignature
+	}
+	var webhook fakeWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("webhook de pagamento malformado: %w", err)
+	}
+	if webhook.ChargeID == "" {
+		return nil, fmt.Errorf("webhook de pagamento sem charge_id")
+	}
+	return &Event{ChargeID: webhook.ChargeID, Status: webhook.Status, OccurredAt: webhook.OccurredAt}, nil
+}
+
+// Confirm simula o pagamento de um PIX ou boleto pendente
+func (p *FakeProvider) Confirm(chargeID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[chargeID]
+	if !ok {
+		return ErrChargeNotFound
+	}
+	if charge.Status != ChargePending {
+		return fmt.Errorf("cobrança %s não está pendente", chargeID)
+	}
+	agora := time.Now()
+	charge.Status = ChargePaid
+	charge.PaidAt = &agora
+	return nil
+}
+
+// FailPayouts faz os próximos repasses falharem, para exercitar as novas tentativas
+func (p *FakeProvider) FailPayouts(fail bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failPayouts = fail
+}
+
+// SignedWebhook corpo e assinatura de um webhook do provedor local, como o provedor real enviaria
+func (p *FakeProvider) SignedWebhook(chargeID string, status ChargeStatus) ([]byte, string) {
+	payload, _ := json.Marshal(fakeWebhook{ChargeID: chargeID, Status: status, OccurredAt: time.Now().UTC()})
+	return payload, p.sign(payload)
+}
+
+func (p *FakeProvider) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
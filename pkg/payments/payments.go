@@ -0,0 +1,131 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+)
+
+// ErrChargeNotFound cobrança inexistente no provedor
+var ErrChargeNotFound = errors.New("cobrança não encontrada no provedor de pagamentos")
+
+// ErrInvalidSignature webhook sem assinatura válida do provedor
+var ErrInvalidSignature = errors.New("assinatura do webhook de pagamento inválida")
+
+// Method meio de pagamento da cobrança
+type Method string
+
+const (
+	MethodPix    Method = "pix"
+	MethodBoleto Method = "boleto"
+	MethodCard   Method = "cartao"
+)
+
+// ChargeStatus situação da cobrança no provedor
+type ChargeStatus string
+
+const (
+	ChargePending  ChargeStatus = "pending"
+	ChargePaid     ChargeStatus = "paid"
+	ChargeFailed   ChargeStatus = "failed"
+	ChargeExpired  ChargeStatus = "expired"
+	ChargeRefunded ChargeStatus = "refunded"
+)
+
+// PayoutStatus situação de um repasse enviado pelo provedor
+type PayoutStatus string
+
+const (
+	PayoutPending PayoutStatus = "pending"
+	PayoutPaid    PayoutStatus = "paid"
+	PayoutFailed  PayoutStatus = "failed"
+)
+
+// Party pagador ou recebedor identificado pelo CPF/CNPJ
+type Party struct {
+	Name     string
+	Email    string
+	Document string
+}
+
+// ChargeRequest cobrança do comprador. Reference é a chave de idempotência: repetir a mesma referência devolve a
+// cobrança já criada
+type ChargeRequest struct {
+	Reference    string
+	Method       Method
+	AmountCents  int64
+	Description  string
+	Customer     Party
+	DueAt        time.Time
+	CardToken    string
+	Installments int
+}
+
+// Charge cobrança criada no provedor, com os dados que o comprador usa para pagar
+type Charge struct {
+	ID            string
+	Status        ChargeStatus
+	AmountCents   int64
+	PixCode       string
+	BoletoLine    string
+	BoletoURL     string
+	DueAt         time.Time
+	PaidAt        *time.Time
+	FailureReason string
+}
+
+// RefundRequest devolução ao comprador de uma cobrança paga
+type RefundRequest struct {
+	Reference   string
+	ChargeID    string
+	AmountCents int64
+	Reason      string
+}
+
+// PayoutRequest transferência do valor em custódia para um recebedor
+type PayoutRequest struct {
+	Reference   string
+	AmountCents int64
+	Recipient   Party
+	Description string
+}
+
+// Payout repasse registrado no provedor
+type Payout struct {
+	ID            string
+	Status        PayoutStatus
+	FailureReason string
+}
+
+// Event notificação de mudança de situação de uma cobrança recebida por webhook
+type Event struct {
+	ChargeID   string
+	Status     ChargeStatus
+	OccurredAt time.Time
+}
+
+// Provider integração com um provedor de pagamentos (PIX, boleto e cartão). O valor fica retido na conta da
+// plataforma até os repasses serem enviados
+type Provider interface {
+	// Name identifica o provedor ("fake", ...) gravado junto de cada pagamento
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	GetCharge(ctx context.Context, chargeID string) (*Charge, error)
+	// Refund devolve o valor ao comprador e retorna o identificador do estorno
+	Refund(ctx context.Context, req RefundRequest) (string, error)
+	Payout(ctx context.Context, req PayoutRequest) (*Payout, error)
+	// ParseWebhook valida a assinatura e interpreta a notificação enviada pelo provedor
+	ParseWebhook(payload []byte, header http.Header) (*Event, error)
+}
+
+// ToCents converte reais em centavos, arredondando ao centavo mais próximo
+func ToCents(valor float64) int64 {
+	return int64(math.Round(valor * 100))
+}
+
+// FromCents converte centavos em reais
+func FromCents(centavos int64) float64 {
+	return float64(centavos) / 100
+}
@@ -0,0 +1,238 @@
+// Package pdf gera documentos PDF simples (texto Helvetica, linhas, retângulos e imagens) sem dependências externas.
+// A saída usa tabela xref clássica e trailer com /ID, compatível com a assinatura PAdES incremental da PKI.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dimensões de página em pontos (1/72 pol.)
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Font fontes padrão disponíveis sem incorporação
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+func (f Font) resource() string {
+	if f == HelveticaBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// Color cor RGB com componentes entre 0 e 1
+type Color struct{ R, G, B float64 }
+
+var (
+	Black = Color{0, 0, 0}
+	White = Color{1, 1, 1}
+)
+
+// Info metadados do documento
+type Info struct {
+	Title    string
+	Author   string
+	Subject  string
+	Creator  string
+	Created  time.Time
+	Keywords []string
+}
+
+// Document PDF em construção; as páginas e imagens são serializadas em Bytes
+type Document struct {
+	info   Info
+	pages  []*Page
+	images []*Image
+}
+
+// New cria um documento vazio
+func New(info Info) *Document {
+	if info.Created.IsZero() {
+		info.Created = time.Now()
+	}
+	return &Document{info: info}
+}
+
+// AddPage acrescenta uma página A4 em retrato
+func (d *Document) AddPage() *Page {
+	page := &Page{width: A4Width, height: A4Height}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Page página com o fluxo de conteúdo; as coordenadas têm origem no canto inferior esquerdo
+type Page struct {
+	width   float64
+	height  float64
+	content bytes.Buffer
+}
+
+// Width largura da página
+func (p *Page) Width() float64 { return p.width }
+
+// Height altura da página
+func (p *Page) Height() float64 { return p.height }
+
+// Text escreve o texto com a linha de base em (x, y); caracteres fora do Latin-1 são substituídos por "?"
+func (p *Page) Text(x, y float64, font Font, size float64, color Color, text string) {
+	fmt.Fprintf(&p.content, "BT %s rg /%s %s Tf %s %s Td %s Tj ET\n",
+		color.operands(), font.resource(), num(size), num(x), num(y), encodeText(text))
+}
+
+// TextRight escreve o texto alinhado à direita em x
+func (p *Page) TextRight(x, y float64, font Font, size float64, color Color, text string) {
+	p.Text(x-TextWidth(text, font, size), y, font, size, color, text)
+}
+
+// TextCentered escreve o texto centralizado em x
+func (p *Page) TextCentered(x, y float64, font Font, size float64, color Color, text string) {
+	p.Text(x-TextWidth(text, font, size)/2, y, font, size, color, text)
+}
+
+// Line traça um segmento
+func (p *Page) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n",
+		color.operands(), num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect contorna um retângulo a partir do canto inferior esquerdo
+func (p *Page) Rect(x, y, w, h, width float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s %s %s re S\n",
+		color.operands(), num(width), num(x), num(y), num(w), num(h))
+}
+
+// FillRect preenche um retângulo a partir do canto inferior esquerdo
+func (p *Page) FillRect(x, y, w, h float64, color Color) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n", color.operands(), num(x), num(y), num(w), num(h))
+}
+
+// DrawImage desenha a imagem escalada para o retângulo (x, y, w, h)
+func (p *Page) DrawImage(img *Image, x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /%s Do Q\n", num(w), num(h), num(x), num(y), img.name)
+}
+
+// Bytes serializa o documento
+func (d *Document) Bytes() []byte {
+	const (
+		catalogNum = 1
+		pagesNum   = 2
+		infoNum    = 3
+		fontNum    = 4 // F1 = 4, F2 = 5
+		firstFree  = 6
+	)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := map[int]int{}
+	writeObject := func(n int, body string) {
+		offsets[n] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", n, body)
+	}
+	writeStream := func(n int, dict string, data []byte) {
+		offsets[n] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", n, dict, len(data))
+		buf.Write(data)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+
+	writeObject(fontNum, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObject(fontNum+1, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	next := firstFree
+	var xobjects strings.Builder
+	for _, img := range d.images {
+		dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /%s",
+			img.width, img.height, img.colorSpace, img.filter)
+		writeStream(next, dict, img.data)
+		fmt.Fprintf(&xobjects, " /%s %d 0 R", img.name, next)
+		next++
+	}
+	resources := fmt.Sprintf("<< /Font << /F1 %d 0 R /F2 %d 0 R >> /XObject <<%s >> >>", fontNum, fontNum+1, xobjects.String())
+
+	kids := make([]string, 0, len(d.pages))
+	for _, page := range d.pages {
+		pageNum, contentNum := next, next+1
+		next += 2
+		writeStream(contentNum, "/Filter /FlateDecode", deflate(page.content.Bytes()))
+		writeObject(pageNum, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			pagesNum, num(page.width), num(page.height), resources, contentNum))
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageNum))
+	}
+	writeObject(pagesNum, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	writeObject(infoNum, d.infoDict())
+	// O catálogo é gravado por último: a assinatura incremental localiza a última definição do objeto
+	writeObject(catalogNum, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesNum))
+
+	size := next
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for n := 1; n < size; n++ {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offsets[n])
+	}
+
+	id := md5.Sum(buf.Bytes())
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R /ID [<%x> <%x>] >>\nstartxref\n%d\n%%%%EOF\n",
+		size, catalogNum, infoNum, id, id, xref)
+	return buf.Bytes()
+}
+
+func (d *Document) infoDict() string {
+	entries := map[string]string{
+		"Title":    d.info.Title,
+		"Author":   d.info.Author,
+		"Subject":  d.info.Subject,
+		"Creator":  d.info.Creator,
+		"Producer": "Equinoid",
+		"Keywords": strings.Join(d.info.Keywords, ", "),
+	}
+	keys := make([]string, 0, len(entries))
+	for k, v := range entries {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("<<")
+	for _, k := range keys {
+		fmt.Fprintf(&b, " /%s %s", k, encodeText(entries[k]))
+	}
+	fmt.Fprintf(&b, " /CreationDate (D:%s+00'00') >>", d.info.Created.UTC().Format("20060102150405"))
+	return b.String()
+}
+
+func (c Color) operands() string {
+	return num(c.R) + " " + num(c.G) + " " + num(c.B)
+}
+
+// num número com até 3 casas decimais, sem zeros à direita
+func num(v float64) string {
+	s := fmt.Sprintf("%.3f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	w, _ := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,81 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+
+	// Decodificadores aceitos em AddImage
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Image imagem registrada no documento (XObject)
+type Image struct {
+	name       string
+	width      int
+	height     int
+	colorSpace string
+	filter     string
+	data       []byte
+}
+
+// Width largura em pixels
+func (i *Image) Width() int { return i.width }
+
+// Height altura em pixels
+func (i *Image) Height() int { return i.height }
+
+// AddImage registra uma imagem JPEG, PNG ou GIF. JPEGs RGB e em tons de cinza são incorporados sem recompressão;
+// os demais formatos são convertidos para RGB sobre fundo branco
+func (d *Document) AddImage(data []byte) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image: %w", err)
+	}
+
+	if format == "jpeg" {
+		switch cfg.ColorModel {
+		case color.GrayModel:
+			return d.register(cfg.Width, cfg.Height, "DeviceGray", "DCTDecode", data), nil
+		case color.YCbCrModel, color.RGBAModel:
+			return d.register(cfg.Width, cfg.Height, "DeviceRGB", "DCTDecode", data), nil
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return d.AddRaster(img), nil
+}
+
+// AddRaster registra uma imagem já decodificada como RGB comprimido, compondo a transparência sobre branco
+func (d *Document) AddRaster(img image.Image) *Image {
+	bounds := img.Bounds()
+	pixels := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// Componentes pré-multiplicados: somar o branco na proporção da transparência
+			white := 0xffff - a
+			pixels = append(pixels, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+	return d.register(bounds.Dx(), bounds.Dy(), "DeviceRGB", "FlateDecode", deflate(pixels))
+}
+
+func (d *Document) register(width, height int, colorSpace, filter string, data []byte) *Image {
+	img := &Image{
+		name:       fmt.Sprintf("Im%d", len(d.images)+1),
+		width:      width,
+		height:     height,
+		colorSpace: colorSpace,
+		filter:     filter,
+		data:       data,
+	}
+	d.images = append(d.images, img)
+	return img
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage armazena objetos no sistema de arquivos; as URLs assinadas apontam para a rota pública da API que
+// confere a assinatura HMAC e serve o arquivo
+type LocalStorage struct {
+	basePath   string
+	publicURL  string
+	signingKey []byte
+}
+
+// NewLocalStorage cria o armazenamento local em basePath com URLs assinadas sob publicURL
+func NewLocalStorage(basePath, publicURL, signingKey string) (*LocalStorage, error) {
+	if signingKey == "" {
+		return nil, errors.New("chave de assinatura de URLs não configurada")
+	}
+	if err := os.MkdirAll(basePath, 0o750); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de armazenamento: %w", err)
+	}
+	return &LocalStorage{
+		basePath:   basePath,
+		publicURL:  publicURL,
+		signingKey: []byte(signingKey),
+	}, nil
+}
+
+func (s *LocalStorage) Name() string {
+	return "local"
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("erro ao criar diretório do objeto: %w", err)
+	}
+
+	// Grava em arquivo temporário e renomeia, para nunca expor um objeto pela metade
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("erro ao gravar objeto: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, bytes.NewReader(data)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar objeto: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar objeto: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("erro ao ler objeto: %w", err)
+	}
+	return file, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("erro ao remover objeto: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) PresignGet(ctx context.Context, key string, ttl time.Duration, filename string) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expires := time.Now().Add(ttl).Unix()
+
+	query := url.Values{}
+	query.Set("key", key)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	if filename != "" {
+		query.Set("filename", filename)
+	}
+	query.Set("signature", s.sign(key, expires, filename))
+	return s.publicURL + "?" + query.Encode(), nil
+}
+
+// VerifySignedURL confere a assinatura e a validade de uma URL emitida por PresignGet
+func (s *LocalStorage) VerifySignedURL(key string, expires int64, filename, signature string) error {
+	if time.Now().Unix() > expires {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires, filename))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s *LocalStorage) sign(key string, expires int64, filename string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10) + "\n" + filename))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path resolve a chave dentro do diretório base, recusando chaves que escapem dele
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if key == "" || clean == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("chave de objeto inválida: %q", key)
+	}
+	return filepath.Join(s.basePath, filepath.FromSlash(clean)), nil
+}
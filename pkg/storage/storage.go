@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrObjectNotFound objeto inexistente no armazenamento
+var ErrObjectNotFound = errors.New("objeto não encontrado no armazenamento")
+
+// ErrInvalidSignature URL assinada inválida ou expirada
+var ErrInvalidSignature = errors.New("assinatura de URL inválida ou expirada")
+
+// Storage define a interface para armazenamento de objetos (arquivos de documentos)
+type Storage interface {
+	// Name identifica o backend ("local", "s3") gravado junto de cada objeto
+	Name() string
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// PresignGet URL temporária para download direto do objeto, sem autenticação da API
+	PresignGet(ctx context.Context, key string, ttl time.Duration, filename string) (string, error)
+}
+
+// SignedURLVerifier backends cujas URLs assinadas são servidas pela própria API
+type SignedURLVerifier interface {
+	VerifySignedURL(key string, expires int64, filename, signature string) error
+}